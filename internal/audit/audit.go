@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// the actions recorded in the audit log
+const (
+	ActionDashboardViewed = "dashboard_viewed"
+	ActionSnapshotViewed  = "snapshot_viewed"
+	ActionBenchmarkRun    = "benchmark_run"
+	ActionInputsChanged   = "inputs_changed"
+	ActionCommandRun      = "command_run"
+)
+
+// eventBufferSize is the number of events which may be queued for each sink - events recorded when the queue of a
+// sink is full are dropped for that sink
+const eventBufferSize = 256
+
+// Event is a single audit log entry
+type Event struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Action    string         `json:"action"`
+	Resource  string         `json:"resource,omitempty"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	Session   string         `json:"session"`
+	Client    string         `json:"client,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
+
+	// the details of a command invocation - see NewCommandEvent
+	Command    string   `json:"command,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	User       string   `json:"user,omitempty"`
+	DurationMs *int64   `json:"duration_ms,omitempty"`
+	ExitCode   *int     `json:"exit_code,omitempty"`
+}
+
+// NewEvent creates an event for the given action, populating the client details from the request
+func NewEvent(action, resource, session string, inputs map[string]any, request *http.Request) *Event {
+	e := &Event{
+		Timestamp: time.Now(),
+		Action:    action,
+		Resource:  resource,
+		Inputs:    inputs,
+		Session:   session,
+	}
+	if request != nil {
+		e.Client = clientAddress(request)
+		e.UserAgent = request.UserAgent()
+	}
+	return e
+}
+
+// the address of the client - use the forwarded address if the server is behind a proxy
+func clientAddress(request *http.Request) string {
+	if forwarded := request.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		return host
+	}
+	return request.RemoteAddr
+}
+
+// sink is implemented by all audit log destinations
+type sink interface {
+	write(ctx context.Context, e *Event) error
+	close()
+	// the name of the sink, used in warnings
+	name() string
+}
+
+// sinkQueue is the queue of events to be written to a sink - each sink has its own queue, so a slow sink (e.g. a
+// webhook) does not cause events to be dropped for the other sinks
+type sinkQueue struct {
+	sink   sink
+	events chan *Event
+	done   chan struct{}
+}
+
+func newSinkQueue(s sink) *sinkQueue {
+	q := &sinkQueue{
+		sink:   s,
+		events: make(chan *Event, eventBufferSize),
+		done:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *sinkQueue) run() {
+	defer close(q.done)
+	// queued events are still written after the server context is cancelled, so do not use it here
+	ctx := context.Background()
+	for e := range q.events {
+		if err := q.sink.write(ctx, e); err != nil {
+			slog.Warn("failed to write audit event", "sink", q.sink.name(), "action", e.Action, "error", err)
+		}
+	}
+	q.sink.close()
+}
+
+// Logger writes audit events to the configured sinks
+// events are written asynchronously so recording an event does not block the caller
+// a nil Logger is valid and records nothing
+type Logger struct {
+	queues []*sinkQueue
+}
+
+// NewLogger creates an audit logger using the configured audit log file and webhook
+// if no audit destination is configured, return nil
+func NewLogger(ctx context.Context) (*Logger, error) {
+	var sinks []sink
+	if dir := viper.GetString(localconstants.ArgAuditLog); dir != "" {
+		s, err := newFileSink(ctx, dir, viper.GetInt(localconstants.ArgAuditRetention))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if url := viper.GetString(localconstants.ArgAuditWebhook); url != "" {
+		sinks = append(sinks, newWebhookSink(url))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return newLogger(sinks), nil
+}
+
+func newLogger(sinks []sink) *Logger {
+	l := &Logger{}
+	for _, s := range sinks {
+		l.queues = append(l.queues, newSinkQueue(s))
+	}
+	return l
+}
+
+// Record queues the event to be written to the audit log. Record never blocks - if a sink cannot keep up (e.g. a
+// slow webhook) and its queue is full, the event is dropped for that sink and a warning logged
+func (l *Logger) Record(e *Event) {
+	if l == nil {
+		return
+	}
+	for _, q := range l.queues {
+		select {
+		case q.events <- e:
+		default:
+			slog.Warn("audit log queue is full - dropping audit event", "sink", q.sink.name(), "action", e.Action, "resource", e.Resource)
+		}
+	}
+}
+
+// Close writes any queued events and closes the sinks
+func (l *Logger) Close() {
+	if l == nil {
+		return
+	}
+	for _, q := range l.queues {
+		close(q.events)
+	}
+	for _, q := range l.queues {
+		<-q.done
+	}
+}
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink records the events written to it - if blocked is set, writes wait until it is closed
+type recordingSink struct {
+	mut     sync.Mutex
+	events  []*Event
+	blocked chan struct{}
+	closed  bool
+}
+
+func (s *recordingSink) write(_ context.Context, e *Event) error {
+	if s.blocked != nil {
+		<-s.blocked
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *recordingSink) close() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.closed = true
+}
+
+func (s *recordingSink) name() string {
+	return "recording"
+}
+
+func TestLoggerSlowSink(t *testing.T) {
+	fast := &recordingSink{}
+	slow := &recordingSink{blocked: make(chan struct{})}
+	l := newLogger([]sink{fast, slow})
+
+	// the slow sink cannot keep up, so its queue fills and events are dropped for it - but not for the fast sink
+	eventCount := 4 * eventBufferSize
+	for i := 0; i < eventCount; i++ {
+		l.Record(NewEvent(ActionDashboardViewed, "local.dashboard.d1", "s1", nil, nil))
+		if i%eventBufferSize == 0 {
+			// let the fast sink drain its queue
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	close(slow.blocked)
+	l.Close()
+
+	if len(fast.events) != eventCount {
+		t.Errorf("expected the fast sink to write %d events, got %d", eventCount, len(fast.events))
+	}
+	if len(slow.events) >= eventCount {
+		t.Errorf("expected events to be dropped for the slow sink, got %d", len(slow.events))
+	}
+	if !fast.closed || !slow.closed {
+		t.Errorf("expected the sinks to be closed")
+	}
+}
+
+func TestFileSinkPrune(t *testing.T) {
+	now := time.Now()
+	fileName := func(daysAgo int) string {
+		return auditFilePrefix + now.AddDate(0, 0, -daysAgo).Format(auditFileDateFmt) + auditFileExtension
+	}
+
+	tests := map[string]struct {
+		retentionDays int
+		existing      []string
+		expected      []string
+	}{
+		"files within retention": {
+			retentionDays: 7,
+			existing:      []string{fileName(0), fileName(3), fileName(7)},
+			expected:      []string{fileName(0), fileName(3), fileName(7)},
+		},
+		"expired files": {
+			retentionDays: 7,
+			existing:      []string{fileName(0), fileName(8), fileName(30)},
+			expected:      []string{fileName(0)},
+		},
+		"other files": {
+			retentionDays: 1,
+			existing:      []string{fileName(30), "notes.txt", auditFilePrefix + "backup.json"},
+			expected:      []string{auditFilePrefix + "backup.json", "notes.txt"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tc.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s := &fileSink{dir: dir, retentionDays: tc.retentionDays}
+			s.prune()
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			expected := append([]string{}, tc.expected...)
+			sort.Strings(expected)
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("got files %v, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestFileSinkRetention(t *testing.T) {
+	dir := t.TempDir()
+	expired := auditFilePrefix + time.Now().AddDate(0, 0, -10).Format(auditFileDateFmt) + auditFileExtension
+	if err := os.WriteFile(filepath.Join(dir, expired), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// expired files are pruned when the sink is created, and events are written to the file of their date
+	s, err := newFileSink(context.Background(), dir, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEvent(ActionDashboardViewed, "local.dashboard.d1", "s1", nil, nil)
+	if err := s.write(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	s.close()
+
+	current := auditFilePrefix + e.Timestamp.Format(auditFileDateFmt) + auditFileExtension
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, expired)); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be pruned", expired)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if info, err := os.Stat(filepath.Join(dir, current)); err != nil || info.Size() == 0 {
+		t.Errorf("expected the event to be written to %s: %v", current, err)
+	}
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/constants/runtime"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
+// secretFlags are the flags whose values are always redacted from the audit log
+var secretFlags = map[string]struct{}{
+	constants.ArgPipesToken: {},
+}
+
+// variableFlags are the flags whose values are name=value pairs - the values are redacted, as variables may be secrets
+var variableFlags = map[string]struct{}{
+	constants.ArgVariable: {},
+}
+
+// SensitiveArgsAnnotation is the annotation of commands whose positional args are secrets, e.g. the plaintext of
+// config encrypt - the positional args of these commands are redacted from the audit log
+const SensitiveArgsAnnotation = "audit_sensitive_args"
+
+// NewCommandEvent creates an event recording the invocation of a command, e.g. "powerpipe benchmark run", by the
+// current user. The session of the event is the execution ID, which is also written to the logs of the command.
+// The sensitive args are the positional args of the command which are redacted, see SensitiveArgsAnnotation
+func NewCommandEvent(command string, args, sensitiveArgs []string, start time.Time, exitCode int) *Event {
+	durationMs := time.Since(start).Milliseconds()
+	return &Event{
+		Timestamp:  time.Now(),
+		Action:     ActionCommandRun,
+		Session:    runtime.ExecutionID,
+		Command:    command,
+		Args:       RedactArgs(args, sensitiveArgs),
+		User:       currentUser(),
+		DurationMs: &durationMs,
+		ExitCode:   &exitCode,
+	}
+}
+
+// RecordCommand writes the event to the audit log, if one is configured. Unlike Record, the event has been written
+// when RecordCommand returns, as it is called when the command is about to exit
+func RecordCommand(ctx context.Context, e *Event) {
+	l, err := NewLogger(ctx)
+	if err != nil {
+		slog.Warn("failed to open the audit log", "error", err)
+		return
+	}
+	l.Record(e)
+	l.Close()
+}
+
+// RedactArgs returns the command line args with secrets redacted: the sensitive positional args, the values of secret
+// flags and variables, the passwords of urls, and any values which look like credentials
+func RedactArgs(args, sensitiveArgs []string) []string {
+	res := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			if slices.Contains(sensitiveArgs, arg) {
+				res = append(res, sanitize.RedactedStr)
+				continue
+			}
+			res = append(res, redactValue("", arg))
+			continue
+		}
+		flag, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if hasValue {
+			res = append(res, "--"+flag+"="+redactValue(flag, value))
+			continue
+		}
+		res = append(res, arg)
+		// the value of a secret or variable flag may be the next arg
+		_, isSecret := secretFlags[flag]
+		_, isVariable := variableFlags[flag]
+		if (isSecret || isVariable) && i+1 < len(args) {
+			i++
+			res = append(res, redactValue(flag, args[i]))
+		}
+	}
+	return res
+}
+
+func redactValue(flag, value string) string {
+	if _, ok := secretFlags[flag]; ok {
+		return sanitize.RedactedStr
+	}
+	if _, ok := variableFlags[flag]; ok {
+		if name, _, ok := strings.Cut(value, "="); ok {
+			return name + "=" + sanitize.RedactedStr
+		}
+	}
+	if redacted, ok := redactURLPassword(value); ok {
+		return redacted
+	}
+	return sanitize.Instance.SanitizeString(value)
+}
+
+// redactURLPassword redacts the password of a url, e.g. a database connection string, returning false if the value
+// is not a url with a password
+func redactURLPassword(value string) (string, bool) {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return "", false
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return "", false
+	}
+	u.User = url.UserPassword(u.User.Username(), sanitize.RedactedStr)
+	return u.String(), true
+}
+
+// currentUser returns the name of the user running the command
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
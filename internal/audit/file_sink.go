@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	auditFilePrefix    = "audit-"
+	auditFileExtension = ".jsonl"
+	auditFileDateFmt   = "2006-01-02"
+	// how often to remove audit files which are older than the retention period
+	pruneInterval = time.Hour
+)
+
+// fileSink writes audit events as JSON lines to a daily file in the audit log directory
+// files older than the retention period are deleted
+type fileSink struct {
+	dir           string
+	retentionDays int
+
+	mut         sync.Mutex
+	file        *os.File
+	currentDate string
+	stopPrune   context.CancelFunc
+}
+
+func newFileSink(ctx context.Context, dir string, retentionDays int) (*fileSink, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory %s: %w", dir, err)
+	}
+	s := &fileSink{
+		dir:           dir,
+		retentionDays: retentionDays,
+	}
+	if retentionDays > 0 {
+		var pruneCtx context.Context
+		pruneCtx, s.stopPrune = context.WithCancel(ctx)
+		go s.pruneLoop(pruneCtx)
+	}
+	return s, nil
+}
+
+func (s *fileSink) write(_ context.Context, e *Event) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if err := s.ensureFile(e.Timestamp); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// ensureFile opens the audit file for the date of the given time, closing the previous file if the date has changed
+func (s *fileSink) ensureFile(t time.Time) error {
+	date := t.Format(auditFileDateFmt)
+	if s.file != nil && date == s.currentDate {
+		return nil
+	}
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+	path := filepath.Join(s.dir, auditFilePrefix+date+auditFileExtension)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.currentDate = date
+	return nil
+}
+
+func (s *fileSink) pruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		s.prune()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune deletes audit files older than the retention period
+func (s *fileSink) prune() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		slog.Warn("failed to read audit log directory", "error", err)
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.retentionDays).Format(auditFileDateFmt)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, auditFilePrefix) || !strings.HasSuffix(name, auditFileExtension) {
+			continue
+		}
+		date := strings.TrimSuffix(strings.TrimPrefix(name, auditFilePrefix), auditFileExtension)
+		// dates are formatted so they sort lexically
+		if date < cutoff {
+			slog.Debug("removing expired audit log", "file", name)
+			if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+				slog.Warn("failed to remove expired audit log", "file", name, "error", err)
+			}
+		}
+	}
+}
+
+func (s *fileSink) name() string {
+	return "file"
+}
+
+func (s *fileSink) close() {
+	if s.stopPrune != nil {
+		s.stopPrune()
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+}
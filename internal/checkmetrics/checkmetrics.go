@@ -0,0 +1,180 @@
+// Package checkmetrics renders the summary of a check run as OpenMetrics, so alerting can be built on benchmark
+// trends. The metrics are written to a file by --export openmetrics (or --export <file>.prom, which the node exporter
+// textfile collector can read), or pushed to a Prometheus Pushgateway by --pushgateway.
+//
+// The metrics are gauges:
+//
+//	powerpipe_check_results{status}                                 control results of the run, by status
+//	powerpipe_check_duration_seconds                                duration of the run
+//	powerpipe_check_timestamp_seconds                               end time of the run
+//	powerpipe_benchmark_results{benchmark,status}                   control results of each benchmark, by status
+//	powerpipe_benchmark_severity_results{benchmark,severity,status} control results of each benchmark, by severity
+//	powerpipe_benchmark_controls{benchmark}                         controls of each benchmark
+//	powerpipe_benchmark_duration_seconds{benchmark}                 duration of each benchmark
+//
+// The statuses are alarm, ok, info, skip and error.
+package checkmetrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+// JobName is the Pushgateway job the metrics are pushed to
+const JobName = "powerpipe"
+
+const pushTimeout = 30 * time.Second
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// Write writes the metrics of the executed tree in the OpenMetrics text format
+func Write(w io.Writer, tree *controlexecute.ExecutionTree) error {
+	benchmarks := benchmarkGroups(tree.Root)
+
+	results := &family{name: "powerpipe_check_results", help: "The number of control results of the run, by status."}
+	results.addStatuses(tree.Root.Summary.Status)
+	duration := &family{name: "powerpipe_check_duration_seconds", help: "The duration of the run."}
+	duration.add(tree.EndTime.Sub(tree.StartTime).Seconds())
+	timestamp := &family{name: "powerpipe_check_timestamp_seconds", help: "The time the run completed."}
+	timestamp.add(float64(tree.EndTime.UnixMilli()) / 1000)
+
+	benchmarkResults := &family{name: "powerpipe_benchmark_results", help: "The number of control results of the benchmark, by status."}
+	severityResults := &family{name: "powerpipe_benchmark_severity_results", help: "The number of control results of the benchmark, by severity and status."}
+	controls := &family{name: "powerpipe_benchmark_controls", help: "The number of controls of the benchmark."}
+	benchmarkDuration := &family{name: "powerpipe_benchmark_duration_seconds", help: "The duration of the benchmark."}
+	for _, group := range benchmarks {
+		benchmark := [2]string{"benchmark", group.GroupId}
+		benchmarkResults.addStatuses(group.Summary.Status, benchmark)
+		severities := make([]string, 0, len(group.Summary.Severity))
+		for severity := range group.Summary.Severity {
+			severities = append(severities, severity)
+		}
+		sort.Strings(severities)
+		for _, severity := range severities {
+			severityResults.addStatuses(group.Summary.Severity[severity], benchmark, [2]string{"severity", severity})
+		}
+		controls.add(float64(group.ControlRunCount()), benchmark)
+		benchmarkDuration.add(group.Duration.Seconds(), benchmark)
+	}
+
+	var b bytes.Buffer
+	for _, f := range []*family{results, duration, timestamp, benchmarkResults, severityResults, controls, benchmarkDuration} {
+		f.write(&b)
+	}
+	b.WriteString("# EOF\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Push replaces the metrics of the target in the Pushgateway with the metrics of the executed tree. The metrics are
+// grouped by the job and the target, so the runs of different targets do not replace each other
+func Push(ctx context.Context, gatewayURL, target string, tree *controlexecute.ExecutionTree) error {
+	var body bytes.Buffer
+	if err := Write(&body, tree); err != nil {
+		return err
+	}
+	pushURL := fmt.Sprintf("%s/metrics/job/%s/target/%s", strings.TrimSuffix(gatewayURL, "/"), JobName, url.PathEscape(target))
+
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// ValidateGatewayURL checks the Pushgateway URL is an http or https URL
+func ValidateGatewayURL(gatewayURL string) error {
+	u, err := url.Parse(gatewayURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid pushgateway '%s' - must be an http or https url", gatewayURL)
+	}
+	return nil
+}
+
+// benchmarkGroups returns the result groups of the benchmarks of the tree, in tree order - every group other than the
+// root and the group of a mod (the groups of a tree loaded from a snapshot have no GroupItem)
+func benchmarkGroups(group *controlexecute.ResultGroup) []*controlexecute.ResultGroup {
+	var res []*controlexecute.ResultGroup
+	if _, isMod := group.GroupItem.(*modconfig.Mod); group.GroupId != controlexecute.RootResultGroupName && !isMod {
+		res = append(res, group)
+	}
+	for _, child := range group.Groups {
+		res = append(res, benchmarkGroups(child)...)
+	}
+	return res
+}
+
+func (f *family) add(value float64, labels ...[2]string) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func (f *family) addStatuses(summary controlstatus.StatusSummary, labels ...[2]string) {
+	for _, s := range []struct {
+		status string
+		count  int
+	}{
+		{constants.ControlAlarm, summary.Alarm},
+		{constants.ControlOk, summary.Ok},
+		{constants.ControlInfo, summary.Info},
+		{constants.ControlSkip, summary.Skip},
+		{constants.ControlError, summary.Error},
+	} {
+		f.add(float64(s.count), append(labels[:len(labels):len(labels)], [2]string{"status", s.status})...)
+	}
+}
+
+func (f *family) write(b *bytes.Buffer) {
+	fmt.Fprintf(b, "# TYPE %s gauge\n# HELP %s %s\n", f.name, f.name, f.help)
+	for _, s := range f.samples {
+		b.WriteString(f.name)
+		if len(s.labels) > 0 {
+			b.WriteByte('{')
+			for i, l := range s.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(b, "%s=\"%s\"", l[0], escapeLabelValue(l[1]))
+			}
+			b.WriteByte('}')
+		}
+		fmt.Fprintf(b, " %s\n", strconv.FormatFloat(s.value, 'f', -1, 64))
+	}
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
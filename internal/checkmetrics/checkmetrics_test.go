@@ -0,0 +1,113 @@
+package checkmetrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+const testSnapshot = `{
+  "schema_version": "20240607",
+  "start_time": "2024-01-01T09:00:00Z",
+  "end_time": "2024-01-01T09:01:30Z",
+  "layout": {"name": "aws_compliance.benchmark.s3", "panel_type": "benchmark", "children": [
+    {"name": "aws_compliance.control.s3_bucket_versioning_enabled", "panel_type": "control"},
+    {"name": "aws_compliance.control.s3_bucket_logging_enabled", "panel_type": "control"}
+  ]},
+  "panels": {
+    "aws_compliance.benchmark.s3": {"name": "aws_compliance.benchmark.s3", "title": "S3", "panel_type": "benchmark"},
+    "aws_compliance.control.s3_bucket_versioning_enabled": {
+      "name": "aws_compliance.control.s3_bucket_versioning_enabled",
+      "panel_type": "control",
+      "properties": {"severity": "high"},
+      "status": "complete",
+      "data": {
+        "columns": [{"name": "resource"}, {"name": "status"}, {"name": "reason"}],
+        "rows": [
+          {"resource": "arn:aws:s3:::assets", "status": "ok", "reason": "enabled"},
+          {"resource": "arn:aws:s3:::logs", "status": "alarm", "reason": "disabled"}
+        ]
+      }
+    },
+    "aws_compliance.control.s3_bucket_logging_enabled": {
+      "name": "aws_compliance.control.s3_bucket_logging_enabled",
+      "panel_type": "control",
+      "properties": {"severity": "high"},
+      "status": "complete",
+      "data": {
+        "columns": [{"name": "resource"}, {"name": "status"}, {"name": "reason"}],
+        "rows": [
+          {"resource": "arn:aws:s3:::logs", "status": "alarm", "reason": "disabled"}
+        ]
+      }
+    }
+  }
+}`
+
+func testTree(t *testing.T) *controlexecute.ExecutionTree {
+	tree, err := controlexecute.NewExecutionTreeFromSnapshot([]byte(testSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestWrite(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, testTree(t)); err != nil {
+		t.Fatal(err)
+	}
+	metrics := b.String()
+	for _, expected := range []string{
+		"# TYPE powerpipe_check_results gauge\n",
+		`powerpipe_check_results{status="alarm"} 2` + "\n",
+		"powerpipe_check_duration_seconds 90\n",
+		"powerpipe_check_timestamp_seconds 1704099690\n",
+		`powerpipe_benchmark_results{benchmark="aws_compliance.benchmark.s3",status="ok"} 1` + "\n",
+		`powerpipe_benchmark_severity_results{benchmark="aws_compliance.benchmark.s3",severity="high",status="alarm"} 2` + "\n",
+		`powerpipe_benchmark_controls{benchmark="aws_compliance.benchmark.s3"} 2` + "\n",
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("expected the metrics to contain %q, got\n%s", expected, metrics)
+		}
+	}
+	if strings.Contains(metrics, controlexecute.RootResultGroupName) {
+		t.Error("expected no metrics for the root result group")
+	}
+	if !strings.HasSuffix(metrics, "# EOF\n") {
+		t.Error("expected the metrics to end with # EOF")
+	}
+	if escapeLabelValue("a\"b\\c\nd") != `a\"b\\c\nd` {
+		t.Errorf("unexpected escaped label value %s", escapeLabelValue("a\"b\\c\nd"))
+	}
+}
+
+func TestPush(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		path, body = r.URL.EscapedPath(), string(b)
+	}))
+	defer server.Close()
+
+	if err := Push(context.Background(), server.URL+"/", "aws_compliance.benchmark.s3", testTree(t)); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/metrics/job/powerpipe/target/aws_compliance.benchmark.s3" || !strings.Contains(body, "powerpipe_check_results") {
+		t.Errorf("unexpected push to %s", path)
+	}
+
+	if err := ValidateGatewayURL("localhost:9091"); err == nil {
+		t.Error("expected a url without a scheme to be invalid")
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/powerpipe/internal/audit"
+)
+
+// recordCommandAudit records the invocation of the command in the audit log, if one is configured (see --audit-log
+// and --audit-webhook)
+func recordCommandAudit(ctx context.Context, start time.Time, exitCode int) {
+	// the active command is set by the pre run hook - if it is not set (e.g. for --help) no config has been loaded
+	cmd, ok := viper.Get(constants.ConfigKeyActiveCommand).(*cobra.Command)
+	if !ok {
+		return
+	}
+	var sensitiveArgs []string
+	if _, ok := cmd.Annotations[audit.SensitiveArgsAnnotation]; ok {
+		sensitiveArgs = cmd.Flags().Args()
+	}
+	audit.RecordCommand(ctx, audit.NewCommandEvent(cmd.CommandPath(), os.Args[1:], sensitiveArgs, start, exitCode))
+}
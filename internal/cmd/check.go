@@ -20,6 +20,7 @@ import (
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/statushooks"
 	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/checkmetrics"
 	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controldisplay"
@@ -28,6 +29,8 @@ import (
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/display"
 	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/redact"
+	"github.com/turbot/powerpipe/internal/snapshot"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
 
@@ -69,23 +72,39 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 		AddBoolFlag(constants.ArgProgress, true, "Display control execution progress").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Turbot Pipes with 'anyone_with_link' visibility").
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Turbot Pipes with the default (workspace) visibility").
-		AddBoolFlag(constants.ArgTiming, false, "Turn on the query timer").
+		AddStringFlag(constants.ArgTiming, constants.ArgOff, "Display query timing; one of: off, on, verbose", cmdconfig.FlagOptions.NoOptDefVal(constants.ArgOn)).
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
+		AddStringFlag(localconstants.ArgSnapshotSigningKey, "", "Path to an armored private key used to sign snapshots written to a directory or object storage").
+		AddStringFlag(localconstants.ArgSnapshotCompression, snapshot.CompressionNone, "Compression of snapshots written to a directory or object storage: none or zstd").
+		AddStringFlag(localconstants.ArgSnapshotVisibility, "", "Visibility of snapshots uploaded to Turbot Pipes: workspace, org or anyone_with_link (defaults to anyone_with_link for --share, otherwise workspace)").
+		AddStringFlag(localconstants.ArgSnapshotExpiry, "", "Expire snapshots uploaded to Turbot Pipes after a number of hours, days or weeks, e.g. 7d").
+		AddStringFlag(localconstants.ArgSnapshotWebhook, "", "URL to post the snapshot metadata to when a snapshot is uploaded to Turbot Pipes or object storage").
+		AddStringSliceFlag(localconstants.ArgRedactColumns, nil, "Columns whose values are redacted in snapshots and exports (comma-separated, may contain glob patterns)").
+		AddStringFlag(localconstants.ArgRedactMode, redact.ModeMask, "How redacted values are replaced: mask, or hash (keyed with POWERPIPE_REDACT_SALT)").
 		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
 		AddStringArrayFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
+		AddStringFlag(localconstants.ArgPipesVariables, "", "Use the variable settings of a Turbot Pipes workspace (<identity>/<workspace>) for variables which are not set locally").
+		AddStringFlag(localconstants.ArgOtelEndpoint, "", "Export traces of the execution to the OpenTelemetry collector at this OTLP gRPC endpoint").
+		AddBoolFlag(localconstants.ArgOtelInsecure, false, "Connect to the OpenTelemetry collector without TLS").
+		AddStringFlag(localconstants.ArgQueryLog, "", "Record every SQL statement executed in this file (or stdout or stderr)").
+		AddStringFlag(localconstants.ArgPushgateway, "", "URL of a Prometheus Pushgateway to push the summary metrics of the run to").
+		AddStringArrayFlag(localconstants.ArgControlDatabase, nil, "Run the controls matching a control or benchmark name (or glob pattern) against a database, as <name>=<database>").
 		// Define the CLI flag parameters for wrapped enum flag.
 		AddVarFlag(enumflag.New(&checkOutputMode, constants.ArgOutput, localconstants.CheckOutputModeIds, enumflag.EnumCaseInsensitive),
 			constants.ArgOutput,
 			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.CheckOutputModeIds), ", "))).
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - a local file path, a Turbot Pipes workspace or an object storage URL (s3://, gs:// or azblob://)").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, pps (snapshot), asff").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, openmetrics, pps (snapshot), asff, bundle (zip)").
+		AddStringSliceFlag(localconstants.ArgBundleFormat, nil, "The export formats included in bundle exports, as well as the snapshot").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path (comma-separated)").
+		AddBoolFlag(localconstants.ArgReadOnly, false, "Reject statements which are not queries and use read-only database sessions where supported").
+		AddBoolFlag(localconstants.ArgStartSteampipe, false, "Start the local Steampipe service if no database is specified and the service is not running").
 		AddIntFlag(constants.ArgBenchmarkTimeout, 0, "Set the benchmark execution timeout")
 
 	// for control command, add --arg
@@ -208,6 +227,9 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 			display.PrintTiming(&localqueryresult.TimingMetadata{
 				Duration: time.Since(startTime),
 			})
+			if display.TimingMode() == constants.ArgVerbose {
+				display.PrintTimingReport(namedTree.tree.TimingReport(namedTree.name))
+			}
 		}
 
 		err = exportExecutionTree(ctx, namedTree, initData, viper.GetStringSlice(constants.ArgExport))
@@ -215,6 +237,13 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 			error_helpers.ShowError(ctx, err)
 			totalErrors++
 		}
+
+		// a failure to push the metrics does not fail the run
+		if gateway := viper.GetString(localconstants.ArgPushgateway); gateway != "" {
+			if err := checkmetrics.Push(ctx, gateway, namedTree.name, namedTree.tree); err != nil {
+				error_helpers.ShowWarning(fmt.Sprintf("failed to push metrics to the pushgateway: %s", err.Error()))
+			}
+		}
 	}
 }
 
@@ -232,6 +261,15 @@ func exportExecutionTree[T controlinit.CheckTarget](ctx context.Context, namedTr
 		return err
 	}
 
+	// with verbose timing, write the timing of the control queries alongside the exports
+	if len(exportArgs) > 0 && display.TimingMode() == constants.ArgVerbose {
+		timingMsg, err := exportTimingReport(namedTree.tree.TimingReport(namedTree.name))
+		if err != nil {
+			return err
+		}
+		exportMsg = append(exportMsg, timingMsg)
+	}
+
 	// print the location where the file is exported if progress=true
 	if len(exportMsg) > 0 && viper.GetBool(constants.ArgProgress) {
 		fmt.Printf("\n%s\n", strings.Join(exportMsg, "\n")) //nolint:forbidigo // we want to print
@@ -355,13 +393,23 @@ func validateCheckArgs(ctx context.Context) error {
 		return fmt.Errorf("only 1 of '--%s' and '--%s' may be set", constants.ArgWhere, constants.ArgTag)
 	}
 
+	if err := display.ValidateTiming(); err != nil {
+		return err
+	}
+
+	if gateway := viper.GetString(localconstants.ArgPushgateway); gateway != "" {
+		if err := checkmetrics.ValidateGatewayURL(gateway); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func shouldPrintCheckTiming() bool {
 	outputFormat := viper.GetString(constants.ArgOutput)
 
-	return (viper.GetBool(constants.ArgTiming) && !viper.GetBool(constants.ArgDryRun)) &&
+	return (display.TimingMode() != constants.ArgOff && !viper.GetBool(constants.ArgDryRun)) &&
 		(outputFormat == constants.OutputFormatText || outputFormat == constants.OutputFormatBrief)
 }
 
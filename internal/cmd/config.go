@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/audit"
+	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
+	"github.com/turbot/powerpipe/internal/configschema"
+	"github.com/turbot/powerpipe/internal/configvalidate"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/encryption"
+	"sigs.k8s.io/yaml"
+)
+
+func configCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "config [command]",
+		Args:  cobra.NoArgs,
+		Short: "Powerpipe config management",
+		Long: `Powerpipe config management.
+
+Config is defined in .ppc files in the config path: workspace profiles, command options, connections and pipes
+credentials. Workspace profiles and command options may also be defined in YAML or JSON, in .ppc.yaml, .ppc.yml
+and .ppc.json files.
+
+Sensitive values such as tokens may be encrypted with config encrypt, and set in config files with the encrypted
+function, e.g. pipes_token = encrypted("age:..."), so config files which contain them can be committed.
+
+Examples:
+
+    # Validate the config files
+    powerpipe config validate
+
+    # Output the JSON schema of the YAML and JSON config files
+    powerpipe config schema
+
+    # Encrypt a token for an age recipient
+    powerpipe config encrypt --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p tpt_xxx`,
+	}
+	cmd.AddCommand(configValidateCmd())
+	cmd.AddCommand(configSchemaCmd())
+	cmd.AddCommand(configEncryptCmd())
+	cmd.AddCommand(configDecryptCmd())
+	cmd.Flags().BoolP("help", "h", false, "Help for config")
+
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "validate",
+		Args:  cobra.NoArgs,
+		Run:   runConfigValidateCmd,
+		Short: "Validate the config files",
+		Long: `Validate the config files.
+
+The config files of each directory in the config path are parsed, and the workspace profiles, command options,
+connections and pipes credentials they define are decoded. Errors are reported with their file and line for files
+which cannot be parsed, blocks which cannot be decoded and workspace profiles whose database refers to a connection
+which is not defined. Warnings are reported for workspace profiles which are shadowed by a profile of the same name
+in a directory of higher precedence.
+
+The command exits with code 69 if any errors are found. Use --output json for machine-readable output in CI.
+
+Example:
+
+  # Validate the config files
+  powerpipe config validate
+
+  # Validate the config files of a directory, outputting the issues as JSON
+  powerpipe config validate --config-path ./config --output json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for validate", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func runConfigValidateCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runConfigValidateCmd")
+	defer func() {
+		utils.LogTime("cmd.runConfigValidateCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	configPaths, err := cmdconfig.GetConfigPath()
+	error_helpers.FailOnError(err)
+
+	issues, err := configvalidate.Validate(configPaths, localcmdconfig.ConfigError())
+	error_helpers.FailOnError(err)
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		// always output an array, so the output can be parsed when there are no issues
+		if issues == nil {
+			issues = []*configvalidate.Issue{}
+		}
+		jsonOutput, err := json.MarshalIndent(issues, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(issues)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		//nolint:forbidigo // intended output
+		fmt.Println(buildConfigValidateSummary(issues))
+	}
+
+	if configvalidate.HasErrors(issues) {
+		exitCode = localconstants.ExitCodeConfigInvalid
+	}
+}
+
+func configSchemaCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:       "schema [config|mod]",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{configschema.TypeConfig, configschema.TypeMod},
+		Run:       runConfigSchemaCmd,
+		Short:     "Output the JSON schema of the config or mod files",
+		Long: `Output the JSON schema of the config or mod files.
+
+The config schema describes the workspace profiles and command options of the YAML and JSON config files
+(.ppc.yaml, .ppc.yml and .ppc.json). The mod schema describes the mod and resource blocks of a mod file in the HCL
+JSON syntax. Editors and policy tools can use the schemas to validate the files.
+
+Example:
+
+  # Output the schema of the config files
+  powerpipe config schema > powerpipe-config.schema.json
+
+  # Output the schema of the mod files
+  powerpipe config schema mod`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for schema", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runConfigSchemaCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runConfigSchemaCmd")
+	defer func() {
+		utils.LogTime("cmd.runConfigSchemaCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	var schema *configschema.Schema
+	if len(args) > 0 && args[0] == configschema.TypeMod {
+		schema = configschema.ModSchema()
+	} else {
+		schema = configschema.ConfigSchema(cmd.Root())
+	}
+	jsonOutput, err := json.MarshalIndent(schema, "", "  ")
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(string(jsonOutput))
+}
+
+func configEncryptCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:         "encrypt [value]",
+		Annotations: map[string]string{audit.SensitiveArgsAnnotation: "true"},
+		Args:        cobra.MaximumNArgs(1),
+		Run:         runConfigEncryptCmd,
+		Short:       "Encrypt a config value",
+		Long: `Encrypt a config value.
+
+The ciphertext is output, to be set in a config file with the encrypted function, e.g.
+
+  workspace "prod" {
+    pipes_token = encrypted("age:YWdlLWVuY3J5cHRpb24ub3JnL3Yx...")
+  }
+
+If no value is passed, it is read from stdin. A recipient is an age recipient (age1...), which may be repeated, or
+a single AWS KMS key ID, alias or ARN, prefixed with aws-kms:. If no recipient is passed, the value is encrypted for
+the age identities of POWERPIPE_AGE_IDENTITY and POWERPIPE_AGE_IDENTITY_FILE, which are used to decrypt values.
+
+Example:
+
+  # Encrypt a token for an age recipient
+  powerpipe config encrypt --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p tpt_xxx
+
+  # Encrypt a connection string from stdin with a KMS key
+  echo -n "$DATABASE_URL" | powerpipe config encrypt --recipient aws-kms:alias/powerpipe`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for encrypt", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringArrayFlag(localconstants.ArgRecipient, nil, "Encrypt for this recipient: an age recipient (age1...) or an AWS KMS key (aws-kms:<key>)")
+	return cmd
+}
+
+func runConfigEncryptCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runConfigEncryptCmd")
+	defer func() {
+		utils.LogTime("cmd.runConfigEncryptCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	var plaintext string
+	if len(args) > 0 {
+		plaintext = args[0]
+	} else {
+		b, err := io.ReadAll(os.Stdin)
+		error_helpers.FailOnError(err)
+		plaintext = string(b)
+	}
+
+	recipients := viper.GetStringSlice(localconstants.ArgRecipient)
+	if len(recipients) == 0 {
+		identities, err := encryption.AgeIdentities()
+		error_helpers.FailOnError(err)
+		if len(identities) == 0 {
+			error_helpers.FailOnError(fmt.Errorf("no recipients - pass --%s, or set %s", localconstants.ArgRecipient, localconstants.EnvAgeIdentity))
+		}
+		recipients = encryption.AgeRecipients(identities)
+	}
+
+	ciphertext, err := encryption.Encrypt(ctx, plaintext, recipients)
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(ciphertext)
+}
+
+func configDecryptCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:         "decrypt <ciphertext>",
+		Annotations: map[string]string{audit.SensitiveArgsAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		Run:         runConfigDecryptCmd,
+		Short:       "Decrypt a config value",
+		Long: `Decrypt a config value encrypted by config encrypt.
+
+An age value is decrypted with the age identities of POWERPIPE_AGE_IDENTITY and POWERPIPE_AGE_IDENTITY_FILE, and an
+AWS KMS value with the AWS credentials of the environment.
+
+Example:
+
+  # Decrypt a value
+  powerpipe config decrypt age:YWdlLWVuY3J5cHRpb24ub3JnL3Yx...`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for decrypt", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runConfigDecryptCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runConfigDecryptCmd")
+	defer func() {
+		utils.LogTime("cmd.runConfigDecryptCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	plaintext, err := encryption.Decrypt(ctx, args[0])
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(plaintext)
+}
+
+func buildConfigValidateSummary(issues []*configvalidate.Issue) string {
+	if len(issues) == 0 {
+		return "Config is valid."
+	}
+	var b strings.Builder
+	var errorCount int
+	for _, i := range issues {
+		if i.Severity == configvalidate.SeverityError {
+			errorCount++
+		}
+		if location := i.Location(); location != "" {
+			fmt.Fprintf(&b, "%s: ", location)
+		}
+		fmt.Fprintf(&b, "%s: ", i.Severity)
+		if i.Profile != "" {
+			fmt.Fprintf(&b, "workspace %s: ", i.Profile)
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", i.Message, i.Rule)
+	}
+	warningCount := len(issues) - errorCount
+	fmt.Fprintf(&b, "\n%d %s, %d %s", errorCount, utils.Pluralize("error", errorCount), warningCount, utils.Pluralize("warning", warningCount))
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/connection"
+	"github.com/turbot/powerpipe/internal/connectiontest"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/display"
+	"sigs.k8s.io/yaml"
+)
+
+func connectionCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "connection [command]",
+		Args:  cobra.NoArgs,
+		Short: "Powerpipe connection management",
+		Long: `Powerpipe connection management.
+
+Connections are named databases, defined in connection blocks in .ppc files in the config path.
+
+Examples:
+
+    # Test all connections
+    powerpipe connection test`,
+	}
+	cmd.AddCommand(connectionTestCmd())
+	cmd.Flags().BoolP("help", "h", false, "Help for connection")
+
+	return cmd
+}
+
+func connectionTestCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "test [flags] [connection names...]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runConnectionTestCmd,
+		Short: "Test the configured database connections",
+		Long: `Test the configured database connections.
+
+Each connection is tested by connecting to the database (verifying connectivity and credentials), reading the
+server version and counting the schemas visible to the connection user.
+
+The command exits with code 67 if any connection test fails.
+
+Example:
+
+  # Test all connections
+  powerpipe connection test
+
+  # Test the named connections, outputting the results as JSON
+  powerpipe connection test prod_steampipe warehouse --output json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for test", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, 30, "The timeout, in seconds, for testing each connection").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func runConnectionTestCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runConnectionTestCmd")
+	defer func() {
+		utils.LogTime("cmd.runConnectionTestCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	names, err := connectionNames(args)
+	error_helpers.FailOnError(err)
+
+	results := make([]*connectiontest.Result, len(names))
+	var failed int
+	timeout := time.Duration(viper.GetInt(constants.ArgDatabaseQueryTimeout)) * time.Second
+	for i, name := range names {
+		testCtx, cancel := context.WithTimeout(ctx, timeout)
+		results[i] = connectiontest.Run(testCtx, name)
+		cancel()
+		if !results[i].Passed() {
+			failed++
+		}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(results, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(results)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		showConnectionTestResults(results, failed)
+	}
+
+	if failed > 0 {
+		exitCode = localconstants.ExitCodeConnectionTestFailed
+	}
+}
+
+// connectionNames returns the names of the connections to test - the named connections (which may be given as
+// references, e.g. connection.prod), or all connections if no names are given
+func connectionNames(args []string) ([]string, error) {
+	if len(args) == 0 {
+		names := connection.Names()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no connections are defined in the config path")
+		}
+		return names, nil
+	}
+	names := make([]string, len(args))
+	for i, arg := range args {
+		name := strings.TrimPrefix(arg, connection.ReferencePrefix)
+		if _, err := connection.Resolve(connection.ReferencePrefix + name); err != nil {
+			return nil, err
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+func showConnectionTestResults(results []*connectiontest.Result, failed int) {
+	headers := []string{"Connection", "Backend", "Status", "Version", "Schemas", "Time", "Error"}
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		schemas := ""
+		if r.Schemas != nil {
+			schemas = fmt.Sprintf("%d", *r.Schemas)
+		}
+		rows[i] = []string{r.Name, r.Backend, r.Status, r.Version, schemas, r.Duration.Round(time.Millisecond).String(), r.Error}
+	}
+	display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{HideEmptyColumns: true})
+	//nolint:forbidigo // intended output
+	fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/thediveo/enumflag/v2"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/app_specific"
-	"github.com/turbot/pipe-fittings/cloud"
 	"github.com/turbot/pipe-fittings/cmdconfig"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/contexthelpers"
@@ -28,7 +27,14 @@ import (
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardexport"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/exportbundle"
 	"github.com/turbot/powerpipe/internal/initialisation"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/redact"
+	"github.com/turbot/powerpipe/internal/snapshot"
 	"github.com/turbot/steampipe-plugin-sdk/v5/logging"
 )
 
@@ -54,7 +60,8 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddCloudFlags().
 		AddModLocationFlag().
 		AddStringArrayFlag(constants.ArgArg, nil, "Specify the value of a dashboard argument").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported format: pps (snapshot)").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: pps (snapshot), png, pdf, html, md, bundle (zip)").
+		AddStringSliceFlag(localconstants.ArgBundleFormat, nil, "The export formats included in bundle exports, as well as the snapshot").
 		AddStringFlag(constants.ArgDatabase, app_specific.DefaultDatabase, "Turbot Pipes workspace database").
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
 		AddBoolFlag(constants.ArgHelp, false, "Help for dashboard", cmdconfig.FlagOptions.WithShortHand("h")).
@@ -70,18 +77,32 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddBoolFlag(constants.ArgProgress, true, "Display dashboard execution progress respected when a dashboard name argument is passed").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a dashboard session (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for a dashboard session (comma-separated)").
+		AddBoolFlag(localconstants.ArgReadOnly, false, "Reject statements which are not queries and use read-only database sessions where supported").
+		AddBoolFlag(localconstants.ArgStartSteampipe, false, "Start the local Steampipe service if no database is specified and the service is not running").
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Turbot Pipes with the default (workspace) visibility").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Turbot Pipes with 'anyone_with_link' visibility").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
 		// NOTE: use StringArrayFlag for ArgDashboardInput, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
+		AddStringFlag(localconstants.ArgSnapshotSigningKey, "", "Path to an armored private key used to sign snapshots written to a directory or object storage").
+		AddStringFlag(localconstants.ArgSnapshotCompression, snapshot.CompressionNone, "Compression of snapshots written to a directory or object storage: none or zstd").
+		AddStringFlag(localconstants.ArgSnapshotVisibility, "", "Visibility of snapshots uploaded to Turbot Pipes: workspace, org or anyone_with_link (defaults to anyone_with_link for --share, otherwise workspace)").
+		AddStringFlag(localconstants.ArgSnapshotExpiry, "", "Expire snapshots uploaded to Turbot Pipes after a number of hours, days or weeks, e.g. 7d").
+		AddStringFlag(localconstants.ArgSnapshotWebhook, "", "URL to post the snapshot metadata to when a snapshot is uploaded to Turbot Pipes or object storage").
+		AddStringSliceFlag(localconstants.ArgRedactColumns, nil, "Columns whose values are redacted in snapshots and exports (comma-separated, may contain glob patterns)").
+		AddStringFlag(localconstants.ArgRedactMode, redact.ModeMask, "How redacted values are replaced: mask, or hash (keyed with POWERPIPE_REDACT_SALT)").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - a local file path, a Turbot Pipes workspace or an object storage URL (s3://, gs:// or azblob://)").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
 		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
-		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set the dashboard execution timeout")
+		AddStringFlag(localconstants.ArgPipesVariables, "", "Use the variable settings of a Turbot Pipes workspace (<identity>/<workspace>) for variables which are not set locally").
+		AddStringFlag(localconstants.ArgOtelEndpoint, "", "Export traces of the execution to the OpenTelemetry collector at this OTLP gRPC endpoint").
+		AddBoolFlag(localconstants.ArgOtelInsecure, false, "Connect to the OpenTelemetry collector without TLS").
+		AddStringFlag(localconstants.ArgQueryLog, "", "Record every SQL statement executed in this file (or stdout or stderr)").
+		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set the dashboard execution timeout").
+		AddStringFlag(constants.ArgTiming, constants.ArgOff, "Display query timing (with --output none); one of: off, on, verbose", cmdconfig.FlagOptions.NoOptDefVal(constants.ArgOn))
 
 	return cmd
 }
@@ -123,7 +144,9 @@ func dashboardRun(cmd *cobra.Command, args []string) {
 	initData := initialisation.NewInitData[*modconfig.Dashboard](ctx, cmd, dashboardName)
 
 	if len(viper.GetStringSlice(constants.ArgExport)) > 0 {
-		err := initData.RegisterExporters(dashboardExporters()...)
+		exporters, err := exportbundle.AppendExporter(initData.Workspace, dashboardExporters(initData.WorkspaceEvents))
+		error_helpers.FailOnError(err)
+		err = initData.RegisterExporters(exporters...)
 		error_helpers.FailOnError(err)
 
 		// validate required export formats
@@ -147,6 +170,14 @@ func dashboardRun(cmd *cobra.Command, args []string) {
 	error_helpers.FailOnError(err)
 	// display the snapshot result (if needed)
 	displaySnapshot(snap)
+	// the timing is not displayed with the snapshot, which must remain valid JSON
+	if display.TimingMode() != constants.ArgOff && viper.GetString(constants.ArgOutput) == constants.OutputFormatNone {
+		report := dashboardexecute.SnapshotTimingReport(snap)
+		display.PrintTiming(&localqueryresult.TimingMetadata{Duration: report.Duration})
+		if display.TimingMode() == constants.ArgVerbose {
+			display.PrintTimingReport(report)
+		}
+	}
 
 	// upload the snapshot (if needed)
 	err = publishSnapshotIfNeeded(ctx, snap)
@@ -159,6 +190,12 @@ func dashboardRun(cmd *cobra.Command, args []string) {
 	exportArgs := viper.GetStringSlice(constants.ArgExport)
 	exportMsg, err := initData.ExportManager.DoExport(ctx, snap.FileNameRoot, snap, exportArgs)
 	error_helpers.FailOnErrorWithMessage(err, "failed to export snapshot")
+	// with verbose timing, write the timing of the panel queries alongside the exports
+	if len(exportArgs) > 0 && display.TimingMode() == constants.ArgVerbose {
+		timingMsg, err := exportTimingReport(dashboardexecute.SnapshotTimingReport(snap))
+		error_helpers.FailOnErrorWithMessage(err, "failed to export timing report")
+		exportMsg = append(exportMsg, timingMsg)
+	}
 
 	// print the location where the file is exported
 	if len(exportMsg) > 0 && viper.GetBool(constants.ArgProgress) {
@@ -178,6 +215,10 @@ func validateDashboardArgs(ctx context.Context) error {
 		return fmt.Errorf("only one of --search-path or --search-path-prefix may be set")
 	}
 
+	if err := display.ValidateTiming(); err != nil {
+		return err
+	}
+
 	// only 1 of 'share' and 'snapshot' may be set
 	share := viper.GetBool(constants.ArgShare)
 	snapshot := viper.GetBool(constants.ArgSnapshot)
@@ -199,11 +240,19 @@ func displaySnapshot(snapshot *steampipeconfig.SteampipeSnapshot) {
 	}
 }
 
-func dashboardExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+func dashboardExporters(w *dashboardworkspace.WorkspaceEvents) []export.Exporter {
+	// png and pdf exports are rendered from the snapshot using a headless browser
+	renderer := dashboardexport.NewRenderer(w)
+	return []export.Exporter{
+		&snapshot.Exporter{},
+		dashboardexport.NewPngExporter(renderer),
+		dashboardexport.NewPdfExporter(renderer),
+		&dashboardexport.HtmlExporter{},
+		&dashboardexport.MarkdownExporter{},
+	}
 }
 
-func publishSnapshotIfNeeded(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot) error {
+func publishSnapshotIfNeeded(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot) error {
 	shouldShare := viper.GetBool(constants.ArgShare)
 	shouldUpload := viper.GetBool(constants.ArgSnapshot)
 
@@ -211,7 +260,7 @@ func publishSnapshotIfNeeded(ctx context.Context, snapshot *steampipeconfig.Stea
 		return nil
 	}
 
-	message, err := cloud.PublishSnapshot(ctx, snapshot, shouldShare)
+	message, err := snapshot.Publish(ctx, snap, shouldShare)
 	if err != nil {
 		// reword "402 Payment Required" error
 		return handlePublishSnapshotError(err)
@@ -224,7 +273,7 @@ func publishSnapshotIfNeeded(ctx context.Context, snapshot *steampipeconfig.Stea
 }
 
 func handlePublishSnapshotError(err error) error {
-	if err.Error() == "402 Payment Required" {
+	if strings.HasSuffix(err.Error(), "402 Payment Required") {
 		return fmt.Errorf("maximum number of snapshots reached")
 	}
 	return err
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/moddocs"
+	"github.com/turbot/powerpipe/internal/varprompt"
+)
+
+func docsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "docs [command]",
+		Args:  cobra.NoArgs,
+		Short: "Mod documentation",
+		Long: `Mod documentation.
+
+Example:
+
+  # Generate markdown documentation for the mod in the current directory
+  powerpipe docs generate`,
+	}
+	cmd.AddCommand(docsGenerateCmd())
+	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for docs")
+	return cmd
+}
+
+func docsGenerateCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "generate",
+		Args:  cobra.NoArgs,
+		Run:   runDocsGenerateCmd,
+		Short: "Generate markdown documentation for the mod",
+		Long: `Generate markdown documentation for the mod.
+
+The documentation is written to the output directory as a README.md index of the mod, its variables, benchmarks
+and dashboards, a page for each top level benchmark in benchmarks/ (with the tree of child benchmarks and controls,
+and the description and sql of each control) and a page for each dashboard in dashboards/ (with its inputs).
+Existing pages are overwritten, so the documentation can be regenerated and committed to the repository or
+published to a wiki.
+
+Example:
+
+  # Generate the documentation in the docs directory
+  powerpipe docs generate
+
+  # Generate the documentation in a wiki repository
+  powerpipe docs generate --output-dir ../mod.wiki`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddBoolFlag(constants.ArgHelp, false, "Help for generate", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(localconstants.ArgOutputDir, "docs", "The directory to write the documentation to").
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddStringArrayFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values")
+	return cmd
+}
+
+func runDocsGenerateCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runDocsGenerateCmd")
+	defer func() {
+		utils.LogTime("cmd.runDocsGenerateCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, viper.GetString(constants.ArgModLocation))
+	error_helpers.FailOnError(errAndWarnings.GetError())
+	if !w.ModfileExists() {
+		error_helpers.FailOnError(localconstants.ErrorNoModDefinition{})
+	}
+
+	pages := moddocs.Generate(w)
+	paths := make([]string, 0, len(pages))
+	for path := range pages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	outputDir := viper.GetString(localconstants.ArgOutputDir)
+	for _, path := range paths {
+		pagePath := filepath.Join(outputDir, filepath.FromSlash(path))
+		error_helpers.FailOnError(os.MkdirAll(filepath.Dir(pagePath), 0755))
+		error_helpers.FailOnError(os.WriteFile(pagePath, []byte(pages[path]), 0644)) //nolint:gosec // documentation is not sensitive
+	}
+	//nolint:forbidigo // intended output
+	fmt.Printf("Generated %d %s in %s.\n", len(paths), utils.Pluralize("page", len(paths)), outputDir)
+}
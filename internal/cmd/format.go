@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/modformat"
+)
+
+func formatCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "format [flags] [paths...]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runFormatCmd,
+		Short: "Format mod files",
+		Long: `Format mod files canonically.
+
+Attributes are aligned and ordered (title, description and documentation first, sql and tags last, then nested
+blocks), heredoc SQL is indented one level deeper than its attribute, and blocks and multi-line attributes are
+separated by a single blank line.
+
+The files in the mod location are formatted, unless files or directories are given. The paths of the files which
+are changed are printed. With --check, no files are changed, and the command exits with code 65 if any files are
+not formatted.
+
+Example:
+
+  # Format the mod in the current directory
+  powerpipe format
+
+  # Check the mod files are formatted, e.g. in CI
+  powerpipe format --check`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddBoolFlag(localconstants.ArgCheck, false, "Check the files are formatted, without changing them").
+		AddBoolFlag(constants.ArgHelp, false, "Help for format", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runFormatCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runFormatCmd")
+	defer func() {
+		utils.LogTime("cmd.runFormatCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{viper.GetString(constants.ArgModLocation)}
+	}
+	files, err := modformat.ModFiles(paths)
+	error_helpers.FailOnError(err)
+
+	check := viper.GetBool(localconstants.ArgCheck)
+	var unformatted int
+	for _, file := range files {
+		changed, err := modformat.FormatFile(file, !check)
+		error_helpers.FailOnError(err)
+		if changed {
+			unformatted++
+			//nolint:forbidigo // intended output
+			fmt.Println(file)
+		}
+	}
+
+	if check && unformatted > 0 {
+		exitCode = localconstants.ExitCodeFormatCheckFailed
+	}
+}
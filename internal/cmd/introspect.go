@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/pipe-fittings/versionmap"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/modintrospect"
+	"github.com/turbot/powerpipe/internal/varprompt"
+)
+
+func introspectCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "introspect",
+		Args:  cobra.NoArgs,
+		Run:   runIntrospectCmd,
+		Short: "Output the resolved resources of the mod as JSON",
+		Long: `Output the resolved resources of the mod as a single JSON document.
+
+The document contains the mod, the graph of its installed dependency mods, the benchmarks, controls, dashboards,
+queries and variables of the mod and its dependencies (with resolved variable values), and the values used for
+each tag. It is intended for tooling which builds resource catalogs and coverage reports.
+
+Example:
+
+  # Output the resolved resources of the mod in the current directory
+  powerpipe introspect
+
+  # List the titles of the controls
+  powerpipe introspect | jq '.controls[].title'`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddBoolFlag(constants.ArgHelp, false, "Help for introspect", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddStringArrayFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values")
+	return cmd
+}
+
+func runIntrospectCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runIntrospectCmd")
+	defer func() {
+		utils.LogTime("cmd.runIntrospectCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	modLocation := viper.GetString(constants.ArgModLocation)
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, modLocation)
+	error_helpers.FailOnError(errAndWarnings.GetError())
+	if !w.ModfileExists() {
+		error_helpers.FailOnError(localconstants.ErrorNoModDefinition{})
+	}
+	lock, err := versionmap.LoadWorkspaceLock(modLocation)
+	error_helpers.FailOnError(err)
+
+	doc, err := modintrospect.Introspect(w, lock)
+	error_helpers.FailOnError(err)
+	jsonOutput, err := json.MarshalIndent(doc, "", "  ")
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(string(jsonOutput))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/turbot/pipe-fittings/cmdconfig"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/error_helpers"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
 
@@ -27,7 +28,17 @@ func loginCmd() *cobra.Command {
 		
 The Powerpipe CLI can interact with Turbot Pipes to run pipelines in a remote cloud instance.		
 These capabilities require authenticating to Turbot Pipes. The powerpipe login command launches an interactive process for logging in 
-and obtaining a temporary (30 day) token. The token is written to ~/.powerpipe/internal/{cloud host}.pptt.`,
+and obtaining a temporary (30 day) token. The token is written to ~/.powerpipe/internal/{cloud host}.pptt.
+
+To log in to a self-hosted Turbot Pipes instance, set --pipes-host to its host, or to the name of a pipes credential
+which defines it:
+
+  credential "pipes" "acme" {
+    host       = "pipes.acme.internal"
+    workspaces = ["acme/*"]
+  }
+
+Workspaces matching the workspaces of a pipes credential are then resolved using its host and token.`,
 	}
 
 	cmdconfig.OnCmd(cmd).
@@ -40,6 +51,12 @@ and obtaining a temporary (30 day) token. The token is written to ~/.powerpipe/i
 func runLoginCmd(cmd *cobra.Command, _ []string) {
 	ctx := cmd.Context()
 
+	if viper.GetBool(localconstants.ArgOffline) {
+		error_helpers.ShowError(ctx, sperr.New("cannot log in to Turbot Pipes in offline mode"))
+		exitCode = constants.ExitCodeLoginCloudConnectionFailed
+		return
+	}
+
 	log.Printf("[TRACE] login, cloud host %s", viper.Get(constants.ArgPipesHost))
 	log.Printf("[TRACE] opening login web page")
 	// start login flow - this will open a web page prompting user to login, and will give the user a code to enter
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/lsp"
+)
+
+func lspCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lsp",
+		Args:  cobra.NoArgs,
+		Run:   runLspCmd,
+		Short: "Run the language server for mod files",
+		Long: `Run the language server for mod files.
+
+The language server communicates over stdin and stdout using the Language Server Protocol, and is intended to be
+started by an editor rather than run directly. It provides:
+
+  - completion of attribute and block names, and of the names of resources, variables and locals
+  - go to definition of references, including references to the resources of dependency mods
+  - diagnostics for syntax errors and broken references as files are edited, and for all mod lint issues
+    when a file is saved
+
+The mod is the root of the editor workspace, or the mod location if the editor does not provide one.
+
+Example:
+
+  # Configure an editor to start the language server for .pp files with
+  powerpipe lsp`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddBoolFlag(constants.ArgHelp, false, "Help for lsp", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runLspCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runLspCmd")
+	defer func() {
+		utils.LogTime("cmd.runLspCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	server := lsp.NewServer(viper.GetString(constants.ArgModLocation))
+	error_helpers.FailOnError(server.Run(ctx, os.Stdin, os.Stdout))
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -15,13 +19,29 @@ import (
 	cmdconfig "github.com/turbot/pipe-fittings/cmdconfig"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/filepaths"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/modinstaller"
 	"github.com/turbot/pipe-fittings/parse"
 	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/pipe-fittings/versionmap"
+	"github.com/turbot/pipe-fittings/workspace"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/db_client"
 	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/modcompat"
+	"github.com/turbot/powerpipe/internal/moddiff"
+	"github.com/turbot/powerpipe/internal/modgraph"
+	"github.com/turbot/powerpipe/internal/modlint"
+	"github.com/turbot/powerpipe/internal/modlock"
+	"github.com/turbot/powerpipe/internal/modpublish"
+	"github.com/turbot/powerpipe/internal/modreplace"
+	"github.com/turbot/powerpipe/internal/modsearch"
+	"github.com/turbot/powerpipe/internal/modsignature"
+	"github.com/turbot/powerpipe/internal/modtemplate"
+	"github.com/turbot/powerpipe/internal/modvendor"
+	"github.com/turbot/powerpipe/internal/varprompt"
+	"sigs.k8s.io/yaml"
 )
 
 func modCmd() *cobra.Command {
@@ -51,6 +71,24 @@ Examples:
     
     # Uninstall a mod
     powerpipe mod uninstall github.com/turbot/steampipe-mod-aws-compliance 
+
+    # Vendor the installed mods so they can be installed without Git access
+    powerpipe mod vendor
+
+    # Search for mods in the public registry
+    powerpipe mod search aws
+
+    # Publish a release of the mod in the current directory
+    powerpipe mod publish v1.2.0 --push
+
+    # Show the dependency graph of the installed mods as a Mermaid flowchart
+    powerpipe mod graph --output mermaid
+
+    # Check the mod in the current directory for problems
+    powerpipe mod lint
+
+    # Check for breaking changes since the v1.2.0 release
+    powerpipe mod breaking v1.2.0
 	`,
 	}
 	cmd.AddCommand(modInstallCmd(),
@@ -59,6 +97,12 @@ Examples:
 		modListCmd(),
 		showCmd[*modconfig.Mod](),
 		modInitCmd(),
+		modVendorCmd(),
+		modSearchCmd(),
+		modPublishCmd(),
+		modGraphCmd(),
+		modLintCmd(),
+		modBreakingCmd(),
 	)
 
 	cmd.Flags().BoolP("help", "h", false, "Help for mod")
@@ -91,7 +135,19 @@ Examples:
   powerpipe mod install
 
   # Preview what powerpipw mod install will do, without actually installing anything
-  powerpipe mod install --dry-run`,
+  powerpipe mod install --dry-run
+
+  # Install the mods previously vendored using powerpipe mod vendor, without Git access
+  powerpipe mod install --vendor
+
+  # Install exactly the mod versions in the lock file, failing if the lock file is out of date (e.g. in CI)
+  powerpipe mod install --frozen
+
+  # Install all mods, using a local copy of a dependency to test changes to it before they are published
+  powerpipe mod install --replace github.com/turbot/steampipe-mod-aws-compliance=../steampipe-mod-aws-compliance
+
+  # Install all mods, failing if any are not signed by a key in the trusted keyring
+  powerpipe mod install --verify-signatures fail --trusted-keys ~/.powerpipe/trusted.asc`,
 	}
 
 	// default update strategy to minimal for mod install
@@ -107,6 +163,11 @@ Examples:
 		AddVarFlag(enumflag.New(&updateStrategy, constants.ArgPull, constants.ModUpdateStrategyIds, enumflag.EnumCaseInsensitive),
 			constants.ArgPull,
 			fmt.Sprintf("Update strategy; one of: %s", strings.Join(constants.FlagValues(constants.ModUpdateStrategyIds), ", "))).
+		AddBoolFlag(localconstants.ArgVendor, false, "Install the mods in the vendor directory rather than from Git").
+		AddBoolFlag(localconstants.ArgFrozen, false, "Fail if the lock file would change or an installed mod does not match its recorded hash").
+		AddStringSliceFlag(localconstants.ArgReplace, nil, "Replace a dependency with a local directory, in the form <mod name>=<path> (replacements apply until the next mod install)").
+		AddStringFlag(localconstants.ArgVerifySignatures, modsignature.PolicyOff, fmt.Sprintf("Verify the signatures of installed mods; one of: %s", strings.Join(modsignature.Policies, ", "))).
+		AddStringFlag(localconstants.ArgTrustedKeys, "", "Path to a file of armored public keys trusted to sign mods").
 		AddModLocationFlag()
 
 	return cmd
@@ -129,6 +190,45 @@ func runModInstallCmd(cmd *cobra.Command, args []string) {
 	workspaceMod, err := parse.LoadModfile(workspacePath)
 	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
 
+	// if installing from the vendor directory, the mods to install are determined by the lock file
+	if viper.GetBool(localconstants.ArgVendor) {
+		if len(args) > 0 {
+			error_helpers.FailOnError(fmt.Errorf("mods cannot be specified when installing from the vendor directory"))
+		}
+		if workspaceMod == nil {
+			error_helpers.FailOnError(fmt.Errorf("the vendor directory can only be installed into an existing mod"))
+		}
+		previousLock, err := modlock.Load(workspacePath)
+		error_helpers.FailOnError(err)
+		// the vendored mods replace all installed mods, including any local replacements
+		error_helpers.FailOnError(modreplace.Restore(workspacePath, nil))
+		installed, err := modvendor.Restore(workspacePath)
+		error_helpers.FailOnError(err)
+		recordModHashes(workspacePath, previousLock)
+		//nolint:forbidigo // intended output
+		fmt.Println(buildVendorSummary("Installed", installed, "from the vendor directory"))
+		return
+	}
+
+	// validate the signature policy and load the trusted keys before installing anything
+	signaturePolicy, trustedKeys, err := getSignatureVerificationConfig()
+	error_helpers.FailOnError(err)
+
+	// load the current lock file - this is used to verify the hashes of the installed mods
+	previousLock, err := modlock.Load(workspacePath)
+	error_helpers.FailOnError(err)
+	frozen := viper.GetBool(localconstants.ArgFrozen)
+	if frozen {
+		error_helpers.FailOnError(validateFrozenInstall(workspaceMod, previousLock, args))
+	}
+
+	// remove any previous replacements which are no longer required, so the locked versions are reinstalled
+	replacements, err := modreplace.Parse(viper.GetStringSlice(localconstants.ArgReplace))
+	error_helpers.FailOnError(err)
+	if !viper.GetBool(constants.ArgDryRun) {
+		error_helpers.FailOnError(modreplace.Restore(workspacePath, replacements))
+	}
+
 	// if no mod was loaded, create a default
 	if workspaceMod == nil {
 		workspaceMod, err = createWorkspaceMod(ctx, cmd, workspacePath)
@@ -142,16 +242,182 @@ func runModInstallCmd(cmd *cobra.Command, args []string) {
 	installOpts := modinstaller.NewInstallOpts(workspaceMod, args...)
 	installOpts.PluginVersions = getPluginVersions(ctx)
 
+	rollback, pruneVerified := prepareSignatureRollback(workspacePath, signaturePolicy)
 	installData, err := modinstaller.InstallWorkspaceDependencies(ctx, installOpts)
 	if err != nil {
 		// exitCode = constants.ExitCodeModInstallFailed
 		error_helpers.FailOnError(err)
 	}
+	if !viper.GetBool(constants.ArgDryRun) {
+		if frozen {
+			checkLockUnchanged(workspacePath, previousLock)
+		}
+		recordModHashes(workspacePath, previousLock)
+		verifyModSignatures(workspacePath, signaturePolicy, trustedKeys, rollback)
+		pruneVerified()
+	}
 
 	summary := modinstaller.BuildInstallSummary(installData)
 	// tactical: remove trailing newline
 	summary = strings.TrimRight(summary, "\n")
 	fmt.Println(summary) //nolint:forbidigo // intended output
+
+	if len(replacements) > 0 && !viper.GetBool(constants.ArgDryRun) {
+		replaced, err := modreplace.Apply(workspacePath, replacements)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(buildVendorSummary("Replaced", replaced, "with local directories"))
+	}
+
+	if !viper.GetBool(constants.ArgDryRun) {
+		warnMissingVariables(ctx, workspacePath)
+	}
+}
+
+// warnMissingVariables warns if the mod or the installed dependency mods have required variables which have not been
+// set, so these are reported when the mods are installed rather than when they are first run
+func warnMissingVariables(ctx context.Context, workspacePath string) {
+	_, errAndWarnings := workspace.Load(ctx, workspacePath)
+	missing, ok := varprompt.MissingVariables(errAndWarnings.GetError(), workspacePath)
+	if !ok {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "the mod and its dependencies have %d required %s with no value set:", len(missing.Variables), utils.Pluralize("variable", len(missing.Variables)))
+	for i, v := range missing.Variables {
+		fmt.Fprintf(&b, "\n  %s", varprompt.Describe(missing.Names[i], v))
+	}
+	b.WriteString("\nThese are prompted for when the mod is run, or may be set with --var, --var-file or a .ppvars file")
+	error_helpers.ShowWarning(b.String())
+}
+
+// validateFrozenInstall checks a frozen install is possible - the workspace must have a lock file in which every
+// installed mod has a recorded hash
+func validateFrozenInstall(workspaceMod *modconfig.Mod, lock modlock.Lock, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("mods cannot be specified when using --%s", localconstants.ArgFrozen)
+	}
+	if workspaceMod == nil || len(lock) == 0 {
+		return fmt.Errorf("--%s requires an existing lock file - run 'powerpipe mod install' to create it", localconstants.ArgFrozen)
+	}
+	if unhashed := lock.Unhashed(); len(unhashed) > 0 {
+		return fmt.Errorf("the lock file does not have hashes for %s - run 'powerpipe mod install' to record them", strings.Join(unhashed, ", "))
+	}
+	return nil
+}
+
+// checkLockUnchanged fails if the install changed the resolved versions in the lock file, restoring the previous lock
+func checkLockUnchanged(workspacePath string, previousLock modlock.Lock) {
+	lock, err := modlock.Load(workspacePath)
+	error_helpers.FailOnError(err)
+	if lock.Equal(previousLock) {
+		return
+	}
+	error_helpers.FailOnErrorWithMessage(previousLock.Save(workspacePath), "failed to restore the lock file")
+	error_helpers.FailOnError(fmt.Errorf("the lock file is out of date with the mod requirements - run 'powerpipe mod install' without --%s to update it", localconstants.ArgFrozen))
+}
+
+// recordModHashes records the hashes of the installed mods in the lock file, failing if any installed mod does not
+// match its previously recorded hash - those mods are removed so they cannot be loaded
+func recordModHashes(workspacePath string, previousLock modlock.Lock) {
+	// mods replaced by local directories are not hashed
+	replaced, err := modreplace.Replaced(workspacePath)
+	error_helpers.FailOnError(err)
+	mismatched, err := modlock.RecordHashes(workspacePath, previousLock, replaced)
+	error_helpers.FailOnErrorWithMessage(err, "failed to record mod hashes")
+	if len(mismatched) == 0 {
+		return
+	}
+	for _, m := range mismatched {
+		error_helpers.FailOnError(os.RemoveAll(filepath.Join(filepaths.WorkspaceModPath(workspacePath), m)))
+	}
+	error_helpers.FailOnError(fmt.Errorf("the content of %s does not match the hash in the lock file - the mods have been removed", strings.Join(mismatched, ", ")))
+}
+
+// getSignatureVerificationConfig returns the signature verification policy and, if verification is enabled,
+// the armored keyring of trusted keys
+func getSignatureVerificationConfig() (string, string, error) {
+	policy := strings.ToLower(viper.GetString(localconstants.ArgVerifySignatures))
+	if !helpers.StringSliceContains(modsignature.Policies, policy) {
+		return "", "", fmt.Errorf("invalid value for --%s: %s - must be one of: %s", localconstants.ArgVerifySignatures, policy, strings.Join(modsignature.Policies, ", "))
+	}
+	if policy == modsignature.PolicyOff {
+		return policy, "", nil
+	}
+	keyRing, err := modsignature.LoadKeyRing(viper.GetString(localconstants.ArgTrustedKeys))
+	if err != nil {
+		return "", "", fmt.Errorf("--%s %s: %w", localconstants.ArgVerifySignatures, policy, err)
+	}
+	return policy, keyRing, nil
+}
+
+// prepareSignatureRollback records the state of the workspace before mods are installed with the fail policy, so the
+// install can be undone if any installed mod fails verification. Unused mods are not pruned by the install, so the
+// mods of the previous lock file are still installed if it is undone - the returned func prunes them once the mods
+// have been verified
+func prepareSignatureRollback(workspacePath, policy string) (*modsignature.Rollback, func()) {
+	if policy != modsignature.PolicyFail || viper.GetBool(constants.ArgDryRun) {
+		return nil, func() {}
+	}
+	rollback, err := modsignature.NewRollback(workspacePath)
+	error_helpers.FailOnErrorWithMessage(err, "failed to record the installed mods")
+
+	prune := viper.GetBool(constants.ArgPrune)
+	viper.Set(constants.ArgPrune, false)
+	return rollback, func() {
+		viper.Set(constants.ArgPrune, prune)
+		if prune {
+			pruneMods(workspacePath)
+		}
+	}
+}
+
+// pruneMods removes the installed mods which are not in the lock file
+func pruneMods(workspacePath string) {
+	workspaceMod, err := parse.LoadModfile(workspacePath)
+	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
+	if workspaceMod == nil {
+		return
+	}
+	installer, err := modinstaller.NewModInstaller(modinstaller.NewInstallOpts(workspaceMod))
+	error_helpers.FailOnError(err)
+	_, err = installer.Prune()
+	error_helpers.FailOnErrorWithMessage(err, "failed to remove unused mods")
+}
+
+// verifyModSignatures verifies the signatures of the installed mods according to the policy
+// - for the warn policy, a warning is shown for each mod which fails verification
+// - for the fail policy, the install is undone (see prepareSignatureRollback) and the command fails
+func verifyModSignatures(workspacePath, policy, keyRing string, rollback *modsignature.Rollback) {
+	if policy == modsignature.PolicyOff {
+		return
+	}
+	results, err := modsignature.VerifyInstalled(workspacePath, keyRing)
+	error_helpers.FailOnErrorWithMessage(err, "failed to verify mod signatures")
+	// mods replaced by local directories are not signed releases, so are not verified
+	replaced, err := modreplace.Replaced(workspacePath)
+	error_helpers.FailOnError(err)
+	results = slices.DeleteFunc(results, func(r modsignature.Result) bool { return replaced[r.Mod] })
+
+	var failed []string
+	for _, r := range results {
+		if r.Error != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", r.Mod, r.Error.Error()))
+			continue
+		}
+		slog.Debug("verified mod signature", "mod", r.Mod, "signer", r.Signer)
+	}
+	if len(failed) == 0 {
+		return
+	}
+	if policy == modsignature.PolicyWarn {
+		for _, f := range failed {
+			error_helpers.ShowWarning(fmt.Sprintf("mod signature verification failed for %s", f))
+		}
+		return
+	}
+	error_helpers.FailOnErrorWithMessage(rollback.Undo(), "failed to undo the install of unverified mods")
+	error_helpers.FailOnError(fmt.Errorf("mod signature verification failed - the install has been undone:\n  %s", strings.Join(failed, "\n  ")))
 }
 
 func getPluginVersions(ctx context.Context) *modconfig.PluginVersionMap {
@@ -217,10 +483,18 @@ func runModUninstallCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// the installer rewrites the lock file without hashes, so load them to restore after uninstalling
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	previousLock, err := modlock.Load(workspacePath)
+	error_helpers.FailOnError(err)
+
 	opts := modinstaller.NewInstallOpts(workspaceMod, args...)
 
 	installData, err := modinstaller.UninstallWorkspaceDependencies(ctx, opts)
 	error_helpers.FailOnError(err)
+	if !viper.GetBool(constants.ArgDryRun) {
+		recordModHashes(workspacePath, previousLock)
+	}
 	//nolint:forbidigo // acceptable
 	fmt.Println(modinstaller.BuildUninstallSummary(installData))
 }
@@ -238,7 +512,10 @@ Example:
   powerpipe mod update github.com/turbot/steampipe-mod-aws-compliance
 
   # Update all mods specified in the mod.pp and their dependencies to the latest versions that meet their constraints, and install any that are missing
-  powerpipe mod update`,
+  powerpipe mod update
+
+  # Preview which dependency versions would change, including the tags released in between, without updating
+  powerpipe mod update --dry-run`,
 	}
 
 	// variable used to assign the output mode flag
@@ -252,6 +529,8 @@ Example:
 		AddVarFlag(enumflag.New(&updateStrategy, constants.ArgPull, constants.ModUpdateStrategyIds, enumflag.EnumCaseInsensitive),
 			constants.ArgPull,
 			fmt.Sprintf("Update strategy; one of: %s", strings.Join(constants.FlagValues(constants.ModUpdateStrategyIds), ", "))).
+		AddStringFlag(localconstants.ArgVerifySignatures, modsignature.PolicyOff, fmt.Sprintf("Verify the signatures of installed mods; one of: %s", strings.Join(modsignature.Policies, ", "))).
+		AddStringFlag(localconstants.ArgTrustedKeys, "", "Path to a file of armored public keys trusted to sign mods").
 		AddModLocationFlag()
 
 	return cmd
@@ -278,14 +557,55 @@ func runModUpdateCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	signaturePolicy, trustedKeys, err := getSignatureVerificationConfig()
+	error_helpers.FailOnError(err)
+	previousLock, err := modlock.Load(workspacePath)
+	error_helpers.FailOnError(err)
+	// the current replacements are reapplied to the updated versions
+	replacements, err := modreplace.Current(workspacePath)
+	error_helpers.FailOnError(err)
+
 	opts := modinstaller.NewInstallOpts(workspaceMod, args...)
 
 	// do this update
+	rollback, pruneVerified := prepareSignatureRollback(workspacePath, signaturePolicy)
 	installData, err := modinstaller.InstallWorkspaceDependencies(ctx, opts)
 	error_helpers.FailOnError(err)
+	if !viper.GetBool(constants.ArgDryRun) {
+		recordModHashes(workspacePath, previousLock)
+		verifyModSignatures(workspacePath, signaturePolicy, trustedKeys, rollback)
+		pruneVerified()
+		_, err := modreplace.Apply(workspacePath, replacements)
+		error_helpers.FailOnError(err)
+	}
 
 	//nolint:forbidigo // acceptable
 	fmt.Println(modinstaller.BuildInstallSummary(installData))
+
+	// for a dry run, show how the version of each dependency would change, so the update can be reviewed
+	if viper.GetBool(constants.ArgDryRun) {
+		if report := buildVersionChangeReport(previousLock.InstallCache(), installData.NewLock.InstallCache); report != "" {
+			//nolint:forbidigo // acceptable
+			fmt.Println(report)
+		}
+	}
+}
+
+// buildVersionChangeReport returns a report of the dependency versions which change between the locks, including
+// the tags released between the old and new versions
+func buildVersionChangeReport(from, to versionmap.InstalledDependencyVersionsMap) string {
+	changes := moddiff.Diff(from, to)
+	if len(changes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Version changes:\n\n")
+	for _, c := range changes {
+		c.LoadTags()
+		fmt.Fprintf(&b, "  %s\n", c.String())
+	}
+	return strings.TrimRight(b.String(), "\n")
 }
 
 // list
@@ -352,21 +672,103 @@ func runModListCmd(cmd *cobra.Command, _ []string) {
 	fmt.Println(treeString)
 }
 
+func modVendorCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "vendor",
+		Run:   runModVendorCmd,
+		Short: "Copy the installed mods into the vendor directory",
+		Long: `Copy the installed mods into the vendor directory.
+
+The vendor directory can be committed along with the mod, so that the mods can be installed without Git access,
+for example on air-gapped CI runners, using powerpipe mod install --vendor.
+
+Example:
+
+  # Vendor the installed mods
+  powerpipe mod vendor`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for vendor", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModVendorCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModVendorCmd")
+	defer func() {
+		utils.LogTime("cmd.runModVendorCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	workspaceMod, err := parse.LoadModfile(workspacePath)
+	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
+	if workspaceMod == nil {
+		//nolint:forbidigo // acceptable output
+		fmt.Println("No mods installed.")
+		return
+	}
+
+	// replaced mods are symlinks to local directories, which cannot be vendored
+	replaced, err := modreplace.Replaced(workspacePath)
+	error_helpers.FailOnError(err)
+	if len(replaced) > 0 {
+		error_helpers.FailOnError(fmt.Errorf("mods cannot be vendored while dependencies are replaced with local directories - run 'powerpipe mod install' to restore them"))
+	}
+
+	vendored, err := modvendor.Vendor(workspacePath)
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(buildVendorSummary("Vendored", vendored, fmt.Sprintf("into %s", modvendor.VendorPath(workspacePath))))
+}
+
+func buildVendorSummary(verb string, mods []string, location string) string {
+	if len(mods) == 0 {
+		return "No mods installed."
+	}
+	var b strings.Builder
+	modStr := "mods"
+	if len(mods) == 1 {
+		modStr = "mod"
+	}
+	fmt.Fprintf(&b, "%s %d %s %s:\n", verb, len(mods), modStr, location)
+	for _, m := range mods {
+		fmt.Fprintf(&b, "  - %s\n", m)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
 func modInitCmd() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "init",
 		Run:   runModInitCmd,
 		Short: "Initialize the current directory with a mod.pp file",
 		Long: `Initialize the current directory with a mod.pp file.
+
+Use --template to scaffold an example mod instead of an empty mod definition. The templates generate example
+resources, variables, a folder structure and tests with fixture data:
+
+  benchmark:  a benchmark with controls and queries
+  dashboard:  a dashboard with inputs, cards and a table
+  detection:  detection controls which alarm on suspicious events
 		
 Example:
 
   # Initialize the current directory with a mod.pp file
-  powerpipe mod init`,
+  powerpipe mod init
+
+  # Initialize the current directory with an example benchmark mod
+  powerpipe mod init --template benchmark`,
 	}
 
 	cmdconfig.OnCmd(cmd).
 		AddBoolFlag(constants.ArgHelp, false, "Help for init", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(localconstants.ArgTemplate, "", fmt.Sprintf("Create the mod from a template; one of: %s", strings.Join(modtemplate.Names(), ", "))).
 		AddModLocationFlag()
 	return cmd
 }
@@ -383,12 +785,47 @@ func runModInitCmd(cmd *cobra.Command, args []string) {
 		}
 	}()
 	workspacePath := viper.GetString(constants.ArgModLocation)
+	if templateName := viper.GetString(localconstants.ArgTemplate); templateName != "" {
+		if err := createTemplateMod(ctx, cmd, workspacePath, templateName); err != nil {
+			exitCode = constants.ExitCodeModInitFailed
+			error_helpers.FailOnError(err)
+		}
+		return
+	}
 	if _, err := createWorkspaceMod(ctx, cmd, workspacePath); err != nil {
 		exitCode = constants.ExitCodeModInitFailed
 		error_helpers.FailOnError(err)
 	}
 }
 
+// createTemplateMod creates a mod in the workspace from the named template
+func createTemplateMod(ctx context.Context, cmd *cobra.Command, workspacePath, templateName string) error {
+	if !modinstaller.ValidateModLocation(ctx, workspacePath) {
+		return fmt.Errorf("mod %s cancelled", cmd.Name())
+	}
+	if _, exists := parse.ModFileExists(workspacePath); exists {
+		error_helpers.ShowWarning("Working folder already contains a mod definition file")
+		return nil
+	}
+	absPath, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return err
+	}
+	files, err := modtemplate.Create(templateName, workspacePath, modtemplate.NewData(absPath))
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Created %s mod in '%s':\n", templateName, absPath))
+	for _, f := range files {
+		b.WriteString(fmt.Sprintf("  - %s\n", f))
+	}
+	//nolint:forbidigo // intended output
+	fmt.Print(b.String())
+	return nil
+}
+
 func createWorkspaceMod(ctx context.Context, cmd *cobra.Command, workspacePath string) (*modconfig.Mod, error) {
 	if !modinstaller.ValidateModLocation(ctx, workspacePath) {
 		return nil, fmt.Errorf("mod %s cancelled", cmd.Name())
@@ -416,3 +853,476 @@ func createWorkspaceMod(ctx context.Context, cmd *cobra.Command, workspacePath s
 
 	return mod, nil
 }
+
+func modSearchCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "search [flags] [text]",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runModSearchCmd,
+		Short: "Search for mods in the public registry and private indexes",
+		Long: `Search for mods in the public registry and private indexes.
+
+Mods are matched by text in their name, title or description, and optionally by tag and maintainer.
+Private indexes are JSON files, read from a URL or local path, listing the mods which may be installed.
+
+Example:
+
+  # Search the public registry for AWS mods
+  powerpipe mod search aws
+
+  # Search for mods with the cis tag, maintained by turbot
+  powerpipe mod search --tag cis --maintainer turbot
+
+  # Search the public registry and a private index
+  powerpipe mod search compliance --index hub --index https://mods.acme.com/index.json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for search", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringSliceFlag(localconstants.ArgModIndex, []string{modsearch.PublicRegistry}, fmt.Sprintf("The indexes to search - '%s' for the public registry, or the URL or path of an index file", modsearch.PublicRegistry)).
+		AddStringSliceFlag(localconstants.ArgTag, nil, "Only show mods with this tag").
+		AddStringFlag(localconstants.ArgMaintainer, "", "Only show mods with this maintainer").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func runModSearchCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModSearchCmd")
+	defer func() {
+		utils.LogTime("cmd.runModSearchCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	query := modsearch.Query{
+		Tags:       viper.GetStringSlice(localconstants.ArgTag),
+		Maintainer: viper.GetString(localconstants.ArgMaintainer),
+	}
+	if len(args) > 0 {
+		query.Text = args[0]
+	}
+
+	// the indexes may be set as a comma separated list in the environment
+	var indexes []string
+	for _, index := range viper.GetStringSlice(localconstants.ArgModIndex) {
+		for _, i := range strings.Split(index, ",") {
+			if i = strings.TrimSpace(i); i != "" {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	// in offline mode, only local index files are searched
+	if viper.GetBool(localconstants.ArgOffline) {
+		var localIndexes []string
+		for _, index := range indexes {
+			if !modsearch.IsRemote(index) {
+				localIndexes = append(localIndexes, index)
+			}
+		}
+		if len(localIndexes) == 0 {
+			error_helpers.FailOnError(fmt.Errorf("only local index files can be searched in offline mode - set --%s to the path of an index file", localconstants.ArgModIndex))
+		}
+		indexes = localIndexes
+	}
+
+	mods, err := modsearch.Search(ctx, indexes, query)
+	if err != nil {
+		// show the results from the indexes which could be searched
+		if len(mods) == 0 {
+			error_helpers.FailOnError(err)
+		}
+		error_helpers.ShowWarning(err.Error())
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(mods, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(mods)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		//nolint:forbidigo // intended output
+		fmt.Println(buildSearchSummary(mods))
+	}
+}
+
+func buildSearchSummary(mods []*modsearch.Mod) string {
+	if len(mods) == 0 {
+		return "No mods found."
+	}
+	var b strings.Builder
+	for _, m := range mods {
+		b.WriteString(m.Name)
+		if m.Version != "" {
+			fmt.Fprintf(&b, " (v%s)", strings.TrimPrefix(m.Version, "v"))
+		}
+		b.WriteString("\n")
+		if m.Description != "" {
+			fmt.Fprintf(&b, "  %s\n", m.Description)
+		}
+		if len(m.Tags) > 0 {
+			fmt.Fprintf(&b, "  Tags: %s\n", strings.Join(m.Tags, ", "))
+		}
+		if len(m.Maintainers) > 0 {
+			fmt.Fprintf(&b, "  Maintainers: %s\n", strings.Join(m.Maintainers, ", "))
+		}
+		fmt.Fprintf(&b, "  Install: %s\n\n", m.InstallCommand())
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func modPublishCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "publish [flags] <version>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runModPublishCmd,
+		Short: "Validate and publish a release of the mod",
+		Long: `Validate and publish a release of the mod.
+
+The mod must have no lint errors (see powerpipe mod lint), and must have a title and description. The Git
+worktree must be clean and the version must be greater than all previously released versions. The release is
+published by creating an annotated version tag on the current commit, which can be pushed to the remote, and
+optionally added to a private mod index file (see powerpipe mod search). Breaking changes since the previous
+release (see powerpipe mod breaking) are reported as warnings, unless the release is a new major version.
+
+Example:
+
+  # Check the mod is ready to be released as v1.2.0, without tagging it
+  powerpipe mod publish v1.2.0 --dry-run
+
+  # Tag the v1.2.0 release and push the tag
+  powerpipe mod publish v1.2.0 --push
+
+  # Tag and push the release, and add it to a private index
+  powerpipe mod publish v1.2.0 --push --index-file ../mod-index/index.json --maintainer platform-team`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for publish", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgDryRun, false, "Validate the mod and release without tagging it").
+		AddBoolFlag(localconstants.ArgPush, false, "Push the release tag to the remote").
+		AddStringFlag(localconstants.ArgRemote, "origin", "The Git remote to push the release tag to, and to derive the mod name from").
+		AddStringFlag(localconstants.ArgIndexFile, "", "Path of a mod index file to add the release to").
+		AddStringSliceFlag(localconstants.ArgMaintainer, nil, "The maintainers of the mod, recorded in the index").
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModPublishCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModPublishCmd")
+	defer func() {
+		utils.LogTime("cmd.runModPublishCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	release, err := modpublish.Prepare(ctx, viper.GetString(constants.ArgModLocation), args[0], viper.GetString(localconstants.ArgRemote))
+	error_helpers.FailOnError(err)
+	for _, warning := range release.Warnings {
+		error_helpers.ShowWarning(warning)
+	}
+	indexPath := viper.GetString(localconstants.ArgIndexFile)
+	if indexPath != "" && release.Name == "" {
+		error_helpers.FailOnError(fmt.Errorf("cannot add the release to the index - the mod name could not be determined from the Git remote '%s'", viper.GetString(localconstants.ArgRemote)))
+	}
+	//nolint:forbidigo // intended output
+	fmt.Printf("Mod '%s' is valid for release %s.\n", release.Mod.ShortName, release.TagName())
+	if viper.GetBool(constants.ArgDryRun) {
+		return
+	}
+
+	error_helpers.FailOnError(release.Tag())
+	//nolint:forbidigo // intended output
+	fmt.Printf("Tagged %s.\n", release.TagName())
+
+	if viper.GetBool(localconstants.ArgPush) {
+		error_helpers.FailOnError(release.Push(ctx))
+		//nolint:forbidigo // intended output
+		fmt.Printf("Pushed %s to %s.\n", release.TagName(), viper.GetString(localconstants.ArgRemote))
+	}
+
+	if indexPath != "" {
+		error_helpers.FailOnError(release.UpdateIndex(indexPath, viper.GetStringSlice(localconstants.ArgMaintainer)))
+		//nolint:forbidigo // intended output
+		fmt.Printf("Added %s %s to index %s.\n", release.Name, release.TagName(), indexPath)
+	}
+}
+
+func modGraphCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "graph",
+		Run:   runModGraphCmd,
+		Short: "Show the graph of installed mod dependencies",
+		Long: `Show the graph of installed mod dependencies.
+
+The graph shows the resolved dependency tree from the lock file, with the installed version of each mod and the
+constraint which required it. Use --resources to also show the references between the resources of the mods.
+
+Example:
+
+  # Render the dependency graph as an SVG with Graphviz
+  powerpipe mod graph | dot -Tsvg > mods.svg
+
+  # Show the dependency graph as a Mermaid flowchart
+  powerpipe mod graph --output mermaid
+
+  # Show the dependencies and resource references as JSON
+  powerpipe mod graph --resources --output json`,
+	}
+
+	var graphOutputMode = localconstants.GraphOutputModeDot
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for graph", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(localconstants.ArgResources, false, "Include the references between resources").
+		AddVarFlag(enumflag.New(&graphOutputMode, constants.ArgOutput, localconstants.GraphOutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.GraphOutputModeIds), ", "))).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModGraphCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModGraphCmd")
+	defer func() {
+		utils.LogTime("cmd.runModGraphCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	workspaceMod, err := parse.LoadModfile(workspacePath)
+	error_helpers.FailOnErrorWithMessage(err, "failed to load mod definition")
+	if workspaceMod == nil {
+		error_helpers.FailOnError(fmt.Errorf("no mod definition found in %s", workspacePath))
+	}
+
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	error_helpers.FailOnError(err)
+	graph := modgraph.DependencyGraph(workspacePath, workspaceMod, lock.InstallCache)
+
+	if viper.GetBool(localconstants.ArgResources) {
+		w, errAndWarnings := workspace.Load(ctx, workspacePath, workspace.WithVariableValidation(false))
+		error_helpers.FailOnError(errAndWarnings.GetError())
+		graph.AddResourceReferences(w.Mod)
+	}
+
+	res, err := graph.Render(viper.GetString(constants.ArgOutput))
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(res)
+}
+
+func modLintCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lint",
+		Run:   runModLintCmd,
+		Short: "Check the mod for problems",
+		Long: `Check the mod for problems.
+
+Errors are reported for files which cannot be parsed, references to resources, variables or locals which are
+not defined, and mods which fail to load. Warnings are reported for queries which are not referenced, controls
+without a severity or tags, resources with duplicated titles, params without defaults and deprecated syntax.
+
+The command exits with code 63 if any errors are found. Use --output json for machine-readable output in CI.
+
+Example:
+
+  # Check the mod in the current directory
+  powerpipe mod lint
+
+  # Check the mod, outputting the issues as JSON
+  powerpipe mod lint --output json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for lint", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", "))).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModLintCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModLintCmd")
+	defer func() {
+		utils.LogTime("cmd.runModLintCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	if _, exists := parse.ModFileExists(workspacePath); !exists {
+		error_helpers.FailOnError(localconstants.ErrorNoModDefinition{})
+	}
+
+	issues, err := modlint.Lint(ctx, workspacePath)
+	error_helpers.FailOnError(err)
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		// always output an array, so the output can be parsed when there are no issues
+		if issues == nil {
+			issues = []*modlint.Issue{}
+		}
+		jsonOutput, err := json.MarshalIndent(issues, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(issues)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		//nolint:forbidigo // intended output
+		fmt.Println(buildLintSummary(issues))
+	}
+
+	if modlint.HasErrors(issues) {
+		exitCode = localconstants.ExitCodeModLintFailed
+	}
+}
+
+func buildLintSummary(issues []*modlint.Issue) string {
+	if len(issues) == 0 {
+		return "No issues found."
+	}
+	var b strings.Builder
+	var errorCount int
+	for _, i := range issues {
+		if i.Severity == modlint.SeverityError {
+			errorCount++
+		}
+		if location := i.Location(); location != "" {
+			fmt.Fprintf(&b, "%s: ", location)
+		}
+		fmt.Fprintf(&b, "%s: ", i.Severity)
+		if i.Resource != "" {
+			fmt.Fprintf(&b, "%s: ", i.Resource)
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", i.Message, i.Rule)
+	}
+	warningCount := len(issues) - errorCount
+	fmt.Fprintf(&b, "\n%d %s, %d %s", errorCount, utils.Pluralize("error", errorCount), warningCount, utils.Pluralize("warning", warningCount))
+	return b.String()
+}
+
+func modBreakingCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "breaking [flags] <old> [new]",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runModBreakingCmd,
+		Short: "Report breaking changes between two versions of the mod",
+		Long: `Report breaking changes between two versions of the mod.
+
+Each version is either a directory containing the mod, or a revision (tag, branch or commit) of the Git
+repository containing the mod location. If the new version is not given, the mod location is used.
+
+Breaking changes are resources which were removed or renamed, params which were removed or are now required,
+and variables which were removed, changed type or are now required. A removed resource is reported as renamed
+if a new resource of the same type has the same title or sql.
+
+The command exits with code 66 if any breaking changes are found.
+
+Example:
+
+  # Check for breaking changes in the mod since the v1.2.0 release
+  powerpipe mod breaking v1.2.0
+
+  # Check for breaking changes between two releases
+  powerpipe mod breaking v1.2.0 v2.0.0
+
+  # Check for breaking changes between two installed versions of a dependency mod
+  powerpipe mod breaking .powerpipe/mods/github.com/acme/mod@v1.2.0 .powerpipe/mods/github.com/acme/mod@v1.3.0`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for breaking", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", "))).
+		AddModLocationFlag()
+	return cmd
+}
+
+func runModBreakingCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModBreakingCmd")
+	defer func() {
+		utils.LogTime("cmd.runModBreakingCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	workspacePath := viper.GetString(constants.ArgModLocation)
+	oldSurface, err := modcompat.Load(workspacePath, args[0])
+	error_helpers.FailOnError(err)
+	var newVersion string
+	if len(args) > 1 {
+		newVersion = args[1]
+	}
+	newSurface, err := modcompat.Load(workspacePath, newVersion)
+	error_helpers.FailOnError(err)
+
+	changes := modcompat.Compare(oldSurface, newSurface)
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		// always output an array, so the output can be parsed when there are no changes
+		if changes == nil {
+			changes = []*modcompat.Change{}
+		}
+		jsonOutput, err := json.MarshalIndent(changes, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(changes)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		//nolint:forbidigo // intended output
+		fmt.Println(buildBreakingSummary(changes))
+	}
+
+	if len(changes) > 0 {
+		exitCode = localconstants.ExitCodeBreakingChanges
+	}
+}
+
+func buildBreakingSummary(changes []*modcompat.Change) string {
+	if len(changes) == 0 {
+		return "No breaking changes found."
+	}
+	var b strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&b, "%s: %s (%s)\n", c.Resource, c.Message, c.Kind)
+	}
+	fmt.Fprintf(&b, "\n%d breaking %s", len(changes), utils.Pluralize("change", len(changes)))
+	return b.String()
+}
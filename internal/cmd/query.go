@@ -25,8 +25,12 @@ import (
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/exportbundle"
 	"github.com/turbot/powerpipe/internal/initialisation"
 	"github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/redact"
+	"github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/powerpipe/internal/timingreport"
 	"github.com/turbot/steampipe-plugin-sdk/v5/logging"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
@@ -54,7 +58,7 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddStringArrayFlag(constants.ArgArg, nil, "Specify the value of a query argument").
 		AddStringFlag(constants.ArgDatabase, app_specific.DefaultDatabase, "Turbot Pipes workspace database").
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, pps (snapshot), asff").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, pps (snapshot), asff, bundle (zip)").
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv and table output").
 		AddBoolFlag(constants.ArgHelp, false, "Help for query", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
@@ -65,17 +69,28 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddBoolFlag(constants.ArgProgress, true, "Display snapshot upload status").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a query session (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for a query session (comma-separated)").
+		AddBoolFlag(localconstants.ArgReadOnly, false, "Reject statements which are not queries and use read-only database sessions where supported").
+		AddBoolFlag(localconstants.ArgStartSteampipe, false, "Start the local Steampipe service if no database is specified and the service is not running").
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Turbot Pipes with 'anyone_with_link' visibility").
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Turbot Pipes with the default (workspace) visibility").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - a local file path, a Turbot Pipes workspace or an object storage URL (s3://, gs:// or azblob://)").
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
+		AddStringFlag(localconstants.ArgSnapshotSigningKey, "", "Path to an armored private key used to sign snapshots written to a directory or object storage").
+		AddStringFlag(localconstants.ArgSnapshotCompression, snapshot.CompressionNone, "Compression of snapshots written to a directory or object storage: none or zstd").
+		AddStringFlag(localconstants.ArgSnapshotVisibility, "", "Visibility of snapshots uploaded to Turbot Pipes: workspace, org or anyone_with_link (defaults to anyone_with_link for --share, otherwise workspace)").
+		AddStringFlag(localconstants.ArgSnapshotExpiry, "", "Expire snapshots uploaded to Turbot Pipes after a number of hours, days or weeks, e.g. 7d").
+		AddStringFlag(localconstants.ArgSnapshotWebhook, "", "URL to post the snapshot metadata to when a snapshot is uploaded to Turbot Pipes or object storage").
+		AddStringSliceFlag(localconstants.ArgRedactColumns, nil, "Columns whose values are redacted in snapshots and exports (comma-separated, may contain glob patterns)").
+		AddStringFlag(localconstants.ArgRedactMode, redact.ModeMask, "How redacted values are replaced: mask, or hash (keyed with POWERPIPE_REDACT_SALT)").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
-		AddBoolFlag(constants.ArgTiming, false, "Turn on the query timer").
+		AddStringFlag(constants.ArgTiming, constants.ArgOff, "Display query timing; one of: off, on, verbose", cmdconfig.FlagOptions.NoOptDefVal(constants.ArgOn)).
+		AddStringFlag(localconstants.ArgQueryLog, "", "Record every SQL statement executed in this file (or stdout or stderr)").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
-		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values")
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
+		AddStringFlag(localconstants.ArgPipesVariables, "", "Use the variable settings of a Turbot Pipes workspace (<identity>/<workspace>) for variables which are not set locally")
 
 	return cmd
 }
@@ -121,7 +136,9 @@ func queryRun(cmd *cobra.Command, args []string) {
 
 	// register the query exporters if necessary
 	if len(viper.GetStringSlice(constants.ArgExport)) > 0 {
-		err := initData.RegisterExporters(queryExporters()...)
+		exporters, err := exportbundle.AppendExporter(initData.Workspace, queryExporters())
+		error_helpers.FailOnError(err)
+		err = initData.RegisterExporters(exporters...)
 		error_helpers.FailOnError(err)
 
 		// validate required export formats
@@ -170,6 +187,12 @@ func queryRun(cmd *cobra.Command, args []string) {
 	exportArgs := viper.GetStringSlice(constants.ArgExport)
 	exportMsg, err := initData.ExportManager.DoExport(ctx, snap.FileNameRoot, snap, exportArgs)
 	error_helpers.FailOnErrorWithMessage(err, "failed to export snapshot")
+	// with verbose timing, write the timing of the query alongside the exports
+	if len(exportArgs) > 0 && display.TimingMode() == constants.ArgVerbose {
+		timingMsg, err := exportTimingReport(dashboardexecute.SnapshotTimingReport(snap))
+		error_helpers.FailOnErrorWithMessage(err, "failed to export timing report")
+		exportMsg = append(exportMsg, timingMsg)
+	}
 	// print the location where the file is exported
 	if len(exportMsg) > 0 && viper.GetBool(constants.ArgProgress) {
 		fmt.Printf("\n")                           //nolint:forbidigo // intentional use of fmt
@@ -190,6 +213,10 @@ func validateQueryArgs(ctx context.Context) error {
 		return fmt.Errorf("only one of --search-path or --search-path-prefix may be set")
 	}
 
+	if err := display.ValidateTiming(); err != nil {
+		return err
+	}
+
 	// only 1 of 'share' and 'snapshot' may be set
 	share := viper.GetBool(constants.ArgShare)
 	snapshot := viper.GetBool(constants.ArgSnapshot)
@@ -201,7 +228,7 @@ func validateQueryArgs(ctx context.Context) error {
 }
 
 func queryExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+	return []export.Exporter{&snapshot.Exporter{}}
 }
 
 func setExitCodeForQueryError(err error) {
@@ -252,5 +279,25 @@ func snapshotToQueryResult(snap *steampipeconfig.SteampipeSnapshot, startTime ti
 	res.Timing = &queryresult.TimingMetadata{
 		Duration: time.Since(startTime),
 	}
+	// include the breakdown of the query timing (the duration is the duration of the command)
+	if timing := chartRun.Timing; timing != nil {
+		res.Timing.Queue = timing.Queue
+		res.Timing.Execution = timing.Execution
+		res.Timing.Fetch = timing.Fetch
+		res.Timing.Render = timing.Render
+	}
 	return res, nil
 }
+
+// exportTimingReport writes the timing report of a run alongside its exports, and returns the export message
+func exportTimingReport(report *timingreport.Report) (string, error) {
+	path, err := report.Export()
+	if err != nil {
+		return "", err
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("File exported to %s/%s", pwd, path), nil
+}
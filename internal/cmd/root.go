@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -15,6 +16,7 @@ import (
 	"github.com/turbot/pipe-fittings/statushooks"
 	"github.com/turbot/pipe-fittings/utils"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/logger"
 )
 
 var exitCode int
@@ -44,15 +46,38 @@ func rootCommand() *cobra.Command {
 
 	cmdconfig.
 		OnCmd(rootCmd).
-		AddPersistentStringFlag(constants.ArgConfigPath, "", "Colon separated list of paths to search for workspace files, in order of decreasing precedence").
+		AddPersistentStringFlag(constants.ArgConfigPath, "", "Colon separated list of the directories to load config files from, in order of decreasing precedence, in place of the mod location and the config directory of the install dir (env POWERPIPE_CONFIG_PATH)").
 		AddPersistentStringFlag(constants.ArgInstallDir, app_specific.DefaultInstallDir, "Path to the installation directory").
 		AddPersistentStringFlag(constants.ArgModLocation, wd, "Path to the workspace working directory").
-		AddPersistentStringFlag(constants.ArgWorkspaceProfile, "default", "Sets the Powerpipe workspace profile")
+		AddPersistentStringFlag(constants.ArgWorkspaceProfile, "default", "Sets the Powerpipe workspace profile").
+		AddPersistentBoolFlag(localconstants.ArgPrompt, true, "Prompt for the values of required variables which have not been set (set to false to fail immediately, e.g. in CI)").
+		AddPersistentBoolFlag(localconstants.ArgOffline, false, "Run without network access: disable update checks, telemetry, Turbot Pipes and the public mod registry").
+		AddPersistentStringFlag(localconstants.ArgLogFormat, logger.FormatJSON, "Format of the logs: json (for ingestion by log aggregators), or text (env POWERPIPE_LOG_FORMAT)").
+		AddPersistentStringFlag(localconstants.ArgAuditLog, "", "Directory to write the audit log of commands, and of dashboard server activity, to (env POWERPIPE_AUDIT_LOG)").
+		AddPersistentStringFlag(localconstants.ArgAuditWebhook, "", "URL to post audit log events to (env POWERPIPE_AUDIT_WEBHOOK)").
+		AddPersistentIntFlag(localconstants.ArgAuditRetention, 30, "The number of days to retain audit log files (0 to retain indefinitely)").
+		AddPersistentStringFlag(localconstants.ArgLogFile, "", "Write the logs (of the level set by POWERPIPE_LOG_LEVEL) to this file rather than stderr, rotating it according to the log rotation options (env POWERPIPE_LOG_FILE)").
+		AddPersistentIntFlag(localconstants.ArgLogMaxSize, 100, "The size in megabytes at which the log file is rotated (0 to never rotate)").
+		AddPersistentIntFlag(localconstants.ArgLogMaxAge, 0, "The number of days to retain rotated log files (0 to retain them regardless of age)").
+		AddPersistentIntFlag(localconstants.ArgLogMaxFiles, 5, "The number of rotated log files to retain (0 to retain them all)").
+		AddPersistentBoolFlag(localconstants.ArgLogCompress, false, "Compress rotated log files with gzip").
+		AddPersistentStringSliceFlag(localconstants.ArgLogDestination, nil, "Destinations of the logs (comma-separated): stderr, file (the log file), syslog or journald (the systemd journal) - by default, the log file if set, otherwise stderr (env POWERPIPE_LOG_DESTINATION)").
+		AddPersistentStringSliceFlag(localconstants.ArgGitHostTokens, nil, "Tokens used to install mods from private Git hosts over HTTPS, as host=token or host=user:token pairs (comma-separated) - prefer setting these in the environment or an options block to passing them on the command line (env POWERPIPE_GIT_HOST_TOKENS)").
+		AddPersistentStringSliceFlag(localconstants.ArgGitSSHKeys, nil, "Private key files used to install mods from Git hosts over SSH, as host=key file pairs (comma-separated) - a host of * sets the key for all other hosts (env POWERPIPE_GIT_SSH_KEYS)").
+		AddPersistentStringFlag(localconstants.ArgGitSSHKeyPassphrase, "", "The passphrase of the SSH private key files (env POWERPIPE_GIT_SSH_KEY_PASSPHRASE)")
 
 	rootCmd.AddCommand(
 		serverCmd(),
 		modCmd(),
 		loginCmd(),
+		connectionCmd(),
+		configCmd(),
+		snapshotCmd(),
+		formatCmd(),
+		introspectCmd(),
+		lspCmd(),
+		testCmd(),
+		docsCmd(),
 		resourceCmd[*modconfig.Benchmark](),
 		resourceCmd[*modconfig.Control](),
 		resourceCmd[*modconfig.Dashboard](),
@@ -74,6 +99,19 @@ func Execute() int {
 
 	ctx := createRootContext()
 
+	start := time.Now()
+	defer func() {
+		// panics are reported by main - record the exit code main will exit with
+		r := recover()
+		if r != nil && exitCode == 0 {
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+		recordCommandAudit(ctx, start, exitCode)
+		if r != nil {
+			panic(r)
+		}
+	}()
+
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		exitCode = -1
 	}
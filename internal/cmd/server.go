@@ -3,8 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -17,6 +20,7 @@ import (
 	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/dashboardassets"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/dashboardserver"
 	"github.com/turbot/powerpipe/internal/initialisation"
 	"github.com/turbot/powerpipe/internal/service/api"
@@ -41,18 +45,35 @@ Powerpipe server runs in the foreground; Press Ctrl-C to exit.`,
 		AddBoolFlag(constants.ArgHelp, false, "Help for service start", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddIntFlag(constants.ArgPort, dashboardserver.DashboardServerDefaultPort, "Web server port").
 		AddBoolFlag(constants.ArgWatch, true, "Watch mod files for changes when running powerpipe server").
+		AddBoolFlag(localconstants.ArgWatchDependencies, false, "Also watch the installed dependency mods for changes, e.g. dependencies replaced by local directories").
 		AddStringFlag(constants.ArgListen, string(dashboardserver.ListenTypeLocal), "Accept connections from local (localhost only) or network (all interfaces / IP addresses)").
 		AddStringSliceFlag(constants.ArgVariable, []string{}, "Specify the value of a variable. Multiple --var arguments may be passed.").
 		AddStringFlag(constants.ArgVarFile, "", "Specify a .ppvar file containing variable values.").
+		AddStringFlag(localconstants.ArgPipesVariables, "", "Use the variable settings of a Turbot Pipes workspace (<identity>/<workspace>) for variables which are not set locally").
+		AddStringFlag(localconstants.ArgOtelEndpoint, "", "Export traces of the execution to the OpenTelemetry collector at this OTLP gRPC endpoint").
+		AddBoolFlag(localconstants.ArgOtelInsecure, false, "Connect to the OpenTelemetry collector without TLS").
+		AddStringFlag(localconstants.ArgQueryLog, "", "Record every SQL statement executed in this file (or stdout or stderr)").
 		AddStringFlag(constants.ArgDatabase, app_specific.DefaultDatabase, "Turbot Pipes workspace database").
-		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set a the dashboard execution timeout")
+		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for dashboard sessions (comma-separated)").
+		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for dashboard sessions (comma-separated)").
+		AddBoolFlag(localconstants.ArgReadOnly, false, "Reject statements which are not queries and use read-only database sessions where supported").
+		AddBoolFlag(localconstants.ArgStartSteampipe, false, "Start the local Steampipe service if no database is specified and the service is not running").
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
+		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set a the dashboard execution timeout").
+		AddIntFlag(localconstants.ArgMaxConcurrentDashboards, 0, "The maximum number of dashboards which may execute concurrently (0 for no limit)").
+		AddIntFlag(localconstants.ArgDashboardQueueSize, 100, "The maximum number of dashboard executions which may be queued when the concurrency limit is reached").
+		AddIntFlag(localconstants.ArgClientRateLimit, 0, "The maximum number of dashboard executions per second for each client (0 for no limit)").
+		AddStringFlag(localconstants.ArgBranding, "", "Path to a file containing a branding block to customize the dashboard UI").
+		AddIntFlag(localconstants.ArgShutdownTimeout, 30, "The number of seconds to allow in-progress dashboard executions to complete, and their snapshots to be saved, when the server is stopped").
+		AddBoolFlag(constants.ArgSnapshot, false, "Save a snapshot of each completed dashboard execution to the snapshot location").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - a local file path, a Turbot Pipes workspace or an object storage URL (s3://, gs:// or azblob://)")
 
 	return cmd
 }
 
 func runServerCmd(cmd *cobra.Command, _ []string) {
 	ctx := context.Background()
-	ctx, stopFn := signal.NotifyContext(ctx, os.Interrupt)
+	ctx, stopFn := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stopFn()
 
 	// if diagnostic mode is set, print out config and return
@@ -68,6 +89,9 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	serverListen := dashboardserver.ListenType(viper.GetString(constants.ArgListen))
 	error_helpers.FailOnError(serverListen.IsValid())
 
+	// validate the snapshot location if the snapshots of completed executions are saved
+	error_helpers.FailOnError(localcmdconfig.ValidateSnapshotArgs(ctx))
+
 	serverHost := ""
 	if err := utils.IsPortBindable(serverHost, int(serverPort)); err != nil {
 		exitCode = constants.ExitCodeBindPortUnavailable
@@ -78,6 +102,9 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	modInitData := initialisation.NewInitData[*modconfig.Dashboard](ctx, cmd)
 	error_helpers.FailOnError(modInitData.Result.Error)
 
+	// limit concurrent dashboard executions (if configured)
+	dashboardexecute.Executor.SetExecutionLimits(viper.GetInt(localconstants.ArgMaxConcurrentDashboards), viper.GetInt(localconstants.ArgDashboardQueueSize))
+
 	// ensure dashboard assets
 	err := dashboardassets.Ensure(ctx)
 	error_helpers.FailOnError(err)
@@ -85,15 +112,17 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	// setup a new webSocket service
 	webSocket := melody.New()
 	// create the dashboardServer
-	dashboardServer, err := dashboardserver.NewServer(ctx, modInitData.WorkspaceEvents, webSocket)
+	// executions must not be cancelled by the stop signal - they are drained when the server shuts down
+	serverCtx := context.WithoutCancel(ctx)
+	dashboardServer, err := dashboardserver.NewServer(serverCtx, modInitData.WorkspaceEvents, webSocket)
 	error_helpers.FailOnError(err)
 
 	// send it over to the powerpipe API Server
-	powerpipeService, err := api.NewAPIService(ctx, api.WithWebSocket(webSocket), api.WithWorkspace(modInitData.Workspace), api.WithHttpPort(serverPort))
+	powerpipeService, err := api.NewAPIService(serverCtx, api.WithWebSocket(webSocket), api.WithWorkspace(modInitData.Workspace), api.WithHttpPort(serverPort), api.WithBranding(dashboardServer.Branding()))
 	if err != nil {
 		error_helpers.FailOnError(err)
 	}
-	dashboardServer.InitAsync(ctx)
+	dashboardServer.InitAsync(serverCtx)
 
 	//start the API server
 	err = powerpipeService.Start()
@@ -106,4 +135,17 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	dashboardserver.OutputMessage(ctx, "Press Ctrl+C to exit")
 
 	<-ctx.Done()
+
+	// allow in-progress executions to complete before stopping the server
+	drainCtx, cancelDrain := context.WithTimeout(serverCtx, time.Duration(viper.GetInt(localconstants.ArgShutdownTimeout))*time.Second)
+	defer cancelDrain()
+	dashboardServer.Drain(drainCtx)
+
+	// close the websocket and write any pending audit events
+	dashboardServer.Shutdown(serverCtx)
+	stopCtx, cancelStop := context.WithTimeout(serverCtx, 5*time.Second)
+	defer cancelStop()
+	if err := powerpipeService.Stop(stopCtx); err != nil {
+		slog.Warn("failed to stop API server", "error", err)
+	}
 }
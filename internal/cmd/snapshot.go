@@ -0,0 +1,1048 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/dashboardassets"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardserver"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/modsignature"
+	"github.com/turbot/powerpipe/internal/objectstore"
+	"github.com/turbot/powerpipe/internal/service/api"
+	"github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/powerpipe/internal/varprompt"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"gopkg.in/olahol/melody.v1"
+	"sigs.k8s.io/yaml"
+)
+
+func snapshotCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot [command]",
+		Args:  cobra.NoArgs,
+		Short: "Powerpipe local snapshot management",
+		Long: `Powerpipe local snapshot management.
+
+Manage the snapshot (.pps) files saved to a local snapshot directory, i.e. when --snapshot-location is a directory.
+
+Examples:
+
+    # List the snapshots in the current directory
+    powerpipe snapshot list
+
+    # List the snapshots of the aws_compliance mod taken in the last week
+    powerpipe snapshot list --snapshot-location ~/snapshots --mod aws_compliance --since 7d
+
+    # List the snapshots tagged env=prod (set with --snapshot-tag when the snapshot was taken)
+    powerpipe snapshot list --tag env=prod`,
+	}
+	cmd.AddCommand(snapshotListCmd())
+	cmd.AddCommand(snapshotShowCmd())
+	cmd.AddCommand(snapshotRmCmd())
+	cmd.AddCommand(snapshotPruneCmd())
+	cmd.AddCommand(snapshotVerifyCmd())
+	cmd.AddCommand(snapshotSearchCmd())
+	cmd.AddCommand(snapshotExportCmd())
+	cmd.AddCommand(snapshotOpenCmd())
+	cmd.AddCommand(snapshotUnshareCmd())
+	cmd.AddCommand(snapshotPushCmd())
+	cmd.Flags().BoolP("help", "h", false, "Help for snapshot")
+
+	return cmd
+}
+
+func snapshotListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Run:   runSnapshotListCmd,
+		Short: "List the snapshots in the snapshot directory",
+		Long: `List the snapshots in the snapshot directory, most recent first.
+
+The --since and --until filters accept either a duration, e.g. 24h or 7d, meaning that long ago,
+or a date or timestamp, e.g. 2024-01-31 or 2024-01-31T09:00:00Z.
+
+Example:
+
+  # List the snapshots of the vpc dashboard as JSON
+  powerpipe snapshot list --resource dashboard.vpc --output json`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for list")
+	addSnapshotFilterFlags(builder)
+	builder.
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func snapshotShowCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "show <snapshot>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotShowCmd,
+		Short: "Show the details of a snapshot",
+		Long: `Show the details of a snapshot - the resource, run times, variables, inputs and panels.
+
+The snapshot is either a path or the name of a file in the snapshot directory.
+
+Example:
+
+  # Show a snapshot as JSON
+  powerpipe snapshot show aws_insights.dashboard.vpc.20240131T090000.pps --output json`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for show")
+	builder.
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func snapshotRmCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "rm [flags] [snapshots...]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runSnapshotRmCmd,
+		Short: "Delete snapshots from the snapshot directory",
+		Long: `Delete snapshots from the snapshot directory.
+
+Either name the snapshots to delete, or select them with the --mod, --resource, --tag, --since and --until filters.
+
+Example:
+
+  # Delete the snapshots of the aws_compliance mod older than 30 days
+  powerpipe snapshot rm --mod aws_compliance --until 30d
+
+  # Show which snapshots would be deleted
+  powerpipe snapshot rm --resource cis_v300 --dry-run`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for rm")
+	addSnapshotFilterFlags(builder)
+	builder.
+		AddBoolFlag(constants.ArgDryRun, false, "List the snapshots which would be deleted, without deleting them")
+	return cmd
+}
+
+func snapshotPruneCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.NoArgs,
+		Run:   runSnapshotPruneCmd,
+		Short: "Apply the snapshot retention policy to the snapshot directory",
+		Long: `Apply the snapshot retention policy to the snapshot directory.
+
+The retention policy removes snapshots older than --snapshot-max-age, all but the most recent --snapshot-max-count
+snapshots of each dashboard, benchmark or query, and the oldest snapshots once the total size of the directory exceeds
+--snapshot-max-size.
+
+The policy may also be set with the POWERPIPE_SNAPSHOT_MAX_AGE, POWERPIPE_SNAPSHOT_MAX_COUNT and
+POWERPIPE_SNAPSHOT_MAX_SIZE environment variables, in which case it is applied to the snapshot location
+(POWERPIPE_SNAPSHOT_LOCATION) automatically once a day.
+
+Example:
+
+  # Keep the 10 most recent snapshots of each resource, for at most 90 days
+  powerpipe snapshot prune --snapshot-max-count 10 --snapshot-max-age 90d
+
+  # Show which snapshots would be removed to keep the directory under 1GB
+  powerpipe snapshot prune --snapshot-max-size 1GB --dry-run`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for prune")
+	builder.
+		AddStringFlag(localconstants.ArgSnapshotMaxAge, "", "Remove snapshots older than this, e.g. 30d").
+		AddIntFlag(localconstants.ArgSnapshotMaxCount, 0, "Keep at most this many snapshots of each resource").
+		AddStringFlag(localconstants.ArgSnapshotMaxSize, "", "Remove the oldest snapshots once their total size exceeds this, e.g. 1GB").
+		AddBoolFlag(constants.ArgDryRun, false, "List the snapshots which would be removed, without removing them")
+	return cmd
+}
+
+func snapshotVerifyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "verify [flags] [snapshots...]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runSnapshotVerifyCmd,
+		Short: "Verify the signatures of snapshots",
+		Long: `Verify the signatures of snapshots, to check they have not been modified since they were taken.
+
+Snapshots are signed when they are taken if --snapshot-signing-key (or POWERPIPE_SNAPSHOT_SIGNING_KEY) is set to an
+armored private key - the detached signature is written alongside the snapshot, with a .asc extension. Signatures are
+verified against a file of trusted armored public keys.
+
+With no snapshots named, all the snapshots in the snapshot directory are verified. The command exits with code 68 if
+any snapshot is unsigned or its signature is invalid.
+
+Example:
+
+  # Verify all the snapshots in the snapshot directory
+  powerpipe snapshot verify --snapshot-location ~/evidence --trusted-keys ~/keys/compliance.asc
+
+  # Verify a single snapshot, outputting the result as JSON
+  powerpipe snapshot verify aws_compliance.benchmark.cis_v300.20240131T090000.pps --trusted-keys keys.asc --output json`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for verify")
+	builder.
+		AddStringFlag(localconstants.ArgTrustedKeys, "", "Path to a file of armored public keys trusted to sign snapshots").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func snapshotSearchCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "search",
+		Args:  cobra.NoArgs,
+		Run:   runSnapshotSearchCmd,
+		Short: "Search the content of the snapshots in the snapshot directory",
+		Long: `Search the content of the snapshots in the snapshot directory for controls, resources and statuses.
+
+--control matches the names of controls, --resource-id matches the resource of control results (and the values of the
+rows of other panels, such as tables) and --status matches the status of control results. A * in --control or
+--resource-id matches any characters. The snapshots searched may be narrowed with the --mod, --resource, --tag,
+--since and --until filters.
+
+Example:
+
+  # Find every snapshot in which a bucket was in alarm
+  powerpipe snapshot search --resource-id 'arn:aws:s3:::my-bucket' --status alarm
+
+  # Find the results of a control in the snapshots taken in the last 30 days, as JSON
+  powerpipe snapshot search --control s3_bucket_versioning_enabled --since 30d --output json`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for search")
+	addSnapshotFilterFlags(builder)
+	builder.
+		AddStringFlag(localconstants.ArgControl, "", "Match controls with this name or pattern").
+		AddStringFlag(localconstants.ArgResourceID, "", "Match results for resources with this id or pattern").
+		AddStringFlag(localconstants.ArgStatus, "", "Match control results with this status, e.g. alarm").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func snapshotExportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export <snapshot>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotExportCmd,
+		Short: "Export the results of a benchmark or control snapshot",
+		Long: `Export the results of a benchmark or control snapshot.
+
+The results stored in the snapshot are rendered with the same export formats as 'powerpipe benchmark run', without
+re-running the controls - so reports in new formats can be generated from previous runs. Any custom export templates
+in the templates directory may also be used.
+
+The snapshot is either a path or the name of a file in the snapshot directory.
+
+Example:
+
+  # Export a benchmark snapshot as HTML and CSV
+  powerpipe snapshot export aws_compliance.benchmark.cis_v300.20240131T090000.pps --export html --export csv
+
+  # Export a benchmark snapshot to a named file
+  powerpipe snapshot export aws_compliance.benchmark.cis_v300.20240131T090000.pps --export cis_january.md`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for export")
+	builder.
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, asff").
+		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv output").
+		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output")
+	return cmd
+}
+
+func snapshotOpenCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "open <snapshot>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotOpenCmd,
+		Short: "Open a snapshot in the dashboard UI",
+		Long: `Open a snapshot in the dashboard UI.
+
+Starts a dashboard server which serves only the snapshot - no mod or database connection is required - and opens it
+in the default browser. The server runs in the foreground; press Ctrl-C to exit.
+
+The snapshot is either a path or the name of a file in the snapshot directory.
+
+Example:
+
+  # Open a snapshot
+  powerpipe snapshot open ./aws_compliance.benchmark.cis_v300.20240131T090000.pps
+
+  # Serve a snapshot on a different port, without opening a browser
+  powerpipe snapshot open aws_insights.dashboard.vpc.20240131T090000.pps --port 9194 --browser=false`,
+	}
+
+	builder := cmdconfig.OnCmd(cmd)
+	addSnapshotDirFlags(builder, "Help for open")
+	builder.
+		AddIntFlag(constants.ArgPort, dashboardserver.DashboardServerDefaultPort, "Web server port").
+		AddStringFlag(constants.ArgListen, string(dashboardserver.ListenTypeLocal), "Accept connections from local (localhost only) or network (all interfaces / IP addresses)").
+		AddBoolFlag(localconstants.ArgBrowser, true, "Open the snapshot in the default browser")
+	return cmd
+}
+
+func snapshotUnshareCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "unshare <snapshot>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotUnshareCmd,
+		Short: "Revoke the shared link of a Turbot Pipes snapshot",
+		Long: `Revoke the shared link of a Turbot Pipes snapshot.
+
+Sets the visibility of a snapshot uploaded with --share (or --snapshot-visibility) back to the workspace members, so
+the link no longer gives access to anyone outside the workspace.
+
+The snapshot is either the url returned when it was uploaded, or {identity}/{workspace}/{snapshot id}.
+
+Example:
+
+  # Unshare a snapshot
+  powerpipe snapshot unshare https://pipes.turbot.com/user/jane/workspace/dev/snapshot/snap_cm2abc123
+
+  # Unshare a snapshot by id
+  powerpipe snapshot unshare acme/prod/snap_cm2abc123`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddCloudFlags().
+		AddBoolFlag(constants.ArgHelp, false, "Help for unshare", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func snapshotPushCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "push --pending",
+		Args:  cobra.NoArgs,
+		Run:   runSnapshotPushCmd,
+		Short: "Upload the snapshots queued when Turbot Pipes could not be reached",
+		Long: `Upload the snapshots queued when Turbot Pipes could not be reached.
+
+If a snapshot upload fails because Turbot Pipes cannot be reached, or returns a server error, the snapshot is queued
+in the install directory rather than lost. Queued snapshots are uploaded after the next successful snapshot upload,
+or by this command. Snapshots which fail to upload remain queued.
+
+The command exits with code 22 if any queued snapshot fails to upload.
+
+Example:
+
+  # Upload the queued snapshots
+  powerpipe snapshot push --pending
+
+  # List the queued snapshots without uploading them
+  powerpipe snapshot push --pending --dry-run`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddCloudFlags().
+		AddBoolFlag(constants.ArgHelp, false, "Help for push", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(localconstants.ArgPending, false, "Upload the queued snapshots").
+		AddStringFlag(localconstants.ArgSnapshotWebhook, "", "URL to post the snapshot metadata to when a queued snapshot is uploaded").
+		AddBoolFlag(constants.ArgDryRun, false, "List the queued snapshots without uploading them").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func addSnapshotDirFlags(builder *cmdconfig.CmdBuilder, help string) {
+	builder.
+		AddBoolFlag(constants.ArgHelp, false, help, cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The local snapshot directory (defaults to the current directory)")
+}
+
+func addSnapshotFilterFlags(builder *cmdconfig.CmdBuilder) {
+	builder.
+		AddStringFlag(localconstants.ArgMod, "", "Only include snapshots of resources in this mod").
+		AddStringFlag(localconstants.ArgResource, "", "Only include snapshots of resources matching this name or pattern, e.g. dashboard.vpc or cis_*").
+		AddStringArrayFlag(localconstants.ArgTag, nil, "Only include snapshots with this tag, specified as key=value").
+		AddStringFlag(localconstants.ArgSince, "", "Only include snapshots taken at or after this time").
+		AddStringFlag(localconstants.ArgUntil, "", "Only include snapshots taken at or before this time")
+}
+
+func runSnapshotListCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotListCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotListCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	filter, err := snapshotFilter()
+	error_helpers.FailOnError(err)
+
+	summaries, err := snapshot.List(dir, filter)
+	error_helpers.FailOnError(err)
+	if summaries == nil {
+		summaries = []*snapshot.Summary{}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(summaries, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(summaries)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		if len(summaries) == 0 {
+			//nolint:forbidigo // intended output
+			fmt.Printf("No snapshots found in %s\n", dir)
+			return
+		}
+		headers := []string{"Snapshot", "Resource", "Title", "Tags", "Taken", "Duration", "Size"}
+		rows := make([][]string, len(summaries))
+		for i, s := range summaries {
+			rows[i] = []string{
+				s.FileName(),
+				s.Resource,
+				s.Title,
+				formatSnapshotTags(s.Tags),
+				s.StartTime.Local().Format(time.DateTime),
+				s.EndTime.Sub(s.StartTime).Round(time.Millisecond).String(),
+				humanize.Bytes(uint64(s.Size)),
+			}
+		}
+		display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{HideEmptyColumns: true})
+	}
+}
+
+func runSnapshotShowCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotShowCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotShowCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	snapshotPath, err := snapshot.Resolve(dir, args[0])
+	error_helpers.FailOnError(err)
+	details, err := snapshot.Load(snapshotPath)
+	error_helpers.FailOnError(err)
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(details, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(details)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		showSnapshotDetails(details)
+	}
+}
+
+func showSnapshotDetails(details *snapshot.Details) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Path:           %s\n", details.Path)
+	fmt.Fprintf(&b, "Resource:       %s\n", details.Resource)
+	if details.Title != "" {
+		fmt.Fprintf(&b, "Title:          %s\n", details.Title)
+	}
+	fmt.Fprintf(&b, "Type:           %s\n", details.ResourceType)
+	fmt.Fprintf(&b, "Start time:     %s\n", details.StartTime.Local().Format(time.RFC3339))
+	fmt.Fprintf(&b, "End time:       %s\n", details.EndTime.Local().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Size:           %s\n", humanize.Bytes(uint64(details.Size)))
+	fmt.Fprintf(&b, "Schema version: %s\n", details.SchemaVersion)
+	if len(details.SearchPath) > 0 {
+		fmt.Fprintf(&b, "Search path:    %s\n", strings.Join(details.SearchPath, ", "))
+	}
+	writeSnapshotSection(&b, "Tags", details.Tags)
+	writeSnapshotSection(&b, "Panels", details.Panels)
+	writeSnapshotSection(&b, "Variables", details.Variables)
+	writeSnapshotSection(&b, "Inputs", details.Inputs)
+	//nolint:forbidigo // intended output
+	fmt.Print(b.String())
+}
+
+func formatSnapshotTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func writeSnapshotSection[V any](b *strings.Builder, title string, values map[string]V) {
+	if len(values) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(b, "\n%s:\n", title)
+	for _, k := range keys {
+		fmt.Fprintf(b, "  %s: %v\n", k, values[k])
+	}
+}
+
+func runSnapshotRmCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotRmCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotRmCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	filter, err := snapshotFilter()
+	error_helpers.FailOnError(err)
+
+	var paths []string
+	switch {
+	case len(args) > 0 && !filter.Empty():
+		error_helpers.FailOnError(fmt.Errorf("either name the snapshots to delete or filter them, not both"))
+	case len(args) > 0:
+		for _, arg := range args {
+			snapshotPath, err := snapshot.Resolve(dir, arg)
+			error_helpers.FailOnError(err)
+			paths = append(paths, snapshotPath)
+		}
+	case !filter.Empty():
+		summaries, err := snapshot.List(dir, filter)
+		error_helpers.FailOnError(err)
+		for _, s := range summaries {
+			paths = append(paths, s.Path)
+		}
+	default:
+		error_helpers.FailOnError(fmt.Errorf("name the snapshots to delete, or select them with --%s, --%s, --%s, --%s or --%s",
+			localconstants.ArgMod, localconstants.ArgResource, localconstants.ArgTag, localconstants.ArgSince, localconstants.ArgUntil))
+	}
+
+	dryRun := viper.GetBool(constants.ArgDryRun)
+	if !dryRun {
+		for _, p := range paths {
+			error_helpers.FailOnError(snapshot.Remove(p))
+		}
+	}
+	showRemovedSnapshots(paths, dryRun)
+}
+
+func runSnapshotPruneCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotPruneCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotPruneCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	policy, err := snapshot.RetentionPolicyFromConfig()
+	error_helpers.FailOnError(err)
+	if policy.Empty() {
+		error_helpers.FailOnError(fmt.Errorf("no retention policy is set - set --%s, --%s or --%s",
+			localconstants.ArgSnapshotMaxAge, localconstants.ArgSnapshotMaxCount, localconstants.ArgSnapshotMaxSize))
+	}
+
+	dryRun := viper.GetBool(constants.ArgDryRun)
+	removed, err := snapshot.Prune(dir, policy, dryRun, time.Now())
+	paths := make([]string, len(removed))
+	for i, s := range removed {
+		paths[i] = s.Path
+	}
+	showRemovedSnapshots(paths, dryRun)
+	error_helpers.FailOnError(err)
+}
+
+func showRemovedSnapshots(paths []string, dryRun bool) {
+	for _, p := range paths {
+		//nolint:forbidigo // intended output
+		fmt.Println(p)
+	}
+
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	//nolint:forbidigo // intended output
+	fmt.Printf("\n%s %d %s\n", verb, len(paths), utils.Pluralize("snapshot", len(paths)))
+}
+
+func runSnapshotSearchCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotSearchCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotSearchCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	query := snapshot.Query{
+		Control:    viper.GetString(localconstants.ArgControl),
+		ResourceID: viper.GetString(localconstants.ArgResourceID),
+		Status:     viper.GetString(localconstants.ArgStatus),
+	}
+	if query.Empty() {
+		error_helpers.FailOnError(fmt.Errorf("specify what to search for with --%s, --%s or --%s",
+			localconstants.ArgControl, localconstants.ArgResourceID, localconstants.ArgStatus))
+	}
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	filter, err := snapshotFilter()
+	error_helpers.FailOnError(err)
+
+	matches, err := snapshot.Search(dir, filter, query)
+	error_helpers.FailOnError(err)
+	if matches == nil {
+		matches = []*snapshot.Match{}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(matches, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(matches)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		showSnapshotSearchMatches(matches)
+	}
+}
+
+// showSnapshotSearchMatches shows a row for each matching control result or panel row - or for the panel itself if
+// it has no rows, e.g. a control which failed to run
+func showSnapshotSearchMatches(matches []*snapshot.Match) {
+	if len(matches) == 0 {
+		//nolint:forbidigo // intended output
+		fmt.Println("No matches found")
+		return
+	}
+	headers := []string{"Snapshot", "Taken", "Panel", "Status", "Resource", "Reason"}
+	var rows [][]string
+	for _, m := range matches {
+		prefix := []string{m.Snapshot.FileName(), m.Snapshot.StartTime.Local().Format(time.DateTime), m.Panel}
+		if len(m.Rows) == 0 {
+			rows = append(rows, append(prefix, m.Status, "", m.Error))
+			continue
+		}
+		for _, row := range m.Rows {
+			if m.PanelType != "control" {
+				rows = append(rows, append(prefix, "", formatSnapshotRow(row), ""))
+				continue
+			}
+			rows = append(rows, append(prefix, fmt.Sprint(row["status"]), fmt.Sprint(row["resource"]), fmt.Sprint(row["reason"])))
+		}
+	}
+	display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{HideEmptyColumns: true})
+	//nolint:forbidigo // intended output
+	fmt.Printf("\n%d %s\n", len(rows), utils.Pluralize("match", len(rows)))
+}
+
+// formatSnapshotRow formats a row of a panel other than a control as key=value pairs
+func formatSnapshotRow(row map[string]any) string {
+	pairs := make([]string, 0, len(row))
+	for k, v := range row {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func runSnapshotExportCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotExportCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotExportCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	exports := viper.GetStringSlice(constants.ArgExport)
+	if len(exports) == 0 {
+		error_helpers.FailOnError(fmt.Errorf("specify the export formats with --%s", constants.ArgExport))
+	}
+
+	exportManager := export.NewManager()
+	exporters, err := controldisplay.GetTemplateExporters()
+	error_helpers.FailOnError(err)
+	for _, exporter := range exporters {
+		error_helpers.FailOnError(exportManager.Register(exporter))
+	}
+	error_helpers.FailOnError(exportManager.ValidateExportFormat(exports))
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	snapshotPath, err := snapshot.Resolve(dir, args[0])
+	error_helpers.FailOnError(err)
+	details, err := snapshot.Load(snapshotPath)
+	error_helpers.FailOnError(err)
+	if details.ResourceType != schema.BlockTypeBenchmark && details.ResourceType != schema.BlockTypeControl {
+		error_helpers.FailOnError(fmt.Errorf("cannot export '%s' - only benchmark and control snapshots can be exported", details.Resource))
+	}
+	data, err := snapshot.ReadFile(snapshotPath)
+	error_helpers.FailOnError(err)
+	tree, err := controlexecute.NewExecutionTreeFromSnapshot(data)
+	error_helpers.FailOnError(err)
+
+	exportMsg, err := exportManager.DoExport(ctx, details.Resource, tree, exports)
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // intended output
+	fmt.Println(strings.Join(exportMsg, "\n"))
+}
+
+func runSnapshotOpenCmd(cmd *cobra.Command, args []string) {
+	ctx, stopFn := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stopFn()
+	utils.LogTime("cmd.runSnapshotOpenCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotOpenCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	serverPort := dashboardserver.ListenPort(viper.GetInt(constants.ArgPort))
+	error_helpers.FailOnError(serverPort.IsValid())
+	serverListen := dashboardserver.ListenType(viper.GetString(constants.ArgListen))
+	error_helpers.FailOnError(serverListen.IsValid())
+	if err := utils.IsPortBindable("", int(serverPort)); err != nil {
+		exitCode = constants.ExitCodeBindPortUnavailable
+		error_helpers.FailOnError(sperr.New("Port %d is not available - set a different port using the --port argument", serverPort))
+	}
+
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+	snapshotPath, err := snapshot.Resolve(dir, args[0])
+	error_helpers.FailOnError(err)
+	// check the file is a snapshot before starting the server
+	_, err = snapshot.Load(snapshotPath)
+	error_helpers.FailOnError(err)
+	snapshotPath, err = filepath.Abs(snapshotPath)
+	error_helpers.FailOnError(err)
+
+	// the dashboard UI displays snapshots from the workspace, so serve an empty workspace containing only the
+	// snapshot - as there are no dashboards to run, no database connection is required
+	workspaceDir, err := os.MkdirTemp("", "powerpipe-snapshot")
+	error_helpers.FailOnError(err)
+	defer os.RemoveAll(workspaceDir)
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, workspaceDir)
+	error_helpers.FailOnError(errAndWarnings.GetError())
+	w.GetResourceMaps().AddSnapshots([]string{snapshotPath})
+	snapshotName := fmt.Sprintf("snapshot.%s", utils.FilenameNoExtension(snapshotPath))
+	dashboardexecute.Executor = dashboardexecute.NewDashboardExecutor(db_client.NewClientMap())
+
+	error_helpers.FailOnError(dashboardassets.Ensure(ctx))
+
+	serverCtx := context.WithoutCancel(ctx)
+	webSocket := melody.New()
+	dashboardServer, err := dashboardserver.NewServer(serverCtx, dashboardworkspace.NewWorkspaceEvents(w), webSocket)
+	error_helpers.FailOnError(err)
+	apiService, err := api.NewAPIService(serverCtx, api.WithWebSocket(webSocket), api.WithWorkspace(w), api.WithHttpPort(serverPort), api.WithBranding(dashboardServer.Branding()))
+	error_helpers.FailOnError(err)
+	dashboardServer.InitAsync(serverCtx)
+	error_helpers.FailOnError(apiService.Start())
+
+	snapshotUrl := fmt.Sprintf("http://localhost:%d/snapshot/%s", serverPort, url.PathEscape(snapshotName))
+	dashboardserver.OutputMessage(ctx, fmt.Sprintf("Visit %s", snapshotUrl))
+	dashboardserver.OutputMessage(ctx, "Press Ctrl+C to exit")
+	if viper.GetBool(localconstants.ArgBrowser) {
+		if err := utils.OpenBrowser(snapshotUrl); err != nil {
+			slog.Warn("failed to open browser", "error", err)
+		}
+	}
+
+	<-ctx.Done()
+
+	dashboardServer.Shutdown(serverCtx)
+	stopCtx, cancelStop := context.WithTimeout(serverCtx, 5*time.Second)
+	defer cancelStop()
+	if err := apiService.Stop(stopCtx); err != nil {
+		slog.Warn("failed to stop API server", "error", err)
+	}
+}
+
+// snapshotVerifyResult is the result of verifying the signature of a snapshot
+type snapshotVerifyResult struct {
+	Path   string `json:"path"`
+	Valid  bool   `json:"valid"`
+	Signer string `json:"signer,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runSnapshotVerifyCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotVerifyCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotVerifyCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	keyRing, err := modsignature.LoadKeyRing(viper.GetString(localconstants.ArgTrustedKeys))
+	error_helpers.FailOnError(err)
+	dir, err := snapshotDir()
+	error_helpers.FailOnError(err)
+
+	var paths []string
+	if len(args) == 0 {
+		summaries, err := snapshot.List(dir, snapshot.Filter{})
+		error_helpers.FailOnError(err)
+		for _, s := range summaries {
+			paths = append(paths, s.Path)
+		}
+	}
+	for _, arg := range args {
+		snapshotPath, err := snapshot.Resolve(dir, arg)
+		error_helpers.FailOnError(err)
+		paths = append(paths, snapshotPath)
+	}
+
+	results := make([]snapshotVerifyResult, len(paths))
+	var failed int
+	for i, p := range paths {
+		results[i].Path = p
+		results[i].Signer, err = snapshot.Verify(p, keyRing)
+		if err != nil {
+			results[i].Error = err.Error()
+			failed++
+		} else {
+			results[i].Valid = true
+		}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(results, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(results)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		headers := []string{"Snapshot", "Status", "Signer", "Error"}
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			status := "valid"
+			if !r.Valid {
+				status = "invalid"
+			}
+			rows[i] = []string{r.Path, status, r.Signer, r.Error}
+		}
+		display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{HideEmptyColumns: true})
+		//nolint:forbidigo // intended output
+		fmt.Printf("\n%d valid, %d invalid\n", len(results)-failed, failed)
+	}
+
+	if failed > 0 {
+		exitCode = localconstants.ExitCodeSnapshotVerifyFailed
+	}
+}
+
+func runSnapshotUnshareCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotUnshareCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotUnshareCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	ref, err := snapshot.ParsePipesSnapshotRef(args[0])
+	error_helpers.FailOnError(err)
+	error_helpers.FailOnError(snapshot.Unshare(ctx, ref))
+	//nolint:forbidigo // intended output
+	fmt.Printf("Snapshot %s is now only visible to the members of workspace %s/%s\n", ref.SnapshotId, ref.Identity, ref.Workspace)
+}
+
+func runSnapshotPushCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotPushCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotPushCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	if !viper.GetBool(localconstants.ArgPending) {
+		error_helpers.FailOnError(fmt.Errorf("specify --%s to upload the queued snapshots", localconstants.ArgPending))
+	}
+	error_helpers.FailOnError(snapshot.ValidateShareSettings())
+
+	var results []*snapshot.PushResult
+	if viper.GetBool(constants.ArgDryRun) {
+		queued, err := snapshot.ListPending(snapshot.PendingDir())
+		error_helpers.FailOnError(err)
+		for _, p := range queued {
+			results = append(results, &snapshot.PushResult{Path: p.Path, Workspace: p.Workspace, Title: p.Request.Title, Error: p.LastError})
+		}
+	} else {
+		var err error
+		results, err = snapshot.PushPending(ctx, snapshot.PendingDir())
+		error_helpers.FailOnError(err)
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Error != "" && !viper.GetBool(constants.ArgDryRun) {
+			failed++
+		}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(results, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(results)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		if len(results) == 0 {
+			//nolint:forbidigo // intended output
+			fmt.Println("No snapshots are queued")
+			return
+		}
+		headers := []string{"Snapshot", "Workspace", "Url", "Error"}
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			rows[i] = []string{r.Title, r.Workspace, r.URL, r.Error}
+		}
+		display.ShowWrappedTable(headers, rows, &display.ShowWrappedTableOptions{HideEmptyColumns: true})
+		if !viper.GetBool(constants.ArgDryRun) {
+			//nolint:forbidigo // intended output
+			fmt.Printf("\n%d uploaded, %d failed\n", len(results)-failed, failed)
+		}
+	}
+
+	if failed > 0 {
+		exitCode = constants.ExitCodeSnapshotUploadFailed
+	}
+}
+
+// snapshotDir returns the local snapshot directory - the snapshot location, or the current directory if not set
+func snapshotDir() (string, error) {
+	dir := viper.GetString(constants.ArgSnapshotLocation)
+	if dir == "" {
+		return os.Getwd()
+	}
+	if steampipeconfig.IsCloudWorkspaceIdentifier(dir) || objectstore.IsURL(dir) {
+		return "", fmt.Errorf("snapshot location %s is not a local directory - snapshot commands manage local snapshot directories", dir)
+	}
+	dir, err := filehelpers.Tildefy(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filehelpers.DirectoryExists(dir) {
+		return "", fmt.Errorf("snapshot location %s does not exist", dir)
+	}
+	return dir, nil
+}
+
+// snapshotFilter builds the snapshot filter from the --mod, --resource, --tag, --since and --until args
+func snapshotFilter() (snapshot.Filter, error) {
+	filter := snapshot.Filter{
+		Mod:      viper.GetString(localconstants.ArgMod),
+		Resource: viper.GetString(localconstants.ArgResource),
+	}
+	var err error
+	if filter.Tags, err = snapshot.ParseTags(viper.GetStringSlice(localconstants.ArgTag)); err != nil {
+		return filter, err
+	}
+	now := time.Now()
+	if since := viper.GetString(localconstants.ArgSince); since != "" {
+		if filter.Since, err = snapshot.ParseTime(since, now); err != nil {
+			return filter, err
+		}
+	}
+	if until := viper.GetString(localconstants.ArgUntil); until != "" {
+		if filter.Until, err = snapshot.ParseUntilTime(until, now); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/modtest"
+	"github.com/turbot/powerpipe/internal/varprompt"
+	"sigs.k8s.io/yaml"
+)
+
+func testCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "test [flags] [test names...]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runTestCmd,
+		Short: "Run the tests of the mod",
+		Long: `Run the tests of the mod.
+
+Tests are defined in test blocks in .hcl files in the tests directory of the mod. Each test runs a control or
+query against fixture data and asserts the rows of the result:
+
+  test "s3_bucket_versioning_enabled" {
+    control  = "control.s3_bucket_versioning_enabled"
+    fixtures = ["fixtures/aws_s3_bucket.sql"]
+    rows     = 2
+
+    expect {
+      resource = "arn:aws:s3:::example-scratch"
+      status   = "alarm"
+    }
+  }
+
+Fixtures are SQL files, relative to the test file, which seed the data the control or query runs against. They
+are run in a transaction which is rolled back after the test. A test may set database to run against a SQLite or
+DuckDB database file, e.g. database = "sqlite:fixtures/inventory.db", rather than the default database.
+
+The command exits with code 64 if any tests fail.
+
+Example:
+
+  # Run all tests
+  powerpipe test
+
+  # Run the named tests, outputting the results as JSON
+  powerpipe test s3_bucket_versioning_enabled --output json`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddStringFlag(constants.ArgDatabase, app_specific.DefaultDatabase, "Database to run tests against, for tests which do not set a database").
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
+		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for tests which do not set a search path (comma-separated)").
+		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for tests which do not set a search path (comma-separated)").
+		AddBoolFlag(constants.ArgHelp, false, "Help for test", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddStringArrayFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
+		AddStringFlag(localconstants.ArgPipesVariables, "", "Use the variable settings of a Turbot Pipes workspace (<identity>/<workspace>) for variables which are not set locally").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func runTestCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runTestCmd")
+	defer func() {
+		utils.LogTime("cmd.runTestCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	modLocation := viper.GetString(constants.ArgModLocation)
+	tests, err := modtest.LoadTests(modLocation)
+	error_helpers.FailOnError(err)
+	tests, err = filterTests(tests, args)
+	error_helpers.FailOnError(err)
+
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, modLocation)
+	error_helpers.FailOnError(errAndWarnings.GetError())
+	if !w.ModfileExists() {
+		error_helpers.FailOnError(localconstants.ErrorNoModDefinition{})
+	}
+
+	database, searchPathConfig := db_client.GetDefaultDatabaseConfig()
+	runner := modtest.NewRunner(w, database, searchPathConfig)
+	defer runner.Close(ctx) //nolint:errcheck // nothing to do if closing the clients fails
+
+	results := make([]*modtest.Result, len(tests))
+	var failed int
+	for i, t := range tests {
+		results[i] = runner.Run(ctx, t)
+		if !results[i].Passed {
+			failed++
+		}
+	}
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatJSON:
+		jsonOutput, err := json.MarshalIndent(results, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Println(string(jsonOutput))
+	case constants.OutputFormatYAML:
+		yamlOutput, err := yaml.Marshal(results)
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // intended output
+		fmt.Print(string(yamlOutput))
+	default:
+		//nolint:forbidigo // intended output
+		fmt.Println(buildTestSummary(results, failed))
+	}
+
+	if failed > 0 {
+		exitCode = localconstants.ExitCodeTestsFailed
+	}
+}
+
+// filterTests returns the tests with the given names, or all tests if no names are given
+func filterTests(tests []*modtest.Test, names []string) ([]*modtest.Test, error) {
+	if len(names) == 0 {
+		return tests, nil
+	}
+	byName := make(map[string]*modtest.Test, len(tests))
+	for _, t := range tests {
+		byName[t.Name] = t
+	}
+	res := make([]*modtest.Test, 0, len(names))
+	for _, name := range names {
+		t, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("test '%s' not found", name)
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+func buildTestSummary(results []*modtest.Result, failed int) string {
+	if len(results) == 0 {
+		return "No tests found."
+	}
+	var b strings.Builder
+	for _, r := range results {
+		mark := "✔"
+		if !r.Passed {
+			mark = "✘"
+		}
+		fmt.Fprintf(&b, "%s %s (%s)\n", mark, r.Name, r.Target)
+		for _, failure := range r.Failures {
+			fmt.Fprintf(&b, "    %s\n", failure)
+		}
+	}
+	fmt.Fprintf(&b, "\n%d passed, %d failed", len(results)-failed, failed)
+	return b.String()
+}
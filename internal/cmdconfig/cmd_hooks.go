@@ -6,12 +6,14 @@ import (
 	"log/slog"
 	"os"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/cloud"
@@ -23,7 +25,14 @@ import (
 	"github.com/turbot/pipe-fittings/steampipeconfig"
 	"github.com/turbot/pipe-fittings/task"
 	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/connection"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/gitauth"
 	"github.com/turbot/powerpipe/internal/logger"
+	"github.com/turbot/powerpipe/internal/pipes"
+	"github.com/turbot/powerpipe/internal/secrets"
+	"github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/powerpipe/internal/workspaceprofile"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
@@ -31,6 +40,15 @@ import (
 var waitForTasksChannel chan struct{}
 var tasksCancelFn context.CancelFunc
 
+// configErr is the error loading the global config for a command which validates the config - see ConfigError
+var configErr error
+
+// ConfigError returns the error loading the global config for the config validate command, which runs (and reports
+// the error) rather than failing when the config is invalid
+func ConfigError() error {
+	return configErr
+}
+
 // postRunHook is a function that is executed after the PostRun of every command handler
 func postRunHook(_ *cobra.Command, _ []string) error {
 	utils.LogTime("cmdhook.postRunHook start")
@@ -64,9 +82,18 @@ func preRunHook(cmd *cobra.Command, args []string) error {
 	// display any warnings
 	ew.ShowWarnings()
 	// check for error
-	error_helpers.FailOnError(ew.Error)
+	if validatesConfig(cmd) {
+		configErr = ew.Error
+	} else {
+		error_helpers.FailOnError(ew.Error)
+	}
 
-	logger.Initialize()
+	error_helpers.FailOnError(logger.Initialize())
+
+	// configure any per-host credentials used to install mods from private Git hosts
+	if err := gitauth.Install(); err != nil {
+		error_helpers.ShowWarning(err.Error())
+	}
 
 	// runScheduledTasks skips running tasks if this instance is the plugin manager
 	waitForTasksChannel = runScheduledTasks(cmd.Context(), cmd, args)
@@ -90,23 +117,68 @@ func setMemoryLimit() {
 // runScheduledTasks skips running tasks if this instance is the plugin manager
 func runScheduledTasks(ctx context.Context, cmd *cobra.Command, args []string) chan struct{} {
 	updateCheck := viper.GetBool(constants.ArgUpdateCheck)
-	// for now the only scheduled task we support is update check so if that is disabled, do nothing
-	if !updateCheck {
+	pruneSnapshots := snapshotRetentionHook()
+	// the scheduled tasks are the update check and snapshot retention - if neither is enabled, do nothing
+	if !updateCheck && pruneSnapshots == nil {
 		return nil
 	}
 
 	taskUpdateCtx, cancelFn := context.WithCancel(ctx)
 	tasksCancelFn = cancelFn
 
-	return task.RunTasks(
-		taskUpdateCtx,
-		cmd,
-		args,
+	opts := []task.TaskRunOption{
 		// pass the config value in rather than runRasks querying viper directly - to avoid concurrent map access issues
 		// (we can use the update-check viper config here, since initGlobalConfig has already set it up
 		// with values from the config files and ENV settings - update-check cannot be set from the command line)
 		task.WithUpdateCheck(updateCheck),
-	)
+		task.WithShowNotificationsFunc(shouldShowNotifications),
+	}
+	if pruneSnapshots != nil {
+		opts = append(opts, task.WithPreHook(pruneSnapshots))
+	}
+	return task.RunTasks(taskUpdateCtx, cmd, args, opts...)
+}
+
+// snapshotRetentionHook returns a task which applies the snapshot retention policy to the local snapshot directory,
+// or nil if no retention policy is configured or the snapshot location is not a local directory
+//
+// the policy and directory are resolved here, as the task runs asynchronously and must not read viper
+func snapshotRetentionHook() task.HookFn {
+	policy, err := snapshot.RetentionPolicyFromConfig()
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("snapshot retention policy is invalid and will not be applied: %s", err))
+		return nil
+	}
+	dir := viper.GetString(constants.ArgSnapshotLocation)
+	if policy.Empty() || dir == "" || steampipeconfig.IsCloudWorkspaceIdentifier(dir) {
+		return nil
+	}
+	dir, err = filehelpers.Tildefy(dir)
+	if err != nil || !filehelpers.DirectoryExists(dir) {
+		return nil
+	}
+
+	return func(context.Context) {
+		removed, err := snapshot.Prune(dir, policy, false, time.Now())
+		if err != nil {
+			slog.Warn("failed to prune snapshots", "dir", dir, "error", err)
+		}
+		if len(removed) > 0 {
+			slog.Info("pruned snapshots", "dir", dir, "count", len(removed))
+		}
+	}
+}
+
+// shouldShowNotifications returns false for commands whose output is read by another program rather than a user,
+// i.e. the language server, whose stdout is the protocol stream
+func shouldShowNotifications(cmd *cobra.Command, _ []string) bool {
+	return cmd.Name() != "lsp"
+}
+
+// validatesConfig returns whether the command is config validate, which reports errors in the config rather than
+// failing to start
+func validatesConfig(cmd *cobra.Command) bool {
+	return cmd.Name() == "validate" && cmd.HasParent() && cmd.Parent().Name() == "config"
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -114,11 +186,21 @@ func initGlobalConfig() error_helpers.ErrorAndWarnings {
 	utils.LogTime("cmdconfig.initGlobalConfig start")
 	defer utils.LogTime("cmdconfig.initGlobalConfig end")
 
+	// an explicit config path replaces the default search, so fail rather than silently load no config if a
+	// directory of it is missing
+	if err := validateConfigPath(); err != nil {
+		return error_helpers.NewErrorsAndWarning(err)
+	}
+
 	// load workspace profile from the configured install dir
-	loader, err := cmdconfig.GetWorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile]()
+	loader, err := workspaceprofile.NewLoader()
 	if err != nil {
 		return error_helpers.NewErrorsAndWarning(err)
 	}
+	// complete the inheritance of settings from the base of the profiles
+	if err := workspaceprofile.ResolveBase(loader.DefaultProfile, loader.ConfiguredProfile); err != nil {
+		return error_helpers.NewErrorsAndWarning(err)
+	}
 
 	var cmd = viper.Get(constants.ConfigKeyActiveCommand).(*cobra.Command)
 
@@ -128,9 +210,29 @@ func initGlobalConfig() error_helpers.ErrorAndWarnings {
 		cmdconfig.WithConfigDefaults(configDefaults(cmd)),
 		cmdconfig.WithDirectoryEnvMappings(dirEnvMappings()))
 
+	// load the options blocks which set flag defaults for the command - the options which apply to every workspace
+	// profile (and those for the default profile, if no profile was configured) have the precedence of the default
+	// profile, so are set now, before the env
+	configPaths, err := cmdconfig.GetConfigPath()
 	if err != nil {
 		return error_helpers.NewErrorsAndWarning(err)
 	}
+	slog.Debug("loading config", "configPath", configPaths)
+	commandOptions, err := workspaceprofile.LoadCommandOptions(configPaths)
+	if err != nil {
+		return error_helpers.NewErrorsAndWarning(err)
+	}
+	workspaces := []string{""}
+	if loader.ConfiguredProfile == nil {
+		workspaces = append(workspaces, loader.DefaultProfile.ProfileName)
+	}
+	for _, workspace := range workspaces {
+		values, err := commandOptions.Values(cmd, workspace)
+		if err != nil {
+			return error_helpers.NewErrorsAndWarning(err)
+		}
+		cmdconfig.SetDefaultsFromConfig(values)
+	}
 
 	// set global containing the configured install dir (create directory if needed)
 	ensureInstallDirs()
@@ -138,22 +240,53 @@ func initGlobalConfig() error_helpers.ErrorAndWarnings {
 	// set the rest of the defaults from ENV
 	// ENV takes precedence over any default configuration
 	cmdconfig.SetDefaultsFromEnv(envMappings())
+	setSearchPathDefaultsFromEnv()
 
 	// if an explicit workspace profile was set, add to viper as highest precedence default
 	// NOTE: if install_dir/mod_location are set these will already have been passed to viper by BootstrapViper
 	// since the "ConfiguredProfile" is passed in through a cmdline flag, it will always take precedence
+	// the options of the command for the profile take precedence over the settings of the profile
 	if loader.ConfiguredProfile != nil {
 		cmdconfig.SetDefaultsFromConfig(loader.ConfiguredProfile.ConfigMap(cmd))
+		values, err := commandOptions.Values(cmd, loader.ConfiguredProfile.ProfileName)
+		if err != nil {
+			return error_helpers.NewErrorsAndWarning(err)
+		}
+		cmdconfig.SetDefaultsFromConfig(values)
+	}
+
+	// load the named database connections from the config path
+	if err := connection.Load(configPaths); err != nil {
+		return error_helpers.NewErrorsAndWarning(err)
+	}
+	// load the named Pipes hosts (pipes credentials), resolving the pipes-host if it is the name of one
+	if err := pipes.Load(configPaths); err != nil {
+		return error_helpers.NewErrorsAndWarning(err)
+	}
+	namedPipesHost := pipes.Resolve(viper.GetString(constants.ArgPipesHost))
+	if namedPipesHost != nil {
+		viper.Set(constants.ArgPipesHost, namedPipesHost.Host)
 	}
 
 	// now env vars have been processed, set filepaths.PipesInstallDir
 	filepaths.PipesInstallDir = viper.GetString(constants.ArgPipesInstallDir)
 
-	// NOTE: we need to resolve the token separately
-	// - that is because we need the resolved value of ArgPipesHost in order to load any saved token
-	// and we cannot get this until the other config has been resolved
-	err = setPipesTokenDefault(loader)
-	if err != nil {
+	// in offline mode, disable update checks and telemetry - the Pipes token is not resolved, as Turbot Pipes cannot
+	// be used
+	if viper.GetBool(localconstants.ArgOffline) {
+		setOfflineConfig()
+	} else {
+		// NOTE: we need to resolve the token separately
+		// - that is because we need the resolved value of ArgPipesHost in order to load any saved token
+		// and we cannot get this until the other config has been resolved
+		err = setPipesTokenDefault(cmd.Context(), loader, namedPipesHost)
+		if err != nil {
+			return error_helpers.NewErrorsAndWarning(err)
+		}
+	}
+
+	// now every config value has been set, resolve the secrets they reference
+	if err := resolveSecrets(cmd.Context()); err != nil {
 		return error_helpers.NewErrorsAndWarning(err)
 	}
 
@@ -161,9 +294,91 @@ func initGlobalConfig() error_helpers.ErrorAndWarnings {
 	return validateConfig()
 }
 
-func setPipesTokenDefault(loader *steampipeconfig.WorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile]) error {
+// validateConfigPath checks that each directory of the config path exists, if the config path was set by the
+// --config-path flag or the POWERPIPE_CONFIG_PATH env var
+func validateConfigPath() error {
+	if _, ok := os.LookupEnv(app_specific.EnvConfigPath); !ok && !viper.IsSet(constants.ArgConfigPath) {
+		return nil
+	}
+	configPaths, err := cmdconfig.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	for _, configPath := range configPaths {
+		if !filehelpers.DirectoryExists(configPath) {
+			return fmt.Errorf("config path directory '%s' does not exist", configPath)
+		}
+	}
+	return nil
+}
+
+// setOfflineConfig disables the features which require network access and are enabled by default, so an
+// air-gapped run does not wait for network timeouts
+func setOfflineConfig() {
+	viper.Set(constants.ArgUpdateCheck, false)
+	viper.Set(constants.ArgTelemetry, constants.TelemetryNone)
+}
+
+// setSearchPathDefaultsFromEnv sets the search path and search path prefix defaults from the comma separated env vars
+// (these are not set using the env mappings as viper does not split a string default on commas)
+func setSearchPathDefaultsFromEnv() {
+	for envVar, arg := range map[string]string{
+		localconstants.EnvSearchPath:       constants.ArgSearchPath,
+		localconstants.EnvSearchPathPrefix: constants.ArgSearchPathPrefix,
+	} {
+		schemas := strings.FieldsFunc(os.Getenv(envVar), func(r rune) bool { return r == ',' || r == ' ' })
+		if len(schemas) > 0 {
+			viper.SetDefault(arg, schemas)
+		}
+	}
+}
+
+// resolveSecrets replaces each config value which is a secret reference with the value of the secret. The database
+// may also contain ${...} templates which refer to secrets or environment variables
+func resolveSecrets(ctx context.Context) error {
+	for _, key := range viper.AllKeys() {
+		switch value := viper.Get(key).(type) {
+		case string:
+			var resolved string
+			var err error
+			switch {
+			case key == constants.ArgDatabase && (secrets.IsReference(value) || strings.Contains(value, "${")):
+				resolved, err = secrets.ResolveConnectionString(ctx, value)
+			case secrets.IsReference(value):
+				resolved, err = secrets.Resolve(ctx, value)
+			default:
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			viper.Set(key, resolved)
+		case []string:
+			// only set the values which contain a secret reference, as setting a value marks it as set
+			if !slices.ContainsFunc(value, secrets.IsReference) {
+				continue
+			}
+			resolved := make([]string, len(value))
+			for i, v := range value {
+				resolved[i] = v
+				if !secrets.IsReference(v) {
+					continue
+				}
+				var err error
+				if resolved[i], err = secrets.Resolve(ctx, v); err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+			}
+			viper.Set(key, resolved)
+		}
+	}
+	return nil
+}
+
+func setPipesTokenDefault(ctx context.Context, loader *steampipeconfig.WorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile], namedPipesHost *pipes.Host) error {
 	/*
 	   saved cloud token
+	   token of the named pipes host
 	   pipes_token in default workspace
 	   explicit env var (PIPES_TOKEN ) wins over
 	   pipes_token in specific workspace
@@ -177,6 +392,14 @@ func setPipesTokenDefault(loader *steampipeconfig.WorkspaceProfileLoader[*modcon
 	if savedToken != "" {
 		viper.SetDefault(constants.ArgPipesToken, savedToken)
 	}
+	// 1b) the token of the pipes credential named by the pipes-host
+	if namedPipesHost != nil && namedPipesHost.Token != nil {
+		token, err := namedPipesHost.GetToken(ctx)
+		if err != nil {
+			return err
+		}
+		viper.SetDefault(constants.ArgPipesToken, token)
+	}
 	// 2) default profile cloud token
 	if loader.DefaultProfile.PipesToken != nil {
 		viper.SetDefault(constants.ArgPipesToken, *loader.DefaultProfile.PipesToken)
@@ -72,9 +72,48 @@ func envMappings() map[string]cmdconfig.EnvMapping {
 		constants.EnvPipesHost:       {ConfigVar: []string{constants.ArgPipesHost}, VarType: cmdconfig.EnvVarTypeString},
 		constants.EnvPipesToken:      {ConfigVar: []string{constants.ArgPipesToken}, VarType: cmdconfig.EnvVarTypeString},
 		// powerpipe specific constants
-		localconstants.EnvListen:           {ConfigVar: []string{constants.ArgListen}, VarType: cmdconfig.EnvVarTypeString},
-		localconstants.EnvPort:             {ConfigVar: []string{constants.ArgPort}, VarType: cmdconfig.EnvVarTypeInt},
-		localconstants.EnvBenchmarkTimeout: {ConfigVar: []string{constants.ArgBenchmarkTimeout}, VarType: cmdconfig.EnvVarTypeInt},
-		localconstants.EnvDashboardTimeout: {ConfigVar: []string{constants.ArgDashboardTimeout}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvListen:                  {ConfigVar: []string{constants.ArgListen}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvPort:                    {ConfigVar: []string{constants.ArgPort}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvBenchmarkTimeout:        {ConfigVar: []string{constants.ArgBenchmarkTimeout}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvDashboardTimeout:        {ConfigVar: []string{constants.ArgDashboardTimeout}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvMaxConcurrentDashboards: {ConfigVar: []string{localconstants.ArgMaxConcurrentDashboards}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvDashboardQueueSize:      {ConfigVar: []string{localconstants.ArgDashboardQueueSize}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvClientRateLimit:         {ConfigVar: []string{localconstants.ArgClientRateLimit}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvBranding:                {ConfigVar: []string{localconstants.ArgBranding}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvAuditLog:                {ConfigVar: []string{localconstants.ArgAuditLog}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvAuditWebhook:            {ConfigVar: []string{localconstants.ArgAuditWebhook}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvAuditRetention:          {ConfigVar: []string{localconstants.ArgAuditRetention}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvShutdownTimeout:         {ConfigVar: []string{localconstants.ArgShutdownTimeout}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvVerifySignatures:        {ConfigVar: []string{localconstants.ArgVerifySignatures}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvTrustedKeys:             {ConfigVar: []string{localconstants.ArgTrustedKeys}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvModIndexes:              {ConfigVar: []string{localconstants.ArgModIndex}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvReadOnly:                {ConfigVar: []string{localconstants.ArgReadOnly}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvStartSteampipe:          {ConfigVar: []string{localconstants.ArgStartSteampipe}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvSnapshotMaxAge:          {ConfigVar: []string{localconstants.ArgSnapshotMaxAge}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotMaxCount:        {ConfigVar: []string{localconstants.ArgSnapshotMaxCount}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvSnapshotMaxSize:         {ConfigVar: []string{localconstants.ArgSnapshotMaxSize}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotSigningKey:      {ConfigVar: []string{localconstants.ArgSnapshotSigningKey}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotCompression:     {ConfigVar: []string{localconstants.ArgSnapshotCompression}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotVisibility:      {ConfigVar: []string{localconstants.ArgSnapshotVisibility}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotExpiry:          {ConfigVar: []string{localconstants.ArgSnapshotExpiry}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvSnapshotWebhook:         {ConfigVar: []string{localconstants.ArgSnapshotWebhook}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvRedactColumns:           {ConfigVar: []string{localconstants.ArgRedactColumns}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvRedactMode:              {ConfigVar: []string{localconstants.ArgRedactMode}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvOffline:                 {ConfigVar: []string{localconstants.ArgOffline}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvPipesVariables:          {ConfigVar: []string{localconstants.ArgPipesVariables}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvOtelEndpoint:            {ConfigVar: []string{localconstants.ArgOtelEndpoint}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvOtelInsecure:            {ConfigVar: []string{localconstants.ArgOtelInsecure}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvPushgateway:             {ConfigVar: []string{localconstants.ArgPushgateway}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvLogFormat:               {ConfigVar: []string{localconstants.ArgLogFormat}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvQueryLog:                {ConfigVar: []string{localconstants.ArgQueryLog}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvLogFile:                 {ConfigVar: []string{localconstants.ArgLogFile}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvLogMaxSize:              {ConfigVar: []string{localconstants.ArgLogMaxSize}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvLogMaxAge:               {ConfigVar: []string{localconstants.ArgLogMaxAge}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvLogMaxFiles:             {ConfigVar: []string{localconstants.ArgLogMaxFiles}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvLogCompress:             {ConfigVar: []string{localconstants.ArgLogCompress}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvLogDestination:          {ConfigVar: []string{localconstants.ArgLogDestination}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvGitHostTokens:           {ConfigVar: []string{localconstants.ArgGitHostTokens}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvGitSSHKeys:              {ConfigVar: []string{localconstants.ArgGitSSHKeys}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvGitSSHKeyPassphrase:     {ConfigVar: []string{localconstants.ArgGitSSHKeyPassphrase}, VarType: cmdconfig.EnvVarTypeString},
 	}
 }
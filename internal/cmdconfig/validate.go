@@ -11,6 +11,10 @@ import (
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/objectstore"
+	"github.com/turbot/powerpipe/internal/pipes"
+	localsnapshot "github.com/turbot/powerpipe/internal/snapshot"
 )
 
 func ValidateSnapshotArgs(ctx context.Context) error {
@@ -21,6 +25,17 @@ func ValidateSnapshotArgs(ctx context.Context) error {
 		return fmt.Errorf("only 1 of 'share' and 'snapshot' may be set")
 	}
 
+	// snapshots written with --export are signed too, so validate the signing key first
+	if err := localsnapshot.ValidateSigningKey(); err != nil {
+		return err
+	}
+	if err := localsnapshot.ValidateCompression(); err != nil {
+		return err
+	}
+	if err := localsnapshot.ValidateShareSettings(); err != nil {
+		return err
+	}
+
 	// if neither share or snapshot are set, nothing more to do
 	if !share && !snapshot {
 		return nil
@@ -33,14 +48,24 @@ func ValidateSnapshotArgs(ctx context.Context) error {
 	if err := validateSnapshotLocation(ctx, token); err != nil {
 		return err
 	}
+	if err := validateShareSettingsLocation(); err != nil {
+		return err
+	}
 
-	// if workspace-database or snapshot-location are a cloud workspace handle, cloud token must be set
-	requireCloudToken := steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgDatabase)) ||
-		steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgSnapshotLocation))
-
-	// verify cloud token and workspace has been set
-	if requireCloudToken && token == "" {
-		return error_helpers.MissingCloudTokenError()
+	// if workspace-database or snapshot-location are a cloud workspace handle, a token must be set for the Pipes host
+	// of the workspace
+	for _, arg := range []string{constants.ArgDatabase, constants.ArgSnapshotLocation} {
+		workspace := viper.GetString(arg)
+		if !steampipeconfig.IsCloudWorkspaceIdentifier(workspace) {
+			continue
+		}
+		_, workspaceToken, err := pipes.ForWorkspace(ctx, workspace)
+		if err != nil {
+			return err
+		}
+		if workspaceToken == "" {
+			return error_helpers.MissingCloudTokenError()
+		}
 	}
 
 	// should never happen as there is a default set
@@ -56,12 +81,24 @@ func validateSnapshotLocation(ctx context.Context, cloudToken string) error {
 
 	// if snapshot location is not set, set to the users default
 	if snapshotLocation == "" {
+		if viper.GetBool(localconstants.ArgOffline) {
+			return fmt.Errorf("snapshots cannot be uploaded to Turbot Pipes in offline mode - set --%s to a directory", constants.ArgSnapshotLocation)
+		}
 		if cloudToken == "" {
 			return error_helpers.MissingCloudTokenError()
 		}
 		return setSnapshotLocationFromDefaultWorkspace(ctx, cloudToken)
 	}
 
+	// an object storage URL is written to directly
+	if objectstore.IsURL(snapshotLocation) {
+		if viper.GetBool(constants.ArgShare) {
+			return fmt.Errorf("--%s is only supported for Turbot Pipes workspaces - use --%s to write to %s", constants.ArgShare, constants.ArgSnapshot, snapshotLocation)
+		}
+		_, err := objectstore.Parse(snapshotLocation)
+		return err
+	}
+
 	// if it is NOT a workspace handle, assume it is a local file location:
 	// tildefy it and ensure it exists
 	if !steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation) {
@@ -81,6 +118,19 @@ func validateSnapshotLocation(ctx context.Context, cloudToken string) error {
 	return nil
 }
 
+// validateShareSettingsLocation checks the snapshot visibility and expiry are only set when uploading to Turbot Pipes
+func validateShareSettingsLocation() error {
+	if steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgSnapshotLocation)) {
+		return nil
+	}
+	for _, arg := range []string{localconstants.ArgSnapshotVisibility, localconstants.ArgSnapshotExpiry} {
+		if viper.GetString(arg) != "" {
+			return fmt.Errorf("--%s is only supported for Turbot Pipes workspaces", arg)
+		}
+	}
+	return nil
+}
+
 func setSnapshotLocationFromDefaultWorkspace(ctx context.Context, cloudToken string) error {
 	workspaceHandle, err := cloud.GetUserWorkspaceHandle(ctx, cloudToken)
 	if err != nil {
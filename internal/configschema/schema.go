@@ -0,0 +1,331 @@
+// Package configschema builds JSON schemas of the config and mod files, so editors and policy tools can validate them.
+//
+// The schemas describe the JSON (or YAML) form of the files, which has the structure of the HCL JSON syntax: each
+// block is a property named for the block type, whose value is an object keyed by the block label, e.g.
+//
+//	{"workspace": {"prod": {"database": "connection.prod"}}}
+//
+// As any attribute may be set by a template (e.g. "${var.region}"), attributes which are not strings may also be set
+// to a string.
+package configschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/workspaceprofile"
+)
+
+// the schema types
+const (
+	TypeConfig = "config"
+	TypeMod    = "mod"
+)
+
+const schemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON schema
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// the type name, or a list of type names
+	Type                 any                `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// ConfigSchema returns the schema of the YAML and JSON config files (see workspaceprofile.StructuredConfigExtensions),
+// which define workspace profiles and the command options of the commands of the command tree
+func ConfigSchema(root *cobra.Command) *Schema {
+	options := map[string]*Schema{}
+	for command, commands := range workspaceprofile.OptionsCommands(root) {
+		options[command] = &Schema{
+			Description:          "The flag defaults of " + commandPaths(commands),
+			Type:                 "object",
+			Properties:           optionsProperties(commands),
+			AdditionalProperties: false,
+		}
+	}
+
+	return &Schema{
+		Schema:      schemaVersion,
+		Title:       "Powerpipe config file",
+		Description: "The workspace profiles and command options of a .ppc.json, .ppc.yaml or .ppc.yml config file",
+		Type:        "object",
+		Properties: map[string]*Schema{
+			schema.BlockTypeWorkspaceProfile: labelledBlocks(schema.BlockTypeWorkspaceProfile),
+			schema.BlockTypeOptions: {
+				Description: "The command options blocks, keyed by command",
+				Type:        "object",
+				Properties:  options,
+				// options for a command which does not exist are an error
+				AdditionalProperties: false,
+			},
+		},
+		AdditionalProperties: false,
+		Defs: map[string]*Schema{
+			schema.BlockTypeWorkspaceProfile: structSchema(reflect.TypeOf(modconfig.PowerpipeWorkspaceProfile{}), nil),
+		},
+	}
+}
+
+func commandPaths(commands []*cobra.Command) string {
+	paths := make([]string, len(commands))
+	for i, cmd := range commands {
+		paths[i] = cmd.CommandPath()
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, ", ")
+}
+
+// optionsProperties returns the properties of an options block for the commands - the flags of the commands, and the
+// workspace which scopes the block
+func optionsProperties(commands []*cobra.Command) map[string]*Schema {
+	res := map[string]*Schema{
+		constants.ArgWorkspaceProfile: {Type: "string", Description: "The workspace profile the options apply to"},
+	}
+	for _, cmd := range commands {
+		add := func(f *pflag.Flag) {
+			if f.Name == constants.ArgHelp || f.Name == constants.ArgWorkspaceProfile {
+				return
+			}
+			property := flagSchema(f)
+			property.Description = f.Usage
+			res[strings.ReplaceAll(f.Name, "-", "_")] = property
+		}
+		cmd.LocalFlags().VisitAll(add)
+		cmd.InheritedFlags().VisitAll(add)
+	}
+	return res
+}
+
+func flagSchema(f *pflag.Flag) *Schema {
+	switch f.Value.Type() {
+	case "bool":
+		return typeSchema("boolean")
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return typeSchema("integer")
+	case "float32", "float64":
+		return typeSchema("number")
+	case "stringSlice", "stringArray":
+		return &Schema{Type: []string{"array", "string"}, Items: &Schema{Type: "string"}}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+// the block types of a mod file, and the structs they are decoded into
+var modBlockTypes = map[string]any{
+	schema.BlockTypeMod:       modconfig.Mod{},
+	schema.BlockTypeVariable:  modconfig.Variable{},
+	schema.BlockTypeQuery:     modconfig.Query{},
+	schema.BlockTypeControl:   modconfig.Control{},
+	schema.BlockTypeBenchmark: modconfig.Benchmark{},
+	schema.BlockTypeDashboard: modconfig.Dashboard{},
+	schema.BlockTypeContainer: modconfig.DashboardContainer{},
+	schema.BlockTypeCard:      modconfig.DashboardCard{},
+	schema.BlockTypeChart:     modconfig.DashboardChart{},
+	schema.BlockTypeFlow:      modconfig.DashboardFlow{},
+	schema.BlockTypeGraph:     modconfig.DashboardGraph{},
+	schema.BlockTypeHierarchy: modconfig.DashboardHierarchy{},
+	schema.BlockTypeImage:     modconfig.DashboardImage{},
+	schema.BlockTypeInput:     modconfig.DashboardInput{},
+	schema.BlockTypeTable:     modconfig.DashboardTable{},
+	schema.BlockTypeText:      modconfig.DashboardText{},
+	schema.BlockTypeNode:      modconfig.DashboardNode{},
+	schema.BlockTypeEdge:      modconfig.DashboardEdge{},
+	schema.BlockTypeCategory:  modconfig.DashboardCategory{},
+	schema.BlockTypeWith:      modconfig.DashboardWith{},
+	schema.BlockTypeParam:     modconfig.ParamDef{},
+}
+
+// ModSchema returns the schema of mod files in the HCL JSON syntax: the mod block, and the resource blocks
+func ModSchema() *Schema {
+	res := &Schema{
+		Schema:               schemaVersion,
+		Title:                "Powerpipe mod file",
+		Description:          "The mod and resource blocks of a mod file",
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+		Defs:                 map[string]*Schema{},
+	}
+	for blockType, resource := range modBlockTypes {
+		res.Defs[blockType] = resourceSchema(blockType, resource)
+	}
+	for _, block := range parse.WorkspaceBlockSchema.Blocks {
+		switch {
+		case block.Type == schema.BlockTypeLocals:
+			res.Properties[block.Type] = &Schema{Description: "Local values, keyed by name", Type: "object"}
+		case modBlockTypes[block.Type] != nil:
+			res.Properties[block.Type] = labelledBlocks(block.Type)
+		}
+	}
+	return res
+}
+
+// resourceSchema returns the schema of the body of a resource block - the attributes and blocks accepted by the
+// decoder of the block
+func resourceSchema(blockType string, resource any) *Schema {
+	t := reflect.TypeOf(resource)
+	// the blocks decoded with an explicit schema
+	switch blockType {
+	case schema.BlockTypeVariable:
+		return bodySchema(parse.VariableBlockSchema, t)
+	case schema.BlockTypeBenchmark:
+		return bodySchema(parse.BenchmarkBlockSchema, t)
+	case schema.BlockTypeParam:
+		return bodySchema(parse.ParamDefBlockSchema, t)
+	}
+
+	// other blocks are decoded into their struct, with the special cases of the decoder
+	extra := &hcl.BodySchema{}
+	switch blockType {
+	case schema.BlockTypeMod:
+		extra.Blocks = append(extra.Blocks, hcl.BlockHeaderSchema{Type: schema.BlockTypeRequire})
+	case schema.BlockTypeDashboard:
+		extra.Blocks = append(extra.Blocks, parse.DashboardBlockSchema.Blocks...)
+	case schema.BlockTypeContainer:
+		extra.Blocks = append(extra.Blocks, parse.DashboardContainerBlockSchema.Blocks...)
+	}
+	ptr := reflect.New(t).Interface()
+	if _, ok := ptr.(modconfig.QueryProvider); ok {
+		extra.Blocks = append(extra.Blocks, hcl.BlockHeaderSchema{Type: schema.BlockTypeParam})
+		if blockType != schema.BlockTypeQuery {
+			extra.Attributes = append(extra.Attributes, hcl.AttributeSchema{Name: schema.AttributeTypeArgs})
+		}
+	}
+	if _, ok := ptr.(modconfig.NodeAndEdgeProvider); ok {
+		extra.Blocks = append(extra.Blocks,
+			hcl.BlockHeaderSchema{Type: schema.BlockTypeCategory},
+			hcl.BlockHeaderSchema{Type: schema.BlockTypeNode},
+			hcl.BlockHeaderSchema{Type: schema.BlockTypeEdge})
+	}
+	if _, ok := ptr.(modconfig.WithProvider); ok {
+		extra.Blocks = append(extra.Blocks, hcl.BlockHeaderSchema{Type: schema.BlockTypeWith})
+	}
+
+	res := structSchema(t, extra)
+	if blockType == schema.BlockTypeQuery {
+		// a query may not refer to another query
+		delete(res.Properties, schema.AttributeTypeQuery)
+	}
+	return res
+}
+
+// bodySchema returns the schema of a block decoded with the hcl schema - the types of the attributes are taken from
+// the fields of the struct the block is decoded into
+func bodySchema(body *hcl.BodySchema, t reflect.Type) *Schema {
+	fields := structSchema(t, nil)
+	res := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	for _, a := range body.Attributes {
+		if property, ok := fields.Properties[a.Name]; ok {
+			res.Properties[a.Name] = property
+		} else {
+			res.Properties[a.Name] = &Schema{}
+		}
+	}
+	for _, b := range body.Blocks {
+		res.Properties[b.Type] = nestedBlocks(b.Type)
+	}
+	return res
+}
+
+// structSchema returns the schema of a block decoded into the struct, from the hcl tags of its fields (and of the
+// structs it embeds), with the extra attributes and blocks decoded explicitly
+func structSchema(t reflect.Type, extra *hcl.BodySchema) *Schema {
+	res := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	addFields(res, t)
+	if extra != nil {
+		for _, a := range extra.Attributes {
+			if _, ok := res.Properties[a.Name]; !ok {
+				res.Properties[a.Name] = &Schema{}
+			}
+		}
+		for _, b := range extra.Blocks {
+			res.Properties[b.Type] = nestedBlocks(b.Type)
+		}
+	}
+	return res
+}
+
+func addFields(res *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		tag, hasTag := field.Tag.Lookup("hcl")
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && !hasTag {
+			addFields(res, fieldType)
+			continue
+		}
+		name, kind, _ := strings.Cut(tag, ",")
+		switch {
+		case name == "" || kind == "label" || kind == "remain":
+			continue
+		case kind == "block":
+			res.Properties[name] = nestedBlocks(name)
+		default:
+			res.Properties[name] = attributeSchema(fieldType)
+		}
+	}
+}
+
+// attributeSchema returns the schema of an attribute decoded into a field of the type - attributes which are not
+// strings may also be set by a template
+func attributeSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return typeSchema("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typeSchema("integer")
+	case reflect.Float32, reflect.Float64:
+		return typeSchema("number")
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return &Schema{Type: []string{"array", "string"}, Items: &Schema{Type: "string"}}
+		}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.String {
+			return &Schema{Type: []string{"object", "string"}, AdditionalProperties: &Schema{Type: "string"}}
+		}
+	}
+	// references to other resources, expressions and values of any type
+	return &Schema{}
+}
+
+func typeSchema(typeName string) *Schema {
+	return &Schema{Type: []string{typeName, "string"}}
+}
+
+// labelledBlocks returns the schema of the top level blocks of the type, keyed by label
+func labelledBlocks(blockType string) *Schema {
+	return &Schema{
+		Description:          "The " + blockType + " blocks, keyed by name",
+		Type:                 "object",
+		AdditionalProperties: &Schema{Ref: "#/$defs/" + blockType},
+	}
+}
+
+// nestedBlocks returns the schema of the blocks of the type nested in another block - a block, a list of blocks or
+// the blocks keyed by label
+func nestedBlocks(blockType string) *Schema {
+	return &Schema{Description: "The nested " + blockType + " blocks", Type: []string{"object", "array"}}
+}
@@ -0,0 +1,60 @@
+package configschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestConfigSchema(t *testing.T) {
+	root := &cobra.Command{Use: "powerpipe"}
+	root.PersistentFlags().String("workspace", "default", "")
+	benchmark := &cobra.Command{Use: "benchmark"}
+	run := &cobra.Command{Use: "run", Run: func(*cobra.Command, []string) {}}
+	run.Flags().Int("max-parallel", 10, "")
+	run.Flags().StringSlice("export", nil, "")
+	benchmark.AddCommand(run)
+	root.AddCommand(benchmark)
+
+	options := ConfigSchema(root).Properties["options"].Properties
+	for _, command := range []string{"check", "benchmark", "benchmark run"} {
+		properties := options[command].Properties
+		if properties == nil {
+			t.Errorf("expected options for %s", command)
+			continue
+		}
+		if !reflect.DeepEqual(properties["max_parallel"].Type, []string{"integer", "string"}) {
+			t.Errorf("%s: expected an integer max_parallel, got %v", command, properties["max_parallel"].Type)
+		}
+		if properties["export"].Items == nil || properties["workspace"].Type != "string" {
+			t.Errorf("%s: expected a list export and a string workspace", command)
+		}
+	}
+	if _, ok := options["benchmark list"]; ok {
+		t.Errorf("expected no options for a command which does not exist")
+	}
+}
+
+func TestModSchema(t *testing.T) {
+	s := ModSchema()
+	if s.Properties["query"].AdditionalProperties.(*Schema).Ref != "#/$defs/query" {
+		t.Errorf("expected the query blocks to refer to the query definition")
+	}
+	query := s.Defs["query"].Properties
+	if query["sql"].Type != "string" || query["param"] == nil {
+		t.Errorf("expected the sql attribute and param blocks of a query")
+	}
+	if _, ok := query["query"]; ok {
+		t.Errorf("expected a query to have no query attribute")
+	}
+	if _, ok := s.Defs["card"].Properties["args"]; !ok {
+		t.Errorf("expected the args attribute of a card")
+	}
+	if _, ok := s.Defs["variable"].Properties["default"]; !ok {
+		t.Errorf("expected the default attribute of a variable")
+	}
+	if _, ok := s.Defs["graph"].Properties["node"]; !ok {
+		t.Errorf("expected the node blocks of a graph")
+	}
+}
@@ -0,0 +1,257 @@
+// Package configvalidate checks the config (.ppc, .ppc.json and .ppc.yaml) files of the config path: that the files parse, and that the
+// workspace profiles, command options, connections and pipes credentials they define are valid. It is used to
+// validate config changes in CI, before they break scheduled runs.
+//
+// The files of each config directory are first checked syntactically - the blocks of a directory are only decoded if
+// all of its files parse.
+package configvalidate
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/powerpipe/internal/connection"
+	"github.com/turbot/powerpipe/internal/pipes"
+	"github.com/turbot/powerpipe/internal/workspaceprofile"
+)
+
+// issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// validation rules
+const (
+	RuleParseError          = "parse-error"
+	RuleWorkspaceProfile    = "workspace-profile"
+	RuleConnection          = "connection"
+	RulePipesCredential     = "pipes-credential"
+	RuleCommandOptions      = "command-options"
+	RuleConfigValue         = "config-value"
+	RuleUndefinedConnection = "undefined-connection"
+	RuleShadowedProfile     = "shadowed-profile"
+)
+
+// Issue is a problem found in the config
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// the name of the workspace profile the issue was found in, if any
+	Profile string `json:"profile,omitempty"`
+	// the location of the issue
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Location returns the file and line of the issue, e.g. /home/jane/.powerpipe/config/default.ppc:12
+func (i *Issue) Location() string {
+	if i.File == "" {
+		return ""
+	}
+	if i.Line == 0 {
+		return i.File
+	}
+	return fmt.Sprintf("%s:%d", i.File, i.Line)
+}
+
+// HasErrors returns whether any of the issues are errors
+func HasErrors(issues []*Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the config files of the config directories, which are in decreasing order of precedence, returning
+// the issues found sorted by location. configErr is the error (if any) found resolving the config values of the
+// command - it is reported if the config files are otherwise valid, as it may be caused by an invalid file
+func Validate(configPaths []string, configErr error) ([]*Issue, error) {
+	v := &validator{}
+
+	// the directories whose files all parse
+	var parsedPaths []string
+	for _, configPath := range configPaths {
+		parsed, err := v.checkSyntax(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if parsed {
+			parsedPaths = append(parsedPaths, configPath)
+		}
+	}
+
+	profiles := v.checkProfiles(parsedPaths)
+	connectionsLoaded := v.checkLoad(RuleConnection, connection.Load(parsedPaths))
+	v.checkLoad(RulePipesCredential, pipes.Load(parsedPaths))
+	_, err := workspaceprofile.LoadCommandOptions(parsedPaths)
+	v.checkLoad(RuleCommandOptions, err)
+	if connectionsLoaded {
+		v.checkProfileConnections(profiles)
+	}
+
+	if configErr != nil && !HasErrors(v.issues) {
+		v.addError(RuleConfigValue, configErr)
+	}
+
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		a, b := v.issues[i], v.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+	return v.issues, nil
+}
+
+// errorLocation matches the source range which error_helpers.HclDiagsToError appends to each diagnostic
+var errorLocation = regexp.MustCompile(`\n\((.+):(\d+),\d+-[^)\n]*\)`)
+
+// the prefixes of the errors returned when decoding config blocks, which are implied by the rule of the issue
+var errorPrefixes = []string{
+	"Internal Error: ",
+	"Failed to load workspace profiles: ",
+	"Failed to decode all workspace profile files: ",
+	"failed to decode connections: ",
+	"failed to decode credentials: ",
+	"failed to decode command options: ",
+}
+
+type validator struct {
+	issues []*Issue
+}
+
+func (v *validator) add(issue *Issue) {
+	v.issues = append(v.issues, issue)
+}
+
+// addAt adds an issue at the given source range
+func (v *validator) addAt(rule, severity, profile, message string, rng *hcl.Range) {
+	issue := &Issue{Rule: rule, Severity: severity, Profile: profile, Message: message}
+	if rng != nil && rng.Filename != "" {
+		issue.File = rng.Filename
+		issue.Line = rng.Start.Line
+	}
+	v.add(issue)
+}
+
+// addError adds the error as one error issue for each of the diagnostics it was built from, located at the source
+// range of the diagnostic
+func (v *validator) addError(rule string, err error) {
+	message := err.Error()
+	for _, prefix := range errorPrefixes {
+		message = strings.TrimPrefix(message, prefix)
+	}
+	locations := errorLocation.FindAllStringSubmatchIndex(message, -1)
+	if len(locations) == 0 {
+		v.add(&Issue{Rule: rule, Severity: SeverityError, Message: strings.TrimSpace(message)})
+		return
+	}
+	start := 0
+	for _, l := range locations {
+		line, _ := strconv.Atoi(message[l[4]:l[5]])
+		v.add(&Issue{
+			Rule:     rule,
+			Severity: SeverityError,
+			Message:  strings.TrimSpace(message[start:l[0]]),
+			File:     message[l[2]:l[3]],
+			Line:     line,
+		})
+		start = l[1]
+	}
+}
+
+// checkSyntax parses the config files of the directory, returning whether they all parse
+func (v *validator) checkSyntax(configPath string) (bool, error) {
+	if !filehelpers.DirectoryExists(configPath) {
+		return false, nil
+	}
+	configFiles, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions(append([]string{app_specific.ConfigExtension}, workspaceprofile.StructuredConfigExtensions...)),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	parsed := true
+	for _, configFile := range configFiles {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return false, err
+		}
+		// the file is parsed as HCL, JSON or YAML according to its extension
+		_, diags := parse.ParseHclFiles(map[string][]byte{configFile: data})
+		for _, diag := range diags {
+			if diag.Severity != hcl.DiagError {
+				continue
+			}
+			message := diag.Summary
+			if diag.Detail != "" {
+				message += ": " + diag.Detail
+			}
+			v.addAt(RuleParseError, SeverityError, "", message, diag.Subject)
+			parsed = false
+		}
+	}
+	return parsed, nil
+}
+
+// checkProfiles decodes the workspace profiles of each directory, returning the profiles in effect (a profile
+// defined in more than one directory is shadowed by the definition with the highest precedence)
+func (v *validator) checkProfiles(configPaths []string) map[string]*modconfig.PowerpipeWorkspaceProfile {
+	res := map[string]*modconfig.PowerpipeWorkspaceProfile{}
+	for _, configPath := range configPaths {
+		profiles, err := workspaceprofile.LoadDir(configPath)
+		if err != nil {
+			v.addError(RuleWorkspaceProfile, err)
+			continue
+		}
+		for name, p := range profiles {
+			if existing, ok := res[name]; ok {
+				message := fmt.Sprintf("workspace profile is shadowed by the definition in %s", existing.GetDeclRange().Filename)
+				v.addAt(RuleShadowedProfile, SeverityWarning, name, message, p.GetDeclRange())
+				continue
+			}
+			res[name] = p
+		}
+	}
+	return res
+}
+
+// checkLoad adds the error (if any) loading the connections, pipes credentials or command options, returning whether
+// they loaded
+func (v *validator) checkLoad(rule string, err error) bool {
+	if err == nil {
+		return true
+	}
+	v.addError(rule, err)
+	return false
+}
+
+// checkProfileConnections checks the connections referred to by the database of each profile are defined
+func (v *validator) checkProfileConnections(profiles map[string]*modconfig.PowerpipeWorkspaceProfile) {
+	for name, p := range profiles {
+		if p.Database == nil || !connection.IsReference(*p.Database) {
+			continue
+		}
+		if _, err := connection.Resolve(*p.Database); err != nil {
+			v.addAt(RuleUndefinedConnection, SeverityError, name, err.Error(), p.GetDeclRange())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package configvalidate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	modDir, globalDir, brokenDir := t.TempDir(), t.TempDir(), t.TempDir()
+	modConfig := writeConfig(t, modDir, "workspaces.ppc", `
+workspace "ci" {
+  database = "connection.missing"
+}
+
+connection "prod" {
+  connection_string = "postgres://steampipe@prod:9193/steampipe"
+}
+`)
+	globalConfig := writeConfig(t, globalDir, "workspaces.ppc", `
+workspace "ci" {
+  output = "json"
+}
+`)
+	brokenConfig := writeConfig(t, brokenDir, "broken.ppc", `
+workspace "broken" {
+  output =
+}
+`)
+
+	// the config error is not reported, as the config files have errors
+	issues, err := Validate([]string{modDir, globalDir, brokenDir}, errors.New("invalid value of 'telemetry'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Issue{
+		{Rule: RuleUndefinedConnection, Severity: SeverityError, Profile: "ci", File: modConfig, Line: 2},
+		{Rule: RuleShadowedProfile, Severity: SeverityWarning, Profile: "ci", File: globalConfig, Line: 2},
+		{Rule: RuleParseError, Severity: SeverityError, File: brokenConfig, Line: 3},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for i, w := range want {
+		got := *issues[i]
+		got.Message = ""
+		if got != w {
+			t.Errorf("issue %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestValidateConfigError(t *testing.T) {
+	issues, err := Validate([]string{t.TempDir()}, errors.New("invalid value of 'telemetry'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Rule != RuleConfigValue || !HasErrors(issues) {
+		t.Errorf("expected a config value error, got %v", issues)
+	}
+}
+
+func TestAddError(t *testing.T) {
+	v := &validator{}
+	v.addError(RuleConnection, errors.New(`failed to decode connections: Missing required argument
+(/config/a.ppc:5,1-20)
+Unsupported argument
+(/config/a.ppc:6,3-7,1)
+`))
+	want := []Issue{
+		{Rule: RuleConnection, Severity: SeverityError, Message: "Missing required argument", File: "/config/a.ppc", Line: 5},
+		{Rule: RuleConnection, Severity: SeverityError, Message: "Unsupported argument", File: "/config/a.ppc", Line: 6},
+	}
+	if len(v.issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d", len(want), len(v.issues))
+	}
+	for i, w := range want {
+		if *v.issues[i] != w {
+			t.Errorf("issue %d: expected %+v, got %+v", i, w, *v.issues[i])
+		}
+	}
+}
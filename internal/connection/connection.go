@@ -0,0 +1,213 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/powerpipe/internal/encryption"
+	"github.com/turbot/powerpipe/internal/secrets"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ReferencePrefix is the prefix of a database which refers to a named connection, e.g. connection.prod_steampipe
+const ReferencePrefix = "connection."
+
+// Connection is a named database connection, defined in a config (.ppc) file in the config path, for example:
+//
+//	connection "prod_steampipe" {
+//	  connection_string  = "postgres://steampipe@prod:9193/steampipe"
+//	  search_path_prefix = ["aws_prod"]
+//	  sslmode            = "verify-full"
+//	  sslrootcert        = "~/.postgresql/prod-ca.crt"
+//	  sslcert            = "~/.postgresql/prod.crt"
+//	  sslkey             = "~/.postgresql/prod.key"
+//	}
+//
+// Connections to RDS or Cloud SQL can authenticate using IAM tokens rather than a password, by setting iam_auth to
+// "aws-rds" (with optional aws_region and aws_profile) or "gcp-cloudsql".
+//
+// As in workspace profiles, values can be read from the environment with env() - use coalesce to fall back to a
+// default when the environment variable is not set, e.g. coalesce(env("PROD_DB"), "sqlite:///local.db").
+//
+// The connection is used by setting the database of a query, control, dashboard or mod dependency (or the --database
+// arg) to "connection.prod_steampipe", so a single run can mix databases.
+type Connection struct {
+	Name             string   `hcl:"name,label"`
+	ConnectionString string   `hcl:"connection_string"`
+	SearchPath       []string `hcl:"search_path,optional"`
+	SearchPathPrefix []string `hcl:"search_path_prefix,optional"`
+	// the ssl and IAM authentication options of a Postgres connection, which are added to the connection string
+	IAMAuth     *string `hcl:"iam_auth,optional"`
+	AWSRegion   *string `hcl:"aws_region,optional"`
+	AWSProfile  *string `hcl:"aws_profile,optional"`
+	SSLMode     *string `hcl:"sslmode,optional"`
+	SSLRootCert *string `hcl:"sslrootcert,optional"`
+	SSLCert     *string `hcl:"sslcert,optional"`
+	SSLKey      *string `hcl:"sslkey,optional"`
+	// the file the connection is defined in
+	FileName string
+}
+
+// SearchPathConfig returns the search path config of the connection
+func (c *Connection) SearchPathConfig() backend.SearchPathConfig {
+	return backend.SearchPathConfig{
+		SearchPath:       c.SearchPath,
+		SearchPathPrefix: c.SearchPathPrefix,
+	}
+}
+
+// GetConnectionString returns the connection string of the connection, with the Postgres options of the connection
+// added as connection string parameters (overriding any set in the connection string) - any secrets referenced by the
+// connection string are resolved
+func (c *Connection) GetConnectionString(ctx context.Context) (string, error) {
+	connectionString, err := secrets.ResolveConnectionString(ctx, c.ConnectionString)
+	if err != nil {
+		return "", fmt.Errorf("connection '%s': %w", c.Name, err)
+	}
+
+	postgresOptions := map[string]*string{
+		"iam_auth":    c.IAMAuth,
+		"aws_region":  c.AWSRegion,
+		"aws_profile": c.AWSProfile,
+		"sslmode":     c.SSLMode,
+		"sslrootcert": c.SSLRootCert,
+		"sslcert":     c.SSLCert,
+		"sslkey":      c.SSLKey,
+	}
+	query := url.Values{}
+	for name, value := range postgresOptions {
+		if value != nil {
+			query.Set(name, *value)
+		}
+	}
+	if len(query) == 0 {
+		return connectionString, nil
+	}
+
+	if !backend.IsPostgresConnectionString(connectionString) {
+		return "", fmt.Errorf("connection '%s' sets Postgres options but is not a Postgres connection", c.Name)
+	}
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return "", fmt.Errorf("connection '%s' has an invalid connection_string: %w", c.Name, err)
+	}
+	connectionQuery := u.Query()
+	for name := range query {
+		connectionQuery.Set(name, query.Get(name))
+	}
+	u.RawQuery = connectionQuery.Encode()
+	return u.String(), nil
+}
+
+type connectionsConfig struct {
+	Connections []*Connection `hcl:"connection,block"`
+	// the other blocks of the config files (workspace profiles, options) are loaded by pipe-fittings
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// the connections loaded from the config path, keyed by name
+var connections = map[string]*Connection{}
+
+// Load loads the connections defined in the config files of the config directories, which are in decreasing order of
+// precedence - if a connection is defined in more than one directory, the first definition is used
+func Load(configPaths []string) error {
+	res := map[string]*Connection{}
+	for _, configPath := range configPaths {
+		dirConnections, err := loadDir(configPath)
+		if err != nil {
+			return err
+		}
+		for name, c := range dirConnections {
+			if _, ok := res[name]; !ok {
+				res[name] = c
+			}
+		}
+	}
+	connections = res
+	return nil
+}
+
+func loadDir(configPath string) (map[string]*Connection, error) {
+	res := map[string]*Connection{}
+	if !filehelpers.DirectoryExists(configPath) {
+		return res, nil
+	}
+	configFiles, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions([]string{app_specific.ConfigExtension}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// decode with the functions available to workspace profiles, so the same config can use env() throughout
+	evalCtx := &hcl.EvalContext{
+		Functions: encryption.ContextFunctions(configPath),
+		Variables: map[string]cty.Value{},
+	}
+
+	parser := hclparse.NewParser()
+	for _, configFile := range configFiles {
+		file, diags := parser.ParseHCLFile(configFile)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to parse config file", diags)
+		}
+		var config connectionsConfig
+		if diags := gohcl.DecodeBody(file.Body, evalCtx, &config); diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to decode connections", diags)
+		}
+		for _, c := range config.Connections {
+			if existing, ok := res[c.Name]; ok {
+				return nil, sperr.New("duplicate connection '%s' defined in %s and %s", c.Name, existing.FileName, configFile)
+			}
+			if c.ConnectionString == "" {
+				return nil, sperr.New("connection '%s' in %s has an empty connection_string", c.Name, configFile)
+			}
+			c.FileName = configFile
+			res[c.Name] = c
+		}
+	}
+	return res, nil
+}
+
+// IsReference returns whether the database refers to a named connection
+func IsReference(database string) bool {
+	return strings.HasPrefix(database, ReferencePrefix)
+}
+
+// Resolve returns the connection the database refers to - if the database is not a connection reference, it returns nil
+func Resolve(database string) (*Connection, error) {
+	name, ok := strings.CutPrefix(database, ReferencePrefix)
+	if !ok {
+		return nil, nil
+	}
+	c, ok := connections[name]
+	if !ok {
+		if len(connections) == 0 {
+			return nil, fmt.Errorf("connection '%s' is not defined - no connections are defined in the config path", name)
+		}
+		return nil, fmt.Errorf("connection '%s' is not defined - defined connections: %s", name, strings.Join(Names(), ", "))
+	}
+	return c, nil
+}
+
+// Names returns the sorted names of the loaded connections
+func Names() []string {
+	res := make([]string, 0, len(connections))
+	for name := range connections {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
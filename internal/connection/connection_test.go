@@ -0,0 +1,141 @@
+package connection
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	modDir, globalDir := t.TempDir(), t.TempDir()
+	writeConfig(t, modDir, "connections.ppc", `
+connection "prod" {
+  connection_string  = "postgres://steampipe@prod:9193/steampipe"
+  search_path_prefix = ["aws_prod"]
+}
+`)
+	writeConfig(t, globalDir, "default.ppc", `
+workspace "default" {
+  database = "connection.prod"
+}
+
+connection "prod" {
+  connection_string = "postgres://steampipe@other:9193/steampipe"
+}
+
+connection "warehouse" {
+  connection_string = "duckdb:///data/warehouse.duckdb"
+}
+`)
+
+	// the mod location has precedence over the global config directory
+	if err := Load([]string{modDir, globalDir, filepath.Join(globalDir, "missing")}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Names(); !reflect.DeepEqual(got, []string{"prod", "warehouse"}) {
+		t.Errorf("got connections %v", got)
+	}
+
+	c, err := Resolve("connection.prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ConnectionString != "postgres://steampipe@prod:9193/steampipe" || !reflect.DeepEqual(c.SearchPathConfig().SearchPathPrefix, []string{"aws_prod"}) {
+		t.Errorf("got %+v", c)
+	}
+
+	if c, err := Resolve("postgres://steampipe@prod:9193/steampipe"); c != nil || err != nil {
+		t.Errorf("expected a connection string not to be resolved, got %v, %v", c, err)
+	}
+	if _, err := Resolve("connection.missing"); err == nil || !strings.Contains(err.Error(), "prod, warehouse") {
+		t.Errorf("expected an error listing the defined connections, got %v", err)
+	}
+}
+
+func TestGetConnectionString(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	writeConfig(t, dir, "connections.ppc", `
+connection "mtls" {
+  connection_string = "postgres://steampipe@prod:9193/steampipe?sslmode=require"
+  sslmode           = "verify-full"
+  sslcert           = "~/.postgresql/prod.crt"
+  sslkey            = "~/.postgresql/prod.key"
+}
+
+connection "lite" {
+  connection_string = "sqlite:///data/lite.db"
+  sslmode           = "require"
+}
+`)
+	if err := Load([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := Resolve("connection.mtls")
+	got, err := c.GetConnectionString(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "postgres://steampipe@prod:9193/steampipe?sslcert=~%2F.postgresql%2Fprod.crt&sslkey=~%2F.postgresql%2Fprod.key&sslmode=verify-full"
+	if got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	c, _ = Resolve("connection.lite")
+	if _, err := c.GetConnectionString(context.Background()); err == nil || !strings.Contains(err.Error(), "not a Postgres connection") {
+		t.Errorf("expected an error for ssl options on a non-Postgres connection, got %v", err)
+	}
+}
+
+func TestLoadDuplicate(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	writeConfig(t, dir, "a.ppc", `connection "prod" { connection_string = "sqlite:///a.db" }`)
+	writeConfig(t, dir, "b.ppc", `connection "prod" { connection_string = "sqlite:///b.db" }`)
+
+	if err := Load([]string{dir}); err == nil || !strings.Contains(err.Error(), "duplicate connection 'prod'") {
+		t.Errorf("expected a duplicate connection error, got %v", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	writeConfig(t, dir, "connections.ppc", `
+connection "ci" {
+  connection_string = coalesce(env("POWERPIPE_TEST_CI_DB"), "sqlite:///local.db")
+  search_path       = [env("POWERPIPE_TEST_CI_SCHEMA")]
+}
+`)
+	t.Setenv("POWERPIPE_TEST_CI_SCHEMA", "aws_ci")
+
+	if err := Load([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := Resolve("connection.ci")
+	if c.ConnectionString != "sqlite:///local.db" || !reflect.DeepEqual(c.SearchPath, []string{"aws_ci"}) {
+		t.Errorf("got %+v", c)
+	}
+
+	t.Setenv("POWERPIPE_TEST_CI_DB", "postgres://steampipe@ci:9193/steampipe")
+	if err := Load([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := Resolve("connection.ci"); c.ConnectionString != "postgres://steampipe@ci:9193/steampipe" {
+		t.Errorf("expected the environment variable to be used, got %s", c.ConnectionString)
+	}
+}
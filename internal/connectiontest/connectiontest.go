@@ -0,0 +1,142 @@
+package connectiontest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/powerpipe/internal/connection"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/db_client"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+)
+
+// the statuses of a connection test
+const (
+	StatusOK            = "ok"
+	StatusConnectFailed = "connect_failed"
+	StatusAuthFailed    = "auth_failed"
+	StatusError         = "error"
+)
+
+// the queries used to read the server version of each backend
+var versionQueries = map[string]string{
+	constants.PostgresBackendName:        "select version()",
+	constants.SteampipeBackendName:       "select version()",
+	constants.MySQLBackendName:           "select version()",
+	constants.DuckDBBackendName:          "select version()",
+	constants.SQLiteBackendName:          "select sqlite_version()",
+	localconstants.ClickHouseBackendName: "select version()",
+	localconstants.SnowflakeBackendName:  "select current_version()",
+	localconstants.TrinoBackendName:      "select node_version from system.runtime.nodes limit 1",
+}
+
+// the queries used to count the schemas visible to the connection user (excluding system schemas) for each backend
+// - the schemas of a SQLite connection are its attached databases
+var schemaCountQueries = map[string]string{
+	constants.PostgresBackendName:        "select count(*) from information_schema.schemata where schema_name not in ('information_schema', 'pg_catalog') and schema_name not like 'pg\\_%'",
+	constants.SteampipeBackendName:       "select count(*) from information_schema.schemata where schema_name not in ('information_schema', 'pg_catalog') and schema_name not like 'pg\\_%'",
+	constants.MySQLBackendName:           "select count(*) from information_schema.schemata where schema_name not in ('information_schema', 'mysql', 'performance_schema', 'sys')",
+	constants.DuckDBBackendName:          "select count(*) from information_schema.schemata where schema_name not in ('information_schema', 'pg_catalog')",
+	constants.SQLiteBackendName:          "select count(*) from pragma_database_list",
+	localconstants.ClickHouseBackendName: "select count(*) from system.databases where name not in ('INFORMATION_SCHEMA', 'information_schema', 'system')",
+	localconstants.SnowflakeBackendName:  "select count(*) from information_schema.schemata where schema_name != 'INFORMATION_SCHEMA'",
+	localconstants.TrinoBackendName:      "select count(*) from information_schema.schemata where schema_name != 'information_schema'",
+}
+
+// Result is the result of testing a connection
+type Result struct {
+	Name    string `json:"name"`
+	Backend string `json:"backend,omitempty"`
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+	// the number of schemas visible to the connection user - nil if it could not be determined
+	Schemas  *int          `json:"schemas,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Passed returns whether the connection test passed
+func (r *Result) Passed() bool {
+	return r.Status == StatusOK
+}
+
+// Run tests the named connection: it connects to the database (verifying connectivity and credentials), reads the
+// server version and counts the schemas visible to the connection user
+func Run(ctx context.Context, name string) *Result {
+	start := time.Now()
+	res := &Result{Name: name}
+	defer func() {
+		res.Duration = time.Since(start)
+	}()
+
+	client, err := db_client.NewDbClient(ctx, connection.ReferencePrefix+name)
+	if err != nil {
+		res.fail(err)
+		return res
+	}
+	defer client.Close(ctx) //nolint:errcheck // nothing to do if closing the client fails
+	res.Backend = client.Backend.Name()
+
+	if query, ok := versionQueries[res.Backend]; ok {
+		version, err := queryValue(ctx, client, query)
+		if err != nil {
+			res.fail(fmt.Errorf("failed to read the server version: %w", err))
+			return res
+		}
+		res.Version = version
+	}
+
+	if query, ok := schemaCountQueries[res.Backend]; ok {
+		count, err := queryValue(ctx, client, query)
+		if err != nil {
+			res.fail(fmt.Errorf("failed to list the visible schemas: %w", err))
+			return res
+		}
+		var schemas int
+		if _, err := fmt.Sscan(count, &schemas); err != nil {
+			res.fail(fmt.Errorf("failed to list the visible schemas: unexpected count %q", count))
+			return res
+		}
+		res.Schemas = &schemas
+		if schemas == 0 {
+			res.Status = StatusError
+			res.Error = "no schemas are visible to the connection user"
+			return res
+		}
+	}
+
+	res.Status = StatusOK
+	return res
+}
+
+// fail sets the status and error of the result for the error
+func (r *Result) fail(err error) {
+	r.Error = err.Error()
+	switch {
+	case db_client.IsAuthenticationError(err):
+		r.Status = StatusAuthFailed
+	case r.Backend == "":
+		// the client could not be created or could not connect
+		r.Status = StatusConnectFailed
+	default:
+		r.Status = StatusError
+	}
+}
+
+// queryValue returns the first column of the first row of the query result as a string
+func queryValue(ctx context.Context, client *db_client.DbClient, query string) (string, error) {
+	result, err := client.ExecuteSync(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Rows) == 0 {
+		return "", fmt.Errorf("the query returned no rows")
+	}
+	row, ok := result.Rows[0].(*localqueryresult.RowResult)
+	if !ok || len(row.Data) == 0 {
+		return "", fmt.Errorf("the query returned no columns")
+	}
+	return fmt.Sprint(row.Data[0]), nil
+}
@@ -0,0 +1,70 @@
+package constants
+
+// powerpipe specific arguments
+const (
+	ArgMaxConcurrentDashboards = "max-concurrent-dashboards"
+	ArgDashboardQueueSize      = "dashboard-queue-size"
+	ArgClientRateLimit         = "client-rate-limit"
+	ArgBranding                = "branding"
+	ArgAuditLog                = "audit-log"
+	ArgAuditWebhook            = "audit-webhook"
+	ArgAuditRetention          = "audit-retention"
+	ArgShutdownTimeout         = "shutdown-timeout"
+	ArgVendor                  = "vendor"
+	ArgVerifySignatures        = "verify-signatures"
+	ArgTrustedKeys             = "trusted-keys"
+	ArgFrozen                  = "frozen"
+	ArgReplace                 = "replace"
+	ArgModIndex                = "index"
+	ArgTag                     = "tag"
+	ArgMaintainer              = "maintainer"
+	ArgPush                    = "push"
+	ArgRemote                  = "remote"
+	ArgIndexFile               = "index-file"
+	ArgTemplate                = "template"
+	ArgResources               = "resources"
+	ArgCheck                   = "check"
+	ArgWatchDependencies       = "watch-dependencies"
+	ArgOutputDir               = "output-dir"
+	ArgPrompt                  = "prompt"
+	ArgReadOnly                = "read-only"
+	ArgStartSteampipe          = "start-steampipe"
+	ArgControlDatabase         = "control-database"
+	ArgMod                     = "mod"
+	ArgResource                = "resource"
+	ArgSince                   = "since"
+	ArgUntil                   = "until"
+	ArgSnapshotMaxAge          = "snapshot-max-age"
+	ArgSnapshotMaxCount        = "snapshot-max-count"
+	ArgSnapshotMaxSize         = "snapshot-max-size"
+	ArgSnapshotSigningKey      = "snapshot-signing-key"
+	ArgSnapshotCompression     = "snapshot-compression"
+	ArgControl                 = "control"
+	ArgResourceID              = "resource-id"
+	ArgStatus                  = "status"
+	ArgBrowser                 = "browser"
+	ArgSnapshotVisibility      = "snapshot-visibility"
+	ArgSnapshotExpiry          = "snapshot-expiry"
+	ArgOffline                 = "offline"
+	ArgPending                 = "pending"
+	ArgPipesVariables          = "pipes-variables"
+	ArgBundleFormat            = "bundle-format"
+	ArgSnapshotWebhook         = "snapshot-webhook"
+	ArgRedactColumns           = "redact-columns"
+	ArgRedactMode              = "redact-mode"
+	ArgRecipient               = "recipient"
+	ArgOtelEndpoint            = "otel-endpoint"
+	ArgOtelInsecure            = "otel-insecure"
+	ArgPushgateway             = "pushgateway"
+	ArgLogFormat               = "log-format"
+	ArgQueryLog                = "query-log"
+	ArgLogFile                 = "log-file"
+	ArgLogMaxSize              = "log-max-size"
+	ArgLogMaxAge               = "log-max-age"
+	ArgLogMaxFiles             = "log-max-files"
+	ArgLogCompress             = "log-compress"
+	ArgLogDestination          = "log-destination"
+	ArgGitHostTokens           = "git-host-tokens"
+	ArgGitSSHKeys              = "git-ssh-keys"
+	ArgGitSSHKeyPassphrase     = "git-ssh-key-passphrase"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// the names of the backends implemented by powerpipe, in addition to the pipe-fittings backends
+const (
+	BigQueryBackendName   = "BigQuery"
+	ClickHouseBackendName = "ClickHouse"
+	SnowflakeBackendName  = "Snowflake"
+	TrinoBackendName      = "Trino"
+)
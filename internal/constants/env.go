@@ -1,10 +1,77 @@
 package constants
 
 const (
-	EnvListen           = "POWERPIPE_LISTEN"
-	EnvPort             = "POWERPIPE_PORT"
-	EnvBenchmarkTimeout = "POWERPIPE_BENCHMARK_TIMEOUT"
-	EnvDashboardTimeout = "POWERPIPE_DASHBOARD_TIMEOUT"
+	EnvListen                  = "POWERPIPE_LISTEN"
+	EnvPort                    = "POWERPIPE_PORT"
+	EnvBenchmarkTimeout        = "POWERPIPE_BENCHMARK_TIMEOUT"
+	EnvDashboardTimeout        = "POWERPIPE_DASHBOARD_TIMEOUT"
+	EnvMaxConcurrentDashboards = "POWERPIPE_MAX_CONCURRENT_DASHBOARDS"
+	EnvDashboardQueueSize      = "POWERPIPE_DASHBOARD_QUEUE_SIZE"
+	EnvClientRateLimit         = "POWERPIPE_CLIENT_RATE_LIMIT"
+	EnvBranding                = "POWERPIPE_BRANDING"
+	EnvAuditLog                = "POWERPIPE_AUDIT_LOG"
+	EnvAuditWebhook            = "POWERPIPE_AUDIT_WEBHOOK"
+	EnvAuditRetention          = "POWERPIPE_AUDIT_RETENTION"
+	EnvShutdownTimeout         = "POWERPIPE_SHUTDOWN_TIMEOUT"
+	// comma separated search path (or search path prefix) used for query execution
+	EnvSearchPath       = "POWERPIPE_SEARCH_PATH"
+	EnvSearchPathPrefix = "POWERPIPE_SEARCH_PATH_PREFIX"
+	EnvReadOnly         = "POWERPIPE_READ_ONLY"
+	// start the local Steampipe service if no database is specified and it is not running
+	EnvStartSteampipe = "POWERPIPE_START_STEAMPIPE"
+	// retention policy applied to the local snapshot directory
+	EnvSnapshotMaxAge   = "POWERPIPE_SNAPSHOT_MAX_AGE"
+	EnvSnapshotMaxCount = "POWERPIPE_SNAPSHOT_MAX_COUNT"
+	EnvSnapshotMaxSize  = "POWERPIPE_SNAPSHOT_MAX_SIZE"
+	// the armored private key used to sign snapshots, and its passphrase
+	EnvSnapshotSigningKey           = "POWERPIPE_SNAPSHOT_SIGNING_KEY"
+	EnvSnapshotSigningKeyPassphrase = "POWERPIPE_SNAPSHOT_SIGNING_KEY_PASSPHRASE"
+	// the compression of snapshots written to a directory or object storage
+	EnvSnapshotCompression = "POWERPIPE_SNAPSHOT_COMPRESSION"
+	// the visibility and expiry of snapshots uploaded to Turbot Pipes
+	EnvSnapshotVisibility = "POWERPIPE_SNAPSHOT_VISIBILITY"
+	EnvSnapshotExpiry     = "POWERPIPE_SNAPSHOT_EXPIRY"
+	// the webhook notified when a snapshot is uploaded
+	EnvSnapshotWebhook = "POWERPIPE_SNAPSHOT_WEBHOOK"
+	// the sensitive columns redacted in snapshots and exports, how they are redacted, and the key used to hash them
+	EnvRedactColumns = "POWERPIPE_REDACT_COLUMNS"
+	EnvRedactMode    = "POWERPIPE_REDACT_MODE"
+	EnvRedactSalt    = "POWERPIPE_REDACT_SALT"
+	// disable everything which requires network access, for air-gapped environments
+	EnvOffline = "POWERPIPE_OFFLINE"
+	// the Turbot Pipes workspace whose variable settings are used
+	EnvPipesVariables = "POWERPIPE_PIPES_VARIABLES"
+	// per-host credentials used when installing mods from private Git hosts
+	EnvGitHostTokens       = "POWERPIPE_GIT_HOST_TOKENS"
+	EnvGitSSHKeys          = "POWERPIPE_GIT_SSH_KEYS"
+	EnvGitSSHKeyPassphrase = "POWERPIPE_GIT_SSH_KEY_PASSPHRASE"
+	// signature verification of installed mods
+	EnvVerifySignatures = "POWERPIPE_VERIFY_SIGNATURES"
+	EnvTrustedKeys      = "POWERPIPE_TRUSTED_KEYS"
+	// comma separated list of the mod indexes searched by mod search
+	EnvModIndexes = "POWERPIPE_MOD_INDEXES"
+	// the age identities used to decrypt encrypted config values, and the path of a file of them
+	EnvAgeIdentity     = "POWERPIPE_AGE_IDENTITY"
+	EnvAgeIdentityFile = "POWERPIPE_AGE_IDENTITY_FILE"
+	// the OTLP gRPC endpoint traces of check and dashboard execution are exported to, and whether to connect without TLS
+	EnvOtelEndpoint = "POWERPIPE_OTEL_ENDPOINT"
+	EnvOtelInsecure = "POWERPIPE_OTEL_INSECURE"
+	// the Prometheus Pushgateway the metrics of check runs are pushed to
+	EnvPushgateway = "POWERPIPE_PUSHGATEWAY"
+	// the format of the logs - text, or json for log aggregators
+	EnvLogFormat = "POWERPIPE_LOG_FORMAT"
+	// the file (or stdout or stderr) every executed SQL statement is recorded in
+	EnvQueryLog = "POWERPIPE_QUERY_LOG"
+	// the file the logs are written to, and the policy for rotating it
+	EnvLogFile     = "POWERPIPE_LOG_FILE"
+	EnvLogMaxSize  = "POWERPIPE_LOG_MAX_SIZE"
+	EnvLogMaxAge   = "POWERPIPE_LOG_MAX_AGE"
+	EnvLogMaxFiles = "POWERPIPE_LOG_MAX_FILES"
+	EnvLogCompress = "POWERPIPE_LOG_COMPRESS"
+	// comma separated list of the destinations of the logs - stderr, file, syslog or journald
+	EnvLogDestination = "POWERPIPE_LOG_DESTINATION"
+	// EnvBrowserPath is the path to the Chrome/Chromium executable used to render dashboard images and PDFs
+	EnvBrowserPath = "POWERPIPE_BROWSER_PATH"
 	// EnvConfigDump is an undocumented variable is subject to change in the future
 	EnvConfigDump = "POWERPIPE_CONFIG_DUMP"
 )
@@ -0,0 +1,12 @@
+package constants
+
+// exit codes for powerpipe commands, in addition to those defined by pipe-fittings
+const (
+	ExitCodeModLintFailed        = 63 // mod - lint found errors
+	ExitCodeTestsFailed          = 64 // test - tests failed
+	ExitCodeFormatCheckFailed    = 65 // format - files are not formatted
+	ExitCodeBreakingChanges      = 66 // mod - breaking changes found
+	ExitCodeConnectionTestFailed = 67 // connection - connection tests failed
+	ExitCodeSnapshotVerifyFailed = 68 // snapshot - snapshot signature verification failed
+	ExitCodeConfigInvalid        = 69 // config - config validation found errors
+)
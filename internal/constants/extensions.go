@@ -2,4 +2,8 @@ package constants
 
 const (
 	SnapshotExtension = ".pps"
+	PngExtension      = ".png"
+	PdfExtension      = ".pdf"
+	MarkdownExtension = ".md"
+	HtmlExtension     = ".html"
 )
@@ -38,6 +38,21 @@ const (
 // powerpipe snapshot
 const OutputFormatPpSnapshotShort = "pps"
 
+// check run metrics
+const OutputFormatOpenMetrics = "openmetrics"
+
+// rendered dashboard export formats
+const (
+	OutputFormatPng = "png"
+	OutputFormatPdf = "pdf"
+)
+
+// mod graph formats
+const (
+	OutputFormatDot     = "dot"
+	OutputFormatMermaid = "mermaid"
+)
+
 var QueryOutputModeIds = map[QueryOutputMode][]string{
 	QueryOutputModeCsv:           {constants.OutputFormatCSV},
 	QueryOutputModeJson:          {constants.OutputFormatJSON},
@@ -89,3 +104,17 @@ var CheckOutputModeIds = map[CheckOutputMode][]string{
 	CheckOutputModeSnapshotShort: {OutputFormatPpSnapshotShort},
 	CheckOutputModeNone:          {constants.OutputFormatNone},
 }
+
+type GraphOutputMode enumflag.Flag
+
+const (
+	GraphOutputModeDot GraphOutputMode = iota
+	GraphOutputModeMermaid
+	GraphOutputModeJson
+)
+
+var GraphOutputModeIds = map[GraphOutputMode][]string{
+	GraphOutputModeDot:     {OutputFormatDot},
+	GraphOutputModeMermaid: {OutputFormatMermaid},
+	GraphOutputModeJson:    {constants.OutputFormatJSON},
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/turbot/pipe-fittings/contexthelpers"
 	"github.com/turbot/pipe-fittings/export"
 	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/redact"
 )
 
 var contextKeyFormatterPurpose = contexthelpers.ContextKey("formatter_purpose")
@@ -32,6 +33,11 @@ func (e *ControlExporter) Export(ctx context.Context, input export.ExportSourceD
 	if !ok {
 		return fmt.Errorf("ControlExporter input must be *controlexecute.ExecutionTree")
 	}
+	// redact the values of sensitive columns - snapshots are redacted when they are encoded
+	if _, isSnapshot := e.formatter.(*SnapshotFormatter); !isSnapshot {
+		restore := redactTree(tree, redact.Current())
+		defer restore()
+	}
 	res, err := e.formatter.Format(exportCtx, tree)
 	if err != nil {
 		return err
@@ -25,6 +25,7 @@ func NewFormatResolver() (*FormatResolver, error) {
 		&NullFormatter{},
 		&TextFormatter{},
 		&SnapshotFormatter{},
+		&OpenMetricsFormatter{},
 	}
 
 	res := &FormatResolver{
@@ -88,6 +89,16 @@ func (r *FormatResolver) controlExporters() []export.Exporter {
 	return res
 }
 
+func (r *FormatResolver) templateExporters() []export.Exporter {
+	var res []export.Exporter
+	for _, formatter := range r.exportFormatters {
+		if _, ok := formatter.(*TemplateFormatter); ok {
+			res = append(res, NewControlExporter(formatter))
+		}
+	}
+	return res
+}
+
 func loadAvailableTemplates() ([]*OutputTemplate, error) {
 	templateDirectories, err := files.ListFiles(filepaths.EnsureTemplateDir(), &files.ListOptions{
 		Flags:   files.DirectoriesFlat | files.NotEmpty,
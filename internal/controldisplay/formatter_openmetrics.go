@@ -0,0 +1,32 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/turbot/powerpipe/internal/checkmetrics"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+// OpenMetricsFormatter formats the summary metrics of the run in the OpenMetrics text format
+type OpenMetricsFormatter struct {
+	FormatterBase
+}
+
+func (f *OpenMetricsFormatter) Format(_ context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	var b bytes.Buffer
+	if err := checkmetrics.Write(&b, tree); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (f *OpenMetricsFormatter) FileExtension() string {
+	return ".prom"
+}
+
+func (f OpenMetricsFormatter) Name() string {
+	return localconstants.OutputFormatOpenMetrics
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/turbot/pipe-fittings/constants"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/snapshot"
 )
 
 type SnapshotFormatter struct {
@@ -16,7 +17,7 @@ type SnapshotFormatter struct {
 }
 
 func (f *SnapshotFormatter) Format(ctx context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
-	snapshot, err := executionTreeToSnapshot(tree)
+	snap, err := executionTreeToSnapshot(tree)
 	if err != nil {
 		return nil, err
 	}
@@ -27,8 +28,12 @@ func (f *SnapshotFormatter) Format(ctx context.Context, tree *controlexecute.Exe
 	if formatterPurpose, ok := ctx.Value(contextKeyFormatterPurpose).(string); ok && formatterPurpose == formatterPurposeExport {
 		indent = false
 	}
+	tags, err := snapshot.TagsFromConfig()
+	if err != nil {
+		return nil, err
+	}
 	// strip unwanted fields from the snapshot
-	snapshotStr, err := snapshot.AsStrippedJson(indent)
+	snapshotStr, err := snapshot.AsJson(snap, tags, indent)
 	if err != nil {
 		return nil, err
 	}
@@ -13,3 +13,13 @@ func GetExporters() ([]export.Exporter, error) {
 	exporters := formatResolver.controlExporters()
 	return exporters, nil
 }
+
+// GetTemplateExporters returns an array of ControlExporters for the template based output formats - these render
+// only the results in the execution tree, so can export a tree rebuilt from a snapshot
+func GetTemplateExporters() ([]export.Exporter, error) {
+	formatResolver, err := NewFormatResolver()
+	if err != nil {
+		return nil, err
+	}
+	return formatResolver.templateExporters(), nil
+}
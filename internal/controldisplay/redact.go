@@ -0,0 +1,62 @@
+package controldisplay
+
+import (
+	"fmt"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/redact"
+)
+
+// redactTree redacts the values of the sensitive columns of the control results in the tree, returning a function
+// which restores the original values - the tree is redacted only while it is exported, as the results have already
+// been displayed
+func redactTree(tree *controlexecute.ExecutionTree, policy *redact.Policy) func() {
+	var restores []func()
+	restore := func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+	if policy.Empty() || tree.Root == nil {
+		return restore
+	}
+	var walk func(group *controlexecute.ResultGroup, ancestors []string)
+	walk = func(group *controlexecute.ResultGroup, ancestors []string) {
+		ancestors = append(append([]string{}, ancestors...), group.GroupId)
+		for _, run := range group.ControlRuns {
+			resources := append([]string{run.FullName}, ancestors...)
+			for _, row := range run.Rows {
+				restores = append(restores, redactRow(row, resources, policy))
+			}
+		}
+		for _, child := range group.Groups {
+			walk(child, ancestors)
+		}
+	}
+	walk(tree.Root, nil)
+	return restore
+}
+
+func redactRow(row *controlexecute.ResultRow, resources []string, policy *redact.Policy) func() {
+	original := *row
+	original.Dimensions = append([]controlexecute.Dimension{}, row.Dimensions...)
+	if policy.Redacts(resources, "reason") {
+		row.Reason = fmt.Sprint(policy.Value(row.Reason))
+	}
+	if policy.Redacts(resources, "resource") {
+		row.Resource = fmt.Sprint(policy.Value(row.Resource))
+	}
+	dimensions := make([]controlexecute.Dimension, len(row.Dimensions))
+	for i, d := range row.Dimensions {
+		if policy.Redacts(resources, d.Key) {
+			d.Value = fmt.Sprint(policy.Value(d.Value))
+		}
+		dimensions[i] = d
+	}
+	row.Dimensions = dimensions
+	return func() {
+		row.Reason = original.Reason
+		row.Resource = original.Resource
+		row.Dimensions = original.Dimensions
+	}
+}
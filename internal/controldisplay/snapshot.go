@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
 
-	"github.com/turbot/pipe-fittings/cloud"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/statushooks"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/snapshot"
 )
 
 func executionTreeToSnapshot(e *controlexecute.ExecutionTree) (*steampipeconfig.SteampipeSnapshot, error) {
@@ -64,12 +64,12 @@ func PublishSnapshot(ctx context.Context, e *controlexecute.ExecutionTree, shoul
 	statushooks.Show(ctx)
 	defer statushooks.Done(ctx)
 
-	snapshot, err := executionTreeToSnapshot(e)
+	snap, err := executionTreeToSnapshot(e)
 	if err != nil {
 		return err
 	}
 
-	message, err := cloud.PublishSnapshot(ctx, snapshot, shouldShare)
+	message, err := snapshot.Publish(ctx, snap, shouldShare)
 	if err != nil {
 		return err
 	}
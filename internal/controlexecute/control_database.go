@@ -0,0 +1,78 @@
+package controlexecute
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/modconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// controlDatabaseRule selects the database the controls matching the pattern are run against
+type controlDatabaseRule struct {
+	pattern  string
+	database string
+}
+
+// parseControlDatabaseRules parses the control database args, of the form <pattern>=<database>, e.g.
+// aws_compliance.benchmark.cis_v300=connection.prod
+func parseControlDatabaseRules(args []string) ([]controlDatabaseRule, error) {
+	rules := make([]controlDatabaseRule, 0, len(args))
+	for _, arg := range args {
+		pattern, database, ok := strings.Cut(arg, "=")
+		pattern, database = strings.TrimSpace(pattern), strings.TrimSpace(database)
+		if !ok || pattern == "" || database == "" {
+			return nil, fmt.Errorf("invalid --%s '%s' - expected <control or benchmark>=<database>", localconstants.ArgControlDatabase, arg)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --%s pattern '%s': %w", localconstants.ArgControlDatabase, pattern, err)
+		}
+		rules = append(rules, controlDatabaseRule{pattern: pattern, database: database})
+	}
+	return rules, nil
+}
+
+// controlDatabaseRulesFromConfig returns the control database rules set by the --control-database args
+func controlDatabaseRulesFromConfig() ([]controlDatabaseRule, error) {
+	return parseControlDatabaseRules(viper.GetStringSlice(localconstants.ArgControlDatabase))
+}
+
+// matchControlDatabaseRule returns the database of the first rule matching the control, or any of the benchmarks it
+// is run as part of - the pattern is matched against the full name, unqualified name and short name of each
+func matchControlDatabaseRule(rules []controlDatabaseRule, control *modconfig.Control, group *ResultGroup) (string, bool) {
+	items := []modconfig.ModTreeItem{control}
+	for g := group; g != nil; g = g.Parent {
+		if g.GroupItem != nil {
+			items = append(items, g.GroupItem)
+		}
+	}
+	for _, rule := range rules {
+		for _, item := range items {
+			for _, name := range resourceNames(item) {
+				if matched, _ := path.Match(rule.pattern, name); matched {
+					return rule.database, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// resourceNames returns the names a resource may be referred to by, e.g. aws_compliance.benchmark.cis_v300,
+// benchmark.cis_v300 and cis_v300
+func resourceNames(item modconfig.ModTreeItem) []string {
+	return []string{item.Name(), item.GetUnqualifiedName(), item.GetShortName()}
+}
+
+// redactedDatabase returns the database for display, with the password of a connection string removed
+func redactedDatabase(database string) string {
+	if u, err := url.Parse(database); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+	}
+	return database
+}
@@ -19,9 +19,12 @@ import (
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/querylog"
 	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
 	"github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/powerpipe/internal/tracing"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ControlRun is a struct representing the execution of a control run. It will contain one or more result items (i.e. for one or more resources).
@@ -61,10 +64,14 @@ type ControlRun struct {
 
 	// execution duration
 	Duration time.Duration `json:"-"`
+	// the timing of the control query, once the results have been read (nil if the query did not complete)
+	Timing *localqueryresult.TimingMetadata `json:"-"`
 	// parent result group
 	Group *ResultGroup `json:"-"`
 	// execution tree
 	Tree *ExecutionTree `json:"-"`
+	// the database the control was run against, if not the default database
+	Database string `json:"database,omitempty"`
 	// save run error as string for JSON export
 	RunErrorString string `json:"error,omitempty"`
 	runError       error
@@ -75,6 +82,8 @@ type ControlRun struct {
 	doneChan    chan bool
 	attempts    int
 	startTime   time.Time
+	// the time waiting for a slot to run in
+	queueTime time.Duration
 }
 
 func NewControlRun(control *modconfig.Control, group *ResultGroup, executionTree *ExecutionTree) (*ControlRun, error) {
@@ -177,9 +186,9 @@ func (r *ControlRun) setError(ctx context.Context, err error) {
 	if err.Error() == context.DeadlineExceeded.Error() {
 		// had the control started?
 		if r.RunStatus == dashboardtypes.RunRunning {
-			r.runError = fmt.Errorf("control execution timed out after running for %0.2fs", time.Since(r.startTime).Seconds())
+			r.runError = db_client.NewTimeoutError("control execution timed out after running for %0.2fs", time.Since(r.startTime).Seconds())
 		} else {
-			r.runError = fmt.Errorf("execution timed out before control started")
+			r.runError = db_client.NewTimeoutError("execution timed out before control started")
 		}
 	} else {
 		r.runError = error_helpers.TransformErrorToSteampipe(err)
@@ -187,9 +196,12 @@ func (r *ControlRun) setError(ctx context.Context, err error) {
 	r.RunErrorString = r.runError.Error()
 	// update error count
 	r.Summary.Error++
-	if error_helpers.IsContextCancelledError(err) {
+	switch {
+	case error_helpers.IsContextCancelledError(err):
 		r.setRunStatus(ctx, dashboardtypes.RunCanceled)
-	} else {
+	case db_client.IsTimeoutError(err):
+		r.setRunStatus(ctx, dashboardtypes.RunTimeout)
+	default:
 		r.setRunStatus(ctx, dashboardtypes.RunError)
 	}
 
@@ -229,7 +241,7 @@ func (r *ControlRun) execute(ctx context.Context, client *db_client.DbClient) {
 	r.Tree.Progress.OnControlStart(ctx, r)
 	defer func() {
 		// update Progress
-		if r.GetRunStatus() == dashboardtypes.RunError {
+		if status := r.GetRunStatus(); status == dashboardtypes.RunError || status == dashboardtypes.RunTimeout {
 			r.Tree.Progress.OnControlError(ctx, r)
 		} else {
 			r.Tree.Progress.OnControlComplete(ctx, r)
@@ -243,15 +255,28 @@ func (r *ControlRun) execute(ctx context.Context, client *db_client.DbClient) {
 		return
 	}
 
-	controlExecutionCtx := r.getControlQueryContext(ctx)
+	// if the control selects a different database, run it against that database
+	defaultClient := client
+	client, err = r.Tree.getControlClient(ctx, control, r.Group, client)
+	if err != nil {
+		r.setError(ctx, err)
+		return
+	}
+	if client != defaultClient {
+		r.Database = redactedDatabase(client.GetConnectionString())
+	}
+
+	controlExecutionCtx := querylog.WithResource(r.getControlQueryContext(ctx), control.Name())
 
 	// execute the control query
 	// NOTE no need to pass an OnComplete callback - we are already closing our session after waiting for results
 	slog.Debug("execute start", "name", r.Control.Name())
+	controlExecutionCtx, span := tracing.StartQuerySpan(controlExecutionCtx, resolvedQuery.Name, resolvedQuery.ExecuteSQL)
 	queryResult, err := client.Execute(controlExecutionCtx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
 	slog.Debug("execute finish", "name", r.Control.Name())
 
 	if err != nil {
+		tracing.EndSpan(span, err)
 		r.attempts++
 
 		// is this an rpc EOF error - meaning that the plugin somehow crashed
@@ -275,6 +300,8 @@ func (r *ControlRun) execute(ctx context.Context, client *db_client.DbClient) {
 	slog.Debug("wait result", "name", r.Control.Name())
 	r.waitForResults(ctx)
 	slog.Debug("finish result", "name", r.Control.Name())
+	span.SetAttributes(attribute.Int(tracing.AttributeRows, len(r.Rows)))
+	tracing.EndSpan(span, r.GetError())
 }
 
 // create a context with status updates disabled (we do not want to show 'loading' results)
@@ -294,10 +321,20 @@ func (r *ControlRun) resolveControlQuery(control *modconfig.Control) (*modconfig
 }
 
 func (r *ControlRun) waitForResults(ctx context.Context) {
+	complete := false
 	defer func() {
+		renderStartTime := time.Now()
 		dimensionsSchema := r.getDimensionSchema()
 		// convert the data to snapshot format
 		r.Data = r.Rows.ToLeafData(dimensionsSchema)
+		// the timing of the query result is only complete once all rows have been read
+		if complete && r.queryResult.Timing != nil {
+			timing := *r.queryResult.Timing
+			timing.Queue += r.queueTime
+			timing.Render = time.Since(renderStartTime)
+			timing.Duration += r.queueTime + timing.Render
+			r.Timing = &timing
+		}
 	}()
 
 	for {
@@ -309,6 +346,7 @@ func (r *ControlRun) waitForResults(ctx context.Context) {
 			// nil row means control run is complete
 			if row == nil {
 				// nil row means we are done
+				complete = true
 				r.setRunStatus(ctx, dashboardtypes.RunComplete)
 				r.createdOrderedResultRows()
 				return
@@ -10,9 +10,12 @@ import (
 	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/workspace"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/timingreport"
+	"github.com/turbot/powerpipe/internal/tracing"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -30,6 +33,10 @@ type ExecutionTree struct {
 	SearchPath []string             `json:"-"`
 	Workspace  *workspace.Workspace `json:"-"`
 	client     *db_client.DbClient
+	// clients for the controls which select a database other than the default
+	clients *db_client.ClientMap
+	// the rules selecting the database of controls, set by the --control-database args
+	databaseRules []controlDatabaseRule
 	// an optional map of control names used to filter the controls which are run
 	controlNameFilterMap map[string]struct{}
 }
@@ -39,6 +46,7 @@ func NewExecutionTree(ctx context.Context, workspace *workspace.Workspace, clien
 	executionTree := &ExecutionTree{
 		Workspace: workspace,
 		client:    client,
+		clients:   db_client.NewClientMap(),
 	}
 
 	// if backend supports search path, get it
@@ -46,8 +54,14 @@ func NewExecutionTree(ctx context.Context, workspace *workspace.Workspace, clien
 		executionTree.SearchPath = sp.RequiredSearchPath()
 	}
 
+	databaseRules, err := controlDatabaseRulesFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	executionTree.databaseRules = databaseRules
+
 	// if a "--where" or "--tag" parameter was passed, build a map of control names used to filter the controls to run
-	err := executionTree.populateControlFilterMap(controlFilter)
+	err = executionTree.populateControlFilterMap(controlFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +87,39 @@ func NewExecutionTree(ctx context.Context, workspace *workspace.Workspace, clien
 	return executionTree, nil
 }
 
+// getControlClient returns the client to run the control with. If the control selects a database (or search path),
+// either itself, through a parent benchmark, through its query, through a --control-database rule matching the
+// control or a benchmark it is run as part of, or through the require block of its dependency mod, a client for that
+// database is returned - otherwise the default client is returned.
+func (e *ExecutionTree) getControlClient(ctx context.Context, control *modconfig.Control, group *ResultGroup, defaultClient *db_client.DbClient) (*db_client.DbClient, error) {
+	database, searchPathConfig, err := db_client.GetDatabaseConfigForResource(control, e.Workspace.Mod, "", backend.SearchPathConfig{})
+	if err != nil {
+		return nil, err
+	}
+	if ruleDatabase, ok := matchControlDatabaseRule(e.databaseRules, control, group); ok {
+		database = ruleDatabase
+	}
+	if controlDatabase := control.GetDatabase(); controlDatabase != nil {
+		database = *controlDatabase
+	} else if query := control.GetQuery(); query != nil && query.GetDatabase() != nil {
+		database = *query.GetDatabase()
+	}
+	if searchPath := control.GetSearchPath(); len(searchPath) > 0 {
+		searchPathConfig.SearchPath = searchPath
+	}
+	if searchPathPrefix := control.GetSearchPathPrefix(); len(searchPathPrefix) > 0 {
+		searchPathConfig.SearchPathPrefix = searchPathPrefix
+	}
+
+	if database == "" {
+		if searchPathConfig.Empty() {
+			return defaultClient, nil
+		}
+		database = defaultClient.GetConnectionString()
+	}
+	return e.clients.GetOrCreate(ctx, database, searchPathConfig)
+}
+
 // IsExportSourceData implements ExportSourceData
 func (*ExecutionTree) IsExportSourceData() {}
 
@@ -98,6 +145,8 @@ func (e *ExecutionTree) AddControl(ctx context.Context, control *modconfig.Contr
 func (e *ExecutionTree) Execute(ctx context.Context) error {
 	slog.Debug("begin ExecutionTree.Execute")
 	defer slog.Debug("end ExecutionTree.Execute")
+	ctx, span := tracing.StartSpan(ctx, "check", "")
+	defer span.End()
 	e.StartTime = time.Now()
 	e.Progress.Start(ctx)
 
@@ -121,6 +170,10 @@ func (e *ExecutionTree) Execute(ctx context.Context) error {
 	if err := e.waitForActiveRunsToComplete(ctx, parallelismLock, maxParallelGoRoutines); err != nil {
 		slog.Warn("timed out waiting for active runs to complete")
 	}
+	// close the clients of any other databases used by controls
+	if err := e.clients.Close(ctx); err != nil {
+		slog.Warn("failed to close database clients", "error", err)
+	}
 
 	// now build map of dimension property name to property value to color map
 	e.DimensionColorGenerator, _ = NewDimensionColorGenerator(4, 27)
@@ -183,6 +236,15 @@ func (e *ExecutionTree) getControlMapFromFilter(controlFilter workspace.Resource
 	return res, nil
 }
 
+// TimingReport returns the timing of the control queries of the executed tree
+func (e *ExecutionTree) TimingReport(name string) *timingreport.Report {
+	res := &timingreport.Report{Name: name, Duration: e.EndTime.Sub(e.StartTime)}
+	for _, r := range e.ControlRuns {
+		res.Add(r.Control.Name(), schema.BlockTypeControl, len(r.Rows), r.Timing)
+	}
+	return res
+}
+
 func (e *ExecutionTree) GetAllTags() []string {
 	// map keep track which tags have been added as columns
 	tagColumnMap := make(map[string]bool)
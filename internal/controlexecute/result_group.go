@@ -17,6 +17,9 @@ import (
 	"github.com/turbot/pipe-fittings/steampipeconfig"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -54,6 +57,8 @@ type ResultGroup struct {
 
 	childrenComplete   uint32
 	executionStartTime time.Time
+	// the trace span of the group execution
+	span trace.Span
 	// lock to prevent multiple control_runs updating this
 	updateLock *sync.Mutex
 }
@@ -267,6 +272,13 @@ func (r *ResultGroup) onChildDone() {
 
 	// all children are done
 	r.Duration = time.Since(r.executionStartTime)
+	if r.span != nil {
+		r.span.SetAttributes(
+			attribute.Int(tracing.AttributeAlarm, r.Summary.Status.Alarm),
+			attribute.Int(tracing.AttributeOk, r.Summary.Status.Ok),
+			attribute.Int(tracing.AttributeError, r.Summary.Status.Error))
+		r.span.End()
+	}
 	if r.Parent != nil {
 		r.Parent.onChildDone()
 	}
@@ -291,10 +303,7 @@ func (r *ResultGroup) updateSeverityCounts(severity string, summary *controlstat
 	r.updateLock.Lock()
 	defer r.updateLock.Unlock()
 
-	val, exists := r.Severity[severity]
-	if !exists {
-		val = controlstatus.StatusSummary{}
-	}
+	val := r.Summary.Severity[severity]
 	val.Alarm += summary.Alarm
 	val.Error += summary.Error
 	val.Info += summary.Info
@@ -312,6 +321,14 @@ func (r *ResultGroup) execute(ctx context.Context, client *db_client.DbClient, p
 	defer slog.Debug("end ResultGroup.Execute", "group id", r.GroupId)
 
 	r.executionStartTime = time.Now()
+	// the root group is traced as the execution tree
+	if r.GroupId != RootResultGroupName {
+		ctx, r.span = tracing.StartSpan(ctx, r.NodeType, r.GroupId)
+		// in a dry run (or for an empty group) no child reports completion, so end the span now
+		if viper.GetBool(constants.ArgDryRun) || len(r.ControlRuns)+len(r.Groups) == 0 {
+			defer r.span.End()
+		}
+	}
 
 	for _, controlRun := range r.ControlRuns {
 		if error_helpers.IsContextCanceled(ctx) {
@@ -324,11 +341,13 @@ func (r *ResultGroup) execute(ctx context.Context, client *db_client.DbClient, p
 			continue
 		}
 
+		queueStartTime := time.Now()
 		err := parallelismLock.Acquire(ctx, 1)
 		if err != nil {
 			controlRun.setError(ctx, err)
 			continue
 		}
+		controlRun.queueTime = time.Since(queueStartTime)
 
 		go executeRun(ctx, controlRun, parallelismLock, client)
 	}
@@ -338,6 +357,11 @@ func (r *ResultGroup) execute(ctx context.Context, client *db_client.DbClient, p
 }
 
 func executeRun(ctx context.Context, run *ControlRun, parallelismLock *semaphore.Weighted, client *db_client.DbClient) {
+	ctx, span := tracing.StartSpan(ctx, schema.BlockTypeControl, run.Control.Name())
+	defer func() {
+		span.SetAttributes(attribute.String(tracing.AttributeStatus, string(run.GetRunStatus())))
+		tracing.EndSpan(span, run.GetError())
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			// if the Execute panic'ed, set it as an error
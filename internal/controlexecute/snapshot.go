@@ -0,0 +1,183 @@
+package controlexecute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+// snapshotFile is the subset of a benchmark or control snapshot used to rebuild its execution tree
+type snapshotFile struct {
+	Layout     *steampipeconfig.SnapshotTreeNode `json:"layout"`
+	Panels     map[string]json.RawMessage        `json:"panels"`
+	SearchPath []string                          `json:"search_path"`
+	StartTime  time.Time                         `json:"start_time"`
+	EndTime    time.Time                         `json:"end_time"`
+}
+
+// NewExecutionTreeFromSnapshot rebuilds the execution tree of a benchmark or control run from its snapshot, so the
+// results of a previous run can be rendered by the control formatters without re-running the controls.
+// The tree has no workspace and its result groups have no GroupItem, so only formatters which depend solely on
+// the results (i.e. the template formatters) can render it
+func NewExecutionTreeFromSnapshot(data []byte) (*ExecutionTree, error) {
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if snap.Layout == nil {
+		return nil, errors.New("invalid snapshot: no layout")
+	}
+	if snap.Layout.NodeType != schema.BlockTypeBenchmark && snap.Layout.NodeType != schema.BlockTypeControl {
+		return nil, fmt.Errorf("'%s' is a %s snapshot - only benchmark and control snapshots can be exported", snap.Layout.Name, snap.Layout.NodeType)
+	}
+
+	tree := &ExecutionTree{
+		SearchPath: snap.SearchPath,
+		StartTime:  snap.StartTime,
+		EndTime:    snap.EndTime,
+	}
+	tree.Root = &ResultGroup{
+		GroupId:    RootResultGroupName,
+		Groups:     []*ResultGroup{},
+		Tags:       make(map[string]string),
+		Summary:    NewGroupSummary(),
+		Severity:   make(map[string]controlstatus.StatusSummary),
+		updateLock: new(sync.Mutex),
+		NodeType:   schema.BlockTypeBenchmark,
+		Duration:   snap.EndTime.Sub(snap.StartTime),
+	}
+	// controls from the mod of the snapshot root are identified by their unqualified name, as in NewControlRun
+	rootMod := strings.Split(snap.Layout.Name, ".")[0]
+	if err := tree.addSnapshotNode(snap.Panels, snap.Layout, tree.Root, rootMod); err != nil {
+		return nil, err
+	}
+	if len(tree.Root.Children) > 0 {
+		switch child := tree.Root.Children[0].(type) {
+		case *ResultGroup:
+			tree.Root.Title = child.Title
+		case *ControlRun:
+			tree.Root.Title = child.Title
+		}
+	}
+
+	tree.Progress = controlstatus.NewControlProgress(len(tree.ControlRuns))
+	tree.DimensionColorGenerator, _ = NewDimensionColorGenerator(4, 27)
+	tree.DimensionColorGenerator.populate(tree)
+	return tree, nil
+}
+
+func (e *ExecutionTree) addSnapshotNode(panels map[string]json.RawMessage, node *steampipeconfig.SnapshotTreeNode, parent *ResultGroup, rootMod string) error {
+	panel, ok := panels[node.Name]
+	if !ok {
+		return fmt.Errorf("invalid snapshot: no panel for '%s'", node.Name)
+	}
+
+	if node.NodeType == schema.BlockTypeControl {
+		run, err := newControlRunFromSnapshot(panel, parent, e, rootMod)
+		if err != nil {
+			return err
+		}
+		parent.addControl(run)
+		e.ControlRuns = append(e.ControlRuns, run)
+		return nil
+	}
+
+	group := &ResultGroup{}
+	if err := json.Unmarshal(panel, group); err != nil {
+		return fmt.Errorf("invalid snapshot panel '%s': %w", node.Name, err)
+	}
+	// the summaries are recalculated from the control runs
+	group.Summary = NewGroupSummary()
+	group.Severity = make(map[string]controlstatus.StatusSummary)
+	group.Groups = []*ResultGroup{}
+	group.Parent = parent
+	group.updateLock = new(sync.Mutex)
+	for _, child := range node.Children {
+		if err := e.addSnapshotNode(panels, child, group, rootMod); err != nil {
+			return err
+		}
+	}
+	parent.addResultGroup(group)
+	return nil
+}
+
+func newControlRunFromSnapshot(panel json.RawMessage, group *ResultGroup, executionTree *ExecutionTree, rootMod string) (*ControlRun, error) {
+	run := &ControlRun{}
+	if err := json.Unmarshal(panel, run); err != nil {
+		return nil, fmt.Errorf("invalid snapshot control panel: %w", err)
+	}
+	run.Group = group
+	run.Tree = executionTree
+	run.rowMap = make(map[string]ResultRows)
+	run.Summary = &controlstatus.StatusSummary{}
+	if severity, ok := run.Properties["severity"].(string); ok {
+		run.Severity = severity
+	}
+	run.Control = controlFromSnapshot(run)
+	run.ControlId = run.Control.Name()
+	if strings.HasPrefix(run.FullName, rootMod+".") {
+		run.ControlId = run.Control.UnqualifiedName
+	}
+
+	if run.Data != nil {
+		for _, data := range run.Data.Rows {
+			row := &ResultRow{
+				Run:     run,
+				Control: run.Control,
+			}
+			for _, c := range run.Data.Columns {
+				val := data[c.Name]
+				switch c.Name {
+				case "reason":
+					row.Reason = typehelpers.ToString(val)
+				case "resource":
+					row.Resource = typehelpers.ToString(val)
+				case "status":
+					row.Status = typehelpers.ToString(val)
+				default:
+					if c.IsScalar(val) {
+						row.AddDimension(c, val)
+					}
+				}
+			}
+			run.addResultRow(row)
+		}
+	}
+	run.createdOrderedResultRows()
+	if run.RunErrorString != "" {
+		run.runError = errors.New(run.RunErrorString)
+		run.Summary.Error++
+	}
+	run.getDimensionSchema()
+
+	group.updateSummary(run.Summary)
+	if len(run.Severity) != 0 {
+		group.updateSeverityCounts(run.Severity, run.Summary)
+	}
+	return run, nil
+}
+
+// controlFromSnapshot creates a control with the properties of the control run which are stored in the snapshot
+func controlFromSnapshot(run *ControlRun) *modconfig.Control {
+	control := &modconfig.Control{}
+	control.FullName = run.FullName
+	control.ShortName = run.FullName[strings.LastIndex(run.FullName, ".")+1:]
+	control.UnqualifiedName = fmt.Sprintf("%s.%s", schema.BlockTypeControl, control.ShortName)
+	control.Title = &run.Title
+	control.Description = &run.Description
+	control.Documentation = &run.Documentation
+	control.Tags = run.Tags
+	if run.Severity != "" {
+		control.Severity = &run.Severity
+	}
+	return control
+}
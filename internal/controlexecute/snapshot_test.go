@@ -0,0 +1,80 @@
+package controlexecute
+
+import (
+	"testing"
+)
+
+const testBenchmarkSnapshot = `{
+  "schema_version": "20240607",
+  "start_time": "2024-01-01T09:00:00Z",
+  "end_time": "2024-01-01T09:01:00Z",
+  "layout": {"name": "aws_compliance.benchmark.s3", "panel_type": "benchmark", "children": [
+    {"name": "aws_compliance.benchmark.s3_versioning", "panel_type": "benchmark", "children": [
+      {"name": "aws_compliance.control.s3_bucket_versioning_enabled", "panel_type": "control"}
+    ]},
+    {"name": "other_mod.control.s3_bucket_logging_enabled", "panel_type": "control"}
+  ]},
+  "panels": {
+    "aws_compliance.benchmark.s3": {"name": "aws_compliance.benchmark.s3", "title": "S3", "panel_type": "benchmark"},
+    "aws_compliance.benchmark.s3_versioning": {"name": "aws_compliance.benchmark.s3_versioning", "title": "Versioning", "panel_type": "benchmark"},
+    "aws_compliance.control.s3_bucket_versioning_enabled": {
+      "name": "aws_compliance.control.s3_bucket_versioning_enabled",
+      "title": "Versioning enabled",
+      "panel_type": "control",
+      "properties": {"severity": "high"},
+      "status": "complete",
+      "data": {
+        "columns": [{"name": "resource"}, {"name": "status"}, {"name": "reason"}, {"name": "region"}],
+        "rows": [
+          {"resource": "arn:aws:s3:::assets", "status": "ok", "reason": "enabled", "region": "us-east-1"},
+          {"resource": "arn:aws:s3:::logs", "status": "alarm", "reason": "disabled", "region": "eu-west-1"}
+        ]
+      }
+    },
+    "other_mod.control.s3_bucket_logging_enabled": {
+      "name": "other_mod.control.s3_bucket_logging_enabled",
+      "panel_type": "control",
+      "status": "error",
+      "error": "access denied"
+    }
+  }
+}`
+
+func TestNewExecutionTreeFromSnapshot(t *testing.T) {
+	tree, err := NewExecutionTreeFromSnapshot([]byte(testBenchmarkSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.ControlRuns) != 2 || tree.Root.Title != "S3" {
+		t.Fatalf("unexpected tree: %d control runs, title %s", len(tree.ControlRuns), tree.Root.Title)
+	}
+
+	benchmark := tree.Root.Groups[0]
+	if status := benchmark.Summary.Status; status.Ok != 1 || status.Alarm != 1 || status.Error != 1 {
+		t.Errorf("unexpected benchmark summary %+v", status)
+	}
+	if severity := tree.Root.Summary.Severity["high"]; severity.Alarm != 1 {
+		t.Errorf("unexpected severity summary %+v", severity)
+	}
+	if len(tree.Root.DimensionKeys) != 1 || tree.Root.DimensionKeys[0] != "region" {
+		t.Errorf("unexpected dimension keys %v", tree.Root.DimensionKeys)
+	}
+
+	versioning := benchmark.Groups[0].ControlRuns[0]
+	if versioning.ControlId != "control.s3_bucket_versioning_enabled" || versioning.Control.Name() != versioning.FullName {
+		t.Errorf("unexpected control id %s", versioning.ControlId)
+	}
+	// rows are ordered by status, as when the control is run
+	if versioning.Rows[0].Status != "alarm" || versioning.Rows[0].GetDimensionValue("region") != "eu-west-1" {
+		t.Errorf("unexpected first row %+v", versioning.Rows[0])
+	}
+
+	logging := benchmark.ControlRuns[0]
+	if logging.ControlId != "other_mod.control.s3_bucket_logging_enabled" || logging.GetError() == nil {
+		t.Errorf("unexpected errored control run %s: %v", logging.ControlId, logging.GetError())
+	}
+
+	if _, err := NewExecutionTreeFromSnapshot([]byte(`{"layout": {"name": "aws_insights.dashboard.vpc", "panel_type": "dashboard"}}`)); err == nil {
+		t.Error("expected an error for a dashboard snapshot")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/turbot/pipe-fittings/statushooks"
 	"github.com/turbot/pipe-fittings/workspace"
 	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/exportbundle"
 	"github.com/turbot/powerpipe/internal/initialisation"
 )
 
@@ -113,6 +114,12 @@ func (i *InitData[T]) registerCheckExporters() error {
 	exporters, err := controldisplay.GetExporters()
 	error_helpers.FailOnErrorWithMessage(err, "failed to load exporters")
 
+	// bundle exports include the output of the other exporters
+	exporters, err = exportbundle.AppendExporter(i.Workspace, exporters)
+	if err != nil {
+		return err
+	}
+
 	// register all exporters
 	return i.RegisterExporters(exporters...)
 }
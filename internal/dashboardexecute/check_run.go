@@ -42,7 +42,7 @@ func NewCheckRun(resource modconfig.DashboardLeafNode, parent dashboardtypes.Das
 	//  set status to initialized
 	r.Status = dashboardtypes.RunInitialized
 	// add r into execution tree
-	executionTree.runs[r.Name] = r
+	executionTree.addRun(r)
 
 	if err := r.resolveDatabaseConfig(); err != nil {
 		return nil, err
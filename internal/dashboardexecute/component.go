@@ -0,0 +1,160 @@
+package dashboardexecute
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+// componentParamPrefix is the prefix of the tag options used to declare component params (on the component)
+// and to set them (on the instance)
+const componentParamPrefix = "param."
+
+// componentInstance is a container which instantiates a reusable component
+//
+// A component is a dashboard which packages a group of panels, e.g. a resource summary strip.
+// It declares its params, with their default values, using "powerpipe:param.<name>" tag options.
+// A component is instantiated by a container with the "powerpipe:component" tag option set to the
+// component name, e.g. "dashboard.resource_summary" or "aws_insights.dashboard.resource_summary" for a
+// component in a dependency mod. The instance sets param values using "powerpipe:param.<name>" tag options.
+//
+// The panels of the component are created as children of the instance container.
+// Params are passed to any panel query which declares a param with the same name.
+type componentInstance struct {
+	component *modconfig.Dashboard
+	params    map[string]any
+	// the suffix added to the names of all runs created for this instance (and their descendants)
+	// so that panels of components which are instantiated more than once have unique names
+	suffix string
+	// the instance of the component which contains this instance, if any
+	parent *componentInstance
+}
+
+// getComponentInstance returns the component instance for the container, if it has a component option
+// (run is the container run, which is used to build the name suffix for the instance)
+func getComponentInstance(container *modconfig.DashboardContainer, run dashboardtypes.DashboardParent, executionTree *DashboardExecutionTree) (*componentInstance, error) {
+	componentName, ok := tagoptions.Get(container.GetTags(), "component")
+	if !ok || componentName == "" {
+		return nil, nil
+	}
+	if len(container.GetChildren()) > 0 {
+		return nil, fmt.Errorf("%s instantiates component '%s' so cannot declare children", container.Name(), componentName)
+	}
+
+	parsedName, err := modconfig.ParseResourceName(componentName)
+	if err != nil || parsedName.ItemType != schema.BlockTypeDashboard {
+		return nil, fmt.Errorf("%s has an invalid component '%s' - must be a dashboard", container.Name(), componentName)
+	}
+	// resolve unqualified names relative to the mod of the container
+	if parsedName.Mod == "" {
+		parsedName.Mod = container.GetMod().ShortName
+	}
+	resource, ok := executionTree.workspace.GetResource(parsedName)
+	if !ok {
+		return nil, fmt.Errorf("%s component '%s' not found", container.Name(), componentName)
+	}
+	component, ok := resource.(*modconfig.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("%s has an invalid component '%s' - must be a dashboard", container.Name(), componentName)
+	}
+	// runtime dependencies are resolved by the dashboard which declares them, so components cannot declare them
+	for _, child := range component.GetChildren() {
+		switch child.BlockType() {
+		case schema.BlockTypeInput, schema.BlockTypeWith:
+			return nil, fmt.Errorf("component %s cannot declare %s blocks - use params instead", component.Name(), child.BlockType())
+		}
+	}
+
+	// a component may not instantiate itself, directly or through another component
+	parent := findComponentInstance(run)
+	if err := checkComponentCycle(component, parent); err != nil {
+		return nil, err
+	}
+
+	params, err := resolveComponentParams(component, container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &componentInstance{
+		component: component,
+		params:    params,
+		suffix:    fmt.Sprintf("%s_%s", runNameSuffix(run), container.ShortName),
+		parent:    parent,
+	}, nil
+}
+
+// checkComponentCycle returns an error if the component is already instantiated by the chain of instances which
+// contain the new instance, e.g. a component which contains an instance of itself
+func checkComponentCycle(component *modconfig.Dashboard, parent *componentInstance) error {
+	chain := []string{component.Name()}
+	for instance := parent; instance != nil; instance = instance.parent {
+		chain = append(chain, instance.component.Name())
+		if instance.component.Name() == component.Name() {
+			slices.Reverse(chain)
+			return fmt.Errorf("component %s instantiates itself: %s", component.Name(), strings.Join(chain, " -> "))
+		}
+	}
+	return nil
+}
+
+// resolveComponentParams returns the param values for an instance of the component - these are the values
+// set by the instance, or the defaults declared by the component
+func resolveComponentParams(component *modconfig.Dashboard, instance *modconfig.DashboardContainer) (map[string]any, error) {
+	declared := tagoptions.GetWithPrefix(component.GetTags(), componentParamPrefix)
+	values := tagoptions.GetWithPrefix(instance.GetTags(), componentParamPrefix)
+
+	params := make(map[string]any, len(declared))
+	for name, defaultValue := range declared {
+		params[name] = defaultValue
+	}
+
+	var unknown []string
+	for name, value := range values {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		params[name] = value
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s sets params not declared by component %s: %s", instance.Name(), component.Name(), strings.Join(unknown, ", "))
+	}
+
+	// params with no default are required
+	var missing []string
+	for name, value := range params {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%s does not set required params of component %s: %s", instance.Name(), component.Name(), strings.Join(missing, ", "))
+	}
+	return params, nil
+}
+
+// findComponentInstance returns the component instance of the nearest ancestor of the run which instantiates a component
+func findComponentInstance(run dashboardtypes.DashboardTreeRun) *componentInstance {
+	for run != nil {
+		if r, ok := run.(interface{ getComponentInstance() *componentInstance }); ok {
+			if instance := r.getComponentInstance(); instance != nil {
+				return instance
+			}
+		}
+		parent := run.GetParent()
+		if parent == nil {
+			return nil
+		}
+		run = parent
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package dashboardexecute
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+)
+
+// newComponentTestTree returns an execution tree for a workspace with the given dashboards, each containing a single
+// container which instantiates the given component
+func newComponentTestTree(mod *modconfig.Mod, components map[string]string) *DashboardExecutionTree {
+	for name, component := range components {
+		dashboard := modconfig.NewDashboard(&hcl.Block{Type: schema.BlockTypeDashboard}, mod, name).(*modconfig.Dashboard)
+		container := modconfig.NewDashboardContainer(&hcl.Block{Type: schema.BlockTypeContainer}, mod, name+"_instance").(*modconfig.DashboardContainer)
+		container.Tags = map[string]string{"powerpipe:component": component}
+		dashboard.SetChildren([]modconfig.ModTreeItem{container})
+		mod.ResourceMaps.Dashboards[dashboard.Name()] = dashboard
+		mod.ResourceMaps.DashboardContainers[container.Name()] = container
+	}
+	return &DashboardExecutionTree{
+		runs:      make(map[string]dashboardtypes.DashboardTreeRun),
+		workspace: dashboardworkspace.NewWorkspaceEvents(&workspace.Workspace{Mod: mod}),
+	}
+}
+
+func TestComponentCycle(t *testing.T) {
+	tests := map[string]struct {
+		components map[string]string
+		wantErr    string
+	}{
+		"instantiates itself": {
+			components: map[string]string{"a": "dashboard.a"},
+			wantErr:    "component local.dashboard.a instantiates itself: local.dashboard.a -> local.dashboard.a",
+		},
+		"instantiates itself through another component": {
+			components: map[string]string{"a": "dashboard.b", "b": "dashboard.a"},
+			wantErr:    "component local.dashboard.b instantiates itself: local.dashboard.b -> local.dashboard.a -> local.dashboard.b",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+			executionTree := newComponentTestTree(mod, tc.components)
+			dashboard := mod.ResourceMaps.Dashboards["local.dashboard.a"]
+
+			_, err := NewDashboardRun(dashboard, executionTree, executionTree)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestComponentInstantiatedTwice(t *testing.T) {
+	mod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+	executionTree := newComponentTestTree(mod, nil)
+	// a dashboard which instantiates the same component twice, which is not a cycle
+	summary := modconfig.NewDashboard(&hcl.Block{Type: schema.BlockTypeDashboard}, mod, "summary").(*modconfig.Dashboard)
+	mod.ResourceMaps.Dashboards[summary.Name()] = summary
+	dashboard := modconfig.NewDashboard(&hcl.Block{Type: schema.BlockTypeDashboard}, mod, "a").(*modconfig.Dashboard)
+	var children []modconfig.ModTreeItem
+	for _, name := range []string{"first", "second"} {
+		container := modconfig.NewDashboardContainer(&hcl.Block{Type: schema.BlockTypeContainer}, mod, name).(*modconfig.DashboardContainer)
+		container.Tags = map[string]string{"powerpipe:component": "dashboard.summary"}
+		children = append(children, container)
+	}
+	dashboard.SetChildren(children)
+
+	if _, err := NewDashboardRun(dashboard, executionTree, executionTree); err != nil {
+		t.Fatal(err)
+	}
+}
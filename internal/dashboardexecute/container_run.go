@@ -30,6 +30,12 @@ func (r *DashboardContainerRun) AsTreeNode() *steampipeconfig.SnapshotTreeNode {
 }
 
 func NewDashboardContainerRun(container *modconfig.DashboardContainer, parent dashboardtypes.DashboardParent, executionTree *DashboardExecutionTree) (*DashboardContainerRun, error) {
+	return newDashboardContainerRun(container, parent, executionTree, nil)
+}
+
+// newDashboardContainerRun creates a container run - if the container has a for_each option,
+// item is the for_each item this run is being created for
+func newDashboardContainerRun(container *modconfig.DashboardContainer, parent dashboardtypes.DashboardParent, executionTree *DashboardExecutionTree, item *forEachItem) (*DashboardContainerRun, error) {
 	children := container.GetChildren()
 
 	r := &DashboardContainerRun{dashboardNode: container}
@@ -44,8 +50,27 @@ func NewDashboardContainerRun(container *modconfig.DashboardContainer, parent da
 	if container.Width != nil {
 		r.Width = *container.Width
 	}
+	// set the item BEFORE creating children, as the item is used to name the child runs
+	if item != nil {
+		r.setForEachItem(item)
+	}
+	// if the container instantiates a component, the panels of the component are our children
+	component, err := getComponentInstance(container, r, executionTree)
+	if err != nil {
+		return nil, err
+	}
+	if component != nil {
+		r.component = component
+		children = component.component.GetChildren()
+	}
 	r.childCompleteChan = make(chan dashboardtypes.DashboardTreeRun, len(children))
 	for _, child := range children {
+		// runs for children with for_each are created when we are initialised
+		if source, ok := getForEachSource(child); ok {
+			r.addForEachChild(child.(modconfig.DashboardLeafNode), source)
+			continue
+		}
+
 		var childRun dashboardtypes.DashboardTreeRun
 		var err error
 		switch i := child.(type) {
@@ -90,12 +115,17 @@ func NewDashboardContainerRun(container *modconfig.DashboardContainer, parent da
 		r.children = append(r.children, childRun)
 	}
 	// add r into execution tree
-	executionTree.runs[r.Name] = r
+	executionTree.addRun(r)
 	return r, nil
 }
 
 // Initialise implements DashboardTreeRun
 func (r *DashboardContainerRun) Initialise(ctx context.Context) {
+	// create runs for any for_each children
+	if err := r.expandForEach(ctx); err != nil {
+		r.SetError(ctx, err)
+		return
+	}
 	// initialise our children
 	if err := r.initialiseChildren(ctx); err != nil {
 		r.SetError(ctx, err)
@@ -105,6 +135,16 @@ func (r *DashboardContainerRun) Initialise(ctx context.Context) {
 // Execute implements DashboardTreeRun
 // execute all children and wait for them to complete
 func (r *DashboardContainerRun) Execute(ctx context.Context) {
+	// if we have a display condition which is not met, we are hidden - do not execute our children
+	if visible, err := r.evaluateDisplayCondition(ctx); err != nil {
+		r.SetError(ctx, err)
+		return
+	} else if !visible {
+		r.Hidden = true
+		r.SetComplete(ctx)
+		return
+	}
+
 	// execute all children asynchronously
 	r.executeChildrenAsync(ctx)
 
@@ -19,6 +19,7 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/tracing"
 )
 
 // DashboardExecutionTree is a structure representing the control result hierarchy
@@ -31,8 +32,10 @@ type DashboardExecutionTree struct {
 	clientMap *db_client.ClientMap
 	// map of server-managed clients, keyed by connection string - we will NOT close this
 	defaultClientMap *db_client.ClientMap
-	// map of executing runs, keyed by full name
+	// map of executing runs, keyed by full name - runs are added while the tree executes (e.g. for for_each), so
+	// access them with addRun, getRun and allRuns
 	runs      map[string]dashboardtypes.DashboardTreeRun
+	runsLock  sync.RWMutex
 	workspace *dashboardworkspace.WorkspaceEvents
 
 	runComplete chan dashboardtypes.DashboardTreeRun
@@ -44,6 +47,8 @@ type DashboardExecutionTree struct {
 	// active database and search path config (unless overridden at the resource level)
 	database         string
 	searchPathConfig backend.SearchPathConfig
+	// limits the number of concurrent executions (nil if there is no limit)
+	limiter *executionLimiter
 }
 
 func newDashboardExecutionTree(rootResource modconfig.ModTreeItem, sessionId string, workspace *dashboardworkspace.WorkspaceEvents, defaultClientMap *db_client.ClientMap, opts ...backend.ConnectOption) (*DashboardExecutionTree, error) {
@@ -67,6 +72,18 @@ func newDashboardExecutionTree(rootResource modconfig.ModTreeItem, sessionId str
 	if err != nil {
 		return nil, err
 	}
+	// if the root resource specifies a database (e.g. a query which is run directly), use that
+	if d, ok := rootResource.(modconfig.DatabaseItem); ok {
+		if resourceDatabase := d.GetDatabase(); resourceDatabase != nil {
+			database = *resourceDatabase
+		}
+		if resourceSearchPath := d.GetSearchPath(); len(resourceSearchPath) > 0 {
+			searchPathConfig.SearchPath = resourceSearchPath
+		}
+		if resourceSearchPathPrefix := d.GetSearchPathPrefix(); len(resourceSearchPathPrefix) > 0 {
+			searchPathConfig.SearchPathPrefix = resourceSearchPathPrefix
+		}
+	}
 	executionTree.database = database
 	executionTree.searchPathConfig = searchPathConfig
 	// add a client for the active database and search path
@@ -106,8 +123,6 @@ func (e *DashboardExecutionTree) createRootItem(rootResource modconfig.ModTreeIt
 }
 
 func (e *DashboardExecutionTree) Execute(ctx context.Context) {
-	startTime := time.Now()
-
 	// setup a cancel context with timeout and start cancel handler
 	var cancel context.CancelFunc
 	// if a dashboard timeout was specified, use that
@@ -120,6 +135,20 @@ func (e *DashboardExecutionTree) Execute(ctx context.Context) {
 	e.cancel = cancel
 	workspace := e.workspace
 
+	ctx, span := tracing.StartSpan(ctx, e.Root.GetNodeType(), e.Root.GetName())
+	defer func() {
+		tracing.EndSpan(span, e.Root.GetError())
+	}()
+
+	// if the number of concurrent executions is limited, wait for a slot
+	if err := e.limiter.acquire(ctx); err != nil {
+		e.SetError(ctx, err)
+		return
+	}
+	defer e.limiter.release()
+
+	startTime := time.Now()
+
 	// if the default database backend supports search path, retrieve it
 	defaultClient, err := e.getClient(ctx, e.database, e.searchPathConfig)
 	if err != nil {
@@ -231,11 +260,15 @@ func (e *DashboardExecutionTree) SetInputValues(inputValues map[string]any) {
 		return
 	}
 
+	// resolve the values to publish, e.g. converting number input values to numbers
+	// (values have already been validated, so ignore any error)
+	resolvedValues, _ := resolveInputValues(e, inputValues)
+
 	for name, value := range inputValues {
 		slog.Debug("DashboardExecutionTree SetInput", "name", name, "value", value)
 		e.inputValues[name] = value
 		// publish runtime dependency
-		runtimeDependencyPublisher.PublishRuntimeDependencyValue(name, &dashboardtypes.ResolvedRuntimeDependencyValue{Value: value})
+		runtimeDependencyPublisher.PublishRuntimeDependencyValue(name, &dashboardtypes.ResolvedRuntimeDependencyValue{Value: resolvedValues[name]})
 	}
 }
 
@@ -265,11 +298,31 @@ func (e *DashboardExecutionTree) Cancel() {
 	slog.Debug("DashboardExecutionTree Cancel - all children complete")
 }
 
+func (e *DashboardExecutionTree) addRun(run dashboardtypes.DashboardTreeRun) {
+	e.runsLock.Lock()
+	defer e.runsLock.Unlock()
+	e.runs[run.GetName()] = run
+}
+
+// getRun returns the run with the given name, or nil if there is none
+func (e *DashboardExecutionTree) getRun(name string) dashboardtypes.DashboardTreeRun {
+	e.runsLock.RLock()
+	defer e.runsLock.RUnlock()
+	return e.runs[name]
+}
+
+// allRuns returns a copy of the map of runs
+func (e *DashboardExecutionTree) allRuns() map[string]dashboardtypes.DashboardTreeRun {
+	e.runsLock.RLock()
+	defer e.runsLock.RUnlock()
+	return maps.Clone(e.runs)
+}
+
 func (e *DashboardExecutionTree) BuildSnapshotPanels() map[string]steampipeconfig.SnapshotPanel {
 	// just build from e.runs
 	res := map[string]steampipeconfig.SnapshotPanel{}
 
-	for name, run := range e.runs {
+	for name, run := range e.allRuns() {
 		res[name] = run.(steampipeconfig.SnapshotPanel)
 		// special case handling for check runs
 		if checkRun, ok := run.(*CheckRun); ok {
@@ -285,7 +338,7 @@ func (e *DashboardExecutionTree) BuildSnapshotPanels() map[string]steampipeconfi
 // InputRuntimeDependencies returns the names of all inputs which are runtime dependencies
 func (e *DashboardExecutionTree) InputRuntimeDependencies() []string {
 	var deps = map[string]struct{}{}
-	for _, r := range e.runs {
+	for _, r := range e.allRuns() {
 		if leafRun, ok := r.(*LeafRun); ok {
 			for _, r := range leafRun.runtimeDependencies {
 				if r.Dependency.PropertyPath.ItemType == schema.BlockTypeInput {
@@ -11,6 +11,7 @@ import (
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/db_client"
 )
 
 type DashboardParentImpl struct {
@@ -20,6 +21,8 @@ type DashboardParentImpl struct {
 	// are we blocked by a child run
 	blockedByChild  bool
 	childStatusLock *sync.Mutex
+	// children with a for_each option - runs for these are created when we are initialised
+	forEachChildren []forEachChild
 }
 
 func newDashboardParentImpl(resource modconfig.DashboardLeafNode, parent dashboardtypes.DashboardParent, run dashboardtypes.DashboardTreeRun, executionTree *DashboardExecutionTree) DashboardParentImpl {
@@ -119,7 +122,7 @@ func (r *DashboardParentImpl) waitForChildrenAsync(ctx context.Context) chan err
 		// if context is cancelled, just return context cancellation error
 		if ctx.Err() != nil {
 			if ctx.Err().Error() == context.DeadlineExceeded.Error() {
-				err = fmt.Errorf("execution timed out")
+				err = db_client.NewTimeoutError("execution timed out")
 			} else {
 				err = ctx.Err()
 			}
@@ -17,6 +17,8 @@ type DashboardRun struct {
 
 	parent    dashboardtypes.DashboardParent
 	dashboard *modconfig.Dashboard
+
+	Theme *DashboardTheme `json:"theme,omitempty"`
 }
 
 func (r *DashboardRun) AsTreeNode() *steampipeconfig.SnapshotTreeNode {
@@ -40,13 +42,14 @@ func NewDashboardRun(dashboard *modconfig.Dashboard, parent dashboardtypes.Dashb
 	r := &DashboardRun{
 		parent:    parent,
 		dashboard: dashboard,
+		Theme:     newDashboardTheme(dashboard),
 	}
 	// create RuntimeDependencyPublisherImpl- this handles 'with' run creation and resolving runtime dependency resolution
 	// (we must create after creating the run as it requires a ref to the run)
 	r.runtimeDependencyPublisherImpl = newRuntimeDependencyPublisherImpl(dashboard, parent, r, executionTree)
 	// add r into execution tree BEFORE creating child runs or initialising runtime depdencies
 	// - this is so child runs can find this dashboard run
-	executionTree.runs[r.Name] = r
+	executionTree.addRun(r)
 
 	// set inputs map on RuntimeDependencyPublisherImpl BEFORE creating child runs
 	r.inputs = dashboard.GetInputs()
@@ -70,6 +73,11 @@ func NewDashboardRun(dashboard *modconfig.Dashboard, parent dashboardtypes.Dashb
 
 // Initialise implements DashboardTreeRun
 func (r *DashboardRun) Initialise(ctx context.Context) {
+	// create runs for any for_each children
+	if err := r.expandForEach(ctx); err != nil {
+		r.SetError(ctx, err)
+		return
+	}
 	// initialise our children
 	if err := r.initialiseChildren(ctx); err != nil {
 		r.SetError(ctx, err)
@@ -120,6 +128,12 @@ func (r *DashboardRun) createChildRuns(executionTree *DashboardExecutionTree) er
 	children := r.dashboard.GetChildren()
 
 	for _, child := range children {
+		// runs for children with for_each are created when we are initialised
+		if source, ok := getForEachSource(child); ok {
+			r.addForEachChild(child.(modconfig.DashboardLeafNode), source)
+			continue
+		}
+
 		var childRun dashboardtypes.DashboardTreeRun
 		var err error
 		switch i := child.(type) {
@@ -0,0 +1,44 @@
+package dashboardexecute
+
+import (
+	"strings"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+// DashboardTheme is the theme preferences declared by a dashboard
+//
+// These are set using the following tag options:
+//
+//	"powerpipe:theme"         - the default theme, either "light" or "dark"
+//	"powerpipe:palette"       - a comma separated list of colors to use for chart series
+//	"powerpipe:accent_<name>" - the color to use for the named accent, e.g. "powerpipe:accent_alert"
+type DashboardTheme struct {
+	Mode    string            `json:"mode,omitempty"`
+	Palette []string          `json:"palette,omitempty"`
+	Accents map[string]string `json:"accents,omitempty"`
+}
+
+// newDashboardTheme builds the theme for the given dashboard - if the dashboard does not declare a theme, return nil
+func newDashboardTheme(dashboard *modconfig.Dashboard) *DashboardTheme {
+	tags := dashboard.GetTags()
+	theme := &DashboardTheme{
+		Accents: tagoptions.GetWithPrefix(tags, "accent_"),
+	}
+	if mode, ok := tagoptions.Get(tags, "theme"); ok {
+		theme.Mode = mode
+	}
+	if palette, ok := tagoptions.Get(tags, "palette"); ok {
+		for _, color := range strings.Split(palette, ",") {
+			if color = strings.TrimSpace(color); color != "" {
+				theme.Palette = append(theme.Palette, color)
+			}
+		}
+	}
+
+	if theme.Mode == "" && len(theme.Palette) == 0 && len(theme.Accents) == 0 {
+		return nil
+	}
+	return theme
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/turbot/pipe-fittings/steampipeconfig"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/tagoptions"
 )
 
 type DashboardTreeRunImpl struct {
@@ -22,14 +24,21 @@ type DashboardTreeRunImpl struct {
 	SourceDefinition string                   `json:"source_definition"`
 	Status           dashboardtypes.RunStatus `json:"status"`
 	Tags             map[string]string        `json:"tags,omitempty"`
+	Options          map[string]string        `json:"options,omitempty"`
 	Title            string                   `json:"title,omitempty"`
 	Type             string                   `json:"display_type,omitempty"`
 	Width            int                      `json:"width,omitempty"`
+	// set if the run has a display condition which is not met
+	Hidden bool `json:"hidden,omitempty"`
 
 	err           error
 	parent        dashboardtypes.DashboardParent
 	executionTree *DashboardExecutionTree
 	resource      modconfig.DashboardLeafNode
+	// if this run was created by for_each, the item it was created for
+	each *forEachItem
+	// if this run instantiates a component, the component instance
+	component *componentInstance
 
 	// store the top level run which embeds this struct
 	// we need this for setStatus which serialises the run for the message payload
@@ -41,7 +50,7 @@ func NewDashboardTreeRunImpl(resource modconfig.DashboardLeafNode, parent dashbo
 	// (if we supported the children property then we could reuse resources)
 	// so FOR NOW it is safe to use the container name directly as the run name
 	res := DashboardTreeRunImpl{
-		Name:             resource.Name(),
+		Name:             resource.Name() + runNameSuffix(parent),
 		Title:            resource.GetTitle(),
 		NodeType:         resource.BlockType(),
 		Width:            resource.GetWidth(),
@@ -49,7 +58,8 @@ func NewDashboardTreeRunImpl(resource modconfig.DashboardLeafNode, parent dashbo
 		Description:      resource.GetDescription(),
 		Documentation:    resource.GetDocumentation(),
 		Type:             resource.GetType(),
-		Tags:             resource.GetTags(),
+		Tags:             tagoptions.StripOptions(resource.GetTags()),
+		Options:          tagoptions.GetWithPrefix(resource.GetTags(), ""),
 		SourceDefinition: resource.GetMetadata().SourceDefinition,
 
 		// set to complete, optimistically
@@ -101,6 +111,34 @@ func (r *DashboardTreeRunImpl) GetParent() dashboardtypes.DashboardParent {
 	return r.parent
 }
 
+// if this run was created by for_each, return the item it was created for
+func (r *DashboardTreeRunImpl) getForEachItem() *forEachItem {
+	return r.each
+}
+
+// setForEachItem sets the for_each item this run was created for, and updates the name and title to reflect the item
+func (r *DashboardTreeRunImpl) setForEachItem(item *forEachItem) {
+	r.each = item
+	r.Name = r.resource.Name() + item.suffix
+	r.Title = item.title(r.Title)
+}
+
+// if this run instantiates a component, return the component instance
+func (r *DashboardTreeRunImpl) getComponentInstance() *componentInstance {
+	return r.component
+}
+
+// getNameSuffix returns the suffix added to the names of runs created beneath this run
+func (r *DashboardTreeRunImpl) getNameSuffix() string {
+	switch {
+	case r.each != nil:
+		return r.each.suffix
+	case r.component != nil:
+		return r.component.suffix
+	}
+	return ""
+}
+
 // GetTitle implements DashboardTreeRun
 func (r *DashboardTreeRunImpl) GetTitle() string {
 	return r.Title
@@ -140,9 +178,12 @@ func (r *DashboardTreeRunImpl) SetError(ctx context.Context, err error) {
 	r.ErrorString = r.err.Error()
 
 	// set status (this sends update event)
-	if error_helpers.IsContextCancelledError(err) {
+	switch {
+	case error_helpers.IsContextCancelledError(err):
 		r.setStatus(ctx, dashboardtypes.RunCanceled)
-	} else {
+	case db_client.IsTimeoutError(err):
+		r.setStatus(ctx, dashboardtypes.RunTimeout)
+	default:
 		r.setStatus(ctx, dashboardtypes.RunError)
 	}
 	// tell parent we are done
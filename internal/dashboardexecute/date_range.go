@@ -0,0 +1,87 @@
+package dashboardexecute
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dateRangePresetRegex = regexp.MustCompile(`^last_(\d+)([mhdw])$`)
+
+const dateOnlyLayout = "2006-01-02"
+
+// the layouts accepted for the start and end of a custom date range
+var dateRangeLayouts = []string{time.RFC3339, "2006-01-02T15:04", dateOnlyLayout}
+
+// resolveDateRange resolves the value of a date_range input into its start and end timestamps,
+// returned as a map with "start" and "end" keys, which dependents reference as
+// self.input.<name>.value.start and self.input.<name>.value.end
+//
+// The value is either:
+//
+//	a preset relative to the current time - "last_<n><unit>", where unit is one of m, h, d or w, e.g. "last_24h", "last_7d"
+//	a custom range - two timestamps or dates separated by "/", e.g. "2024-01-01/2024-01-31"
+//
+// If the end of a custom range is a date, the range includes the whole of that day.
+func resolveDateRange(value any, now time.Time) (any, error) {
+	// the value may already have been resolved
+	if m, ok := value.(map[string]any); ok {
+		if _, ok := m["start"]; ok {
+			return m, nil
+		}
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid date range '%v'", value)
+	}
+	str = strings.TrimSpace(str)
+
+	var start, end time.Time
+	if match := dateRangePresetRegex.FindStringSubmatch(str); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		unit := map[string]time.Duration{
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[match[2]]
+		end = now
+		start = now.Add(-time.Duration(count) * unit)
+	} else {
+		startStr, endStr, ok := strings.Cut(str, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid date range '%s' - must be a preset such as 'last_7d' or a range such as '2024-01-01/2024-01-31'", str)
+		}
+		var err error
+		if start, err = parseDateRangeTime(startStr); err != nil {
+			return nil, err
+		}
+		if end, err = parseDateRangeTime(endStr); err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(dateOnlyLayout, strings.TrimSpace(endStr)); err == nil {
+			end = end.Add(24*time.Hour - time.Second)
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("invalid date range '%s' - the end is before the start", str)
+		}
+	}
+
+	return map[string]any{
+		"start": start.UTC().Format(time.RFC3339),
+		"end":   end.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func parseDateRangeTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateRangeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date range time '%s'", value)
+}
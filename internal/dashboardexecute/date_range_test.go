@@ -0,0 +1,110 @@
+package dashboardexecute
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveDateRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 30, 0, 0, time.UTC)
+	tests := map[string]struct {
+		value     any
+		expected  any
+		expectErr string
+	}{
+		"minutes preset": {
+			value:    "last_30m",
+			expected: map[string]any{"start": "2024-03-15T12:00:00Z", "end": "2024-03-15T12:30:00Z"},
+		},
+		"hours preset": {
+			value:    "last_24h",
+			expected: map[string]any{"start": "2024-03-14T12:30:00Z", "end": "2024-03-15T12:30:00Z"},
+		},
+		"days preset": {
+			value:    "last_7d",
+			expected: map[string]any{"start": "2024-03-08T12:30:00Z", "end": "2024-03-15T12:30:00Z"},
+		},
+		"weeks preset": {
+			value:    "last_2w",
+			expected: map[string]any{"start": "2024-03-01T12:30:00Z", "end": "2024-03-15T12:30:00Z"},
+		},
+		"preset with whitespace": {
+			value:    " last_1h ",
+			expected: map[string]any{"start": "2024-03-15T11:30:00Z", "end": "2024-03-15T12:30:00Z"},
+		},
+		"date range includes the whole end day": {
+			value:    "2024-01-01/2024-01-31",
+			expected: map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-31T23:59:59Z"},
+		},
+		"single day": {
+			value:    "2024-01-01/2024-01-01",
+			expected: map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-01T23:59:59Z"},
+		},
+		"timestamp range": {
+			value:    "2024-01-01T10:00:00Z/2024-01-01T11:00:00Z",
+			expected: map[string]any{"start": "2024-01-01T10:00:00Z", "end": "2024-01-01T11:00:00Z"},
+		},
+		"timestamps are converted to UTC": {
+			value:    "2024-01-01T10:00:00+02:00/2024-01-01T11:00:00+02:00",
+			expected: map[string]any{"start": "2024-01-01T08:00:00Z", "end": "2024-01-01T09:00:00Z"},
+		},
+		"minute timestamps": {
+			value:    "2024-01-01T10:00/2024-01-01T10:15",
+			expected: map[string]any{"start": "2024-01-01T10:00:00Z", "end": "2024-01-01T10:15:00Z"},
+		},
+		"whitespace around the times": {
+			value:    "2024-01-01 / 2024-01-02",
+			expected: map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T23:59:59Z"},
+		},
+		"resolved value": {
+			value:    map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"},
+			expected: map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"},
+		},
+		"map without start": {
+			value:     map[string]any{"end": "2024-01-02T00:00:00Z"},
+			expectErr: "invalid date range 'map[end:2024-01-02T00:00:00Z]'",
+		},
+		"not a string": {
+			value:     42,
+			expectErr: "invalid date range '42'",
+		},
+		"unknown preset unit": {
+			value:     "last_7y",
+			expectErr: "invalid date range 'last_7y' - must be a preset such as 'last_7d' or a range such as '2024-01-01/2024-01-31'",
+		},
+		"invalid start": {
+			value:     "yesterday/2024-01-31",
+			expectErr: "invalid date range time 'yesterday'",
+		},
+		"invalid end": {
+			value:     "2024-01-01/2024-02-30",
+			expectErr: "invalid date range time '2024-02-30'",
+		},
+		"empty end": {
+			value:     "2024-01-01/",
+			expectErr: "invalid date range time ''",
+		},
+		"end before start": {
+			value:     "2024-01-31/2024-01-01",
+			expectErr: "invalid date range '2024-01-31/2024-01-01' - the end is before the start",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := resolveDateRange(test.value, now)
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("got %v, expected %v", res, test.expected)
+			}
+		})
+	}
+}
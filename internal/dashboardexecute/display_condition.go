@@ -0,0 +1,247 @@
+package dashboardexecute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+// displayCondition is a condition which determines whether a dashboard element is displayed
+//
+// It is set using the "powerpipe:display_if" tag option, and may be one of:
+//
+//	"input.<name>"              - displayed if the input has a value
+//	"input.<name> == '<value>'" - displayed if the input has the given value ("!=" is also supported)
+//	"query.<name>"              - displayed if the first column of the first row returned by the query is truthy
+//
+// Any condition may be negated with a leading "!". Query params with the same name as a dashboard input
+// are passed the value of that input. The query is resolved in the mod of the element with the condition, as
+// unqualified references in HCL are.
+type displayCondition struct {
+	negate   bool
+	source   string
+	operator string
+	value    string
+	// the short name of the mod of the element with the condition
+	mod string
+}
+
+// resourceProvider looks up resources by name - implemented by the workspace and by mod resource maps
+type resourceProvider interface {
+	GetResource(parsedName *modconfig.ParsedResourceName) (modconfig.HclResource, bool)
+}
+
+// getDisplayCondition returns the display condition for the resource - if it has none, return nil
+func getDisplayCondition(resource modconfig.DashboardLeafNode) (*displayCondition, error) {
+	expr, ok := tagoptions.Get(resource.GetTags(), "display_if")
+	if !ok {
+		return nil, nil
+	}
+	condition, err := parseDisplayCondition(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s has an invalid display_if condition: %s", resource.Name(), err.Error())
+	}
+	if mod := resource.GetMod(); mod != nil {
+		condition.mod = mod.ShortName
+	}
+	return condition, nil
+}
+
+func parseDisplayCondition(expr string) (*displayCondition, error) {
+	c := &displayCondition{}
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "!"); ok {
+		c.negate = true
+		expr = strings.TrimSpace(rest)
+	}
+
+	for _, operator := range []string{"==", "!="} {
+		if source, value, ok := strings.Cut(expr, operator); ok {
+			c.operator = operator
+			expr = strings.TrimSpace(source)
+			c.value = strings.Trim(strings.TrimSpace(value), `'"`)
+			break
+		}
+	}
+	c.source = expr
+
+	switch {
+	case strings.HasPrefix(c.source, "input."):
+	case strings.HasPrefix(c.source, "query."):
+		if c.operator != "" {
+			return nil, fmt.Errorf("comparison operators are only supported for inputs")
+		}
+	default:
+		return nil, fmt.Errorf("'%s' must reference an input or a query", c.source)
+	}
+	return c, nil
+}
+
+// dependsOnInput returns whether the result of the condition depends on the given input
+func (c *displayCondition) dependsOnInput(inputName string, w *DashboardExecutionTree) bool {
+	if c.source == inputName {
+		return true
+	}
+	query, err := c.getQuery(w)
+	if err != nil || query == nil {
+		return false
+	}
+	for _, p := range query.GetParams() {
+		if "input."+p.ShortName == inputName {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns whether the element with this condition should be displayed
+func (c *displayCondition) evaluate(ctx context.Context, executionTree *DashboardExecutionTree) (bool, error) {
+	var res bool
+	if strings.HasPrefix(c.source, "input.") {
+		res = c.evaluateInput(executionTree)
+	} else {
+		var err error
+		res, err = c.evaluateQuery(ctx, executionTree)
+		if err != nil {
+			return false, err
+		}
+	}
+	return res != c.negate, nil
+}
+
+func (c *displayCondition) evaluateInput(executionTree *DashboardExecutionTree) bool {
+	executionTree.inputLock.Lock()
+	value, ok := executionTree.inputValues[c.source]
+	executionTree.inputLock.Unlock()
+
+	switch c.operator {
+	case "==":
+		return ok && value != nil && fmt.Sprintf("%v", value) == c.value
+	case "!=":
+		return !ok || value == nil || fmt.Sprintf("%v", value) != c.value
+	default:
+		return ok && isTruthy(value)
+	}
+}
+
+func (c *displayCondition) evaluateQuery(ctx context.Context, executionTree *DashboardExecutionTree) (bool, error) {
+	query, err := c.getQuery(executionTree)
+	if err != nil {
+		return false, err
+	}
+	if query.SQL == nil {
+		return false, fmt.Errorf("display_if query %s has no sql", query.Name())
+	}
+
+	// pass the values of any inputs with the same name as a query param
+	runtimeArgs := modconfig.NewQueryArgs()
+	executionTree.inputLock.Lock()
+	for _, p := range query.GetParams() {
+		if value, ok := executionTree.inputValues["input."+p.ShortName]; ok {
+			if err := runtimeArgs.SetNamedArgVal(p.ShortName, value); err != nil {
+				executionTree.inputLock.Unlock()
+				return false, err
+			}
+		}
+	}
+	executionTree.inputLock.Unlock()
+
+	args, err := modconfig.ResolveArgs(query, runtimeArgs)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve args for display_if query %s: %s", query.Name(), err.Error())
+	}
+
+	client, err := executionTree.getClient(ctx, executionTree.database, executionTree.searchPathConfig)
+	if err != nil {
+		return false, err
+	}
+	queryResult, err := client.ExecuteSync(ctx, *query.SQL, args...)
+	if err != nil {
+		return false, err
+	}
+	if len(queryResult.Rows) == 0 {
+		return false, nil
+	}
+	row, ok := queryResult.Rows[0].(*localqueryresult.RowResult)
+	if !ok || len(row.Data) == 0 {
+		return false, nil
+	}
+	return isTruthy(row.Data[0]), nil
+}
+
+// getQuery returns the query of the condition from the workspace of the execution, if it references one
+func (c *displayCondition) getQuery(executionTree *DashboardExecutionTree) (*modconfig.Query, error) {
+	return c.resolveQuery(executionTree.workspace)
+}
+
+// resolveQuery returns the query of the condition, if it references one
+func (c *displayCondition) resolveQuery(resources resourceProvider) (*modconfig.Query, error) {
+	if !strings.HasPrefix(c.source, "query.") {
+		return nil, nil
+	}
+	parsedName, err := modconfig.ParseResourceName(c.source)
+	if err != nil || parsedName.ItemType != schema.BlockTypeQuery {
+		return nil, fmt.Errorf("invalid display_if query '%s'", c.source)
+	}
+	// the query is in the mod of the element - if the mod is not set, the workspace mod is assumed
+	parsedName.Mod = c.mod
+	resource, ok := resources.GetResource(parsedName)
+	if !ok {
+		return nil, fmt.Errorf("display_if query '%s' not found", c.source)
+	}
+	return resource.(*modconfig.Query), nil
+}
+
+// isTruthy returns whether a value should be treated as true: false, zero, empty strings and nil are false
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != "" && !strings.EqualFold(v, "false")
+	case int:
+		return v != 0
+	case int32:
+		return v != 0
+	case int64:
+		return v != 0
+	case float32:
+		return v != 0
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+// evaluateDisplayCondition returns whether this run should be displayed
+// if the run has no display condition, it is always displayed
+func (r *DashboardTreeRunImpl) evaluateDisplayCondition(ctx context.Context) (bool, error) {
+	condition, err := getDisplayCondition(r.resource)
+	if err != nil || condition == nil {
+		return true, err
+	}
+	return condition.evaluate(ctx, r.executionTree)
+}
+
+// hasDisplayConditionDependingOn returns whether any run in the tree has a display condition which
+// depends on the given input
+func (e *DashboardExecutionTree) hasDisplayConditionDependingOn(inputName string) bool {
+	for _, run := range e.allRuns() {
+		resource := run.GetResource()
+		if resource == nil {
+			continue
+		}
+		condition, err := getDisplayCondition(resource)
+		if err == nil && condition != nil && condition.dependsOnInput(inputName, e) {
+			return true
+		}
+	}
+	return false
+}
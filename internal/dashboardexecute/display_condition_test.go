@@ -0,0 +1,54 @@
+package dashboardexecute
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+func TestDisplayConditionQueryInDependencyMod(t *testing.T) {
+	// the workspace mod and a dependency mod both define query.is_enabled - the resource maps of the workspace mod
+	// contain the resources of its dependencies
+	workspaceMod := modconfig.NewMod("local", "/workspace", hcl.Range{})
+	depMod := modconfig.NewMod("aws_insights", "/workspace/.powerpipe/mods/aws_insights", hcl.Range{})
+	for _, mod := range []*modconfig.Mod{workspaceMod, depMod} {
+		query := modconfig.NewQuery(&hcl.Block{Type: schema.BlockTypeQuery}, mod, "is_enabled").(*modconfig.Query)
+		workspaceMod.ResourceMaps.Queries[query.Name()] = query
+	}
+
+	tests := map[string]struct {
+		mod     *modconfig.Mod
+		source  string
+		want    string
+		wantErr bool
+	}{
+		"workspace mod":  {mod: workspaceMod, source: "query.is_enabled", want: "local.query.is_enabled"},
+		"dependency mod": {mod: depMod, source: "query.is_enabled", want: "aws_insights.query.is_enabled"},
+		"missing query":  {mod: depMod, source: "query.is_disabled", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			card := modconfig.NewDashboardCard(&hcl.Block{Type: schema.BlockTypeCard}, tc.mod, "status").(*modconfig.DashboardCard)
+			card.Tags = map[string]string{"powerpipe:display_if": tc.source}
+			condition, err := getDisplayCondition(card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := condition.resolveQuery(workspaceMod.ResourceMaps)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", query.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.Name() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, query.Name())
+			}
+		})
+	}
+}
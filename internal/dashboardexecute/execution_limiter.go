@@ -0,0 +1,66 @@
+package dashboardexecute
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var ErrExecutionQueueFull = errors.New("the server is busy - too many dashboard executions are queued, please try again later")
+
+// executionLimiter limits the number of dashboard executions which may run concurrently
+// executions which cannot start immediately are queued, up to a maximum queue size
+// a nil executionLimiter imposes no limit
+type executionLimiter struct {
+	slots     chan struct{}
+	maxQueued int64
+	queued    atomic.Int64
+}
+
+func newExecutionLimiter(maxConcurrent, maxQueued int) *executionLimiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	return &executionLimiter{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: int64(maxQueued),
+	}
+}
+
+// checkQueue returns ErrExecutionQueueFull if there is no room to queue another execution
+func (l *executionLimiter) checkQueue() error {
+	if l == nil {
+		return nil
+	}
+	// if there is a free slot, the execution will not be queued
+	if len(l.slots) < cap(l.slots) {
+		return nil
+	}
+	if l.queued.Load() >= l.maxQueued {
+		return ErrExecutionQueueFull
+	}
+	return nil
+}
+
+// acquire waits for an execution slot to be available, or for the context to be cancelled
+func (l *executionLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.queued.Add(1)
+	defer l.queued.Add(-1)
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *executionLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,96 @@
+package dashboardexecute
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecutionLimiter(t *testing.T) {
+	l := newExecutionLimiter(1, 1)
+	ctx := context.Background()
+
+	if err := l.checkQueue(); err != nil {
+		t.Fatalf("expected a free slot, got %v", err)
+	}
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the slot is taken - a single execution may be queued
+	if err := l.checkQueue(); err != nil {
+		t.Fatalf("expected room in the queue, got %v", err)
+	}
+	acquired := make(chan error)
+	go func() {
+		acquired <- l.acquire(ctx)
+	}()
+	waitFor(t, func() bool { return l.queued.Load() == 1 })
+	if err := l.checkQueue(); !errors.Is(err, ErrExecutionQueueFull) {
+		t.Errorf("expected ErrExecutionQueueFull, got %v", err)
+	}
+
+	// releasing the slot starts the queued execution
+	l.release()
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+	if l.queued.Load() != 0 {
+		t.Errorf("expected the queue to be empty, got %d", l.queued.Load())
+	}
+	l.release()
+	if err := l.checkQueue(); err != nil {
+		t.Errorf("expected a free slot, got %v", err)
+	}
+}
+
+func TestExecutionLimiterCancelled(t *testing.T) {
+	l := newExecutionLimiter(1, 1)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a queued execution which is cancelled leaves the queue
+	ctx, cancel := context.WithCancel(context.Background())
+	acquired := make(chan error)
+	go func() {
+		acquired <- l.acquire(ctx)
+	}()
+	waitFor(t, func() bool { return l.queued.Load() == 1 })
+	cancel()
+	if err := <-acquired; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if l.queued.Load() != 0 {
+		t.Errorf("expected the queue to be empty, got %d", l.queued.Load())
+	}
+}
+
+func TestExecutionLimiterNoLimit(t *testing.T) {
+	l := newExecutionLimiter(0, 0)
+	if l != nil {
+		t.Fatal("expected no limiter when there is no concurrency limit")
+	}
+	for i := 0; i < 10; i++ {
+		if err := l.checkQueue(); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.release()
+}
+
+// waitFor waits for the condition to be met, failing the test if it is not met within 5 seconds
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
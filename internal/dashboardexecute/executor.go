@@ -3,9 +3,9 @@ package dashboardexecute
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/turbot/powerpipe/internal/db_client"
-	"os"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +17,8 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/snapshot"
+	"golang.org/x/exp/maps"
 )
 
 type DashboardExecutor struct {
@@ -30,11 +32,21 @@ type DashboardExecutor struct {
 	// store the default client which is created during initData creation
 	// - this is to avoid creating a new client for each dashboard execution if the database/search path is NOT overridden
 	defaultClient *db_client.ClientMap
+	// limits the number of concurrent executions (nil if there is no limit)
+	limiter *executionLimiter
+	// tracks in-progress executions so they may be drained on shutdown
+	running sync.WaitGroup
+	// set (under the execution lock) when the executions are drained - no new executions are started once it is set
+	draining bool
+	// the paginated tables of the snapshots loaded by each session, keyed by session id
+	snapshots    map[string]*snapshotSession
+	snapshotLock sync.Mutex
 }
 
 func NewDashboardExecutor(defaultClient *db_client.ClientMap) *DashboardExecutor {
 	return &DashboardExecutor{
 		executions: make(map[string]*DashboardExecutionTree),
+		snapshots:  make(map[string]*snapshotSession),
 		// default to interactive execution
 		interactive:   true,
 		defaultClient: defaultClient,
@@ -43,12 +55,22 @@ func NewDashboardExecutor(defaultClient *db_client.ClientMap) *DashboardExecutor
 
 var Executor *DashboardExecutor
 
+// ErrShuttingDown is the error of an execution requested when the executions are being drained for shutdown
+var ErrShuttingDown = errors.New("the server is shutting down - please try again shortly")
+
+// SetExecutionLimits sets the maximum number of dashboard executions which may run concurrently,
+// and the maximum number of executions which may be queued waiting to run
+// if maxConcurrent is zero, executions are not limited
+func (e *DashboardExecutor) SetExecutionLimits(maxConcurrent, maxQueued int) {
+	e.limiter = newExecutionLimiter(maxConcurrent, maxQueued)
+}
+
 func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId string, rootResource modconfig.ModTreeItem, inputs map[string]any, workspace *dashboardworkspace.WorkspaceEvents, opts ...backend.ConnectOption) (err error) {
 	var executionTree *DashboardExecutionTree
 	defer func() {
 		if err == nil && ctx.Err() != nil {
 			if ctx.Err() != nil && ctx.Err().Error() == context.DeadlineExceeded.Error() {
-				err = fmt.Errorf("execution timed out")
+				err = db_client.NewTimeoutError("execution timed out")
 			} else {
 				err = ctx.Err()
 			}
@@ -67,19 +89,32 @@ func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId stri
 	// reset any existing executions for this session
 	e.CancelExecutionForSession(ctx, sessionId)
 
+	// if the execution queue is full, fail now rather than waiting
+	if err = e.limiter.checkQueue(); err != nil {
+		return err
+	}
+
 	// now create a new execution
 	executionTree, err = newDashboardExecutionTree(rootResource, sessionId, workspace, e.defaultClient, opts...)
 	if err != nil {
 		return err
 	}
+	executionTree.limiter = e.limiter
 
 	// if inputs must be provided before execution (i.e. this is a batch dashboard execution),
 	// verify all required inputs are provided
 	if err = e.validateInputs(executionTree, inputs); err != nil {
 		return err
 	}
+	// validate the values of text, number and date_range inputs
+	if _, err = resolveInputValues(executionTree, inputs); err != nil {
+		return err
+	}
 
-	// add to execution map
+	// record the execution as running (unless the executions are being drained) and add to execution map
+	if !e.startRunning() {
+		return ErrShuttingDown
+	}
 	e.setExecution(sessionId, executionTree)
 
 	// if inputs have been passed, set them first
@@ -87,11 +122,57 @@ func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId stri
 		executionTree.SetInputValues(inputs)
 	}
 
-	go executionTree.Execute(ctx)
+	go func() {
+		defer e.running.Done()
+		executionTree.Execute(ctx)
+	}()
 
 	return nil
 }
 
+// startRunning records the start of an execution, returning false if the executions are being drained
+func (e *DashboardExecutor) startRunning() bool {
+	e.executionLock.Lock()
+	defer e.executionLock.Unlock()
+	if e.draining {
+		return false
+	}
+	e.running.Add(1)
+	return true
+}
+
+// WaitForExecutions stops new executions from starting, and waits for all in-progress executions to complete, or for
+// the context to be cancelled
+func (e *DashboardExecutor) WaitForExecutions(ctx context.Context) error {
+	e.executionLock.Lock()
+	e.draining = true
+	e.executionLock.Unlock()
+
+	doneChan := make(chan struct{})
+	go func() {
+		e.running.Wait()
+		close(doneChan)
+	}()
+
+	select {
+	case <-doneChan:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelAllExecutions cancels all in-progress executions
+func (e *DashboardExecutor) CancelAllExecutions(ctx context.Context) {
+	e.executionLock.Lock()
+	sessionIds := maps.Keys(e.executions)
+	e.executionLock.Unlock()
+
+	for _, sessionId := range sessionIds {
+		e.CancelExecutionForSession(ctx, sessionId)
+	}
+}
+
 // if inputs must be provided before execution (i.e. this is a batch dashboard execution),
 // verify all required inputs are provided
 func (e *DashboardExecutor) validateInputs(executionTree *DashboardExecutionTree, inputs map[string]any) error {
@@ -123,7 +204,8 @@ func (e *DashboardExecutor) LoadSnapshot(ctx context.Context, sessionId, snapsho
 		return nil, fmt.Errorf("snapshot %s not does not exist", snapshotPath)
 	}
 
-	snapshotContent, err := os.ReadFile(snapshotPath)
+	// compressed snapshots are decompressed
+	snapshotContent, err := snapshot.ReadFile(snapshotPath)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +219,7 @@ func (e *DashboardExecutor) LoadSnapshot(ctx context.Context, sessionId, snapsho
 	if err != nil {
 		return nil, err
 	}
+	e.paginateSnapshot(sessionId, snap, w)
 
 	return snap, nil
 }
@@ -151,7 +234,8 @@ func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string
 	// get the previous value of this input
 	inputPrevValue := executionTree.inputValues[changedInput]
 	// first see if any other inputs rely on the one which was just changed
-	clearedInputs := e.clearDependentInputs(executionTree.Root, changedInput, inputs)
+	// if there are any dependent inputs, clear their values and send an event to the UI
+	clearedInputs, clearedValues := e.clearDependentInputs(executionTree.Root, changedInput, inputs)
 	if len(clearedInputs) > 0 {
 		event := &dashboardevents.InputValuesCleared{
 			ClearedInputs: clearedInputs,
@@ -160,9 +244,11 @@ func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string
 		}
 		executionTree.workspace.PublishDashboardEvent(ctx, event)
 	}
-	// if there are any dependent inputs, set their value to nil and send an event to the UI
 	// if the dashboard run is complete, just re-execute
-	if executionTree.GetRunStatus().IsFinished() || inputPrevValue != nil {
+	// also re-execute if:
+	// - this input or any cleared dependent input already had a value, as runs may have used the previous value
+	// - any display conditions depend on this input, as they are evaluated at execution time
+	if executionTree.GetRunStatus().IsFinished() || inputPrevValue != nil || clearedValues || executionTree.hasDisplayConditionDependingOn(changedInput) {
 		return e.ExecuteDashboard(
 			ctx,
 			sessionId,
@@ -171,30 +257,73 @@ func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string
 			executionTree.workspace)
 	}
 
+	// validate the new input values
+	if _, err := resolveInputValues(executionTree, inputs); err != nil {
+		executionTree.workspace.PublishDashboardEvent(ctx, &dashboardevents.ExecutionError{
+			Error:     err,
+			Session:   sessionId,
+			Timestamp: time.Now(),
+		})
+		return err
+	}
+
 	// set the inputs
 	executionTree.SetInputValues(inputs)
 
 	return nil
 }
 
-func (e *DashboardExecutor) clearDependentInputs(root dashboardtypes.DashboardTreeRun, changedInput string, inputs map[string]any) []string {
-	dependentInputs := root.GetInputsDependingOn(changedInput)
-	clearedInputs := dependentInputs
-	if len(dependentInputs) > 0 {
-		for _, inputName := range dependentInputs {
+// OnTablePageChanged executes the query for the requested page of a paginated table in the execution for the session
+func (e *DashboardExecutor) OnTablePageChanged(ctx context.Context, sessionId, panelName string, page int, sortColumn, sortDirection string) error {
+	executionTree, found := e.getExecution(sessionId)
+	if !found {
+		// the session may be viewing a snapshot
+		return e.onSnapshotTablePageChanged(ctx, sessionId, panelName, page, sortColumn, sortDirection)
+	}
+	run, ok := executionTree.getRun(panelName).(*LeafRun)
+	if !ok {
+		return fmt.Errorf("panel %s not found", panelName)
+	}
+	if !run.RunComplete() {
+		return fmt.Errorf("panel %s has not completed execution", panelName)
+	}
+	return run.ExecutePage(ctx, page, sortColumn, sortDirection)
+}
+
+// clearDependentInputs removes the values of all inputs which depend (directly or indirectly) on the changed input,
+// so that their options are re-resolved using the new value, and runs which use them wait for a new value to be selected
+// It returns the names of the dependent inputs, and whether any of them had a value
+// (each input is cleared once, even if it depends on the changed input through more than one path, or the
+// dependencies are cyclic)
+func (e *DashboardExecutor) clearDependentInputs(root dashboardtypes.DashboardTreeRun, changedInput string, inputs map[string]any) ([]string, bool) {
+	var clearedInputs []string
+	var clearedValues bool
+	visited := map[string]struct{}{changedInput: {}}
+	pending := []string{changedInput}
+	for len(pending) > 0 {
+		input := pending[0]
+		pending = pending[1:]
+		for _, inputName := range root.GetInputsDependingOn(input) {
+			if _, ok := visited[inputName]; ok {
+				continue
+			}
+			visited[inputName] = struct{}{}
 			if inputs[inputName] != nil {
-				// clear the input value
-				inputs[inputName] = nil
-				childDependentInputs := e.clearDependentInputs(root, inputName, inputs)
-				clearedInputs = append(clearedInputs, childDependentInputs...)
+				clearedValues = true
 			}
+			// clear the input value
+			delete(inputs, inputName)
+			clearedInputs = append(clearedInputs, inputName)
+			pending = append(pending, inputName)
 		}
 	}
 
-	return clearedInputs
+	return clearedInputs, clearedValues
 }
 
 func (e *DashboardExecutor) CancelExecutionForSession(_ context.Context, sessionId string) {
+	// remove any snapshot loaded by the session
+	e.removeSnapshot(sessionId)
 	// find the execution
 	executionTree, found := e.getExecution(sessionId)
 	if !found {
@@ -208,6 +337,19 @@ func (e *DashboardExecutor) CancelExecutionForSession(_ context.Context, session
 	e.removeExecution(sessionId)
 }
 
+// GetSessionForExecution returns the id of the session which owns the execution with the given id
+func (e *DashboardExecutor) GetSessionForExecution(executionId string) (string, bool) {
+	e.executionLock.Lock()
+	defer e.executionLock.Unlock()
+
+	for sessionId, executionTree := range e.executions {
+		if executionTree.id == executionId {
+			return sessionId, true
+		}
+	}
+	return "", false
+}
+
 // find the execution for the given session id
 func (e *DashboardExecutor) getExecution(sessionId string) (*DashboardExecutionTree, bool) {
 	e.executionLock.Lock()
@@ -0,0 +1,70 @@
+package dashboardexecute
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// inputDependencyRun is a run whose inputs depend on other inputs
+type inputDependencyRun struct {
+	dashboardtypes.DashboardTreeRun
+	// the inputs which depend on each input
+	dependents map[string][]string
+}
+
+func (r *inputDependencyRun) GetInputsDependingOn(changedInputName string) []string {
+	return r.dependents[changedInputName]
+}
+
+func TestClearDependentInputs(t *testing.T) {
+	tests := map[string]struct {
+		dependents    map[string][]string
+		inputs        map[string]any
+		wantCleared   []string
+		wantValues    bool
+		wantRemaining map[string]any
+	}{
+		"no dependents": {
+			dependents:    map[string][]string{},
+			inputs:        map[string]any{"input.a": "x", "input.b": "y"},
+			wantRemaining: map[string]any{"input.a": "x", "input.b": "y"},
+		},
+		"chain": {
+			dependents:    map[string][]string{"input.a": {"input.b"}, "input.b": {"input.c"}},
+			inputs:        map[string]any{"input.a": "x", "input.c": "z"},
+			wantCleared:   []string{"input.b", "input.c"},
+			wantValues:    true,
+			wantRemaining: map[string]any{"input.a": "x"},
+		},
+		"diamond": {
+			dependents:    map[string][]string{"input.a": {"input.b", "input.c"}, "input.b": {"input.d"}, "input.c": {"input.d"}},
+			inputs:        map[string]any{"input.a": "x", "input.d": "w"},
+			wantCleared:   []string{"input.b", "input.c", "input.d"},
+			wantValues:    true,
+			wantRemaining: map[string]any{"input.a": "x"},
+		},
+		"cycle": {
+			dependents:    map[string][]string{"input.a": {"input.b"}, "input.b": {"input.c"}, "input.c": {"input.a", "input.b"}},
+			inputs:        map[string]any{"input.a": "x"},
+			wantCleared:   []string{"input.b", "input.c"},
+			wantRemaining: map[string]any{"input.a": "x"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := &DashboardExecutor{}
+			cleared, clearedValues := e.clearDependentInputs(&inputDependencyRun{dependents: tc.dependents}, "input.a", tc.inputs)
+			if !reflect.DeepEqual(cleared, tc.wantCleared) {
+				t.Errorf("cleared inputs %v, expected %v", cleared, tc.wantCleared)
+			}
+			if clearedValues != tc.wantValues {
+				t.Errorf("cleared values %v, expected %v", clearedValues, tc.wantValues)
+			}
+			if !reflect.DeepEqual(tc.inputs, tc.wantRemaining) {
+				t.Errorf("remaining inputs %v, expected %v", tc.inputs, tc.wantRemaining)
+			}
+		})
+	}
+}
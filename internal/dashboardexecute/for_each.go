@@ -0,0 +1,283 @@
+package dashboardexecute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+// forEachParamName is the name of the param which is populated with the current item value for runs created by for_each
+const forEachParamName = "each"
+
+// forEachItem is a single item of a for_each collection
+type forEachItem struct {
+	Key   string
+	Value any
+	// the suffix added to the names of all runs created for this item (and their descendants)
+	// this includes the index of any ancestor items, so nested for_each run names are unique
+	suffix string
+}
+
+// forEachChild is a child resource with a for_each option
+// runs for the child are created for each item of the collection when the parent is initialised
+type forEachChild struct {
+	resource modconfig.DashboardLeafNode
+	source   string
+	// the index in the parent children to insert the item runs
+	position int
+}
+
+// getForEachSource returns the for_each source for the resource, if it has one
+//
+// for_each is supported for containers, cards and charts, using the "powerpipe:for_each" tag option.
+// The source is either a variable or a query, e.g. "var.accounts" or "query.regions"
+// - for a list variable, each element is an item
+// - for a map variable, each entry is an item, keyed by the map key
+// - for a query, each row is an item, with the value taken from the first column
+func getForEachSource(resource modconfig.ModTreeItem) (string, bool) {
+	switch resource.BlockType() {
+	case schema.BlockTypeContainer, schema.BlockTypeCard, schema.BlockTypeChart:
+	default:
+		return "", false
+	}
+	source, ok := tagoptions.Get(resource.GetTags(), "for_each")
+	return source, ok && source != ""
+}
+
+// addForEachChild records a for_each child - runs for it are created by expandForEach
+func (r *DashboardParentImpl) addForEachChild(resource modconfig.DashboardLeafNode, source string) {
+	r.forEachChildren = append(r.forEachChildren, forEachChild{
+		resource: resource,
+		source:   source,
+		position: len(r.children),
+	})
+	// we will have children to execute
+	r.Status = dashboardtypes.RunInitialized
+}
+
+// expandForEach resolves the items for any for_each children and creates a run for each item
+func (r *DashboardParentImpl) expandForEach(ctx context.Context) error {
+	if len(r.forEachChildren) == 0 {
+		return nil
+	}
+	parent := r.run.(dashboardtypes.DashboardParent)
+
+	// insert in reverse order so the positions of earlier children are unaffected
+	for i := len(r.forEachChildren) - 1; i >= 0; i-- {
+		child := r.forEachChildren[i]
+		items, err := r.resolveForEachItems(ctx, child)
+		if err != nil {
+			return err
+		}
+
+		itemRuns := make([]dashboardtypes.DashboardTreeRun, len(items))
+		for idx, item := range items {
+			item.suffix = fmt.Sprintf("%s_%d", runNameSuffix(parent), idx)
+			itemRuns[idx], err = newForEachRun(child.resource, parent, r.executionTree, item)
+			if err != nil {
+				return err
+			}
+		}
+		r.children = append(r.children[:child.position], append(itemRuns, r.children[child.position:]...)...)
+	}
+	r.forEachChildren = nil
+
+	// recreate the child complete channel as we have more children
+	r.createChildCompleteChan()
+	return nil
+}
+
+func newForEachRun(resource modconfig.DashboardLeafNode, parent dashboardtypes.DashboardParent, executionTree *DashboardExecutionTree, item *forEachItem) (dashboardtypes.DashboardTreeRun, error) {
+	var run dashboardtypes.DashboardTreeRun
+	var err error
+	switch r := resource.(type) {
+	case *modconfig.DashboardContainer:
+		var containerRun *DashboardContainerRun
+		containerRun, err = newDashboardContainerRun(r, parent, executionTree, item)
+		run = containerRun
+	default:
+		run, err = NewLeafRun(resource, parent, executionTree, withForEachItem(item))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *DashboardParentImpl) resolveForEachItems(ctx context.Context, child forEachChild) ([]*forEachItem, error) {
+	parsedName, err := modconfig.ParseResourceName(child.source)
+	if err != nil || (parsedName.ItemType != schema.AttributeVar && parsedName.ItemType != schema.BlockTypeQuery) {
+		return nil, fmt.Errorf("%s has an invalid for_each source '%s' - must be a variable or query", child.resource.Name(), child.source)
+	}
+	// resolve unqualified names relative to the mod of the resource
+	if parsedName.Mod == "" {
+		parsedName.Mod = child.resource.GetMod().ShortName
+	}
+	resource, ok := r.executionTree.workspace.GetResource(parsedName)
+	if !ok {
+		return nil, fmt.Errorf("%s for_each source '%s' not found", child.resource.Name(), child.source)
+	}
+
+	switch source := resource.(type) {
+	case *modconfig.Variable:
+		return forEachItemsFromValue(source.ValueGo)
+	case *modconfig.Query:
+		return r.forEachItemsFromQuery(ctx, source)
+	}
+	return nil, fmt.Errorf("%s has an invalid for_each source '%s' - must be a variable or query", child.resource.Name(), child.source)
+}
+
+func forEachItemsFromValue(value any) ([]*forEachItem, error) {
+	var items []*forEachItem
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			items = append(items, &forEachItem{Key: fmt.Sprintf("%v", element), Value: element})
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// iterate in a stable order
+		sort.Strings(keys)
+		for _, k := range keys {
+			items = append(items, &forEachItem{Key: k, Value: v[k]})
+		}
+	default:
+		return nil, fmt.Errorf("for_each variable must be a list or map, got %T", value)
+	}
+	return items, nil
+}
+
+func (r *DashboardParentImpl) forEachItemsFromQuery(ctx context.Context, query *modconfig.Query) ([]*forEachItem, error) {
+	if query.SQL == nil {
+		return nil, fmt.Errorf("for_each query %s has no sql", query.Name())
+	}
+	// the query is executed with its param defaults, and any for_each item value and component instance params of the parent
+	runtimeArgs := modconfig.NewQueryArgs()
+	if err := setInheritedArgs(runtimeArgs, r.run, query); err != nil {
+		return nil, err
+	}
+	resolvedQuery, err := r.executionTree.workspace.ResolveQueryFromQueryProvider(query, runtimeArgs)
+	if err != nil {
+		return nil, err
+	}
+	database, searchPathConfig, err := resolveDatabaseConfig(query, r.executionTree)
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.executionTree.getClient(ctx, database, searchPathConfig)
+	if err != nil {
+		return nil, err
+	}
+	queryResult, err := client.ExecuteSync(ctx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*forEachItem
+	for _, row := range queryResult.Rows {
+		rowResult, ok := row.(*localqueryresult.RowResult)
+		if !ok || len(rowResult.Data) == 0 {
+			continue
+		}
+		value := rowResult.Data[0]
+		items = append(items, &forEachItem{Key: fmt.Sprintf("%v", value), Value: value})
+	}
+	return items, nil
+}
+
+// title returns the title for a run created for this item
+func (i *forEachItem) title(baseTitle string) string {
+	if baseTitle == "" {
+		return i.Key
+	}
+	return fmt.Sprintf("%s: %s", baseTitle, i.Key)
+}
+
+// findForEachItem returns the for_each item of the given run or its nearest ancestor created by for_each
+func findForEachItem(run dashboardtypes.DashboardTreeRun) *forEachItem {
+	for run != nil {
+		if r, ok := run.(interface{ getForEachItem() *forEachItem }); ok {
+			if item := r.getForEachItem(); item != nil {
+				return item
+			}
+		}
+		parent := run.GetParent()
+		if parent == nil {
+			return nil
+		}
+		run = parent
+	}
+	return nil
+}
+
+// runNameSuffix returns the suffix to add to the names of runs created beneath the given parent,
+// so that runs repeated by for_each, or by instantiating a component more than once, have unique names
+func runNameSuffix(parent dashboardtypes.DashboardParent) string {
+	var run dashboardtypes.DashboardTreeRun = parent
+	for run != nil {
+		if r, ok := run.(interface{ getNameSuffix() string }); ok {
+			if suffix := r.getNameSuffix(); suffix != "" {
+				return suffix
+			}
+		}
+		parent := run.GetParent()
+		if parent == nil {
+			return ""
+		}
+		run = parent
+	}
+	return ""
+}
+
+// hasForEachParam returns whether the query provider declares the param populated with the for_each item value
+func hasForEachParam(queryProvider modconfig.QueryProvider) bool {
+	return hasParam(queryProvider, forEachParamName)
+}
+
+// hasParam returns whether the query provider, or its query, declares a param with the given name
+func hasParam(queryProvider modconfig.QueryProvider, name string) bool {
+	// NOTE: do not append the query params to the params of the query provider, as that may modify its params
+	paramLists := [][]*modconfig.ParamDef{queryProvider.GetParams()}
+	if query := queryProvider.GetQuery(); query != nil {
+		paramLists = append(paramLists, query.GetParams())
+	}
+	for _, params := range paramLists {
+		for _, p := range params {
+			if p.ShortName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setInheritedArgs sets the args which the query provider of a run inherits from its ancestors:
+// - if the run was created by for_each, the item value is passed to the 'each' param
+// - if the run is part of a component instance, the instance params are passed to the params of the same name
+func setInheritedArgs(runtimeArgs *modconfig.QueryArgs, run dashboardtypes.DashboardTreeRun, queryProvider modconfig.QueryProvider) error {
+	if item := findForEachItem(run); item != nil && hasForEachParam(queryProvider) {
+		if err := runtimeArgs.SetNamedArgVal(forEachParamName, item.Value); err != nil {
+			return err
+		}
+	}
+	if instance := findComponentInstance(run); instance != nil {
+		for name, value := range instance.params {
+			if !hasParam(queryProvider, name) {
+				continue
+			}
+			if err := runtimeArgs.SetNamedArgVal(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
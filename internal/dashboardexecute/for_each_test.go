@@ -0,0 +1,157 @@
+package dashboardexecute
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/db_client"
+)
+
+func TestForEachItemsFromValue(t *testing.T) {
+	tests := map[string]struct {
+		value    any
+		expected []*forEachItem
+		wantErr  bool
+	}{
+		"list": {
+			value:    []any{"b", "a"},
+			expected: []*forEachItem{{Key: "b", Value: "b"}, {Key: "a", Value: "a"}},
+		},
+		"map": {
+			value:    map[string]any{"prod": 2, "dev": 1},
+			expected: []*forEachItem{{Key: "dev", Value: 1}, {Key: "prod", Value: 2}},
+		},
+		"empty list": {
+			value: []any{},
+		},
+		"string": {
+			value:   "a",
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			items, err := forEachItemsFromValue(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", items)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(items, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, items)
+			}
+		})
+	}
+}
+
+func TestHasParam(t *testing.T) {
+	mod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+	query := modconfig.NewQuery(&hcl.Block{Type: schema.BlockTypeQuery}, mod, "q").(*modconfig.Query)
+	query.Params = []*modconfig.ParamDef{{ShortName: "region"}}
+	card := modconfig.NewDashboardCard(&hcl.Block{Type: schema.BlockTypeCard}, mod, "c").(*modconfig.DashboardCard)
+	card.Query = query
+	// params with spare capacity, which appending the query params would write to
+	card.Params = make([]*modconfig.ParamDef, 1, 4)
+	card.Params[0] = &modconfig.ParamDef{ShortName: forEachParamName}
+
+	if !hasParam(card, forEachParamName) {
+		t.Error("expected the param of the card to be found")
+	}
+	if !hasParam(card, "region") {
+		t.Error("expected the param of the query to be found")
+	}
+	if hasParam(card, "account") {
+		t.Error("expected an undeclared param not to be found")
+	}
+	if spare := card.Params[:cap(card.Params)][1]; spare != nil {
+		t.Errorf("expected the params of the card to be unmodified, got %v", spare)
+	}
+}
+
+func TestForEachItemsFromQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("create table regions (name text, account text)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range [][]string{{"us-east-1", "prod"}, {"eu-west-1", "prod"}, {"us-west-2", "dev"}} {
+		if _, err := db.Exec("insert into regions values (?, ?)", row[0], row[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+	executionTree := &DashboardExecutionTree{
+		clientMap:        db_client.NewClientMap(),
+		defaultClientMap: db_client.NewClientMap(),
+		workspace:        dashboardworkspace.NewWorkspaceEvents(&workspace.Workspace{Mod: mod}),
+		// the query specifies its own database, so the dashboard database is not used
+		database: "sqlite://" + filepath.Join(t.TempDir(), "missing", "dashboard.db"),
+	}
+	t.Cleanup(func() { executionTree.clientMap.Close(context.Background()) })
+
+	defaultAccount := `"prod"`
+	tests := map[string]struct {
+		param    string
+		each     *forEachItem
+		expected []string
+	}{
+		"param default": {
+			param:    "account",
+			expected: []string{"eu-west-1", "us-east-1"},
+		},
+		"for_each item of the parent": {
+			param:    forEachParamName,
+			each:     &forEachItem{Key: "dev", Value: "dev"},
+			expected: []string{"us-west-2"},
+		},
+		"for_each item of the parent not declared as a param": {
+			param:    "account",
+			each:     &forEachItem{Key: "dev", Value: "dev"},
+			expected: []string{"eu-west-1", "us-east-1"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query := modconfig.NewQuery(&hcl.Block{Type: schema.BlockTypeQuery}, mod, "regions").(*modconfig.Query)
+			querySQL := "select name from regions where account = $1 order by name"
+			database := "sqlite://" + path
+			query.SQL = &querySQL
+			query.Database = &database
+			query.Params = []*modconfig.ParamDef{{ShortName: tc.param, Default: &defaultAccount}}
+
+			parent := &DashboardContainerRun{}
+			parent.executionTree = executionTree
+			parent.run = parent
+			parent.each = tc.each
+
+			items, err := parent.forEachItemsFromQuery(context.Background(), query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Key)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected items %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
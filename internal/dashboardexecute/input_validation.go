@@ -0,0 +1,144 @@
+package dashboardexecute
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+const (
+	inputTypeText      = "text"
+	inputTypeNumber    = "number"
+	inputTypeDateRange = "date_range"
+)
+
+// resolveInputValues validates the values of any text, number or date_range inputs, and returns the values
+// to pass to dependent queries. Validation uses the options of the input:
+//
+//	"powerpipe:pattern" - a regular expression which text input values must match
+//	"powerpipe:min"     - the minimum value of a number input
+//	"powerpipe:max"     - the maximum value of a number input
+//
+// The values of number inputs are converted to numbers, so they are passed to queries as numeric args,
+// and the values of date_range inputs are converted to a map of start and end timestamps.
+// If a value is invalid, the unresolved value is returned along with the error.
+//
+// NOTE: inputs with no query must have a placeholder or options (unless they are text inputs)
+func resolveInputValues(executionTree *DashboardExecutionTree, inputs map[string]any) (map[string]any, error) {
+	res := make(map[string]any, len(inputs))
+	for name, value := range inputs {
+		res[name] = value
+	}
+
+	// we only support inputs if root is a dashboard (NOT a benchmark)
+	dashboardRun, ok := executionTree.Root.(*DashboardRun)
+	if !ok {
+		return res, nil
+	}
+
+	var errors []error
+	for name, value := range inputs {
+		input, ok := dashboardRun.GetInput(name)
+		if !ok || value == nil {
+			continue
+		}
+		resolvedValue, err := resolveInputValue(input, value)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%s: %s", name, err.Error()))
+			continue
+		}
+		res[name] = resolvedValue
+	}
+	return res, error_helpers.CombineErrors(errors...)
+}
+
+func resolveInputValue(input *modconfig.DashboardInput, value any) (any, error) {
+	switch typehelpers.SafeString(input.Type) {
+	case inputTypeNumber:
+		return validateNumberInputValue(input.GetTags(), value)
+	case inputTypeText:
+		return validateTextInputValue(input.GetTags(), value)
+	case inputTypeDateRange:
+		return resolveDateRange(value, time.Now())
+	}
+	return value, nil
+}
+
+func validateNumberInputValue(tags map[string]string, value any) (any, error) {
+	number, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	if min, ok := tagoptions.Get(tags, "min"); ok {
+		if limit, err := strconv.ParseFloat(min, 64); err == nil && number < limit {
+			return nil, fmt.Errorf("value must be at least %s", min)
+		}
+	}
+	if max, ok := tagoptions.Get(tags, "max"); ok {
+		if limit, err := strconv.ParseFloat(max, 64); err == nil && number > limit {
+			return nil, fmt.Errorf("value must be at most %s", max)
+		}
+	}
+	// pass whole numbers as integers
+	if number == math.Trunc(number) && math.Abs(number) < math.MaxInt64 {
+		return int64(number), nil
+	}
+	return number, nil
+}
+
+func validateTextInputValue(tags map[string]string, value any) (any, error) {
+	pattern, ok := tagoptions.Get(tags, "pattern")
+	if !ok {
+		return value, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation pattern '%s': %s", pattern, err.Error())
+	}
+	if !re.MatchString(fmt.Sprintf("%v", value)) {
+		return nil, fmt.Errorf("value '%v' does not match the pattern '%s'", value, pattern)
+	}
+	return value, nil
+}
+
+func toNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value '%s' is not a number", v)
+		}
+		return number, nil
+	}
+	return 0, fmt.Errorf("value '%v' is not a number", value)
+}
+
+// getInputValueProperty returns the property of a structured input value at the given path,
+// e.g. the "start" property of a date_range input value
+func getInputValueProperty(value any, path []string) (any, error) {
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("input value has no property '%s'", key)
+		}
+		if value, ok = m[key]; !ok {
+			return nil, fmt.Errorf("input value has no property '%s'", key)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,186 @@
+package dashboardexecute
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+func newTestInput(inputType string, tags map[string]string) *modconfig.DashboardInput {
+	mod := modconfig.NewMod("local", "/workspace", hcl.Range{})
+	input := modconfig.NewDashboardInput(&hcl.Block{Type: schema.BlockTypeInput}, mod, "test").(*modconfig.DashboardInput)
+	input.Type = &inputType
+	input.Tags = tags
+	return input
+}
+
+func TestResolveInputValue(t *testing.T) {
+	tests := map[string]struct {
+		inputType string
+		tags      map[string]string
+		value     any
+		expected  any
+		expectErr string
+	}{
+		"number string": {
+			inputType: inputTypeNumber,
+			value:     "42",
+			expected:  int64(42),
+		},
+		"number fraction": {
+			inputType: inputTypeNumber,
+			value:     "1.5",
+			expected:  1.5,
+		},
+		"number float64": {
+			inputType: inputTypeNumber,
+			value:     float64(7),
+			expected:  int64(7),
+		},
+		"number int": {
+			inputType: inputTypeNumber,
+			value:     3,
+			expected:  int64(3),
+		},
+		"not a number": {
+			inputType: inputTypeNumber,
+			value:     "abc",
+			expectErr: "value 'abc' is not a number",
+		},
+		"not a number type": {
+			inputType: inputTypeNumber,
+			value:     true,
+			expectErr: "value 'true' is not a number",
+		},
+		"number within limits": {
+			inputType: inputTypeNumber,
+			tags:      map[string]string{"powerpipe:min": "1", "powerpipe:max": "10"},
+			value:     "10",
+			expected:  int64(10),
+		},
+		"number below min": {
+			inputType: inputTypeNumber,
+			tags:      map[string]string{"powerpipe:min": "1", "powerpipe:max": "10"},
+			value:     "0",
+			expectErr: "value must be at least 1",
+		},
+		"number above max": {
+			inputType: inputTypeNumber,
+			tags:      map[string]string{"powerpipe:min": "1", "powerpipe:max": "10"},
+			value:     "10.5",
+			expectErr: "value must be at most 10",
+		},
+		"invalid limit is ignored": {
+			inputType: inputTypeNumber,
+			tags:      map[string]string{"powerpipe:max": "ten"},
+			value:     "100",
+			expected:  int64(100),
+		},
+		"text without pattern": {
+			inputType: inputTypeText,
+			value:     "anything",
+			expected:  "anything",
+		},
+		"text matching pattern": {
+			inputType: inputTypeText,
+			tags:      map[string]string{"powerpipe:pattern": `^\d{12}$`},
+			value:     "123456789012",
+			expected:  "123456789012",
+		},
+		"text not matching pattern": {
+			inputType: inputTypeText,
+			tags:      map[string]string{"powerpipe:pattern": `^\d{12}$`},
+			value:     "12345",
+			expectErr: `value '12345' does not match the pattern '^\d{12}$'`,
+		},
+		"invalid pattern": {
+			inputType: inputTypeText,
+			tags:      map[string]string{"powerpipe:pattern": `[`},
+			value:     "a",
+			expectErr: "invalid validation pattern '[': error parsing regexp: missing closing ]: `[`",
+		},
+		"date range": {
+			inputType: inputTypeDateRange,
+			value:     "2024-01-01/2024-01-31",
+			expected:  map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-31T23:59:59Z"},
+		},
+		"other input types are not validated": {
+			inputType: "select",
+			tags:      map[string]string{"powerpipe:pattern": `^a$`},
+			value:     "b",
+			expected:  "b",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := resolveInputValue(newTestInput(test.inputType, test.tags), test.value)
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("got %#v, expected %#v", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestGetInputValueProperty(t *testing.T) {
+	value := map[string]any{
+		"start": "2024-01-01T00:00:00Z",
+		"nested": map[string]any{
+			"key": "value",
+		},
+	}
+	tests := map[string]struct {
+		path      []string
+		expected  any
+		expectErr string
+	}{
+		"property": {
+			path:     []string{"start"},
+			expected: "2024-01-01T00:00:00Z",
+		},
+		"nested property": {
+			path:     []string{"nested", "key"},
+			expected: "value",
+		},
+		"empty path": {
+			path:     nil,
+			expected: value,
+		},
+		"missing property": {
+			path:      []string{"end"},
+			expectErr: "input value has no property 'end'",
+		},
+		"property of a scalar": {
+			path:      []string{"start", "year"},
+			expectErr: "input value has no property 'year'",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := getInputValueProperty(value, test.path)
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("got %#v, expected %#v", res, test.expected)
+			}
+		})
+	}
+}
@@ -14,9 +14,15 @@ import (
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/statushooks"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/dashboardtransform"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/querylog"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
 	"github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+	"github.com/turbot/powerpipe/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // LeafRun is a struct representing the execution of a leaf dashboard node
@@ -28,6 +34,8 @@ type LeafRun struct {
 	// this is populated by retrieving Resource properties with the snapshot tag
 	Properties map[string]any           `json:"properties,omitempty"`
 	Data       *dashboardtypes.LeafData `json:"data,omitempty"`
+	// the timing of the query, if it completed
+	Timing *localqueryresult.TimingMetadata `json:"-"`
 	// function called when the run is complete
 	// this property populated for 'with' runs
 	onComplete       func()
@@ -76,7 +84,7 @@ func NewLeafRun(resource modconfig.DashboardLeafNode, parent dashboardtypes.Dash
 		}
 	}
 	// add r into execution tree
-	executionTree.runs[r.Name] = r
+	executionTree.addRun(r)
 
 	// if we have children (nodes/edges), create runs for them
 	err = r.createChildRuns(executionTree)
@@ -97,15 +105,28 @@ func NewLeafRun(resource modconfig.DashboardLeafNode, parent dashboardtypes.Dash
 }
 
 func (r *LeafRun) resolveDatabaseConfig() error {
-	// resolve the database and connection string for the run
-	database, searchPathConfig, err := db_client.GetDatabaseConfigForResource(r.Resource, r.executionTree.workspace.Mod, r.executionTree.database, r.executionTree.searchPathConfig)
+	database, searchPathConfig, err := resolveDatabaseConfig(r.resource, r.executionTree)
 	if err != nil {
 		return err
 	}
-	// if the resource specifies a database, use that
-	if c, ok := r.resource.(modconfig.DatabaseItem); ok {
+	r.database = database
+	r.searchPathConfig = searchPathConfig
+	return nil
+}
+
+// resolveDatabaseConfig resolves the database and search path config used to execute the query of a resource
+func resolveDatabaseConfig(resource modconfig.ModTreeItem, executionTree *DashboardExecutionTree) (string, backend.SearchPathConfig, error) {
+	// resolve the database and connection string for the run
+	database, searchPathConfig, err := db_client.GetDatabaseConfigForResource(resource, executionTree.workspace.Mod, executionTree.database, executionTree.searchPathConfig)
+	if err != nil {
+		return "", backend.SearchPathConfig{}, err
+	}
+	// if the resource specifies a database, use that (otherwise use the database of its query, if it specifies one)
+	if c, ok := resource.(modconfig.DatabaseItem); ok {
 		if resourceDatabase := c.GetDatabase(); resourceDatabase != nil {
 			database = *resourceDatabase
+		} else if queryDatabase := getQueryDatabase(resource); queryDatabase != nil {
+			database = *queryDatabase
 		}
 		if resourceSearchPath := c.GetSearchPath(); len(resourceSearchPath) > 0 {
 			searchPathConfig.SearchPath = resourceSearchPath
@@ -114,10 +135,16 @@ func (r *LeafRun) resolveDatabaseConfig() error {
 			searchPathConfig.SearchPathPrefix = resourceSearchPathPrefix
 		}
 	}
+	return database, searchPathConfig, nil
+}
 
-	r.database = database
-	r.searchPathConfig = searchPathConfig
-	return nil
+// getQueryDatabase returns the database of the query used by the resource, if the query specifies one
+func getQueryDatabase(resource modconfig.ModTreeItem) *string {
+	queryProvider, ok := resource.(modconfig.QueryProvider)
+	if !ok || queryProvider.GetQuery() == nil {
+		return nil
+	}
+	return queryProvider.GetQuery().GetDatabase()
 }
 
 func (r *LeafRun) createChildRuns(executionTree *DashboardExecutionTree) error {
@@ -157,8 +184,23 @@ func (r *LeafRun) Execute(ctx context.Context) {
 		return
 	}
 
+	ctx, span := tracing.StartSpan(ctx, r.GetNodeType(), r.GetName())
+	defer func() {
+		tracing.EndSpan(span, r.GetError())
+	}()
+
 	slog.Debug("LeafRun Execute()", "name", r.resource.Name())
 
+	// if we have a display condition which is not met, we are hidden - there is nothing to execute
+	if visible, err := r.evaluateDisplayCondition(ctx); err != nil {
+		r.SetError(ctx, err)
+		return
+	} else if !visible {
+		r.Hidden = true
+		r.SetComplete(ctx)
+		return
+	}
+
 	// to get here, we must be a query provider
 
 	// if we have children and with runs, start them asynchronously (they may block waiting for our runtime dependencies)
@@ -219,13 +261,13 @@ func (r *LeafRun) SetComplete(ctx context.Context) {
 func (*LeafRun) IsSnapshotPanel() {}
 
 // if this leaf run has a query or sql, execute it now
-func (r *LeafRun) executeQuery(ctx context.Context) error {
+func (r *LeafRun) executeQuery(ctx context.Context) (err error) {
 	slog.Debug("LeafRun SQL resolved, executing", "name", r.resource.Name())
 
 	// check for context errors
 	if err := ctx.Err(); err != nil {
 		if err.Error() == context.DeadlineExceeded.Error() {
-			err = fmt.Errorf("dashboard execution timed out before execution of this node started")
+			err = db_client.NewTimeoutError("dashboard execution timed out before execution of this node started")
 		}
 		return err
 	}
@@ -238,24 +280,69 @@ func (r *LeafRun) executeQuery(ctx context.Context) error {
 	}
 
 	startTime := time.Now()
+	ctx = querylog.WithResource(ctx, r.resource.Name())
+	ctx, span := tracing.StartQuerySpan(ctx, r.resource.Name(), r.executeSQL)
+	defer func() {
+		if r.Data != nil {
+			span.SetAttributes(attribute.Int(tracing.AttributeRows, len(r.Data.Rows)))
+		}
+		tracing.EndSpan(span, err)
+	}()
+
+	// if this is a paginated table, only retrieve the first page of results
+	if pageSize := getPageSize(r.resource); pageSize > 0 {
+		// the transform is applied to the full results, so cannot be combined with pagination
+		if _, ok := tagoptions.Get(r.resource.GetTags(), "transform"); ok {
+			return fmt.Errorf("%s cannot set both the page_size and transform options", r.resource.Name())
+		}
+		r.Data, r.Timing, err = r.executePagedQuery(ctx, &dashboardtypes.LeafDataPagination{PageSize: pageSize})
+		if err != nil && err.Error() == context.DeadlineExceeded.Error() {
+			err = db_client.NewTimeoutError("query execution timed out after running for %0.2fs", time.Since(startTime).Seconds())
+		}
+		return err
+	}
+
 	queryResult, err := client.ExecuteSync(ctx, r.executeSQL, r.Args...)
 	if err != nil {
 		if err.Error() == context.DeadlineExceeded.Error() {
-			err = fmt.Errorf("query execution timed out after running for %0.2fs", time.Since(startTime).Seconds())
+			err = db_client.NewTimeoutError("query execution timed out after running for %0.2fs", time.Since(startTime).Seconds())
 		}
 		slog.Debug("LeafRun query failed", "name", r.resource.Name(), "error", err.Error())
 		return err
 	}
 	slog.Debug("LeafRun complete", "name", r.resource.Name())
 
+	renderStartTime := time.Now()
 	r.Data, err = dashboardtypes.NewLeafData(queryResult)
 	if err != nil {
 		return err
 
 	}
+	if err := r.applyTransform(); err != nil {
+		return err
+	}
+	if timing := queryResult.Timing; timing != nil {
+		timing.Render = time.Since(renderStartTime)
+		timing.Duration += timing.Render
+		r.Timing = timing
+	}
 	return nil
 }
 
+// applyTransform applies the transform pipeline set using the "powerpipe:transform" tag option (if any) to the query results
+func (r *LeafRun) applyTransform() error {
+	transform, ok := tagoptions.Get(r.resource.GetTags(), "transform")
+	if !ok || transform == "" {
+		return nil
+	}
+	pipeline, err := dashboardtransform.Parse(transform)
+	if err != nil {
+		return err
+	}
+	r.Data, err = pipeline.Apply(r.Data)
+	return err
+}
+
 func (r *LeafRun) combineChildData() {
 	// we either have children OR a query
 	// if there are no children, do nothing
@@ -7,3 +7,9 @@ func setName(name string) LeafRunOption {
 		target.Name = name
 	}
 }
+
+func withForEachItem(item *forEachItem) LeafRunOption {
+	return func(target *LeafRun) {
+		target.setForEachItem(item)
+	}
+}
@@ -125,6 +125,23 @@ func (s *RuntimeDependencySubscriberImpl) resolveRuntimeDependencies() error {
 				}
 				return transformedResolvedVal
 			}))
+		case schema.BlockTypeInput:
+			// inputs with structured values (e.g. date_range) may have a property of the value referenced,
+			// e.g. self.input.period.value.start - set a transform function to extract the property
+			if len(dep.PropertyPath.PropertyPath) > 1 {
+				opts = append(opts, WithTransform(func(resolvedVal *dashboardtypes.ResolvedRuntimeDependencyValue) *dashboardtypes.ResolvedRuntimeDependencyValue {
+					transformedResolvedVal := &dashboardtypes.ResolvedRuntimeDependencyValue{Error: resolvedVal.Error}
+					if resolvedVal.Error == nil {
+						inputValue, err := getInputValueProperty(resolvedVal.Value, dep.PropertyPath.PropertyPath[1:])
+						if err != nil {
+							transformedResolvedVal.Error = fmt.Errorf("failed to resolve input value '%s' for %s: %s", dep.PropertyPath.Original, name, err.Error())
+						} else {
+							transformedResolvedVal.Value = inputValue
+						}
+					}
+					return transformedResolvedVal
+				}))
+			}
 		}
 		// subscribe, passing a function which invokes getWithValue to resolve the required with value
 		valueChannel := publisher.SubscribeToRuntimeDependency(d.SourceResourceName(), opts...)
@@ -169,7 +186,7 @@ func (s *RuntimeDependencySubscriberImpl) findRuntimeDependencyPublisher(runtime
 
 	// "if I am a nested resource, my dashboard provides my dependencies"
 	// otherwise the dashboard run must be the publisher
-	dashboardRun := s.executionTree.runs[s.DashboardName].(RuntimeDependencyPublisher)
+	dashboardRun := s.executionTree.getRun(s.DashboardName).(RuntimeDependencyPublisher)
 	if dashboardRun.ProvidesRuntimeDependency(runtimeDependency) {
 		return dashboardRun
 	}
@@ -332,6 +349,11 @@ func (s *RuntimeDependencySubscriberImpl) resolveSQLAndArgs() error {
 		return err
 	}
 
+	// pass any for_each item value and component instance params
+	if err := setInheritedArgs(runtimeArgs, s.run, queryProvider); err != nil {
+		return err
+	}
+
 	slog.Debug("built runtime args: %v", s.resource.Name(), runtimeArgs)
 
 	// does this leaf run have any SQL to execute?
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/controlexecute"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/timingreport"
 )
 
 func GenerateSnapshot(ctx context.Context, w *dashboardworkspace.WorkspaceEvents, rootResource modconfig.ModTreeItem, inputs map[string]any) (snapshot *steampipeconfig.SteampipeSnapshot, err error) {
@@ -72,3 +74,22 @@ func ExecutionCompleteToSnapshot(event *dashboardevents.ExecutionComplete) *stea
 		Title:         event.Root.GetTitle(),
 	}
 }
+
+// SnapshotTimingReport returns the timing of the queries of the panels (and controls) of a snapshot generated by
+// GenerateSnapshot
+func SnapshotTimingReport(snap *steampipeconfig.SteampipeSnapshot) *timingreport.Report {
+	res := &timingreport.Report{Name: snap.FileNameRoot, Duration: snap.EndTime.Sub(snap.StartTime)}
+	for _, panel := range snap.Panels {
+		switch r := panel.(type) {
+		case *LeafRun:
+			rows := 0
+			if r.Data != nil {
+				rows = len(r.Data.Rows)
+			}
+			res.Add(r.Name, r.NodeType, rows, r.Timing)
+		case *controlexecute.ControlRun:
+			res.Add(r.Control.Name(), r.NodeType, len(r.Rows), r.Timing)
+		}
+	}
+	return res
+}
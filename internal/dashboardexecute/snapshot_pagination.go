@@ -0,0 +1,191 @@
+package dashboardexecute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/dashboardevents"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+)
+
+// snapshotPageSize is the number of rows of a snapshot table sent to the client when the snapshot is loaded - tables
+// with more rows are paginated, and the remaining rows are sent a page at a time as the client requests them, so
+// large snapshots can be viewed without sending every row to the browser
+const snapshotPageSize = 500
+
+// snapshotTable is a paginated table of a snapshot loaded by a session
+type snapshotTable struct {
+	panel   map[string]any
+	columns []any
+	rows    []any
+}
+
+// snapshotSession holds the paginated tables of the snapshot loaded by a session
+type snapshotSession struct {
+	workspace *dashboardworkspace.WorkspaceEvents
+	tables    map[string]*snapshotTable
+}
+
+// paginateSnapshot replaces the rows of the large tables of the snapshot with their first page, storing the full
+// rows for the session so further pages can be requested
+func (e *DashboardExecutor) paginateSnapshot(sessionId string, snap map[string]any, w *dashboardworkspace.WorkspaceEvents) {
+	session := &snapshotSession{
+		workspace: w,
+		tables:    make(map[string]*snapshotTable),
+	}
+	panels, _ := snap["panels"].(map[string]any)
+	for name, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok || panel["panel_type"] != schema.BlockTypeTable {
+			continue
+		}
+		data, ok := panel["data"].(map[string]any)
+		if !ok {
+			continue
+		}
+		rows, _ := data["rows"].([]any)
+		if len(rows) <= snapshotPageSize {
+			continue
+		}
+		columns, _ := data["columns"].([]any)
+		table := &snapshotTable{panel: panel, columns: columns, rows: rows}
+		session.tables[name] = table
+		panels[name] = table.page(&dashboardtypes.LeafDataPagination{PageSize: snapshotPageSize}, rows)
+	}
+
+	e.snapshotLock.Lock()
+	defer e.snapshotLock.Unlock()
+	if len(session.tables) == 0 {
+		delete(e.snapshots, sessionId)
+		return
+	}
+	e.snapshots[sessionId] = session
+}
+
+// onSnapshotTablePageChanged sends a page of a paginated table of the snapshot loaded by the session to the client
+func (e *DashboardExecutor) onSnapshotTablePageChanged(ctx context.Context, sessionId, panelName string, page int, sortColumn, sortDirection string) error {
+	e.snapshotLock.Lock()
+	session, found := e.snapshots[sessionId]
+	e.snapshotLock.Unlock()
+	if !found {
+		return fmt.Errorf("no dashboard running for session %s", sessionId)
+	}
+	table, ok := session.tables[panelName]
+	if !ok {
+		return fmt.Errorf("%s is not paginated", panelName)
+	}
+	if page < 0 {
+		return fmt.Errorf("invalid page %d", page)
+	}
+
+	pagination := &dashboardtypes.LeafDataPagination{
+		Page:     page,
+		PageSize: snapshotPageSize,
+	}
+	rows := table.rows
+	if sortColumn != "" {
+		if !table.hasColumn(sortColumn) {
+			return fmt.Errorf("%s has no column '%s'", panelName, sortColumn)
+		}
+		switch sortDirection {
+		case sortAscending, sortDescending:
+		case "":
+			sortDirection = sortAscending
+		default:
+			return fmt.Errorf("invalid sort direction '%s' - must be '%s' or '%s'", sortDirection, sortAscending, sortDescending)
+		}
+		pagination.SortColumn = sortColumn
+		pagination.SortDirection = sortDirection
+		rows = sortSnapshotRows(rows, sortColumn, sortDirection)
+	}
+
+	event := &dashboardevents.LeafNodeUpdated{
+		LeafNode:  table.page(pagination, rows),
+		Session:   sessionId,
+		Timestamp: time.Now(),
+	}
+	session.workspace.PublishDashboardEvent(ctx, event)
+	return nil
+}
+
+// page returns a copy of the table panel containing the given page of the rows
+func (t *snapshotTable) page(pagination *dashboardtypes.LeafDataPagination, rows []any) map[string]any {
+	pagination.TotalRows = int64(len(rows))
+	start := min(pagination.Page*pagination.PageSize, len(rows))
+	end := min(start+pagination.PageSize, len(rows))
+
+	res := make(map[string]any, len(t.panel))
+	for k, v := range t.panel {
+		res[k] = v
+	}
+	res["data"] = map[string]any{
+		"columns":    t.columns,
+		"rows":       rows[start:end],
+		"pagination": pagination,
+	}
+	return res
+}
+
+func (t *snapshotTable) hasColumn(name string) bool {
+	for _, c := range t.columns {
+		if column, ok := c.(map[string]any); ok && column["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *DashboardExecutor) removeSnapshot(sessionId string) {
+	e.snapshotLock.Lock()
+	defer e.snapshotLock.Unlock()
+	delete(e.snapshots, sessionId)
+}
+
+// sortSnapshotRows returns a copy of the rows sorted by the column - numbers are sorted numerically, other values
+// by their string representation, with nulls first
+func sortSnapshotRows(rows []any, column, direction string) []any {
+	res := make([]any, len(rows))
+	copy(res, rows)
+	value := func(row any) any {
+		if r, ok := row.(map[string]any); ok {
+			return r[column]
+		}
+		return nil
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		c := compareSnapshotValues(value(res[i]), value(res[j]))
+		if direction == sortDescending {
+			return c > 0
+		}
+		return c < 0
+	})
+	return res
+}
+
+func compareSnapshotValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if af, ok := a.(float64); ok {
+		if bf, ok := b.(float64); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
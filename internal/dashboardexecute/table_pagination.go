@@ -0,0 +1,148 @@
+package dashboardexecute
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/dashboardevents"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/db_client"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+const (
+	sortAscending  = "asc"
+	sortDescending = "desc"
+)
+
+// getPageSize returns the page size for the resource - this is set for tables using the "powerpipe:page_size" tag option
+// if the results of the resource are not paginated, return 0
+func getPageSize(resource modconfig.DashboardLeafNode) int {
+	if resource.BlockType() != schema.BlockTypeTable {
+		return 0
+	}
+	value, ok := tagoptions.Get(resource.GetTags(), "page_size")
+	if !ok {
+		return 0
+	}
+	pageSize, err := strconv.Atoi(value)
+	if err != nil || pageSize < 0 {
+		return 0
+	}
+	return pageSize
+}
+
+// executePagedQuery executes the query for a page of the results, and the query to count the total rows,
+// sorting the results by the given column (if any). The timing returned is the combined timing of both queries
+func (r *LeafRun) executePagedQuery(ctx context.Context, pagination *dashboardtypes.LeafDataPagination) (*dashboardtypes.LeafData, *localqueryresult.TimingMetadata, error) {
+	client, err := r.executionTree.getClient(ctx, r.database, r.searchPathConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	countSQL, pagedSQL, err := client.PageQueries(r.executeSQL, db_client.Page{
+		SortColumn:    pagination.SortColumn,
+		SortDirection: pagination.SortDirection,
+		Limit:         pagination.PageSize,
+		Offset:        pagination.Page * pagination.PageSize,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	countResult, err := client.ExecuteSync(ctx, countSQL, r.Args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(countResult.Rows) > 0 {
+		if row, ok := countResult.Rows[0].(*localqueryresult.RowResult); ok && len(row.Data) > 0 {
+			pagination.TotalRows, _ = strconv.ParseInt(fmt.Sprintf("%v", row.Data[0]), 10, 64)
+		}
+	}
+
+	queryResult, err := client.ExecuteSync(ctx, pagedSQL, r.Args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	renderStartTime := time.Now()
+	data, err := dashboardtypes.NewLeafData(queryResult)
+	if err != nil {
+		return nil, nil, err
+	}
+	data.Pagination = pagination
+
+	timing := &localqueryresult.TimingMetadata{Render: time.Since(renderStartTime)}
+	timing.Duration = timing.Render
+	timing.Add(countResult.Timing)
+	timing.Add(queryResult.Timing)
+	return data, timing, nil
+}
+
+// ExecutePage executes the query for the given page of the results of a paginated table,
+// and sends the updated data to the client
+//
+// NOTE: the data of the run is not updated - the run may be serialised concurrently (e.g. for the events of other
+// runs, or the snapshot of the execution), so the page is only sent in the event
+func (r *LeafRun) ExecutePage(ctx context.Context, page int, sortColumn, sortDirection string) error {
+	data, err := r.executePage(ctx, page, sortColumn, sortDirection)
+	if err != nil {
+		return err
+	}
+
+	// send the run with the data of the page to the client
+	e, err := dashboardevents.NewLeafNodeUpdate(r, r.executionTree.sessionId, r.executionTree.id)
+	if err != nil {
+		return err
+	}
+	e.LeafNode["data"] = data
+	r.executionTree.workspace.PublishDashboardEvent(ctx, e)
+	return nil
+}
+
+// executePage validates the page and sort options, and executes the query for the page
+func (r *LeafRun) executePage(ctx context.Context, page int, sortColumn, sortDirection string) (*dashboardtypes.LeafData, error) {
+	if r.Data == nil || r.Data.Pagination == nil {
+		return nil, fmt.Errorf("%s is not paginated", r.Name)
+	}
+	// the first page is always valid, even if there are no rows
+	if page < 0 || page > 0 && int64(page*r.Data.Pagination.PageSize) >= r.Data.Pagination.TotalRows {
+		return nil, fmt.Errorf("invalid page %d", page)
+	}
+
+	pagination := &dashboardtypes.LeafDataPagination{
+		Page:     page,
+		PageSize: r.Data.Pagination.PageSize,
+	}
+	if sortColumn != "" {
+		// only allow sorting by a column of the results
+		if !r.hasColumn(sortColumn) {
+			return nil, fmt.Errorf("%s has no column '%s'", r.Name, sortColumn)
+		}
+		switch sortDirection {
+		case sortAscending, sortDescending:
+		case "":
+			sortDirection = sortAscending
+		default:
+			return nil, fmt.Errorf("invalid sort direction '%s' - must be '%s' or '%s'", sortDirection, sortAscending, sortDescending)
+		}
+		pagination.SortColumn = sortColumn
+		pagination.SortDirection = sortDirection
+	}
+
+	data, _, err := r.executePagedQuery(ctx, pagination)
+	return data, err
+}
+
+func (r *LeafRun) hasColumn(name string) bool {
+	for _, c := range r.Data.Columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package dashboardexecute
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/db_client"
+)
+
+// newPaginatedTestRun returns a completed run of a table with a page size of 2, whose query returns 5 rows from a
+// sqlite database
+func newPaginatedTestRun(t *testing.T) *LeafRun {
+	path := filepath.Join(t.TempDir(), "items.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("create table items (name text, n integer)"); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"c", "a", "e", "b", "d"} {
+		if _, err := db.Exec("insert into items values (?, ?)", name, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	executionTree := &DashboardExecutionTree{
+		clientMap:        db_client.NewClientMap(),
+		defaultClientMap: db_client.NewClientMap(),
+		workspace:        dashboardworkspace.NewWorkspaceEvents(&workspace.Workspace{}),
+	}
+	t.Cleanup(func() { executionTree.clientMap.Close(context.Background()) })
+
+	r := &LeafRun{
+		Data: &dashboardtypes.LeafData{
+			Columns:    []*queryresult.ColumnDef{{Name: "name"}, {Name: "n"}},
+			Pagination: &dashboardtypes.LeafDataPagination{PageSize: 2, TotalRows: 5},
+		},
+		database: "sqlite://" + path,
+	}
+	r.Name = "local.table.items"
+	r.executionTree = executionTree
+	r.executeSQL = "select name, n from items order by n"
+	return r
+}
+
+func TestExecutePage(t *testing.T) {
+	tests := map[string]struct {
+		page          int
+		sortColumn    string
+		sortDirection string
+		wantNames     []string
+		wantErr       bool
+	}{
+		"first page":          {page: 0, wantNames: []string{"c", "a"}},
+		"last page":           {page: 2, wantNames: []string{"d"}},
+		"sorted":              {page: 0, sortColumn: "name", wantNames: []string{"a", "b"}},
+		"sorted descending":   {page: 1, sortColumn: "name", sortDirection: "desc", wantNames: []string{"c", "b"}},
+		"negative page":       {page: -1, wantErr: true},
+		"page out of range":   {page: 3, wantErr: true},
+		"unknown sort column": {page: 0, sortColumn: "size", wantErr: true},
+		"invalid direction":   {page: 0, sortColumn: "name", sortDirection: "up", wantErr: true},
+		"injected column":     {page: 0, sortColumn: "name; drop table items", wantErr: true},
+	}
+	r := newPaginatedTestRun(t)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := r.executePage(context.Background(), tc.page, tc.sortColumn, tc.sortDirection)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", data.Rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, row := range data.Rows {
+				names = append(names, fmt.Sprintf("%v", row["name"]))
+			}
+			if !reflect.DeepEqual(names, tc.wantNames) {
+				t.Errorf("got rows %v, expected %v", names, tc.wantNames)
+			}
+			if data.Pagination.Page != tc.page || data.Pagination.TotalRows != 5 {
+				t.Errorf("unexpected pagination %+v", data.Pagination)
+			}
+		})
+	}
+
+	// the data of the run is not changed by executing a page
+	if r.Data.Pagination.Page != 0 || r.Data.Rows != nil {
+		t.Errorf("expected the data of the run not to change, got %+v", r.Data)
+	}
+}
@@ -0,0 +1,354 @@
+package dashboardexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// columnFormat is the display hints of a table column, set using "powerpipe:" tag options on the table
+// (see ui/dashboard/src/components/dashboards/Table/columnFormat.tsx, which this must be kept consistent with):
+//
+//	"powerpipe:column.<name>.format"   = "number[:<decimals>]" | "currency:<code>" | "percent[:<decimals>]" |
+//	                                     "bytes" | "date" | "datetime" | "relative"
+//	"powerpipe:column.<name>.badge"    = "<value>=<color>,..." - display values as badges, "*" matches any value
+//	"powerpipe:column.<name>.truncate" = "<length>" - truncate values longer than length characters
+//
+// NOTE: exports are not localised - numbers and dates are formatted using the en-US conventions
+type columnFormat struct {
+	format   string
+	badge    map[string]string
+	truncate int
+}
+
+const columnOptionPrefix = "column."
+
+// getColumnFormat returns the display hints of the column, or nil if the column has none
+func getColumnFormat(options map[string]string, columnName string) *columnFormat {
+	prefix := columnOptionPrefix + columnName + "."
+	res := &columnFormat{}
+	found := false
+	for key, value := range options {
+		option, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		switch option {
+		case "format":
+			res.format = value
+			found = true
+		case "badge":
+			res.badge = map[string]string{}
+			for _, entry := range strings.Split(value, ",") {
+				parts := strings.Split(entry, "=")
+				if len(parts) == 2 {
+					res.badge[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+				}
+			}
+			found = true
+		case "truncate":
+			if length, err := strconv.Atoi(value); err == nil && length > 0 {
+				res.truncate = length
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return res
+}
+
+// display returns the value formatted and truncated using the column format - values which cannot be formatted
+// are displayed unchanged
+func (f *columnFormat) display(value any, now time.Time) string {
+	res := formatValue(value, f.format, now)
+	if runes := []rune(res); f.truncate > 0 && len(runes) > f.truncate {
+		res = string(runes[:f.truncate]) + "…"
+	}
+	return res
+}
+
+// badgeColor returns the badge color of the value, or an empty string if the value is not displayed as a badge
+func (f *columnFormat) badgeColor(value any) string {
+	if f.badge == nil || value == nil {
+		return ""
+	}
+	if color, ok := f.badge[valueString(value)]; ok {
+		return color
+	}
+	return f.badge["*"]
+}
+
+func formatValue(value any, format string, now time.Time) string {
+	if format == "" || value == nil {
+		return valueString(value)
+	}
+	formatType, arg, _ := strings.Cut(format, ":")
+	formatType = strings.TrimSpace(formatType)
+	arg = strings.TrimSpace(arg)
+	switch formatType {
+	case "number", "percent":
+		number, ok := numberValue(value)
+		if !ok {
+			break
+		}
+		decimals := -1
+		if arg != "" {
+			if d, err := strconv.Atoi(arg); err == nil && d >= 0 {
+				decimals = d
+			}
+		}
+		if formatType == "percent" {
+			if decimals < 0 {
+				decimals = 0
+			}
+			return formatNumber(number*100, decimals, decimals) + "%"
+		}
+		if decimals < 0 {
+			return formatNumber(number, 0, 3)
+		}
+		return formatNumber(number, decimals, decimals)
+	case "currency":
+		number, ok := numberValue(value)
+		if !ok {
+			break
+		}
+		code := strings.ToUpper(arg)
+		if code == "" {
+			code = "USD"
+		}
+		decimals := 2
+		if code == "JPY" || code == "KRW" {
+			decimals = 0
+		}
+		formatted := formatNumber(math.Abs(number), decimals, decimals)
+		sign := ""
+		if number < 0 {
+			sign = "-"
+		}
+		if symbol, ok := currencySymbols[code]; ok {
+			return sign + symbol + formatted
+		}
+		// the currency code is separated from the amount by a non-breaking space
+		return sign + code + "\u00a0" + formatted
+	case "bytes":
+		number, ok := numberValue(value)
+		if !ok {
+			break
+		}
+		return formatBytes(number)
+	case "date", "datetime", "relative":
+		t, ok := timeValue(value)
+		if !ok {
+			break
+		}
+		switch formatType {
+		case "date":
+			return t.Format("1/2/2006")
+		case "datetime":
+			return t.Format("1/2/2006, 3:04:05 PM")
+		}
+		return formatRelativeTime(t, now)
+	}
+	return valueString(value)
+}
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+}
+
+// formatNumber formats the number with thousands separators and between minDecimals and maxDecimals decimal places
+func formatNumber(number float64, minDecimals, maxDecimals int) string {
+	s := strconv.FormatFloat(number, 'f', maxDecimals, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	for len(fracPart) > minDecimals && strings.HasSuffix(fracPart, "0") {
+		fracPart = strings.TrimSuffix(fracPart, "0")
+	}
+	sign := ""
+	if strings.HasPrefix(intPart, "-") {
+		sign = "-"
+		intPart = intPart[1:]
+	}
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(c)
+	}
+	res := sign + b.String()
+	if fracPart != "" {
+		res += "." + fracPart
+	}
+	return res
+}
+
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+
+func formatBytes(bytes float64) string {
+	value := bytes
+	unit := 0
+	for math.Abs(value) >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	return formatNumber(value, 0, 1) + " " + byteUnits[unit]
+}
+
+var relativeTimeUnits = []struct {
+	name    string
+	seconds float64
+}{
+	{"year", 365 * 24 * 60 * 60},
+	{"month", 30 * 24 * 60 * 60},
+	{"week", 7 * 24 * 60 * 60},
+	{"day", 24 * 60 * 60},
+	{"hour", 60 * 60},
+	{"minute", 60},
+	{"second", 1},
+}
+
+func formatRelativeTime(t, now time.Time) string {
+	seconds := t.Sub(now).Seconds()
+	for _, unit := range relativeTimeUnits {
+		if math.Abs(seconds) < unit.seconds && unit.name != "second" {
+			continue
+		}
+		count := int(math.Round(seconds / unit.seconds))
+		switch count {
+		case 0:
+			return "now"
+		case 1:
+			if unit.name == "day" {
+				return "tomorrow"
+			}
+			return fmt.Sprintf("in 1 %s", unit.name)
+		case -1:
+			if unit.name == "day" {
+				return "yesterday"
+			}
+			return fmt.Sprintf("1 %s ago", unit.name)
+		}
+		if count > 0 {
+			return fmt.Sprintf("in %d %ss", count, unit.name)
+		}
+		return fmt.Sprintf("%d %ss ago", -count, unit.name)
+	}
+	return ""
+}
+
+func numberValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02"}
+
+func timeValue(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// valueString returns the value as displayed by the dashboard UI
+func valueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		res, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(res)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// hrefTemplate is a compiled column href template - the template is a string with jq expressions interpolated
+// using {{ }}, which are evaluated against the row, e.g. "https://example.com/{{ .id | @uri }}"
+type hrefTemplate struct {
+	code *gojq.Code
+}
+
+// newHrefTemplate compiles the href template in the same way as the dashboard UI (see ui/dashboard/src/utils/template.ts)
+func newHrefTemplate(template string) (*hrefTemplate, error) {
+	var parts []string
+	for template != "" {
+		start := strings.Index(template, "{{")
+		if start == -1 {
+			parts = append(parts, strconv.Quote(template))
+			break
+		}
+		end := strings.Index(template[start:], "}}")
+		if end == -1 {
+			parts = append(parts, strconv.Quote(template))
+			break
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(template[:start]))
+		}
+		// replace single quotes with jq-compatible double quotes
+		expression := strings.ReplaceAll(template[start+2:start+end], "'", `"`)
+		parts = append(parts, "("+expression+")")
+		template = template[start+end+2:]
+	}
+	query, err := gojq.Parse(fmt.Sprintf(`[%s] | join("")`, strings.Join(parts, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return &hrefTemplate{code: code}, nil
+}
+
+// render renders the href for the row, returning an empty string if the template cannot be evaluated
+func (h *hrefTemplate) render(row map[string]any) string {
+	iter := h.code.Run(row)
+	v, ok := iter.Next()
+	if !ok {
+		return ""
+	}
+	if _, isErr := v.(error); isErr {
+		return ""
+	}
+	res, _ := v.(string)
+	return res
+}
@@ -0,0 +1,138 @@
+package dashboardexport
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetColumnFormat(t *testing.T) {
+	tests := map[string]struct {
+		options  map[string]string
+		expected *columnFormat
+	}{
+		"no options": {},
+		"other column": {
+			options: map[string]string{"column.other.format": "bytes"},
+		},
+		"all options": {
+			options: map[string]string{
+				"column.status.format":   "number:2",
+				"column.status.badge":    "ok=ok, failed = alert,bad",
+				"column.status.truncate": "10",
+			},
+			expected: &columnFormat{
+				format:   "number:2",
+				badge:    map[string]string{"ok": "ok", "failed": "alert"},
+				truncate: 10,
+			},
+		},
+		"invalid truncate": {
+			options: map[string]string{"column.status.truncate": "-1"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := getColumnFormat(tc.options, "status")
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %+v, expected %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	now := time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value    any
+		format   string
+		expected string
+	}{
+		"no format":               {value: 1234.5, expected: "1234.5"},
+		"null":                    {value: nil, format: "number", expected: "null"},
+		"number":                  {value: 1234567.891, format: "number", expected: "1,234,567.891"},
+		"number decimals":         {value: 1234.5, format: "number:2", expected: "1,234.50"},
+		"negative number":         {value: -1234.0, format: "number:0", expected: "-1,234"},
+		"number string":           {value: "42.5", format: "number:1", expected: "42.5"},
+		"not a number":            {value: "n/a", format: "number", expected: "n/a"},
+		"percent":                 {value: 0.256, format: "percent", expected: "26%"},
+		"percent decimals":        {value: 0.256, format: "percent:1", expected: "25.6%"},
+		"currency":                {value: 1234.5, format: "currency:USD", expected: "$1,234.50"},
+		"negative currency":       {value: -5.0, format: "currency:eur", expected: "-€5.00"},
+		"currency without symbol": {value: 10.0, format: "currency:CHF", expected: "CHF\u00a010.00"},
+		"currency default":        {value: 1.0, format: "currency", expected: "$1.00"},
+		"bytes":                   {value: 1536.0, format: "bytes", expected: "1.5 KB"},
+		"small bytes":             {value: 100.0, format: "bytes", expected: "100 B"},
+		"date":                    {value: "2024-03-05T10:30:00Z", format: "date", expected: "3/5/2024"},
+		"datetime":                {value: "2024-03-05T15:30:05Z", format: "datetime", expected: "3/5/2024, 3:30:05 PM"},
+		"relative past":           {value: "2024-04-07T12:00:00Z", format: "relative", expected: "3 days ago"},
+		"relative yesterday":      {value: "2024-04-09T11:00:00Z", format: "relative", expected: "yesterday"},
+		"relative future":         {value: "2024-04-10T14:00:00Z", format: "relative", expected: "in 2 hours"},
+		"relative now":            {value: "2024-04-10T12:00:00Z", format: "relative", expected: "now"},
+		"not a date":              {value: "soon", format: "date", expected: "soon"},
+		"unknown format":          {value: "x", format: "unknown", expected: "x"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := formatValue(tc.value, tc.format, now); got != tc.expected {
+				t.Errorf("got %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestColumnFormatDisplay(t *testing.T) {
+	f := &columnFormat{
+		format:   "number",
+		badge:    map[string]string{"1000": "ok", "*": "#ff0000"},
+		truncate: 3,
+	}
+	if got := f.display(1000.0, time.Now()); got != "1,0…" {
+		t.Errorf("got %q, expected %q", got, "1,0…")
+	}
+	if got := f.badgeColor(1000.0); got != "ok" {
+		t.Errorf("got %q, expected %q", got, "ok")
+	}
+	if got := f.badgeColor(5.0); got != "#ff0000" {
+		t.Errorf("got %q, expected %q", got, "#ff0000")
+	}
+	if got := f.badgeColor(nil); got != "" {
+		t.Errorf("expected no badge for null, got %q", got)
+	}
+}
+
+func TestHrefTemplate(t *testing.T) {
+	row := map[string]any{"id": "a/b", "count": 3.0, "empty": nil}
+
+	tests := map[string]struct {
+		template string
+		expected string
+		wantErr  bool
+	}{
+		"plain":              {template: "https://example.com", expected: "https://example.com"},
+		"field":              {template: "https://example.com/{{ .id | @uri }}", expected: "https://example.com/a%2Fb"},
+		"number":             {template: "/items/{{.count}}?x=1", expected: "/items/3?x=1"},
+		"null":               {template: "/items/{{ .empty }}", expected: "/items/"},
+		"single quotes":      {template: "{{ .id + '!' }}", expected: "a/b!"},
+		"multiple":           {template: "{{ .id }}/{{ .count }}", expected: "a/b/3"},
+		"invalid expression": {template: "{{ .id | }}", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, err := newHrefTemplate(tc.template)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.render(row); got != tc.expected {
+				t.Errorf("got %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package dashboardexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// PngExporter exports a dashboard snapshot as a PNG image
+type PngExporter struct {
+	export.ExporterBase
+	renderer *Renderer
+}
+
+func NewPngExporter(renderer *Renderer) *PngExporter {
+	return &PngExporter{renderer: renderer}
+}
+
+func (e *PngExporter) Export(ctx context.Context, input export.ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("PngExporter input must be a SteampipeSnapshot")
+	}
+	res, err := e.renderer.RenderPng(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+	return export.Write(filePath, bytes.NewReader(res))
+}
+
+func (e *PngExporter) FileExtension() string {
+	return localconstants.PngExtension
+}
+
+func (e *PngExporter) Name() string {
+	return localconstants.OutputFormatPng
+}
+
+// PdfExporter exports a dashboard snapshot as a PDF document
+type PdfExporter struct {
+	export.ExporterBase
+	renderer *Renderer
+}
+
+func NewPdfExporter(renderer *Renderer) *PdfExporter {
+	return &PdfExporter{renderer: renderer}
+}
+
+func (e *PdfExporter) Export(ctx context.Context, input export.ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("PdfExporter input must be a SteampipeSnapshot")
+	}
+	res, err := e.renderer.RenderPdf(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+	return export.Write(filePath, bytes.NewReader(res))
+}
+
+func (e *PdfExporter) FileExtension() string {
+	return localconstants.PdfExtension
+}
+
+func (e *PdfExporter) Name() string {
+	return localconstants.OutputFormatPdf
+}
@@ -0,0 +1,88 @@
+package dashboardexport
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+//go:embed templates/dashboard.html.tmpl
+var htmlTemplateSource string
+
+var htmlTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"visible":    func(p *reportPanel) bool { return p.visible() },
+	"table":      func(p *reportPanel, now time.Time) *reportTable { return p.table(now) },
+	"card":       func(p *reportPanel) []string { label, value := p.cardValue(); return []string{label, value} },
+	"property":   func(p *reportPanel, name string) string { s, _ := p.Properties[name].(string); return s },
+	"badgeClass": badgeClass,
+	"heading":    func(level int) int { return min(level, 6) },
+	"inc":        func(level int) int { return level + 1 },
+	"panel": func(p *reportPanel, level int, now time.Time) map[string]any {
+		return map[string]any{"Panel": p, "Level": level, "Now": now}
+	},
+}).Parse(htmlTemplateSource))
+
+// HtmlExporter exports a dashboard snapshot as a self-contained HTML document
+// Tables are rendered using their column display hints. Other panels with data (e.g. charts) are rendered as tables
+// of their data.
+type HtmlExporter struct {
+	export.ExporterBase
+}
+
+func (e *HtmlExporter) Export(_ context.Context, input export.ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("HtmlExporter input must be a SteampipeSnapshot")
+	}
+	res, err := renderHtml(snapshot, time.Now())
+	if err != nil {
+		return err
+	}
+	return export.Write(filePath, bytes.NewReader(res))
+}
+
+func (e *HtmlExporter) FileExtension() string {
+	return localconstants.HtmlExtension
+}
+
+func (e *HtmlExporter) Name() string {
+	return constants.OutputFormatHTML
+}
+
+// renderHtml renders the snapshot as HTML - relative dates are formatted relative to now
+func renderHtml(snapshot *steampipeconfig.SteampipeSnapshot, now time.Time) ([]byte, error) {
+	r, err := newReport(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, map[string]any{"Report": r, "Now": now}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// the badge colors which are theme statuses, as supported by the dashboard UI
+var themeBadgeColors = map[string]bool{
+	"alert":    true,
+	"info":     true,
+	"ok":       true,
+	"severity": true,
+	"skip":     true,
+}
+
+// badgeClass returns the CSS class of the badge color, or an empty string if the color is a CSS color
+func badgeClass(color string) string {
+	if themeBadgeColors[color] {
+		return "badge-" + color
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package dashboardexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// MarkdownExporter exports a dashboard snapshot as a markdown document
+// Tables are rendered using their column display hints - badges are rendered as code spans, as markdown has no colors.
+// Other panels with data (e.g. charts) are rendered as tables of their data.
+type MarkdownExporter struct {
+	export.ExporterBase
+}
+
+func (e *MarkdownExporter) Export(_ context.Context, input export.ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("MarkdownExporter input must be a SteampipeSnapshot")
+	}
+	res, err := renderMarkdown(snapshot, time.Now())
+	if err != nil {
+		return err
+	}
+	return export.Write(filePath, bytes.NewReader(res))
+}
+
+func (e *MarkdownExporter) FileExtension() string {
+	return localconstants.MarkdownExtension
+}
+
+func (e *MarkdownExporter) Name() string {
+	return constants.OutputFormatMD
+}
+
+// renderMarkdown renders the snapshot as markdown - relative dates are formatted relative to now
+func renderMarkdown(snapshot *steampipeconfig.SteampipeSnapshot, now time.Time) ([]byte, error) {
+	r, err := newReport(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	if !r.StartTime.IsZero() {
+		fmt.Fprintf(&b, "_Snapshot taken %s_\n\n", r.StartTime.UTC().Format(time.RFC3339))
+	}
+	if r.Root != nil {
+		for _, child := range r.Root.Children {
+			writeMarkdownPanel(&b, child, 2, now)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+func writeMarkdownPanel(b *strings.Builder, p *reportPanel, level int, now time.Time) {
+	if !p.visible() {
+		return
+	}
+	if p.Title != "" && p.PanelType != "card" {
+		fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", min(level, 6)), p.Title)
+	}
+	if p.Error != "" {
+		fmt.Fprintf(b, "> Error: %s\n\n", p.Error)
+		return
+	}
+
+	switch p.PanelType {
+	case "text":
+		if value, ok := p.Properties["value"].(string); ok && value != "" {
+			b.WriteString(strings.TrimSpace(value))
+			b.WriteString("\n\n")
+		}
+	case "card":
+		label, value := p.cardValue()
+		fmt.Fprintf(b, "**%s**: %s\n\n", escapeMarkdown(label), escapeMarkdown(value))
+	case "image":
+		src, _ := p.Properties["src"].(string)
+		alt, _ := p.Properties["alt"].(string)
+		if src != "" {
+			fmt.Fprintf(b, "![%s](%s)\n\n", escapeMarkdown(alt), src)
+		}
+	default:
+		if t := p.table(now); t != nil && len(t.Columns) > 0 {
+			writeMarkdownTable(b, t)
+		}
+	}
+	for _, child := range p.Children {
+		writeMarkdownPanel(b, child, level+1, now)
+	}
+}
+
+func writeMarkdownTable(b *strings.Builder, t *reportTable) {
+	header := make([]string, len(t.Columns))
+	separator := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = escapeMarkdown(col)
+		separator[i] = "---"
+	}
+	fmt.Fprintf(b, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(b, "| %s |\n", strings.Join(separator, " | "))
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = markdownCell(cell)
+		}
+		fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	b.WriteString("\n")
+}
+
+func markdownCell(cell reportCell) string {
+	text := escapeMarkdown(cell.Text)
+	if cell.Badge != "" {
+		text = "`" + strings.ReplaceAll(cell.Text, "`", "'") + "`"
+	}
+	if cell.Href != "" {
+		text = fmt.Sprintf("[%s](%s)", text, strings.ReplaceAll(cell.Href, " ", "%20"))
+	}
+	return text
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// escapeMarkdown escapes the text so it is displayed literally, including in a table cell
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,182 @@
+package dashboardexport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/dashboardassets"
+	"github.com/turbot/powerpipe/internal/dashboardserver"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"github.com/turbot/powerpipe/internal/service/api"
+	localsnapshot "github.com/turbot/powerpipe/internal/snapshot"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"gopkg.in/olahol/melody.v1"
+)
+
+const (
+	// renderTimeout is the maximum time to wait for a dashboard to render in the browser
+	renderTimeout = 2 * time.Minute
+	// renderWidth is the width of the browser window used to render dashboards
+	renderWidth = 1440
+	// renderCompleteSelector is the element added by the dashboard UI once a snapshot has rendered
+	renderCompleteSelector = "#snapshot-complete"
+)
+
+// Renderer renders dashboard snapshots to images and PDFs using a headless browser.
+// It serves the snapshot from an embedded dashboard server, so the rendered output is
+// identical to viewing the snapshot in the dashboard UI.
+type Renderer struct {
+	workspace *dashboardworkspace.WorkspaceEvents
+}
+
+func NewRenderer(w *dashboardworkspace.WorkspaceEvents) *Renderer {
+	return &Renderer{workspace: w}
+}
+
+// RenderPng renders the snapshot as a full page PNG screenshot
+func (r *Renderer) RenderPng(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot) ([]byte, error) {
+	var res []byte
+	err := r.render(ctx, snapshot, chromedp.FullScreenshot(&res, 100))
+	return res, err
+}
+
+// RenderPdf renders the snapshot as a PDF document
+func (r *Renderer) RenderPdf(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot) ([]byte, error) {
+	var res []byte
+	err := r.render(ctx, snapshot, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		res, _, err = page.PrintToPDF().WithPrintBackground(true).Do(ctx)
+		return err
+	}))
+	return res, err
+}
+
+func (r *Renderer) render(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot, capture chromedp.Action) error {
+	// the dashboard UI can only display snapshots from the workspace, so write the snapshot to a temp file
+	// and add it to the workspace snapshots
+	snapshotName, cleanup, err := r.addSnapshotToWorkspace(snapshot)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	serverUrl, stopServer, err := r.startServer(ctx)
+	if err != nil {
+		return err
+	}
+	defer stopServer()
+
+	allocatorOpts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.WindowSize(renderWidth, 1024))
+	if browserPath, ok := os.LookupEnv(localconstants.EnvBrowserPath); ok {
+		allocatorOpts = append(allocatorOpts, chromedp.ExecPath(browserPath))
+	}
+	allocatorCtx, cancelAllocator := chromedp.NewExecAllocator(ctx, allocatorOpts...)
+	defer cancelAllocator()
+
+	browserCtx, cancelBrowser := chromedp.NewContext(allocatorCtx)
+	defer cancelBrowser()
+
+	browserCtx, cancelTimeout := context.WithTimeout(browserCtx, renderTimeout)
+	defer cancelTimeout()
+
+	dashboardUrl := fmt.Sprintf("%s/snapshot/%s", serverUrl, url.PathEscape(snapshotName))
+	slog.Debug("rendering dashboard", "url", dashboardUrl)
+
+	err = chromedp.Run(browserCtx,
+		chromedp.Navigate(dashboardUrl),
+		chromedp.WaitReady(renderCompleteSelector, chromedp.ByQuery),
+		capture,
+	)
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to render dashboard - is Chrome or Chromium installed? (set %s to specify the browser path)", localconstants.EnvBrowserPath)
+	}
+	return nil
+}
+
+func (r *Renderer) addSnapshotToWorkspace(snapshot *steampipeconfig.SteampipeSnapshot) (string, func(), error) {
+	// render the snapshot as exported, with the values of sensitive columns redacted
+	snapshotBytes, err := localsnapshot.AsJson(snapshot, nil, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "powerpipe-render")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tempDir) }
+
+	snapshotPath := filepath.Join(tempDir, snapshot.FileNameRoot+localconstants.SnapshotExtension)
+	if err := os.WriteFile(snapshotPath, snapshotBytes, 0600); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	resourceMaps := r.workspace.GetResourceMaps()
+	resourceMaps.AddSnapshots([]string{snapshotPath})
+	snapshotName := fmt.Sprintf("snapshot.%s", utils.FilenameNoExtension(snapshotPath))
+
+	return snapshotName, func() {
+		delete(resourceMaps.Snapshots, snapshotName)
+		cleanup()
+	}, nil
+}
+
+// startServer starts a dashboard server on a free local port and returns its url
+func (r *Renderer) startServer(ctx context.Context) (string, func(), error) {
+	// do not write server status messages to the console
+	ctx = dashboardserver.DisableOutput(ctx)
+
+	if err := dashboardassets.Ensure(ctx); err != nil {
+		return "", nil, err
+	}
+
+	port, err := getFreePort()
+	if err != nil {
+		return "", nil, err
+	}
+
+	webSocket := melody.New()
+	// the server only displays the snapshot, so does not need to watch the workspace or handle dashboard events
+	dashboardServer, err := dashboardserver.NewSnapshotServer(r.workspace, webSocket)
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiService, err := api.NewAPIService(ctx, api.WithWebSocket(webSocket), api.WithWorkspace(r.workspace.Workspace), api.WithHttpPort(port), api.WithBranding(dashboardServer.Branding()))
+	if err != nil {
+		return "", nil, err
+	}
+	dashboardServer.InitAsync(ctx)
+	if err := apiService.Start(); err != nil {
+		return "", nil, err
+	}
+
+	stop := func() {
+		dashboardServer.Shutdown(ctx)
+		if err := apiService.Stop(ctx); err != nil {
+			slog.Warn("failed to stop dashboard render server", "error", err)
+		}
+	}
+	return fmt.Sprintf("http://localhost:%d", port), stop, nil
+}
+
+func getFreePort() (dashboardserver.ListenPort, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return dashboardserver.ListenPort(listener.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,187 @@
+package dashboardexport
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+)
+
+// reportPanel is a panel of a dashboard snapshot, as used by the HTML and markdown exports
+// The panels are read from the JSON of the snapshot, so the exports work for snapshots loaded from file as well as
+// for the snapshots of executed dashboards.
+type reportPanel struct {
+	Name        string            `json:"name"`
+	Title       string            `json:"title"`
+	PanelType   string            `json:"panel_type"`
+	DisplayType string            `json:"display_type"`
+	Display     string            `json:"display"`
+	Hidden      bool              `json:"hidden"`
+	Options     map[string]string `json:"options"`
+	Properties  map[string]any    `json:"properties"`
+	Data        *reportData       `json:"data"`
+	Error       string            `json:"error"`
+
+	Children []*reportPanel `json:"-"`
+}
+
+type reportData struct {
+	Columns []reportColumn   `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+}
+
+type reportColumn struct {
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name"`
+	DataType     string `json:"data_type"`
+}
+
+// report is a dashboard snapshot, as used by the HTML and markdown exports
+type report struct {
+	Title     string
+	StartTime time.Time
+	Root      *reportPanel
+}
+
+func newReport(snapshot *steampipeconfig.SteampipeSnapshot) (*report, error) {
+	panels := make(map[string]*reportPanel, len(snapshot.Panels))
+	for name, panel := range snapshot.Panels {
+		j, err := json.Marshal(panel)
+		if err != nil {
+			return nil, err
+		}
+		var p reportPanel
+		if err := json.Unmarshal(j, &p); err != nil {
+			return nil, err
+		}
+		panels[name] = &p
+	}
+
+	res := &report{
+		Title:     snapshot.Title,
+		StartTime: snapshot.StartTime,
+	}
+	if snapshot.Layout != nil {
+		res.Root = buildReportTree(snapshot.Layout, panels)
+	}
+	if res.Title == "" && res.Root != nil {
+		res.Title = res.Root.Title
+	}
+	return res, nil
+}
+
+// buildReportTree returns the panel for the layout node, with its children populated
+func buildReportTree(node *steampipeconfig.SnapshotTreeNode, panels map[string]*reportPanel) *reportPanel {
+	panel, ok := panels[node.Name]
+	if !ok {
+		panel = &reportPanel{Name: node.Name, PanelType: node.NodeType}
+	}
+	for _, child := range node.Children {
+		panel.Children = append(panel.Children, buildReportTree(child, panels))
+	}
+	return panel
+}
+
+// visible returns whether the panel is displayed
+func (p *reportPanel) visible() bool {
+	return !p.Hidden && p.Display != "none"
+}
+
+// reportCell is a formatted table cell
+type reportCell struct {
+	Text  string
+	Href  string
+	Badge string
+	Wrap  bool
+}
+
+// reportTable is the formatted data of a panel
+type reportTable struct {
+	Columns []string
+	Rows    [][]reportCell
+}
+
+// table returns the formatted data of the panel, applying the display hints of the table columns: the display, wrap
+// and href properties of the column blocks, and the format, badge and truncate tag options
+func (p *reportPanel) table(now time.Time) *reportTable {
+	if p.Data == nil {
+		return nil
+	}
+	type columnInfo struct {
+		name   string
+		format *columnFormat
+		href   *hrefTemplate
+		wrap   bool
+	}
+	columnProperties, _ := p.Properties["columns"].(map[string]any)
+
+	res := &reportTable{}
+	var columns []columnInfo
+	for _, col := range p.Data.Columns {
+		originalName := col.OriginalName
+		if originalName == "" {
+			originalName = col.Name
+		}
+		info := columnInfo{name: col.Name}
+		if p.PanelType == "table" {
+			props, _ := columnProperties[originalName].(map[string]any)
+			if display, _ := props["display"].(string); display == "none" {
+				continue
+			}
+			if wrap, _ := props["wrap"].(string); wrap == "all" {
+				info.wrap = true
+			}
+			if href, _ := props["href"].(string); href != "" {
+				// NOTE: invalid templates are ignored, as they are by the dashboard UI
+				info.href, _ = newHrefTemplate(href)
+			}
+			info.format = getColumnFormat(p.Options, originalName)
+		}
+		columns = append(columns, info)
+		res.Columns = append(res.Columns, originalName)
+	}
+
+	for _, row := range p.Data.Rows {
+		cells := make([]reportCell, len(columns))
+		for i, col := range columns {
+			value := row[col.name]
+			cell := reportCell{Text: valueString(value), Wrap: col.wrap}
+			if col.format != nil {
+				cell.Text = col.format.display(value, now)
+				cell.Badge = col.format.badgeColor(value)
+			}
+			if col.href != nil {
+				cell.Href = col.href.render(row)
+			}
+			cells[i] = cell
+		}
+		res.Rows = append(res.Rows, cells)
+	}
+	return res
+}
+
+// cardValue returns the label and value of a card panel
+func (p *reportPanel) cardValue() (string, string) {
+	label := p.Title
+	if l, ok := p.Properties["label"].(string); ok && l != "" {
+		label = l
+	}
+	value, hasValue := p.Properties["value"]
+	if p.Data != nil && len(p.Data.Columns) > 0 && len(p.Data.Rows) > 0 {
+		row := p.Data.Rows[0]
+		// a card query either returns the value in its first column, or label and value columns
+		if v, ok := row["value"]; ok {
+			value, hasValue = v, true
+			if l, ok := row["label"].(string); ok {
+				label = l
+			}
+		} else {
+			value, hasValue = row[p.Data.Columns[0].Name], true
+			label = p.Data.Columns[0].Name
+		}
+	}
+	if !hasValue {
+		return label, ""
+	}
+	return label, valueString(value)
+}
@@ -0,0 +1,129 @@
+package dashboardexport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+)
+
+// testSnapshotPanel is a snapshot panel as read from a snapshot file
+type testSnapshotPanel map[string]any
+
+func (testSnapshotPanel) IsSnapshotPanel() {}
+
+func newTestSnapshot() *steampipeconfig.SteampipeSnapshot {
+	return &steampipeconfig.SteampipeSnapshot{
+		Title: "Costs",
+		Panels: map[string]steampipeconfig.SnapshotPanel{
+			"local.dashboard.costs": testSnapshotPanel{
+				"name": "local.dashboard.costs", "panel_type": "dashboard", "title": "Costs",
+			},
+			"local.card.total": testSnapshotPanel{
+				"name": "local.card.total", "panel_type": "card",
+				"data": map[string]any{
+					"columns": []any{map[string]any{"name": "Total", "data_type": "INT8"}},
+					"rows":    []any{map[string]any{"Total": 42}},
+				},
+			},
+			"local.text.intro": testSnapshotPanel{
+				"name": "local.text.intro", "panel_type": "text",
+				"properties": map[string]any{"value": "Monthly <costs>"},
+			},
+			"local.table.accounts": testSnapshotPanel{
+				"name": "local.table.accounts", "panel_type": "table", "title": "Accounts",
+				"options": map[string]any{
+					"column.cost.format":   "currency:USD",
+					"column.state.badge":   "active=ok,*=#aaaaaa",
+					"column.note.truncate": "5",
+				},
+				"properties": map[string]any{
+					"columns": map[string]any{
+						"id":     map[string]any{"href": "https://example.com/{{ .id | @uri }}"},
+						"secret": map[string]any{"display": "none"},
+						"note":   map[string]any{"wrap": "all"},
+					},
+				},
+				"data": map[string]any{
+					"columns": []any{
+						map[string]any{"name": "id", "data_type": "TEXT"},
+						map[string]any{"name": "cost", "data_type": "NUMERIC"},
+						map[string]any{"name": "state", "data_type": "TEXT"},
+						map[string]any{"name": "secret", "data_type": "TEXT"},
+						map[string]any{"name": "note", "data_type": "TEXT"},
+					},
+					"rows": []any{
+						map[string]any{"id": "a|1", "cost": 1234.5, "state": "active", "secret": "s", "note": "long note"},
+						map[string]any{"id": "b", "cost": nil, "state": "closed", "secret": "s", "note": nil},
+					},
+				},
+			},
+			"local.table.hidden": testSnapshotPanel{
+				"name": "local.table.hidden", "panel_type": "table", "title": "Hidden", "hidden": true,
+			},
+		},
+		Layout: &steampipeconfig.SnapshotTreeNode{
+			Name:     "local.dashboard.costs",
+			NodeType: "dashboard",
+			Children: []*steampipeconfig.SnapshotTreeNode{
+				{Name: "local.text.intro", NodeType: "text"},
+				{Name: "local.card.total", NodeType: "card"},
+				{Name: "local.table.accounts", NodeType: "table"},
+				{Name: "local.table.hidden", NodeType: "table"},
+			},
+		},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	got, err := renderMarkdown(newTestSnapshot(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Costs
+
+Monthly <costs>
+
+**Total**: 42
+
+## Accounts
+
+| id | cost | state | note |
+| --- | --- | --- | --- |
+| [a\|1](https://example.com/a%7C1) | $1,234.50 | ` + "`active`" + ` | long … |
+| [b](https://example.com/b) | null | ` + "`closed`" + ` | null |
+
+`
+	if string(got) != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestRenderHtml(t *testing.T) {
+	got, err := renderHtml(newTestSnapshot(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(got)
+	for _, expected := range []string{
+		"<title>Costs</title>",
+		`<div class="text">Monthly &lt;costs&gt;</div>`,
+		`<div class="card-label">Total</div><div class="card-value">42</div>`,
+		"<th>id</th><th>cost</th><th>state</th><th>note</th></tr>",
+		`<td><a href="https://example.com/a%7C1">a|1</a></td>`,
+		"<td>$1,234.50</td>",
+		`<span class="badge badge-ok">active</span>`,
+		`<span class="badge" style="background-color: #aaaaaa">closed</span>`,
+		`<td class="wrap">long …</td>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected the HTML to contain %q, got:\n%s", expected, html)
+		}
+	}
+	for _, unexpected := range []string{"<th>secret</th>", "Hidden"} {
+		if strings.Contains(html, unexpected) {
+			t.Errorf("expected the HTML not to contain %q", unexpected)
+		}
+	}
+}
@@ -30,6 +30,11 @@ func startAPIAsync(ctx context.Context, webSocket *melody.Melody) chan struct{}
 
 		router.Use(static.Serve("/", static.LocalFile(assetsDirectory, true)))
 
+		// serve the mod assets directory, if there is one
+		if modAssetsDir, ok := ModAssetsDir(); ok {
+			router.Static(ModAssetsPath, modAssetsDir)
+		}
+
 		router.GET("/ws", func(c *gin.Context) {
 			webSocket.HandleRequest(c.Writer, c.Request) //nolint:errcheck // TODO: fix this
 		})
@@ -0,0 +1,34 @@
+package dashboardserver
+
+import (
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/audit"
+	"gopkg.in/olahol/melody.v1"
+)
+
+// recordAuditEvent records a dashboard server action in the audit log (if one is configured)
+func (s *Server) recordAuditEvent(session *melody.Session, sessionId, action, resource string, inputs map[string]any) {
+	if s.auditLog == nil {
+		return
+	}
+	s.auditLog.Record(audit.NewEvent(action, resource, sessionId, inputs, session.Request))
+}
+
+// auditActionForResource returns the audit action for selecting the given resource
+func auditActionForResource(resource modconfig.ModTreeItem) string {
+	if _, ok := resource.(*modconfig.Benchmark); ok {
+		return audit.ActionBenchmarkRun
+	}
+	return audit.ActionDashboardViewed
+}
+
+// getDashboardForSession returns the name of the dashboard the session is viewing
+func (s *Server) getDashboardForSession(sessionId string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if clientInfo, ok := s.dashboardClients[sessionId]; ok && clientInfo.Dashboard != nil {
+		return *clientInfo.Dashboard
+	}
+	return ""
+}
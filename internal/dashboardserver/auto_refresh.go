@@ -0,0 +1,136 @@
+package dashboardserver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/tagoptions"
+)
+
+// minRefreshInterval is the shortest refresh interval which may be set for a dashboard
+// - this avoids a dashboard continually re-executing its queries
+const minRefreshInterval = 5 * time.Second
+
+// getRefreshInterval returns the interval at which the dashboard should be re-executed
+// this is set using the "powerpipe:refresh_interval" tag option, e.g. "30s" or "5m"
+// if the dashboard does not auto-refresh, return 0
+func getRefreshInterval(resource modconfig.ModTreeItem) time.Duration {
+	value, ok := tagoptions.Get(resource.GetTags(), "refresh_interval")
+	if !ok || value == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		slog.Warn("ignoring invalid refresh_interval", "dashboard", resource.Name(), "refresh_interval", value)
+		return 0
+	}
+	if interval < minRefreshInterval {
+		slog.Warn("refresh_interval is less than the minimum - using the minimum", "dashboard", resource.Name(), "refresh_interval", value, "minimum", minRefreshInterval)
+		return minRefreshInterval
+	}
+	return interval
+}
+
+// scheduleRefresh schedules the re-execution of the dashboard for the session, if the dashboard has a refresh interval
+// and auto-refresh has not been disabled by the client
+func (s *Server) scheduleRefresh(ctx context.Context, sessionId string, resource modconfig.ModTreeItem) {
+	interval := getRefreshInterval(resource)
+	if interval == 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clientInfo, ok := s.dashboardClients[sessionId]
+	if !ok || clientInfo.autoRefreshDisabled || clientInfo.Dashboard == nil || *clientInfo.Dashboard != resource.Name() {
+		return
+	}
+	clientInfo.stopRefresh()
+	// the timer refreshes the dashboard by name, so it executes the dashboard of the current workspace - the
+	// workspace may have been reloaded (after a mod file change) since the refresh was scheduled
+	dashboardName := resource.Name()
+	clientInfo.refreshTimer = time.AfterFunc(interval, func() {
+		s.refreshDashboard(ctx, sessionId, dashboardName)
+	})
+}
+
+// refreshDashboard re-executes the dashboard for the session using the current input values
+func (s *Server) refreshDashboard(ctx context.Context, sessionId string, dashboardName string) {
+	if s.draining.Load() {
+		return
+	}
+
+	s.mutex.Lock()
+	clientInfo, ok := s.dashboardClients[sessionId]
+	// if the client has selected a different dashboard, there is nothing to do
+	if !ok || clientInfo.autoRefreshDisabled || clientInfo.Dashboard == nil || *clientInfo.Dashboard != dashboardName {
+		s.mutex.Unlock()
+		return
+	}
+	suspended := clientInfo.Session == nil
+	inputs := clientInfo.DashboardInputs
+	opts := clientInfo.connectOpts
+	s.mutex.Unlock()
+
+	// look up the dashboard in the current workspace - if it has been removed, there is nothing to do
+	resource := s.getResource(dashboardName)
+	if resource == nil {
+		return
+	}
+
+	// do not refresh while the client is disconnected - try again after the next interval
+	if suspended {
+		s.scheduleRefresh(ctx, sessionId, resource)
+		return
+	}
+
+	slog.Debug("refreshing dashboard", "session", sessionId, "dashboard", dashboardName)
+	_ = dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, resource, inputs, s.workspace, opts...)
+}
+
+// setAutoRefresh enables or disables auto-refresh for the session
+func (s *Server) setAutoRefresh(ctx context.Context, sessionId string, enabled bool) {
+	s.mutex.Lock()
+	clientInfo, ok := s.dashboardClients[sessionId]
+	if !ok {
+		s.mutex.Unlock()
+		return
+	}
+	clientInfo.autoRefreshDisabled = !enabled
+	clientInfo.stopRefresh()
+	var dashboardName string
+	if clientInfo.Dashboard != nil {
+		dashboardName = *clientInfo.Dashboard
+	}
+	s.mutex.Unlock()
+
+	// when auto-refresh is enabled, schedule the next refresh
+	if enabled && dashboardName != "" {
+		if resource := s.getResource(dashboardName); resource != nil {
+			s.scheduleRefresh(ctx, sessionId, resource)
+		}
+	}
+}
+
+// stopRefreshForSession stops any scheduled refresh for the session
+func (s *Server) stopRefreshForSession(sessionId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if clientInfo, ok := s.dashboardClients[sessionId]; ok {
+		clientInfo.stopRefresh()
+	}
+}
+
+// stopRefresh stops any scheduled refresh for the client
+// NOTE: the server mutex must be held when calling this
+func (c *DashboardClientInfo) stopRefresh() {
+	if c.refreshTimer != nil {
+		c.refreshTimer.Stop()
+		c.refreshTimer = nil
+	}
+}
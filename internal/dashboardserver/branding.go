@@ -0,0 +1,90 @@
+package dashboardserver
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// BrandingLogoPath is the url path the branding logo is served from
+const BrandingLogoPath = "/branding/logo"
+
+// Branding is the custom branding applied to the dashboard UI
+//
+// It is loaded from a config file containing a branding block, for example:
+//
+//	branding {
+//	  product_name = "Acme Dashboards"
+//	  logo         = "./acme.svg"
+//	  footer       = "Internal use only"
+//	  colors = {
+//	    dashboard-panel = "#f0f0f0"
+//	  }
+//	}
+type Branding struct {
+	ProductName string `hcl:"product_name,optional" json:"product_name,omitempty"`
+	// the path to the logo file - relative paths are resolved from the branding file location
+	LogoPath string `hcl:"logo,optional" json:"-"`
+	// color overrides for the UI theme, keyed by the theme color name
+	Colors map[string]string `hcl:"colors,optional" json:"colors,omitempty"`
+	Footer string            `hcl:"footer,optional" json:"footer,omitempty"`
+	// the url the logo is served from (set if there is a logo)
+	LogoUrl string `json:"logo_url,omitempty"`
+}
+
+type brandingConfig struct {
+	Branding *Branding `hcl:"branding,block"`
+	Remain   hcl.Body  `hcl:",remain"`
+}
+
+// LoadBranding loads the branding config from the file specified by the branding arg
+// if no branding file is configured, return nil
+func LoadBranding() (*Branding, error) {
+	brandingPath := viper.GetString(localconstants.ArgBranding)
+	if brandingPath == "" {
+		return nil, nil
+	}
+	if !filehelpers.FileExists(brandingPath) {
+		return nil, sperr.New("branding file %s does not exist", brandingPath)
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(brandingPath, ".json") {
+		file, diags = parser.ParseJSONFile(brandingPath)
+	} else {
+		file, diags = parser.ParseHCLFile(brandingPath)
+	}
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("failed to parse branding file", diags)
+	}
+
+	var config brandingConfig
+	if diags := gohcl.DecodeBody(file.Body, nil, &config); diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("failed to decode branding file", diags)
+	}
+	branding := config.Branding
+	if branding == nil {
+		return nil, sperr.New("branding file %s does not contain a branding block", brandingPath)
+	}
+
+	if branding.LogoPath != "" {
+		if !filepath.IsAbs(branding.LogoPath) {
+			branding.LogoPath = filepath.Join(filepath.Dir(brandingPath), branding.LogoPath)
+		}
+		if !filehelpers.FileExists(branding.LogoPath) {
+			return nil, sperr.New("branding logo %s does not exist", branding.LogoPath)
+		}
+		branding.LogoUrl = BrandingLogoPath
+	}
+	return branding, nil
+}
@@ -0,0 +1,57 @@
+package dashboardserver
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+)
+
+var errServerShuttingDown = dashboardexecute.ErrShuttingDown
+
+// drainPollInterval is how often to check whether pending dashboard events have been sent to clients
+const drainPollInterval = 100 * time.Millisecond
+
+// Drain prepares the server for shutdown
+// new executions are rejected, and in-progress executions are given until the context is done to complete
+// any executions which are still running when the context is done are cancelled
+// finally, the snapshots of the completed executions which are still being saved are flushed
+func (s *Server) Drain(ctx context.Context) {
+	s.draining.Store(true)
+	OutputMessage(ctx, "Draining dashboard executions")
+
+	// use a separate context for cancellation, as the drain context may already be done
+	shutdownCtx := context.WithoutCancel(ctx)
+	if err := dashboardexecute.Executor.WaitForExecutions(ctx); err != nil {
+		slog.Warn("drain period expired - cancelling in-progress executions", "error", err)
+		dashboardexecute.Executor.CancelAllExecutions(shutdownCtx)
+	}
+
+	// wait for the completion events of the drained executions to be sent to their sessions
+	// (this also queues the snapshots of the drained executions to be saved)
+	s.waitForEvents(ctx)
+
+	if err := s.snapshotWriter.Flush(ctx); err != nil {
+		slog.Warn("drain period expired - snapshots are still being saved", "error", err)
+	}
+	OutputMessage(ctx, "Drain complete")
+}
+
+// waitForEvents waits for the pending dashboard events to be handled, or the context to be done
+func (s *Server) waitForEvents(ctx context.Context) {
+	for atomic.LoadInt64(&dashboardworkspace.EventCount) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// isDraining returns whether the server is shutting down and no longer accepting new executions
+func (s *Server) isDraining() bool {
+	return s.draining.Load()
+}
@@ -0,0 +1,50 @@
+package dashboardserver
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	localsnapshot "github.com/turbot/powerpipe/internal/snapshot"
+)
+
+func TestDrainFlushesSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set(constants.ArgSnapshot, true)
+	viper.Set(constants.ArgSnapshotLocation, dir)
+	t.Cleanup(func() {
+		viper.Set(constants.ArgSnapshot, nil)
+		viper.Set(constants.ArgSnapshotLocation, nil)
+	})
+
+	dashboardexecute.Executor = dashboardexecute.NewDashboardExecutor(nil)
+	s := &Server{snapshotWriter: localsnapshot.NewWriter()}
+	s.snapshotWriter.Write(&steampipeconfig.SteampipeSnapshot{
+		SchemaVersion: "20240130",
+		FileNameRoot:  "local.dashboard.a",
+		Panels:        map[string]steampipeconfig.SnapshotPanel{},
+		Layout:        &steampipeconfig.SnapshotTreeNode{Name: "local.dashboard.a", NodeType: "dashboard"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Drain(ctx)
+
+	// the queued snapshot is saved when the server is drained
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Name(), "local.dashboard.a") {
+		t.Errorf("expected the snapshot to be saved to %s, got %v", dir, entries)
+	}
+	if !s.isDraining() {
+		t.Error("expected the server to be draining")
+	}
+}
@@ -0,0 +1,25 @@
+package dashboardserver
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+// ModAssetsPath is the url path the files in the mod assets directory are served from
+// dashboards reference these using the "asset:" prefix, e.g. a category icon of "asset:icons/vpc.svg"
+const ModAssetsPath = "/mod-assets"
+
+// modAssetsDirName is the name of the directory in the mod location containing assets used by dashboards
+const modAssetsDirName = "assets"
+
+// ModAssetsDir returns the path of the assets directory of the mod, if it exists
+func ModAssetsDir() (string, bool) {
+	dir := filepath.Join(viper.GetString(constants.ArgModLocation), modAssetsDirName)
+	if !filehelpers.DirectoryExists(dir) {
+		return "", false
+	}
+	return dir, true
+}
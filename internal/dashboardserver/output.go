@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
+	"github.com/turbot/pipe-fittings/contexthelpers"
 	"log/slog"
 	"os"
 )
 
+var contextKeyOutputDisabled = contexthelpers.ContextKey("output_disabled")
+
 const (
 	errorPrefix   = "[ Error   ]"
 	warningPrefix = "[ Warning   ]"
@@ -17,10 +20,19 @@ const (
 	waitPrefix    = "[ Wait    ]"
 )
 
-func output(_ context.Context, prefix string, msg interface{}) {
+func output(ctx context.Context, prefix string, msg interface{}) {
+	if disabled, _ := ctx.Value(contextKeyOutputDisabled).(bool); disabled {
+		return
+	}
 	_, _ = fmt.Fprintf(os.Stdout, "%s %v\n", prefix, msg)
 }
 
+// DisableOutput returns a context which suppresses server output to stdout
+// this is used when the server is run in the background of another command, e.g. to render a dashboard
+func DisableOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyOutputDisabled, true)
+}
+
 func OutputMessage(ctx context.Context, msg string) {
 	output(ctx, applyColor(messagePrefix, color.HiGreenString), msg)
 	slog.Info(msg)
@@ -18,10 +18,11 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/tagoptions"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
 
-func buildServerMetadataPayload(workspaceResources *modconfig.ResourceMaps, cloudMetadata *steampipeconfig.CloudMetadata) ([]byte, error) {
+func buildServerMetadataPayload(workspaceResources *modconfig.ResourceMaps, cloudMetadata *steampipeconfig.CloudMetadata, branding *Branding) ([]byte, error) {
 	installedMods := make(map[string]*ModMetadata)
 	for _, mod := range workspaceResources.Mods {
 		// Ignore current mod
@@ -57,6 +58,7 @@ func buildServerMetadataPayload(workspaceResources *modconfig.ResourceMaps, clou
 			},
 			InstalledMods: installedMods,
 			Telemetry:     viper.GetString(constants.ArgTelemetry),
+			Branding:      branding,
 		},
 	}
 
@@ -142,7 +144,7 @@ func addBenchmarkChildren(benchmark *modconfig.Benchmark, recordTrunk bool, trun
 				Title:     t.GetTitle(),
 				FullName:  t.FullName,
 				ShortName: t.ShortName,
-				Tags:      t.Tags,
+				Tags:      tagoptions.StripOptions(t.Tags),
 				Children:  addBenchmarkChildren(t, recordTrunk, childTrunk, trunks),
 			}
 			children = append(children, availableBenchmark)
@@ -172,7 +174,7 @@ func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps)
 				Title:       typeHelpers.SafeString(dashboard.Title),
 				FullName:    dashboard.FullName,
 				ShortName:   dashboard.ShortName,
-				Tags:        dashboard.Tags,
+				Tags:        tagoptions.StripOptions(dashboard.Tags),
 				ModFullName: mod.FullName,
 			}
 		}
@@ -203,7 +205,7 @@ func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps)
 				Title:       benchmark.GetTitle(),
 				FullName:    benchmark.FullName,
 				ShortName:   benchmark.ShortName,
-				Tags:        benchmark.Tags,
+				Tags:        tagoptions.StripOptions(benchmark.Tags),
 				IsTopLevel:  isTopLevel,
 				Children:    addBenchmarkChildren(benchmark, isTopLevel, trunk, benchmarkTrunks),
 				ModFullName: mod.FullName,
@@ -316,3 +318,20 @@ func buildInputValuesClearedPayload(event *dashboardevents.InputValuesCleared) (
 	}
 	return json.Marshal(payload)
 }
+
+func buildSessionStartedPayload(resumeToken string) ([]byte, error) {
+	payload := SessionStartedPayload{
+		Action:      "session_started",
+		ResumeToken: resumeToken,
+	}
+	return json.Marshal(payload)
+}
+
+func buildSessionResumedPayload(executionId string, resumed bool) ([]byte, error) {
+	payload := SessionResumedPayload{
+		Action:      "session_resumed",
+		ExecutionId: executionId,
+		Resumed:     resumed,
+	}
+	return json.Marshal(payload)
+}
@@ -0,0 +1,100 @@
+package dashboardserver
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"golang.org/x/time/rate"
+)
+
+// clientRateLimitExpiry is how long the rate limit state of an idle client is retained
+const clientRateLimitExpiry = 10 * time.Minute
+
+var errClientRateLimitExceeded = errors.New("too many requests - please wait before running another dashboard")
+
+// the client actions which start a dashboard execution
+var executionActions = map[string]bool{
+	"select_dashboard": true,
+	"select_snapshot":  true,
+	"input_changed":    true,
+	"table_page":       true,
+}
+
+// clientRateLimiter limits the rate of requests from each client address, so the limit applies across all the
+// websocket sessions of a client
+// a nil clientRateLimiter imposes no limit
+type clientRateLimiter struct {
+	mut     sync.Mutex
+	limit   int
+	clients map[string]*clientLimit
+	// the last time idle clients were removed
+	lastPruned time.Time
+}
+
+type clientLimit struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newClientRateLimiter creates a rate limiter for clients using the configured client rate limit
+// (requests per second) - if no limit is configured, return nil
+func newClientRateLimiter() *clientRateLimiter {
+	limit := viper.GetInt(localconstants.ArgClientRateLimit)
+	if limit <= 0 {
+		return nil
+	}
+	return &clientRateLimiter{
+		limit:      limit,
+		clients:    make(map[string]*clientLimit),
+		lastPruned: time.Now(),
+	}
+}
+
+// allow returns whether a request from the given client address is permitted
+func (l *clientRateLimiter) allow(address string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.prune(now)
+	client, ok := l.clients[address]
+	if !ok {
+		// allow short bursts of up to twice the rate limit
+		client = &clientLimit{limiter: rate.NewLimiter(rate.Limit(l.limit), 2*l.limit)}
+		l.clients[address] = client
+	}
+	client.lastSeen = now
+	return client.limiter.AllowN(now, 1)
+}
+
+// prune removes clients which have not made a request within clientRateLimitExpiry
+func (l *clientRateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPruned) < clientRateLimitExpiry {
+		return
+	}
+	for address, client := range l.clients {
+		if now.Sub(client.lastSeen) >= clientRateLimitExpiry {
+			delete(l.clients, address)
+		}
+	}
+	l.lastPruned = now
+}
+
+// rateLimitAddress returns the address of the client which made the request - forwarded headers are not used, as
+// they are set by the client and would allow the limit to be bypassed
+func rateLimitAddress(request *http.Request) string {
+	if request == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		return host
+	}
+	return request.RemoteAddr
+}
@@ -0,0 +1,101 @@
+package dashboardserver
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func newTestClientRateLimiter(t *testing.T, limit int) *clientRateLimiter {
+	t.Helper()
+	viper.Set(localconstants.ArgClientRateLimit, limit)
+	t.Cleanup(func() { viper.Set(localconstants.ArgClientRateLimit, 0) })
+	return newClientRateLimiter()
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	l := newTestClientRateLimiter(t, 1)
+	now := time.Now()
+
+	// a burst of twice the limit is allowed, shared by all the sessions of a client
+	for i := 0; i < 2; i++ {
+		if !l.allow("10.0.0.1", now) {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+	if l.allow("10.0.0.1", now) {
+		t.Error("expected the request to exceed the rate limit")
+	}
+	// other clients have their own limit
+	if !l.allow("10.0.0.2", now) {
+		t.Error("expected the request from another client to be allowed")
+	}
+	// the limit is replenished over time
+	if !l.allow("10.0.0.1", now.Add(time.Second)) {
+		t.Error("expected the request to be allowed after waiting")
+	}
+}
+
+func TestClientRateLimiterNoLimit(t *testing.T) {
+	l := newTestClientRateLimiter(t, 0)
+	if l != nil {
+		t.Fatal("expected no rate limiter when no limit is configured")
+	}
+	for i := 0; i < 100; i++ {
+		if !l.allow("10.0.0.1", time.Now()) {
+			t.Fatal("expected all requests to be allowed")
+		}
+	}
+}
+
+func TestClientRateLimiterPrune(t *testing.T) {
+	l := newTestClientRateLimiter(t, 1)
+	now := time.Now()
+	l.allow("10.0.0.1", now)
+	l.allow("10.0.0.2", now.Add(clientRateLimitExpiry/2))
+
+	l.allow("10.0.0.3", now.Add(clientRateLimitExpiry))
+	if _, ok := l.clients["10.0.0.1"]; ok {
+		t.Error("expected the idle client to be removed")
+	}
+	if _, ok := l.clients["10.0.0.2"]; !ok {
+		t.Error("expected the recent client to be retained")
+	}
+}
+
+func TestRateLimitAddress(t *testing.T) {
+	tests := map[string]struct {
+		request  *http.Request
+		expected string
+	}{
+		"host and port": {
+			request:  &http.Request{RemoteAddr: "10.0.0.1:51234"},
+			expected: "10.0.0.1",
+		},
+		"ipv6": {
+			request:  &http.Request{RemoteAddr: "[::1]:51234"},
+			expected: "::1",
+		},
+		"forwarded header is ignored": {
+			request:  &http.Request{RemoteAddr: "10.0.0.1:51234", Header: http.Header{"X-Forwarded-For": []string{"192.168.0.1"}}},
+			expected: "10.0.0.1",
+		},
+		"no port": {
+			request:  &http.Request{RemoteAddr: "10.0.0.1"},
+			expected: "10.0.0.1",
+		},
+		"no request": {
+			expected: "",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := rateLimitAddress(tc.request); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package dashboardserver
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
@@ -10,48 +13,106 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
 	typeHelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/powerpipe/internal/audit"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	localsnapshot "github.com/turbot/powerpipe/internal/snapshot"
 	"gopkg.in/olahol/melody.v1"
 )
 
+const (
+	// sessionIdKey is the websocket session key used to store the id of a resumed session
+	sessionIdKey = "session_id"
+	// sessionResumeTimeout is how long the execution for a disconnected session is retained
+	sessionResumeTimeout = 30 * time.Second
+)
+
 type Server struct {
 	mutex            *sync.Mutex
 	dashboardClients map[string]*DashboardClientInfo
 	webSocket        *melody.Melody
 	workspace        *dashboardworkspace.WorkspaceEvents
+	// custom branding for the dashboard UI (nil if not configured)
+	branding *Branding
+	// audit log of dashboard server activity (nil if not configured)
+	auditLog *audit.Logger
+	// set when the server is shutting down - new executions are rejected
+	draining atomic.Bool
+	// limits the rate of requests from each client (nil if there is no limit)
+	rateLimiter *clientRateLimiter
+	// saves the snapshot of each completed execution (nil if --snapshot is not set)
+	snapshotWriter *localsnapshot.Writer
 }
 
 func NewServer(ctx context.Context, w *dashboardworkspace.WorkspaceEvents, webSocket *melody.Melody) (*Server, error) {
 	OutputWait(ctx, "Starting WorkspaceEvents Server")
 
-	var dashboardClients = make(map[string]*DashboardClientInfo)
-
-	var mutex = &sync.Mutex{}
+	server, err := newServer(w, webSocket)
+	if err != nil {
+		return nil, err
+	}
 
-	server := &Server{
-		mutex:            mutex,
-		dashboardClients: dashboardClients,
-		webSocket:        webSocket,
-		workspace:        w,
+	server.auditLog, err = audit.NewLogger(ctx)
+	if err != nil {
+		return nil, err
 	}
+	server.snapshotWriter = localsnapshot.NewWriter()
 
 	w.RegisterDashboardEventHandler(ctx, server.HandleDashboardEvent)
 
-	err := w.SetupWatcher(ctx, func(c context.Context, e error) {})
+	err = w.SetupWatcher(ctx, func(c context.Context, e error) {})
+	if err == nil {
+		err = w.WatchReferencedFiles(ctx)
+	}
+	if err == nil && viper.GetBool(localconstants.ArgWatchDependencies) {
+		err = w.WatchDependencyMods(ctx)
+	}
 	OutputMessage(ctx, "WorkspaceEvents loaded")
 
 	return server, err
 }
 
+// NewSnapshotServer creates a server which only displays the snapshots of the workspace, used to render snapshots
+// in a browser
+// unlike NewServer, it does not watch the workspace files, record audit events or handle dashboard events, so
+// nothing is left registered with the workspace once the server is shut down
+func NewSnapshotServer(w *dashboardworkspace.WorkspaceEvents, webSocket *melody.Melody) (*Server, error) {
+	return newServer(w, webSocket)
+}
+
+func newServer(w *dashboardworkspace.WorkspaceEvents, webSocket *melody.Melody) (*Server, error) {
+	branding, err := LoadBranding()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		mutex:            &sync.Mutex{},
+		dashboardClients: make(map[string]*DashboardClientInfo),
+		webSocket:        webSocket,
+		workspace:        w,
+		branding:         branding,
+		rateLimiter:      newClientRateLimiter(),
+	}, nil
+}
+
+// Branding returns the custom branding for the dashboard UI, or nil if none is configured
+func (s *Server) Branding() *Branding {
+	return s.branding
+}
+
 // Start starts the API server
 // it returns a channel which is signalled when the API server terminates
 func (s *Server) Start(ctx context.Context) chan struct{} {
@@ -71,9 +132,22 @@ func (s *Server) Shutdown(ctx context.Context) {
 		slog.Debug("closed websocket")
 	}
 
+	// write any pending audit events
+	s.auditLog.Close()
+
 	slog.Debug("Server shutdown complete")
 }
 
+// saveSnapshot queues the snapshot of the completed execution to be saved (if --snapshot is set)
+func (s *Server) saveSnapshot(e *dashboardevents.ExecutionComplete) {
+	if s.snapshotWriter == nil {
+		return
+	}
+	snap := dashboardexecute.ExecutionCompleteToSnapshot(e)
+	snap.FileNameRoot = e.Root.GetName()
+	s.snapshotWriter.Write(snap)
+}
+
 func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents.DashboardEvent) {
 	var payloadError error
 	var payload []byte
@@ -125,6 +199,9 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 		dashboardName := e.Root.GetName()
 		s.writePayloadToSession(e.Session, payload)
 		OutputReady(ctx, fmt.Sprintf("Execution complete: %s", dashboardName))
+		s.saveSnapshot(e)
+		// if the dashboard has a refresh interval, schedule the next execution
+		s.scheduleRefresh(ctx, e.Session, e.Root.GetResource())
 
 	case *dashboardevents.ControlComplete:
 		slog.Debug("ControlComplete event", "session", e.Session, "control", e.Control.GetControlId())
@@ -202,7 +279,7 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 			OutputMessage(ctx, "Available Dashboards updated")
 
 			// Emit dashboard metadata event in case there is a new mod - else the UI won't know about this mod
-			payload, payloadError = buildServerMetadataPayload(s.workspace.GetResourceMaps(), s.workspace.CloudMetadata)
+			payload, payloadError = buildServerMetadataPayload(s.workspace.GetResourceMaps(), s.workspace.CloudMetadata, s.branding)
 			if payloadError != nil {
 				return
 			}
@@ -311,7 +388,7 @@ func (s *Server) InitAsync(ctx context.Context) {
 		// Return list of dashboards on connect
 		s.webSocket.HandleConnect(func(session *melody.Session) {
 			slog.Debug("client connected")
-			s.addSession(session)
+			s.addSession(ctx, session)
 		})
 
 		s.webSocket.HandleDisconnect(func(session *melody.Session) {
@@ -341,9 +418,30 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 			slog.Debug("handleMessageFunc", "message", string(msg))
 		}
 
+		// once the server is draining, do not start any new executions
+		if executionActions[request.Action] && s.isDraining() {
+			s.workspace.PublishDashboardEvent(ctx, &dashboardevents.ExecutionError{
+				Error:     errServerShuttingDown,
+				Session:   sessionId,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
+		// requests which start an execution are subject to the client rate limit
+		if executionActions[request.Action] && !s.rateLimiter.allow(rateLimitAddress(session.Request), time.Now()) {
+			slog.Warn("client rate limit exceeded", "session", sessionId, "action", request.Action)
+			s.workspace.PublishDashboardEvent(ctx, &dashboardevents.ExecutionError{
+				Error:     errClientRateLimitExceeded,
+				Session:   sessionId,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
 		switch request.Action {
 		case "get_server_metadata":
-			payload, err := buildServerMetadataPayload(s.workspace.GetResourceMaps(), s.workspace.CloudMetadata)
+			payload, err := buildServerMetadataPayload(s.workspace.GetResourceMaps(), s.workspace.CloudMetadata, s.branding)
 			if err != nil {
 				OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for get_metadata"))
 			}
@@ -369,8 +467,6 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 			if dashboard == nil {
 				return
 			}
-			s.setDashboardForSession(sessionId, request.Payload.Dashboard.FullName, request.Payload.InputValues)
-
 			// was a search path passed into the execute command?
 			var opts []backend.ConnectOption
 			if request.Payload.SearchPath != nil || request.Payload.SearchPathPrefix != nil {
@@ -379,11 +475,19 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 					SearchPathPrefix: request.Payload.SearchPathPrefix,
 				}))
 			}
+			clientInfo := s.setDashboardForSession(sessionId, request.Payload.Dashboard.FullName, request.Payload.InputValues)
+			// store the connect options so they can be used if the dashboard is refreshed
+			s.mutex.Lock()
+			clientInfo.connectOpts = opts
+			s.mutex.Unlock()
+			s.recordAuditEvent(session, sessionId, auditActionForResource(dashboard), request.Payload.Dashboard.FullName, request.Payload.InputValues)
+
 			_ = dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, dashboard, request.Payload.InputValues, s.workspace, opts...)
 
 		case "select_snapshot":
 			snapshotName := request.Payload.Dashboard.FullName
 			s.setDashboardForSession(sessionId, snapshotName, request.Payload.InputValues)
+			s.recordAuditEvent(session, sessionId, audit.ActionSnapshotViewed, snapshotName, nil)
 			snap, err := dashboardexecute.Executor.LoadSnapshot(ctx, sessionId, snapshotName, s.workspace)
 			// TACTICAL- handle with error message
 			error_helpers.FailOnError(err)
@@ -396,10 +500,22 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 			OutputReady(ctx, fmt.Sprintf("Show snapshot complete: %s", snapshotName))
 		case "input_changed":
 			s.setDashboardInputsForSession(sessionId, request.Payload.InputValues)
+			s.recordAuditEvent(session, sessionId, audit.ActionInputsChanged, s.getDashboardForSession(sessionId), request.Payload.InputValues)
 			_ = dashboardexecute.Executor.OnInputChanged(ctx, sessionId, request.Payload.InputValues, request.Payload.ChangedInput)
+		case "table_page":
+			if err := dashboardexecute.Executor.OnTablePageChanged(ctx, sessionId, request.Payload.Panel, request.Payload.Page, request.Payload.SortColumn, request.Payload.SortDirection); err != nil {
+				slog.Warn("failed to retrieve table page", "panel", request.Payload.Panel, "error", err)
+			}
+		case "set_auto_refresh":
+			s.setAutoRefresh(ctx, sessionId, request.Payload.AutoRefresh)
 		case "clear_dashboard":
 			s.setDashboardInputsForSession(sessionId, nil)
+			s.stopRefreshForSession(sessionId)
 			dashboardexecute.Executor.CancelExecutionForSession(ctx, sessionId)
+		case "resume_session":
+			s.resumeSession(ctx, session, request.Payload.ExecutionId, request.Payload.ResumeToken, request.Payload.LastSequence)
+		case "ack":
+			s.ackSessionEvents(sessionId, request.Payload.LastSequence)
 		}
 	}
 }
@@ -411,19 +527,160 @@ func (s *Server) clearSession(ctx context.Context, session *melody.Session) {
 
 	sessionId := s.getSessionId(session)
 
+	// if the client is viewing a dashboard, keep the execution alive for a while in case the client reconnects
+	if s.suspendSession(ctx, sessionId) {
+		return
+	}
+
 	dashboardexecute.Executor.CancelExecutionForSession(ctx, sessionId)
 
 	s.deleteDashboardClient(sessionId)
 }
 
-func (s *Server) addSession(session *melody.Session) {
+// suspendSession detaches the websocket session from a client which has a dashboard selected,
+// allowing the execution to continue and events to be buffered until the client resumes the session
+// if the session is not resumed within sessionResumeTimeout, the execution is cancelled
+func (s *Server) suspendSession(ctx context.Context, sessionId string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clientInfo, ok := s.dashboardClients[sessionId]
+	if !ok || clientInfo.Dashboard == nil {
+		return false
+	}
+
+	slog.Debug("suspending session", "session", sessionId)
+	clientInfo.Session = nil
+	clientInfo.resumeTimer = time.AfterFunc(sessionResumeTimeout, func() {
+		s.expireSession(ctx, sessionId)
+	})
+	return true
+}
+
+// expireSession cancels the execution for a suspended session which has not been resumed
+func (s *Server) expireSession(ctx context.Context, sessionId string) {
+	s.mutex.Lock()
+	clientInfo, ok := s.dashboardClients[sessionId]
+	// if the session has been resumed, there is nothing to do
+	if !ok || clientInfo.Session != nil {
+		s.mutex.Unlock()
+		return
+	}
+	clientInfo.stopRefresh()
+	delete(s.dashboardClients, sessionId)
+	s.mutex.Unlock()
+
+	slog.Debug("suspended session expired", "session", sessionId)
+	dashboardexecute.Executor.CancelExecutionForSession(ctx, sessionId)
+}
+
+// resumeSession attaches a reconnected websocket session to the suspended session which owns the given execution
+// and replays all events sent after lastSequence
+func (s *Server) resumeSession(ctx context.Context, session *melody.Session, executionId, resumeToken string, lastSequence int64) {
+	if s.reattachSession(ctx, session, executionId, resumeToken, lastSequence) {
+		return
+	}
+
+	payload, err := buildSessionResumedPayload(executionId, false)
+	if err != nil {
+		OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for resume_session"))
+		return
+	}
+	_ = session.Write(payload)
+}
+
+func (s *Server) reattachSession(ctx context.Context, session *melody.Session, executionId, resumeToken string, lastSequence int64) bool {
+	suspendedSessionId, ok := dashboardexecute.Executor.GetSessionForExecution(executionId)
+	if !ok {
+		return false
+	}
+	return s.reattachToSession(ctx, session, suspendedSessionId, executionId, resumeToken, lastSequence)
+}
+
+func (s *Server) reattachToSession(ctx context.Context, session *melody.Session, suspendedSessionId, executionId, resumeToken string, lastSequence int64) bool {
+	// hold the lock while replaying events so that no new events are written to the session out of order
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clientInfo, ok := s.dashboardClients[suspendedSessionId]
+	// if the session is still connected, it cannot be resumed
+	if !ok || clientInfo.Session != nil {
+		return false
+	}
+	// only the client the session was started for may resume it
+	if subtle.ConstantTimeCompare([]byte(clientInfo.resumeToken), []byte(resumeToken)) != 1 {
+		slog.Warn("session resume rejected - invalid resume token", "session", suspendedSessionId)
+		return false
+	}
+	events, ok := clientInfo.events.since(lastSequence)
+	if !ok {
+		return false
+	}
+	payload, err := buildSessionResumedPayload(executionId, true)
+	if err != nil {
+		OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for resume_session"))
+		return false
+	}
+
+	slog.Debug("resuming session", "session", suspendedSessionId, "last sequence", lastSequence)
+	if clientInfo.resumeTimer != nil {
+		clientInfo.resumeTimer.Stop()
+		clientInfo.resumeTimer = nil
+	}
+	// remove the client info which was created when this websocket session connected
+	delete(s.dashboardClients, s.getSessionId(session))
+	// from now on, messages from this websocket session are handled for the suspended session
+	session.Set(sessionIdKey, suspendedSessionId)
+	clientInfo.Session = session
+
+	_ = session.Write(payload)
+	for _, e := range events {
+		_ = session.Write(e)
+	}
+	return true
+}
+
+func (s *Server) ackSessionEvents(sessionId string, sequence int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if clientInfo, ok := s.dashboardClients[sessionId]; ok {
+		clientInfo.events.ack(sequence)
+	}
+}
+
+func (s *Server) addSession(ctx context.Context, session *melody.Session) {
 	sessionId := s.getSessionId(session)
 
+	resumeToken, err := newResumeToken()
+	if err != nil {
+		OutputError(ctx, sperr.WrapWithMessage(err, "error creating resume token"))
+		return
+	}
 	clientSession := &DashboardClientInfo{
-		Session: session,
+		Session:     session,
+		events:      newSessionEventBuffer(),
+		resumeToken: resumeToken,
 	}
 
 	s.addDashboardClient(sessionId, clientSession)
+
+	// send the client the token it must present to resume this session after a reconnect
+	payload, err := buildSessionStartedPayload(resumeToken)
+	if err != nil {
+		OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for session_started"))
+		return
+	}
+	_ = session.Write(payload)
+}
+
+// newResumeToken returns a random token used to authorise resuming a session
+func newResumeToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
 }
 
 func (s *Server) setDashboardInputsForSession(sessionId string, inputs map[string]interface{}) {
@@ -434,6 +691,10 @@ func (s *Server) setDashboardInputsForSession(sessionId string, inputs map[strin
 }
 
 func (s *Server) getSessionId(session *melody.Session) string {
+	// if this websocket session has resumed a previous session, use the id of that session
+	if sessionId, ok := session.Get(sessionIdKey); ok {
+		return sessionId.(string)
+	}
 	return fmt.Sprintf("%p", session)
 }
 
@@ -446,6 +707,10 @@ func (s *Server) setDashboardForSession(sessionId string, dashboardName string,
 	dashboardClientInfo := s.dashboardClients[sessionId]
 	dashboardClientInfo.Dashboard = &dashboardName
 	dashboardClientInfo.DashboardInputs = inputs
+	// any scheduled refresh is for the previously selected dashboard,
+	// and auto-refresh is turned back on for the new dashboard
+	dashboardClientInfo.stopRefresh()
+	dashboardClientInfo.autoRefreshDisabled = false
 
 	return dashboardClientInfo
 }
@@ -455,7 +720,12 @@ func (s *Server) writePayloadToSession(sessionId string, payload []byte) {
 	defer s.mutex.Unlock()
 
 	if sessionInfo, ok := s.dashboardClients[sessionId]; ok {
-		_ = sessionInfo.Session.Write(payload)
+		// add a sequence number and buffer the payload so it can be replayed if the client reconnects
+		payload = sessionInfo.events.add(payload)
+		// if the session is suspended, the payload will be sent when it is resumed
+		if sessionInfo.Session != nil {
+			_ = sessionInfo.Session.Write(payload)
+		}
 	}
 }
 
@@ -474,6 +744,9 @@ func (s *Server) addDashboardClient(sessionId string, clientSession *DashboardCl
 
 func (s *Server) deleteDashboardClient(sessionId string) {
 	s.mutex.Lock()
+	if clientInfo, ok := s.dashboardClients[sessionId]; ok {
+		clientInfo.stopRefresh()
+	}
 	delete(s.dashboardClients, sessionId)
 	s.mutex.Unlock()
 }
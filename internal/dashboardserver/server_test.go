@@ -0,0 +1,141 @@
+package dashboardserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"gopkg.in/olahol/melody.v1"
+)
+
+// dialTestServer serves the websocket of the server and connects a client to it
+func dialTestServer(t *testing.T, webSocket *melody.Melody) *websocket.Conn {
+	t.Helper()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = webSocket.HandleRequest(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readTestPayload reads the next message sent to the client
+func readTestPayload(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(msg, &payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestReattachToSession(t *testing.T) {
+	tests := map[string]struct {
+		resumeToken  string
+		lastSequence int64
+		wantResumed  bool
+		wantReplayed []float64
+	}{
+		"valid token": {
+			resumeToken:  "secret",
+			lastSequence: 1,
+			wantResumed:  true,
+			wantReplayed: []float64{2, 3},
+		},
+		"invalid token": {
+			resumeToken: "guess",
+		},
+		"no token": {},
+		"events dropped": {
+			resumeToken:  "secret",
+			lastSequence: -1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			webSocket := melody.New()
+			server, err := newServer(dashboardworkspace.NewWorkspaceEvents(&workspace.Workspace{}), webSocket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions := make(chan *melody.Session, 1)
+			webSocket.HandleConnect(func(session *melody.Session) {
+				server.addSession(ctx, session)
+				sessions <- session
+			})
+
+			// a suspended session which has sent 3 events, of which the client acknowledged the first
+			suspended := &DashboardClientInfo{events: newSessionEventBuffer(), resumeToken: "secret"}
+			for i := 0; i < 3; i++ {
+				suspended.events.add([]byte(`{"action":"leaf_node_updated"}`))
+			}
+			suspended.events.ack(1)
+			server.dashboardClients["suspended"] = suspended
+
+			conn := dialTestServer(t, webSocket)
+			started := readTestPayload(t, conn)
+			if started["action"] != "session_started" || started["resume_token"] == "" {
+				t.Fatalf("expected a session_started payload with a resume token, got %v", started)
+			}
+			session := <-sessions
+
+			resumed := server.reattachToSession(ctx, session, "suspended", "e1", tc.resumeToken, tc.lastSequence)
+			if resumed != tc.wantResumed {
+				t.Fatalf("expected resumed %v, got %v", tc.wantResumed, resumed)
+			}
+			if !resumed {
+				if suspended.Session != nil {
+					t.Error("expected the session to remain suspended")
+				}
+				return
+			}
+			if suspended.Session != session || server.getSessionId(session) != "suspended" {
+				t.Error("expected the websocket session to be attached to the suspended session")
+			}
+			if _, ok := server.dashboardClients[fmt.Sprintf("%p", session)]; ok {
+				t.Error("expected the client info of the new websocket session to be removed")
+			}
+			if payload := readTestPayload(t, conn); payload["action"] != "session_resumed" || payload["resumed"] != true {
+				t.Errorf("expected a session_resumed payload, got %v", payload)
+			}
+			for _, sequence := range tc.wantReplayed {
+				if payload := readTestPayload(t, conn); payload["sequence"] != sequence {
+					t.Errorf("expected event %v to be replayed, got %v", sequence, payload)
+				}
+			}
+		})
+	}
+}
+
+func TestNewResumeToken(t *testing.T) {
+	first, err := newResumeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newResumeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 64 || first == second {
+		t.Errorf("expected unique 32 byte tokens, got %s and %s", first, second)
+	}
+}
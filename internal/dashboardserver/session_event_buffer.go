@@ -0,0 +1,87 @@
+package dashboardserver
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// maxBufferedSessionEvents is the maximum number of unacknowledged events retained for a session
+// if this is exceeded, the oldest events are dropped and the session can no longer be resumed from before them
+const maxBufferedSessionEvents = 2048
+
+type sequencedPayload struct {
+	sequence int64
+	payload  []byte
+}
+
+// sessionEventBuffer assigns sequence numbers to the payloads sent to a session and retains
+// any which have not been acknowledged by the client, so they can be replayed after a reconnect
+type sessionEventBuffer struct {
+	lastSequence int64
+	events       []sequencedPayload
+}
+
+func newSessionEventBuffer() *sessionEventBuffer {
+	return &sessionEventBuffer{}
+}
+
+// add assigns the next sequence number to the payload, buffers it
+// and returns the payload with the sequence number included
+func (b *sessionEventBuffer) add(payload []byte) []byte {
+	b.lastSequence++
+	payload = withSequenceNumber(payload, b.lastSequence)
+
+	b.events = append(b.events, sequencedPayload{sequence: b.lastSequence, payload: payload})
+	if len(b.events) > maxBufferedSessionEvents {
+		b.events = b.events[len(b.events)-maxBufferedSessionEvents:]
+	}
+	return payload
+}
+
+// ack discards all buffered events up to and including the given sequence number
+func (b *sessionEventBuffer) ack(sequence int64) {
+	idx := 0
+	for idx < len(b.events) && b.events[idx].sequence <= sequence {
+		idx++
+	}
+	b.events = b.events[idx:]
+}
+
+// since returns all buffered events after the given sequence number
+// if events after this sequence number have already been discarded, return false
+func (b *sessionEventBuffer) since(sequence int64) ([][]byte, bool) {
+	// have we dropped events the client has not received?
+	if len(b.events) > 0 && b.events[0].sequence > sequence+1 {
+		return nil, false
+	}
+	// if the buffer is empty, the client must have seen every event we sent
+	if len(b.events) == 0 && sequence < b.lastSequence {
+		return nil, false
+	}
+
+	var res [][]byte
+	for _, e := range b.events {
+		if e.sequence > sequence {
+			res = append(res, e.payload)
+		}
+	}
+	return res, true
+}
+
+// withSequenceNumber adds a 'sequence' property to a marshalled payload object
+// we do this on the marshalled bytes to avoid re-marshalling (potentially large) payloads
+func withSequenceNumber(payload []byte, sequence int64) []byte {
+	if len(payload) < 2 || payload[0] != '{' {
+		return payload
+	}
+	prefix := fmt.Sprintf(`{"sequence":%d`, sequence)
+	// handle empty object
+	if bytes.Equal(payload, []byte("{}")) {
+		return []byte(prefix + "}")
+	}
+
+	res := make([]byte, 0, len(payload)+len(prefix)+1)
+	res = append(res, prefix...)
+	res = append(res, ',')
+	return append(res, payload[1:]...)
+}
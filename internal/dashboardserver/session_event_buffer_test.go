@@ -0,0 +1,141 @@
+package dashboardserver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWithSequenceNumber(t *testing.T) {
+	tests := map[string]struct {
+		payload  string
+		expected string
+	}{
+		"object": {
+			payload:  `{"action":"leaf_node_updated"}`,
+			expected: `{"sequence":7,"action":"leaf_node_updated"}`,
+		},
+		"empty object": {
+			payload:  `{}`,
+			expected: `{"sequence":7}`,
+		},
+		"not an object": {
+			payload:  `[1,2]`,
+			expected: `[1,2]`,
+		},
+		"empty": {
+			payload:  ``,
+			expected: ``,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := string(withSequenceNumber([]byte(tc.payload), 7)); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSessionEventBufferSequence(t *testing.T) {
+	b := newSessionEventBuffer()
+	for i := 1; i <= 3; i++ {
+		expected := fmt.Sprintf(`{"sequence":%d,"n":%d}`, i, i)
+		if got := string(b.add([]byte(fmt.Sprintf(`{"n":%d}`, i)))); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+	if b.lastSequence != 3 {
+		t.Errorf("expected last sequence 3, got %d", b.lastSequence)
+	}
+}
+
+func TestSessionEventBufferSince(t *testing.T) {
+	tests := map[string]struct {
+		added        int
+		acked        int64
+		since        int64
+		wantOk       bool
+		wantReplayed []string
+	}{
+		"nothing received": {
+			added:        2,
+			since:        0,
+			wantOk:       true,
+			wantReplayed: []string{`{"sequence":1}`, `{"sequence":2}`},
+		},
+		"some received": {
+			added:        3,
+			since:        2,
+			wantOk:       true,
+			wantReplayed: []string{`{"sequence":3}`},
+		},
+		"all received": {
+			added:  3,
+			since:  3,
+			wantOk: true,
+		},
+		"acknowledged events are not replayed": {
+			added:        3,
+			acked:        1,
+			since:        1,
+			wantOk:       true,
+			wantReplayed: []string{`{"sequence":2}`, `{"sequence":3}`},
+		},
+		"acknowledged events requested": {
+			added: 3,
+			acked: 2,
+			since: 0,
+		},
+		"all acknowledged": {
+			added:  3,
+			acked:  3,
+			since:  3,
+			wantOk: true,
+		},
+		"all acknowledged, some requested": {
+			added: 3,
+			acked: 3,
+			since: 2,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := newSessionEventBuffer()
+			for i := 0; i < tc.added; i++ {
+				b.add([]byte(`{}`))
+			}
+			b.ack(tc.acked)
+
+			events, ok := b.since(tc.since)
+			if ok != tc.wantOk {
+				t.Fatalf("expected ok %v, got %v", tc.wantOk, ok)
+			}
+			var got []string
+			for _, e := range events {
+				got = append(got, string(e))
+			}
+			if !reflect.DeepEqual(got, tc.wantReplayed) {
+				t.Errorf("expected %v to be replayed, got %v", tc.wantReplayed, got)
+			}
+		})
+	}
+}
+
+func TestSessionEventBufferOverflow(t *testing.T) {
+	b := newSessionEventBuffer()
+	for i := 0; i < maxBufferedSessionEvents+10; i++ {
+		b.add([]byte(`{}`))
+	}
+	if len(b.events) != maxBufferedSessionEvents {
+		t.Fatalf("expected %d buffered events, got %d", maxBufferedSessionEvents, len(b.events))
+	}
+	// the oldest events have been dropped, so the session cannot be resumed from before them
+	if _, ok := b.since(5); ok {
+		t.Error("expected resuming from a dropped event to fail")
+	}
+	events, ok := b.since(10)
+	if !ok || len(events) != maxBufferedSessionEvents {
+		t.Errorf("expected %d events to be replayed, got %d (ok %v)", maxBufferedSessionEvents, len(events), ok)
+	}
+}
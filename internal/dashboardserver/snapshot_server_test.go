@@ -0,0 +1,64 @@
+package dashboardserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/workspace"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardworkspace"
+	"gopkg.in/olahol/melody.v1"
+)
+
+func TestSnapshotServer(t *testing.T) {
+	// audit logging is configured, but is not used by the snapshot server
+	auditDir := t.TempDir()
+	viper.Set(localconstants.ArgAuditLog, auditDir)
+	t.Cleanup(func() { viper.Set(localconstants.ArgAuditLog, "") })
+	dashboardexecute.Executor = dashboardexecute.NewDashboardExecutor(nil)
+
+	snapshotPath := filepath.Join(t.TempDir(), "report.pps")
+	if err := os.WriteFile(snapshotPath, []byte(`{"schema_version":"20221222","panels":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+	mod.ResourceMaps.AddSnapshots([]string{snapshotPath})
+	w := dashboardworkspace.NewWorkspaceEvents(&workspace.Workspace{Mod: mod})
+
+	webSocket := melody.New()
+	server, err := NewSnapshotServer(w, webSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	webSocket.HandleConnect(func(session *melody.Session) { server.addSession(ctx, session) })
+	webSocket.HandleMessage(server.handleMessageFunc(ctx))
+	conn := dialTestServer(t, webSocket)
+	if payload := readTestPayload(t, conn); payload["action"] != "session_started" {
+		t.Fatalf("expected a session_started payload, got %v", payload)
+	}
+	request := `{"action":"select_snapshot","payload":{"dashboard":{"full_name":"snapshot.report"}}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	payload := readTestPayload(t, conn)
+	snapshot, _ := payload["snapshot"].(map[string]any)
+	if payload["action"] != "execution_complete" || snapshot["schema_version"] != "20221222" {
+		t.Errorf("expected the snapshot to be displayed, got %v", payload)
+	}
+
+	server.Shutdown(ctx)
+	if server.auditLog != nil {
+		t.Error("expected the snapshot server not to record audit events")
+	}
+	if entries, _ := os.ReadDir(auditDir); len(entries) != 0 {
+		t.Errorf("expected no audit log files, got %d", len(entries))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"gopkg.in/olahol/melody.v1"
@@ -103,10 +104,37 @@ type InputValuesClearedPayload struct {
 	ExecutionId   string   `json:"execution_id"`
 }
 
+type SessionStartedPayload struct {
+	Action string `json:"action"`
+	// the token which must be presented to resume the session after a reconnect
+	ResumeToken string `json:"resume_token"`
+}
+
+type SessionResumedPayload struct {
+	Action      string `json:"action"`
+	ExecutionId string `json:"execution_id"`
+	// if false, the session could not be resumed and the client must select the dashboard again
+	Resumed bool `json:"resumed"`
+}
+
 type DashboardClientInfo struct {
+	// the websocket session - this will be nil if the client has disconnected
+	// and the session is waiting to be resumed
 	Session         *melody.Session
 	Dashboard       *string
 	DashboardInputs map[string]interface{}
+	// sequenced events sent to this client which have not yet been acknowledged
+	events *sessionEventBuffer
+	// the token the client must present to resume this session after a reconnect
+	resumeToken string
+	// timer used to expire the session if it is not resumed after a disconnect
+	resumeTimer *time.Timer
+	// the connect options used to execute the selected dashboard, used when the dashboard is refreshed
+	connectOpts []backend.ConnectOption
+	// timer used to re-execute a dashboard which has a refresh interval
+	refreshTimer *time.Timer
+	// set if the client has turned off auto-refresh
+	autoRefreshDisabled bool
 }
 
 type ClientRequestDashboardPayload struct {
@@ -114,11 +142,22 @@ type ClientRequestDashboardPayload struct {
 }
 
 type ClientRequestPayload struct {
-	Dashboard        ClientRequestDashboardPayload `json:"dashboard"`
-	InputValues      map[string]interface{}        `json:"input_values"`
-	ChangedInput     string                        `json:"changed_input"`
-	SearchPath       []string                      `json:"search_path"`
-	SearchPathPrefix []string                      `json:"search_path_prefix"`
+	Dashboard    ClientRequestDashboardPayload `json:"dashboard"`
+	InputValues  map[string]interface{}        `json:"input_values"`
+	ChangedInput string                        `json:"changed_input"`
+	// used to request a page of a paginated table
+	Panel            string   `json:"panel"`
+	Page             int      `json:"page"`
+	SortColumn       string   `json:"sort_column"`
+	SortDirection    string   `json:"sort_direction"`
+	SearchPath       []string `json:"search_path"`
+	SearchPathPrefix []string `json:"search_path_prefix"`
+	// used to resume a session after a reconnect, and to acknowledge received events
+	ExecutionId  string `json:"execution_id"`
+	ResumeToken  string `json:"resume_token"`
+	LastSequence int64  `json:"last_sequence"`
+	// used to turn auto-refresh on or off for dashboards with a refresh interval
+	AutoRefresh bool `json:"auto_refresh"`
 }
 
 type ClientRequest struct {
@@ -182,6 +221,7 @@ type ServerMetadata struct {
 	Cloud         *steampipeconfig.CloudMetadata `json:"cloud,omitempty"`
 	Telemetry     string                         `json:"telemetry"`
 	SearchPath    *SearchPathMetadata            `json:"search_path"`
+	Branding      *Branding                      `json:"branding,omitempty"`
 }
 
 type ServerMetadataPayload struct {
@@ -0,0 +1,351 @@
+package dashboardtransform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+const numericDataType = "NUMERIC"
+
+// selectStep keeps the given columns, optionally renaming them
+type selectStep struct {
+	columns []string
+	aliases []string
+}
+
+func newSelectStep(args []string) (Step, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("select requires at least one column")
+	}
+	s := &selectStep{}
+	for _, arg := range args {
+		column, alias := parseAlias(arg)
+		if alias == "" {
+			alias = column
+		}
+		s.columns = append(s.columns, column)
+		s.aliases = append(s.aliases, alias)
+	}
+	return s, nil
+}
+
+func (s *selectStep) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	res := &dashboardtypes.LeafData{Rows: make([]map[string]any, len(data.Rows))}
+	for i, name := range s.columns {
+		col, err := getColumn(data, name)
+		if err != nil {
+			return nil, err
+		}
+		res.Columns = append(res.Columns, &queryresult.ColumnDef{Name: s.aliases[i], DataType: col.DataType})
+	}
+	for rowIdx, row := range data.Rows {
+		newRow := make(map[string]any, len(s.columns))
+		for i, name := range s.columns {
+			newRow[s.aliases[i]] = row[name]
+		}
+		res.Rows[rowIdx] = newRow
+	}
+	return res, nil
+}
+
+// aggregate is an aggregate function applied to a column by the group_by step
+type aggregate struct {
+	function string
+	column   string
+	alias    string
+}
+
+func (a aggregate) apply(rows []map[string]any) any {
+	if a.function == "count" {
+		if a.column == "" {
+			return int64(len(rows))
+		}
+		var count int64
+		for _, row := range rows {
+			if row[a.column] != nil {
+				count++
+			}
+		}
+		return count
+	}
+
+	var values []float64
+	for _, row := range rows {
+		if f, ok := toFloat(row[a.column]); ok {
+			values = append(values, f)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	switch a.function {
+	case "sum":
+		return sum(values)
+	case "avg":
+		return sum(values) / float64(len(values))
+	case "min":
+		res := math.Inf(1)
+		for _, v := range values {
+			res = math.Min(res, v)
+		}
+		return res
+	case "max":
+		res := math.Inf(-1)
+		for _, v := range values {
+			res = math.Max(res, v)
+		}
+		return res
+	}
+	return nil
+}
+
+func sum(values []float64) float64 {
+	var res float64
+	for _, v := range values {
+		res += v
+	}
+	return res
+}
+
+// groupByStep groups rows by the key columns, aggregating the other columns
+type groupByStep struct {
+	keys       []string
+	aggregates []aggregate
+}
+
+func newGroupByStep(args []string) (Step, error) {
+	s := &groupByStep{}
+	for _, arg := range args {
+		expr, alias := parseAlias(arg)
+		if !strings.Contains(expr, "(") {
+			s.keys = append(s.keys, expr)
+			continue
+		}
+		function, fnArgs, err := parseCall(expr)
+		if err != nil {
+			return nil, err
+		}
+		a := aggregate{function: function, alias: alias}
+		switch function {
+		case "count":
+			if len(fnArgs) > 1 {
+				return nil, fmt.Errorf("count takes at most one column")
+			}
+			if len(fnArgs) == 1 && fnArgs[0] != "*" {
+				a.column = fnArgs[0]
+			}
+		case "sum", "avg", "min", "max":
+			if len(fnArgs) != 1 {
+				return nil, fmt.Errorf("%s requires a column", function)
+			}
+			a.column = fnArgs[0]
+		default:
+			return nil, fmt.Errorf("unknown aggregate function '%s'", function)
+		}
+		if a.alias == "" {
+			a.alias = function
+			if a.column != "" {
+				a.alias = fmt.Sprintf("%s_%s", function, a.column)
+			}
+		}
+		s.aggregates = append(s.aggregates, a)
+	}
+	if len(s.keys) == 0 {
+		return nil, fmt.Errorf("group_by requires at least one key column")
+	}
+	return s, nil
+}
+
+func (s *groupByStep) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	res := &dashboardtypes.LeafData{}
+	for _, key := range s.keys {
+		col, err := getColumn(data, key)
+		if err != nil {
+			return nil, err
+		}
+		res.Columns = append(res.Columns, &queryresult.ColumnDef{Name: key, DataType: col.DataType})
+	}
+	for _, a := range s.aggregates {
+		if a.column != "" {
+			if _, err := getColumn(data, a.column); err != nil {
+				return nil, err
+			}
+		}
+		res.Columns = append(res.Columns, &queryresult.ColumnDef{Name: a.alias, DataType: numericDataType})
+	}
+
+	// group the rows, preserving the order in which each group is first seen
+	var groupOrder []string
+	groups := make(map[string][]map[string]any)
+	for _, row := range data.Rows {
+		keyParts := make([]string, len(s.keys))
+		for i, key := range s.keys {
+			keyParts[i] = keyString(row[key])
+		}
+		groupKey := strings.Join(keyParts, "\x00")
+		if _, ok := groups[groupKey]; !ok {
+			groupOrder = append(groupOrder, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], row)
+	}
+
+	for _, groupKey := range groupOrder {
+		rows := groups[groupKey]
+		newRow := make(map[string]any, len(res.Columns))
+		for _, key := range s.keys {
+			newRow[key] = rows[0][key]
+		}
+		for _, a := range s.aggregates {
+			newRow[a.alias] = a.apply(rows)
+		}
+		res.Rows = append(res.Rows, newRow)
+	}
+	return res, nil
+}
+
+// topStep sorts rows by a column in descending order and keeps the first n
+type topStep struct {
+	n      int
+	column string
+}
+
+func newTopStep(args []string) (Step, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("top requires a row count and a column")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid row count '%s'", args[0])
+	}
+	return &topStep{n: n, column: args[1]}, nil
+}
+
+func (s *topStep) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	if _, err := getColumn(data, s.column); err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]any, len(data.Rows))
+	copy(rows, data.Rows)
+	// rows with non-numeric values are sorted last
+	sort.SliceStable(rows, func(i, j int) bool {
+		vi, iOk := toFloat(rows[i][s.column])
+		vj, jOk := toFloat(rows[j][s.column])
+		if iOk != jOk {
+			return iOk
+		}
+		return vi > vj
+	})
+	if len(rows) > s.n {
+		rows = rows[:s.n]
+	}
+	return &dashboardtypes.LeafData{Columns: data.Columns, Rows: rows}, nil
+}
+
+// percentStep adds a column with the percentage of the column total for each row
+type percentStep struct {
+	column string
+}
+
+func newPercentStep(args []string) (Step, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("percent requires a column")
+	}
+	return &percentStep{column: args[0]}, nil
+}
+
+func (s *percentStep) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	if _, err := getColumn(data, s.column); err != nil {
+		return nil, err
+	}
+	percentColumn := s.column + "_percent"
+
+	var total float64
+	for _, row := range data.Rows {
+		if f, ok := toFloat(row[s.column]); ok {
+			total += f
+		}
+	}
+
+	res := &dashboardtypes.LeafData{
+		Columns: append(append([]*queryresult.ColumnDef{}, data.Columns...), &queryresult.ColumnDef{Name: percentColumn, DataType: numericDataType}),
+		Rows:    make([]map[string]any, len(data.Rows)),
+	}
+	for i, row := range data.Rows {
+		newRow := make(map[string]any, len(row)+1)
+		for k, v := range row {
+			newRow[k] = v
+		}
+		if f, ok := toFloat(row[s.column]); ok && total != 0 {
+			newRow[percentColumn] = f / total * 100
+		} else {
+			newRow[percentColumn] = nil
+		}
+		res.Rows[i] = newRow
+	}
+	return res, nil
+}
+
+// pivotStep pivots the values of a column into columns
+type pivotStep struct {
+	rowColumn   string
+	pivotColumn string
+	valueColumn string
+}
+
+func newPivotStep(args []string) (Step, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("pivot requires a row column, a pivot column and a value column")
+	}
+	return &pivotStep{rowColumn: args[0], pivotColumn: args[1], valueColumn: args[2]}, nil
+}
+
+func (s *pivotStep) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	rowCol, err := getColumn(data, s.rowColumn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getColumn(data, s.pivotColumn); err != nil {
+		return nil, err
+	}
+	valueCol, err := getColumn(data, s.valueColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dashboardtypes.LeafData{
+		Columns: []*queryresult.ColumnDef{{Name: s.rowColumn, DataType: rowCol.DataType}},
+	}
+	// build the rows and columns in the order in which they are first seen
+	rowsByKey := make(map[string]map[string]any)
+	seenColumns := map[string]bool{s.rowColumn: true}
+	for _, row := range data.Rows {
+		rowKey := keyString(row[s.rowColumn])
+		newRow, ok := rowsByKey[rowKey]
+		if !ok {
+			newRow = map[string]any{s.rowColumn: row[s.rowColumn]}
+			rowsByKey[rowKey] = newRow
+			res.Rows = append(res.Rows, newRow)
+		}
+		columnName := keyString(row[s.pivotColumn])
+		if !seenColumns[columnName] {
+			seenColumns[columnName] = true
+			res.Columns = append(res.Columns, &queryresult.ColumnDef{Name: columnName, DataType: valueCol.DataType})
+		}
+		newRow[columnName] = row[s.valueColumn]
+	}
+	// ensure every row has a value for every column
+	for _, row := range res.Rows {
+		for _, col := range res.Columns {
+			if _, ok := row[col.Name]; !ok {
+				row[col.Name] = nil
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,189 @@
+// Package dashboardtransform reshapes the results of a panel query before they are rendered.
+//
+// Transforms are set on a panel using the "powerpipe:transform" tag option, as a pipeline of steps separated by "|":
+//
+//	chart "cost_by_region" {
+//	  sql  = query.cost.sql
+//	  tags = {
+//	    "powerpipe:transform" = "group_by(region, sum(cost) as total) | top(5, total) | percent(total)"
+//	  }
+//	}
+//
+// The supported steps are:
+//
+//	select(col, col as alias, ...)         keep (and optionally rename) the given columns
+//	group_by(col, ..., agg(col) as alias)  group rows by the key columns, aggregating with count, sum, avg, min or max
+//	top(n, col)                            sort by the column in descending order and keep the first n rows
+//	percent(col)                           add a <col>_percent column with the percentage of the column total
+//	pivot(row_col, column_col, value_col)  pivot the values of column_col into columns
+package dashboardtransform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// Step is a single transform of the data
+type Step interface {
+	Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error)
+}
+
+// Pipeline is an ordered list of transform steps
+type Pipeline []Step
+
+// Apply applies each step of the pipeline in turn, returning the transformed data
+func (p Pipeline) Apply(data *dashboardtypes.LeafData) (*dashboardtypes.LeafData, error) {
+	var err error
+	for _, step := range p {
+		data, err = step.Apply(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Parse parses a transform pipeline
+func Parse(transform string) (Pipeline, error) {
+	var pipeline Pipeline
+	for _, stepString := range splitTopLevel(transform, '|') {
+		stepString = strings.TrimSpace(stepString)
+		if stepString == "" {
+			continue
+		}
+		step, err := parseStep(stepString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform step '%s': %w", stepString, err)
+		}
+		pipeline = append(pipeline, step)
+	}
+	return pipeline, nil
+}
+
+func parseStep(stepString string) (Step, error) {
+	name, args, err := parseCall(stepString)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "select":
+		return newSelectStep(args)
+	case "group_by":
+		return newGroupByStep(args)
+	case "top":
+		return newTopStep(args)
+	case "percent":
+		return newPercentStep(args)
+	case "pivot":
+		return newPivotStep(args)
+	default:
+		return nil, fmt.Errorf("unknown step '%s'", name)
+	}
+}
+
+// parseCall parses a string of the form name(arg, arg, ...)
+func parseCall(s string) (string, []string, error) {
+	open := strings.Index(s, "(")
+	if open == -1 || !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("expected <name>(<args>)")
+	}
+	name := strings.TrimSpace(s[:open])
+	var args []string
+	for _, arg := range splitTopLevel(s[open+1:len(s)-1], ',') {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			args = append(args, arg)
+		}
+	}
+	return name, args, nil
+}
+
+// parseAlias parses a string of the form "expr as alias", returning the expression and alias
+// (if there is no alias, the alias is empty)
+func parseAlias(s string) (string, string) {
+	parts := strings.Fields(s)
+	if len(parts) >= 3 && strings.EqualFold(parts[len(parts)-2], "as") {
+		return strings.Join(parts[:len(parts)-2], " "), parts[len(parts)-1]
+	}
+	return s, ""
+}
+
+// splitTopLevel splits the string on the separator, ignoring separators inside parentheses
+func splitTopLevel(s string, sep rune) []string {
+	var res []string
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				res = append(res, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, s[start:])
+}
+
+func getColumn(data *dashboardtypes.LeafData, name string) (*queryresult.ColumnDef, error) {
+	for _, c := range data.Columns {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("column '%s' not found", name)
+}
+
+// toFloat converts a numeric value to a float64
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	case fmt.Stringer:
+		f, err := strconv.ParseFloat(n.String(), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// keyString returns a string representation of a value, for use as a map key
+func keyString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,123 @@
+package dashboardtransform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+type transformTest struct {
+	transform       string
+	expectedColumns []string
+	expectedRows    []map[string]any
+	expectedErr     bool
+}
+
+func testData() *dashboardtypes.LeafData {
+	return &dashboardtypes.LeafData{
+		Columns: []*queryresult.ColumnDef{
+			{Name: "region", DataType: "TEXT"},
+			{Name: "service", DataType: "TEXT"},
+			{Name: "cost", DataType: "INT8"},
+		},
+		Rows: []map[string]any{
+			{"region": "us", "service": "ec2", "cost": int64(10)},
+			{"region": "eu", "service": "ec2", "cost": int64(20)},
+			{"region": "us", "service": "s3", "cost": int64(30)},
+			{"region": "ap", "service": "s3", "cost": int64(40)},
+		},
+	}
+}
+
+func testCasesTransform() map[string]transformTest {
+	return map[string]transformTest{
+		"select": {
+			transform:       "select(service, cost as amount)",
+			expectedColumns: []string{"service", "amount"},
+			expectedRows: []map[string]any{
+				{"service": "ec2", "amount": int64(10)},
+				{"service": "ec2", "amount": int64(20)},
+				{"service": "s3", "amount": int64(30)},
+				{"service": "s3", "amount": int64(40)},
+			},
+		},
+		"group_by": {
+			transform:       "group_by(region, sum(cost) as total, count(*))",
+			expectedColumns: []string{"region", "total", "count"},
+			expectedRows: []map[string]any{
+				{"region": "us", "total": float64(40), "count": int64(2)},
+				{"region": "eu", "total": float64(20), "count": int64(1)},
+				{"region": "ap", "total": float64(40), "count": int64(1)},
+			},
+		},
+		"top": {
+			transform:       "top(2, cost) | select(region)",
+			expectedColumns: []string{"region"},
+			expectedRows: []map[string]any{
+				{"region": "ap"},
+				{"region": "us"},
+			},
+		},
+		"percent": {
+			transform:       "group_by(service, sum(cost) as total) | percent(total)",
+			expectedColumns: []string{"service", "total", "total_percent"},
+			expectedRows: []map[string]any{
+				{"service": "ec2", "total": float64(30), "total_percent": float64(30)},
+				{"service": "s3", "total": float64(70), "total_percent": float64(70)},
+			},
+		},
+		"pivot": {
+			transform:       "pivot(region, service, cost)",
+			expectedColumns: []string{"region", "ec2", "s3"},
+			expectedRows: []map[string]any{
+				{"region": "us", "ec2": int64(10), "s3": int64(30)},
+				{"region": "eu", "ec2": int64(20), "s3": nil},
+				{"region": "ap", "ec2": nil, "s3": int64(40)},
+			},
+		},
+		"unknown step": {
+			transform:   "sort(cost)",
+			expectedErr: true,
+		},
+		"unknown column": {
+			transform:   "select(owner)",
+			expectedErr: true,
+		},
+		"unknown aggregate": {
+			transform:   "group_by(region, median(cost))",
+			expectedErr: true,
+		},
+	}
+}
+
+func TestTransform(t *testing.T) {
+	for name, test := range testCasesTransform() {
+		pipeline, err := Parse(test.transform)
+		var res *dashboardtypes.LeafData
+		if err == nil {
+			res, err = pipeline.Apply(testData())
+		}
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("Test: '%s' FAILED : expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %v", name, err)
+			continue
+		}
+		var columns []string
+		for _, c := range res.Columns {
+			columns = append(columns, c.Name)
+		}
+		if !reflect.DeepEqual(columns, test.expectedColumns) {
+			t.Errorf("Test: '%s' FAILED : expected columns %v, got %v", name, test.expectedColumns, columns)
+		}
+		if !reflect.DeepEqual(res.Rows, test.expectedRows) {
+			t.Errorf("Test: '%s' FAILED : expected rows %v, got %v", name, test.expectedRows, res.Rows)
+		}
+	}
+}
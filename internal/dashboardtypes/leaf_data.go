@@ -10,6 +10,18 @@ import (
 type LeafData struct {
 	Columns []*queryresult.ColumnDef `json:"columns"`
 	Rows    []map[string]interface{} `json:"rows"`
+	// set if the rows are a single page of the query results
+	Pagination *LeafDataPagination `json:"pagination,omitempty"`
+}
+
+// LeafDataPagination describes the page of query results contained in a LeafData
+type LeafDataPagination struct {
+	// the zero-based page index
+	Page          int    `json:"page"`
+	PageSize      int    `json:"page_size"`
+	TotalRows     int64  `json:"total_rows"`
+	SortColumn    string `json:"sort_column,omitempty"`
+	SortDirection string `json:"sort_direction,omitempty"`
 }
 
 func NewLeafData(result *localqueryresult.SyncQueryResult) (*LeafData, error) {
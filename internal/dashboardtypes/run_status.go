@@ -9,10 +9,12 @@ const (
 	RunComplete    RunStatus = "complete"
 	RunError       RunStatus = "error"
 	RunCanceled    RunStatus = "canceled"
+	// RunTimeout is the status of a run which failed because a query (or the execution) timed out
+	RunTimeout RunStatus = "timeout"
 )
 
 func (s RunStatus) IsError() bool {
-	return s == RunError || s == RunCanceled
+	return s == RunError || s == RunCanceled || s == RunTimeout
 }
 
 func (s RunStatus) IsFinished() bool {
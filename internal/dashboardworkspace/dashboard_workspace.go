@@ -2,8 +2,18 @@ package dashboardworkspace
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/filewatcher"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/workspace"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
@@ -16,6 +26,14 @@ type WorkspaceEvents struct {
 	dashboardEventHandlers []dashboardevents.DashboardEventHandler
 	// channel used to send dashboard events to the handleDashboardEvent goroutine
 	dashboardEventChan chan dashboardevents.DashboardEvent
+	// watchers for the files which are not watched by the workspace file watcher
+	watchers []*filewatcher.FileWatcher
+	// the watcher for the files read by the mod files, and the paths it watches - it is recreated when the
+	// referenced files change
+	referencedFilesWatcher *filewatcher.FileWatcher
+	referencedFiles        []string
+	watchReferencedFiles   bool
+	referencedFilesLock    sync.Mutex
 }
 
 func NewWorkspaceEvents(workspace *workspace.Workspace) *WorkspaceEvents {
@@ -27,12 +45,24 @@ func NewWorkspaceEvents(workspace *workspace.Workspace) *WorkspaceEvents {
 		w.PublishDashboardEvent(ctx, &dashboardevents.WorkspaceError{Error: err})
 	}
 	w.OnFileWatcherEvent = func(ctx context.Context, resourceMaps, prevResourceMaps *modconfig.ResourceMaps) {
+		_ = w.updateReferencedFilesWatcher(ctx, resourceMaps)
 		w.raiseDashboardChangedEvents(ctx, resourceMaps, prevResourceMaps)
 	}
 	return w
 }
+
 func (w *WorkspaceEvents) Close() {
 	w.Workspace.Close()
+	for _, watcher := range w.watchers {
+		watcher.Close()
+	}
+	w.referencedFilesLock.Lock()
+	w.watchReferencedFiles = false
+	if w.referencedFilesWatcher != nil {
+		w.referencedFilesWatcher.Close()
+		w.referencedFilesWatcher = nil
+	}
+	w.referencedFilesLock.Unlock()
 	if ch := w.dashboardEventChan; ch != nil {
 		// NOTE: set nil first
 		w.dashboardEventChan = nil
@@ -40,3 +70,121 @@ func (w *WorkspaceEvents) Close() {
 		close(ch)
 	}
 }
+
+// WatchReferencedFiles watches the files read by the mod files using the file functions (e.g. sql = file("x.sql")),
+// reloading the workspace and raising dashboard changed events when they change - the workspace file watcher only
+// watches mod files. The files read by dependency mods are watched as well, resolved relative to the dependency mod
+// which reads them. The watched files are updated whenever the workspace is reloaded.
+// SetupWatcher must be called first, as errors reloading the workspace are reported using its error handler.
+func (w *WorkspaceEvents) WatchReferencedFiles(ctx context.Context) error {
+	w.referencedFilesLock.Lock()
+	w.watchReferencedFiles = true
+	w.referencedFilesLock.Unlock()
+	return w.updateReferencedFilesWatcher(ctx, w.GetResourceMaps())
+}
+
+// WatchDependencyMods watches the installed dependency mods for changes, reloading the workspace and raising dashboard
+// changed events when they change. This is intended for local development of dependencies - dependencies replaced by
+// local directories (see mod install --replace) are linked to the local files, so edits to them are picked up.
+// SetupWatcher must be called first, as errors reloading the workspace are reported using its error handler.
+func (w *WorkspaceEvents) WatchDependencyMods(ctx context.Context) error {
+	modsPath := filepaths.WorkspaceModPath(w.Path)
+	if _, err := os.Stat(modsPath); err != nil {
+		// there are no installed dependencies
+		return nil
+	}
+	watcher, err := filewatcher.NewWatcher(&filewatcher.WatcherOptions{
+		Directories: []string{modsPath},
+		Include:     filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+		Exclude:     []string{fmt.Sprintf("%s/.*", modsPath), fmt.Sprintf("%s/.*/**", modsPath)},
+		ListFlag:    filehelpers.FilesRecursive,
+		EventMask:   watchedFileEvents,
+		OnChange: func(events []fsnotify.Event) {
+			w.handleWatchedFileChange(ctx)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	w.watchers = append(w.watchers, watcher)
+	watcher.Start()
+	return nil
+}
+
+// the file events which cause the workspace to be reloaded
+const watchedFileEvents = fsnotify.Create | fsnotify.Remove | fsnotify.Rename | fsnotify.Write
+
+// updateReferencedFilesWatcher replaces the watcher for the files read by the mod files if the set of referenced files
+// has changed (if WatchReferencedFiles has been called)
+func (w *WorkspaceEvents) updateReferencedFilesWatcher(ctx context.Context, resourceMaps *modconfig.ResourceMaps) error {
+	w.referencedFilesLock.Lock()
+	defer w.referencedFilesLock.Unlock()
+	if !w.watchReferencedFiles || resourceMaps == nil {
+		return nil
+	}
+
+	paths, err := referencedFilePaths(resourceMaps.Mods)
+	if err != nil {
+		slog.Warn("failed to resolve the files read by the mod files", "error", err)
+		return err
+	}
+	if w.referencedFilesWatcher != nil && reflect.DeepEqual(paths, w.referencedFiles) {
+		return nil
+	}
+	if w.referencedFilesWatcher != nil {
+		// NOTE: close asynchronously - this may be called from the change handler of the watcher being closed, which
+		// holds the watcher's handler lock
+		go w.referencedFilesWatcher.Close()
+		w.referencedFilesWatcher = nil
+	}
+	w.referencedFiles = paths
+	if len(paths) == 0 {
+		return nil
+	}
+	watcher, err := watchFiles(paths, func() { w.handleWatchedFileChange(ctx) })
+	if err != nil {
+		return err
+	}
+	w.referencedFilesWatcher = watcher
+	return nil
+}
+
+// watchFiles starts a watcher for the given files, calling onChange when any of them is created, changed or removed
+// NOTE: the folders containing the files are watched, so files which do not exist yet are watched once created
+func watchFiles(paths []string, onChange func()) (*filewatcher.FileWatcher, error) {
+	dirs := map[string]bool{}
+	for _, path := range paths {
+		dirs[filepath.Dir(path)] = true
+	}
+	var directories []string
+	for dir := range dirs {
+		directories = append(directories, dir)
+	}
+	watcher, err := filewatcher.NewWatcher(&filewatcher.WatcherOptions{
+		Directories: directories,
+		// the paths are absolute, so match the files exactly
+		Include:   paths,
+		ListFlag:  filehelpers.FilesFlat,
+		EventMask: watchedFileEvents,
+		OnChange: func(events []fsnotify.Event) {
+			onChange()
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	watcher.Start()
+	return watcher, nil
+}
+
+func (w *WorkspaceEvents) handleWatchedFileChange(ctx context.Context) {
+	slog.Debug("handleWatchedFileChange")
+	prevResourceMaps, resourceMaps, errAndWarnings := w.ReloadResourceMaps(ctx)
+	if err := errAndWarnings.GetError(); err != nil {
+		w.OnFileWatcherError(ctx, err)
+		return
+	}
+	// the files read by the mod files may have changed
+	_ = w.updateReferencedFilesWatcher(ctx, resourceMaps)
+	w.raiseDashboardChangedEvents(ctx, resourceMaps, prevResourceMaps)
+}
@@ -0,0 +1,95 @@
+package dashboardworkspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// the functions which read the content of a file, e.g. sql = file("sql/query.sql")
+var fileFunctions = map[string]bool{
+	"file":       true,
+	"filebase64": true,
+}
+
+// referencedFilePaths returns the absolute paths of the files read by the mod files of the given mods using the file
+// functions. Relative paths are resolved against the path of the mod which references them, as they are when the
+// mod is parsed, so the files read by dependency mods resolve to their installation location.
+// References whose path is not a constant (e.g. a path built from a variable) cannot be resolved and are ignored.
+func referencedFilePaths(mods map[string]*modconfig.Mod) ([]string, error) {
+	paths := map[string]bool{}
+	for _, mod := range mods {
+		if mod.ModPath == "" {
+			continue
+		}
+		modFiles, err := filehelpers.ListFiles(mod.ModPath, &filehelpers.ListOptions{
+			Flags:   filehelpers.FilesRecursive,
+			Include: filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+			// exclude hidden files and folders - this excludes the dependency mods installed in the mod folder,
+			// which are listed separately
+			Exclude: []string{fmt.Sprintf("%s/.*", mod.ModPath), fmt.Sprintf("%s/.*/**", mod.ModPath)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, modFile := range modFiles {
+			refs, err := fileReferences(modFile)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range refs {
+				if !filepath.IsAbs(ref) {
+					ref = filepath.Join(mod.ModPath, ref)
+				}
+				paths[filepath.Clean(ref)] = true
+			}
+		}
+	}
+
+	var res []string
+	for path := range paths {
+		res = append(res, path)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// fileReferences returns the constant paths passed to the file functions in the given mod file
+// NOTE: files which fail to parse are ignored - the parse errors are reported when the workspace is loaded
+func fileReferences(modFile string) ([]string, error) {
+	content, err := os.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	file, diags := hclsyntax.ParseConfig(content, modFile, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+
+	var res []string
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		call, ok := node.(*hclsyntax.FunctionCallExpr)
+		if !ok || !fileFunctions[call.Name] || len(call.Args) != 1 {
+			return nil
+		}
+		val, diags := call.Args[0].Value(nil)
+		if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+			return nil
+		}
+		res = append(res, val.AsString())
+		return nil
+	})
+	return res, nil
+}
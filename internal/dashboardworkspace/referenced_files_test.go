@@ -0,0 +1,149 @@
+package dashboardworkspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReferencedFilePaths(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+
+	workspacePath := t.TempDir()
+	depPath := filepath.Join(workspacePath, ".powerpipe", "mods", "github.com", "acme", "dep@v1.0.0")
+	absolutePath := filepath.Join(t.TempDir(), "shared.sql")
+
+	tests := map[string]struct {
+		files    map[string]string
+		mods     []string
+		expected []string
+	}{
+		"relative to the mod": {
+			files: map[string]string{
+				"queries/queries.pp": `query "a" {
+  sql = file("sql/a.sql")
+}`,
+			},
+			mods:     []string{workspacePath},
+			expected: []string{filepath.Join(workspacePath, "sql", "a.sql")},
+		},
+		"relative to the dependency mod": {
+			files: map[string]string{
+				"mod.pp": `query "a" {
+  sql = file("sql/a.sql")
+}`,
+				".powerpipe/mods/github.com/acme/dep@v1.0.0/mod.pp": `control "b" {
+  sql = file("./sql/../b.sql")
+}`,
+			},
+			mods: []string{workspacePath, depPath},
+			expected: []string{
+				filepath.Join(depPath, "b.sql"),
+				filepath.Join(workspacePath, "sql", "a.sql"),
+			},
+		},
+		"dependency mods are not listed with the workspace mod": {
+			files: map[string]string{
+				".powerpipe/mods/github.com/acme/dep@v1.0.0/mod.pp": `query "b" {
+  sql = file("b.sql")
+}`,
+			},
+			mods: []string{workspacePath},
+		},
+		"absolute and nested": {
+			files: map[string]string{
+				"mod.sp": `dashboard "d" {
+  card {
+    sql = file("` + absolutePath + `")
+  }
+  text {
+    value = filebase64("logo.png")
+  }
+}`,
+			},
+			mods:     []string{workspacePath},
+			expected: []string{absolutePath, filepath.Join(workspacePath, "logo.png")},
+		},
+		"non constant and unparseable": {
+			files: map[string]string{
+				"mod.pp": `query "a" {
+  sql = file("${var.dir}/a.sql")
+}
+query "b" {
+  sql = templatefile("b.sql", {})
+}`,
+				"broken.pp": `query "c" {`,
+			},
+			mods: []string{workspacePath},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := os.RemoveAll(workspacePath); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFiles(t, workspacePath, tc.files)
+			mods := map[string]*modconfig.Mod{}
+			for _, modPath := range tc.mods {
+				mods[modPath] = modconfig.NewMod(filepath.Base(modPath), modPath, hcl.Range{})
+			}
+
+			got, err := referencedFilePaths(mods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(tc.expected)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	referenced := filepath.Join(dir, "a.sql")
+	writeTestFiles(t, dir, map[string]string{"a.sql": "select 1", "other.sql": "select 2"})
+
+	changes := make(chan struct{}, 10)
+	watcher, err := watchFiles([]string{referenced}, func() { changes <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	// a file which is not referenced does not raise a change
+	writeTestFiles(t, dir, map[string]string{"other.sql": "select 3"})
+	select {
+	case <-changes:
+		t.Fatal("expected no change for a file which is not referenced")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	writeTestFiles(t, dir, map[string]string{"a.sql": "select 4"})
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change for the referenced file")
+	}
+}
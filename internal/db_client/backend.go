@@ -0,0 +1,57 @@
+package db_client
+
+import (
+	"context"
+
+	"github.com/turbot/pipe-fittings/backend"
+)
+
+// HasBackend returns whether the string is a connection string for a supported database (rather than the name of a
+// Turbot Pipes workspace database)
+func HasBackend(connectionString string) bool {
+	return isMySQLConnectionString(connectionString) ||
+		isClickHouseConnectionString(connectionString) ||
+		isBigQueryConnectionString(connectionString) ||
+		isSnowflakeConnectionString(connectionString) ||
+		isTrinoConnectionString(connectionString) ||
+		backend.HasBackend(connectionString)
+}
+
+// newBackend returns the backend for the connection string, normalising connection strings which are not in the
+// form expected by the pipe-fittings backends and applying the query timeout and read-only mode to the session where
+// supported
+func newBackend(ctx context.Context, connectionString string) (backend.Backend, error) {
+	connectionString = withStatementTimeout(connectionString, queryTimeout())
+	connectionString = withReadOnlySession(connectionString)
+
+	if isClickHouseConnectionString(connectionString) {
+		return NewClickHouseBackend(connectionString), nil
+	}
+	if isBigQueryConnectionString(connectionString) {
+		return NewBigQueryBackend(connectionString), nil
+	}
+	if isSnowflakeConnectionString(connectionString) {
+		return NewSnowflakeBackend(connectionString), nil
+	}
+	if isTrinoConnectionString(connectionString) {
+		return NewTrinoBackend(connectionString), nil
+	}
+	if isMySQLConnectionString(connectionString) {
+		mysqlConnectionString, err := mysqlConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+		return backend.FromConnectionString(ctx, mysqlConnectionString)
+	}
+	if isPostgresIAMConnectionString(connectionString) {
+		return newPostgresIAMBackend(ctx, connectionString)
+	}
+	if backend.IsPostgresConnectionString(connectionString) {
+		postgresConnectionString, err := postgresSSLConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+		return backend.FromConnectionString(ctx, postgresConnectionString)
+	}
+	return backend.FromConnectionString(ctx, connectionString)
+}
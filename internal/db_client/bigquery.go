@@ -0,0 +1,131 @@
+package db_client
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/backend"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"google.golang.org/api/option"
+)
+
+const bigQueryConnectionStringPrefix = "bigquery://"
+
+// isBigQueryConnectionString returns whether the connection string is for BigQuery
+func isBigQueryConnectionString(connectionString string) bool {
+	return strings.HasPrefix(connectionString, bigQueryConnectionStringPrefix)
+}
+
+// BigQueryBackend is a backend for BigQuery, which is queried using the BigQuery client. The connection string is of
+// the form bigquery://project/dataset?credentials=/path/to/key.json&location=EU - the dataset is the default dataset
+// for unqualified table names, and if no service account key file is given, Application Default Credentials are used.
+type BigQueryBackend struct {
+	connectionString string
+	rowReader        backend.RowReader
+	// additional options for the BigQuery client, e.g. to use a different endpoint
+	clientOptions []option.ClientOption
+}
+
+func NewBigQueryBackend(connectionString string) *BigQueryBackend {
+	return &BigQueryBackend{
+		connectionString: strings.TrimSpace(connectionString),
+		// values are converted by the driver
+		rowReader: backend.NewBasicRowReader(),
+	}
+}
+
+// Connect implements Backend.
+func (b *BigQueryBackend) Connect(ctx context.Context, options ...backend.ConnectOption) (*sql.DB, error) {
+	config := backend.NewConnectConfig(options)
+	bigQueryConfig, err := parseBigQueryConnectionString(b.connectionString)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "could not connect to bigquery backend")
+	}
+	connector, err := newBigQueryConnector(ctx, bigQueryConfig, b.clientOptions...)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "could not connect to bigquery backend")
+	}
+	db := sql.OpenDB(connector)
+	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
+	db.SetConnMaxLifetime(config.MaxConnLifeTime)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	return db, nil
+}
+
+func (b *BigQueryBackend) ConnectionString() string {
+	return b.connectionString
+}
+
+func (b *BigQueryBackend) Name() string {
+	return localconstants.BigQueryBackendName
+}
+
+// RowReader implements Backend.
+func (b *BigQueryBackend) RowReader() backend.RowReader {
+	return b.rowReader
+}
+
+type bigQueryConfig struct {
+	project string
+	dataset string
+	// the location to run queries in, e.g. EU - if not set, BigQuery infers it from the tables referenced
+	location string
+	// the path to a service account key file - if not set, Application Default Credentials are used
+	credentialsFile string
+}
+
+func parseBigQueryConnectionString(connectionString string) (*bigQueryConfig, error) {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BigQuery connection string: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid BigQuery connection string: no project")
+	}
+	query := u.Query()
+	return &bigQueryConfig{
+		project:         u.Host,
+		dataset:         strings.Trim(u.Path, "/"),
+		location:        query.Get("location"),
+		credentialsFile: query.Get("credentials"),
+	}, nil
+}
+
+// bigQueryColumnTypes maps BigQuery column types to the equivalent Postgres types
+var bigQueryColumnTypes = map[string]string{
+	"INTEGER":    "INT8",
+	"INT64":      "INT8",
+	"FLOAT":      "FLOAT8",
+	"FLOAT64":    "FLOAT8",
+	"NUMERIC":    "NUMERIC",
+	"BIGNUMERIC": "NUMERIC",
+	"BOOLEAN":    "BOOL",
+	"BOOL":       "BOOL",
+	"STRING":     "TEXT",
+	"GEOGRAPHY":  "TEXT",
+	"BYTES":      "BYTEA",
+	"TIMESTAMP":  "TIMESTAMPTZ",
+	"DATETIME":   "TIMESTAMP",
+	"DATE":       "DATE",
+	"TIME":       "TIME",
+	"INTERVAL":   "INTERVAL",
+	"JSON":       "JSONB",
+	"RECORD":     "JSONB",
+	"STRUCT":     "JSONB",
+}
+
+// bigQueryColumnType returns the Postgres type equivalent to the BigQuery column type - repeated columns are returned
+// as JSONB
+func bigQueryColumnType(dataType string) string {
+	if strings.HasPrefix(dataType, "ARRAY<") {
+		return "JSONB"
+	}
+	if t, ok := bigQueryColumnTypes[dataType]; ok {
+		return t
+	}
+	return dataType
+}
@@ -0,0 +1,265 @@
+package db_client
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// the maximum time to wait for a cancelled job to be cancelled
+const bigQueryCancelTimeout = 10 * time.Second
+
+// bigQueryConnector is a database/sql connector for BigQuery, which runs queries as jobs with the BigQuery client.
+//
+// Queries are run with standard SQL and positional parameters, and the values of each row are converted according
+// to the result schema. If the context of a query is cancelled, its job is cancelled.
+type bigQueryConnector struct {
+	config *bigQueryConfig
+	client *bigquery.Client
+}
+
+func newBigQueryConnector(ctx context.Context, config *bigQueryConfig, opts ...option.ClientOption) (*bigQueryConnector, error) {
+	if config.credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(config.credentialsFile))
+	}
+	// if no credentials are given, the client uses Application Default Credentials
+	client, err := bigquery.NewClient(ctx, config.project, opts...)
+	if err != nil {
+		return nil, err
+	}
+	client.Location = config.location
+	return &bigQueryConnector{config: config, client: client}, nil
+}
+
+func (c *bigQueryConnector) Connect(context.Context) (driver.Conn, error) {
+	return &bigQueryConn{config: c.config, client: c.client}, nil
+}
+
+func (c *bigQueryConnector) Driver() driver.Driver {
+	return bigQueryDriver{}
+}
+
+// Close closes the client - it is called when the sql.DB is closed
+func (c *bigQueryConnector) Close() error {
+	return c.client.Close()
+}
+
+type bigQueryDriver struct{}
+
+func (bigQueryDriver) Open(connectionString string) (driver.Conn, error) {
+	config, err := parseBigQueryConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := newBigQueryConnector(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+type bigQueryConn struct {
+	config *bigQueryConfig
+	client *bigquery.Client
+}
+
+func (c *bigQueryConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("BigQuery does not support prepared statements")
+}
+
+func (c *bigQueryConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("BigQuery does not support transactions")
+}
+
+func (c *bigQueryConn) Close() error {
+	return nil
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *bigQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, rows.Close()
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *bigQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q := c.client.Query(query)
+	q.Parameters = bigQueryParams(args)
+	if c.config.dataset != "" {
+		q.DefaultProjectID = c.config.project
+		q.DefaultDatasetID = c.config.dataset
+	}
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// wait for the job to complete - the schema is only returned once it has
+	it, err := job.Read(ctx)
+	if err != nil {
+		cancelIfCancelled(ctx, job)
+		return nil, err
+	}
+	return &bigQueryRows{ctx: ctx, job: job, it: it}, nil
+}
+
+// cancelIfCancelled cancels the job if the context has been cancelled - BigQuery continues to run the job otherwise
+func cancelIfCancelled(ctx context.Context, job *bigquery.Job) {
+	if ctx.Err() == nil {
+		return
+	}
+	slog.Debug("cancelling BigQuery job", "job_id", job.ID())
+	cancelCtx, cancel := context.WithTimeout(context.Background(), bigQueryCancelTimeout)
+	defer cancel()
+	if err := job.Cancel(cancelCtx); err != nil {
+		slog.Warn("failed to cancel BigQuery job", "job_id", job.ID(), "error", err)
+	}
+}
+
+// bigQueryParams returns the positional query parameters for the args - the client infers the parameter types from
+// the Go types of the values
+func bigQueryParams(args []driver.NamedValue) []bigquery.QueryParameter {
+	params := make([]bigquery.QueryParameter, len(args))
+	for i, arg := range args {
+		params[i].Value = arg.Value
+		if arg.Value == nil {
+			params[i].Value = bigquery.NullString{}
+		}
+	}
+	return params
+}
+
+type bigQueryRows struct {
+	ctx  context.Context
+	job  *bigquery.Job
+	it   *bigquery.RowIterator
+	done bool
+}
+
+func (r *bigQueryRows) Columns() []string {
+	res := make([]string, len(r.it.Schema))
+	for i, f := range r.it.Schema {
+		res[i] = f.Name
+	}
+	return res
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName
+func (r *bigQueryRows) ColumnTypeDatabaseTypeName(index int) string {
+	f := r.it.Schema[index]
+	if f.Repeated {
+		return "ARRAY<" + string(f.Type) + ">"
+	}
+	return string(f.Type)
+}
+
+func (r *bigQueryRows) Close() error {
+	// if the rows were not fully read because the query was cancelled, cancel the job
+	if !r.done {
+		cancelIfCancelled(r.ctx, r.job)
+	}
+	return nil
+}
+
+func (r *bigQueryRows) Next(dest []driver.Value) error {
+	var row []bigquery.Value
+	if err := r.it.Next(&row); err != nil {
+		if errors.Is(err, iterator.Done) {
+			r.done = true
+			return io.EOF
+		}
+		cancelIfCancelled(r.ctx, r.job)
+		return err
+	}
+
+	for i, field := range r.it.Schema {
+		var cell bigquery.Value
+		if i < len(row) {
+			cell = row[i]
+		}
+		value, err := bigQueryValue(cell, field)
+		if err != nil {
+			return fmt.Errorf("failed to read column %s: %w", field.Name, err)
+		}
+		dest[i] = value
+	}
+	return nil
+}
+
+// bigQueryValue converts a value returned by the client into a Go value according to the field schema. Records are
+// returned by the client as a list of their field values, and are converted to maps keyed by field name.
+func bigQueryValue(v bigquery.Value, field *bigquery.FieldSchema) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if field.Repeated {
+		items, ok := v.([]bigquery.Value)
+		if !ok {
+			return nil, fmt.Errorf("expected a repeated value, got %T", v)
+		}
+		itemField := *field
+		itemField.Repeated = false
+		res := make([]any, len(items))
+		for i, item := range items {
+			value, err := bigQueryValue(item, &itemField)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = value
+		}
+		return res, nil
+	}
+
+	switch value := v.(type) {
+	case []bigquery.Value:
+		if field.Type != bigquery.RecordFieldType {
+			return nil, fmt.Errorf("unexpected list value for a %s column", field.Type)
+		}
+		res := make(map[string]any, len(field.Schema))
+		for i, f := range field.Schema {
+			if i >= len(value) {
+				break
+			}
+			fieldValue, err := bigQueryValue(value[i], f)
+			if err != nil {
+				return nil, err
+			}
+			res[f.Name] = fieldValue
+		}
+		return res, nil
+	case *big.Rat:
+		f, _ := value.Float64()
+		return f, nil
+	case civil.Date:
+		return value.In(time.UTC), nil
+	case civil.DateTime:
+		return value.In(time.UTC), nil
+	case civil.Time:
+		return value.String(), nil
+	case *bigquery.IntervalValue:
+		return value.String(), nil
+	case string:
+		if field.Type == bigquery.JSONFieldType {
+			var res any
+			if err := json.Unmarshal([]byte(value), &res); err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+	}
+	return v, nil
+}
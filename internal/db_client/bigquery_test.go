@@ -0,0 +1,209 @@
+package db_client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestParseBigQueryConnectionString(t *testing.T) {
+	config, err := parseBigQueryConnectionString("bigquery://my-project/warehouse?credentials=/keys/sa.json&location=EU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &bigQueryConfig{project: "my-project", dataset: "warehouse", location: "EU", credentialsFile: "/keys/sa.json"}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("got %+v, expected %+v", config, expected)
+	}
+
+	if _, err := parseBigQueryConnectionString("bigquery:///warehouse"); err == nil {
+		t.Error("expected an error for a connection string with no project")
+	}
+}
+
+// fakeBigQuery is a fake BigQuery REST API, which runs each query job as the given result schema and pages of rows.
+// If running is set, jobs never complete. The last job configuration and any cancelled jobs are recorded.
+type fakeBigQuery struct {
+	schema  string
+	pages   []string
+	running bool
+
+	mu        sync.Mutex
+	query     map[string]any
+	cancelled []string
+}
+
+func (f *fakeBigQuery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/jobs"):
+		var job struct {
+			JobReference  map[string]any `json:"jobReference"`
+			Configuration map[string]any `json:"configuration"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.query, _ = job.Configuration["query"].(map[string]any)
+		job.JobReference["location"] = "EU"
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jobReference":  job.JobReference,
+			"configuration": job.Configuration,
+			"status":        map[string]any{"state": "RUNNING"},
+		})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel"):
+		f.cancelled = append(f.cancelled, path.Base(path.Dir(r.URL.Path)))
+		_, _ = io.WriteString(w, `{}`)
+	case strings.Contains(r.URL.Path, "/queries/"):
+		if f.running {
+			_, _ = io.WriteString(w, `{"jobComplete": false}`)
+			return
+		}
+		page := 0
+		if token := r.URL.Query().Get("pageToken"); token != "" {
+			page, _ = strconv.Atoi(token)
+		}
+		if r.URL.Query().Get("maxResults") == "0" {
+			_, _ = fmt.Fprintf(w, `{"jobComplete": true, "schema": %s}`, f.schema)
+			return
+		}
+		pageToken := ""
+		if page+1 < len(f.pages) {
+			pageToken = strconv.Itoa(page + 1)
+		}
+		_, _ = fmt.Fprintf(w, `{"jobComplete": true, "schema": %s, "rows": %s, "pageToken": %q}`, f.schema, f.pages[page], pageToken)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func connectFakeBigQuery(t *testing.T, fake *fakeBigQuery) *sql.DB {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	b := NewBigQueryBackend("bigquery://p/warehouse")
+	b.clientOptions = []option.ClientOption{option.WithEndpoint(server.URL + "/"), option.WithoutAuthentication()}
+	db, err := b.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBigQueryQuery(t *testing.T) {
+	fake := &fakeBigQuery{
+		schema: `{"fields": [
+			{"name": "id", "type": "INTEGER"},
+			{"name": "ts", "type": "TIMESTAMP"},
+			{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+			{"name": "owner", "type": "RECORD", "fields": [{"name": "name", "type": "STRING"}, {"name": "score", "type": "NUMERIC"}]},
+			{"name": "day", "type": "DATE"},
+			{"name": "doc", "type": "JSON"}
+		]}`,
+		pages: []string{
+			`[{"f": [{"v": "1"}, {"v": "1704164645000000"}, {"v": [{"v": "a"}, {"v": "b"}]}, {"v": {"f": [{"v": "x"}, {"v": "1.5"}]}}, {"v": "2024-01-02"}, {"v": "{\"a\": [1]}"}]}]`,
+			`[{"f": [{"v": "2"}, {"v": null}, {"v": []}, {"v": null}, {"v": null}, {"v": null}]}]`,
+		},
+	}
+	db := connectFakeBigQuery(t, fake)
+
+	rows, err := db.Query("select * from t where id > ? and name = ? and owner = ?", 0, "x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := types[2].DatabaseTypeName(); got != "ARRAY<STRING>" {
+		t.Errorf("got column type %q", got)
+	}
+	var got [][]any
+	for rows.Next() {
+		values := make([]any, len(types))
+		ptrs := make([]any, len(types))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, values)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	expected := [][]any{
+		{int64(1), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), []any{"a", "b"}, map[string]any{"name": "x", "score": 1.5}, day, map[string]any{"a": []any{float64(1)}}},
+		{int64(2), nil, []any{}, nil, nil, nil},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %#v, expected %#v", got, expected)
+	}
+
+	if fake.query["useLegacySql"] != false {
+		t.Errorf("got query configuration %v", fake.query)
+	}
+	if dataset, _ := json.Marshal(fake.query["defaultDataset"]); string(dataset) != `{"datasetId":"warehouse","projectId":"p"}` {
+		t.Errorf("got default dataset %s", dataset)
+	}
+	params, _ := json.Marshal(fake.query["queryParameters"])
+	expectedParams := `[{"parameterType":{"type":"INT64"},"parameterValue":{"value":"0"}},{"parameterType":{"type":"STRING"},"parameterValue":{"value":"x"}},{"parameterType":{"type":"STRING"},"parameterValue":{"value":null}}]`
+	if string(params) != expectedParams {
+		t.Errorf("got params %s, expected %s", params, expectedParams)
+	}
+}
+
+func TestBigQueryQueryCancellation(t *testing.T) {
+	fake := &fakeBigQuery{running: true}
+	db := connectFakeBigQuery(t, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := db.QueryContext(ctx, "select 1"); err == nil {
+		t.Fatal("expected the query to be cancelled")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.cancelled) != 1 {
+		t.Errorf("expected the job to be cancelled, got cancelled jobs %v", fake.cancelled)
+	}
+}
+
+func TestBigQueryColumnType(t *testing.T) {
+	tests := map[string]string{
+		"INTEGER":       "INT8",
+		"TIMESTAMP":     "TIMESTAMPTZ",
+		"RECORD":        "JSONB",
+		"ARRAY<STRING>": "JSONB",
+		"RANGE":         "RANGE",
+	}
+	for input, expected := range tests {
+		if got := bigQueryColumnType(input); got != expected {
+			t.Errorf("bigQueryColumnType(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -23,20 +23,15 @@ func GetDatabaseConfigForResource(resource modconfig.ModTreeItem, workspaceMod *
 			return database, searchPathConfig, sperr.New("could not find mod requirement for '%s' in workspace mod %s", depName, workspaceMod.ShortName)
 		}
 
-		// if the mod requirement has a search path, prefix or database, set it in viper,
-		// overriding whatever value sth, use it
-		// TODO should we only respect overriden search path and search path prefix if the db is overriden?
-		//if modRequirement.OriginalSearchPath != nil {
-		//	searchPathConfig.OriginalSearchPath = modRequirement.OriginalSearchPath
-		//}
-		//if modRequirement.SearchPathPrefix != nil {
-		//	searchPathConfig.SearchPathPrefix = modRequirement.SearchPathPrefix
-		//}
 		if modRequirement.Database != nil {
 			// if database is overriden, also use overriden search path and search path prefix (even if empty)
 			database = *modRequirement.Database
 			searchPathConfig.SearchPath = modRequirement.SearchPath
 			searchPathConfig.SearchPathPrefix = modRequirement.SearchPathPrefix
+		} else if len(modRequirement.SearchPath) > 0 || len(modRequirement.SearchPathPrefix) > 0 {
+			// otherwise the search path or prefix of the mod requirement is used with the default database
+			searchPathConfig.SearchPath = modRequirement.SearchPath
+			searchPathConfig.SearchPathPrefix = modRequirement.SearchPathPrefix
 		}
 	}
 
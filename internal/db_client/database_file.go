@@ -0,0 +1,28 @@
+package db_client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// the connection string of an in-memory SQLite or DuckDB database
+const inMemoryDatabase = ":memory:"
+
+// validateDatabaseFile returns an error if the SQLite or DuckDB database file does not exist - the drivers would
+// otherwise silently create an empty database, so a mistyped path would only show up as missing tables
+func validateDatabaseFile(backendName, connectionString string) error {
+	// strip any uri scheme and configuration parameters, e.g. file:data.db?mode=ro
+	path := strings.TrimPrefix(connectionString, "file:")
+	path, _, _ = strings.Cut(path, "?")
+	if path == "" || path == inMemoryDatabase {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s database file %s does not exist", backendName, path)
+		}
+		return err
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/connection"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 )
 
 // DbClient wraps over `sql.DB` and gives an interface to the database
@@ -19,13 +21,39 @@ type DbClient struct {
 
 	// the Backend
 	Backend backend.Backend
+	// the reader used to convert the values returned by the database driver
+	rowReader backend.RowReader
+	// maps the column types returned by the database driver to the equivalent Postgres types
+	columnType func(string) string
 }
 
 func NewDbClient(ctx context.Context, connectionString string, opts ...backend.ConnectOption) (_ *DbClient, err error) {
 	utils.LogTime("db_client.NewDbClient start")
 	defer utils.LogTime("db_client.NewDbClient end")
 
-	b, err := backend.FromConnectionString(ctx, connectionString)
+	// if the connection string refers to a named connection, use the connection string of the connection
+	namedConnection, err := connection.Resolve(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	backendConnectionString := connectionString
+	if namedConnection != nil {
+		backendConnectionString, err = namedConnection.GetConnectionString(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// if the database is a Turbot Pipes workspace, use the connection string of the workspace database
+	if IsPipesWorkspace(backendConnectionString) {
+		metadata, err := GetPipesWorkspaceMetadata(ctx, backendConnectionString, false)
+		if err != nil {
+			return nil, err
+		}
+		backendConnectionString = metadata.ConnectionString
+	}
+
+	b, err := newBackend(ctx, backendConnectionString)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +61,32 @@ func NewDbClient(ctx context.Context, connectionString string, opts ...backend.C
 	client := &DbClient{
 		connectionString: connectionString,
 		Backend:          b,
+		rowReader:        b.RowReader(),
+		columnType:       func(t string) string { return t },
+	}
+	switch b.Name() {
+	case constants.DuckDBBackendName:
+		if err := validateDatabaseFile(b.Name(), b.ConnectionString()); err != nil {
+			return nil, err
+		}
+		client.rowReader = newDuckDBRowReader()
+		client.columnType = duckDBColumnType
+	case constants.SQLiteBackendName:
+		if err := validateDatabaseFile(b.Name(), b.ConnectionString()); err != nil {
+			return nil, err
+		}
+		client.columnType = sqliteColumnType
+	case constants.MySQLBackendName:
+		client.rowReader = newMySQLRowReader()
+		client.columnType = mysqlColumnType
+	case localconstants.ClickHouseBackendName:
+		client.columnType = clickHouseColumnType
+	case localconstants.BigQueryBackendName:
+		client.columnType = bigQueryColumnType
+	case localconstants.SnowflakeBackendName:
+		client.columnType = snowflakeColumnType
+	case localconstants.TrinoBackendName:
+		client.columnType = trinoColumnType
 	}
 
 	defer func() {
@@ -45,7 +99,11 @@ func NewDbClient(ctx context.Context, connectionString string, opts ...backend.C
 	// process options - searhc path may have been passed in
 	config := backend.NewConnectConfig(opts)
 	config.MaxOpenConns = MaxDbConnections()
-	// if no search path override passed in as an option, use the viper config
+	// if no search path override passed in as an option, use the search path of the named connection
+	if config.SearchPathConfig.Empty() && namedConnection != nil {
+		config.SearchPathConfig = namedConnection.SearchPathConfig()
+	}
+	// otherwise use the viper config
 	if config.SearchPathConfig.Empty() {
 		config.SearchPathConfig = backend.SearchPathConfig{
 			SearchPath:       viper.GetStringSlice(constants.ArgSearchPath),
@@ -54,7 +112,18 @@ func NewDbClient(ctx context.Context, connectionString string, opts ...backend.C
 	}
 
 	if err := client.connect(ctx, backend.WithConfig(config)); err != nil {
-		return nil, err
+		// if the database password of a Pipes workspace has changed, reconnect with the refreshed connection string
+		refreshedConnectionString, ok := refreshPipesWorkspaceConnectionString(ctx, backendConnectionString, err)
+		if !ok {
+			return nil, err
+		}
+		if client.Backend, err = newBackend(ctx, refreshedConnectionString); err != nil {
+			return nil, err
+		}
+		client.rowReader = client.Backend.RowReader()
+		if err := client.connect(ctx, backend.WithConfig(config)); err != nil {
+			return nil, err
+		}
 	}
 
 	return client, nil
@@ -12,6 +12,7 @@ import (
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/pipe-fittings/statushooks"
+	"github.com/turbot/powerpipe/internal/querylog"
 	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -21,43 +22,76 @@ import (
 // NOTE: The returned Result MUST be fully read - otherwise the connection will block and will prevent further communication
 func (c *DbClient) Execute(ctx context.Context, query string, args ...any) (*localqueryresult.Result, error) {
 	// acquire a connection
+	startTime := time.Now()
 	databaseConnection, err := c.db.Conn(ctx)
 	if err != nil {
 		return nil, err
 	}
+	queueTime := time.Since(startTime)
 
 	// define callback to close session when the async execution is complete
 	closeSessionCallback := func() { _ = databaseConnection.Close() }
-	return c.executeOnConnection(ctx, databaseConnection, closeSessionCallback, query, args...)
+	return c.executeOnConnection(ctx, databaseConnection, queueTime, closeSessionCallback, query, args...)
 }
 
 // ExecuteSync executes a query against this client and wait for the result
 func (c *DbClient) ExecuteSync(ctx context.Context, query string, args ...any) (*localqueryresult.SyncQueryResult, error) {
 	// acquire a connection
+	startTime := time.Now()
 	dbConn, err := c.db.Conn(ctx)
 	if err != nil {
 		return nil, err
 	}
+	queueTime := time.Since(startTime)
 
 	defer func() {
 		dbConn.Close()
 
 	}()
-	return c.executeSyncOnConnection(ctx, dbConn, query, args...)
+	return c.executeSyncOnConnection(ctx, dbConn, queueTime, query, args...)
+}
+
+// ExecuteSyncInTransaction executes the setup statements and then the query in a single transaction, waiting for
+// the result. The transaction is always rolled back, so the setup statements may create (temporary) tables and data
+// which are only visible to the query.
+func (c *DbClient) ExecuteSyncInTransaction(ctx context.Context, setup []string, query string, args ...any) (*localqueryresult.SyncQueryResult, error) {
+	startTime := time.Now()
+	dbConn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queueTime := time.Since(startTime)
+	defer dbConn.Close()
+
+	// the transaction is managed with statements rather than a sql.Tx, so the query can be executed on the connection
+	if _, err := dbConn.ExecContext(ctx, "begin"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = dbConn.ExecContext(context.Background(), "rollback")
+	}()
+
+	for _, statement := range setup {
+		if _, err := dbConn.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+	return c.executeSyncOnConnection(ctx, dbConn, queueTime, query, args...)
 }
 
 // execute a query against this client and wait for the result
-func (c *DbClient) executeSyncOnConnection(ctx context.Context, dbConn *sql.Conn, query string, args ...any) (*localqueryresult.SyncQueryResult, error) {
+func (c *DbClient) executeSyncOnConnection(ctx context.Context, dbConn *sql.Conn, queueTime time.Duration, query string, args ...any) (*localqueryresult.SyncQueryResult, error) {
 	if query == "" {
-		return &localqueryresult.SyncQueryResult{}, nil
+		return &localqueryresult.SyncQueryResult{Timing: &localqueryresult.TimingMetadata{Duration: queueTime, Queue: queueTime}}, nil
 	}
 
-	result, err := c.executeOnConnection(ctx, dbConn, nil, query, args...)
+	result, err := c.executeOnConnection(ctx, dbConn, queueTime, nil, query, args...)
 	if err != nil {
 		return nil, error_helpers.WrapError(err)
 	}
 
-	syncResult := &localqueryresult.SyncQueryResult{Cols: result.Cols}
+	// the timing is complete once the rows have been read
+	syncResult := &localqueryresult.SyncQueryResult{Cols: result.Cols, Timing: result.Timing}
 	for row := range *result.RowChan {
 		select {
 		case <-ctx.Done():
@@ -74,10 +108,13 @@ func (c *DbClient) executeSyncOnConnection(ctx context.Context, dbConn *sql.Conn
 
 // execute the query in the given Context using the provided DatabaseSession
 // executeOnConnection assumes no responsibility over the lifecycle of the DatabaseSession - that is the responsibility of the caller
+// (queueTime is the time spent acquiring the connection, which is reported in the timing of the result)
 // NOTE: The returned Result MUST be fully read - otherwise the connection will block and will prevent further communication
-func (c *DbClient) executeOnConnection(ctx context.Context, dbConn *sql.Conn, onComplete func(), query string, args ...any) (res *localqueryresult.Result, err error) {
+func (c *DbClient) executeOnConnection(ctx context.Context, dbConn *sql.Conn, queueTime time.Duration, onComplete func(), query string, args ...any) (res *localqueryresult.Result, err error) {
 	if query == "" {
-		return localqueryresult.NewResult(nil), nil
+		res := localqueryresult.NewResult(nil)
+		res.Timing = &localqueryresult.TimingMetadata{Duration: queueTime, Queue: queueTime}
+		return res, nil
 	}
 
 	// get a context with a timeout for the query to execute within
@@ -102,10 +139,29 @@ func (c *DbClient) executeOnConnection(ctx context.Context, dbConn *sql.Conn, on
 		}
 	}()
 
-	// start query
+	// select (or translate) the SQL for the dialect of the backend
+	query, err = c.dialectQuery(query)
+	if err != nil {
+		return
+	}
+
+	// in read-only mode, reject any statement which is not a query
+	if isReadOnly() {
+		if err = validateReadOnlyQuery(query); err != nil {
+			return
+		}
+	}
 
+	query, args, err = c.prepareQuery(query, args)
+	if err != nil {
+		return
+	}
+
+	// start query
+	startTime := time.Now()
 	rows, err := c.StartQuery(ctxExecute, dbConn, query, args...)
 	if err != nil {
+		querylog.Log(ctx, query, args, time.Since(startTime), 0, err)
 		return
 	}
 
@@ -113,14 +169,17 @@ func (c *DbClient) executeOnConnection(ctx context.Context, dbConn *sql.Conn, on
 	if err != nil {
 		return
 	}
-	colDefs := fieldDescriptionsToColumns(colTypes, dbConn)
+	colDefs := fieldDescriptionsToColumns(colTypes, c.columnType)
 
 	result := localqueryresult.NewResult(colDefs)
+	// the fetch time is set when the rows have been read, before the row channel is closed
+	result.Timing = &localqueryresult.TimingMetadata{Queue: queueTime, Execution: time.Since(startTime)}
 
 	// read the rows in a go routine
 	go func() {
 		// read in the rows and stream to the query result object
-		c.readRows(ctxExecute, rows, result)
+		rowCount, err := c.readRows(ctxExecute, rows, result)
+		querylog.Log(ctx, query, args, time.Since(startTime), rowCount, err)
 
 		// call the completion callback - if one was provided
 		if onComplete != nil {
@@ -132,7 +191,7 @@ func (c *DbClient) executeOnConnection(ctx context.Context, dbConn *sql.Conn, on
 }
 
 func (c *DbClient) getExecuteContext(ctx context.Context) context.Context {
-	queryTimeout := time.Duration(viper.GetInt(constants.ArgDatabaseQueryTimeout)) * time.Second
+	queryTimeout := queryTimeout()
 	// if timeout is zero, do not set a timeout
 	if queryTimeout == 0 {
 		return ctx
@@ -164,32 +223,40 @@ func (c *DbClient) StartQuery(ctx context.Context, dbConn *sql.Conn, query strin
 	return
 }
 
-func (c *DbClient) readRows(ctx context.Context, rows *sql.Rows, result *localqueryresult.Result) {
+// readRows returns the number of rows read, and the error reading the rows, if any
+func (c *DbClient) readRows(ctx context.Context, rows *sql.Rows, result *localqueryresult.Result) (rowCount int, err error) {
+	fetchStartTime := time.Now()
 	// defer this, so that these get cleaned up even if there is an unforeseen error
 	defer func() {
 		// we are done fetching results. time for display. clear the status indication
 		statushooks.Done(ctx)
 		// close the sql rows object
 		rows.Close()
-		if err := rows.Err(); err != nil {
-			result.StreamError(err)
+		if rowsErr := rows.Err(); rowsErr != nil {
+			result.StreamError(rowsErr)
+			err = rowsErr
+		}
+		if t := result.Timing; t != nil {
+			t.Fetch = time.Since(fetchStartTime)
+			t.Duration = t.Queue + t.Execution + t.Fetch
 		}
 		// close the channels in the result object
 		result.Close()
 
 	}()
 
-	rowCount := 0
 Loop:
 	for rows.Next() {
 		select {
 		case <-ctx.Done():
 			statushooks.SetStatus(ctx, "Cancelling query")
+			err = ctx.Err()
 			break Loop
 		default:
-			rowResult, err := c.readRow(rows, result.Cols)
-			if err != nil {
+			rowResult, readErr := c.readRow(rows, result.Cols)
+			if readErr != nil {
 				// the error will be streamed in the defer
+				err = readErr
 				break Loop
 			}
 
@@ -205,6 +272,7 @@ Loop:
 			rowCount++
 		}
 	}
+	return
 }
 
 func (c *DbClient) rowValues(rows *sql.Rows, cols []*queryresult.ColumnDef) ([]any, error) {
@@ -230,7 +298,7 @@ func (c *DbClient) readRow(rows *sql.Rows, cols []*queryresult.ColumnDef) ([]any
 	if err != nil {
 		return nil, error_helpers.WrapError(err)
 	}
-	return c.Backend.RowReader().Read(columnValues, cols)
+	return c.rowReader.Read(columnValues, cols)
 }
 
 func isStreamingOutput() bool {
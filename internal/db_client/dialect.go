@@ -0,0 +1,506 @@
+package db_client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// Capabilities are the SQL features of a database backend which differ from Postgres - queries are written in the
+// Postgres dialect, and the features a backend does not support are translated to its equivalent where possible
+type Capabilities struct {
+	// the dialect name used to select the SQL of a query for the backend, e.g. sqlite
+	Dialect string
+	// whether Postgres style expr::type casts are supported
+	CastOperator bool
+	// whether ILIKE is supported - if not, and LIKE is case-insensitive, ILIKE is rewritten as LIKE
+	ILike               bool
+	CaseInsensitiveLike bool
+	// whether the now() function is supported
+	Now bool
+	// whether double quotes delimit identifiers (rather than strings)
+	DoubleQuotedIdentifiers bool
+	// whether the -> and ->> JSON operators take a JSON path (as in MySQL) rather than a key or index
+	JSONPathOperators bool
+	// whether OFFSET must precede LIMIT (as in Trino), rather than follow it
+	OffsetBeforeLimit bool
+	// the Postgres types which have a different name in the backend
+	types map[string]string
+	// the function used to convert a value to the Postgres type, rather than a cast
+	typeFunctions map[string]string
+}
+
+var postgresCapabilities = Capabilities{
+	Dialect:                 "postgres",
+	CastOperator:            true,
+	ILike:                   true,
+	Now:                     true,
+	DoubleQuotedIdentifiers: true,
+}
+
+// the capabilities of each backend, keyed by backend name
+var backendCapabilities = map[string]Capabilities{
+	constants.PostgresBackendName: postgresCapabilities,
+	constants.SteampipeBackendName: {
+		Dialect:                 "steampipe",
+		CastOperator:            true,
+		ILike:                   true,
+		Now:                     true,
+		DoubleQuotedIdentifiers: true,
+	},
+	constants.DuckDBBackendName: {
+		Dialect:                 "duckdb",
+		CastOperator:            true,
+		ILike:                   true,
+		Now:                     true,
+		DoubleQuotedIdentifiers: true,
+	},
+	constants.SQLiteBackendName: {
+		Dialect:                 "sqlite",
+		CaseInsensitiveLike:     true,
+		DoubleQuotedIdentifiers: true,
+		types: map[string]string{
+			"varchar": "text", "character varying": "text", "char": "text",
+			"int": "integer", "int2": "integer", "int4": "integer", "int8": "integer", "bigint": "integer", "smallint": "integer",
+			"numeric": "real", "decimal": "real", "float": "real", "float4": "real", "float8": "real", "double precision": "real",
+		},
+		typeFunctions: map[string]string{
+			"json": "json", "jsonb": "json",
+			"timestamp": "datetime", "timestamptz": "datetime", "timestamp with time zone": "datetime", "timestamp without time zone": "datetime",
+			"date": "date", "time": "time",
+		},
+	},
+	constants.MySQLBackendName: {
+		Dialect:             "mysql",
+		CaseInsensitiveLike: true,
+		Now:                 true,
+		JSONPathOperators:   true,
+		types: map[string]string{
+			"text": "char", "varchar": "char", "character varying": "char",
+			"int": "signed", "int2": "signed", "int4": "signed", "int8": "signed", "integer": "signed", "bigint": "signed", "smallint": "signed",
+			"numeric": "double", "float": "double", "float4": "double", "float8": "double", "real": "double", "double precision": "double",
+			"jsonb": "json", "timestamp": "datetime", "timestamptz": "datetime", "timestamp with time zone": "datetime", "timestamp without time zone": "datetime",
+		},
+	},
+	localconstants.ClickHouseBackendName: {
+		Dialect:                 "clickhouse",
+		CastOperator:            true,
+		ILike:                   true,
+		Now:                     true,
+		DoubleQuotedIdentifiers: true,
+	},
+	localconstants.BigQueryBackendName: {
+		Dialect: "bigquery",
+		types: map[string]string{
+			"text": "string", "varchar": "string", "character varying": "string",
+			"int": "int64", "int4": "int64", "int8": "int64", "integer": "int64", "bigint": "int64", "smallint": "int64",
+			"numeric": "numeric", "float": "float64", "float8": "float64", "real": "float64", "double precision": "float64",
+			"bool": "bool", "boolean": "bool", "json": "json", "jsonb": "json", "timestamptz": "timestamp", "timestamp with time zone": "timestamp",
+		},
+	},
+	localconstants.SnowflakeBackendName: {
+		Dialect:                 "snowflake",
+		CastOperator:            true,
+		ILike:                   true,
+		Now:                     true,
+		DoubleQuotedIdentifiers: true,
+	},
+	localconstants.TrinoBackendName: {
+		Dialect:                 "trino",
+		Now:                     true,
+		DoubleQuotedIdentifiers: true,
+		OffsetBeforeLimit:       true,
+		types: map[string]string{
+			"text": "varchar", "character varying": "varchar",
+			"int": "integer", "int4": "integer", "int8": "bigint", "int2": "smallint",
+			"float": "double", "float8": "double", "float4": "real", "double precision": "double",
+			"jsonb": "json", "timestamptz": "timestamp with time zone",
+		},
+	},
+}
+
+// Capabilities returns the SQL capabilities of the backend of the client
+func (c *DbClient) Capabilities() Capabilities {
+	return GetCapabilities(c.Backend.Name())
+}
+
+// dialectQuery returns the SQL of the query for the dialect of the backend - SQL which is not written for the dialect
+// is translated from the Postgres dialect
+func (c *DbClient) dialectQuery(query string) (string, error) {
+	return dialectSQL(query, c.Capabilities())
+}
+
+// dialectSQL returns the SQL of the query for the dialect of a backend with the given capabilities
+func dialectSQL(query string, capabilities Capabilities) (string, error) {
+	dialects := []string{capabilities.Dialect}
+	if capabilities.Dialect == "steampipe" {
+		dialects = append(dialects, "postgres")
+	}
+	query, native, err := selectDialectSQL(query, dialects...)
+	if err != nil || native {
+		return query, err
+	}
+	return translateDialect(query, capabilities), nil
+}
+
+// GetCapabilities returns the SQL capabilities of the backend - unknown backends are assumed to support the Postgres
+// dialect
+func GetCapabilities(backendName string) Capabilities {
+	if capabilities, ok := backendCapabilities[backendName]; ok {
+		return capabilities
+	}
+	return postgresCapabilities
+}
+
+// dialectMarker is the comment which starts the SQL used for the named dialects, e.g. -- dialect: sqlite, duckdb
+var dialectMarker = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*dialect:[ \t]*([a-z, \t]+?)[ \t]*$`)
+
+// selectDialectSQL returns the SQL of the query to run against the first of the dialects it has SQL for, and whether it
+// is written for that dialect (rather than in the Postgres dialect). A query may contain the SQL for specific dialects,
+// each following a dialect comment, for example:
+//
+//	select now() as t
+//	-- dialect: sqlite
+//	select datetime('now') as t
+//
+// The SQL before the first dialect comment is used for any other dialect.
+func selectDialectSQL(query string, dialects ...string) (string, bool, error) {
+	markers := dialectMarker.FindAllStringSubmatchIndex(query, -1)
+	if len(markers) == 0 {
+		return query, false, nil
+	}
+
+	defaultSQL := strings.TrimSpace(query[:markers[0][0]])
+	for _, dialect := range dialects {
+		for i, marker := range markers {
+			end := len(query)
+			if i+1 < len(markers) {
+				end = markers[i+1][0]
+			}
+			for _, name := range strings.Split(query[marker[2]:marker[3]], ",") {
+				if strings.TrimSpace(name) == dialect {
+					return strings.TrimSpace(query[marker[1]:end]), true, nil
+				}
+			}
+		}
+	}
+	if defaultSQL == "" {
+		return "", false, fmt.Errorf("the query has no SQL for %s", dialects[0])
+	}
+	return defaultSQL, false, nil
+}
+
+// the kinds of the tokens of a query
+type sqlTokenKind int
+
+const (
+	// whitespace and comments
+	tokenSpace sqlTokenKind = iota
+	// string literals, including dollar quoted strings
+	tokenString
+	tokenQuotedIdentifier
+	// identifiers, keywords and placeholders
+	tokenWord
+	tokenNumber
+	tokenPunctuation
+	// an expression produced by translating other tokens
+	tokenExpression
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// the operators which are translated, longest first
+var sqlOperators = []string{"->>", "->", "::"}
+
+// tokenizeSQL splits the query into tokens - joining the text of the tokens returns the query
+func tokenizeSQL(query string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+		kind := tokenPunctuation
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			kind = tokenSpace
+			for i < len(query) && strings.IndexByte(" \t\n\r", query[i]) != -1 {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "--"):
+			kind = tokenSpace
+			if end := strings.IndexByte(query[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			kind = tokenSpace
+			if end := strings.Index(query[i+2:], "*/"); end != -1 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		case c == '\'':
+			kind = tokenString
+			i = quotedEnd(query, i, c)
+		case c == '"' || c == '`':
+			kind = tokenQuotedIdentifier
+			i = quotedEnd(query, i, c)
+		case c == '$' && dollarQuoteTag(query[i:]) != "":
+			kind = tokenString
+			tag := dollarQuoteTag(query[i:])
+			if end := strings.Index(query[i+len(tag):], tag); end != -1 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(query)
+			}
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			// a placeholder
+			kind = tokenWord
+			for i++; i < len(query) && query[i] >= '0' && query[i] <= '9'; i++ {
+			}
+		case isIdentifierChar(c, false):
+			kind = tokenWord
+			for i++; i < len(query) && isIdentifierChar(query[i], true); i++ {
+			}
+		case c >= '0' && c <= '9':
+			kind = tokenNumber
+			for i++; i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.'); i++ {
+			}
+		default:
+			i++
+			for _, operator := range sqlOperators {
+				if strings.HasPrefix(query[start:], operator) {
+					i = start + len(operator)
+					break
+				}
+			}
+		}
+		tokens = append(tokens, sqlToken{kind: kind, text: query[start:i]})
+	}
+	return tokens
+}
+
+// translateDialect translates the Postgres dialect features of the query which the backend does not support: casts
+// using ::, ILIKE, now(), double quoted identifiers and the keys of the JSON operators
+func translateDialect(query string, capabilities Capabilities) string {
+	if capabilities.CastOperator && (capabilities.ILike || !capabilities.CaseInsensitiveLike) && capabilities.Now &&
+		capabilities.DoubleQuotedIdentifiers && !capabilities.JSONPathOperators {
+		return query
+	}
+
+	tokens := tokenizeSQL(query)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.kind == tokenPunctuation && token.text == "::" && !capabilities.CastOperator:
+			tokens, i = translateCast(tokens, i, capabilities)
+		case token.kind == tokenWord && strings.EqualFold(token.text, "ilike") && !capabilities.ILike && capabilities.CaseInsensitiveLike:
+			tokens[i].text = "like"
+		case token.kind == tokenWord && strings.EqualFold(token.text, "now") && !capabilities.Now && isEmptyCall(tokens, i):
+			now := "datetime('now')"
+			if capabilities.Dialect == "bigquery" {
+				now = "current_timestamp()"
+			}
+			next := nextToken(tokens, i)
+			tokens = append(tokens[:i], append([]sqlToken{{kind: tokenExpression, text: now}}, tokens[nextToken(tokens, next)+1:]...)...)
+		case token.kind == tokenQuotedIdentifier && token.text[0] == '"' && !capabilities.DoubleQuotedIdentifiers:
+			tokens[i].text = "`" + strings.ReplaceAll(strings.ReplaceAll(token.text[1:len(token.text)-1], `""`, `"`), "`", "``") + "`"
+		case token.kind == tokenPunctuation && (token.text == "->" || token.text == "->>") && capabilities.JSONPathOperators:
+			if next := nextToken(tokens, i); next < len(tokens) {
+				if path, ok := jsonPath(tokens[next]); ok {
+					tokens[next] = sqlToken{kind: tokenString, text: path}
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString(token.text)
+	}
+	return b.String()
+}
+
+// translateCast replaces the operand::type cast at index i with a cast expression, returning the tokens and the index
+// of the expression - if the operand or type cannot be determined, the tokens are returned unchanged
+func translateCast(tokens []sqlToken, i int, capabilities Capabilities) ([]sqlToken, int) {
+	start := operandStart(tokens, i)
+	typeStart := nextToken(tokens, i)
+	if start == -1 || typeStart >= len(tokens) || tokens[typeStart].kind != tokenWord {
+		return tokens, i
+	}
+
+	// the type may be several words, e.g. double precision, and may have a modifier, e.g. numeric(10, 2)
+	typeName := strings.ToLower(tokens[typeStart].text)
+	end := typeStart
+	for _, suffix := range []string{"precision", "varying", "with time zone", "without time zone"} {
+		words := strings.Fields(suffix)
+		j, matched := end, true
+		for _, word := range words {
+			j = nextToken(tokens, j)
+			if j >= len(tokens) || !strings.EqualFold(tokens[j].text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			typeName += " " + suffix
+			end = j
+		}
+	}
+	modifier := ""
+	if next := nextToken(tokens, end); next < len(tokens) && tokens[next].text == "(" {
+		close := matchingParen(tokens, next)
+		if close == -1 {
+			return tokens, i
+		}
+		modifier = joinTokens(tokens[next : close+1])
+		end = close
+	}
+	if next := nextToken(tokens, end); next < len(tokens) && tokens[next].text == "[" {
+		// array types cannot be translated
+		return tokens, i
+	}
+
+	operand := joinTokens(tokens[start:i])
+	operand = strings.TrimSpace(operand)
+	var expression string
+	if function, ok := capabilities.typeFunctions[typeName]; ok {
+		expression = fmt.Sprintf("%s(%s)", function, operand)
+	} else {
+		if mapped, ok := capabilities.types[typeName]; ok {
+			typeName = mapped
+			if typeName != "numeric" && typeName != "decimal" {
+				modifier = ""
+			}
+		}
+		expression = fmt.Sprintf("cast(%s as %s%s)", operand, typeName, modifier)
+	}
+
+	res := append([]sqlToken{}, tokens[:start]...)
+	res = append(res, sqlToken{kind: tokenExpression, text: expression})
+	res = append(res, tokens[end+1:]...)
+	return res, start
+}
+
+// operandStart returns the index of the first token of the operand ending before index i, or -1 if there is none - an
+// operand is a literal, a (qualified) identifier, a parenthesized expression or a function call
+func operandStart(tokens []sqlToken, i int) int {
+	end := prevToken(tokens, i)
+	if end < 0 {
+		return -1
+	}
+	start := end
+	switch tokens[end].kind {
+	case tokenString, tokenNumber, tokenExpression:
+		return start
+	case tokenWord, tokenQuotedIdentifier:
+	case tokenPunctuation:
+		if tokens[end].text != ")" {
+			return -1
+		}
+		start = matchingOpenParen(tokens, end)
+		if start <= 0 || (tokens[start-1].kind != tokenWord && tokens[start-1].kind != tokenQuotedIdentifier) {
+			return start
+		}
+		// a function call
+		start--
+	default:
+		return -1
+	}
+	// a qualified name, e.g. schema.table.column
+	for start >= 2 && tokens[start-1].text == "." && (tokens[start-2].kind == tokenWord || tokens[start-2].kind == tokenQuotedIdentifier) {
+		start -= 2
+	}
+	return start
+}
+
+// a JSON key which can be used unquoted in a JSON path
+var jsonKeyIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPath returns the JSON path equivalent of the key or index operand of a Postgres JSON operator, e.g. '$.name'
+// for 'name' - operands which are already paths (or are not literals) are not changed
+func jsonPath(token sqlToken) (string, bool) {
+	switch token.kind {
+	case tokenNumber:
+		return fmt.Sprintf("'$[%s]'", token.text), true
+	case tokenString:
+		key := token.text[1 : len(token.text)-1]
+		if strings.HasPrefix(key, "$") {
+			return "", false
+		}
+		if jsonKeyIdentifier.MatchString(key) {
+			return fmt.Sprintf("'$.%s'", key), true
+		}
+		return fmt.Sprintf(`'$."%s"'`, strings.ReplaceAll(key, `"`, `\"`)), true
+	}
+	return "", false
+}
+
+// isEmptyCall returns whether the word at index i is a call of a function with no args, e.g. now()
+func isEmptyCall(tokens []sqlToken, i int) bool {
+	open := nextToken(tokens, i)
+	if open >= len(tokens) || tokens[open].text != "(" {
+		return false
+	}
+	close := nextToken(tokens, open)
+	return close < len(tokens) && tokens[close].text == ")"
+}
+
+func matchingParen(tokens []sqlToken, open int) int {
+	depth := 0
+	for j := open; j < len(tokens); j++ {
+		switch tokens[j].text {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func matchingOpenParen(tokens []sqlToken, close int) int {
+	depth := 0
+	for j := close; j >= 0; j-- {
+		switch tokens[j].text {
+		case ")":
+			depth++
+		case "(":
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// nextToken returns the index of the first token after i which is not whitespace or a comment
+func nextToken(tokens []sqlToken, i int) int {
+	for i++; i < len(tokens) && tokens[i].kind == tokenSpace; i++ {
+	}
+	return i
+}
+
+// prevToken returns the index of the last token before i which is not whitespace or a comment
+func prevToken(tokens []sqlToken, i int) int {
+	for i--; i >= 0 && tokens[i].kind == tokenSpace; i-- {
+	}
+	return i
+}
+
+func joinTokens(tokens []sqlToken) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString(token.text)
+	}
+	return b.String()
+}
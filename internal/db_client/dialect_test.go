@@ -0,0 +1,67 @@
+package db_client
+
+import (
+	"testing"
+
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestSelectDialectSQL(t *testing.T) {
+	query := `select now() as t
+-- dialect: sqlite
+select datetime('now') as t
+-- dialect: mysql, duckdb
+select current_timestamp as t`
+
+	tests := []struct {
+		dialects       []string
+		expectedSQL    string
+		expectedNative bool
+	}{
+		{[]string{"postgres"}, "select now() as t", false},
+		{[]string{"sqlite"}, "select datetime('now') as t", true},
+		{[]string{"duckdb"}, "select current_timestamp as t", true},
+		{[]string{"steampipe", "postgres"}, "select now() as t", false},
+	}
+	for _, test := range tests {
+		sql, native, err := selectDialectSQL(query, test.dialects...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != test.expectedSQL || native != test.expectedNative {
+			t.Errorf("selectDialectSQL(%v) = %q, %v, expected %q, %v", test.dialects, sql, native, test.expectedSQL, test.expectedNative)
+		}
+	}
+
+	if sql, native, _ := selectDialectSQL("select 1 -- dialect: sqlite", "sqlite"); sql != "select 1 -- dialect: sqlite" || native {
+		t.Errorf("expected a trailing comment not to be a dialect marker, got %q", sql)
+	}
+	if _, _, err := selectDialectSQL("-- dialect: sqlite\nselect 1", "duckdb"); err == nil {
+		t.Error("expected an error for a query with no SQL for the dialect")
+	}
+}
+
+func TestTranslateDialect(t *testing.T) {
+	tests := []struct {
+		backend  string
+		query    string
+		expected string
+	}{
+		{constants.PostgresBackendName, `select data->>'name', x::int from t where a ilike 'b%'`, `select data->>'name', x::int from t where a ilike 'b%'`},
+		{constants.SQLiteBackendName, `select x::int, t.y::text, '1.5'::numeric(10, 2) from t`, `select cast(x as integer), cast(t.y as text), cast('1.5' as real) from t`},
+		{constants.SQLiteBackendName, `select (a + b)::bigint, lower(c)::text, $1::jsonb, created_at::timestamp with time zone`, `select cast((a + b) as integer), cast(lower(c) as text), json($1), datetime(created_at)`},
+		{constants.SQLiteBackendName, `select x::text::int`, `select cast(cast(x as text) as integer)`},
+		{constants.SQLiteBackendName, `select now(), '::int', "a::b" from t where name ilike 'x%' -- now()`, `select datetime('now'), '::int', "a::b" from t where name like 'x%' -- now()`},
+		{constants.SQLiteBackendName, `select tags::text[] from t`, `select tags::text[] from t`},
+		{constants.MySQLBackendName, `select "name", data->>'key', data->'a b', data->0, data->>'$.x' from "t"`, "select `name`, data->>'$.key', data->'$.\"a b\"', data->'$[0]', data->>'$.x' from `t`"},
+		{constants.MySQLBackendName, `select x::numeric, now() where a ilike 'b'`, `select cast(x as double), now() where a like 'b'`},
+		{localconstants.BigQueryBackendName, `select now(), x::text, "col" from t`, "select current_timestamp(), cast(x as string), `col` from t"},
+		{localconstants.TrinoBackendName, `select x::timestamptz from t`, `select cast(x as timestamp with time zone) from t`},
+	}
+	for _, test := range tests {
+		if got := translateDialect(test.query, GetCapabilities(test.backend)); got != test.expected {
+			t.Errorf("translateDialect(%s, %q) = %q, expected %q", test.backend, test.query, got, test.expected)
+		}
+	}
+}
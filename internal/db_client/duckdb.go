@@ -0,0 +1,154 @@
+package db_client
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/marcboeker/go-duckdb"
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+// duckDBColumnTypes maps DuckDB column types to the equivalent Postgres types, which are used by the display and
+// snapshot code (and the dashboard UI) to format values
+var duckDBColumnTypes = map[string]string{
+	"BOOLEAN":      "BOOL",
+	"TINYINT":      "INT2",
+	"UTINYINT":     "INT2",
+	"SMALLINT":     "INT2",
+	"USMALLINT":    "INT4",
+	"INTEGER":      "INT4",
+	"UINTEGER":     "INT8",
+	"BIGINT":       "INT8",
+	"UBIGINT":      "NUMERIC",
+	"HUGEINT":      "NUMERIC",
+	"UHUGEINT":     "NUMERIC",
+	"FLOAT":        "FLOAT4",
+	"DOUBLE":       "FLOAT8",
+	"VARCHAR":      "TEXT",
+	"ENUM":         "TEXT",
+	"BLOB":         "BYTEA",
+	"BIT":          "BIT",
+	"DATE":         "DATE",
+	"TIME":         "TIME",
+	"TIMETZ":       "TIMETZ",
+	"TIMESTAMP":    "TIMESTAMP",
+	"TIMESTAMP_S":  "TIMESTAMP",
+	"TIMESTAMP_MS": "TIMESTAMP",
+	"TIMESTAMP_NS": "TIMESTAMP",
+	"TIMESTAMPTZ":  "TIMESTAMPTZ",
+	"INTERVAL":     "INTERVAL",
+	"UUID":         "UUID",
+}
+
+// duckDBColumnType returns the Postgres type equivalent to the DuckDB column type - nested types (lists, structs,
+// maps and unions) are returned as JSONB
+func duckDBColumnType(dataType string) string {
+	if t, ok := duckDBColumnTypes[dataType]; ok {
+		return t
+	}
+	switch {
+	case strings.HasPrefix(dataType, "DECIMAL"):
+		return "NUMERIC"
+	case strings.HasSuffix(dataType, "]"),
+		strings.HasPrefix(dataType, "STRUCT"),
+		strings.HasPrefix(dataType, "MAP"),
+		strings.HasPrefix(dataType, "UNION"):
+		return "JSONB"
+	}
+	return dataType
+}
+
+// newDuckDBRowReader returns a row reader which converts the values returned by the DuckDB driver into values which
+// can be displayed and serialised to JSON
+func newDuckDBRowReader() backend.RowReader {
+	r := backend.NewBasicRowReader()
+	r.CellReader = func(columnValue any, col *queryresult.ColumnDef) (any, error) {
+		// the driver returns uuids as bytes
+		if b, ok := columnValue.([]byte); ok && col.DataType == "UUID" && len(b) == 16 {
+			return uuidString(b), nil
+		}
+		return duckDBValue(columnValue), nil
+	}
+	return r
+}
+
+// duckDBValue converts a value returned by the DuckDB driver, recursing into nested values
+func duckDBValue(v any) any {
+	switch v := v.(type) {
+	case duckdb.Decimal:
+		return v.Float64()
+	case *big.Int:
+		if v.IsInt64() {
+			return v.Int64()
+		}
+		return v.String()
+	case duckdb.Interval:
+		return duckDBIntervalString(v)
+	case duckdb.UUID:
+		return uuidString(v[:])
+	case duckdb.Map:
+		// map keys may be of any type, so convert them to strings so the map can be serialised to JSON
+		res := make(map[string]any, len(v))
+		for k, value := range v {
+			res[fmt.Sprintf("%v", duckDBValue(k))] = duckDBValue(value)
+		}
+		return res
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, value := range v {
+			res[k] = duckDBValue(value)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, value := range v {
+			res[i] = duckDBValue(value)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// duckDBIntervalString formats the interval in the same way as Postgres, e.g. 1 year 2 mons 3 days 04:05:06
+func duckDBIntervalString(i duckdb.Interval) string {
+	var parts []string
+	if years := i.Months / 12; years != 0 {
+		parts = append(parts, pluralUnit(int64(years), "year", "years"))
+	}
+	if months := i.Months % 12; months != 0 {
+		parts = append(parts, pluralUnit(int64(months), "mon", "mons"))
+	}
+	if i.Days != 0 {
+		parts = append(parts, pluralUnit(int64(i.Days), "day", "days"))
+	}
+	if i.Micros != 0 || len(parts) == 0 {
+		d := time.Duration(i.Micros) * time.Microsecond
+		sign := ""
+		if d < 0 {
+			sign = "-"
+			d = -d
+		}
+		clock := fmt.Sprintf("%s%02d:%02d:%02d", sign, int64(d/time.Hour), int64(d%time.Hour/time.Minute), int64(d%time.Minute/time.Second))
+		if micros := int64(d % time.Second / time.Microsecond); micros != 0 {
+			clock += strings.TrimRight(fmt.Sprintf(".%06d", micros), "0")
+		}
+		parts = append(parts, clock)
+	}
+	return strings.Join(parts, " ")
+}
+
+// uuidString formats the 16 bytes of a uuid in the canonical form, e.g. 123e4567-e89b-12d3-a456-426614174000
+func uuidString(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func pluralUnit(n int64, singular, plural string) string {
+	if n == 1 || n == -1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
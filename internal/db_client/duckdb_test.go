@@ -0,0 +1,90 @@
+package db_client
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/marcboeker/go-duckdb"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+func TestDuckDBColumnType(t *testing.T) {
+	tests := map[string]string{
+		"INTEGER":                       "INT4",
+		"DOUBLE":                        "FLOAT8",
+		"VARCHAR":                       "TEXT",
+		"DECIMAL(10,2)":                 "NUMERIC",
+		"INTEGER[]":                     "JSONB",
+		`STRUCT("x" INTEGER)`:           "JSONB",
+		"MAP(VARCHAR, INTEGER)":         "JSONB",
+		"UUID":                          "UUID",
+		"TIMESTAMP_NS":                  "TIMESTAMP",
+		"SOME_FUTURE_TYPE":              "SOME_FUTURE_TYPE",
+		`STRUCT("a" MAP(VARCHAR, INT))`: "JSONB",
+	}
+	for input, expected := range tests {
+		if got := duckDBColumnType(input); got != expected {
+			t.Errorf("duckDBColumnType(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestDuckDBRowReader(t *testing.T) {
+	uuidBytes := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	huge, _ := new(big.Int).SetString("170141183460469231731687303715884105727", 10)
+	cols := []*queryresult.ColumnDef{
+		{Name: "u", DataType: "UUID"},
+		{Name: "b", DataType: "BYTEA"},
+		{Name: "d", DataType: "NUMERIC"},
+		{Name: "h", DataType: "NUMERIC"},
+		{Name: "hh", DataType: "NUMERIC"},
+		{Name: "i", DataType: "INTERVAL"},
+		{Name: "m", DataType: "JSONB"},
+		{Name: "s", DataType: "JSONB"},
+	}
+	values := []any{
+		uuidBytes,
+		uuidBytes,
+		duckdb.Decimal{Width: 10, Scale: 2, Value: big.NewInt(150)},
+		big.NewInt(1),
+		huge,
+		duckdb.Interval{Months: 14, Days: 3, Micros: 14706500000},
+		duckdb.Map{1: duckdb.Map{"a": []any{big.NewInt(2)}}},
+		map[string]any{"x": duckdb.Decimal{Width: 3, Scale: 1, Value: big.NewInt(5)}},
+	}
+	expected := []any{
+		"123e4567-e89b-12d3-a456-426614174000",
+		uuidBytes,
+		1.5,
+		int64(1),
+		"170141183460469231731687303715884105727",
+		"1 year 2 mons 3 days 04:05:06.5",
+		map[string]any{"1": map[string]any{"a": []any{int64(2)}}},
+		map[string]any{"x": 0.5},
+	}
+
+	got, err := newDuckDBRowReader().Read(values, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(got[i], expected[i]) {
+			t.Errorf("column %s: got %#v, expected %#v", cols[i].Name, got[i], expected[i])
+		}
+	}
+}
+
+func TestDuckDBIntervalString(t *testing.T) {
+	tests := map[string]duckdb.Interval{
+		"00:00:00":          {},
+		"1 day":             {Days: 1},
+		"-2 days -01:00:00": {Days: -2, Micros: -3600000000},
+		"1 mon 00:00:01":    {Months: 1, Micros: 1000000},
+	}
+	for expected, input := range tests {
+		if got := duckDBIntervalString(input); got != expected {
+			t.Errorf("duckDBIntervalString(%+v) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package db_client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Page is a page of the results of a query, sorted by a column (if set)
+type Page struct {
+	SortColumn    string
+	SortDirection string
+	Limit         int
+	Offset        int
+}
+
+// PageQueries returns the queries which count the rows of the query and select a page of its results.
+//
+// The query is wrapped in a subquery, so its SQL is selected (or translated) for the dialect of the backend first, and
+// the queries are written for the dialect - so they are not translated again when executed.
+func (c *DbClient) PageQueries(query string, page Page) (countQuery, pageQuery string, err error) {
+	return pageQueries(query, page, c.Capabilities())
+}
+
+func pageQueries(query string, page Page, capabilities Capabilities) (string, string, error) {
+	sql, err := dialectSQL(query, capabilities)
+	if err != nil {
+		return "", "", err
+	}
+	// remove any trailing semicolon, which is not valid in a subquery
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+
+	countQuery := fmt.Sprintf("select count(*) from (%s) as powerpipe_count", sql)
+
+	pageQuery := fmt.Sprintf("select * from (%s) as powerpipe_page", sql)
+	if page.SortColumn != "" {
+		pageQuery += fmt.Sprintf(" order by %s %s", quoteIdentifier(page.SortColumn, capabilities), page.SortDirection)
+	}
+	if capabilities.OffsetBeforeLimit {
+		pageQuery += fmt.Sprintf(" offset %d limit %d", page.Offset, page.Limit)
+	} else {
+		pageQuery += fmt.Sprintf(" limit %d offset %d", page.Limit, page.Offset)
+	}
+	return dialectQueryText(countQuery, capabilities), dialectQueryText(pageQuery, capabilities), nil
+}
+
+// quoteIdentifier quotes the identifier with the identifier quotes of the backend
+func quoteIdentifier(identifier string, capabilities Capabilities) string {
+	if capabilities.DoubleQuotedIdentifiers {
+		return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+	}
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// dialectQueryText marks the SQL as written for the dialect of the backend, so it is not translated when executed
+func dialectQueryText(sql string, capabilities Capabilities) string {
+	return fmt.Sprintf("-- dialect: %s\n%s", capabilities.Dialect, sql)
+}
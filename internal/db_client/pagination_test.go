@@ -0,0 +1,66 @@
+package db_client
+
+import (
+	"testing"
+
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestPageQueries(t *testing.T) {
+	query := `select name, created_at::date as created from t where name ilike 'a%'
+-- dialect: sqlite
+select name, date(created_at) as created from t where name like 'a%';
+-- dialect: trino
+select name, cast(created_at as date) as created from t where lower(name) like 'a%'`
+
+	tests := []struct {
+		backend       string
+		page          Page
+		expectedCount string
+		expectedPage  string
+	}{
+		{
+			backend:       constants.SQLiteBackendName,
+			page:          Page{SortColumn: "name", SortDirection: "desc", Limit: 10, Offset: 20},
+			expectedCount: `select count(*) from (select name, date(created_at) as created from t where name like 'a%') as powerpipe_count`,
+			expectedPage:  `select * from (select name, date(created_at) as created from t where name like 'a%') as powerpipe_page order by "name" desc limit 10 offset 20`,
+		},
+		{
+			backend:       localconstants.TrinoBackendName,
+			page:          Page{Limit: 10, Offset: 20},
+			expectedCount: `select count(*) from (select name, cast(created_at as date) as created from t where lower(name) like 'a%') as powerpipe_count`,
+			expectedPage:  `select * from (select name, cast(created_at as date) as created from t where lower(name) like 'a%') as powerpipe_page offset 20 limit 10`,
+		},
+		{
+			// the default SQL is translated for the backend, and the sort column quoted with its identifier quotes
+			backend:       constants.MySQLBackendName,
+			page:          Page{SortColumn: "created", SortDirection: "asc", Limit: 5},
+			expectedCount: `select count(*) from (select name, cast(created_at as date) as created from t where name like 'a%') as powerpipe_count`,
+			expectedPage:  "select * from (select name, cast(created_at as date) as created from t where name like 'a%') as powerpipe_page order by `created` asc limit 5 offset 0",
+		},
+		{
+			backend:       constants.PostgresBackendName,
+			page:          Page{SortColumn: `a"b`, SortDirection: "asc", Limit: 5, Offset: 5},
+			expectedCount: `select count(*) from (select name, created_at::date as created from t where name ilike 'a%') as powerpipe_count`,
+			expectedPage:  `select * from (select name, created_at::date as created from t where name ilike 'a%') as powerpipe_page order by "a""b" asc limit 5 offset 5`,
+		},
+	}
+	for _, test := range tests {
+		capabilities := GetCapabilities(test.backend)
+		countQuery, pageQuery, err := pageQueries(query, test.page, capabilities)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the queries are written for the dialect, so are executed unchanged
+		for _, q := range []struct{ got, expected string }{{countQuery, test.expectedCount}, {pageQuery, test.expectedPage}} {
+			executed, err := dialectSQL(q.got, capabilities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if executed != q.expected {
+				t.Errorf("%s: executed %q, expected %q", test.backend, executed, q.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package db_client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/pipes"
+)
+
+// the Postgres error codes for a failed password authentication, and the MySQL error number for denied access
+const (
+	postgresInvalidPasswordCode      = "28P01"
+	postgresInvalidAuthorizationCode = "28000"
+	mysqlAccessDenied                = 1045
+)
+
+// the metadata of the Turbot Pipes workspaces which have been resolved, keyed by workspace identifier (<identity>/<workspace>)
+var (
+	pipesWorkspaces     = map[string]*steampipeconfig.CloudMetadata{}
+	pipesWorkspacesLock sync.Mutex
+)
+
+// IsPipesWorkspace returns whether the database is a Turbot Pipes workspace identifier, e.g. acme/prod
+func IsPipesWorkspace(database string) bool {
+	return !HasBackend(database) && steampipeconfig.IsCloudWorkspaceIdentifier(database)
+}
+
+// GetPipesWorkspaceMetadata returns the metadata of the Turbot Pipes workspace, including its connection string,
+// using the Pipes host and token configured for the workspace - the metadata is cached unless refresh is set
+func GetPipesWorkspaceMetadata(ctx context.Context, workspace string, refresh bool) (*steampipeconfig.CloudMetadata, error) {
+	pipesWorkspacesLock.Lock()
+	defer pipesWorkspacesLock.Unlock()
+
+	if metadata, ok := pipesWorkspaces[workspace]; ok && !refresh {
+		return metadata, nil
+	}
+	metadata, err := pipes.GetWorkspaceMetadata(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	pipesWorkspaces[workspace] = metadata
+	return metadata, nil
+}
+
+// IsAuthenticationError returns whether the error is a failure to authenticate with the database, e.g. an invalid
+// password
+func IsAuthenticationError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == postgresInvalidPasswordCode || pgErr.Code == postgresInvalidAuthorizationCode
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlAccessDenied
+	}
+	return false
+}
+
+// pipesWorkspaceForConnectionString returns the identifier of the Pipes workspace the connection string was resolved
+// from, if any
+func pipesWorkspaceForConnectionString(connectionString string) (string, bool) {
+	pipesWorkspacesLock.Lock()
+	defer pipesWorkspacesLock.Unlock()
+
+	for workspace, metadata := range pipesWorkspaces {
+		if metadata.ConnectionString == connectionString {
+			return workspace, true
+		}
+	}
+	return "", false
+}
+
+// refreshPipesWorkspaceConnectionString returns the refreshed connection string of the Pipes workspace the connection
+// string was resolved from, if the error is a password authentication failure
+func refreshPipesWorkspaceConnectionString(ctx context.Context, connectionString string, err error) (string, bool) {
+	if !IsAuthenticationError(err) {
+		return "", false
+	}
+	workspace, ok := pipesWorkspaceForConnectionString(connectionString)
+	if !ok {
+		return "", false
+	}
+	slog.Info("Pipes workspace authentication failed - refreshing the workspace connection string", "workspace", workspace)
+	metadata, refreshErr := GetPipesWorkspaceMetadata(ctx, workspace, true)
+	if refreshErr != nil || metadata.ConnectionString == connectionString {
+		return "", false
+	}
+	return metadata.ConnectionString, true
+}
@@ -0,0 +1,228 @@
+package db_client
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/turbot/pipe-fittings/backend"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// the iam_auth values of a Postgres connection string
+const (
+	postgresIAMAuthAWSRDS      = "aws-rds"
+	postgresIAMAuthGCPCloudSQL = "gcp-cloudsql"
+)
+
+// the connection string parameters used to configure IAM authentication - these are removed from the connection
+// string before connecting, as Postgres would treat them as run-time parameters
+var postgresIAMParams = []string{"iam_auth", "aws_region", "aws_profile"}
+
+// the scope of the access token used to log in to Cloud SQL
+const cloudSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+// isPostgresIAMConnectionString returns whether the connection string is a Postgres connection string which uses IAM
+// authentication, e.g. postgres://powerpipe@prod.abc123.us-east-1.rds.amazonaws.com:5432/compliance?iam_auth=aws-rds
+func isPostgresIAMConnectionString(connectionString string) bool {
+	if !backend.IsPostgresConnectionString(connectionString) {
+		return false
+	}
+	u, err := url.Parse(connectionString)
+	return err == nil && u.Query().Get("iam_auth") != ""
+}
+
+// postgresIAMBackend is a Postgres backend which authenticates using a short-lived IAM token rather than a password.
+// A new token is minted for each database connection, so the token does not expire during long runs.
+//
+// The underlying pipe-fittings backend (which is created using an initial token) provides the row reader, name and
+// search path - Connect uses a connector which mints the token before connecting.
+type postgresIAMBackend struct {
+	backend.Backend
+	// the connection string, without a token
+	connectionString string
+	connConfig       *pgx.ConnConfig
+	token            func(context.Context) (string, error)
+}
+
+func newPostgresIAMBackend(ctx context.Context, connectionString string) (backend.Backend, error) {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	query := u.Query()
+	iamAuth, region, profile := query.Get("iam_auth"), query.Get("aws_region"), query.Get("aws_profile")
+	for _, param := range postgresIAMParams {
+		query.Del(param)
+	}
+	u.RawQuery = query.Encode()
+	if u.User.Username() == "" {
+		return nil, fmt.Errorf("connection string must include the database user to use IAM authentication")
+	}
+
+	b := &postgresIAMBackend{connectionString: connectionString}
+	switch iamAuth {
+	case postgresIAMAuthAWSRDS:
+		if b.token, err = rdsTokenFunc(ctx, u, region, profile); err != nil {
+			return nil, err
+		}
+	case postgresIAMAuthGCPCloudSQL:
+		tokenSource, err := google.DefaultTokenSource(ctx, cloudSQLLoginScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Google Cloud credentials for Cloud SQL IAM authentication: %w", err)
+		}
+		b.token = cloudSQLTokenFunc(oauth2.ReuseTokenSource(nil, tokenSource))
+	default:
+		return nil, fmt.Errorf("invalid iam_auth '%s' - must be %s or %s", iamAuth, postgresIAMAuthAWSRDS, postgresIAMAuthGCPCloudSQL)
+	}
+
+	baseConnectionString, err := postgresSSLConnectionString(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if b.connConfig, err = pgx.ParseConfig(baseConnectionString); err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	// create the underlying backend using an initial token
+	token, err := b.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokenURL, _ := url.Parse(baseConnectionString)
+	tokenURL.User = url.UserPassword(u.User.Username(), token)
+	if b.Backend, err = backend.FromConnectionString(ctx, tokenURL.String()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Connect implements Backend
+func (b *postgresIAMBackend) Connect(ctx context.Context, opts ...backend.ConnectOption) (*sql.DB, error) {
+	// resolve the required search path using the underlying backend (this does not open a connection)
+	db, err := b.Backend.Connect(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	db.Close()
+	var searchPath []string
+	if sp, ok := b.Backend.(backend.SearchPathProvider); ok {
+		searchPath = sp.RequiredSearchPath()
+	}
+
+	beforeConnect := func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+		token, err := b.token(ctx)
+		if err != nil {
+			return err
+		}
+		connConfig.Password = token
+		return nil
+	}
+	afterConnect := func(ctx context.Context, conn *pgx.Conn) error {
+		if len(searchPath) == 0 {
+			return nil
+		}
+		_, err := conn.Exec(ctx, "SET search_path TO "+strings.Join(searchPath, ","))
+		return err
+	}
+
+	config := backend.NewConnectConfig(opts)
+	db = stdlib.OpenDB(*b.connConfig, stdlib.OptionBeforeConnect(beforeConnect), stdlib.OptionAfterConnect(afterConnect))
+	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
+	db.SetConnMaxLifetime(config.MaxConnLifeTime)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	return db, nil
+}
+
+// ConnectionString implements Backend - it returns the connection string without a token
+func (b *postgresIAMBackend) ConnectionString() string {
+	return b.connectionString
+}
+
+// OriginalSearchPath implements SearchPathProvider
+func (b *postgresIAMBackend) OriginalSearchPath() []string {
+	if sp, ok := b.Backend.(backend.SearchPathProvider); ok {
+		return sp.OriginalSearchPath()
+	}
+	return nil
+}
+
+// RequiredSearchPath implements SearchPathProvider
+func (b *postgresIAMBackend) RequiredSearchPath() []string {
+	if sp, ok := b.Backend.(backend.SearchPathProvider); ok {
+		return sp.RequiredSearchPath()
+	}
+	return nil
+}
+
+// ResolvedSearchPath implements SearchPathProvider
+func (b *postgresIAMBackend) ResolvedSearchPath() []string {
+	if sp, ok := b.Backend.(backend.SearchPathProvider); ok {
+		return sp.ResolvedSearchPath()
+	}
+	return nil
+}
+
+// rdsTokenFunc returns a function which mints RDS auth tokens for the database user, using the default AWS credential
+// chain (optionally for the given profile) - the region defaults to the region of the RDS hostname
+func rdsTokenFunc(ctx context.Context, u *url.URL, region, profile string) (func(context.Context) (string, error), error) {
+	var optFns []func(*awsconfig.LoadOptions) error
+	if profile != "" {
+		optFns = append(optFns, awsconfig.WithSharedConfigProfile(profile))
+	}
+	if region == "" {
+		region = rdsHostRegion(u.Hostname())
+	}
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS credentials for RDS IAM authentication: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("could not determine the AWS region of the database - set aws_region in the connection string")
+	}
+
+	endpoint := u.Host
+	if u.Port() == "" {
+		endpoint = net.JoinHostPort(u.Hostname(), "5432")
+	}
+	// each token is valid for 15 minutes
+	return func(ctx context.Context) (string, error) {
+		token, err := auth.BuildAuthToken(ctx, endpoint, cfg.Region, u.User.Username(), cfg.Credentials)
+		if err != nil {
+			return "", fmt.Errorf("failed to create RDS auth token: %w", err)
+		}
+		return token, nil
+	}, nil
+}
+
+// rdsHostRegion returns the region of an RDS hostname, e.g. prod.abc123.us-east-1.rds.amazonaws.com
+func rdsHostRegion(host string) string {
+	parts := strings.Split(host, ".")
+	for i, part := range parts {
+		if part == "rds" && i > 0 {
+			return parts[i-1]
+		}
+	}
+	return ""
+}
+
+func cloudSQLTokenFunc(tokenSource oauth2.TokenSource) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		token, err := tokenSource.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve a Cloud SQL IAM token: %w", err)
+		}
+		return token.AccessToken, nil
+	}
+}
@@ -0,0 +1,111 @@
+package db_client
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRDSTokenFunc(t *testing.T) {
+	// use static credentials from the environment, and no shared config
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	tests := map[string]struct {
+		connectionString string
+		region           string
+		wantHost         string
+		wantRegion       string
+		wantErr          string
+	}{
+		"region of hostname": {
+			connectionString: "postgres://powerpipe@prod.abc123.us-east-1.rds.amazonaws.com:5433/compliance",
+			wantHost:         "prod.abc123.us-east-1.rds.amazonaws.com:5433",
+			wantRegion:       "us-east-1",
+		},
+		"default port": {
+			connectionString: "postgres://powerpipe@prod.abc123.us-east-1.rds.amazonaws.com/compliance",
+			wantHost:         "prod.abc123.us-east-1.rds.amazonaws.com:5432",
+			wantRegion:       "us-east-1",
+		},
+		"region": {
+			connectionString: "postgres://powerpipe@db.example.com/compliance",
+			region:           "eu-west-2",
+			wantHost:         "db.example.com:5432",
+			wantRegion:       "eu-west-2",
+		},
+		"no region": {
+			connectionString: "postgres://powerpipe@db.example.com/compliance",
+			wantErr:          "could not determine the AWS region",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(tc.connectionString)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenFunc, err := rdsTokenFunc(context.Background(), u, tc.region, "")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := tokenFunc(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			host, rawQuery, _ := strings.Cut(token, "?")
+			if host != tc.wantHost {
+				t.Errorf("got token host %s, expected %s", host, tc.wantHost)
+			}
+			query, err := url.ParseQuery(rawQuery)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.Get("Action") != "connect" || query.Get("DBUser") != "powerpipe" {
+				t.Errorf("got token query %v", query)
+			}
+			if credential := query.Get("X-Amz-Credential"); !strings.HasPrefix(credential, "AKIDEXAMPLE/") || !strings.HasSuffix(credential, "/"+tc.wantRegion+"/rds-db/aws4_request") {
+				t.Errorf("got credential %s", credential)
+			}
+			if query.Get("X-Amz-Signature") == "" {
+				t.Error("expected the token to be signed")
+			}
+		})
+	}
+}
+
+func TestRDSHostRegion(t *testing.T) {
+	for host, expected := range map[string]string{
+		"prod.abc123.eu-west-2.rds.amazonaws.com":        "eu-west-2",
+		"proxy.proxy-abc123.us-east-1.rds.amazonaws.com": "us-east-1",
+		"db.example.com": "",
+	} {
+		if got := rdsHostRegion(host); got != expected {
+			t.Errorf("%s: got %q, expected %q", host, got, expected)
+		}
+	}
+}
+
+func TestNewPostgresIAMBackendInvalid(t *testing.T) {
+	for connectionString, expectedErr := range map[string]string{
+		"postgres://db.example.com/compliance?iam_auth=aws-rds":         "must include the database user",
+		"postgres://powerpipe@db.example.com/compliance?iam_auth=azure": "invalid iam_auth 'azure'",
+	} {
+		if _, err := newPostgresIAMBackend(context.Background(), connectionString); err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("%s: expected error %q, got %v", connectionString, expectedErr, err)
+		}
+	}
+}
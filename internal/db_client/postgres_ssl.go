@@ -0,0 +1,80 @@
+package db_client
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+)
+
+// the sslmode values supported by libpq (and pgx)
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// postgresSSLConnectionString validates the ssl parameters of a Postgres connection string (sslmode, sslrootcert,
+// sslcert and sslkey) and resolves the files they refer to, expanding ~ to the home directory.
+//
+// As with libpq, if no client certificate is given, ~/.postgresql/postgresql.crt and ~/.postgresql/postgresql.key are
+// used if they exist, and if the server certificate is verified and no root certificate is given,
+// ~/.postgresql/root.crt is used if it exists (otherwise the system root certificates are used).
+func postgresSSLConnectionString(connectionString string) (string, error) {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		// leave the connection string for the driver to report the error
+		return connectionString, nil
+	}
+	query := u.Query()
+
+	sslMode := query.Get("sslmode")
+	if sslMode == "" {
+		sslMode = os.Getenv("PGSSLMODE")
+	}
+	if sslMode != "" && !helpers.StringSliceContains(postgresSSLModes, sslMode) {
+		return "", fmt.Errorf("invalid sslmode '%s' - must be one of %s", sslMode, strings.Join(postgresSSLModes, ", "))
+	}
+	if sslMode == "disable" {
+		return connectionString, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	for _, param := range []string{"sslrootcert", "sslcert", "sslkey"} {
+		path := query.Get(param)
+		if path == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			path = filepath.Join(home, rest)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%s file %s does not exist", param, path)
+		}
+		query.Set(param, path)
+	}
+	if (query.Get("sslcert") == "") != (query.Get("sslkey") == "") {
+		return "", fmt.Errorf("sslcert and sslkey must both be set to use a client certificate")
+	}
+
+	// apply the libpq default files
+	if os.Getenv("PGSSLCERT") == "" && os.Getenv("PGSSLKEY") == "" {
+		certPath := filepath.Join(home, ".postgresql", "postgresql.crt")
+		keyPath := filepath.Join(home, ".postgresql", "postgresql.key")
+		if query.Get("sslcert") == "" && files.FileExists(certPath) && files.FileExists(keyPath) {
+			query.Set("sslcert", certPath)
+			query.Set("sslkey", keyPath)
+		}
+		rootCertPath := filepath.Join(home, ".postgresql", "root.crt")
+		verify := sslMode == "verify-ca" || sslMode == "verify-full"
+		if verify && query.Get("sslrootcert") == "" && os.Getenv("PGSSLROOTCERT") == "" && files.FileExists(rootCertPath) {
+			query.Set("sslrootcert", rootCertPath)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,79 @@
+package db_client
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPostgresSSLConnectionString(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PGSSLMODE", "")
+	certDir := filepath.Join(home, "certs")
+	if err := os.MkdirAll(certDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"ca.crt", "client.crt", "client.key"} {
+		if err := os.WriteFile(filepath.Join(certDir, name), []byte("pem"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := postgresSSLConnectionString("postgres://steampipe@db:9193/steampipe?sslmode=verify-full&sslrootcert=~/certs/ca.crt&sslcert=~/certs/client.crt&sslkey=~/certs/client.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(got)
+	for param, expected := range map[string]string{
+		"sslmode":     "verify-full",
+		"sslrootcert": filepath.Join(certDir, "ca.crt"),
+		"sslcert":     filepath.Join(certDir, "client.crt"),
+		"sslkey":      filepath.Join(certDir, "client.key"),
+	} {
+		if u.Query().Get(param) != expected {
+			t.Errorf("%s: got %s, expected %s", param, u.Query().Get(param), expected)
+		}
+	}
+
+	for connectionString, expectedErr := range map[string]string{
+		"postgres://db/steampipe?sslmode=strict":                        "invalid sslmode 'strict'",
+		"postgres://db/steampipe?sslmode=require&sslcert=~/certs/x.crt": "sslcert file",
+		"postgres://db/steampipe?sslcert=~/certs/client.crt":            "sslcert and sslkey must both be set",
+	} {
+		if _, err := postgresSSLConnectionString(connectionString); err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("%s: expected error %q, got %v", connectionString, expectedErr, err)
+		}
+	}
+}
+
+func TestPostgresSSLConnectionStringDefaultFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PGSSLMODE", "")
+	pgDir := filepath.Join(home, ".postgresql")
+	if err := os.MkdirAll(pgDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"root.crt", "postgresql.crt", "postgresql.key"} {
+		if err := os.WriteFile(filepath.Join(pgDir, name), []byte("pem"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := postgresSSLConnectionString("postgres://db/steampipe?sslmode=verify-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(got)
+	if u.Query().Get("sslrootcert") != filepath.Join(pgDir, "root.crt") || u.Query().Get("sslkey") != filepath.Join(pgDir, "postgresql.key") {
+		t.Errorf("expected the default certificate files to be used, got %s", got)
+	}
+
+	// with sslmode disabled the connection string is unchanged
+	if got, _ := postgresSSLConnectionString("postgres://db/steampipe?sslmode=disable"); got != "postgres://db/steampipe?sslmode=disable" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,145 @@
+package db_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// prepareQuery returns the query and args to pass to the database driver.
+//
+// Queries are written with Postgres style $n placeholders, which are rewritten for MySQL, ClickHouse, BigQuery,
+// Snowflake and Trino. Only the Postgres driver can bind list and object args (as arrays and jsonb), so for other
+// databases they are passed as JSON strings, which can be expanded with the JSON functions of the database, e.g.
+// select value from json_each($1) in SQLite.
+func (c *DbClient) prepareQuery(query string, args []any) (string, []any, error) {
+	switch c.Backend.Name() {
+	case constants.PostgresBackendName, constants.SteampipeBackendName:
+		return query, args, nil
+	}
+
+	args, err := jsonArgs(args)
+	if err != nil {
+		return "", nil, err
+	}
+	switch c.Backend.Name() {
+	case constants.MySQLBackendName, localconstants.BigQueryBackendName, localconstants.SnowflakeBackendName,
+		localconstants.TrinoBackendName:
+		return positionalPlaceholders(query, args)
+	case localconstants.ClickHouseBackendName:
+		return clickHousePlaceholders(query, args)
+	}
+	return query, args, nil
+}
+
+// jsonArgs returns the args with list and object args converted to JSON strings
+func jsonArgs(args []any) ([]any, error) {
+	res := make([]any, len(args))
+	for i, arg := range args {
+		res[i] = arg
+		if arg == nil {
+			continue
+		}
+		switch reflect.TypeOf(arg).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if _, isBytes := arg.([]byte); isBytes {
+				continue
+			}
+			jsonArg, err := json.Marshal(arg)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = string(jsonArg)
+		}
+	}
+	return res, nil
+}
+
+// positionalPlaceholders rewrites the Postgres style $n placeholders of the query into ? placeholders, returning the
+// args in the order the placeholders appear. If the query has no $n placeholders, it is returned unchanged.
+func positionalPlaceholders(query string, args []any) (string, []any, error) {
+	var res []any
+	query, err := rewritePlaceholders(query, len(args), func(n int) string {
+		res = append(res, args[n-1])
+		return "?"
+	})
+	if err != nil || res == nil {
+		return query, args, err
+	}
+	return query, res, nil
+}
+
+// rewritePlaceholders rewrites the Postgres style $n placeholders of the query, replacing each with the result of
+// calling placeholder with n. Placeholders in string literals, quoted identifiers and comments are ignored.
+func rewritePlaceholders(query string, argCount int, placeholder func(n int) string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(query, i, c)
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "--")):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				n, _ := strconv.Atoi(query[i+1 : j])
+				if n < 1 || n > argCount {
+					return "", fmt.Errorf("query references parameter $%d but %d args were provided", n, argCount)
+				}
+				b.WriteString(placeholder(n))
+				i = j - 1
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), nil
+}
+
+// quotedEnd returns the index after the end of the quoted string starting at start - quotes are escaped by doubling
+// them, or (except for identifiers) with a backslash
+func quotedEnd(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
@@ -0,0 +1,68 @@
+package db_client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/backend"
+)
+
+func TestPrepareQuery(t *testing.T) {
+	args := []any{"a", 1, nil, []byte("b"), []string{"x", "y"}, map[string]any{"k": 1}}
+
+	sqliteClient := &DbClient{Backend: backend.NewSqliteBackend("sqlite:test.db")}
+	_, got, err := sqliteClient.prepareQuery("select 1", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []any{"a", 1, nil, []byte("b"), `["x","y"]`, `{"k":1}`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %#v, expected %#v", got, expected)
+	}
+
+	mysqlClient := &DbClient{Backend: backend.NewMySQLBackend("mysql://user@tcp(localhost)/db")}
+	query, got, err := mysqlClient.prepareQuery("select $2, $1 where x in (select value from json_table($5))", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedQuery := "select ?, ? where x in (select value from json_table(?))"; query != expectedQuery {
+		t.Errorf("got query %q, expected %q", query, expectedQuery)
+	}
+	expected = []any{1, "a", `["x","y"]`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %#v, expected %#v", got, expected)
+	}
+
+	postgresArgs := []any{[]string{"x"}}
+	_, got, _ = (&DbClient{Backend: &backend.PostgresBackend{}}).prepareQuery("select $1", postgresArgs)
+	if !reflect.DeepEqual(got, postgresArgs) {
+		t.Errorf("got %#v, expected args to be unchanged", got)
+	}
+}
+
+func TestPositionalPlaceholders(t *testing.T) {
+	args := []any{"a", "b"}
+	tests := map[string]string{
+		"select $1, $2":                 "select ?, ?",
+		"select '$1', `$2`, \"$1\", $2": "select '$1', `$2`, \"$1\", ?",
+		"select 'it''s $1', $1 -- $2\n": "select 'it''s $1', ? -- $2\n",
+		"select /* $2 */ $1 # $2":       "select /* $2 */ ? # $2",
+		"select 'a\\'$1', $1":           "select 'a\\'$1', ?",
+		"select ? from t":               "select ? from t",
+		"select price$ from t":          "select price$ from t",
+	}
+	for input, expected := range tests {
+		got, _, err := positionalPlaceholders(input, args)
+		if err != nil {
+			t.Errorf("positionalPlaceholders(%q) returned error: %s", input, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("positionalPlaceholders(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+
+	if _, _, err := positionalPlaceholders("select $3", args); err == nil {
+		t.Error("expected an error for a placeholder with no arg")
+	}
+}
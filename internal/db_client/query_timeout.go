@@ -0,0 +1,83 @@
+package db_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+// the Postgres error code for a cancelled statement, and the MySQL error number for a statement which exceeded
+// max_execution_time (which may be set in the connection string)
+const (
+	postgresQueryCanceledCode     = "57014"
+	mysqlMaxExecutionTimeExceeded = 3024
+)
+
+// TimeoutError is the error of a query (or execution) which timed out
+type TimeoutError struct {
+	message string
+}
+
+// NewTimeoutError returns a TimeoutError with the formatted message
+func NewTimeoutError(format string, args ...any) error {
+	return &TimeoutError{message: fmt.Sprintf(format, args...)}
+}
+
+func (e *TimeoutError) Error() string {
+	return e.message
+}
+
+// IsTimeoutError returns whether the error was caused by a timeout - either the deadline of the query context, or the
+// statement timeout of the database session
+func IsTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) || err.Error() == context.DeadlineExceeded.Error() {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == postgresQueryCanceledCode && strings.Contains(pgErr.Message, "statement timeout")
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlMaxExecutionTimeExceeded
+	}
+	return false
+}
+
+// withStatementTimeout sets the query timeout as the statement_timeout of Postgres (including Steampipe) sessions,
+// unless the connection string already sets one, so a query is stopped by the database even if it does not respond to
+// cancellation - queries run against other backends are cancelled when the query context deadline is reached
+func withStatementTimeout(connectionString string, timeout time.Duration) string {
+	if timeout <= 0 || !backend.IsPostgresConnectionString(connectionString) {
+		return connectionString
+	}
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return connectionString
+	}
+	query := u.Query()
+	if query.Has("statement_timeout") || strings.Contains(query.Get("options"), "statement_timeout") {
+		return connectionString
+	}
+	query.Set("statement_timeout", fmt.Sprintf("%d", timeout.Milliseconds()))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// queryTimeout returns the configured query timeout
+func queryTimeout() time.Duration {
+	return time.Duration(viper.GetInt(constants.ArgDatabaseQueryTimeout)) * time.Second
+}
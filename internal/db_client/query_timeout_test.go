@@ -0,0 +1,44 @@
+package db_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsTimeoutError(t *testing.T) {
+	for err, expected := range map[error]bool{
+		NewTimeoutError("query execution timed out after running for %0.2fs", 1.5):              true,
+		fmt.Errorf("failed: %w", context.DeadlineExceeded):                                      true,
+		&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}: true,
+		&pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}:      false,
+		&mysql.MySQLError{Number: 3024, Message: "maximum statement execution time exceeded"}:   true,
+		context.Canceled:                      false,
+		errors.New("relation does not exist"): false,
+	} {
+		if got := IsTimeoutError(err); got != expected {
+			t.Errorf("%v: got %v, expected %v", err, got, expected)
+		}
+	}
+}
+
+func TestWithStatementTimeout(t *testing.T) {
+	for connectionString, expected := range map[string]string{
+		"postgres://steampipe@localhost:9193/steampipe":                        "postgres://steampipe@localhost:9193/steampipe?statement_timeout=300000",
+		"postgres://steampipe@localhost:9193/steampipe?statement_timeout=5000": "postgres://steampipe@localhost:9193/steampipe?statement_timeout=5000",
+		"mysql://root@localhost/compliance":                                    "mysql://root@localhost/compliance",
+		"duckdb:///data/compliance.duckdb":                                     "duckdb:///data/compliance.duckdb",
+	} {
+		if got := withStatementTimeout(connectionString, 5*time.Minute); got != expected {
+			t.Errorf("%s: got %s, expected %s", connectionString, got, expected)
+		}
+	}
+	if got := withStatementTimeout("postgres://localhost/steampipe", 0); got != "postgres://localhost/steampipe" {
+		t.Errorf("expected no statement timeout to be set for a zero timeout, got %s", got)
+	}
+}
@@ -0,0 +1,281 @@
+package db_client
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/powerpipe/internal/constants"
+)
+
+// the first keywords of the statements which may be run in read-only mode
+var readOnlyStatementKeywords = []string{"select", "with", "values", "table", "show", "explain", "describe", "desc"}
+
+// keywords which start statements which modify data, schema or privileges - an EXPLAIN of any of these is rejected in
+// read-only mode, as EXPLAIN ANALYZE runs the statement
+var modifyingKeywords = []string{
+	"insert", "update", "delete", "merge", "upsert", "replace", "truncate",
+	"create", "alter", "drop", "rename", "grant", "revoke", "copy",
+	"attach", "detach", "install", "load", "pragma", "vacuum", "call", "do", "lock",
+}
+
+// the keywords which start data-modifying statements which may be nested in a query (a data-modifying WITH query, or
+// the main statement of a WITH query), and the keyword which must follow each - so columns and functions with the same
+// name are not mistaken for statements ("" means any identifier)
+var nestedModifyingKeywords = map[string]string{"insert": "into", "merge": "into", "delete": "from", "update": ""}
+
+// functions which change the settings of the session - these could turn off the read-only setting of the session for
+// the later queries which use the connection
+var sessionSettingFunctions = []string{"set_config"}
+
+// isReadOnly returns whether read-only mode is enabled
+func isReadOnly() bool {
+	return viper.GetBool(constants.ArgReadOnly)
+}
+
+// validateReadOnlyQuery returns an error if the query is not a single statement which only reads data
+//
+// NOTE: this is a lexical check, which cannot detect functions with side effects - where the backend supports it, the
+// database session is also made read-only (see withReadOnlySession)
+func validateReadOnlyQuery(query string) error {
+	// whether a backslash escapes a quote depends on the database (and its settings), so the query must be read-only
+	// whether or not backslash escapes are used
+	for _, backslashEscapes := range []bool{false, true} {
+		if err := validateReadOnlyTokens(sqlTokens(query, backslashEscapes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateReadOnlyTokens(tokens []string, statementCount int) error {
+	if statementCount > 1 {
+		return fmt.Errorf("read-only mode: queries may only contain a single statement")
+	}
+	first := slices.IndexFunc(tokens, isWordToken)
+	if first == -1 {
+		return nil
+	}
+	if !helpers.StringSliceContains(readOnlyStatementKeywords, tokens[first]) {
+		return fmt.Errorf("read-only mode: only SELECT queries may be run, not %s statements", strings.ToUpper(tokens[first]))
+	}
+	if tokens[first] == "explain" {
+		if explained := explainedStatement(tokens[first+1:]); helpers.StringSliceContains(modifyingKeywords, explained) {
+			return fmt.Errorf("read-only mode: queries may not contain %s", strings.ToUpper(explained))
+		}
+	}
+
+	for i, token := range tokens {
+		if !isIdentifierToken(token) {
+			continue
+		}
+		var prev, next string
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		switch {
+		case next == "(" && helpers.StringSliceContains(sessionSettingFunctions, identifierName(token)):
+			// a function call which changes the session settings, e.g. set_config or pg_catalog.set_config
+			return fmt.Errorf("read-only mode: queries may not call %s", strings.ToUpper(identifierName(token)))
+		case next == "(":
+			// a function call, e.g. replace(name, 'a', 'b')
+			continue
+		case token == "into":
+			// SELECT INTO creates a table
+			return fmt.Errorf("read-only mode: queries may not contain INTO")
+		case prev == "for" && (token == "update" || token == "share" || token == "no" || token == "key"),
+			token == "lock" && next == "in":
+			// FOR UPDATE, FOR SHARE or LOCK IN SHARE MODE lock the selected rows
+			return fmt.Errorf("read-only mode: queries may not lock rows")
+		case prev == "(" || prev == ")":
+			// the keyword may start a nested statement
+			if following, ok := nestedModifyingKeywords[token]; ok && (next == following || following == "" && isIdentifierToken(next)) {
+				return fmt.Errorf("read-only mode: queries may not contain %s", strings.ToUpper(token))
+			}
+		}
+	}
+	return nil
+}
+
+// explainedStatement returns the keyword which starts the statement explained by an EXPLAIN statement, skipping the
+// options of the EXPLAIN (e.g. EXPLAIN ANALYZE VERBOSE, or EXPLAIN (FORMAT JSON))
+func explainedStatement(tokens []string) string {
+	depth := 0
+	for _, token := range tokens {
+		switch {
+		case token == "(":
+			depth++
+		case token == ")":
+			depth--
+		case depth == 0 && (helpers.StringSliceContains(readOnlyStatementKeywords, token) || helpers.StringSliceContains(modifyingKeywords, token)):
+			return token
+		}
+	}
+	return ""
+}
+
+// sqlTokens returns the tokens of the query, ignoring whitespace and comments, and the number of statements it
+// contains. Words are returned in lower case, quoted identifiers as their opening quote followed by the identifier,
+// string literals as their opening quote and other characters individually.
+func sqlTokens(query string, backslashEscapes bool) ([]string, int) {
+	var tokens []string
+	statementCount := 0
+	inStatement := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(query)
+			}
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end != -1 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+			continue
+		case c == ';':
+			inStatement = false
+			continue
+		}
+
+		if !inStatement {
+			inStatement = true
+			statementCount++
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// skip the quoted string or identifier (a doubled quote is an escaped quote)
+			start := i
+			for i++; i < len(query); i++ {
+				if query[i] == '\\' && c == '\'' && backslashEscapes {
+					i++
+				} else if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c {
+						i++
+					} else {
+						break
+					}
+				}
+			}
+			if c == '\'' {
+				tokens = append(tokens, string(c))
+			} else {
+				tokens = append(tokens, query[start:min(i, len(query))])
+			}
+		case c == '$' && dollarQuoteTag(query[i:]) != "":
+			// skip the dollar quoted string
+			tokens = append(tokens, "'")
+			tag := dollarQuoteTag(query[i:])
+			if end := strings.Index(query[i+len(tag):], tag); end != -1 {
+				i += len(tag) + end + len(tag) - 1
+			} else {
+				i = len(query)
+			}
+		case isIdentifierChar(c, false):
+			start := i
+			for i+1 < len(query) && isIdentifierChar(query[i+1], true) {
+				i++
+			}
+			tokens = append(tokens, strings.ToLower(query[start:i+1]))
+		default:
+			tokens = append(tokens, string(c))
+		}
+	}
+	return tokens, statementCount
+}
+
+// isWordToken returns whether the token is a keyword or unquoted identifier
+func isWordToken(token string) bool {
+	return token != "" && isIdentifierChar(token[0], false)
+}
+
+// isIdentifierToken returns whether the token is an unquoted or quoted identifier
+func isIdentifierToken(token string) bool {
+	return isWordToken(token) || strings.HasPrefix(token, `"`) || strings.HasPrefix(token, "`")
+}
+
+// identifierName returns the lower case name of an unquoted or quoted identifier token
+func identifierName(token string) string {
+	return strings.ToLower(strings.TrimLeft(token, "\"`"))
+}
+
+// dollarQuoteTag returns the tag of the Postgres dollar quoted string at the start of the query, e.g. $$ or $body$
+func dollarQuoteTag(query string) string {
+	for i := 1; i < len(query); i++ {
+		if query[i] == '$' {
+			return query[:i+1]
+		}
+		if !isIdentifierChar(query[i], i > 1) || query[i] == '$' {
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentifierChar returns whether the byte may be part of an unquoted identifier (bytes of multi-byte UTF-8
+// characters are treated as identifier characters)
+func isIdentifierChar(c byte, notFirst bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c >= 0x80:
+		return true
+	case notFirst && (c >= '0' && c <= '9' || c == '$'):
+		return true
+	default:
+		return false
+	}
+}
+
+// withReadOnlySession makes the database session read-only in read-only mode, for backends which support it:
+// Postgres (including Steampipe) sessions use read-only transactions, DuckDB databases are opened read-only and SQLite
+// connections are query only
+func withReadOnlySession(connectionString string) string {
+	if !isReadOnly() {
+		return connectionString
+	}
+	switch {
+	case backend.IsPostgresConnectionString(connectionString):
+		u, err := url.Parse(connectionString)
+		if err != nil {
+			return connectionString
+		}
+		query := u.Query()
+		query.Set("default_transaction_read_only", "on")
+		u.RawQuery = query.Encode()
+		return u.String()
+	case backend.IsDuckDBConnectionString(connectionString):
+		// an in-memory database cannot be opened read-only
+		path, _, _ := strings.Cut(strings.TrimPrefix(connectionString, "duckdb://"), "?")
+		if path == "" || path == ":memory:" || strings.Contains(connectionString, "access_mode") {
+			return connectionString
+		}
+		return appendConnectionStringParam(connectionString, "access_mode=read_only")
+	case backend.IsSqliteConnectionString(connectionString):
+		if strings.Contains(connectionString, "_query_only") {
+			return connectionString
+		}
+		return appendConnectionStringParam(connectionString, "_query_only=1")
+	default:
+		return connectionString
+	}
+}
+
+func appendConnectionStringParam(connectionString, param string) string {
+	if strings.Contains(connectionString, "?") {
+		return connectionString + "&" + param
+	}
+	return connectionString + "?" + param
+}
@@ -0,0 +1,75 @@
+package db_client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestValidateReadOnlyQuery(t *testing.T) {
+	for _, query := range []string{
+		"select * from aws_s3_bucket",
+		"  -- buckets\n/* all of them */ SELECT name, 'drop table x' as \"delete\" FROM aws_s3_bucket;",
+		"with b as (select * from aws_s3_bucket) select count(*) from b",
+		"select $$ insert $$ as a, $body$ ; update $body$ as b where id = $1",
+		"explain select 1",
+		"explain analyze select 1",
+		"",
+		// functions and columns with the same names as keywords which modify data
+		"select replace(name, 'a', 'b') from t",
+		"select (replace(name, 'a', 'b')) as name from t",
+		"select load, do, call, lock, copy, \"update\" from t where (load > 1) order by copy",
+		"select t.lock, t.do from t",
+		"with t as (select insert(name, 1, 2, 'x') as name from u) select * from t",
+		"select count(*) from (select * from t) limit 10",
+		"select current_setting('default_transaction_read_only'), \"set_config\" from t",
+	} {
+		if err := validateReadOnlyQuery(query); err != nil {
+			t.Errorf("%q: unexpected error %v", query, err)
+		}
+	}
+
+	for query, expectedErr := range map[string]string{
+		"delete from aws_s3_bucket":                                                             "not DELETE statements",
+		"select 1; drop table aws_s3_bucket":                                                    "single statement",
+		"with d as (delete from t returning *) select * from d":                                 "may not contain DELETE",
+		"select * into backup from aws_s3_bucket":                                               "may not contain INTO",
+		"select 'x\\' ; delete from t; --'":                                                     "single statement",
+		"SET search_path = public":                                                              "not SET statements",
+		"with d as (select 1) delete from t":                                                    "may not contain DELETE",
+		"with u as (update t set a = 1 returning *) select 1":                                   "may not contain UPDATE",
+		"with i as (insert into t values (1) returning *) select 1":                             "may not contain INSERT",
+		"explain analyze delete from t":                                                         "may not contain DELETE",
+		"explain (analyze, format json) update t set a = 1":                                     "may not contain UPDATE",
+		"select * from t for update":                                                            "may not lock rows",
+		"select * from t for no key update":                                                     "may not lock rows",
+		"select * from t lock in share mode":                                                    "may not lock rows",
+		"select set_config('default_transaction_read_only', 'off', false)":                      "may not call SET_CONFIG",
+		"SELECT pg_catalog.set_config('default_transaction_read_only', 'off', false)":           "may not call SET_CONFIG",
+		"select \"set_config\"('default_transaction_read_only', 'off', false)":                  "may not call SET_CONFIG",
+		"with s as (select 1) select * from s where set_config('search_path', 'x', true) = 'x'": "may not call SET_CONFIG",
+	} {
+		if err := validateReadOnlyQuery(query); err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("%q: expected error %q, got %v", query, expectedErr, err)
+		}
+	}
+}
+
+func TestWithReadOnlySession(t *testing.T) {
+	viper.Set(constants.ArgReadOnly, true)
+	defer viper.Set(constants.ArgReadOnly, false)
+
+	for connectionString, expected := range map[string]string{
+		"postgres://steampipe@localhost:9193/steampipe": "postgres://steampipe@localhost:9193/steampipe?default_transaction_read_only=on",
+		"duckdb:///data/compliance.duckdb":              "duckdb:///data/compliance.duckdb?access_mode=read_only",
+		"duckdb://":                                     "duckdb://",
+		"sqlite:///data/compliance.db?_busy_timeout=5":  "sqlite:///data/compliance.db?_busy_timeout=5&_query_only=1",
+		"mysql://root@localhost/compliance":             "mysql://root@localhost/compliance",
+	} {
+		if got := withReadOnlySession(connectionString); got != expected {
+			t.Errorf("%s: got %s, expected %s", connectionString, got, expected)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package db_client
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/snowflakedb/gosnowflake"
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/queryresult"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+const snowflakeConnectionStringPrefix = "snowflake://"
+
+// the Snowflake authenticators
+const (
+	snowflakeAuthenticatorJWT             = "snowflake_jwt"
+	snowflakeAuthenticatorExternalBrowser = "externalbrowser"
+	snowflakeAuthenticatorOAuth           = "oauth"
+)
+
+// isSnowflakeConnectionString returns whether the connection string is for Snowflake
+func isSnowflakeConnectionString(connectionString string) bool {
+	return strings.HasPrefix(connectionString, snowflakeConnectionStringPrefix)
+}
+
+// SnowflakeBackend is a backend for Snowflake, which is queried using the Snowflake driver. The connection string is
+// of the form snowflake://user@account/database/schema?warehouse=WH&role=ROLE, with one of the authentication methods:
+//
+//   - key-pair: private_key_file=/path/to/rsa_key.p8 (an unencrypted PKCS#8 or PKCS#1 RSA key)
+//   - SSO: authenticator=externalbrowser - the user logs in (with the identity provider configured for the account)
+//     in the browser
+//   - OAuth: authenticator=oauth&token=... (an OAuth access token obtained elsewhere)
+//
+// The host of the account may be set with the host parameter, e.g. for private connectivity.
+type SnowflakeBackend struct {
+	connectionString string
+	rowReader        backend.RowReader
+}
+
+func NewSnowflakeBackend(connectionString string) *SnowflakeBackend {
+	return &SnowflakeBackend{
+		connectionString: strings.TrimSpace(connectionString),
+		rowReader:        newSnowflakeRowReader(),
+	}
+}
+
+// Connect implements Backend.
+func (b *SnowflakeBackend) Connect(_ context.Context, options ...backend.ConnectOption) (*sql.DB, error) {
+	config := backend.NewConnectConfig(options)
+	snowflakeConfig, err := parseSnowflakeConnectionString(b.connectionString)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "could not connect to snowflake backend")
+	}
+	db := sql.OpenDB(gosnowflake.NewConnector(gosnowflake.SnowflakeDriver{}, *snowflakeConfig))
+	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
+	db.SetConnMaxLifetime(config.MaxConnLifeTime)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	return db, nil
+}
+
+func (b *SnowflakeBackend) ConnectionString() string {
+	return b.connectionString
+}
+
+func (b *SnowflakeBackend) Name() string {
+	return localconstants.SnowflakeBackendName
+}
+
+// RowReader implements Backend.
+func (b *SnowflakeBackend) RowReader() backend.RowReader {
+	return b.rowReader
+}
+
+// parseSnowflakeConnectionString returns the Snowflake driver config for the connection string
+func parseSnowflakeConnectionString(connectionString string) (*gosnowflake.Config, error) {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Snowflake connection string: %w", err)
+	}
+	if u.Host == "" || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid Snowflake connection string: expected snowflake://user@account/database/schema")
+	}
+	query := u.Query()
+	database, schema, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+
+	res := &gosnowflake.Config{
+		Account:   u.Host,
+		User:      u.User.Username(),
+		Database:  database,
+		Schema:    schema,
+		Warehouse: query.Get("warehouse"),
+		Role:      query.Get("role"),
+		Host:      query.Get("host"),
+	}
+
+	privateKeyFile, token := query.Get("private_key_file"), query.Get("token")
+	authenticator := strings.ToLower(query.Get("authenticator"))
+	if authenticator == "" {
+		switch {
+		case privateKeyFile != "":
+			authenticator = snowflakeAuthenticatorJWT
+		case token != "":
+			authenticator = snowflakeAuthenticatorOAuth
+		default:
+			return nil, fmt.Errorf("no Snowflake authentication method - set private_key_file for key-pair authentication or authenticator=externalbrowser for SSO")
+		}
+	}
+	switch authenticator {
+	case snowflakeAuthenticatorJWT:
+		if privateKeyFile == "" {
+			return nil, fmt.Errorf("private_key_file must be set for key-pair authentication")
+		}
+		res.Authenticator = gosnowflake.AuthTypeJwt
+		if res.PrivateKey, err = readSnowflakePrivateKey(privateKeyFile); err != nil {
+			return nil, err
+		}
+	case snowflakeAuthenticatorExternalBrowser:
+		res.Authenticator = gosnowflake.AuthTypeExternalBrowser
+	case snowflakeAuthenticatorOAuth:
+		if token == "" {
+			return nil, fmt.Errorf("token must be set for OAuth authentication")
+		}
+		res.Authenticator = gosnowflake.AuthTypeOAuth
+		res.Token = token
+	default:
+		return nil, fmt.Errorf("unsupported Snowflake authenticator '%s' - expected %s, %s or %s", authenticator, snowflakeAuthenticatorJWT, snowflakeAuthenticatorExternalBrowser, snowflakeAuthenticatorOAuth)
+	}
+	return res, nil
+}
+
+func readSnowflakePrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Snowflake private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("Snowflake private key file %s is not PEM encoded", path)
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("Snowflake private key must be an RSA key")
+		}
+		return rsaKey, nil
+	case "ENCRYPTED PRIVATE KEY":
+		return nil, fmt.Errorf("encrypted Snowflake private keys are not supported - decrypt the key with: openssl pkcs8 -in %s -out rsa_key.p8 -nocrypt", path)
+	default:
+		return nil, fmt.Errorf("unsupported Snowflake private key type %s", block.Type)
+	}
+}
+
+// snowflakeColumnTypes maps the Snowflake driver column types to the equivalent Postgres types
+var snowflakeColumnTypes = map[string]string{
+	"FIXED":         "NUMERIC",
+	"REAL":          "FLOAT8",
+	"TEXT":          "TEXT",
+	"BINARY":        "BYTEA",
+	"BOOLEAN":       "BOOL",
+	"DATE":          "DATE",
+	"TIME":          "TIME",
+	"TIMESTAMP_NTZ": "TIMESTAMP",
+	"TIMESTAMP_LTZ": "TIMESTAMPTZ",
+	"TIMESTAMP_TZ":  "TIMESTAMPTZ",
+	"VARIANT":       "JSONB",
+	"OBJECT":        "JSONB",
+	"ARRAY":         "JSONB",
+	"GEOGRAPHY":     "TEXT",
+	"GEOMETRY":      "TEXT",
+}
+
+// snowflakeColumnType returns the Postgres type equivalent to the Snowflake column type
+func snowflakeColumnType(dataType string) string {
+	if t, ok := snowflakeColumnTypes[dataType]; ok {
+		return t
+	}
+	return dataType
+}
+
+// newSnowflakeRowReader returns a row reader for the Snowflake driver, which returns numbers and semi-structured
+// values as strings, so these are parsed according to the column type
+func newSnowflakeRowReader() backend.RowReader {
+	r := backend.NewBasicRowReader()
+	r.CellReader = snowflakeCellValue
+	return r
+}
+
+func snowflakeCellValue(columnValue any, col *queryresult.ColumnDef) (any, error) {
+	s, ok := columnValue.(string)
+	if !ok {
+		return columnValue, nil
+	}
+	switch col.DataType {
+	case "NUMERIC":
+		// integers have a scale of 0, so have no decimal point
+		if !strings.ContainsAny(s, ".eE") {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		return strconv.ParseFloat(s, 64)
+	case "FLOAT8":
+		return strconv.ParseFloat(s, 64)
+	case "BOOL":
+		return strconv.ParseBool(s)
+	case "JSONB":
+		var res any
+		if err := json.Unmarshal([]byte(s), &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,200 @@
+package db_client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowflakedb/gosnowflake"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+// writeSnowflakePrivateKey writes the key to a PEM file of the given type, returning its path
+func writeSnowflakePrivateKey(t *testing.T, key *rsa.PrivateKey, blockType string) string {
+	t.Helper()
+	var keyBytes []byte
+	switch blockType {
+	case "RSA PRIVATE KEY":
+		keyBytes = x509.MarshalPKCS1PrivateKey(key)
+	default:
+		var err error
+		if keyBytes, err = x509.MarshalPKCS8PrivateKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "rsa_key.p8")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: keyBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSnowflakeConnectionString(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := writeSnowflakePrivateKey(t, key, "PRIVATE KEY")
+
+	tests := map[string]struct {
+		connectionString string
+		expected         *gosnowflake.Config
+		wantErr          string
+	}{
+		"key-pair": {
+			connectionString: "snowflake://svc_user@myorg-myaccount/compliance/public?warehouse=wh&role=auditor&private_key_file=" + keyFile,
+			expected: &gosnowflake.Config{
+				Account:       "myorg-myaccount",
+				User:          "svc_user",
+				Database:      "compliance",
+				Schema:        "public",
+				Warehouse:     "wh",
+				Role:          "auditor",
+				Authenticator: gosnowflake.AuthTypeJwt,
+				PrivateKey:    key,
+			},
+		},
+		"sso": {
+			connectionString: "snowflake://me@acct/db?authenticator=externalbrowser&host=acct.privatelink.snowflakecomputing.com",
+			expected: &gosnowflake.Config{
+				Account:       "acct",
+				User:          "me",
+				Database:      "db",
+				Host:          "acct.privatelink.snowflakecomputing.com",
+				Authenticator: gosnowflake.AuthTypeExternalBrowser,
+			},
+		},
+		"oauth": {
+			connectionString: "snowflake://me@acct?token=tok",
+			expected: &gosnowflake.Config{
+				Account:       "acct",
+				User:          "me",
+				Authenticator: gosnowflake.AuthTypeOAuth,
+				Token:         "tok",
+			},
+		},
+		"no user": {
+			connectionString: "snowflake://acct/db?private_key_file=" + keyFile,
+			wantErr:          "expected snowflake://user@account",
+		},
+		"no authentication method": {
+			connectionString: "snowflake://me@acct/db",
+			wantErr:          "no Snowflake authentication method",
+		},
+		"key-pair without key": {
+			connectionString: "snowflake://me@acct/db?authenticator=snowflake_jwt",
+			wantErr:          "private_key_file must be set",
+		},
+		"oauth without token": {
+			connectionString: "snowflake://me@acct/db?authenticator=oauth",
+			wantErr:          "token must be set",
+		},
+		"unsupported authenticator": {
+			connectionString: "snowflake://me@acct/db?authenticator=username_password_mfa",
+			wantErr:          "unsupported Snowflake authenticator",
+		},
+		"missing key file": {
+			connectionString: "snowflake://me@acct/db?private_key_file=" + filepath.Join(t.TempDir(), "missing.p8"),
+			wantErr:          "failed to read Snowflake private key",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := parseSnowflakeConnectionString(tc.connectionString)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, tc.expected) {
+				t.Errorf("got %+v, expected %+v", config, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReadSnowflakePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, blockType := range []string{"PRIVATE KEY", "RSA PRIVATE KEY"} {
+		got, err := readSnowflakePrivateKey(writeSnowflakePrivateKey(t, key, blockType))
+		if err != nil {
+			t.Fatalf("%s: %v", blockType, err)
+		}
+		if !got.Equal(key) {
+			t.Errorf("%s: expected the key to be read", blockType)
+		}
+	}
+
+	encrypted := filepath.Join(t.TempDir(), "encrypted.p8")
+	if err := os.WriteFile(encrypted, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte("x")}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSnowflakePrivateKey(encrypted); err == nil || !strings.Contains(err.Error(), "openssl pkcs8") {
+		t.Errorf("expected an encrypted key error, got %v", err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(notPEM, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSnowflakePrivateKey(notPEM); err == nil {
+		t.Error("expected an error for a key which is not PEM encoded")
+	}
+}
+
+func TestSnowflakeCellValue(t *testing.T) {
+	tests := map[string]struct {
+		value    any
+		dataType string
+		expected any
+	}{
+		"integer":        {value: "42", dataType: "FIXED", expected: int64(42)},
+		"decimal":        {value: "1.50", dataType: "FIXED", expected: 1.5},
+		"large integer":  {value: "99999999999999999999", dataType: "FIXED", expected: 1e20},
+		"real":           {value: "0.25", dataType: "REAL", expected: 0.25},
+		"boolean string": {value: "true", dataType: "BOOLEAN", expected: true},
+		"boolean":        {value: false, dataType: "BOOLEAN", expected: false},
+		"array":          {value: "[\n  \"a\"\n]", dataType: "ARRAY", expected: []any{"a"}},
+		"object":         {value: `{"a": 1}`, dataType: "OBJECT", expected: map[string]any{"a": float64(1)}},
+		"text":           {value: "x", dataType: "TEXT", expected: "x"},
+		"null":           {value: nil, dataType: "FIXED", expected: nil},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := snowflakeCellValue(tc.value, &queryresult.ColumnDef{DataType: snowflakeColumnType(tc.dataType)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %#v, expected %#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSnowflakeColumnType(t *testing.T) {
+	for dataType, expected := range map[string]string{
+		"FIXED":         "NUMERIC",
+		"REAL":          "FLOAT8",
+		"TIMESTAMP_NTZ": "TIMESTAMP",
+		"VARIANT":       "JSONB",
+		"TEXT":          "TEXT",
+		"VECTOR":        "VECTOR",
+	} {
+		if got := snowflakeColumnType(dataType); got != expected {
+			t.Errorf("%s: got %s, expected %s", dataType, got, expected)
+		}
+	}
+}
@@ -6,13 +6,13 @@ import (
 	"github.com/turbot/pipe-fittings/queryresult"
 )
 
-func fieldDescriptionsToColumns(fieldDescriptions []*sql.ColumnType, connection *sql.Conn) []*queryresult.ColumnDef {
+func fieldDescriptionsToColumns(fieldDescriptions []*sql.ColumnType, columnType func(string) string) []*queryresult.ColumnDef {
 	cols := make([]*queryresult.ColumnDef, len(fieldDescriptions))
 
 	for i, f := range fieldDescriptions {
 		cols[i] = &queryresult.ColumnDef{
 			Name:     f.Name(),
-			DataType: f.DatabaseTypeName(),
+			DataType: columnType(f.DatabaseTypeName()),
 		}
 	}
 	return cols
@@ -0,0 +1,30 @@
+package db_client
+
+import (
+	"strings"
+)
+
+// sqliteColumnType returns the Postgres type equivalent to the declared type of a SQLite column, following the SQLite
+// type affinity rules (https://www.sqlite.org/datatype3.html#determination_of_column_affinity). Expression columns
+// have no declared type, so are returned unchanged.
+func sqliteColumnType(dataType string) string {
+	t := strings.ToUpper(dataType)
+	switch {
+	case t == "":
+		return dataType
+	case t == "BOOLEAN" || t == "BOOL":
+		return "BOOL"
+	case t == "DATE" || t == "DATETIME" || t == "TIMESTAMP":
+		return "TIMESTAMP"
+	case strings.Contains(t, "INT"):
+		return "INT8"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case strings.Contains(t, "BLOB"):
+		return "BYTEA"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "FLOAT8"
+	default:
+		return "NUMERIC"
+	}
+}
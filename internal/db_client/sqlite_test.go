@@ -0,0 +1,26 @@
+package db_client
+
+import (
+	"testing"
+)
+
+func TestSqliteColumnType(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"INTEGER":       "INT8",
+		"bigint":        "INT8",
+		"VARCHAR(255)":  "TEXT",
+		"text":          "TEXT",
+		"BLOB":          "BYTEA",
+		"REAL":          "FLOAT8",
+		"double":        "FLOAT8",
+		"boolean":       "BOOL",
+		"datetime":      "TIMESTAMP",
+		"DECIMAL(10,5)": "NUMERIC",
+	}
+	for input, expected := range tests {
+		if got := sqliteColumnType(input); got != expected {
+			t.Errorf("sqliteColumnType(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -23,11 +23,13 @@ import (
 	"github.com/turbot/pipe-fittings/error_helpers"
 	pfq "github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/turbot/powerpipe/internal/timingreport"
 )
 
 // ShowQueryOutput displays the output using the proper formatter as applicable
 func ShowQueryOutput(ctx context.Context, result *queryresult.Result) int {
 	rowErrors := 0
+	renderStartTime := time.Now()
 
 	switch cmdconfig.Viper().GetString(constants.ArgOutput) {
 	case constants.OutputFormatJSON:
@@ -41,6 +43,10 @@ func ShowQueryOutput(ctx context.Context, result *queryresult.Result) int {
 	}
 
 	if shouldShowQueryTiming() {
+		// the time displaying the results is the render time of the query
+		renderTime := time.Since(renderStartTime)
+		result.Timing.Render += renderTime
+		result.Timing.Duration += renderTime
 		PrintTiming(result.Timing)
 	}
 	// return the number of rows that returned errors
@@ -407,14 +413,52 @@ func iterateResults(result *queryresult.Result, displayResult displayResultsFunc
 
 func shouldShowQueryTiming() bool {
 	outputFormat := viper.GetString(constants.ArgOutput)
-	return viper.GetBool(constants.ArgTiming) && outputFormat == constants.OutputFormatTable
+	return TimingMode() != constants.ArgOff && outputFormat == constants.OutputFormatTable
+}
+
+// TimingMode returns the --timing mode: off, on or verbose (a workspace profile may set timing as a bool)
+func TimingMode() string {
+	switch mode := strings.ToLower(viper.GetString(constants.ArgTiming)); mode {
+	case "", "false", constants.ArgOff:
+		return constants.ArgOff
+	case "true", constants.ArgOn:
+		return constants.ArgOn
+	default:
+		return mode
+	}
+}
+
+// ValidateTiming checks the --timing mode is off, on or verbose
+func ValidateTiming() error {
+	switch mode := TimingMode(); mode {
+	case constants.ArgOff, constants.ArgOn, constants.ArgVerbose:
+		return nil
+	default:
+		return fmt.Errorf("invalid value of --%s '%s' - must be %s, %s or %s", constants.ArgTiming, mode, constants.ArgOff, constants.ArgOn, constants.ArgVerbose)
+	}
 }
 
 func PrintTiming(timingMetadata *queryresult.TimingMetadata) {
 	durationString := getDurationString(timingMetadata.Duration)
+	if TimingMode() == constants.ArgVerbose && timingMetadata.Execution > 0 {
+		fmt.Printf("\nTime: %s (queue %s, execution %s, fetch %s, render %s)\n", durationString, //nolint:forbidigo // intentional use of fmt
+			timingreport.FormatDuration(timingMetadata.Queue), timingreport.FormatDuration(timingMetadata.Execution),
+			timingreport.FormatDuration(timingMetadata.Fetch), timingreport.FormatDuration(timingMetadata.Render))
+		return
+	}
 	fmt.Printf("\nTime: %s\n", durationString) //nolint:forbidigo // intentional use of fmt
 }
 
+// PrintTimingReport displays the timing of each query of a run, slowest first
+func PrintTimingReport(report *timingreport.Report) {
+	if len(report.Queries) == 0 {
+		return
+	}
+	headers, rows := report.Table()
+	fmt.Println() //nolint:forbidigo // intentional use of fmt
+	ShowWrappedTable(headers, rows, nil)
+}
+
 func getDurationString(duration time.Duration) string {
 	// Calculate duration since startTime and round down to the nearest millisecond
 	durationInMS := duration / time.Millisecond
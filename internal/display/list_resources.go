@@ -14,6 +14,7 @@ import (
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/workspace"
 	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
+	"github.com/turbot/powerpipe/internal/varprompt"
 )
 
 func ListResources[T modconfig.ModTreeItem](cmd *cobra.Command) {
@@ -22,7 +23,7 @@ func ListResources[T modconfig.ModTreeItem](cmd *cobra.Command) {
 	modLocation := viper.GetString(constants.ArgModLocation)
 	// build options to specify which blocks we need to load (based on type T
 	opts := getListLoadWorkspaceOpts[T]()
-	w, errAndWarnings := workspace.LoadWorkspacePromptingForVariables(ctx, modLocation, opts...)
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, modLocation, opts...)
 	error_helpers.FailOnError(errAndWarnings.GetError())
 
 	// get resource filter depending on resource type and output type
@@ -104,7 +105,7 @@ func ShowResource[T modconfig.ModTreeItem](cmd *cobra.Command, args []string) {
 	modLocation := viper.GetString(constants.ArgModLocation)
 	// build options to specify which blocks we need to load (based on type T
 	opts := getListLoadWorkspaceOpts[T]()
-	w, errAndWarnings := workspace.LoadWorkspacePromptingForVariables(ctx, modLocation, opts...)
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, modLocation, opts...)
 	error_helpers.FailOnError(errAndWarnings.GetError())
 	if !w.ModfileExists() {
 		error_helpers.FailOnError(localconstants.ErrorNoModDefinition{})
@@ -0,0 +1,80 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// parseAgeRecipient parses an age X25519 recipient, e.g. age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+func parseAgeRecipient(s string) (*age.X25519Recipient, error) {
+	recipient, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age recipient '%s'", s)
+	}
+	return recipient, nil
+}
+
+// parseAgeIdentities parses the identities of an age identity file - one identity per line, with # comments
+func parseAgeIdentities(s string) ([]*age.X25519Identity, error) {
+	// an empty file has no identities, rather than being invalid
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	identities, err := age.ParseIdentities(strings.NewReader(s))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*age.X25519Identity, 0, len(identities))
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			res = append(res, x25519)
+		}
+	}
+	return res, nil
+}
+
+// ageEncrypt returns the age file of the plaintext encrypted to the recipients
+func ageEncrypt(plaintext []byte, recipients []*age.X25519Recipient) ([]byte, error) {
+	ageRecipients := make([]age.Recipient, len(recipients))
+	for i, recipient := range recipients {
+		ageRecipients[i] = recipient
+	}
+	var b bytes.Buffer
+	w, err := age.Encrypt(&b, ageRecipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ageDecrypt returns the plaintext of the age file, decrypted with whichever of the identities it was encrypted for
+func ageDecrypt(file []byte, identities []*age.X25519Identity) ([]byte, error) {
+	ageIdentities := make([]age.Identity, len(identities))
+	for i, identity := range identities {
+		ageIdentities[i] = identity
+	}
+	r, err := age.Decrypt(bytes.NewReader(file), ageIdentities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("no age identity matches the recipients of the encrypted value")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the value: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the value: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,184 @@
+// Package encryption encrypts sensitive config values, such as tokens and connection strings, so config files which
+// contain them can be committed. An encrypted value is set in a config file with the encrypted function, e.g.
+//
+//	workspace "prod" {
+//	  pipes_token = encrypted("age:YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSB...")
+//	}
+//
+// and is decrypted when the config is loaded. Values are encrypted by powerpipe config encrypt for a recipient, which
+// is one of:
+//
+//	age1...               an age X25519 recipient - the value is decrypted with the age identities (AGE-SECRET-KEY-1...)
+//	                      in POWERPIPE_AGE_IDENTITY, or in the identity file at POWERPIPE_AGE_IDENTITY_FILE
+//	aws-kms:<key>         an AWS KMS key ID, alias or ARN - the value is decrypted with the AWS credentials of the
+//	                      environment
+//
+// The ciphertext of an age value is the base64 encoded age file, so a value may also be encrypted with the age CLI,
+// e.g. printf %s "$TOKEN" | age -r age1... | base64 -w0
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/turbot/pipe-fittings/funcs"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// the schemes of an encrypted value, which prefix the ciphertext (and the key of an aws-kms recipient)
+const (
+	schemeAge    = "age"
+	schemeAWSKMS = "aws-kms"
+)
+
+// FunctionName is the name of the config function which decrypts a value
+const FunctionName = "encrypted"
+
+// Encrypt returns the ciphertext of the plaintext encrypted for the recipients - any number of age recipients, or a
+// single AWS KMS key
+func Encrypt(ctx context.Context, plaintext string, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("no recipients")
+	}
+	if keyID, ok := strings.CutPrefix(recipients[0], schemeAWSKMS+":"); ok {
+		if len(recipients) > 1 {
+			return "", fmt.Errorf("a value may only be encrypted with a single KMS key")
+		}
+		region := kmsKeyRegion(keyID)
+		client, err := newKMSClient(ctx, region)
+		if err != nil {
+			return "", err
+		}
+		blob, err := client.encrypt(ctx, keyID, []byte(plaintext))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt with KMS key '%s': %w", keyID, err)
+		}
+		return strings.Join([]string{schemeAWSKMS, client.region, base64.StdEncoding.EncodeToString(blob)}, ":"), nil
+	}
+
+	var ageRecipients []*age.X25519Recipient
+	for _, r := range recipients {
+		recipient, err := parseAgeRecipient(r)
+		if err != nil {
+			return "", fmt.Errorf("%w - a recipient must be an age recipient (age1...) or an AWS KMS key (%s:<key>)", err, schemeAWSKMS)
+		}
+		ageRecipients = append(ageRecipients, recipient)
+	}
+	file, err := ageEncrypt([]byte(plaintext), ageRecipients)
+	if err != nil {
+		return "", err
+	}
+	return schemeAge + ":" + base64.StdEncoding.EncodeToString(file), nil
+}
+
+// Decrypt returns the plaintext of the ciphertext
+func Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	scheme, data, _ := strings.Cut(ciphertext, ":")
+	switch scheme {
+	case schemeAge:
+		file, err := decodeBase64(data)
+		if err != nil {
+			return "", err
+		}
+		identities, err := AgeIdentities()
+		if err != nil {
+			return "", err
+		}
+		if len(identities) == 0 {
+			return "", fmt.Errorf("no age identity to decrypt the value - set %s or %s", localconstants.EnvAgeIdentity, localconstants.EnvAgeIdentityFile)
+		}
+		plaintext, err := ageDecrypt(file, identities)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	case schemeAWSKMS:
+		region, data, ok := strings.Cut(data, ":")
+		if !ok {
+			return "", fmt.Errorf("invalid encrypted value - expected %s:<region>:<ciphertext>", schemeAWSKMS)
+		}
+		blob, err := decodeBase64(data)
+		if err != nil {
+			return "", err
+		}
+		client, err := newKMSClient(ctx, region)
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := client.decrypt(ctx, blob)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt with KMS: %w", err)
+		}
+		return string(plaintext), nil
+	default:
+		return "", fmt.Errorf("invalid encrypted value - expected %s:<ciphertext> or %s:<region>:<ciphertext>", schemeAge, schemeAWSKMS)
+	}
+}
+
+// decodeBase64 decodes standard base64, with or without padding
+func decodeBase64(s string) ([]byte, error) {
+	res, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(s), "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value - the ciphertext is not base64 encoded")
+	}
+	return res, nil
+}
+
+// AgeIdentities returns the age identities of POWERPIPE_AGE_IDENTITY and the identity file at
+// POWERPIPE_AGE_IDENTITY_FILE
+func AgeIdentities() ([]*age.X25519Identity, error) {
+	identities, err := parseAgeIdentities(os.Getenv(localconstants.EnvAgeIdentity))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", localconstants.EnvAgeIdentity, err)
+	}
+	if path := os.Getenv(localconstants.EnvAgeIdentityFile); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the age identity file: %w", err)
+		}
+		fileIdentities, err := parseAgeIdentities(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age identity file %s: %w", path, err)
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	return identities, nil
+}
+
+// AgeRecipients returns the age recipients of the identities
+func AgeRecipients(identities []*age.X25519Identity) []string {
+	res := make([]string, len(identities))
+	for i, identity := range identities {
+		res[i] = identity.Recipient().String()
+	}
+	return res
+}
+
+// EncryptedFunc is the config function which decrypts an encrypted value
+var EncryptedFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "ciphertext", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		plaintext, err := Decrypt(context.Background(), args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(plaintext), nil
+	},
+})
+
+// ContextFunctions returns the functions of the config eval context - the pipe-fittings functions, and the encrypted
+// function
+func ContextFunctions(baseDir string) map[string]function.Function {
+	res := funcs.ContextFunctions(baseDir)
+	res[FunctionName] = EncryptedFunc
+	return res
+}
@@ -0,0 +1,122 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/turbot/powerpipe/internal/constants"
+)
+
+func newAgeIdentity(t *testing.T) (*age.X25519Identity, string) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity, identity.String()
+}
+
+func TestAgeRoundTrip(t *testing.T) {
+	identity, encodedIdentity := newAgeIdentity(t)
+	other, _ := newAgeIdentity(t)
+	t.Setenv(constants.EnvAgeIdentity, encodedIdentity)
+
+	recipients := AgeRecipients([]*age.X25519Identity{other, identity})
+	if !strings.HasPrefix(recipients[1], "age1") {
+		t.Fatalf("expected an age recipient, got %s", recipients[1])
+	}
+	for _, plaintext := range []string{"", "tpt_secret", strings.Repeat("x", 64*1024), strings.Repeat("y", 128*1024+1)} {
+		ciphertext, err := Encrypt(context.Background(), plaintext, recipients)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Decrypt(context.Background(), ciphertext)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", len(plaintext), err)
+		}
+		if got != plaintext {
+			t.Errorf("%d bytes: the decrypted value does not match", len(plaintext))
+		}
+	}
+
+	ciphertext, err := Encrypt(context.Background(), "tpt_secret", recipients[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(context.Background(), ciphertext); err == nil || !strings.Contains(err.Error(), "no age identity matches") {
+		t.Errorf("expected an error decrypting with an identity which is not a recipient, got %v", err)
+	}
+}
+
+func TestParseAgeRecipient(t *testing.T) {
+	if _, err := parseAgeRecipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"); err != nil {
+		t.Error(err)
+	}
+	for _, recipient := range []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q", "AGE1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", "ssh-ed25519 AAAA"} {
+		if _, err := parseAgeRecipient(recipient); err == nil {
+			t.Errorf("expected %s to be invalid", recipient)
+		}
+	}
+}
+
+func TestKMS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var req map[string][]byte
+		body, _ := io.ReadAll(r.Body)
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			// the key ID is not base64, so is decoded separately
+			var keyReq map[string]any
+			_ = json.Unmarshal(body, &keyReq)
+			if keyReq["KeyId"] != "alias/powerpipe" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = io.WriteString(w, `{"__type": "NotFoundException", "message": "Alias is not found."}`)
+				return
+			}
+			delete(keyReq, "KeyId")
+			b, _ := json.Marshal(keyReq)
+			_ = json.Unmarshal(b, &req)
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"CiphertextBlob": append([]byte("blob:"), req["Plaintext"]...)})
+		case "TrentService.Decrypt":
+			_ = json.Unmarshal(body, &req)
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": []byte(strings.TrimPrefix(string(req["CiphertextBlob"]), "blob:"))})
+		}
+	}))
+	defer server.Close()
+
+	// the client is configured from the environment, with the endpoint overridden by the standard AWS SDK variable
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_ENDPOINT_URL_KMS", server.URL)
+	client, err := newKMSClient(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := client.encrypt(context.Background(), "alias/powerpipe", []byte("tpt_secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := client.decrypt(context.Background(), blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "tpt_secret" {
+		t.Errorf("got %s", plaintext)
+	}
+	if _, err := client.encrypt(context.Background(), "alias/missing", []byte("tpt_secret")); err == nil || !strings.Contains(err.Error(), "NotFoundException") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
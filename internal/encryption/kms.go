@@ -0,0 +1,60 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsClient calls the AWS KMS API of a region
+type kmsClient struct {
+	client *kms.Client
+	region string
+}
+
+// newKMSClient returns a client for the region - if the region is empty, the region of the AWS config is used. The
+// endpoint may be overridden with the standard AWS SDK settings, e.g. AWS_ENDPOINT_URL_KMS
+func newKMSClient(ctx context.Context, region string) (*kmsClient, error) {
+	var optFns []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("could not determine the AWS region - use a key ARN, or set AWS_REGION")
+	}
+	return &kmsClient{client: kms.NewFromConfig(cfg), region: cfg.Region}, nil
+}
+
+// kmsKeyRegion returns the region of a key ARN, e.g. arn:aws:kms:us-east-1:123456789012:key/..., or an empty string
+// for a key ID or alias
+func kmsKeyRegion(keyID string) string {
+	if arn := strings.Split(keyID, ":"); len(arn) > 3 && arn[0] == "arn" {
+		return arn[3]
+	}
+	return ""
+}
+
+// encrypt returns the ciphertext blob of the plaintext encrypted with the key
+func (c *kmsClient) encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	resp, err := c.client.Encrypt(ctx, &kms.EncryptInput{KeyId: &keyID, Plaintext: plaintext})
+	if err != nil {
+		return nil, err
+	}
+	return resp.CiphertextBlob, nil
+}
+
+// decrypt returns the plaintext of the ciphertext blob - the blob identifies the key
+func (c *kmsClient) decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	resp, err := c.client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
@@ -0,0 +1,224 @@
+// Package exportbundle exports a run as a single zip archive of evidence - the snapshot of the run, the output of
+// the selected export formats and the run metadata (the CLI version, the Git commit of the mod and the variable
+// values used), along with a manifest of the SHA-256 hash of each file so the content of the archive can be verified.
+package exportbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/workspace"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+const (
+	// FormatBundle is the name of the bundle export format
+	FormatBundle = "bundle"
+
+	metadataFileName = "metadata.json"
+	manifestFileName = "manifest.json"
+)
+
+// Metadata describes the run a bundle was exported from
+type Metadata struct {
+	CliVersion string    `json:"cli_version"`
+	CreatedAt  time.Time `json:"created_at"`
+	Mod        string    `json:"mod,omitempty"`
+	// the commit of the Git repository containing the mod, and whether the working tree has uncommitted changes
+	GitCommit string `json:"git_commit,omitempty"`
+	GitDirty  bool   `json:"git_dirty,omitempty"`
+	// the values of the variables of the workspace mod and its dependencies
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ManifestFile is the entry of a file of the bundle in the manifest
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// Manifest lists the files of a bundle, with their hashes
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// Exporter exports a zip archive containing the output of the snapshot exporter and the exporters of the selected
+// formats
+type Exporter struct {
+	export.ExporterBase
+	workspace *workspace.Workspace
+	exporters []export.Exporter
+}
+
+// NewExporter returns a bundle exporter for the workspace, which includes the snapshot and the given formats, using
+// the available exporters
+func NewExporter(w *workspace.Workspace, available []export.Exporter, formats []string) (*Exporter, error) {
+	byName := map[string]export.Exporter{}
+	for _, e := range available {
+		byName[e.Name()] = e
+		if alias := e.Alias(); alias != "" {
+			byName[alias] = e
+		}
+	}
+	snapshotExporter, ok := byName[constants.OutputFormatSnapshot]
+	if !ok {
+		return nil, fmt.Errorf("bundle exports require a snapshot exporter")
+	}
+	res := &Exporter{workspace: w, exporters: []export.Exporter{snapshotExporter}}
+	for _, format := range formats {
+		e, ok := byName[strings.TrimSpace(format)]
+		if !ok || e.Name() == FormatBundle {
+			return nil, fmt.Errorf("invalid bundle format '%s'", format)
+		}
+		if e != snapshotExporter {
+			res.exporters = append(res.exporters, e)
+		}
+	}
+	return res, nil
+}
+
+func (e *Exporter) Export(ctx context.Context, input export.ExportSourceData, filePath string) error {
+	dir, err := os.MkdirTemp("", "powerpipe-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// the files of the bundle are named after the bundle, e.g. the snapshot of cis.zip is cis.pps
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	for _, exporter := range e.exporters {
+		if err := exporter.Export(ctx, input, filepath.Join(dir, name+exporter.FileExtension())); err != nil {
+			return fmt.Errorf("failed to export %s: %w", exporter.Name(), err)
+		}
+	}
+	metadata, err := json.MarshalIndent(e.metadata(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, metadataFileName), metadata, 0600); err != nil {
+		return err
+	}
+	return writeArchive(dir, filePath)
+}
+
+func (e *Exporter) FileExtension() string {
+	return ".zip"
+}
+
+func (e *Exporter) Name() string {
+	return FormatBundle
+}
+
+func (e *Exporter) metadata() *Metadata {
+	res := &Metadata{CreatedAt: time.Now().UTC()}
+	if app_specific.AppVersion != nil {
+		res.CliVersion = app_specific.AppVersion.String()
+	}
+	if e.workspace == nil {
+		return res
+	}
+	res.Variables = e.workspace.VariableValues
+	if mod := e.workspace.Mod; mod != nil {
+		res.Mod = mod.ShortName
+		res.GitCommit, res.GitDirty = gitState(mod.ModPath)
+	}
+	return res
+}
+
+// gitState returns the HEAD commit of the Git repository containing the directory and whether its working tree has
+// uncommitted changes, or an empty commit if the directory is not in a Git repository
+func gitState(dir string) (string, bool) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", false
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", false
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return head.Hash().String(), false
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return head.Hash().String(), false
+	}
+	return head.Hash().String(), !status.IsClean()
+}
+
+// writeArchive writes a zip archive of the files in the directory to the path, with a manifest of their hashes
+func writeArchive(dir, filePath string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	manifest := &Manifest{}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ManifestFile{Name: name, Size: int64(len(data)), Sha256: hex.EncodeToString(hash[:])})
+		if err := addFile(w, name, data); err != nil {
+			return err
+		}
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := addFile(w, manifestFileName, manifestData); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return export.Write(filePath, &buf)
+}
+
+func addFile(w *zip.Writer, name string, data []byte) error {
+	f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// AppendExporter returns the exporters with a bundle exporter appended, which includes the formats given by
+// --bundle-format
+func AppendExporter(w *workspace.Workspace, exporters []export.Exporter) ([]export.Exporter, error) {
+	bundle, err := NewExporter(w, exporters, viper.GetStringSlice(localconstants.ArgBundleFormat))
+	if err != nil {
+		return nil, err
+	}
+	return append(exporters, bundle), nil
+}
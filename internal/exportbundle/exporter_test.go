@@ -0,0 +1,92 @@
+package exportbundle
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/export"
+)
+
+type testExporter struct {
+	export.ExporterBase
+	name    string
+	content string
+}
+
+func (e *testExporter) Export(_ context.Context, _ export.ExportSourceData, filePath string) error {
+	return export.Write(filePath, strings.NewReader(e.content))
+}
+
+func (e *testExporter) FileExtension() string {
+	return "." + e.name
+}
+
+func (e *testExporter) Name() string {
+	return e.name
+}
+
+func TestNewExporter(t *testing.T) {
+	available := []export.Exporter{&testExporter{name: "snapshot"}, &testExporter{name: "csv"}}
+	if _, err := NewExporter(nil, available, []string{"csv"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := NewExporter(nil, available, []string{"pdf"}); err == nil {
+		t.Errorf("expected an error for an unavailable format")
+	}
+	if _, err := NewExporter(nil, available[1:], nil); err == nil {
+		t.Errorf("expected an error without a snapshot exporter")
+	}
+}
+
+func TestExport(t *testing.T) {
+	available := []export.Exporter{&testExporter{name: "snapshot", content: "{}"}, &testExporter{name: "csv", content: "a,b"}}
+	e, err := NewExporter(nil, available, []string{"csv", "snapshot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "evidence.zip")
+	if err := e.Export(context.Background(), nil, path); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"evidence.snapshot", "evidence.csv", metadataFileName, manifestFileName} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected the bundle to contain %s", name)
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files[manifestFileName], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != 3 {
+		t.Fatalf("expected 3 files in the manifest, got %d", len(manifest.Files))
+	}
+	for _, f := range manifest.Files {
+		hash := sha256.Sum256(files[f.Name])
+		if f.Sha256 != hex.EncodeToString(hash[:]) {
+			t.Errorf("%s: hash mismatch", f.Name)
+		}
+	}
+}
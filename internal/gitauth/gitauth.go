@@ -0,0 +1,168 @@
+// Package gitauth configures the authentication used when installing mods from private Git hosts.
+//
+// By default, mods are installed using anonymous HTTPS (or the token set in POWERPIPE_GIT_TOKEN, which is used for
+// all hosts), falling back to SSH using keys from the SSH agent. This package adds per-host credentials:
+//
+//	--git-host-tokens         host=token pairs, e.g. "gitlab.acme.com=glpat-xxx,git.acme.com=user:token"
+//	                          a token of the form user:token sets the username as well as the token
+//	--git-ssh-keys            host=private key file pairs, e.g. "github.com=~/.ssh/deploy_key"
+//	                          (a host of * sets the key used for all other hosts)
+//	--git-ssh-key-passphrase  the passphrase of the private key files
+//
+// Like other flags, these may be set in the environment (POWERPIPE_GIT_HOST_TOKENS, POWERPIPE_GIT_SSH_KEYS and
+// POWERPIPE_GIT_SSH_KEY_PASSPHRASE, as comma separated lists) or in an options block of the config, which may be
+// scoped to a workspace profile and may use encrypted values, e.g.
+//
+//	options "general" {
+//	  workspace       = "prod"
+//	  git_host_tokens = ["gitlab.acme.com=${encrypted("age:...")}"]
+//	}
+//
+// Credentials are applied by wrapping the go-git transports, so they are used for every Git operation made by the
+// mod installer, including following redirects and shallow clones.
+package gitauth
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/powerpipe/internal/constants"
+)
+
+// the username used for token authentication when no username is specified
+// (GitHub, GitLab and Gitea all accept any username when the password is a token)
+const defaultTokenUsername = "x-access-token"
+
+// the host key used to set a default SSH key for all hosts
+const anyHost = "*"
+
+// Install installs Git transports which use the configured per-host credentials
+func Install() error {
+	tokens, err := parseHostMap(viper.GetStringSlice(constants.ArgGitHostTokens))
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", constants.ArgGitHostTokens, err)
+	}
+	sshKeys, err := parseHostMap(viper.GetStringSlice(constants.ArgGitSSHKeys))
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", constants.ArgGitSSHKeys, err)
+	}
+
+	if len(tokens) > 0 {
+		for _, scheme := range []string{"http", "https"} {
+			client.InstallProtocol(scheme, &hostAuthTransport{
+				Transport: http.DefaultClient,
+				authFunc:  tokenAuth(tokens),
+			})
+		}
+	}
+	if len(sshKeys) > 0 {
+		client.InstallProtocol("ssh", &hostAuthTransport{
+			Transport: ssh.DefaultClient,
+			authFunc:  sshKeyAuth(sshKeys, viper.GetString(constants.ArgGitSSHKeyPassphrase)),
+		})
+	}
+	return nil
+}
+
+// hostAuthTransport is a transport which sets the authentication for a session based on the endpoint host
+type hostAuthTransport struct {
+	transport.Transport
+	// returns the auth to use for the endpoint, or nil to use the auth passed to the session
+	authFunc func(ep *transport.Endpoint) (transport.AuthMethod, error)
+}
+
+func (t *hostAuthTransport) NewUploadPackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	auth, err := t.resolveAuth(ep, auth)
+	if err != nil {
+		return nil, err
+	}
+	return t.Transport.NewUploadPackSession(ep, auth)
+}
+
+func (t *hostAuthTransport) NewReceivePackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	auth, err := t.resolveAuth(ep, auth)
+	if err != nil {
+		return nil, err
+	}
+	return t.Transport.NewReceivePackSession(ep, auth)
+}
+
+// resolveAuth returns the auth for the endpoint - host specific credentials take precedence over the auth
+// set by the caller, which is the token used for all hosts
+func (t *hostAuthTransport) resolveAuth(ep *transport.Endpoint, auth transport.AuthMethod) (transport.AuthMethod, error) {
+	hostAuth, err := t.authFunc(ep)
+	if err != nil {
+		return nil, err
+	}
+	if hostAuth != nil {
+		slog.Debug("using host specific git credentials", "host", ep.Host, "method", hostAuth.Name())
+		return hostAuth, nil
+	}
+	return auth, nil
+}
+
+func tokenAuth(tokens map[string]string) func(ep *transport.Endpoint) (transport.AuthMethod, error) {
+	return func(ep *transport.Endpoint) (transport.AuthMethod, error) {
+		token, ok := tokens[strings.ToLower(ep.Host)]
+		if !ok {
+			return nil, nil
+		}
+		username := defaultTokenUsername
+		if u, t, ok := strings.Cut(token, ":"); ok {
+			username, token = u, t
+		}
+		return &http.BasicAuth{Username: username, Password: token}, nil
+	}
+}
+
+func sshKeyAuth(keys map[string]string, passphrase string) func(ep *transport.Endpoint) (transport.AuthMethod, error) {
+	return func(ep *transport.Endpoint) (transport.AuthMethod, error) {
+		keyPath, ok := keys[strings.ToLower(ep.Host)]
+		if !ok {
+			keyPath, ok = keys[anyHost]
+		}
+		if !ok {
+			return nil, nil
+		}
+		keyPath, err := files.Tildefy(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		user := ep.User
+		if user == "" {
+			user = "git"
+		}
+		auth, err := ssh.NewPublicKeysFromFile(user, keyPath, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key for %s: %w", ep.Host, err)
+		}
+		return auth, nil
+	}
+}
+
+// parseHostMap parses a list of host=value pairs - each value of the list may itself be a comma separated list of
+// pairs, as it is when set in the environment
+func parseHostMap(values []string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, entry := range strings.Split(strings.Join(values, ","), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, v, ok := strings.Cut(entry, "=")
+		host, v = strings.TrimSpace(host), strings.TrimSpace(v)
+		if !ok || host == "" || v == "" {
+			// do not include the entry in the error as it may contain a secret
+			return nil, fmt.Errorf("expected a list of host=value pairs")
+		}
+		res[strings.ToLower(host)] = v
+	}
+	return res, nil
+}
@@ -0,0 +1,264 @@
+package gitauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestParseHostMap(t *testing.T) {
+	tests := map[string]struct {
+		values    []string
+		expected  map[string]string
+		expectErr bool
+	}{
+		"empty": {
+			values:   nil,
+			expected: map[string]string{},
+		},
+		"single pair": {
+			values:   []string{"gitlab.acme.com=glpat-xxx"},
+			expected: map[string]string{"gitlab.acme.com": "glpat-xxx"},
+		},
+		"comma separated pairs": {
+			values:   []string{"gitlab.acme.com=glpat-xxx,git.acme.com=user:token"},
+			expected: map[string]string{"gitlab.acme.com": "glpat-xxx", "git.acme.com": "user:token"},
+		},
+		"list of pairs": {
+			values:   []string{"gitlab.acme.com=glpat-xxx", "git.acme.com=user:token,*=~/.ssh/id"},
+			expected: map[string]string{"gitlab.acme.com": "glpat-xxx", "git.acme.com": "user:token", "*": "~/.ssh/id"},
+		},
+		"whitespace and empty entries": {
+			values:   []string{" gitlab.acme.com = glpat-xxx ,, "},
+			expected: map[string]string{"gitlab.acme.com": "glpat-xxx"},
+		},
+		"hosts are lower cased": {
+			values:   []string{"GitLab.Acme.com=Token"},
+			expected: map[string]string{"gitlab.acme.com": "Token"},
+		},
+		"value containing =": {
+			values:   []string{"gitlab.acme.com=abc=="},
+			expected: map[string]string{"gitlab.acme.com": "abc=="},
+		},
+		"missing value": {
+			values:    []string{"gitlab.acme.com="},
+			expectErr: true,
+		},
+		"missing host": {
+			values:    []string{"=glpat-xxx"},
+			expectErr: true,
+		},
+		"not a pair": {
+			values:    []string{"glpat-xxx"},
+			expectErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := parseHostMap(test.values)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("got %v, expected %v", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseHostMapErrorOmitsValue(t *testing.T) {
+	_, err := parseHostMap([]string{"glpat-secret"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "glpat-secret") {
+		t.Errorf("expected the error not to include the entry, got %q", err.Error())
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	authFunc := tokenAuth(map[string]string{
+		"gitlab.acme.com": "glpat-xxx",
+		"git.acme.com":    "deploy:secret",
+	})
+	tests := map[string]struct {
+		host     string
+		expected transport.AuthMethod
+	}{
+		"token": {
+			host:     "gitlab.acme.com",
+			expected: &http.BasicAuth{Username: defaultTokenUsername, Password: "glpat-xxx"},
+		},
+		"user and token": {
+			host:     "git.acme.com",
+			expected: &http.BasicAuth{Username: "deploy", Password: "secret"},
+		},
+		"host is case insensitive": {
+			host:     "GitLab.Acme.com",
+			expected: &http.BasicAuth{Username: defaultTokenUsername, Password: "glpat-xxx"},
+		},
+		"other host": {
+			host:     "github.com",
+			expected: nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			auth, err := authFunc(&transport.Endpoint{Protocol: "https", Host: test.host})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(auth, test.expected) {
+				t.Errorf("got %v, expected %v", auth, test.expected)
+			}
+		})
+	}
+}
+
+// writeTestKey writes an ed25519 private key to a file in the directory, encrypted with the passphrase if it is set
+func writeTestKey(t *testing.T, dir, name, passphrase string) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = gossh.MarshalPrivateKey(key, "")
+	} else {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSSHKeyAuth(t *testing.T) {
+	dir := t.TempDir()
+	deployKey := writeTestKey(t, dir, "deploy_key", "")
+	defaultKey := writeTestKey(t, dir, "default_key", "")
+	protectedKey := writeTestKey(t, dir, "protected_key", "passphrase")
+
+	tests := map[string]struct {
+		keys         map[string]string
+		passphrase   string
+		endpoint     *transport.Endpoint
+		expectedUser string
+		expectNil    bool
+		expectErr    bool
+	}{
+		"host key": {
+			keys:         map[string]string{"github.com": deployKey},
+			endpoint:     &transport.Endpoint{Protocol: "ssh", Host: "github.com"},
+			expectedUser: "git",
+		},
+		"endpoint user": {
+			keys:         map[string]string{"github.com": deployKey},
+			endpoint:     &transport.Endpoint{Protocol: "ssh", Host: "github.com", User: "deploy"},
+			expectedUser: "deploy",
+		},
+		"default key": {
+			keys:         map[string]string{"github.com": deployKey, anyHost: defaultKey},
+			endpoint:     &transport.Endpoint{Protocol: "ssh", Host: "gitlab.com"},
+			expectedUser: "git",
+		},
+		"other host": {
+			keys:      map[string]string{"github.com": deployKey},
+			endpoint:  &transport.Endpoint{Protocol: "ssh", Host: "gitlab.com"},
+			expectNil: true,
+		},
+		"passphrase": {
+			keys:         map[string]string{"github.com": protectedKey},
+			passphrase:   "passphrase",
+			endpoint:     &transport.Endpoint{Protocol: "ssh", Host: "github.com"},
+			expectedUser: "git",
+		},
+		"wrong passphrase": {
+			keys:       map[string]string{"github.com": protectedKey},
+			passphrase: "wrong",
+			endpoint:   &transport.Endpoint{Protocol: "ssh", Host: "github.com"},
+			expectErr:  true,
+		},
+		"missing key file": {
+			keys:      map[string]string{"github.com": filepath.Join(dir, "missing")},
+			endpoint:  &transport.Endpoint{Protocol: "ssh", Host: "github.com"},
+			expectErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			auth, err := sshKeyAuth(test.keys, test.passphrase)(test.endpoint)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", auth)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.expectNil {
+				if auth != nil {
+					t.Errorf("expected no auth, got %v", auth)
+				}
+				return
+			}
+			keys, ok := auth.(*ssh.PublicKeys)
+			if !ok {
+				t.Fatalf("expected public keys auth, got %T", auth)
+			}
+			if keys.User != test.expectedUser {
+				t.Errorf("got user %s, expected %s", keys.User, test.expectedUser)
+			}
+		})
+	}
+}
+
+func TestResolveAuth(t *testing.T) {
+	hostAuth := &http.BasicAuth{Username: "host", Password: "token"}
+	callerAuth := &http.BasicAuth{Username: "caller", Password: "token"}
+	tr := &hostAuthTransport{
+		authFunc: func(ep *transport.Endpoint) (transport.AuthMethod, error) {
+			if ep.Host == "gitlab.acme.com" {
+				return hostAuth, nil
+			}
+			return nil, nil
+		},
+	}
+
+	auth, err := tr.resolveAuth(&transport.Endpoint{Host: "gitlab.acme.com"}, callerAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != hostAuth {
+		t.Errorf("expected the host credentials to take precedence, got %v", auth)
+	}
+
+	auth, err = tr.resolveAuth(&transport.Endpoint{Host: "github.com"}, callerAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != callerAuth {
+		t.Errorf("expected the caller credentials for other hosts, got %v", auth)
+	}
+}
@@ -4,11 +4,10 @@ import (
 	"context"
 
 	"github.com/spf13/viper"
-	"github.com/turbot/pipe-fittings/backend"
-	"github.com/turbot/pipe-fittings/cloud"
 	"github.com/turbot/pipe-fittings/constants"
-	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/connection"
+	"github.com/turbot/powerpipe/internal/db_client"
 )
 
 func getCloudMetadata(ctx context.Context) (*steampipeconfig.CloudMetadata, error) {
@@ -17,18 +16,19 @@ func getCloudMetadata(ctx context.Context) (*steampipeconfig.CloudMetadata, erro
 
 	var cloudMetadata *steampipeconfig.CloudMetadata
 
+	// a named connection is resolved when the client is created - just verify it is defined
+	if connection.IsReference(database) {
+		_, err := connection.Resolve(database)
+		return nil, err
+	}
+
 	// so a backend was set - is it a connection string or a database name
-	workspaceDatabaseIsConnectionString := backend.HasBackend(database)
+	workspaceDatabaseIsConnectionString := db_client.HasBackend(database)
 	if !workspaceDatabaseIsConnectionString {
-		// it must be a database name - verify the cloud token was provided
-		cloudToken := viper.GetString(constants.ArgPipesToken)
-		if cloudToken == "" {
-			return nil, error_helpers.MissingCloudTokenError()
-		}
-
-		// so we have a database and a token - build the connection string and set it in viper
+		// it must be a Pipes workspace - resolve the connection string using the Pipes token and set it in viper
+		// (the workspace is cached, so the connection string can be refreshed if the workspace password changes)
 		var err error
-		if cloudMetadata, err = cloud.GetCloudMetadata(ctx, database, cloudToken); err != nil {
+		if cloudMetadata, err = db_client.GetPipesWorkspaceMetadata(ctx, database, false); err != nil {
 			return nil, err
 		}
 		// read connection string out of cloudMetadata
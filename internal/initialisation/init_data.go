@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
-	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/error_helpers"
@@ -21,8 +20,11 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/dashboardworkspace"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/querylog"
+	"github.com/turbot/powerpipe/internal/redact"
+	"github.com/turbot/powerpipe/internal/tracing"
+	"github.com/turbot/powerpipe/internal/varprompt"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
-	"github.com/turbot/steampipe-plugin-sdk/v5/telemetry"
 	"log/slog"
 )
 
@@ -48,7 +50,7 @@ func NewErrorInitData[T modconfig.ModTreeItem](err error) *InitData[T] {
 func NewInitData[T modconfig.ModTreeItem](ctx context.Context, cmd *cobra.Command, cmdArgs ...string) *InitData[T] {
 	modLocation := viper.GetString(constants.ArgModLocation)
 
-	w, errAndWarnings := workspace.LoadWorkspacePromptingForVariables(ctx, modLocation)
+	w, errAndWarnings := varprompt.LoadWorkspace(ctx, modLocation)
 	if errAndWarnings.GetError() != nil {
 		return NewErrorInitData[T](fmt.Errorf("failed to load workspace: %s", error_helpers.HandleCancelError(errAndWarnings.GetError()).Error()))
 	}
@@ -56,6 +58,14 @@ func NewInitData[T modconfig.ModTreeItem](ctx context.Context, cmd *cobra.Comman
 	if !w.ModfileExists() && commandRequiresModfile[T](cmd, cmdArgs) {
 		return NewErrorInitData[T](localconstants.ErrorNoModDefinition{})
 	}
+
+	// the sensitive columns redacted in snapshots and exports are defined by config and the workspace mod
+	redactPolicy, err := redact.FromConfig(w)
+	if err != nil {
+		return NewErrorInitData[T](err)
+	}
+	redact.SetPolicy(redactPolicy)
+
 	i := &InitData[T]{
 		Result:        &InitResult{},
 		ExportManager: export.NewManager(),
@@ -119,8 +129,14 @@ func (i *InitData[T]) Init(ctx context.Context, args ...string) {
 	statushooks.SetStatus(ctx, "Initializing")
 	i.WorkspaceEvents = dashboardworkspace.NewWorkspaceEvents(i.Workspace)
 
+	// open the query log, if enabled
+	if err := querylog.Open(); err != nil {
+		i.Result.Error = err
+		return
+	}
+
 	// initialise telemetry
-	shutdownTelemetry, err := telemetry.Init(app_specific.AppName)
+	shutdownTelemetry, err := tracing.Init(ctx)
 	if err != nil {
 		i.Result.AddWarnings(err.Error())
 	} else {
@@ -145,6 +161,12 @@ func (i *InitData[T]) Init(ctx context.Context, args ...string) {
 		}
 	}
 
+	// if no database was specified, use the local Steampipe service
+	if err := resolveLocalSteampipe(ctx); err != nil {
+		i.Result.Error = err
+		return
+	}
+
 	// retrieve cloud metadata
 	cloudMetadata, err := getCloudMetadata(ctx)
 	if err != nil {
@@ -231,6 +253,7 @@ func (i *InitData[T]) Cleanup(ctx context.Context) {
 	if i.DefaultClient != nil {
 		i.DefaultClient.Close(ctx)
 	}
+	querylog.Close()
 
 }
 
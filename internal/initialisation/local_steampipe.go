@@ -0,0 +1,51 @@
+package initialisation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/statushooks"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/steampipe"
+)
+
+// resolveLocalSteampipe resolves the default database to the local Steampipe service, if no database was specified
+// - if the service is not running it is started when --start-steampipe is set, otherwise a clear error is returned
+func resolveLocalSteampipe(ctx context.Context) error {
+	if viper.GetString(constants.ArgDatabase) != app_specific.DefaultDatabase {
+		return nil
+	}
+
+	service, err := steampipe.Detect()
+	if err != nil {
+		return err
+	}
+	started := false
+	if service == nil {
+		if !viper.GetBool(localconstants.ArgStartSteampipe) {
+			return fmt.Errorf("no database was specified and the local Steampipe service is not running - run 'steampipe service start', pass --%s to start it, or pass --%s to use a different database", localconstants.ArgStartSteampipe, constants.ArgDatabase)
+		}
+		statushooks.SetStatus(ctx, "Starting the Steampipe service")
+		slog.Info("Starting the Steampipe service")
+		service, err = steampipe.Start(ctx)
+		statushooks.Done(ctx)
+		if err != nil {
+			return err
+		}
+		started = true
+	}
+
+	slog.Info("Using the local Steampipe service", "database", service.ConnectionString(), "pid", service.Pid, "started", started)
+	viper.Set(constants.ArgDatabase, service.ConnectionString())
+	if started {
+		fmt.Fprintf(os.Stderr, "Started the Steampipe service - using database %s\n", service.ConnectionString())
+	} else if viper.GetBool(constants.ConfigKeyIsTerminalTTY) {
+		fmt.Fprintf(os.Stderr, "Using the local Steampipe service - database %s\n", service.ConnectionString())
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// the destinations the logs may be written to, set by --log-destination
+const (
+	DestinationStderr = "stderr"
+	// DestinationFile is the log file set by --log-file
+	DestinationFile   = "file"
+	DestinationSyslog = "syslog"
+	// DestinationJournald is the systemd journal
+	DestinationJournald = "journald"
+)
+
+// syslogTag is the tag (and journal SYSLOG_IDENTIFIER) of the log messages sent to syslog and the systemd journal
+const syslogTag = "powerpipe"
+
+// destination is a destination the logs are written to - either a writer of formatted records, or (for syslog and the
+// journal, which record the priority of each message) a sender of formatted records and their level
+type destination struct {
+	w    io.Writer
+	send func(level slog.Level, msg []byte) error
+}
+
+// logDestinations opens the destinations set by --log-destination - by default, the log file set by --log-file, or
+// stderr if there is none
+func logDestinations() ([]*destination, error) {
+	names, err := destinationNames()
+	if err != nil {
+		return nil, err
+	}
+	policy := RotationPolicy{
+		MaxSize:  viper.GetInt(localconstants.ArgLogMaxSize),
+		MaxAge:   viper.GetInt(localconstants.ArgLogMaxAge),
+		MaxFiles: viper.GetInt(localconstants.ArgLogMaxFiles),
+		Compress: viper.GetBool(localconstants.ArgLogCompress),
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	// do not create the log file or connect to syslog if logging is off
+	if getLogLevel() == constants.LogLevelOff {
+		return nil, nil
+	}
+
+	res := make([]*destination, len(names))
+	for i, name := range names {
+		switch name {
+		case DestinationStderr:
+			res[i] = &destination{w: os.Stderr}
+		case DestinationFile:
+			w, err := newRotatingWriter(viper.GetString(localconstants.ArgLogFile), policy)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = &destination{w: w}
+		case DestinationSyslog:
+			w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, syslogTag)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+			}
+			res[i] = &destination{send: syslogSender(w)}
+		case DestinationJournald:
+			j, err := newJournal()
+			if err != nil {
+				return nil, err
+			}
+			res[i] = &destination{send: j.send}
+		}
+	}
+	return res, nil
+}
+
+// destinationNames returns the validated log destinations - the value of --log-destination may be a comma-separated
+// list, as it is when set by POWERPIPE_LOG_DESTINATION
+func destinationNames() ([]string, error) {
+	var names []string
+	for _, value := range viper.GetStringSlice(localconstants.ArgLogDestination) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	logFile := viper.GetString(localconstants.ArgLogFile)
+	if len(names) == 0 {
+		if logFile != "" {
+			return []string{DestinationFile}, nil
+		}
+		return []string{DestinationStderr}, nil
+	}
+	for _, name := range names {
+		switch name {
+		case DestinationStderr, DestinationSyslog, DestinationJournald:
+		case DestinationFile:
+			if logFile == "" {
+				return nil, fmt.Errorf("log destination '%s' requires --%s", DestinationFile, localconstants.ArgLogFile)
+			}
+		default:
+			return nil, fmt.Errorf("invalid log destination '%s' - must be %s, %s, %s or %s", name, DestinationStderr, DestinationFile, DestinationSyslog, DestinationJournald)
+		}
+	}
+	return names, nil
+}
+
+// syslogPriority returns the syslog priority of a log level
+func syslogPriority(level slog.Level) syslog.Priority {
+	switch {
+	case level >= slog.LevelError:
+		return syslog.LOG_ERR
+	case level >= slog.LevelWarn:
+		return syslog.LOG_WARNING
+	case level >= slog.LevelInfo:
+		return syslog.LOG_INFO
+	default:
+		return syslog.LOG_DEBUG
+	}
+}
+
+func syslogSender(w *syslog.Writer) func(slog.Level, []byte) error {
+	return func(level slog.Level, msg []byte) error {
+		switch syslogPriority(level) {
+		case syslog.LOG_ERR:
+			return w.Err(string(msg))
+		case syslog.LOG_WARNING:
+			return w.Warning(string(msg))
+		case syslog.LOG_INFO:
+			return w.Info(string(msg))
+		default:
+			return w.Debug(string(msg))
+		}
+	}
+}
+
+// senderHandler formats records with the handler of the log format, and sends each formatted record with its level.
+// The handlers derived by WithAttrs and WithGroup share the buffer the records are formatted in
+type senderHandler struct {
+	handler slog.Handler
+	buf     *bytes.Buffer
+	mut     *sync.Mutex
+	send    func(slog.Level, []byte) error
+}
+
+func (h *senderHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *senderHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	h.buf.Reset()
+	if err := h.handler.Handle(ctx, r); err != nil {
+		return err
+	}
+	return h.send(r.Level, bytes.TrimSuffix(h.buf.Bytes(), []byte("\n")))
+}
+
+func (h *senderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &senderHandler{handler: h.handler.WithAttrs(attrs), buf: h.buf, mut: h.mut, send: h.send}
+}
+
+func (h *senderHandler) WithGroup(name string) slog.Handler {
+	return &senderHandler{handler: h.handler.WithGroup(name), buf: h.buf, mut: h.mut, send: h.send}
+}
+
+// multiHandler writes records to the handlers of each destination
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			if err := handler.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(multiHandler, len(h))
+	for i, handler := range h {
+		res[i] = handler.WithAttrs(attrs)
+	}
+	return res
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	res := make(multiHandler, len(h))
+	for i, handler := range h {
+		res[i] = handler.WithGroup(name)
+	}
+	return res
+}
@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// setLogLevel sets the log level env var for the test - the name of the env var is set by the CLI on startup, so it is
+// set here too
+func setLogLevel(t *testing.T, level string) {
+	t.Helper()
+	app_specific.SetAppSpecificEnvVarKeys("POWERPIPE_")
+	t.Setenv(app_specific.EnvLogLevel, level)
+}
+
+func TestDestinationNames(t *testing.T) {
+	tests := map[string]struct {
+		destinations []string
+		logFile      string
+		expected     []string
+		expectErr    string
+	}{
+		"default": {
+			expected: []string{DestinationStderr},
+		},
+		"default with log file": {
+			logFile:  "/tmp/powerpipe.log",
+			expected: []string{DestinationFile},
+		},
+		"stderr": {
+			destinations: []string{"stderr"},
+			logFile:      "/tmp/powerpipe.log",
+			expected:     []string{DestinationStderr},
+		},
+		"list": {
+			destinations: []string{"stderr", "syslog", "journald"},
+			expected:     []string{DestinationStderr, DestinationSyslog, DestinationJournald},
+		},
+		"comma separated": {
+			destinations: []string{"file, syslog"},
+			logFile:      "/tmp/powerpipe.log",
+			expected:     []string{DestinationFile, DestinationSyslog},
+		},
+		"empty entries": {
+			destinations: []string{",stderr,,"},
+			expected:     []string{DestinationStderr},
+		},
+		"file without log file": {
+			destinations: []string{"file"},
+			expectErr:    "log destination 'file' requires --log-file",
+		},
+		"invalid": {
+			destinations: []string{"stderr,stdout"},
+			expectErr:    "invalid log destination 'stdout' - must be stderr, file, syslog or journald",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			viper.Set(localconstants.ArgLogDestination, test.destinations)
+			viper.Set(localconstants.ArgLogFile, test.logFile)
+
+			res, err := destinationNames()
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, test.expected) {
+				t.Errorf("got %v, expected %v", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestDestinationNamesFromEnv(t *testing.T) {
+	// a comma separated list set in the environment is a single string rather than a slice
+	t.Cleanup(viper.Reset)
+	viper.Set(localconstants.ArgLogDestination, "stderr,syslog")
+
+	res, err := destinationNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{DestinationStderr, DestinationSyslog}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %v, expected %v", res, expected)
+	}
+}
+
+func TestLogDestinations(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	setLogLevel(t, "info")
+	logFile := filepath.Join(t.TempDir(), "powerpipe.log")
+	viper.Set(localconstants.ArgLogDestination, []string{"stderr", "file"})
+	viper.Set(localconstants.ArgLogFile, logFile)
+
+	destinations, err := logDestinations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(destinations) != 2 {
+		t.Fatalf("expected 2 destinations, got %d", len(destinations))
+	}
+	if destinations[0].w != os.Stderr {
+		t.Errorf("expected the first destination to be stderr, got %v", destinations[0].w)
+	}
+	if _, err := destinations[1].w.Write([]byte("message\n")); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(logFile); err != nil || string(content) != "message\n" {
+		t.Errorf("expected the log file to contain the message, got %q (%v)", content, err)
+	}
+}
+
+func TestLogDestinationsOff(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	setLogLevel(t, "off")
+	logFile := filepath.Join(t.TempDir(), "powerpipe.log")
+	viper.Set(localconstants.ArgLogFile, logFile)
+
+	destinations, err := logDestinations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if destinations != nil {
+		t.Errorf("expected no destinations when logging is off, got %v", destinations)
+	}
+	if _, err := os.Stat(logFile); !os.IsNotExist(err) {
+		t.Errorf("expected the log file not to be created when logging is off")
+	}
+}
+
+func TestLogDestinationsInvalidPolicy(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	setLogLevel(t, "info")
+	viper.Set(localconstants.ArgLogMaxSize, -1)
+
+	if _, err := logDestinations(); err == nil {
+		t.Error("expected an error for an invalid rotation policy")
+	}
+}
+
+func TestSyslogPriority(t *testing.T) {
+	tests := map[string]struct {
+		level    slog.Level
+		expected syslog.Priority
+	}{
+		"trace": {level: constants.LogLevelTrace, expected: syslog.LOG_DEBUG},
+		"debug": {level: slog.LevelDebug, expected: syslog.LOG_DEBUG},
+		"info":  {level: slog.LevelInfo, expected: syslog.LOG_INFO},
+		"warn":  {level: slog.LevelWarn, expected: syslog.LOG_WARNING},
+		"error": {level: slog.LevelError, expected: syslog.LOG_ERR},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if res := syslogPriority(test.level); res != test.expected {
+				t.Errorf("got %v, expected %v", res, test.expected)
+			}
+		})
+	}
+}
+
+// sentRecord is a formatted record sent by a senderHandler
+type sentRecord struct {
+	level slog.Level
+	msg   string
+}
+
+func TestSenderHandler(t *testing.T) {
+	var sent []sentRecord
+	var buf bytes.Buffer
+	handler := &senderHandler{
+		handler: slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		buf: &buf,
+		mut: &sync.Mutex{},
+		send: func(level slog.Level, msg []byte) error {
+			sent = append(sent, sentRecord{level: level, msg: string(msg)})
+			return nil
+		},
+	}
+	logger := slog.New(handler)
+	logger.Debug("not sent")
+	logger.Warn("first", "key", "value")
+	logger.With("component", "server").Error("second")
+
+	// each record is sent without the trailing newline, and the derived handler shares the buffer
+	expected := []sentRecord{
+		{level: slog.LevelWarn, msg: "level=WARN msg=first key=value"},
+		{level: slog.LevelError, msg: "level=ERROR msg=second component=server"},
+	}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("got %v, expected %v", sent, expected)
+	}
+}
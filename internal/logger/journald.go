@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"github.com/turbot/pipe-fittings/constants/runtime"
+)
+
+// journalSocket is the socket of the systemd journal native protocol
+const journalSocket = "/run/systemd/journal/socket"
+
+// journal sends log messages to the systemd journal using its native protocol, so each message records its priority
+// and the execution ID as fields
+type journal struct {
+	conn *net.UnixConn
+}
+
+func newJournal() (*journal, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the systemd journal: %w", err)
+	}
+	return &journal{conn: conn}, nil
+}
+
+func (j *journal) send(level slog.Level, msg []byte) error {
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", msg)
+	writeJournalField(&b, "PRIORITY", []byte(strconv.Itoa(int(syslogPriority(level)))))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", []byte(syslogTag))
+	writeJournalField(&b, "POWERPIPE_EXECUTION_ID", []byte(runtime.ExecutionID))
+	_, err := j.conn.Write(b.Bytes())
+	return err
+}
+
+// writeJournalField writes a field of a journal entry - values containing newlines are written as the field name
+// followed by the length of the value, as a little-endian 64-bit integer, and the value
+func writeJournalField(b *bytes.Buffer, name string, value []byte) {
+	if !bytes.ContainsRune(value, '\n') {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.Write(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteString(name)
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.Write(value)
+	b.WriteByte('\n')
+}
@@ -1,24 +1,49 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/turbot/pipe-fittings/sanitize"
 	"io"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/constants/runtime"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 )
 
-func Initialize() {
-	logger := PowerpipeLogger()
+// the log formats
+const (
+	FormatText = "text"
+	// FormatJSON writes each record as a JSON object with the keys level, ts, execution_id, msg and fields (the
+	// attributes of the record), for ingestion by log aggregators such as Loki or Elasticsearch
+	FormatJSON = "json"
+)
+
+func Initialize() error {
+	format := viper.GetString(localconstants.ArgLogFormat)
+	destinations, err := logDestinations()
+	if err != nil {
+		return err
+	}
+	logger, err := PowerpipeLogger(format, destinations...)
+	if err != nil {
+		return err
+	}
 	slog.SetDefault(logger)
 
+	if format == FormatJSON {
+		// every record has the execution ID, so there is no need for a banner
+		slog.Info("Powerpipe started", "version", viper.GetString("main.version"), "log_level", os.Getenv(app_specific.EnvLogLevel))
+		return nil
+	}
+
 	// pump in the initial set of logs
 	// this will also write out the Execution ID - enabling easy filtering of logs for a single execution
 	// we need to do this since all instances will log to a single file and logs will be interleaved
@@ -28,12 +53,16 @@ func Initialize() {
 	slog.Info(fmt.Sprintf("AppVersion:   v%s\n", viper.GetString("main.version")))
 	slog.Info(fmt.Sprintf("Log level: %s\n", os.Getenv(app_specific.EnvLogLevel)))
 	slog.Info(fmt.Sprintf("Log date: %s\n", time.Now().Format("2006-01-02")))
+	return nil
 }
 
-func PowerpipeLogger() *slog.Logger {
+func PowerpipeLogger(format string, destinations ...*destination) (*slog.Logger, error) {
+	if format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("invalid log format '%s' - must be %s or %s", format, FormatText, FormatJSON)
+	}
 	level := getLogLevel()
 	if level == constants.LogLevelOff {
-		return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+		return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})), nil
 	}
 
 	handlerOptions := &slog.HandlerOptions{
@@ -49,7 +78,60 @@ func PowerpipeLogger() *slog.Logger {
 		},
 	}
 
-	return slog.New(slog.NewJSONHandler(os.Stderr, handlerOptions))
+	if len(destinations) == 0 {
+		destinations = []*destination{{w: os.Stderr}}
+	}
+	handlers := make(multiHandler, len(destinations))
+	for i, d := range destinations {
+		if d.send == nil {
+			handlers[i] = newHandler(format, d.w, handlerOptions)
+			continue
+		}
+		var buf bytes.Buffer
+		handlers[i] = &senderHandler{handler: newHandler(format, &buf, handlerOptions), buf: &buf, mut: &sync.Mutex{}, send: d.send}
+	}
+	if len(handlers) == 1 {
+		return slog.New(handlers[0]), nil
+	}
+	return slog.New(handlers), nil
+}
+
+func newHandler(format string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == FormatJSON {
+		return newJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// newJSONHandler returns a handler writing records as JSON objects with the keys level, ts, execution_id, msg and
+// fields
+func newJSONHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	sanitizeAttr := opts.ReplaceAttr
+	jsonOpts := *opts
+	jsonOpts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.String("ts", a.Value.Time().UTC().Format(time.RFC3339Nano))
+			case slog.LevelKey:
+				return slog.String(slog.LevelKey, levelName(a.Value.Any().(slog.Level)))
+			case slog.MessageKey:
+				return a
+			}
+		}
+		return sanitizeAttr(groups, a)
+	}
+	// the attributes of records (and of loggers derived with With) are grouped under fields
+	return slog.NewJSONHandler(w, &jsonOpts).
+		WithAttrs([]slog.Attr{slog.String("execution_id", runtime.ExecutionID)}).
+		WithGroup("fields")
+}
+
+func levelName(level slog.Level) string {
+	if level == constants.LogLevelTrace {
+		return "trace"
+	}
+	return strings.ToLower(level.String())
 }
 
 func getLogLevel() slog.Leveler {
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/constants/runtime"
+)
+
+// jsonRecords decodes the JSON records written to the buffer, one per line
+func jsonRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var res []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode record %q: %s", line, err)
+		}
+		res = append(res, record)
+	}
+	return res
+}
+
+func TestJSONLogger(t *testing.T) {
+	setLogLevel(t, "trace")
+	var buf bytes.Buffer
+	logger, err := PowerpipeLogger(FormatJSON, &destination{w: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("started", "port", 9033)
+	logger.With("component", "server").Warn("slow", "duration_ms", 1500)
+	logger.Log(context.Background(), constants.LogLevelTrace, "traced")
+	logger.Info("no fields")
+
+	records := jsonRecords(t, &buf)
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d: %s", len(records), buf.String())
+	}
+
+	// the timestamp varies, so check it separately
+	for _, record := range records {
+		ts, ok := record["ts"].(string)
+		if !ok {
+			t.Fatalf("expected a ts key, got %v", record)
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			t.Errorf("expected ts to be an RFC3339 timestamp, got %s", ts)
+		} else if parsed.Location() != time.UTC {
+			t.Errorf("expected ts to be UTC, got %s", ts)
+		}
+		delete(record, "ts")
+		if _, ok := record[slog.TimeKey]; ok {
+			t.Errorf("expected no time key, got %v", record)
+		}
+	}
+
+	expected := []map[string]any{
+		{"level": "info", "execution_id": runtime.ExecutionID, "msg": "started", "fields": map[string]any{"port": float64(9033)}},
+		{"level": "warn", "execution_id": runtime.ExecutionID, "msg": "slow", "fields": map[string]any{"component": "server", "duration_ms": float64(1500)}},
+		{"level": "trace", "execution_id": runtime.ExecutionID, "msg": "traced"},
+		{"level": "info", "execution_id": runtime.ExecutionID, "msg": "no fields"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("got %v, expected %v", records, expected)
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	setLogLevel(t, "warn")
+	var buf bytes.Buffer
+	logger, err := PowerpipeLogger(FormatJSON, &destination{w: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("not written")
+	logger.Error("written")
+
+	records := jsonRecords(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "written" {
+		t.Errorf("expected only the error record to be written, got %v", records)
+	}
+}
+
+func TestLoggerMultipleDestinations(t *testing.T) {
+	setLogLevel(t, "info")
+	var first, second bytes.Buffer
+	logger, err := PowerpipeLogger(FormatText, &destination{w: &first}, &destination{w: &second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.With("component", "server").Info("message")
+
+	for _, buf := range []*bytes.Buffer{&first, &second} {
+		if !strings.Contains(buf.String(), "msg=message component=server") {
+			t.Errorf("expected the record to be written to each destination, got %q", buf.String())
+		}
+	}
+}
+
+func TestLoggerInvalidFormat(t *testing.T) {
+	setLogLevel(t, "info")
+	_, err := PowerpipeLogger("xml")
+	expectedErr := "invalid log format 'xml' - must be text or json"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// the timestamp of a rotated log file, e.g. powerpipe-2024-05-01T10-00-00.000.log - timestamps sort lexically
+	rotatedTimeFormat   = "2006-01-02T15-04-05.000"
+	compressedExtension = ".gz"
+	megabyte            = 1024 * 1024
+)
+
+// RotationPolicy is the policy for rotating the log file, set by the log-max-size, log-max-age, log-max-files and
+// log-compress options
+type RotationPolicy struct {
+	// the size in megabytes at which the log file is rotated (0 to never rotate)
+	MaxSize int
+	// the number of days to retain rotated log files (0 to retain them regardless of age)
+	MaxAge int
+	// the number of rotated log files to retain (0 to retain them all)
+	MaxFiles int
+	// whether to gzip rotated log files
+	Compress bool
+}
+
+func (p RotationPolicy) validate() error {
+	if p.MaxSize < 0 || p.MaxAge < 0 || p.MaxFiles < 0 {
+		return fmt.Errorf("invalid log rotation policy - log-max-size, log-max-age and log-max-files must not be negative")
+	}
+	return nil
+}
+
+// rotatingWriter appends to a log file, which is renamed with the time it was rotated when writing to it would exceed
+// the maximum size. Rotated files are compressed and removed according to the policy in the background - if the
+// process exits first, they are compressed and removed when the log file is next opened
+type rotatingWriter struct {
+	path   string
+	policy RotationPolicy
+
+	mut  sync.Mutex
+	file *os.File
+	size int64
+
+	// serializes the compression and removal of rotated files
+	millMut sync.Mutex
+	// the compressions and removals in progress
+	millWg sync.WaitGroup
+}
+
+func newRotatingWriter(path string, policy RotationPolicy) (*rotatingWriter, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	w := &rotatingWriter{path: path, policy: policy}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.startMill()
+	return w, nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	maxSize := int64(w.policy.MaxSize) * megabyte
+	if maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) Close() error {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return w.file.Close()
+}
+
+func (w *rotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// rotate renames the log file and opens a new one - the caller must hold the lock
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	prefix, ext := w.rotatedNameParts()
+	rotatedPath := filepath.Join(filepath.Dir(w.path), prefix+time.Now().UTC().Format(rotatedTimeFormat)+ext)
+	if err := os.Rename(w.path, rotatedPath); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.startMill()
+	return nil
+}
+
+// rotatedNameParts returns the prefix and extension of the names of rotated log files, e.g. powerpipe- and .log
+func (w *rotatingWriter) rotatedNameParts() (string, string) {
+	name := filepath.Base(w.path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
+
+type rotatedFile struct {
+	path      string
+	timestamp time.Time
+}
+
+// rotatedFiles returns the rotated log files, newest first
+func (w *rotatingWriter) rotatedFiles() ([]rotatedFile, error) {
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix, ext := w.rotatedNameParts()
+	var res []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressedExtension), ext)
+		t, err := time.Parse(rotatedTimeFormat, timestamp)
+		if err != nil {
+			// not a rotated log file
+			continue
+		}
+		res = append(res, rotatedFile{path: filepath.Join(dir, name), timestamp: t})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].timestamp.After(res[j].timestamp)
+	})
+	return res, nil
+}
+
+// startMill compresses and removes rotated log files in the background
+func (w *rotatingWriter) startMill() {
+	w.millWg.Add(1)
+	go func() {
+		defer w.millWg.Done()
+		w.mill()
+	}()
+}
+
+// mill removes the rotated log files which exceed the maximum number or age, and compresses the rest (if enabled)
+func (w *rotatingWriter) mill() {
+	w.millMut.Lock()
+	defer w.millMut.Unlock()
+
+	files, err := w.rotatedFiles()
+	if err != nil {
+		slog.Warn("failed to list rotated log files", "error", err)
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -w.policy.MaxAge)
+	for i, f := range files {
+		expired := (w.policy.MaxFiles > 0 && i >= w.policy.MaxFiles) || (w.policy.MaxAge > 0 && f.timestamp.Before(cutoff))
+		switch {
+		case expired:
+			if err := os.Remove(f.path); err != nil {
+				slog.Warn("failed to remove rotated log file", "file", f.path, "error", err)
+			}
+		case w.policy.Compress && !strings.HasSuffix(f.path, compressedExtension):
+			if err := compressFile(f.path); err != nil {
+				slog.Warn("failed to compress rotated log file", "file", f.path, "error", err)
+			}
+		}
+	}
+}
+
+// compressFile gzips the file, replacing it with <path>.gz. The compressed file is written to a temporary file first,
+// so the file is not lost if the process exits while it is being compressed
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + compressedExtension + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path+compressedExtension); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// rotatedName returns the name of the log file powerpipe.log rotated at the given time
+func rotatedName(t time.Time) string {
+	return "powerpipe-" + t.UTC().Format(rotatedTimeFormat) + ".log"
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, entry := range entries {
+		res = append(res, entry.Name())
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestRotatingWriterRotation(t *testing.T) {
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+
+	tests := map[string]struct {
+		policy RotationPolicy
+		writes int
+		// the expected number of rotated files
+		expectedRotated int
+		// the expected size of the log file
+		expectedSize int
+	}{
+		"below max size": {
+			policy:          RotationPolicy{MaxSize: 1},
+			writes:          1,
+			expectedRotated: 0,
+			expectedSize:    len(chunk),
+		},
+		"exceeds max size": {
+			policy:          RotationPolicy{MaxSize: 1},
+			writes:          3,
+			expectedRotated: 2,
+			expectedSize:    len(chunk),
+		},
+		"never rotated": {
+			policy:          RotationPolicy{MaxSize: 0},
+			writes:          3,
+			expectedRotated: 0,
+			expectedSize:    3 * len(chunk),
+		},
+		"rotated files pruned": {
+			policy:          RotationPolicy{MaxSize: 1, MaxFiles: 1},
+			writes:          4,
+			expectedRotated: 1,
+			expectedSize:    len(chunk),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := newRotatingWriter(filepath.Join(dir, "powerpipe.log"), tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tc.writes; i++ {
+				if _, err := w.Write(chunk); err != nil {
+					t.Fatal(err)
+				}
+				// rotated files are named with the time (to the millisecond) they were rotated
+				time.Sleep(2 * time.Millisecond)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			w.millWg.Wait()
+
+			rotated, err := w.rotatedFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rotated) != tc.expectedRotated {
+				t.Errorf("got %d rotated files, expected %d: %v", len(rotated), tc.expectedRotated, listDir(t, dir))
+			}
+			for _, f := range rotated {
+				if info, err := os.Stat(f.path); err != nil || info.Size() != int64(len(chunk)) {
+					t.Errorf("expected rotated file %s to contain one write: %v", f.path, err)
+				}
+			}
+			info, err := os.Stat(filepath.Join(dir, "powerpipe.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != int64(tc.expectedSize) {
+				t.Errorf("got log file size %d, expected %d", info.Size(), tc.expectedSize)
+			}
+		})
+	}
+}
+
+func TestRotatingWriterPruning(t *testing.T) {
+	now := time.Now()
+	hourAgo := rotatedName(now.Add(-time.Hour))
+	dayAgo := rotatedName(now.Add(-24 * time.Hour))
+	weekAgo := rotatedName(now.Add(-8 * 24 * time.Hour))
+	monthAgo := rotatedName(now.Add(-31 * 24 * time.Hour))
+
+	tests := map[string]struct {
+		policy   RotationPolicy
+		existing []string
+		expected []string
+	}{
+		"retain all": {
+			policy:   RotationPolicy{},
+			existing: []string{hourAgo, dayAgo, weekAgo, monthAgo},
+			expected: []string{hourAgo, dayAgo, weekAgo, monthAgo},
+		},
+		"max files": {
+			policy:   RotationPolicy{MaxFiles: 2},
+			existing: []string{hourAgo, dayAgo, weekAgo, monthAgo},
+			expected: []string{hourAgo, dayAgo},
+		},
+		"max age": {
+			policy:   RotationPolicy{MaxAge: 7},
+			existing: []string{hourAgo, dayAgo, weekAgo, monthAgo},
+			expected: []string{hourAgo, dayAgo},
+		},
+		"max files and max age": {
+			policy:   RotationPolicy{MaxFiles: 1, MaxAge: 7},
+			existing: []string{hourAgo, dayAgo, weekAgo, monthAgo},
+			expected: []string{hourAgo},
+		},
+		"compressed files are pruned": {
+			policy:   RotationPolicy{MaxFiles: 1},
+			existing: []string{hourAgo + compressedExtension, dayAgo + compressedExtension},
+			expected: []string{hourAgo + compressedExtension},
+		},
+		"other files are retained": {
+			policy:   RotationPolicy{MaxFiles: 1},
+			existing: []string{hourAgo, dayAgo, "powerpipe-old.log", "notes.txt"},
+			expected: []string{hourAgo, "powerpipe-old.log", "notes.txt"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tc.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// rotated files are pruned when the log file is opened
+			w, err := newRotatingWriter(filepath.Join(dir, "powerpipe.log"), tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.millWg.Wait()
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := append([]string{"powerpipe.log"}, tc.expected...)
+			sort.Strings(expected)
+			if got := listDir(t, dir); !reflect.DeepEqual(got, expected) {
+				t.Errorf("got files %v, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestRotatingWriterCompression(t *testing.T) {
+	dir := t.TempDir()
+	rotated := rotatedName(time.Now().Add(-time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, rotated), []byte("rotated logs"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newRotatingWriter(filepath.Join(dir, "powerpipe.log"), RotationPolicy{MaxFiles: 5, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("current logs")); err != nil {
+		t.Fatal(err)
+	}
+	w.millWg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rotated file is replaced by its compressed copy - the current log file is not compressed
+	expected := []string{rotated + compressedExtension, "powerpipe.log"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got files %v, expected %v", got, expected)
+	}
+	f, err := os.Open(filepath.Join(dir, rotated+compressedExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "rotated logs" {
+		t.Errorf("got compressed content %q, expected %q", content, "rotated logs")
+	}
+}
+
+func TestRotationPolicyValidate(t *testing.T) {
+	for _, policy := range []RotationPolicy{{MaxSize: -1}, {MaxAge: -1}, {MaxFiles: -1}} {
+		if _, err := newRotatingWriter(filepath.Join(t.TempDir(), "powerpipe.log"), policy); err == nil {
+			t.Errorf("expected an error for policy %+v", policy)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// completion returns the completions at the byte offset of the document:
+//   - attribute and block names, at the start of a line
+//   - resource types, var, local and dependency mod names, in expressions
+//   - resource, variable and local names, after <type>. or <mod>.<type>.
+func (s *Server) completion(doc *document, offset int) []CompletionItem {
+	// the partial name before the cursor
+	start := offset
+	for start > 0 && isNameChar(doc.content[start-1]) {
+		start--
+	}
+	prefix := string(doc.content[start:offset])
+	lineStart := strings.LastIndexByte(string(doc.content[:start]), '\n') + 1
+	beforePrefix := string(doc.content[lineStart:start])
+
+	blocks := doc.enclosingBlocks(offset)
+	body := doc.body
+	if len(blocks) > 0 {
+		body = blocks[len(blocks)-1].Body
+	}
+
+	if attr := attributeAt(body, offset); attr != nil {
+		if inStringLiteral(attr.Expr, offset) {
+			return nil
+		}
+		return s.referenceCompletions(doc, prefix)
+	}
+	if strings.TrimSpace(beforePrefix) == "" {
+		return nameCompletions(blocks)
+	}
+	return s.referenceCompletions(doc, prefix)
+}
+
+// nameCompletions returns the attributes and nested blocks which may be set in the innermost of the blocks
+func nameCompletions(blocks []*hclsyntax.Block) []CompletionItem {
+	path := make([]string, len(blocks))
+	for i, block := range blocks {
+		path[i] = block.Type
+	}
+	s := schemaForBlock(path)
+	if s == nil {
+		return nil
+	}
+	var res []CompletionItem
+	for _, name := range s.attributes {
+		res = append(res, CompletionItem{Label: name, Kind: completionKindField, Detail: "attribute"})
+	}
+	for _, name := range s.blocks {
+		res = append(res, CompletionItem{Label: name, Kind: completionKindClass, Detail: "block"})
+	}
+	return res
+}
+
+// referenceCompletions returns the completions of the partial reference
+func (s *Server) referenceCompletions(doc *document, prefix string) []CompletionItem {
+	fileMod := s.index.modFor(doc.path)
+	parts := strings.Split(prefix, ".")
+	// the parts before the one being completed
+	qualifier := parts[:len(parts)-1]
+
+	switch {
+	case len(qualifier) == 0:
+		return s.rootCompletions(fileMod)
+	case len(qualifier) == 1 && (referenceableBlocks[qualifier[0]] || qualifier[0] == variablePrefix || qualifier[0] == localPrefix):
+		return s.declarationCompletions(fileMod, qualifier[0])
+	case len(qualifier) == 1 && s.index.isMod(qualifier[0]):
+		// the resource types of the dependency mod
+		types := make(map[string]bool)
+		for _, d := range s.index.declarations(qualifier[0]) {
+			if referenceableBlocks[d.referenceType()] {
+				types[d.referenceType()] = true
+			}
+		}
+		var res []CompletionItem
+		for _, t := range sortedKeys(types) {
+			res = append(res, CompletionItem{Label: t, Kind: completionKindKeyword})
+		}
+		return res
+	case len(qualifier) == 2 && s.index.isMod(qualifier[0]) && referenceableBlocks[qualifier[1]]:
+		return s.declarationCompletions(qualifier[0], qualifier[1])
+	}
+	return nil
+}
+
+// rootCompletions returns the names which may start a reference
+func (s *Server) rootCompletions(fileMod string) []CompletionItem {
+	var res []CompletionItem
+	for _, t := range sortedKeys(referenceableBlocks) {
+		res = append(res, CompletionItem{Label: t, Kind: completionKindKeyword})
+	}
+	res = append(res,
+		CompletionItem{Label: variablePrefix, Kind: completionKindKeyword},
+		CompletionItem{Label: localPrefix, Kind: completionKindKeyword})
+	mods := make(map[string]bool)
+	for _, name := range s.index.mods {
+		if name != "" && name != fileMod {
+			mods[name] = true
+		}
+	}
+	for _, name := range sortedKeys(mods) {
+		res = append(res, CompletionItem{Label: name, Kind: completionKindModule, Detail: "mod"})
+	}
+	return res
+}
+
+// declarationCompletions returns the names of the declarations of the given reference type in the mod
+func (s *Server) declarationCompletions(mod, referenceType string) []CompletionItem {
+	kind := completionKindField
+	if referenceType == variablePrefix || referenceType == localPrefix {
+		kind = completionKindVariable
+	}
+	var res []CompletionItem
+	for _, d := range s.index.declarations(mod) {
+		if d.referenceType() == referenceType {
+			res = append(res, CompletionItem{Label: d.shortName(), Kind: kind, Detail: d.title})
+		}
+	}
+	return res
+}
+
+// attributeAt returns the attribute of the body whose expression contains the byte offset
+func attributeAt(body *hclsyntax.Body, offset int) *hclsyntax.Attribute {
+	for _, attr := range body.Attributes {
+		if offset >= attr.EqualsRange.End.Byte && offset <= attr.SrcRange.End.Byte {
+			return attr
+		}
+	}
+	return nil
+}
+
+// inStringLiteral returns whether the byte offset is in the literal part of a string or heredoc, rather than in an
+// interpolated expression
+func inStringLiteral(expr hclsyntax.Expression, offset int) bool {
+	var innermost hclsyntax.Node
+	_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if contains(node.Range(), offset) {
+			innermost = node
+		}
+		return nil
+	})
+	literal, ok := innermost.(*hclsyntax.LiteralValueExpr)
+	if ok && literal.Val.Type() == cty.String {
+		return true
+	}
+	template, ok := innermost.(*hclsyntax.TemplateExpr)
+	return ok && offset > template.SrcRange.Start.Byte && offset < template.SrcRange.End.Byte
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func sortedKeys(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
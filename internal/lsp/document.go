@@ -0,0 +1,116 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// document is a mod file open in the editor
+type document struct {
+	path    string
+	content []byte
+	// the parsed body - this may be partial if the content has syntax errors
+	body  *hclsyntax.Body
+	diags hcl.Diagnostics
+}
+
+func newDocument(path string, content []byte) *document {
+	d := &document{path: path, content: content}
+	file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	d.diags = diags
+	if body, ok := file.Body.(*hclsyntax.Body); ok {
+		d.body = body
+	} else {
+		d.body = &hclsyntax.Body{}
+	}
+	return d
+}
+
+// offset returns the byte offset of the position in the document
+func (d *document) offset(pos Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(string(d.content[offset:]), '\n')
+		if next == -1 {
+			return len(d.content)
+		}
+		offset += next + 1
+	}
+	// the position character is in UTF-16 code units
+	for units := 0; units < pos.Character && offset < len(d.content); {
+		r, size := utf8.DecodeRune(d.content[offset:])
+		if r == '\n' {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// enclosingBlocks returns the blocks which contain the byte offset, outermost first
+func (d *document) enclosingBlocks(offset int) []*hclsyntax.Block {
+	var res []*hclsyntax.Block
+	body := d.body
+	for {
+		var inner *hclsyntax.Block
+		for _, block := range body.Blocks {
+			if contains(block.Body.SrcRange, offset) && offset > block.OpenBraceRange.Start.Byte {
+				inner = block
+				break
+			}
+		}
+		if inner == nil {
+			return res
+		}
+		res = append(res, inner)
+		body = inner.Body
+	}
+}
+
+// attributes returns the attributes of the document, including those of nested blocks
+func (d *document) attributes() []*hclsyntax.Attribute {
+	var res []*hclsyntax.Attribute
+	var walk func(body *hclsyntax.Body)
+	walk = func(body *hclsyntax.Body) {
+		for _, attr := range body.Attributes {
+			res = append(res, attr)
+		}
+		for _, block := range body.Blocks {
+			walk(block.Body)
+		}
+	}
+	walk(d.body)
+	return res
+}
+
+func contains(rng hcl.Range, offset int) bool {
+	return offset >= rng.Start.Byte && offset <= rng.End.Byte
+}
+
+// toRange converts an hcl range to a protocol range - hcl columns count characters, which are assumed to be single
+// UTF-16 code units
+func toRange(rng hcl.Range) Range {
+	return Range{
+		Start: Position{Line: max(rng.Start.Line-1, 0), Character: max(rng.Start.Column-1, 0)},
+		End:   Position{Line: max(rng.End.Line-1, 0), Character: max(rng.End.Column-1, 0)},
+	}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
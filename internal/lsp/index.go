@@ -0,0 +1,241 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// referenceableBlocks are the block types which may be referenced as <type>.<name>
+var referenceableBlocks = map[string]bool{
+	schema.BlockTypeQuery:     true,
+	schema.BlockTypeControl:   true,
+	schema.BlockTypeBenchmark: true,
+	schema.BlockTypeDashboard: true,
+	schema.BlockTypeContainer: true,
+	schema.BlockTypeCard:      true,
+	schema.BlockTypeChart:     true,
+	schema.BlockTypeFlow:      true,
+	schema.BlockTypeGraph:     true,
+	schema.BlockTypeHierarchy: true,
+	schema.BlockTypeImage:     true,
+	schema.BlockTypeInput:     true,
+	schema.BlockTypeTable:     true,
+	schema.BlockTypeText:      true,
+	schema.BlockTypeNode:      true,
+	schema.BlockTypeEdge:      true,
+	schema.BlockTypeCategory:  true,
+}
+
+// the reference prefixes of variables and locals
+const (
+	variablePrefix = "var"
+	localPrefix    = "local"
+)
+
+// declaration is a resource, variable or local declared in a mod file
+type declaration struct {
+	// the name used to reference the declaration within its mod, e.g. query.instances, var.region or local.tags
+	name  string
+	title string
+	rng   hcl.Range
+}
+
+// referenceType returns the first part of the declaration name, i.e. the resource type, var or local
+func (d *declaration) referenceType() string {
+	return strings.SplitN(d.name, ".", 2)[0]
+}
+
+// shortName returns the declaration name without the resource type, var or local prefix
+func (d *declaration) shortName() string {
+	return strings.SplitN(d.name, ".", 2)[1]
+}
+
+// index is an index of the declarations of the workspace mod and its installed dependency mods
+type index struct {
+	workspacePath string
+	// the short names of the mods, keyed by mod directory
+	mods map[string]string
+	// the declarations of each mod file, keyed by file path
+	files map[string][]*declaration
+}
+
+func newIndex(workspacePath string) *index {
+	return &index{workspacePath: workspacePath}
+}
+
+// build indexes the files of the workspace mod and its installed dependency mods
+func (idx *index) build() error {
+	idx.mods = make(map[string]string)
+	idx.files = make(map[string][]*declaration)
+
+	modDirs := []string{idx.workspacePath}
+	// dependency mods are installed in the workspace mod directory, each in a directory containing its mod file
+	depsPath := filepaths.WorkspaceModPath(idx.workspacePath)
+	_ = filepath.WalkDir(depsPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == depsPath {
+			return nil
+		}
+		if _, exists := parse.ModFileExists(path); exists {
+			modDirs = append(modDirs, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	for _, modDir := range modDirs {
+		idx.mods[modDir] = modName(modDir)
+		paths, err := filehelpers.ListFiles(modDir, &filehelpers.ListOptions{
+			Flags: filehelpers.FilesRecursive,
+			Exclude: []string{
+				fmt.Sprintf("%s/.*", modDir),
+				fmt.Sprintf("%s/.*/**", modDir),
+			},
+			Include: filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+		})
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			idx.update(path, content)
+		}
+	}
+	return nil
+}
+
+// update re-indexes the declarations of the file, from its (possibly unsaved) content
+func (idx *index) update(path string, content []byte) {
+	file, _ := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	var declarations []*declaration
+	if body, ok := file.Body.(*hclsyntax.Body); ok {
+		declarations = fileDeclarations(body, true)
+	}
+	idx.files[path] = declarations
+}
+
+// modFor returns the short name of the mod which contains the file
+func (idx *index) modFor(path string) string {
+	var dir, name string
+	for modDir, modName := range idx.mods {
+		if strings.HasPrefix(path, modDir+string(filepath.Separator)) && len(modDir) > len(dir) {
+			dir, name = modDir, modName
+		}
+	}
+	return name
+}
+
+// isMod returns whether the name is the short name of the workspace mod or a dependency mod
+func (idx *index) isMod(name string) bool {
+	for _, modName := range idx.mods {
+		if modName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the declaration with the given name in the given mod, and the path of the file which declares it
+func (idx *index) lookup(mod, name string) (*declaration, string) {
+	for path, declarations := range idx.files {
+		if idx.modFor(path) != mod {
+			continue
+		}
+		for _, d := range declarations {
+			if d.name == name {
+				return d, path
+			}
+		}
+	}
+	return nil, ""
+}
+
+// declarations returns the declarations of the given mod, sorted by name
+func (idx *index) declarations(mod string) []*declaration {
+	var res []*declaration
+	for path, declarations := range idx.files {
+		if idx.modFor(path) == mod {
+			res = append(res, declarations...)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
+
+// fileDeclarations returns the resources, variables and locals declared by the blocks of the body
+func fileDeclarations(body *hclsyntax.Body, topLevel bool) []*declaration {
+	var res []*declaration
+	for _, block := range body.Blocks {
+		switch {
+		case topLevel && block.Type == schema.BlockTypeVariable && len(block.Labels) > 0:
+			res = append(res, &declaration{
+				name:  variablePrefix + "." + block.Labels[0],
+				title: stringAttribute(block.Body, "description"),
+				rng:   block.DefRange(),
+			})
+		case topLevel && block.Type == schema.BlockTypeLocals:
+			for name, attr := range block.Body.Attributes {
+				res = append(res, &declaration{name: localPrefix + "." + name, rng: attr.NameRange})
+			}
+		case referenceableBlocks[block.Type] && len(block.Labels) > 0:
+			res = append(res, &declaration{
+				name:  block.Type + "." + block.Labels[0],
+				title: stringAttribute(block.Body, "title"),
+				rng:   block.DefRange(),
+			})
+		}
+		// nested resources (e.g. the cards of a dashboard) may also be referenced
+		res = append(res, fileDeclarations(block.Body, false)...)
+	}
+	return res
+}
+
+// modName returns the short name of the mod in the directory, from its mod file
+func modName(modDir string) string {
+	modFilePath, exists := parse.ModFileExists(modDir)
+	if !exists {
+		return ""
+	}
+	content, err := os.ReadFile(modFilePath)
+	if err != nil {
+		return ""
+	}
+	file, _ := hclsyntax.ParseConfig(content, modFilePath, hcl.InitialPos)
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return ""
+	}
+	for _, block := range body.Blocks {
+		if block.Type == schema.BlockTypeMod && len(block.Labels) > 0 {
+			return block.Labels[0]
+		}
+	}
+	return ""
+}
+
+// stringAttribute returns the value of the attribute, if it is a literal string
+func stringAttribute(body *hclsyntax.Body, name string) string {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return ""
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
@@ -0,0 +1,120 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/powerpipe/internal/modlint"
+)
+
+// reference is a reference to a declaration, e.g. query.instances, var.region or aws_compliance.control.s3_public
+type reference struct {
+	// the short name of the mod the declaration is in
+	mod string
+	// the name of the declaration within its mod
+	name string
+	rng  hcl.Range
+}
+
+// resolveReference returns the reference made by the traversal, from a file in the given mod - traversals which do
+// not reference a resource, variable or local (e.g. param.region or self.input.type.value) are not references
+func (idx *index) resolveReference(traversal hcl.Traversal, fileMod string) *reference {
+	root := traversal.RootName()
+	var names []string
+	for _, step := range traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		names = append(names, attr.Name)
+	}
+
+	switch {
+	case referenceableBlocks[root] || root == variablePrefix || root == localPrefix:
+		if len(names) < 1 {
+			return nil
+		}
+		return &reference{mod: fileMod, name: root + "." + names[0], rng: traversal.SourceRange()}
+	case idx.isMod(root):
+		// a reference to a resource of a dependency mod, i.e. <mod>.<type>.<name>
+		if len(names) < 2 || !referenceableBlocks[names[0]] {
+			return nil
+		}
+		return &reference{mod: root, name: names[0] + "." + names[1], rng: traversal.SourceRange()}
+	}
+	return nil
+}
+
+// definition returns the location of the declaration referenced at the byte offset of the document
+func (s *Server) definition(doc *document, offset int) *Location {
+	fileMod := s.index.modFor(doc.path)
+	for _, attr := range doc.attributes() {
+		for _, traversal := range attr.Expr.Variables() {
+			if !contains(traversal.SourceRange(), offset) {
+				continue
+			}
+			ref := s.index.resolveReference(traversal, fileMod)
+			if ref == nil {
+				return nil
+			}
+			decl, path := s.index.lookup(ref.mod, ref.name)
+			if decl == nil {
+				return nil
+			}
+			return &Location{URI: pathToURI(path), Range: toRange(decl.rng)}
+		}
+	}
+	return nil
+}
+
+// documentDiagnostics returns the syntax errors of the document and its references to undeclared resources,
+// variables and locals
+func (s *Server) documentDiagnostics(doc *document) []Diagnostic {
+	res := []Diagnostic{}
+	for _, diag := range doc.diags {
+		if diag.Subject == nil {
+			continue
+		}
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += ": " + diag.Detail
+		}
+		res = append(res, Diagnostic{Range: toRange(*diag.Subject), Severity: severityFromHcl(diag.Severity), Source: diagnosticSource, Message: message})
+	}
+	if doc.diags.HasErrors() {
+		// references are not checked until the document parses
+		return res
+	}
+
+	fileMod := s.index.modFor(doc.path)
+	for _, attr := range doc.attributes() {
+		for _, traversal := range attr.Expr.Variables() {
+			ref := s.index.resolveReference(traversal, fileMod)
+			if ref == nil {
+				continue
+			}
+			if decl, _ := s.index.lookup(ref.mod, ref.name); decl != nil {
+				continue
+			}
+			name := ref.name
+			if ref.mod != fileMod {
+				name = ref.mod + "." + name
+			}
+			res = append(res, Diagnostic{
+				Range:    toRange(ref.rng),
+				Severity: severityError,
+				Source:   diagnosticSource,
+				Code:     modlint.RuleBrokenReference,
+				Message:  fmt.Sprintf("'%s' is not defined", name),
+			})
+		}
+	}
+	return res
+}
+
+func severityFromHcl(severity hcl.DiagnosticSeverity) int {
+	if severity == hcl.DiagWarning {
+		return severityWarning
+	}
+	return severityError
+}
@@ -0,0 +1,201 @@
+package lsp
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// the resources decoded from each block type - their hcl tags define the attributes and nested blocks of the block
+var blockResources = map[string]any{
+	schema.BlockTypeMod:       &modconfig.Mod{},
+	schema.BlockTypeQuery:     &modconfig.Query{},
+	schema.BlockTypeControl:   &modconfig.Control{},
+	schema.BlockTypeBenchmark: &modconfig.Benchmark{},
+	schema.BlockTypeDashboard: &modconfig.Dashboard{},
+	schema.BlockTypeContainer: &modconfig.DashboardContainer{},
+	schema.BlockTypeCard:      &modconfig.DashboardCard{},
+	schema.BlockTypeChart:     &modconfig.DashboardChart{},
+	schema.BlockTypeFlow:      &modconfig.DashboardFlow{},
+	schema.BlockTypeGraph:     &modconfig.DashboardGraph{},
+	schema.BlockTypeHierarchy: &modconfig.DashboardHierarchy{},
+	schema.BlockTypeImage:     &modconfig.DashboardImage{},
+	schema.BlockTypeInput:     &modconfig.DashboardInput{},
+	schema.BlockTypeTable:     &modconfig.DashboardTable{},
+	schema.BlockTypeText:      &modconfig.DashboardText{},
+	schema.BlockTypeNode:      &modconfig.DashboardNode{},
+	schema.BlockTypeEdge:      &modconfig.DashboardEdge{},
+	schema.BlockTypeCategory:  &modconfig.DashboardCategory{},
+	schema.BlockTypeWith:      &modconfig.DashboardWith{},
+}
+
+// blockSchema is the attributes and nested blocks which may be set in a block
+type blockSchema struct {
+	attributes []string
+	blocks     []string
+	// the struct types of nested blocks which are decoded from the hcl tags of the block resource
+	nested map[string]reflect.Type
+}
+
+// topLevelSchema returns the schema of the top level of a mod file
+func topLevelSchema() *blockSchema {
+	s := &blockSchema{}
+	for _, b := range parse.WorkspaceBlockSchema.Blocks {
+		if b.Type == schema.BlockTypeMod || b.Type == schema.BlockTypeVariable || b.Type == schema.BlockTypeLocals || blockResources[b.Type] != nil {
+			s.blocks = append(s.blocks, b.Type)
+		}
+	}
+	return s.sorted()
+}
+
+// schemaForBlock returns the schema of the innermost of the given nested blocks, e.g. [dashboard, container, card]
+func schemaForBlock(path []string) *blockSchema {
+	if len(path) == 0 {
+		return topLevelSchema()
+	}
+	var parent *blockSchema
+	for _, blockType := range path {
+		var s *blockSchema
+		if parent != nil && parent.nested[blockType] != nil {
+			s = schemaForType(parent.nested[blockType])
+		} else {
+			s = schemaForBlockType(blockType)
+		}
+		if s == nil {
+			return nil
+		}
+		parent = s
+	}
+	return parent
+}
+
+// schemaForBlockType returns the schema of a block of the given type, which may be a resource, variable or param
+func schemaForBlockType(blockType string) *blockSchema {
+	switch blockType {
+	case schema.BlockTypeVariable:
+		return schemaFromHcl(parse.VariableBlockSchema.Attributes, parse.VariableBlockSchema.Blocks)
+	case schema.BlockTypeParam:
+		return schemaFromHcl(parse.ParamDefBlockSchema.Attributes, nil)
+	}
+	resource, ok := blockResources[blockType]
+	if !ok {
+		return nil
+	}
+	s := schemaForType(reflect.TypeOf(resource))
+
+	// add the attributes and blocks which are decoded explicitly rather than from hcl tags
+	if _, ok := resource.(modconfig.QueryProvider); ok {
+		s.blocks = append(s.blocks, schema.BlockTypeParam)
+		if blockType == schema.BlockTypeQuery {
+			s.attributes = remove(s.attributes, schema.AttributeTypeQuery)
+		} else {
+			s.attributes = append(s.attributes, schema.AttributeTypeArgs)
+		}
+	}
+	if _, ok := resource.(modconfig.NodeAndEdgeProvider); ok {
+		s.blocks = append(s.blocks, schema.BlockTypeCategory, schema.BlockTypeNode, schema.BlockTypeEdge)
+	}
+	if _, ok := resource.(modconfig.WithProvider); ok {
+		s.blocks = append(s.blocks, schema.BlockTypeWith)
+	}
+	switch blockType {
+	case schema.BlockTypeMod:
+		s.blocks = append(s.blocks, schema.BlockTypeRequire)
+	case schema.BlockTypeDashboard:
+		s.blocks = append(s.blocks, blockTypes(parse.DashboardBlockSchema)...)
+	case schema.BlockTypeContainer:
+		s.blocks = append(s.blocks, blockTypes(parse.DashboardContainerBlockSchema)...)
+	case schema.BlockTypeBenchmark:
+		for _, a := range parse.BenchmarkBlockSchema.Attributes {
+			s.attributes = append(s.attributes, a.Name)
+		}
+	}
+	return s.sorted()
+}
+
+// schemaForType returns the schema defined by the hcl tags of the struct type, including those of embedded structs
+func schemaForType(t reflect.Type) *blockSchema {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	s := &blockSchema{nested: make(map[string]reflect.Type)}
+	if t.Kind() != reflect.Struct {
+		return s
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			embedded := schemaForType(field.Type)
+			s.attributes = append(s.attributes, embedded.attributes...)
+			s.blocks = append(s.blocks, embedded.blocks...)
+			for name, nestedType := range embedded.nested {
+				s.nested[name] = nestedType
+			}
+			continue
+		}
+		tag := field.Tag.Get("hcl")
+		name, kind, _ := strings.Cut(tag, ",")
+		switch {
+		case name == "" || kind == "label" || kind == "remain":
+		case kind == "block":
+			s.blocks = append(s.blocks, name)
+			s.nested[name] = field.Type
+		default:
+			s.attributes = append(s.attributes, name)
+		}
+	}
+	return s.sorted()
+}
+
+// sorted sorts and de-duplicates the attributes and blocks of the schema
+func (s *blockSchema) sorted() *blockSchema {
+	s.attributes = unique(s.attributes)
+	s.blocks = unique(s.blocks)
+	return s
+}
+
+func schemaFromHcl(attributes []hcl.AttributeSchema, blocks []hcl.BlockHeaderSchema) *blockSchema {
+	s := &blockSchema{}
+	for _, a := range attributes {
+		s.attributes = append(s.attributes, a.Name)
+	}
+	for _, b := range blocks {
+		s.blocks = append(s.blocks, b.Type)
+	}
+	return s.sorted()
+}
+
+// blockTypes returns the types of the blocks of the hcl schema
+func blockTypes(s *hcl.BodySchema) []string {
+	res := make([]string, len(s.Blocks))
+	for i, b := range s.Blocks {
+		res[i] = b.Type
+	}
+	return res
+}
+
+func unique(values []string) []string {
+	sort.Strings(values)
+	var res []string
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func remove(values []string, value string) []string {
+	var res []string
+	for _, v := range values {
+		if v != value {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,265 @@
+// Package lsp implements a language server for mod files, providing completion of attribute, block and resource
+// names, go to definition of references (including to the resources of dependency mods) and diagnostics.
+//
+// The server communicates over a reader and writer (stdin and stdout when run by an editor) using the language server
+// protocol. Documents are synchronised in full. Syntax errors and broken references are reported as a document is
+// edited; when a document is saved, the whole mod is linted (see modlint) and the issues of every file are reported.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/powerpipe/internal/modlint"
+)
+
+// the source of the diagnostics published by the server
+const diagnosticSource = "powerpipe"
+
+// document sync kinds
+const textDocumentSyncFull = 1
+
+// Server is a language server for the mod in a workspace directory
+type Server struct {
+	workspacePath string
+	conn          *conn
+	index         *index
+	// the documents open in the editor, keyed by path
+	documents map[string]*document
+	// the files which have diagnostics published, so these can be cleared
+	published   map[string]bool
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer returns a server for the mod in the workspace directory - this is replaced by the root of the editor
+// workspace, if the editor provides one
+func NewServer(workspacePath string) *Server {
+	return &Server{
+		workspacePath: workspacePath,
+		documents:     make(map[string]*document),
+		published:     make(map[string]bool),
+	}
+}
+
+// Run serves requests read from r, writing responses to w, until the exit notification is received or r is closed
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		req, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(ctx, req)
+		// notifications have no id and are not responded to
+		if req.ID == nil {
+			if rpcErr != nil {
+				slog.Warn("lsp notification failed", "method", req.Method, "error", rpcErr.Message)
+			}
+			continue
+		}
+		if err := s.conn.write(&response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, req *request) (any, *responseError) {
+	if !s.initialized && req.Method != "initialize" {
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.initialize(params), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.openDocument(params.TextDocument.URI, []byte(params.TextDocument.Text))
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		// documents are synchronised in full, so the last change is the content of the document
+		if len(params.ContentChanges) > 0 {
+			s.openDocument(params.TextDocument.URI, []byte(params.ContentChanges[len(params.ContentChanges)-1].Text))
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		s.lint(ctx)
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.closeDocument(uriToPath(params.TextDocument.URI))
+		return nil, nil
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc, ok := s.documents[uriToPath(params.TextDocument.URI)]
+		if !ok {
+			return []CompletionItem{}, nil
+		}
+		items := s.completion(doc, doc.offset(params.Position))
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return items, nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc, ok := s.documents[uriToPath(params.TextDocument.URI)]
+		if !ok {
+			return nil, nil
+		}
+		return s.definition(doc, doc.offset(params.Position)), nil
+	}
+	if strings.HasPrefix(req.Method, "$/") {
+		// optional notifications and requests may be ignored
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+}
+
+func (s *Server) initialize(params initializeParams) any {
+	if params.RootURI != "" {
+		s.workspacePath = uriToPath(params.RootURI)
+	} else if params.RootPath != "" {
+		s.workspacePath = params.RootPath
+	}
+	s.index = newIndex(s.workspacePath)
+	if err := s.index.build(); err != nil {
+		slog.Warn("failed to index workspace", "error", err)
+	}
+	s.initialized = true
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    textDocumentSyncFull,
+				"save":      true,
+			},
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"."},
+			},
+			"definitionProvider": true,
+		},
+		"serverInfo": map[string]any{"name": "powerpipe"},
+	}
+}
+
+// openDocument sets the content of an open document, re-indexing it and publishing its diagnostics
+func (s *Server) openDocument(uri string, content []byte) {
+	path := uriToPath(uri)
+	doc := newDocument(path, content)
+	s.documents[path] = doc
+	s.index.update(path, content)
+	s.publish(path, s.documentDiagnostics(doc))
+}
+
+// closeDocument closes the document - its declarations are re-indexed from the saved file
+func (s *Server) closeDocument(path string) {
+	delete(s.documents, path)
+	if content, err := os.ReadFile(path); err == nil {
+		s.index.update(path, content)
+	} else {
+		delete(s.index.files, path)
+	}
+}
+
+// lint lints the saved mod files, publishing the issues of each file
+func (s *Server) lint(ctx context.Context) {
+	if _, exists := parse.ModFileExists(s.workspacePath); !exists {
+		return
+	}
+	issues, err := modlint.Lint(ctx, s.workspacePath)
+	if err != nil {
+		slog.Warn("failed to lint workspace", "error", err)
+		return
+	}
+
+	modFilePath, _ := parse.ModFileExists(s.workspacePath)
+	diagnostics := make(map[string][]Diagnostic)
+	for _, issue := range issues {
+		// issues which are not in a file (e.g. load errors) are reported on the mod file
+		path, line := modFilePath, 1
+		if issue.File != "" {
+			path, line = filepath.Join(s.workspacePath, issue.File), max(issue.Line, 1)
+		}
+		severity := severityWarning
+		if issue.Severity == modlint.SeverityError {
+			severity = severityError
+		}
+		diagnostics[path] = append(diagnostics[path], Diagnostic{
+			Range:    Range{Start: Position{Line: line - 1}, End: Position{Line: line}},
+			Severity: severity,
+			Source:   diagnosticSource,
+			Code:     issue.Rule,
+			Message:  issue.Message,
+		})
+	}
+
+	// clear the diagnostics of files which no longer have issues
+	for path := range s.published {
+		if _, ok := diagnostics[path]; !ok {
+			s.publish(path, []Diagnostic{})
+		}
+	}
+	for path, fileDiagnostics := range diagnostics {
+		s.publish(path, fileDiagnostics)
+	}
+}
+
+func (s *Server) publish(path string, diagnostics []Diagnostic) {
+	if len(diagnostics) == 0 {
+		delete(s.published, path)
+	} else {
+		s.published[path] = true
+	}
+	err := s.conn.write(&notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: pathToURI(path), Diagnostics: diagnostics},
+	})
+	if err != nil {
+		slog.Warn("failed to publish diagnostics", "error", err)
+	}
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
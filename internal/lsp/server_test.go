@@ -0,0 +1,158 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+const testModFile = `mod "local" {
+  title = "Local"
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+query "instances" {
+  title = "Instances"
+  sql   = "select 1"
+}
+
+control "public" {
+  query = dep.query.buckets
+  args  = { region = var.region }
+}
+
+control "broken" {
+  query = query.missing
+}
+`
+
+const testDependencyModFile = `mod "dep" {
+}
+
+query "buckets" {
+  title = "Buckets"
+  sql   = "select 1"
+}
+`
+
+func newTestServer(t *testing.T) (*Server, *document) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	dir := t.TempDir()
+	depDir := filepath.Join(dir, ".powerpipe", "mods", "github.com", "turbot", "dep@v1.0.0")
+	if err := os.MkdirAll(depDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(depDir, "mod.pp"), []byte(testDependencyModFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mod.pp")
+	if err := os.WriteFile(path, []byte(testModFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(dir)
+	s.index = newIndex(dir)
+	if err := s.index.build(); err != nil {
+		t.Fatal(err)
+	}
+	return s, newDocument(path, []byte(testModFile))
+}
+
+func TestDefinition(t *testing.T) {
+	s, doc := newTestServer(t)
+
+	cases := map[string]struct {
+		reference string
+		file      string
+		line      int
+	}{
+		"dependency resource": {reference: "dep.query.buckets", file: "dep@v1.0.0/mod.pp", line: 3},
+		"variable":            {reference: "var.region", file: "mod.pp", line: 4},
+		"missing":             {reference: "query.missing"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			offset := strings.Index(testModFile, tc.reference) + 2
+			location := s.definition(doc, offset)
+			if tc.file == "" {
+				if location != nil {
+					t.Fatalf("expected no definition, got %v", location)
+				}
+				return
+			}
+			if location == nil {
+				t.Fatal("expected a definition")
+			}
+			if !strings.HasSuffix(location.URI, tc.file) || location.Range.Start.Line != tc.line {
+				t.Errorf("expected %s:%d, got %s:%d", tc.file, tc.line, location.URI, location.Range.Start.Line)
+			}
+		})
+	}
+}
+
+func TestDocumentDiagnostics(t *testing.T) {
+	s, doc := newTestServer(t)
+
+	diagnostics := s.documentDiagnostics(doc)
+	if len(diagnostics) != 1 || diagnostics[0].Message != "'query.missing' is not defined" {
+		t.Errorf("expected a broken reference to query.missing, got %v", diagnostics)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	cases := map[string]struct {
+		// the content, with the cursor marked by |
+		content  string
+		expected []string
+	}{
+		"resource names": {
+			content:  "control \"a\" {\n  query = query.|\n}\n",
+			expected: []string{"instances"},
+		},
+		"dependency resource names": {
+			content:  "control \"a\" {\n  query = dep.query.|\n}\n",
+			expected: []string{"buckets"},
+		},
+		"dependency resource types": {
+			content:  "control \"a\" {\n  query = dep.|\n}\n",
+			expected: []string{"query"},
+		},
+		"variables": {
+			content:  "control \"a\" {\n  args = { region = var.| }\n}\n",
+			expected: []string{"region"},
+		},
+		"string literal": {
+			content:  "query \"a\" {\n  sql = \"select |\"\n}\n",
+			expected: nil,
+		},
+		"param attributes": {
+			content:  "query \"a\" {\n  param \"p\" {\n    |\n  }\n}\n",
+			expected: []string{"default", "description"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			offset := strings.Index(tc.content, "|")
+			content := strings.Replace(tc.content, "|", "", 1)
+			doc := newDocument(filepath.Join(s.workspacePath, "test.pp"), []byte(content))
+
+			var labels []string
+			for _, item := range s.completion(doc, offset) {
+				labels = append(labels, item.Label)
+			}
+			if strings.Join(labels, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("expected %v, got %v", tc.expected, labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package modcompat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the kinds of breaking change
+const (
+	KindRemoved             = "removed"
+	KindRenamed             = "renamed"
+	KindParamRemoved        = "param-removed"
+	KindParamRequired       = "param-required"
+	KindVariableRemoved     = "variable-removed"
+	KindVariableTypeChanged = "variable-type-changed"
+	KindVariableRequired    = "variable-required"
+)
+
+// Change is a breaking change between two versions of a mod
+type Change struct {
+	Kind string `json:"kind" yaml:"kind"`
+	// the name of the resource (or var.<name> for a variable) in the old version
+	Resource string `json:"resource" yaml:"resource"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+// Compare returns the breaking changes from the old to the new surface of a mod, sorted by resource. A removed
+// resource is reported as renamed if a new resource of the same type has the same title or sql.
+func Compare(old, new *Surface) []*Change {
+	var res []*Change
+
+	for name, oldResource := range old.Resources {
+		newResource, ok := new.Resources[name]
+		if !ok {
+			if renamed := findRenamed(oldResource, old, new); renamed != "" {
+				res = append(res, &Change{Kind: KindRenamed, Resource: name, Message: "renamed to " + renamed})
+			} else {
+				res = append(res, &Change{Kind: KindRemoved, Resource: name, Message: "removed"})
+			}
+			continue
+		}
+		res = append(res, compareParams(oldResource, newResource)...)
+	}
+
+	for name, oldVariable := range old.Variables {
+		resource := "var." + name
+		newVariable, ok := new.Variables[name]
+		switch {
+		case !ok:
+			res = append(res, &Change{Kind: KindVariableRemoved, Resource: resource, Message: "removed"})
+		case oldVariable.Type != newVariable.Type:
+			res = append(res, &Change{
+				Kind:     KindVariableTypeChanged,
+				Resource: resource,
+				Message:  fmt.Sprintf("type changed from %s to %s", typeDisplayString(oldVariable.Type), typeDisplayString(newVariable.Type)),
+			})
+		case oldVariable.HasDefault && !newVariable.HasDefault:
+			res = append(res, &Change{Kind: KindVariableRequired, Resource: resource, Message: "default removed, so a value is now required"})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Resource != res[j].Resource {
+			return res[i].Resource < res[j].Resource
+		}
+		return res[i].Message < res[j].Message
+	})
+	return res
+}
+
+// findRenamed returns the name of the resource which the removed resource was renamed to, if any
+func findRenamed(removed *Resource, old, new *Surface) string {
+	resourceType := strings.SplitN(removed.Name, ".", 2)[0]
+	var candidates []string
+	for name, r := range new.Resources {
+		// only resources added in the new version may be renames
+		if _, existed := old.Resources[name]; existed || !strings.HasPrefix(name, resourceType+".") {
+			continue
+		}
+		if (removed.Title != "" && r.Title == removed.Title) || (removed.SQL != "" && r.SQL == removed.SQL) {
+			candidates = append(candidates, name)
+		}
+	}
+	// a rename is only reported if it is unambiguous
+	if len(candidates) != 1 {
+		return ""
+	}
+	return candidates[0]
+}
+
+func compareParams(old, new *Resource) []*Change {
+	var res []*Change
+	newParams := make(map[string]*Param)
+	for _, p := range new.Params {
+		newParams[p.Name] = p
+	}
+	oldParams := make(map[string]*Param)
+	for _, p := range old.Params {
+		oldParams[p.Name] = p
+		newParam, ok := newParams[p.Name]
+		switch {
+		case !ok:
+			res = append(res, &Change{Kind: KindParamRemoved, Resource: old.Name, Message: fmt.Sprintf("param '%s' removed", p.Name)})
+		case p.HasDefault && !newParam.HasDefault:
+			res = append(res, &Change{Kind: KindParamRequired, Resource: old.Name, Message: fmt.Sprintf("param '%s' default removed, so a value is now required", p.Name)})
+		}
+	}
+	for _, p := range new.Params {
+		if _, ok := oldParams[p.Name]; !ok && !p.HasDefault {
+			res = append(res, &Change{Kind: KindParamRequired, Resource: old.Name, Message: fmt.Sprintf("param '%s' added without a default", p.Name)})
+		}
+	}
+	return res
+}
+
+func typeDisplayString(t string) string {
+	if t == "" {
+		return "any"
+	}
+	return t
+}
@@ -0,0 +1,127 @@
+package modcompat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+const oldModFile = `mod "local" {
+  title = "Local"
+}
+
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+variable "tags" {
+  type = list(string)
+}
+
+variable "limit" {
+  default = 10
+}
+
+query "instances" {
+  title = "Instances"
+  sql   = "select * from instances"
+}
+
+query "buckets" {
+  sql = "select * from buckets"
+
+  param "region" {
+    default = "us-east-1"
+  }
+
+  param "limit" {
+    default = 10
+  }
+}
+
+dashboard "overview" {
+  card "total" {
+    sql = "select 1"
+  }
+}
+`
+
+const newModFile = `mod "local" {
+  title = "Local"
+}
+
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+variable "tags" {
+  type = map(string)
+}
+
+variable "limit" {
+}
+
+query "all_instances" {
+  title = "Instances"
+  sql   = "select * from instances where true"
+}
+
+query "buckets" {
+  sql = "select * from buckets"
+
+  param "region" {
+  }
+
+  param "account" {
+  }
+}
+
+dashboard "overview" {
+}
+`
+
+func TestCompare(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+
+	oldSurface := loadTestSurface(t, oldModFile)
+	newSurface := loadTestSurface(t, newModFile)
+
+	expected := []Change{
+		{Kind: KindRemoved, Resource: "card.total", Message: "removed"},
+		{Kind: KindParamRequired, Resource: "query.buckets", Message: "param 'account' added without a default"},
+		{Kind: KindParamRemoved, Resource: "query.buckets", Message: "param 'limit' removed"},
+		{Kind: KindParamRequired, Resource: "query.buckets", Message: "param 'region' default removed, so a value is now required"},
+		{Kind: KindRenamed, Resource: "query.instances", Message: "renamed to query.all_instances"},
+		{Kind: KindVariableRequired, Resource: "var.limit", Message: "default removed, so a value is now required"},
+		{Kind: KindVariableTypeChanged, Resource: "var.tags", Message: "type changed from list(string) to map(string)"},
+	}
+	changes := Compare(oldSurface, newSurface)
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, c := range changes {
+		if *c != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], *c)
+		}
+	}
+
+	if changes := Compare(oldSurface, oldSurface); len(changes) != 0 {
+		t.Errorf("expected no changes comparing a version with itself, got %v", changes)
+	}
+}
+
+func loadTestSurface(t *testing.T, modFile string) *Surface {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mod.pp"), []byte(modFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSurface(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package modcompat
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+// LoadRevisionSurface reads the public surface of the mod in the directory as of a revision (e.g. a tag, branch or
+// commit) of the Git repository containing the directory
+func LoadRevisionSurface(modPath, revision string) (*Surface, error) {
+	modPath, err := filepath.Abs(modPath)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpenWithOptions(modPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("the mod must be in a Git repository to compare revisions: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	// the path of the mod within the repository, using forward slashes as Git does
+	prefix, err := filepath.Rel(worktree.Filesystem.Root(), modPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSurface()
+	err = tree.Files().ForEach(func(f *object.File) error {
+		name := f.Name
+		if prefix != "." {
+			if !strings.HasPrefix(name, prefix+"/") {
+				return nil
+			}
+			name = strings.TrimPrefix(name, prefix+"/")
+		}
+		if !isModFile(name) {
+			return nil
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		return s.addFile(revision+":"+f.Name, []byte(content))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// isModFile returns whether the file (relative to the mod directory) is a mod file outside hidden directories
+func isModFile(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	ext := path.Ext(name)
+	for _, modExt := range app_specific.ModDataExtensions {
+		if ext == modExt {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the public surface of a version of the mod in the directory. The version may be the path of a directory
+// containing the version of the mod, or a revision of the Git repository containing the mod. If the version is empty,
+// the mod directory itself is read.
+func Load(modPath, version string) (*Surface, error) {
+	if version == "" {
+		return LoadSurface(modPath)
+	}
+	if info, err := os.Stat(version); err == nil && info.IsDir() {
+		return LoadSurface(version)
+	}
+	return LoadRevisionSurface(modPath, version)
+}
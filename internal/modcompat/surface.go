@@ -0,0 +1,146 @@
+// Package modcompat detects breaking changes between two versions of a mod: removed or renamed resources, changed
+// params and changed variables.
+//
+// The versions are compared by their public surface - the resources, params and variables declared in the mod
+// files - which is read from the files without loading the mod, so versions can be compared without installing
+// their dependencies.
+package modcompat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// the resource types which may be referenced by other mods
+var publicBlocks = map[string]bool{
+	schema.BlockTypeQuery:     true,
+	schema.BlockTypeControl:   true,
+	schema.BlockTypeBenchmark: true,
+	schema.BlockTypeDashboard: true,
+	schema.BlockTypeContainer: true,
+	schema.BlockTypeCard:      true,
+	schema.BlockTypeChart:     true,
+	schema.BlockTypeFlow:      true,
+	schema.BlockTypeGraph:     true,
+	schema.BlockTypeHierarchy: true,
+	schema.BlockTypeImage:     true,
+	schema.BlockTypeInput:     true,
+	schema.BlockTypeTable:     true,
+	schema.BlockTypeText:      true,
+	schema.BlockTypeNode:      true,
+	schema.BlockTypeEdge:      true,
+	schema.BlockTypeCategory:  true,
+}
+
+// Surface is the public surface of a version of a mod
+type Surface struct {
+	// the named resources, keyed by <type>.<name>
+	Resources map[string]*Resource
+	Variables map[string]*Variable
+}
+
+// Resource is a named resource of a mod
+type Resource struct {
+	Name  string
+	Title string
+	// the source of the sql attribute, used to detect renamed resources
+	SQL    string
+	Params []*Param
+}
+
+// Param is a param of a query, control or dashboard resource
+type Param struct {
+	Name       string
+	HasDefault bool
+}
+
+// Variable is a variable of a mod
+type Variable struct {
+	Name string
+	// the source of the type expression (without whitespace), or empty if the type is not set
+	Type       string
+	HasDefault bool
+}
+
+// LoadSurface reads the public surface of the mod in the directory, from the mod files outside hidden directories
+func LoadSurface(modPath string) (*Surface, error) {
+	modPath, err := filepath.Abs(modPath)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filehelpers.ListFiles(modPath, &filehelpers.ListOptions{
+		Flags: filehelpers.FilesRecursive,
+		Exclude: []string{
+			fmt.Sprintf("%s/.*", modPath),
+			fmt.Sprintf("%s/.*/**", modPath),
+		},
+		Include: filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSurface()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.addFile(path, content); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func newSurface() *Surface {
+	return &Surface{Resources: make(map[string]*Resource), Variables: make(map[string]*Variable)}
+}
+
+func (s *Surface) addFile(path string, content []byte) error {
+	file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to parse %s: %s", path, diags.Error())
+	}
+	s.addBlocks(file.Body.(*hclsyntax.Body), content, true)
+	return nil
+}
+
+func (s *Surface) addBlocks(body *hclsyntax.Body, content []byte, topLevel bool) {
+	for _, block := range body.Blocks {
+		switch {
+		case topLevel && block.Type == schema.BlockTypeVariable && len(block.Labels) > 0:
+			v := &Variable{Name: block.Labels[0]}
+			if attr, ok := block.Body.Attributes["type"]; ok {
+				v.Type = strings.Join(strings.Fields(string(attr.Expr.Range().SliceBytes(content))), "")
+			}
+			_, v.HasDefault = block.Body.Attributes["default"]
+			s.Variables[v.Name] = v
+		case publicBlocks[block.Type] && len(block.Labels) > 0:
+			r := &Resource{Name: block.Type + "." + block.Labels[0]}
+			if attr, ok := block.Body.Attributes["title"]; ok {
+				r.Title = string(attr.Expr.Range().SliceBytes(content))
+			}
+			if attr, ok := block.Body.Attributes["sql"]; ok {
+				r.SQL = strings.Join(strings.Fields(string(attr.Expr.Range().SliceBytes(content))), " ")
+			}
+			for _, child := range block.Body.Blocks {
+				if child.Type == schema.BlockTypeParam && len(child.Labels) > 0 {
+					_, hasDefault := child.Body.Attributes["default"]
+					r.Params = append(r.Params, &Param{Name: child.Labels[0], HasDefault: hasDefault})
+				}
+			}
+			s.Resources[r.Name] = r
+		}
+		// nested resources (e.g. the cards of a dashboard) may also be referenced
+		s.addBlocks(block.Body, content, false)
+	}
+}
@@ -0,0 +1,143 @@
+// Package moddiff reports the dependency version changes between two workspace locks, used to review a mod update
+// before it is applied.
+package moddiff
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// the number of characters of a commit hash to display
+const shortCommitLength = 7
+
+// Change is a change to the installed version of a dependency
+type Change struct {
+	Name string
+	// the previously installed version (nil if the dependency is being installed)
+	From *versionmap.ResolvedVersionConstraint
+	// the new version (nil if the dependency is being uninstalled)
+	To *versionmap.ResolvedVersionConstraint
+	// the tags released between the two versions, in version order
+	Tags []string
+}
+
+// Diff returns the changes between the dependency versions of two locks, sorted by dependency name
+func Diff(from, to versionmap.InstalledDependencyVersionsMap) []*Change {
+	fromVersions, toVersions := versionsByName(from), versionsByName(to)
+
+	var res []*Change
+	for name, f := range fromVersions {
+		t := toVersions[name]
+		// (the dependency path identifies the version, branch, tag or file path)
+		if t != nil && f.DependencyPath() == t.DependencyPath() && f.Commit == t.Commit {
+			continue
+		}
+		res = append(res, &Change{Name: name, From: f, To: t})
+	}
+	for name, t := range toVersions {
+		if _, ok := fromVersions[name]; !ok {
+			res = append(res, &Change{Name: name, To: t})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// LoadTags lists the tags of the dependency repository and populates the tags released between the two versions
+// - if the tags cannot be listed, the change is reported without them
+func (c *Change) LoadTags() {
+	if c.From == nil || c.To == nil || c.From.Version == nil || c.To.Version == nil {
+		return
+	}
+	low, high := c.From.Version, c.To.Version
+	if high.LessThan(low) {
+		low, high = high, low
+	}
+
+	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"https://" + c.Name},
+	})
+	refs, err := rem.List(&git.ListOptions{})
+	if err != nil {
+		slog.Debug("failed to list tags", "mod", c.Name, "error", err)
+		return
+	}
+
+	var versions []*semver.Version
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		v, err := semver.NewVersion(ref.Name().Short())
+		if err != nil {
+			continue
+		}
+		if v.GreaterThan(low) && !v.GreaterThan(high) {
+			versions = append(versions, v)
+		}
+	}
+	sort.Sort(semver.Collection(versions))
+	for _, v := range versions {
+		c.Tags = append(c.Tags, v.Original())
+	}
+}
+
+// String returns the change as a single line, e.g. "github.com/turbot/mod  v0.1.0 -> v0.2.0 (tags: v0.1.1, v0.2.0)"
+func (c *Change) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s -> %s", c.Name, versionString(c.From), versionString(c.To))
+	if len(c.Tags) > 0 {
+		fmt.Fprintf(&b, " (tags: %s)", strings.Join(c.Tags, ", "))
+	}
+	return b.String()
+}
+
+// versionString returns the version and commit of the resolved version, e.g. "v1.0.0 (abc1234)"
+func versionString(v *versionmap.ResolvedVersionConstraint) string {
+	if v == nil {
+		return "(none)"
+	}
+	var version string
+	switch {
+	case v.Version != nil:
+		version = "v" + v.Version.String()
+	case v.Tag != "":
+		version = v.Tag
+	case v.Branch != "":
+		version = v.Branch
+	default:
+		return v.FilePath
+	}
+	if commit := v.Commit; commit != "" {
+		if len(commit) > shortCommitLength {
+			commit = commit[:shortCommitLength]
+		}
+		version = fmt.Sprintf("%s (%s)", version, commit)
+	}
+	return version
+}
+
+// versionsByName returns the resolved version of each dependency in the map
+// (if a dependency is required at multiple versions, the highest is used)
+func versionsByName(m versionmap.InstalledDependencyVersionsMap) map[string]*versionmap.ResolvedVersionConstraint {
+	res := make(map[string]*versionmap.ResolvedVersionConstraint)
+	for _, deps := range m {
+		for name, dep := range deps {
+			existing, ok := res[name]
+			if ok && (existing.Version == nil || dep.Version == nil || !dep.Version.GreaterThan(existing.Version)) {
+				continue
+			}
+			res[name] = dep.ResolvedVersionConstraint
+		}
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package moddiff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+func installed(name, version, branch, commit string) *versionmap.InstalledModVersion {
+	dependencyVersion := modconfig.DependencyVersion{Branch: branch}
+	if version != "" {
+		dependencyVersion.Version = semver.MustParse(version)
+	}
+	return &versionmap.InstalledModVersion{
+		ResolvedVersionConstraint: &versionmap.ResolvedVersionConstraint{
+			DependencyVersion: dependencyVersion,
+			Name:              name,
+			Commit:            commit,
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := versionmap.InstalledDependencyVersionsMap{
+		"local": {
+			"github.com/acme/a": installed("github.com/acme/a", "1.0.0", "", "1111111111"),
+			"github.com/acme/b": installed("github.com/acme/b", "", "main", "2222222222"),
+			"github.com/acme/c": installed("github.com/acme/c", "2.0.0", "", "3333333333"),
+			"github.com/acme/d": installed("github.com/acme/d", "1.0.0", "", "4444444444"),
+		},
+	}
+	to := versionmap.InstalledDependencyVersionsMap{
+		"local": {
+			"github.com/acme/a": installed("github.com/acme/a", "1.2.0", "", "5555555555"),
+			"github.com/acme/b": installed("github.com/acme/b", "", "main", "6666666666"),
+			"github.com/acme/c": installed("github.com/acme/c", "2.0.0", "", "3333333333"),
+			"github.com/acme/e": installed("github.com/acme/e", "0.1.0", "", "7777777777"),
+		},
+	}
+
+	var res []string
+	for _, c := range Diff(from, to) {
+		res = append(res, c.String())
+	}
+	expected := []string{
+		"github.com/acme/a  v1.0.0 (1111111) -> v1.2.0 (5555555)",
+		"github.com/acme/b  main (2222222) -> main (6666666)",
+		"github.com/acme/d  v1.0.0 (4444444) -> (none)",
+		"github.com/acme/e  (none) -> v0.1.0 (7777777)",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected %v, got %v", expected, res)
+	}
+}
@@ -0,0 +1,259 @@
+// Package moddocs generates markdown documentation for a mod: an index of the mod with its variables, benchmarks and
+// dashboards, a page for each top level benchmark describing its tree of benchmarks and controls (with the control
+// sql), and a page for each dashboard describing its inputs.
+package moddocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/workspace"
+)
+
+// the name of the index page
+const indexFileName = "README.md"
+
+// Generate returns the documentation pages for the workspace mod, keyed by their path relative to the docs directory.
+// Resources of dependency mods are only documented where they are children of a benchmark of the workspace mod.
+func Generate(w *workspace.Workspace) map[string]string {
+	resourceMaps := w.GetResourceMaps()
+
+	var benchmarks []*modconfig.Benchmark
+	for _, b := range resourceMaps.Benchmarks {
+		if !b.IsDependencyResource() && isRootBenchmark(b) {
+			benchmarks = append(benchmarks, b)
+		}
+	}
+	sort.Slice(benchmarks, func(i, j int) bool { return benchmarks[i].Name() < benchmarks[j].Name() })
+
+	var dashboards []*modconfig.Dashboard
+	for _, d := range resourceMaps.Dashboards {
+		if !d.IsDependencyResource() {
+			dashboards = append(dashboards, d)
+		}
+	}
+	sort.Slice(dashboards, func(i, j int) bool { return dashboards[i].Name() < dashboards[j].Name() })
+
+	var variables []*modconfig.Variable
+	for _, v := range resourceMaps.Variables {
+		if v.ModName == w.Mod.ShortName {
+			variables = append(variables, v)
+		}
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].ShortName < variables[j].ShortName })
+
+	res := map[string]string{
+		indexFileName: indexPage(w.Mod, variables, benchmarks, dashboards),
+	}
+	for _, b := range benchmarks {
+		res[benchmarkPath(b)] = benchmarkPage(b)
+	}
+	for _, d := range dashboards {
+		res[dashboardPath(d)] = dashboardPage(d)
+	}
+	return res
+}
+
+// isRootBenchmark returns whether the benchmark is not a child of another benchmark
+func isRootBenchmark(b *modconfig.Benchmark) bool {
+	for _, parent := range b.GetParents() {
+		if _, ok := parent.(*modconfig.Benchmark); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkPath(b *modconfig.Benchmark) string {
+	return path.Join("benchmarks", b.GetUnqualifiedName()[len("benchmark."):]+".md")
+}
+
+func dashboardPath(d *modconfig.Dashboard) string {
+	return path.Join("dashboards", d.GetUnqualifiedName()[len("dashboard."):]+".md")
+}
+
+func indexPage(mod *modconfig.Mod, variables []*modconfig.Variable, benchmarks []*modconfig.Benchmark, dashboards []*modconfig.Dashboard) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title(mod.GetTitle(), mod.ShortName))
+	writeDescription(&b, mod.Description, mod.Documentation)
+
+	if len(variables) > 0 {
+		b.WriteString("\n## Variables\n\n")
+		b.WriteString("| Name | Type | Default | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, v := range variables {
+			defaultValue := "*required*"
+			if !v.Required() {
+				defaultValue = "`" + markdownCell(jsonString(v.DefaultGo)) + "`"
+			}
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s |\n", v.ShortName, markdownCell(v.TypeString), defaultValue, markdownCell(typehelpers.SafeString(v.Description)))
+		}
+	}
+
+	if len(benchmarks) > 0 {
+		b.WriteString("\n## Benchmarks\n\n")
+		for _, benchmark := range benchmarks {
+			fmt.Fprintf(&b, "- [%s](%s)\n", title(benchmark.GetTitle(), benchmark.ShortName), benchmarkPath(benchmark))
+		}
+	}
+
+	if len(dashboards) > 0 {
+		b.WriteString("\n## Dashboards\n\n")
+		for _, d := range dashboards {
+			fmt.Fprintf(&b, "- [%s](%s)\n", title(d.GetTitle(), d.ShortName), dashboardPath(d))
+		}
+	}
+	return b.String()
+}
+
+func benchmarkPage(benchmark *modconfig.Benchmark) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title(benchmark.GetTitle(), benchmark.ShortName))
+	writeDescription(&b, benchmark.Description, benchmark.Documentation)
+	fmt.Fprintf(&b, "\nRun with `powerpipe benchmark run %s`.\n", benchmark.Name())
+	writeTags(&b, benchmark.Tags)
+
+	b.WriteString("\n## Contents\n\n")
+	writeTree(&b, benchmark, 0)
+
+	controls := benchmark.GetChildControls()
+	if len(controls) > 0 {
+		b.WriteString("\n## Controls\n")
+		for _, control := range distinctControls(controls) {
+			writeControl(&b, control)
+		}
+	}
+	return b.String()
+}
+
+// writeTree writes the children of the benchmark as a nested list
+func writeTree(b *strings.Builder, benchmark *modconfig.Benchmark, depth int) {
+	for _, child := range benchmark.GetChildren() {
+		fmt.Fprintf(b, "%s- %s (`%s`)\n", strings.Repeat("  ", depth), title(child.GetTitle(), child.GetUnqualifiedName()), child.Name())
+		if childBenchmark, ok := child.(*modconfig.Benchmark); ok {
+			writeTree(b, childBenchmark, depth+1)
+		}
+	}
+}
+
+func writeControl(b *strings.Builder, control *modconfig.Control) {
+	fmt.Fprintf(b, "\n### %s\n\n", title(control.GetTitle(), control.ShortName))
+	fmt.Fprintf(b, "`%s`", control.Name())
+	if severity := typehelpers.SafeString(control.Severity); severity != "" {
+		fmt.Fprintf(b, " - severity: %s", severity)
+	}
+	b.WriteString("\n")
+	writeDescription(b, control.Description, control.Documentation)
+	writeTags(b, control.Tags)
+	writeParams(b, control.Params)
+
+	sql := control.GetSQL()
+	if sql == nil && control.GetQuery() != nil {
+		sql = control.GetQuery().GetSQL()
+	}
+	if sql != nil {
+		fmt.Fprintf(b, "\n```sql\n%s\n```\n", strings.TrimSpace(*sql))
+	}
+}
+
+func dashboardPage(d *modconfig.Dashboard) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title(d.GetTitle(), d.ShortName))
+	writeDescription(&b, d.Description, d.Documentation)
+	fmt.Fprintf(&b, "\nRun with `powerpipe dashboard run %s`.\n", d.Name())
+	writeTags(&b, d.Tags)
+
+	if len(d.Inputs) > 0 {
+		b.WriteString("\n## Inputs\n\n")
+		b.WriteString("| Name | Label | Type | Placeholder |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, input := range d.Inputs {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				input.UnqualifiedName,
+				markdownCell(typehelpers.SafeString(input.Label)),
+				markdownCell(typehelpers.SafeString(input.Type)),
+				markdownCell(typehelpers.SafeString(input.Placeholder)))
+		}
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, description, documentation *string) {
+	if d := typehelpers.SafeString(description); d != "" {
+		fmt.Fprintf(b, "\n%s\n", strings.TrimSpace(d))
+	}
+	if d := typehelpers.SafeString(documentation); d != "" {
+		fmt.Fprintf(b, "\n%s\n", strings.TrimSpace(d))
+	}
+}
+
+func writeTags(b *strings.Builder, tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var values []string
+	for _, k := range keys {
+		values = append(values, fmt.Sprintf("`%s = %s`", k, tags[k]))
+	}
+	fmt.Fprintf(b, "\nTags: %s\n", strings.Join(values, ", "))
+}
+
+func writeParams(b *strings.Builder, params []*modconfig.ParamDef) {
+	if len(params) == 0 {
+		return
+	}
+	b.WriteString("\n| Param | Default | Description |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, p := range params {
+		defaultValue := "*required*"
+		if p.Default != nil {
+			defaultValue = "`" + markdownCell(*p.Default) + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", p.ShortName, defaultValue, markdownCell(typehelpers.SafeString(p.Description)))
+	}
+}
+
+// distinctControls returns the controls with duplicates removed (a control may be in more than one child benchmark)
+func distinctControls(controls []*modconfig.Control) []*modconfig.Control {
+	var res []*modconfig.Control
+	seen := make(map[string]bool)
+	for _, c := range controls {
+		if !seen[c.Name()] {
+			seen[c.Name()] = true
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// title returns the title, or the name if the title is not set
+func title(t, name string) string {
+	if t != "" {
+		return t
+	}
+	return name
+}
+
+// markdownCell escapes the value for use in a markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func jsonString(v any) string {
+	res, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(res)
+}
@@ -0,0 +1,69 @@
+package modformat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+// ModFiles returns the mod files at the given paths - directories are searched recursively, excluding hidden
+// directories (which include the installed dependency mods)
+func ModFiles(paths []string) ([]string, error) {
+	var res []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			res = append(res, path)
+			continue
+		}
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		files, err := filehelpers.ListFiles(path, &filehelpers.ListOptions{
+			Flags: filehelpers.FilesRecursive,
+			Exclude: []string{
+				fmt.Sprintf("%s/.*", path),
+				fmt.Sprintf("%s/.*/**", path),
+			},
+			Include: filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+// FormatFile formats the mod file, returning whether it was not already formatted - if write is set, the formatted
+// content is written to the file
+func FormatFile(path string, write bool) (bool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := Format(src, path)
+	if err != nil {
+		return false, err
+	}
+	if string(formatted) == string(src) {
+		return false, nil
+	}
+	if write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode()); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
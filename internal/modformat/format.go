@@ -0,0 +1,267 @@
+// Package modformat formats mod files canonically.
+//
+// As well as the token spacing and alignment of hclwrite.Format, the formatter:
+//   - orders the attributes of each block: title, description and documentation first, then the other attributes
+//     in their existing order, then sql and tags, followed by the nested blocks in their existing order
+//   - indents the content of flush heredocs (<<-EOQ) one level deeper than the line which opens them, with the
+//     closing marker aligned with that line
+//   - separates top level blocks, nested blocks and multi-line attributes with a single blank line, and removes
+//     blank lines between single line attributes and at the start and end of blocks
+//
+// Comments on the lines directly above an attribute or block move with it. The attributes of a block which contains
+// detached comments (separated from the next attribute or block by a blank line) are not reordered.
+package modformat
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// the attributes which are ordered before and after the other attributes of a block
+var (
+	leadingAttributes  = []string{"title", "description", "documentation"}
+	trailingAttributes = []string{"sql", "tags"}
+)
+
+// the block types whose attributes are not reordered - the order of locals is chosen by the author
+var unorderedBlocks = map[string]bool{
+	schema.BlockTypeLocals: true,
+}
+
+// Format returns the canonically formatted content of a mod file
+func Format(src []byte, filename string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+
+	f := &formatter{lines: strings.Split(string(src), "\n")}
+	body := file.Body.(*hclsyntax.Body)
+	// the top level body spans the whole file
+	lines := f.formatBody(body, 1, len(f.lines), "", true)
+
+	res := hclwrite.Format([]byte(strings.Join(lines, "\n") + "\n"))
+	res, err := indentHeredocs(res, filename)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimLeft(res, "\n"), nil
+}
+
+type formatter struct {
+	// the lines of the source
+	lines []string
+}
+
+// item is an attribute, block or detached comment of a body, as its source lines
+type item struct {
+	attribute *hclsyntax.Attribute
+	block     *hclsyntax.Block
+	// the lines of any comments attached to the item, followed by the lines of the item
+	lines []string
+	// whether the item (excluding its comments) spans multiple lines
+	multiline bool
+}
+
+func (i *item) isComment() bool {
+	return i.attribute == nil && i.block == nil
+}
+
+// formatBody returns the formatted lines of the body, which spans the given (1 based, inclusive) source lines
+func (f *formatter) formatBody(body *hclsyntax.Body, firstLine, lastLine int, blockType string, topLevel bool) []string {
+	type node struct {
+		attribute *hclsyntax.Attribute
+		block     *hclsyntax.Block
+		rng       hcl.Range
+	}
+	var nodes []node
+	for _, attr := range body.Attributes {
+		nodes = append(nodes, node{attribute: attr, rng: attr.SrcRange})
+	}
+	for _, block := range body.Blocks {
+		nodes = append(nodes, node{block: block, rng: block.Range()})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].rng.Start.Byte < nodes[j].rng.Start.Byte })
+
+	var items []*item
+	hasDetachedComments := false
+	next := firstLine
+	for _, n := range nodes {
+		start, end := n.rng.Start.Line, n.rng.End.Line
+		// comments on the lines directly above the item are attached to it
+		commentStart := start
+		for commentStart > next && !isBlank(f.lines[commentStart-2]) {
+			commentStart--
+		}
+		if comment := f.commentItem(next, commentStart-1); comment != nil {
+			items = append(items, comment)
+			hasDetachedComments = true
+		}
+
+		i := &item{attribute: n.attribute, block: n.block, multiline: start != end}
+		i.lines = append(i.lines, f.lines[commentStart-1:start-1]...)
+		if n.block != nil && n.block.OpenBraceRange.Start.Line != n.block.CloseBraceRange.Start.Line {
+			openLine, closeLine := n.block.OpenBraceRange.Start.Line, n.block.CloseBraceRange.Start.Line
+			i.lines = append(i.lines, f.lines[start-1:openLine]...)
+			i.lines = append(i.lines, f.formatBody(n.block.Body, openLine+1, closeLine-1, n.block.Type, false)...)
+			i.lines = append(i.lines, f.lines[closeLine-1])
+		} else {
+			i.lines = append(i.lines, f.lines[start-1:end]...)
+		}
+		items = append(items, i)
+		next = end + 1
+	}
+	if comment := f.commentItem(next, lastLine); comment != nil {
+		items = append(items, comment)
+		hasDetachedComments = true
+	}
+
+	if !topLevel && !hasDetachedComments && !unorderedBlocks[blockType] {
+		orderItems(items)
+	}
+
+	var res []string
+	for idx, i := range items {
+		if idx > 0 && separate(items[idx-1], i, topLevel) {
+			res = append(res, "")
+		}
+		res = append(res, i.lines...)
+	}
+	return res
+}
+
+// commentItem returns the detached comment on the given source lines, if there is one
+func (f *formatter) commentItem(firstLine, lastLine int) *item {
+	var lines []string
+	for l := firstLine; l <= lastLine; l++ {
+		// multiple blank lines between the comment lines are collapsed into one
+		if isBlank(f.lines[l-1]) && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		if isBlank(f.lines[l-1]) {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, f.lines[l-1])
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return &item{lines: lines, multiline: true}
+}
+
+// orderItems orders the attributes of a body before its blocks, with the leading and trailing attributes first and
+// last - the relative order of the other attributes and of the blocks is unchanged
+func orderItems(items []*item) {
+	rank := func(i *item) int {
+		if i.block != nil {
+			return len(leadingAttributes) + len(trailingAttributes) + 1
+		}
+		for idx, name := range leadingAttributes {
+			if i.attribute.Name == name {
+				return idx
+			}
+		}
+		for idx, name := range trailingAttributes {
+			if i.attribute.Name == name {
+				return len(leadingAttributes) + 1 + idx
+			}
+		}
+		return len(leadingAttributes)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return rank(items[i]) < rank(items[j]) })
+}
+
+// separate returns whether there should be a blank line between the items - top level items, blocks, multi-line
+// attributes and detached comments are separated from their neighbours
+func separate(a, b *item, topLevel bool) bool {
+	if topLevel {
+		return true
+	}
+	for _, i := range []*item{a, b} {
+		if i.block != nil || i.multiline || i.isComment() {
+			return true
+		}
+	}
+	return false
+}
+
+// indentHeredocs indents the content of the flush heredocs in the source one level deeper than the line which opens
+// them, preserving its relative indentation, and aligns the closing marker with the opening line
+func indentHeredocs(src []byte, filename string) ([]byte, error) {
+	tokens, diags := hclsyntax.LexConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+
+	lines := strings.Split(string(src), "\n")
+	var openLine int
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenOHeredoc:
+			openLine = 0
+			if bytes.HasPrefix(token.Bytes, []byte("<<-")) {
+				openLine = token.Range.Start.Line
+			}
+		case hclsyntax.TokenCHeredoc:
+			if openLine == 0 {
+				continue
+			}
+			closeLine := token.Range.Start.Line
+			indent := leadingWhitespace(lines[openLine-1])
+			reindent(lines[openLine:closeLine-1], indent+"  ")
+			lines[closeLine-1] = indent + strings.TrimLeft(lines[closeLine-1], " \t")
+			openLine = 0
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// reindent replaces the common leading whitespace of the non-blank lines with the given indent
+func reindent(lines []string, indent string) {
+	var common *string
+	for _, l := range lines {
+		if isBlank(l) {
+			continue
+		}
+		ws := leadingWhitespace(l)
+		if common == nil {
+			common = &ws
+			continue
+		}
+		n := 0
+		for n < len(*common) && n < len(ws) && (*common)[n] == ws[n] {
+			n++
+		}
+		prefix := ws[:n]
+		common = &prefix
+	}
+	if common == nil {
+		return
+	}
+	for idx, l := range lines {
+		if isBlank(l) {
+			lines[idx] = ""
+			continue
+		}
+		lines[idx] = indent + strings.TrimPrefix(l, *common)
+	}
+}
+
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
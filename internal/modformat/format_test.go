@@ -0,0 +1,149 @@
+package modformat
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	cases := map[string]struct {
+		src      string
+		expected string
+	}{
+		"orders attributes and aligns": {
+			src: `control "a" {
+  tags = { service = "s3" }
+  query = query.a
+
+
+  # the control title
+  title = "A"
+  severity="high"
+}
+`,
+			expected: `control "a" {
+  # the control title
+  title    = "A"
+  query    = query.a
+  severity = "high"
+  tags     = { service = "s3" }
+}
+`,
+		},
+		"indents heredocs": {
+			src: `query "a" {
+param "p" {
+default = 1
+}
+    sql = <<-EOQ
+select
+  *
+from a
+      EOQ
+}
+`,
+			expected: `query "a" {
+  sql = <<-EOQ
+    select
+      *
+    from a
+  EOQ
+
+  param "p" {
+    default = 1
+  }
+}
+`,
+		},
+		"separates blocks": {
+			src: `
+// header
+
+dashboard "a" {
+
+  title = "A"
+  container {
+    card {
+      sql = "select 1"
+    }
+    card {
+      sql = "select 2"
+    }
+  }
+
+}
+query "b" {
+  sql = "select 1"
+}
+
+
+`,
+			expected: `// header
+
+dashboard "a" {
+  title = "A"
+
+  container {
+    card {
+      sql = "select 1"
+    }
+
+    card {
+      sql = "select 2"
+    }
+  }
+}
+
+query "b" {
+  sql = "select 1"
+}
+`,
+		},
+		"keeps order with detached comments": {
+			src: `locals {
+  z = 1
+  a = 2
+}
+
+control "a" {
+  query = query.a
+
+  # TODO
+
+  title = "A"
+}
+`,
+			expected: `locals {
+  z = 1
+  a = 2
+}
+
+control "a" {
+  query = query.a
+
+  # TODO
+
+  title = "A"
+}
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			res, err := Format([]byte(tc.src), "test.pp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(res) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, res)
+			}
+			// formatting is idempotent
+			again, err := Format(res, "test.pp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(res) {
+				t.Errorf("formatting is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package modgraph builds the graph of a workspace's resolved mod dependencies (and optionally the references
+// between resources) and renders it as DOT, Mermaid or JSON.
+package modgraph
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// node kinds
+const (
+	KindWorkspace = "workspace"
+	KindMod       = "mod"
+	KindResource  = "resource"
+)
+
+// Node is a mod or resource in the graph
+type Node struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+	// for mods, the installed version, and the commit for branch dependencies
+	Version string `json:"version,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	// for resources, the name of the mod which contains the resource
+	Mod string `json:"mod,omitempty"`
+}
+
+// Edge is a dependency of one mod on another, or a reference from one resource to another
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// for mod dependencies, the version constraint; for references, the attribute which makes the reference
+	Label string `json:"label,omitempty"`
+}
+
+// Graph is a directed graph of mods and resources
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+
+	nodes map[string]*Node
+	edges map[string]bool
+}
+
+func newGraph() *Graph {
+	return &Graph{Nodes: []*Node{}, Edges: []*Edge{}, nodes: make(map[string]*Node), edges: make(map[string]bool)}
+}
+
+// DependencyGraph returns the graph of the installed dependencies of the workspace mod, from the lock file
+// install cache. The edges are labelled with the version constraint from the mod definition of the parent.
+func DependencyGraph(workspacePath string, workspaceMod *modconfig.Mod, installCache versionmap.InstalledDependencyVersionsMap) *Graph {
+	g := newGraph()
+	root := workspaceMod.GetInstallCacheKey()
+	g.addNode(&Node{ID: root, Label: root, Kind: KindWorkspace})
+	g.addDependencies(workspacePath, root, workspaceMod, installCache)
+	g.sort()
+	return g
+}
+
+func (g *Graph) addDependencies(workspacePath, parent string, parentMod *modconfig.Mod, installCache versionmap.InstalledDependencyVersionsMap) {
+	constraints := make(map[string]string)
+	if parentMod != nil && parentMod.Require != nil {
+		for _, c := range parentMod.Require.Mods {
+			constraints[c.Name] = fmt.Sprintf("%v", c.OriginalConstraint())
+		}
+	}
+
+	for _, dep := range installCache[parent] {
+		id := modconfig.BuildModDependencyPath(dep.Name, &dep.DependencyVersion)
+		node := &Node{ID: id, Label: dep.Name, Kind: KindMod, Commit: dep.Commit}
+		if dep.Version != nil {
+			node.Version = "v" + dep.Version.String()
+		} else {
+			node.Version = dep.Branch
+		}
+		g.addEdge(&Edge{From: parent, To: id, Label: constraints[dep.Name]})
+		// a mod may be required by several parents - only walk its dependencies once
+		if g.addNode(node) {
+			// the mod definition is only needed for the constraint labels, so ignore load errors
+			depMod, _ := parse.LoadModfile(filepath.Join(filepaths.WorkspaceModPath(workspacePath), id))
+			g.addDependencies(workspacePath, id, depMod, installCache)
+		}
+	}
+}
+
+// AddResourceReferences adds the resources of the mod (including those of its dependencies) to the graph, with
+// edges for the references between them
+func (g *Graph) AddResourceReferences(mod *modconfig.Mod) {
+	_ = mod.WalkResources(func(resource modconfig.HclResource) (bool, error) {
+		resourceWithMetadata, ok := resource.(modconfig.ResourceWithMetadata)
+		if !ok {
+			return true, nil
+		}
+		// unqualified names are relative to the mod which contains the resource
+		modName := mod.ShortName
+		if modItem, ok := resource.(modconfig.ModItem); ok && modItem.GetMod() != nil {
+			modName = modItem.GetMod().ShortName
+		}
+		for _, ref := range resourceWithMetadata.GetReferences() {
+			from := g.addResource(ref.From, modName)
+			to := g.addResource(ref.To, modName)
+			g.addEdge(&Edge{From: from, To: to, Label: ref.Attribute})
+		}
+		return true, nil
+	})
+	g.sort()
+}
+
+// addResource adds a node for the named resource, returning its ID (the name qualified with the mod name)
+func (g *Graph) addResource(name, modName string) string {
+	parsed, err := modconfig.ParseResourceName(name)
+	if err != nil {
+		g.addNode(&Node{ID: name, Label: name, Kind: KindResource})
+		return name
+	}
+	if parsed.Mod != "" {
+		modName = parsed.Mod
+	}
+	id := fmt.Sprintf("%s.%s.%s", modName, parsed.ItemType, parsed.Name)
+	g.addNode(&Node{ID: id, Label: id, Kind: KindResource, Mod: modName})
+	return id
+}
+
+// addNode adds the node if it is not already in the graph, returning whether it was added
+func (g *Graph) addNode(n *Node) bool {
+	if _, ok := g.nodes[n.ID]; ok {
+		return false
+	}
+	g.nodes[n.ID] = n
+	g.Nodes = append(g.Nodes, n)
+	return true
+}
+
+func (g *Graph) addEdge(e *Edge) {
+	key := e.From + "\x00" + e.To + "\x00" + e.Label
+	if g.edges[key] {
+		return
+	}
+	g.edges[key] = true
+	g.Edges = append(g.Edges, e)
+}
+
+// sort orders the nodes and edges so the output is stable, with the workspace first
+func (g *Graph) sort() {
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		if (g.Nodes[i].Kind == KindWorkspace) != (g.Nodes[j].Kind == KindWorkspace) {
+			return g.Nodes[i].Kind == KindWorkspace
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+}
@@ -0,0 +1,61 @@
+package modgraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+func installedVersion(name, version string) *versionmap.InstalledModVersion {
+	return &versionmap.InstalledModVersion{
+		ResolvedVersionConstraint: &versionmap.ResolvedVersionConstraint{
+			Name:              name,
+			DependencyVersion: modconfig.DependencyVersion{Version: semver.MustParse(version)},
+		},
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	workspaceMod := modconfig.NewMod("local", t.TempDir(), hcl.Range{})
+	installCache := versionmap.InstalledDependencyVersionsMap{
+		"local": {
+			"github.com/acme/a": installedVersion("github.com/acme/a", "1.0.0"),
+			"github.com/acme/b": installedVersion("github.com/acme/b", "2.1.0"),
+		},
+		"github.com/acme/a@v1.0.0": {
+			"github.com/acme/c": installedVersion("github.com/acme/c", "0.1.0"),
+		},
+		"github.com/acme/b@v2.1.0": {
+			"github.com/acme/c": installedVersion("github.com/acme/c", "0.1.0"),
+		},
+	}
+
+	g := DependencyGraph(t.TempDir(), workspaceMod, installCache)
+	if len(g.Nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %d", len(g.Nodes))
+	}
+	if g.Nodes[0].ID != "local" {
+		t.Errorf("expected the workspace to be the first node, got %s", g.Nodes[0].ID)
+	}
+
+	dot := g.Dot()
+	for _, expected := range []string{
+		`"local" -> "github.com/acme/a@v1.0.0";`,
+		`"github.com/acme/a@v1.0.0" -> "github.com/acme/c@v0.1.0";`,
+		`"github.com/acme/b@v2.1.0" -> "github.com/acme/c@v0.1.0";`,
+		`"github.com/acme/c@v0.1.0" [label="github.com/acme/c\nv0.1.0", shape=box];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("expected DOT output to contain %s, got:\n%s", expected, dot)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	if strings.Count(mermaid, "-->") != 4 {
+		t.Errorf("expected 4 edges in the Mermaid output, got:\n%s", mermaid)
+	}
+}
@@ -0,0 +1,107 @@
+package modgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// Render renders the graph in the given format
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case localconstants.OutputFormatDot:
+		return g.Dot(), nil
+	case localconstants.OutputFormatMermaid:
+		return g.Mermaid(), nil
+	case constants.OutputFormatJSON:
+		res, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(res), nil
+	}
+	return "", fmt.Errorf("unsupported graph format '%s'", format)
+}
+
+// Dot renders the graph in the Graphviz DOT language
+func (g *Graph) Dot() string {
+	var b strings.Builder
+	b.WriteString("digraph mods {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", dotQuote(n.ID), dotQuote(n.displayLabel()), dotShape(n.Kind))
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Label))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	// mermaid node ids may not contain most punctuation, so number the nodes
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		open, close := mermaidShape(n.Kind)
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", id, open, mermaidEscape(n.displayLabel()), close)
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", ids[e.From], mermaidEscape(e.Label), ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// displayLabel returns the node label, including the version for mods
+func (n *Node) displayLabel() string {
+	if n.Kind != KindMod || n.Version == "" {
+		return n.Label
+	}
+	return fmt.Sprintf("%s\n%s", n.Label, n.Version)
+}
+
+func dotShape(kind string) string {
+	switch kind {
+	case KindWorkspace:
+		return "doubleoctagon"
+	case KindMod:
+		return "box"
+	default:
+		return "ellipse"
+	}
+}
+
+func mermaidShape(kind string) (string, string) {
+	switch kind {
+	case KindWorkspace:
+		return "[[", "]]"
+	case KindMod:
+		return "[", "]"
+	default:
+		return "(", ")"
+	}
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+}
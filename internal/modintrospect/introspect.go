@@ -0,0 +1,89 @@
+// Package modintrospect builds a single document describing the resolved resources of a workspace, for tooling
+// which builds catalogs and coverage reports.
+package modintrospect
+
+import (
+	"sort"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/versionmap"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/modgraph"
+)
+
+// Document describes the resolved resources of the workspace mod and its dependency mods - the mod_name of each
+// resource is the mod which contains it
+type Document struct {
+	Mod *modconfig.Mod `json:"mod"`
+	// the graph of installed dependency mods
+	Dependencies *modgraph.Graph        `json:"dependencies"`
+	Benchmarks   []*modconfig.Benchmark `json:"benchmarks"`
+	Controls     []*modconfig.Control   `json:"controls"`
+	Dashboards   []*modconfig.Dashboard `json:"dashboards"`
+	Queries      []*modconfig.Query     `json:"queries"`
+	Variables    []*modconfig.Variable  `json:"variables"`
+	// the values used for each tag key by the resources, sorted
+	Tags map[string][]string `json:"tags"`
+}
+
+// Introspect returns the document describing the loaded workspace
+func Introspect(w *workspace.Workspace, lock *versionmap.WorkspaceLock) (*Document, error) {
+	var installCache versionmap.InstalledDependencyVersionsMap
+	if lock != nil {
+		installCache = lock.InstallCache
+	}
+	doc := &Document{
+		Mod:          w.Mod,
+		Dependencies: modgraph.DependencyGraph(w.Path, w.Mod, installCache),
+	}
+
+	var err error
+	if doc.Benchmarks, err = resourcesOfType[*modconfig.Benchmark](w); err != nil {
+		return nil, err
+	}
+	if doc.Controls, err = resourcesOfType[*modconfig.Control](w); err != nil {
+		return nil, err
+	}
+	if doc.Dashboards, err = resourcesOfType[*modconfig.Dashboard](w); err != nil {
+		return nil, err
+	}
+	if doc.Queries, err = resourcesOfType[*modconfig.Query](w); err != nil {
+		return nil, err
+	}
+	if doc.Variables, err = resourcesOfType[*modconfig.Variable](w); err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]map[string]bool)
+	_ = w.GetResourceMaps().WalkResources(func(resource modconfig.HclResource) (bool, error) {
+		for key, value := range resource.GetTags() {
+			if tags[key] == nil {
+				tags[key] = make(map[string]bool)
+			}
+			tags[key][value] = true
+		}
+		return true, nil
+	})
+	doc.Tags = make(map[string][]string, len(tags))
+	for key, values := range tags {
+		for value := range values {
+			doc.Tags[key] = append(doc.Tags[key], value)
+		}
+		sort.Strings(doc.Tags[key])
+	}
+	return doc, nil
+}
+
+// resourcesOfType returns the resources of the given type, sorted by name
+func resourcesOfType[T modconfig.HclResource](w *workspace.Workspace) ([]T, error) {
+	resources, err := workspace.FilterWorkspaceResourcesOfType[T](w, workspace.ResourceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]T, 0, len(resources))
+	for _, r := range resources {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res, nil
+}
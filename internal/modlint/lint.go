@@ -0,0 +1,125 @@
+// Package modlint checks a mod for common problems: broken resource references, unreferenced queries, controls
+// without a severity or tags, duplicated titles, params without defaults and deprecated syntax.
+//
+// The mod files are first checked syntactically, so broken references are reported with their location even though
+// they prevent the mod from loading. If the mod loads, its resources are then checked.
+package modlint
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/workspace"
+)
+
+// issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// lint rules
+const (
+	RuleParseError             = "parse-error"
+	RuleLoadError              = "load-error"
+	RuleBrokenReference        = "broken-reference"
+	RuleDeprecatedSyntax       = "deprecated-syntax"
+	RuleUnreferencedQuery      = "unreferenced-query"
+	RuleControlMissingSeverity = "control-missing-severity"
+	RuleControlMissingTags     = "control-missing-tags"
+	RuleDuplicateTitle         = "duplicate-title"
+	RuleParamWithoutDefault    = "param-without-default"
+)
+
+// Issue is a problem found in the mod
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// the name of the resource the issue was found in, if any
+	Resource string `json:"resource,omitempty"`
+	// the location of the issue, relative to the mod location
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Location returns the file and line of the issue, e.g. controls/s3.pp:12
+func (i *Issue) Location() string {
+	if i.File == "" {
+		return ""
+	}
+	if i.Line == 0 {
+		return i.File
+	}
+	return fmt.Sprintf("%s:%d", i.File, i.Line)
+}
+
+// Lint checks the mod at workspacePath, returning the issues found, sorted by location
+func Lint(ctx context.Context, workspacePath string) ([]*Issue, error) {
+	workspacePath, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{workspacePath: workspacePath}
+
+	if err := l.checkSyntax(); err != nil {
+		return nil, err
+	}
+
+	w, errAndWarnings := workspace.Load(ctx, workspacePath, workspace.WithVariableValidation(false))
+	if err := errAndWarnings.GetError(); err != nil {
+		// the load error is not reported if the mod has syntax errors, as these are the cause
+		if !HasErrors(l.issues) {
+			l.add(&Issue{Rule: RuleLoadError, Severity: SeverityError, Message: err.Error()})
+		}
+	} else {
+		l.checkResources(w)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+	return l.issues, nil
+}
+
+// HasErrors returns whether any of the issues are errors
+func HasErrors(issues []*Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	workspacePath string
+	issues        []*Issue
+}
+
+func (l *linter) add(issue *Issue) {
+	l.issues = append(l.issues, issue)
+}
+
+// addAt adds an issue at the given source range
+func (l *linter) addAt(rule, severity, resource, message string, rng *hcl.Range) {
+	issue := &Issue{Rule: rule, Severity: severity, Resource: resource, Message: message}
+	if rng != nil && rng.Filename != "" {
+		issue.File = rng.Filename
+		if rel, err := filepath.Rel(l.workspacePath, rng.Filename); err == nil {
+			issue.File = rel
+		}
+		issue.Line = rng.Start.Line
+	}
+	l.add(issue)
+}
@@ -0,0 +1,107 @@
+package modlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/workspace"
+)
+
+// the resource types whose titles must be unique
+var uniqueTitleBlocks = []string{
+	schema.BlockTypeQuery,
+	schema.BlockTypeControl,
+	schema.BlockTypeBenchmark,
+	schema.BlockTypeDashboard,
+}
+
+// checkResources checks the resources of the loaded workspace mod (the resources of dependency mods are not checked)
+func (l *linter) checkResources(w *workspace.Workspace) {
+	var resources []modconfig.HclResource
+	referenced := make(map[string]bool)
+	_ = w.Mod.WalkResources(func(resource modconfig.HclResource) (bool, error) {
+		modName := resourceModName(resource, w.Mod)
+		if withMetadata, ok := resource.(modconfig.ResourceWithMetadata); ok {
+			for _, ref := range withMetadata.GetReferences() {
+				referenced[qualifiedName(ref.To, modName)] = true
+			}
+		}
+		if modName == w.Mod.ShortName && resource.BlockType() != schema.BlockTypeMod {
+			resources = append(resources, resource)
+		}
+		return true, nil
+	})
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name() < resources[j].Name() })
+
+	titles := make(map[string][]modconfig.HclResource)
+	for _, resource := range resources {
+		name := resource.GetUnqualifiedName()
+		switch r := resource.(type) {
+		case *modconfig.Query:
+			if !referenced[qualifiedName(name, w.Mod.ShortName)] {
+				l.addAt(RuleUnreferencedQuery, SeverityWarning, name, "query is not referenced by any other resource", r.GetDeclRange())
+			}
+		case *modconfig.Control:
+			if typehelpers.SafeString(r.Severity) == "" {
+				l.addAt(RuleControlMissingSeverity, SeverityWarning, name, "control has no severity", r.GetDeclRange())
+			}
+			if len(r.Tags) == 0 {
+				l.addAt(RuleControlMissingTags, SeverityWarning, name, "control has no tags", r.GetDeclRange())
+			}
+		}
+
+		if provider, ok := resource.(modconfig.QueryProvider); ok {
+			for _, param := range provider.GetParams() {
+				if param.Default == nil {
+					rng := param.DeclRange
+					l.addAt(RuleParamWithoutDefault, SeverityWarning, name, fmt.Sprintf("param '%s' has no default", param.ShortName), &rng)
+				}
+			}
+		}
+
+		if title := resource.GetTitle(); title != "" && resource.IsTopLevel() {
+			for _, blockType := range uniqueTitleBlocks {
+				if resource.BlockType() == blockType {
+					key := blockType + "\x00" + strings.ToLower(title)
+					titles[key] = append(titles[key], resource)
+				}
+			}
+		}
+	}
+
+	for _, duplicates := range titles {
+		if len(duplicates) < 2 {
+			continue
+		}
+		for i, resource := range duplicates {
+			var others []string
+			for j, other := range duplicates {
+				if i != j {
+					others = append(others, other.GetUnqualifiedName())
+				}
+			}
+			l.addAt(RuleDuplicateTitle, SeverityWarning, resource.GetUnqualifiedName(),
+				fmt.Sprintf("title '%s' is also used by %s", resource.GetTitle(), strings.Join(others, ", ")), resource.GetDeclRange())
+		}
+	}
+}
+
+// resourceModName returns the short name of the mod which contains the resource
+func resourceModName(resource modconfig.HclResource, workspaceMod *modconfig.Mod) string {
+	if modItem, ok := resource.(modconfig.ModItem); ok && modItem.GetMod() != nil {
+		return modItem.GetMod().ShortName
+	}
+	return workspaceMod.ShortName
+}
+
+// qualifiedName returns the resource name qualified with the mod name, if it is not already
+func qualifiedName(name, modName string) string {
+	if strings.Count(name, ".") >= 2 {
+		return name
+	}
+	return modName + "." + name
+}
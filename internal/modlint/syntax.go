@@ -0,0 +1,208 @@
+package modlint
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// referenceableBlocks are the block types which may be referenced as <type>.<name>
+var referenceableBlocks = map[string]bool{
+	schema.BlockTypeQuery:     true,
+	schema.BlockTypeControl:   true,
+	schema.BlockTypeBenchmark: true,
+	schema.BlockTypeDashboard: true,
+	schema.BlockTypeContainer: true,
+	schema.BlockTypeCard:      true,
+	schema.BlockTypeChart:     true,
+	schema.BlockTypeFlow:      true,
+	schema.BlockTypeGraph:     true,
+	schema.BlockTypeHierarchy: true,
+	schema.BlockTypeImage:     true,
+	schema.BlockTypeInput:     true,
+	schema.BlockTypeTable:     true,
+	schema.BlockTypeText:      true,
+	schema.BlockTypeNode:      true,
+	schema.BlockTypeEdge:      true,
+	schema.BlockTypeCategory:  true,
+}
+
+// the deprecated file extension for mod files
+const deprecatedModFileExtension = ".sp"
+
+// syntaxFile is a parsed mod file
+type syntaxFile struct {
+	path string
+	body *hclsyntax.Body
+}
+
+// checkSyntax parses the mod files and checks for deprecated syntax and references to resources, variables and
+// locals which are not declared
+func (l *linter) checkSyntax() error {
+	paths, err := l.modFilePaths()
+	if err != nil {
+		return err
+	}
+
+	var files []*syntaxFile
+	declared := make(map[string]bool)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			for _, diag := range diags.Errs() {
+				if d, ok := diag.(*hcl.Diagnostic); ok {
+					l.addAt(RuleParseError, SeverityError, "", d.Summary+diagDetail(d), d.Subject)
+				}
+			}
+			continue
+		}
+		body := file.Body.(*hclsyntax.Body)
+		files = append(files, &syntaxFile{path: path, body: body})
+		addDeclarations(body, declared, true)
+
+		if filepath.Ext(path) == deprecatedModFileExtension {
+			l.addAt(RuleDeprecatedSyntax, SeverityWarning, "",
+				fmt.Sprintf("the %s file extension is deprecated - rename the file to use the .pp extension", deprecatedModFileExtension),
+				&hcl.Range{Filename: path})
+		}
+	}
+
+	for _, f := range files {
+		for _, block := range f.body.Blocks {
+			l.checkDeprecations(block)
+			l.checkReferences(block, blockName(block), declared)
+		}
+	}
+	return nil
+}
+
+// modFilePaths returns the paths of the workspace mod files, excluding hidden directories and the files excluded
+// by the workspace ignore file, as when loading the workspace
+func (l *linter) modFilePaths() ([]string, error) {
+	exclusions := []string{
+		fmt.Sprintf("%s/.*", l.workspacePath),
+		fmt.Sprintf("%s/.*/**", l.workspacePath),
+	}
+	if f, err := os.Open(filepath.Join(l.workspacePath, app_specific.WorkspaceIgnoreFile)); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				exclusions = append(exclusions, filepath.Join(l.workspacePath, line))
+			}
+		}
+		f.Close()
+	}
+	return filehelpers.ListFiles(l.workspacePath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesRecursive,
+		Exclude: exclusions,
+		Include: filehelpers.InclusionsFromExtensions(app_specific.ModDataExtensions),
+	})
+}
+
+// addDeclarations adds the names which the blocks declare, i.e. <type>.<name> for resources, var.<name> for variables
+// and local.<name> for locals
+func addDeclarations(body *hclsyntax.Body, declared map[string]bool, topLevel bool) {
+	for _, block := range body.Blocks {
+		switch {
+		case topLevel && block.Type == schema.BlockTypeVariable && len(block.Labels) > 0:
+			declared["var."+block.Labels[0]] = true
+		case topLevel && block.Type == schema.BlockTypeLocals:
+			for name := range block.Body.Attributes {
+				declared["local."+name] = true
+			}
+		case referenceableBlocks[block.Type] && len(block.Labels) > 0:
+			declared[block.Type+"."+block.Labels[0]] = true
+		}
+		// nested resources (e.g. the cards of a dashboard) may also be referenced
+		addDeclarations(block.Body, declared, false)
+	}
+}
+
+// checkReferences checks the references made by the attributes of the block and its nested blocks are declared
+// - references to the resources of dependency mods (<mod>.<type>.<name>) are not checked
+func (l *linter) checkReferences(block *hclsyntax.Block, resource string, declared map[string]bool) {
+	for _, attr := range block.Body.Attributes {
+		for _, traversal := range attr.Expr.Variables() {
+			root := traversal.RootName()
+			if !referenceableBlocks[root] && root != "var" && root != "local" {
+				continue
+			}
+			if len(traversal) < 2 {
+				continue
+			}
+			step, ok := traversal[1].(hcl.TraverseAttr)
+			if !ok {
+				continue
+			}
+			name := root + "." + step.Name
+			if !declared[name] {
+				rng := traversal.SourceRange()
+				l.addAt(RuleBrokenReference, SeverityError, resource,
+					fmt.Sprintf("'%s' references '%s', which is not defined", attr.Name, name), &rng)
+			}
+		}
+	}
+	for _, child := range block.Body.Blocks {
+		childResource := resource
+		if referenceableBlocks[child.Type] && len(child.Labels) > 0 {
+			childResource = blockName(child)
+		}
+		l.checkReferences(child, childResource, declared)
+	}
+}
+
+// checkDeprecations checks the block for deprecated attributes and blocks
+func (l *linter) checkDeprecations(block *hclsyntax.Block) {
+	switch block.Type {
+	case schema.BlockTypeQuery, schema.BlockTypeControl:
+		for _, name := range []string{"search_path", "search_path_prefix"} {
+			if attr, ok := block.Body.Attributes[name]; ok {
+				rng := attr.Range()
+				l.addAt(RuleDeprecatedSyntax, SeverityWarning, blockName(block),
+					fmt.Sprintf("the '%s' attribute is deprecated for %s blocks and is ignored", name, block.Type), &rng)
+			}
+		}
+	case schema.BlockTypeMod:
+		for _, child := range block.Body.Blocks {
+			switch child.Type {
+			case schema.BlockTypeLegacyRequires:
+				rng := child.DefRange()
+				l.addAt(RuleDeprecatedSyntax, SeverityWarning, blockName(block),
+					fmt.Sprintf("the '%s' block is deprecated - use a '%s' block instead", schema.BlockTypeLegacyRequires, schema.BlockTypeRequire), &rng)
+			case schema.BlockTypeRequire:
+				if attr, ok := child.Body.Attributes[schema.BlockTypeSteampipe]; ok {
+					rng := attr.Range()
+					l.addAt(RuleDeprecatedSyntax, SeverityWarning, blockName(block),
+						fmt.Sprintf("the '%s' attribute of the require block is deprecated - use a '%s' block instead", schema.BlockTypeSteampipe, schema.BlockTypeSteampipe), &rng)
+				}
+			}
+		}
+	}
+}
+
+func blockName(block *hclsyntax.Block) string {
+	if len(block.Labels) == 0 {
+		return block.Type
+	}
+	return block.Type + "." + block.Labels[0]
+}
+
+func diagDetail(d *hcl.Diagnostic) string {
+	if d.Detail == "" {
+		return ""
+	}
+	return ": " + d.Detail
+}
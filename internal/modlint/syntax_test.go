@@ -0,0 +1,87 @@
+package modlint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+const testModFile = `
+mod "test" {
+  require {
+    steampipe = "0.20.0"
+  }
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+locals {
+  tags = { service = "s3" }
+}
+
+query "q1" {
+  sql = "select 1"
+}
+
+control "c1" {
+  query = query.q1
+  tags  = local.tags
+}
+
+control "c2" {
+  query = query.missing
+}
+
+dashboard "d1" {
+  card "count" {
+    sql = "select 1"
+  }
+  table {
+    query = query.q1
+    args  = { region = var.region }
+  }
+}
+
+benchmark "b1" {
+  children    = [control.c1, control.c2, control.nope, card.count, dep.control.x]
+  description = var.nothere
+}
+`
+
+func TestCheckSyntax(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceIgnoreFile = ".powerpipeignore"
+
+	workspacePath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspacePath, "mod.pp"), []byte(testModFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspacePath, "old.sp"), []byte(`query "old" { sql = "select 1" }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &linter{workspacePath: workspacePath}
+	if err := l.checkSyntax(); err != nil {
+		t.Fatal(err)
+	}
+
+	var res []string
+	for _, i := range l.issues {
+		res = append(res, i.Location()+" "+i.Rule)
+	}
+	expected := []string{
+		"old.sp deprecated-syntax",
+		"mod.pp:4 deprecated-syntax",
+		"mod.pp:26 broken-reference",
+		"mod.pp:40 broken-reference",
+		"mod.pp:41 broken-reference",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected issues %v, got %v", expected, res)
+	}
+}
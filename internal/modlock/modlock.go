@@ -0,0 +1,203 @@
+// Package modlock records content hashes of the installed mod dependencies in the workspace lock file.
+//
+// The lock file is written by the pipe-fittings mod installer, which does not know about hashes - so after each
+// install the hashes are added back to the lock file entries. A hash is recorded the first time a dependency version
+// is installed and is checked whenever that version is installed again, so a mod whose content changes without a
+// change of version (e.g. a moved tag) is detected.
+package modlock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/versionmap"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// Entry is a lock file entry - the installed mod version and the hash of its content
+type Entry struct {
+	versionmap.InstalledModVersion
+	Hash string `json:"hash,omitempty"`
+}
+
+// Lock is the content of the workspace lock file, keyed by parent mod and dependency name
+type Lock map[string]map[string]*Entry
+
+// Load loads the workspace lock file, returning an empty lock if it does not exist
+func Load(workspacePath string) (Lock, error) {
+	res := make(Lock)
+	lockPath := filepaths.WorkspaceLockPath(workspacePath)
+	if !filehelpers.FileExists(lockPath) {
+		return res, nil
+	}
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", lockPath, err)
+	}
+	return res, nil
+}
+
+// Save writes the lock to the workspace lock file, in the same format as the mod installer
+func (l Lock) Save(workspacePath string) error {
+	lockPath := filepaths.WorkspaceLockPath(workspacePath)
+	if len(l) == 0 {
+		if filehelpers.FileExists(lockPath) {
+			return os.Remove(lockPath)
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lockPath, content, 0644) //nolint:gosec // same permissions as the mod installer
+}
+
+// Hashes returns the recorded hashes, keyed by dependency path (<name>@<version>)
+func (l Lock) Hashes() map[string]string {
+	res := make(map[string]string)
+	for _, deps := range l {
+		for _, dep := range deps {
+			if dep.Hash != "" && dep.ResolvedVersionConstraint != nil {
+				res[dep.DependencyPath()] = dep.Hash
+			}
+		}
+	}
+	return res
+}
+
+// InstallCache returns the lock entries as an install cache, without the hashes
+func (l Lock) InstallCache() versionmap.InstalledDependencyVersionsMap {
+	res := make(versionmap.InstalledDependencyVersionsMap)
+	for parent, deps := range l {
+		res[parent] = make(map[string]*versionmap.InstalledModVersion, len(deps))
+		for name, dep := range deps {
+			installedVersion := dep.InstalledModVersion
+			res[parent][name] = &installedVersion
+		}
+	}
+	return res
+}
+
+// Equal returns whether the locks resolve the same dependency versions, ignoring the recorded hashes
+func (l Lock) Equal(other Lock) bool {
+	if len(l) != len(other) {
+		return false
+	}
+	for parent, deps := range l {
+		otherDeps, ok := other[parent]
+		if !ok || len(deps) != len(otherDeps) {
+			return false
+		}
+		for name, dep := range deps {
+			otherDep, ok := otherDeps[name]
+			if !ok || dep.ResolvedVersionConstraint == nil || otherDep.ResolvedVersionConstraint == nil {
+				return false
+			}
+			// (the dependency path identifies the version, branch, tag or file path)
+			if dep.DependencyPath() != otherDep.DependencyPath() || dep.Commit != otherDep.Commit {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Unhashed returns the dependency paths of the installed dependencies which do not have a recorded hash
+func (l Lock) Unhashed() []string {
+	var res []string
+	for _, deps := range l {
+		for _, dep := range deps {
+			if dep.ResolvedVersionConstraint != nil && dep.FilePath == "" && dep.Hash == "" {
+				res = append(res, dep.DependencyPath())
+			}
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// RecordHashes hashes the installed dependencies of the workspace and records the hashes in the lock file.
+//
+// If a hash was previously recorded for a dependency version, the installed content must match it - the dependency
+// paths of any mods which do not match are returned, and their previously recorded hash is kept.
+// Dependency paths in exclude (e.g. mods replaced by local directories) are not hashed, and keep any previous hash.
+func RecordHashes(workspacePath string, previous Lock, exclude map[string]bool) ([]string, error) {
+	lock, err := Load(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	previousHashes := previous.Hashes()
+
+	var mismatched []string
+	for _, deps := range lock {
+		for _, dep := range deps {
+			// local filepath dependencies are not installed so are not hashed
+			if dep.ResolvedVersionConstraint == nil || dep.FilePath != "" {
+				continue
+			}
+			dependencyPath := dep.DependencyPath()
+			if exclude[dependencyPath] {
+				dep.Hash = previousHashes[dependencyPath]
+				continue
+			}
+			modPath := filepath.Join(filepaths.WorkspaceModPath(workspacePath), dependencyPath)
+			if !filehelpers.DirectoryExists(modPath) {
+				continue
+			}
+			hash, err := HashMod(modPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %s: %w", dependencyPath, err)
+			}
+			if previousHash, ok := previousHashes[dependencyPath]; ok && previousHash != hash {
+				mismatched = append(mismatched, dependencyPath)
+				hash = previousHash
+			}
+			dep.Hash = hash
+		}
+	}
+	mismatched = helpers.StringSliceDistinct(mismatched)
+	sort.Strings(mismatched)
+
+	return mismatched, lock.Save(workspacePath)
+}
+
+// HashMod returns the content hash of the installed mod in the given directory, excluding any Git metadata
+// (this is the same h1: hash used by Go modules, so vendored and Git installed copies have the same hash)
+func HashMod(modPath string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(modPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(modPath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(modPath, filepath.FromSlash(name)))
+	})
+}
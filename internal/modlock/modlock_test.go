@@ -0,0 +1,68 @@
+package modlock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+func TestHashMod(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mod.pp"), []byte(`mod "dep" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := HashMod(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Git metadata is not included in the hash
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if gitHash, _ := HashMod(dir); gitHash != hash {
+		t.Errorf("expected hash %s to exclude Git metadata, got %s", hash, gitHash)
+	}
+
+	// any change to the mod content changes the hash
+	if err := os.WriteFile(filepath.Join(dir, "query.sql"), []byte("select 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changedHash, _ := HashMod(dir); changedHash == hash {
+		t.Errorf("expected hash to change when a file is added")
+	}
+}
+
+func TestLockEqual(t *testing.T) {
+	entry := func(version, hash string) *Entry {
+		return &Entry{
+			InstalledModVersion: versionmap.InstalledModVersion{
+				ResolvedVersionConstraint: &versionmap.ResolvedVersionConstraint{
+					DependencyVersion: modconfig.DependencyVersion{Version: semver.MustParse(version)},
+					Name:              "github.com/acme/dep",
+				},
+			},
+			Hash: hash,
+		}
+	}
+	lock := func(e *Entry) Lock {
+		return Lock{"local": {"github.com/acme/dep": e}}
+	}
+
+	if !lock(entry("1.0.0", "")).Equal(lock(entry("1.0.0", "h1:abc"))) {
+		t.Errorf("expected locks which differ only by hash to be equal")
+	}
+	if lock(entry("1.0.0", "")).Equal(lock(entry("1.1.0", ""))) {
+		t.Errorf("expected locks with different versions not to be equal")
+	}
+	if lock(entry("1.0.0", "")).Equal(Lock{}) {
+		t.Errorf("expected locks with different dependencies not to be equal")
+	}
+}
@@ -0,0 +1,288 @@
+// Package modpublish publishes a release of a mod: the mod is linted and validated, the release is tagged in Git and,
+// optionally, the tag is pushed and the mod is added to a private mod index.
+package modpublish
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/modcompat"
+	"github.com/turbot/powerpipe/internal/modlint"
+	"github.com/turbot/powerpipe/internal/modsearch"
+)
+
+// Release is a mod release to publish
+type Release struct {
+	// the loaded mod
+	Mod *modconfig.Mod
+	// the full name of the mod, derived from the Git remote, e.g. github.com/acme/powerpipe-mod-internal
+	Name string
+	// the release version
+	Version *semver.Version
+	// the warnings raised validating the mod
+	Warnings []string
+
+	repo   *git.Repository
+	remote string
+}
+
+// TagName returns the name of the release tag, e.g. v1.2.0
+func (r *Release) TagName() string {
+	return "v" + r.Version.String()
+}
+
+// Prepare validates the mod and the Git repository, returning the release to publish
+// - the mod must have no lint errors, load without errors and have a title and description
+// - lint warnings are returned as release warnings
+// - the Git worktree must be clean, and the version must be greater than all previously released versions
+// - breaking changes since the previous release are returned as release warnings, unless the major version is bumped
+func Prepare(ctx context.Context, workspacePath, version, remote string) (*Release, error) {
+	v, err := semver.StrictNewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version '%s' - must be a semantic version, e.g. v1.2.0", version)
+	}
+
+	issues, err := modlint.Lint(ctx, workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	if modlint.HasErrors(issues) {
+		return nil, fmt.Errorf("mod has lint errors - run powerpipe mod lint for details")
+	}
+
+	w, errAndWarnings := workspace.Load(ctx, workspacePath, workspace.WithVariableValidation(false))
+	if err := errAndWarnings.GetError(); err != nil {
+		return nil, fmt.Errorf("mod failed to load: %w", err)
+	}
+	if !w.ModfileExists() {
+		return nil, fmt.Errorf("no mod definition found in %s", workspacePath)
+	}
+	if err := validateMetadata(w.Mod); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpenWithOptions(workspacePath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("the mod must be in a Git repository to publish it: %w", err)
+	}
+	if err := checkWorktreeClean(repo); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(repo, v); err != nil {
+		return nil, err
+	}
+	warnings := append(errAndWarnings.Warnings, lintWarnings(issues)...)
+	breakingWarnings, err := breakingChangeWarnings(workspacePath, repo, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Release{
+		Mod:      w.Mod,
+		Name:     modName(repo, remote),
+		Version:  v,
+		Warnings: append(warnings, breakingWarnings...),
+		repo:     repo,
+		remote:   remote,
+	}, nil
+}
+
+// Tag creates an annotated release tag on the current commit
+func (r *Release) Tag() error {
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+	_, err = r.repo.CreateTag(r.TagName(), head.Hash(), &git.CreateTagOptions{
+		Message: fmt.Sprintf("Release %s", r.TagName()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", r.TagName(), err)
+	}
+	return nil
+}
+
+// Push pushes the release tag to the remote
+func (r *Release) Push(ctx context.Context) error {
+	refSpec := config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", r.TagName(), r.TagName()))
+	err := r.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: r.remote,
+		RefSpecs:   []config.RefSpec{refSpec},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push tag %s to %s: %w", r.TagName(), r.remote, err)
+	}
+	return nil
+}
+
+// UpdateIndex adds the release to the index file at the given path, replacing any existing entry for the mod
+func (r *Release) UpdateIndex(indexPath string, maintainers []string) error {
+	if r.Name == "" {
+		return fmt.Errorf("the mod name could not be determined from the Git remote '%s'", r.remote)
+	}
+	index, err := modsearch.LoadIndexFile(indexPath)
+	if err != nil {
+		return err
+	}
+	index.Upsert(&modsearch.Mod{
+		Name:        r.Name,
+		Title:       typehelpers.SafeString(r.Mod.Title),
+		Description: typehelpers.SafeString(r.Mod.Description),
+		Version:     r.Version.String(),
+		Tags:        indexTags(r.Mod),
+		Maintainers: maintainers,
+	})
+	return index.Save(indexPath)
+}
+
+func lintWarnings(issues []*modlint.Issue) []string {
+	var res []string
+	for _, i := range issues {
+		if location := i.Location(); location != "" {
+			res = append(res, fmt.Sprintf("%s: %s (%s)", location, i.Message, i.Rule))
+		} else {
+			res = append(res, fmt.Sprintf("%s (%s)", i.Message, i.Rule))
+		}
+	}
+	return res
+}
+
+func validateMetadata(mod *modconfig.Mod) error {
+	var missing []string
+	if typehelpers.SafeString(mod.Title) == "" {
+		missing = append(missing, "title")
+	}
+	if typehelpers.SafeString(mod.Description) == "" {
+		missing = append(missing, "description")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("mod '%s' must have a %s to be published", mod.ShortName, strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+func checkWorktreeClean(repo *git.Repository) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	if !status.IsClean() {
+		return fmt.Errorf("the Git worktree has uncommitted changes - commit them before publishing")
+	}
+	return nil
+}
+
+// checkVersion checks the version is not already tagged, and is greater than all previously tagged versions
+func checkVersion(repo *git.Repository, version *semver.Version) error {
+	tags, err := repo.Tags()
+	if err != nil {
+		return err
+	}
+	return tags.ForEach(func(ref *plumbing.Reference) error {
+		tagVersion, err := semver.NewVersion(ref.Name().Short())
+		if err != nil {
+			// ignore non version tags
+			return nil
+		}
+		if !version.GreaterThan(tagVersion) {
+			return fmt.Errorf("version v%s must be greater than the existing release %s", version, ref.Name().Short())
+		}
+		return nil
+	})
+}
+
+// breakingChangeWarnings returns a warning for each breaking change since the latest release, if the version does not
+// bump the major version of that release
+func breakingChangeWarnings(workspacePath string, repo *git.Repository, version *semver.Version) ([]string, error) {
+	latest, err := latestVersionTag(repo)
+	if err != nil || latest == "" {
+		return nil, err
+	}
+	latestVersion, _ := semver.NewVersion(latest)
+	if version.Major() > latestVersion.Major() {
+		return nil, nil
+	}
+
+	oldSurface, err := modcompat.LoadRevisionSurface(workspacePath, latest)
+	if err != nil {
+		return nil, err
+	}
+	newSurface, err := modcompat.LoadSurface(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, c := range modcompat.Compare(oldSurface, newSurface) {
+		res = append(res, fmt.Sprintf("breaking change since %s: %s: %s - consider releasing a new major version", latest, c.Resource, c.Message))
+	}
+	return res, nil
+}
+
+// latestVersionTag returns the name of the tag of the greatest released version, or empty if there are no releases
+func latestVersionTag(repo *git.Repository) (string, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", err
+	}
+	var latest *semver.Version
+	var res string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		tagVersion, err := semver.NewVersion(ref.Name().Short())
+		if err != nil {
+			// ignore non version tags
+			return nil
+		}
+		if latest == nil || tagVersion.GreaterThan(latest) {
+			latest, res = tagVersion, ref.Name().Short()
+		}
+		return nil
+	})
+	return res, err
+}
+
+// modName returns the mod name from the URL of the Git remote,
+// e.g. github.com/acme/mod for https://github.com/acme/mod.git or git@github.com:acme/mod.git
+func modName(repo *git.Repository, remoteName string) string {
+	remote, err := repo.Remote(remoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	remoteURL := strings.TrimSuffix(remote.Config().URLs[0], ".git")
+	if u, err := url.Parse(remoteURL); err == nil && u.Host != "" {
+		return u.Host + u.Path
+	}
+	// scp style ssh url, e.g. git@github.com:acme/mod
+	if _, hostAndPath, ok := strings.Cut(remoteURL, "@"); ok {
+		return strings.Replace(hostAndPath, ":", "/", 1)
+	}
+	return ""
+}
+
+// indexTags returns the index tags for the mod - the values of the mod tags and its categories
+func indexTags(mod *modconfig.Mod) []string {
+	var res []string
+	for _, v := range mod.Tags {
+		res = append(res, strings.ToLower(v))
+	}
+	for _, c := range mod.Categories {
+		res = append(res, strings.ToLower(c))
+	}
+	res = helpers.StringSliceDistinct(res)
+	sort.Strings(res)
+	return res
+}
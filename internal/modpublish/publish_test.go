@@ -0,0 +1,399 @@
+package modpublish
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/powerpipe/internal/modsearch"
+)
+
+const testModFile = `mod "local" {
+  title       = "Internal"
+  description = "Internal dashboards"
+  categories  = ["AWS", "Compliance"]
+  tags = {
+    service = "AWS"
+  }
+}
+
+query "instances" {
+  title = "Instances"
+  sql   = "select 1"
+}
+`
+
+// setAppSpecificConstants sets the app specific constants used to lint and load the mod
+func setAppSpecificConstants() {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.VariablesExtensions = []string{".ppvars", ".spvars"}
+	app_specific.AutoVariablesExtensions = []string{".auto.ppvars", ".auto.spvars"}
+	app_specific.WorkspaceIgnoreFile = ".powerpipeignore"
+	app_specific.WorkspaceDataDir = ".powerpipe"
+	app_specific.DefaultVarsFileName = "powerpipe.ppvars"
+}
+
+// newTestRepo creates a Git repository containing the files, committed, with a tag for each of the tags
+func newTestRepo(t *testing.T, files map[string]string, tags ...string) (string, *git.Repository) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the release tag is created by the configured user
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name, cfg.User.Email = "Mod Author", "author@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, dir, repo, files)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		if _, err := repo.CreateTag(tag, head.Hash(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, repo
+}
+
+func commitFiles(t *testing.T, dir string, repo *git.Repository, files map[string]string) {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature := &object.Signature{Name: "Mod Author", Email: "author@example.com", When: time.Now()}
+	if _, err := worktree.Commit("commit", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	setAppSpecificConstants()
+
+	tests := map[string]struct {
+		files     map[string]string
+		tags      []string
+		version   string
+		dirty     bool
+		expectErr string
+	}{
+		"first release": {
+			files:   map[string]string{"mod.pp": testModFile},
+			version: "v1.0.0",
+		},
+		"version without v prefix": {
+			files:   map[string]string{"mod.pp": testModFile},
+			tags:    []string{"v1.0.0"},
+			version: "1.1.0",
+		},
+		"invalid version": {
+			files:     map[string]string{"mod.pp": testModFile},
+			version:   "latest",
+			expectErr: "invalid version 'latest' - must be a semantic version, e.g. v1.2.0",
+		},
+		"missing metadata": {
+			files:     map[string]string{"mod.pp": "mod \"local\" {}\n"},
+			version:   "v1.0.0",
+			expectErr: "mod 'local' must have a title and description to be published",
+		},
+		"uncommitted changes": {
+			files:     map[string]string{"mod.pp": testModFile},
+			version:   "v1.0.0",
+			dirty:     true,
+			expectErr: "the Git worktree has uncommitted changes - commit them before publishing",
+		},
+		"version already released": {
+			files:     map[string]string{"mod.pp": testModFile},
+			tags:      []string{"v1.0.0", "v1.2.0"},
+			version:   "v1.1.0",
+			expectErr: "version v1.1.0 must be greater than the existing release v1.2.0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, repo := newTestRepo(t, test.files, test.tags...)
+			if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/acme/powerpipe-mod-internal.git"}}); err != nil {
+				t.Fatal(err)
+			}
+			if test.dirty {
+				if err := os.WriteFile(filepath.Join(dir, "mod.pp"), []byte(testModFile+"\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			release, err := Prepare(context.Background(), dir, test.version, "origin")
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if release.Name != "github.com/acme/powerpipe-mod-internal" {
+				t.Errorf("got name %s, expected github.com/acme/powerpipe-mod-internal", release.Name)
+			}
+			if expected := "v" + strings.TrimPrefix(test.version, "v"); release.TagName() != expected {
+				t.Errorf("got tag %s, expected %s", release.TagName(), expected)
+			}
+		})
+	}
+}
+
+func TestPrepareBreakingChanges(t *testing.T) {
+	setAppSpecificConstants()
+	dir, repo := newTestRepo(t, map[string]string{"mod.pp": testModFile}, "v1.0.0")
+	// remove the query in a new commit
+	commitFiles(t, dir, repo, map[string]string{"mod.pp": testModFile[:strings.Index(testModFile, "query")]})
+
+	release, err := Prepare(context.Background(), dir, "v1.1.0", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(release.Warnings) != 1 || !strings.HasPrefix(release.Warnings[0], "breaking change since v1.0.0: ") {
+		t.Errorf("expected a breaking change warning, got %v", release.Warnings)
+	}
+
+	// there is no warning if the major version is bumped
+	release, err = Prepare(context.Background(), dir, "v2.0.0", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(release.Warnings) != 0 {
+		t.Errorf("expected no warnings for a major release, got %v", release.Warnings)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	_, repo := newTestRepo(t, map[string]string{"mod.pp": testModFile}, "v1.0.0", "v1.2.0", "stable")
+
+	tests := map[string]struct {
+		version   string
+		expectErr string
+	}{
+		"greater":            {version: "1.2.1"},
+		"major":              {version: "2.0.0"},
+		"prerelease greater": {version: "1.3.0-rc.1"},
+		"equal": {
+			version:   "1.2.0",
+			expectErr: "version v1.2.0 must be greater than the existing release v1.2.0",
+		},
+		"less": {
+			version:   "1.1.0",
+			expectErr: "version v1.1.0 must be greater than the existing release v1.2.0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkVersion(repo, semver.MustParse(test.version))
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLatestVersionTag(t *testing.T) {
+	tests := map[string]struct {
+		tags     []string
+		expected string
+	}{
+		"no tags":                  {expected: ""},
+		"non version tags":         {tags: []string{"stable", "latest"}, expected: ""},
+		"greatest version":         {tags: []string{"v1.10.0", "v1.2.0", "v1.9.3"}, expected: "v1.10.0"},
+		"ignores non version tags": {tags: []string{"v0.1.0", "stable"}, expected: "v0.1.0"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, repo := newTestRepo(t, map[string]string{"mod.pp": testModFile}, test.tags...)
+			res, err := latestVersionTag(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != test.expected {
+				t.Errorf("got %q, expected %q", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestModName(t *testing.T) {
+	tests := map[string]struct {
+		urls     []string
+		expected string
+	}{
+		"https":        {urls: []string{"https://github.com/acme/mod.git"}, expected: "github.com/acme/mod"},
+		"https no git": {urls: []string{"https://gitlab.acme.com/group/mod"}, expected: "gitlab.acme.com/group/mod"},
+		"ssh url":      {urls: []string{"ssh://git@github.com/acme/mod.git"}, expected: "github.com/acme/mod"},
+		"scp style":    {urls: []string{"git@github.com:acme/mod.git"}, expected: "github.com/acme/mod"},
+		"local path":   {urls: []string{"/repos/mod.git"}, expected: ""},
+		"no remote":    {expected: ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, repo := newTestRepo(t, map[string]string{"mod.pp": testModFile})
+			if test.urls != nil {
+				if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: test.urls}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if res := modName(repo, "origin"); res != test.expected {
+				t.Errorf("got %q, expected %q", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestTag(t *testing.T) {
+	_, repo := newTestRepo(t, map[string]string{"mod.pp": testModFile}, "v1.0.0")
+	release := &Release{Version: semver.MustParse("1.1.0"), repo: repo}
+	if err := release.Tag(); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Tag("v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("expected an annotated tag: %s", err)
+	}
+	if tag.Message != "Release v1.1.0\n" {
+		t.Errorf("got message %q, expected %q", tag.Message, "Release v1.1.0\n")
+	}
+
+	// a release cannot be tagged twice
+	if err := release.Tag(); err == nil {
+		t.Error("expected an error tagging an existing release")
+	}
+}
+
+func newTestMod() *modconfig.Mod {
+	mod := modconfig.NewMod("local", "/workspace", hcl.Range{})
+	title, description := "Internal", "Internal dashboards"
+	mod.Title = &title
+	mod.Description = &description
+	mod.Categories = []string{"AWS", "Compliance"}
+	mod.Tags = map[string]string{"service": "AWS", "type": "Benchmark"}
+	return mod
+}
+
+func TestUpdateIndex(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	existing := &modsearch.Index{Mods: []*modsearch.Mod{
+		{Name: "github.com/acme/other", Version: "0.1.0"},
+		{Name: "github.com/acme/powerpipe-mod-internal", Version: "0.9.0"},
+	}}
+	if err := existing.Save(indexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	release := &Release{Mod: newTestMod(), Name: "github.com/acme/powerpipe-mod-internal", Version: semver.MustParse("1.0.0")}
+	if err := release.UpdateIndex(indexPath, []string{"platform-team"}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := modsearch.LoadIndexFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*modsearch.Mod{
+		{Name: "github.com/acme/other", Version: "0.1.0"},
+		{
+			Name:        "github.com/acme/powerpipe-mod-internal",
+			Title:       "Internal",
+			Description: "Internal dashboards",
+			Version:     "1.0.0",
+			Tags:        []string{"aws", "benchmark", "compliance"},
+			Maintainers: []string{"platform-team"},
+		},
+	}
+	if !reflect.DeepEqual(index.Mods, expected) {
+		t.Errorf("got %v, expected %v", index.Mods, expected)
+	}
+}
+
+func TestUpdateIndexWithoutName(t *testing.T) {
+	release := &Release{Mod: newTestMod(), Version: semver.MustParse("1.0.0"), remote: "origin"}
+	err := release.UpdateIndex(filepath.Join(t.TempDir(), "index.json"), nil)
+	expectedErr := "the mod name could not be determined from the Git remote 'origin'"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	empty := ""
+	tests := map[string]struct {
+		title       *string
+		description *string
+		expectErr   string
+	}{
+		"complete": {
+			title:       newTestMod().Title,
+			description: newTestMod().Description,
+		},
+		"missing title": {
+			description: newTestMod().Description,
+			expectErr:   "mod 'local' must have a title to be published",
+		},
+		"empty description": {
+			title:       newTestMod().Title,
+			description: &empty,
+			expectErr:   "mod 'local' must have a description to be published",
+		},
+		"missing both": {
+			expectErr: "mod 'local' must have a title and description to be published",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := newTestMod()
+			mod.Title, mod.Description = test.title, test.description
+			err := validateMetadata(mod)
+			if test.expectErr != "" {
+				if err == nil || err.Error() != test.expectErr {
+					t.Errorf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
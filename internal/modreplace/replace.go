@@ -0,0 +1,194 @@
+// Package modreplace replaces installed dependency mods with local directories, so changes to a dependency can be
+// tested without publishing a new version.
+//
+// A replaced dependency is installed as a tree of symlinks to the files of the local directory, so edits to existing
+// files are picked up immediately. The lock file is not changed - the replaced mod is loaded in place of the locked
+// version - and replacements last until the next mod install (mod update reapplies them to the updated versions).
+package modreplace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// the name of the file which records the current replacements, relative to the workspace data directory
+const stateFileName = "replacements.json"
+
+// state is the content of the replacements file
+type state struct {
+	// the local directory which replaces each dependency, keyed by mod name
+	Replacements map[string]string `json:"replacements"`
+	// the dependency paths (<name>@<version>) which have been replaced
+	DependencyPaths []string `json:"dependency_paths"`
+}
+
+// Parse parses replacements of the form <mod name>=<path>, returning the absolute path of the local directory
+// which replaces each mod
+func Parse(values []string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, v := range values {
+		name, path, ok := strings.Cut(v, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid replacement '%s' - expected <mod name>=<path>", v)
+		}
+		path, err := filehelpers.Tildefy(path)
+		if err != nil {
+			return nil, err
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, err
+		}
+		if _, exists := parse.ModFileExists(path); !exists {
+			return nil, fmt.Errorf("invalid replacement for %s - %s does not contain a mod definition", name, path)
+		}
+		res[name] = path
+	}
+	return res, nil
+}
+
+// Restore removes the installed replacements which are not in the given set, so the locked versions are reinstalled
+// by the next install
+func Restore(workspacePath string, keep map[string]string) error {
+	s, err := loadState(workspacePath)
+	if err != nil {
+		return err
+	}
+	kept := &state{Replacements: make(map[string]string)}
+	for _, dependencyPath := range s.DependencyPaths {
+		name, _, _ := strings.Cut(dependencyPath, "@")
+		if path, ok := keep[name]; ok && path == s.Replacements[name] {
+			kept.Replacements[name] = path
+			kept.DependencyPaths = append(kept.DependencyPaths, dependencyPath)
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(filepaths.WorkspaceModPath(workspacePath), dependencyPath)); err != nil {
+			return err
+		}
+	}
+	if len(kept.DependencyPaths) > 0 {
+		return saveState(workspacePath, kept)
+	}
+	return os.RemoveAll(statePath(workspacePath))
+}
+
+// Apply installs the replacements in place of the locked versions of the dependencies, returning the dependency
+// paths which were replaced. It returns an error if a replaced mod is not a dependency of the workspace.
+func Apply(workspacePath string, replacements map[string]string) ([]string, error) {
+	if len(replacements) == 0 {
+		return nil, nil
+	}
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &state{Replacements: replacements}
+	for name, localPath := range replacements {
+		var replaced bool
+		for dependencyPath, dep := range lock.InstallCache.FlatMap() {
+			if dep.Name != name || dep.FilePath != "" {
+				continue
+			}
+			if err := link(localPath, filepath.Join(filepaths.WorkspaceModPath(workspacePath), dependencyPath)); err != nil {
+				return nil, fmt.Errorf("failed to replace %s: %w", dependencyPath, err)
+			}
+			s.DependencyPaths = append(s.DependencyPaths, dependencyPath)
+			replaced = true
+		}
+		if !replaced {
+			return nil, fmt.Errorf("cannot replace %s - it is not a dependency of the workspace", name)
+		}
+	}
+	sort.Strings(s.DependencyPaths)
+	if err := saveState(workspacePath, s); err != nil {
+		return nil, err
+	}
+	return s.DependencyPaths, nil
+}
+
+// Current returns the current replacements, keyed by mod name
+func Current(workspacePath string) (map[string]string, error) {
+	s, err := loadState(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	return s.Replacements, nil
+}
+
+// Replaced returns the set of dependency paths which are currently replaced by local directories
+func Replaced(workspacePath string) (map[string]bool, error) {
+	s, err := loadState(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]bool, len(s.DependencyPaths))
+	for _, p := range s.DependencyPaths {
+		res[p] = true
+	}
+	return res, nil
+}
+
+// link replaces the dest directory with a tree of directories containing symlinks to the files in src
+// (symlinked directories are not followed when loading mods, so each file is linked individually)
+func link(src, dest string) error {
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// do not link Git metadata or the installed dependencies of the local mod
+		if d.IsDir() && path != src && (d.Name() == ".git" || d.Name() == app_specific.WorkspaceDataDir) {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return os.Symlink(path, target)
+	})
+}
+
+func statePath(workspacePath string) string {
+	return filepath.Join(workspacePath, app_specific.WorkspaceDataDir, stateFileName)
+}
+
+func loadState(workspacePath string) (*state, error) {
+	s := &state{}
+	content, err := os.ReadFile(statePath(workspacePath))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", statePath(workspacePath), err)
+	}
+	return s, nil
+}
+
+func saveState(workspacePath string, s *state) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath(workspacePath), content, 0644) //nolint:gosec // not sensitive
+}
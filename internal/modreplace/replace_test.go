@@ -0,0 +1,132 @@
+package modreplace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+)
+
+const testLockFile = `{
+  "local": {
+    "github.com/turbot/dep": {
+      "name": "github.com/turbot/dep",
+      "version": "1.0.0"
+    }
+  }
+}`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplaceWithLocalPath(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	// a workspace with the locked version of the dependency installed
+	ws := t.TempDir()
+	writeFile(t, filepaths.WorkspaceLockPath(ws), testLockFile)
+	installedPath := filepath.Join(filepaths.WorkspaceModPath(ws), "github.com/turbot/dep@v1.0.0")
+	writeFile(t, filepath.Join(installedPath, "mod.pp"), `mod "dep" {}`)
+	writeFile(t, filepath.Join(installedPath, "old.pp"), `query "old" { sql = "select 1" }`)
+
+	// a local checkout of the dependency
+	local := t.TempDir()
+	writeFile(t, filepath.Join(local, "mod.pp"), `mod "dep" {}`)
+	writeFile(t, filepath.Join(local, "queries", "new.pp"), `query "new" { sql = "select 2" }`)
+	writeFile(t, filepath.Join(local, ".git", "HEAD"), "ref: refs/heads/main")
+	writeFile(t, filepath.Join(local, ".powerpipe", "mods", "github.com/turbot/other@v1.0.0", "mod.pp"), `mod "other" {}`)
+
+	replacements, err := Parse([]string{"github.com/turbot/dep=" + local})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"github.com/turbot/dep": local}; !reflect.DeepEqual(replacements, want) {
+		t.Fatalf("Parse() = %v, want %v", replacements, want)
+	}
+
+	replaced, err := Apply(ws, replacements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"github.com/turbot/dep@v1.0.0"}; !reflect.DeepEqual(replaced, want) {
+		t.Errorf("Apply() = %v, want %v", replaced, want)
+	}
+
+	// the installed dependency should be a tree of symlinks to the files of the local directory
+	for _, name := range []string{"mod.pp", filepath.Join("queries", "new.pp")} {
+		target, err := os.Readlink(filepath.Join(installedPath, name))
+		if err != nil {
+			t.Errorf("expected %s to be linked: %v", name, err)
+			continue
+		}
+		if want := filepath.Join(local, name); target != want {
+			t.Errorf("%s links to %s, want %s", name, target, want)
+		}
+	}
+	for _, name := range []string{"old.pp", ".git", ".powerpipe"} {
+		if _, err := os.Lstat(filepath.Join(installedPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be installed, got %v", name, err)
+		}
+	}
+
+	current, err := Current(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(current, replacements) {
+		t.Errorf("Current() = %v, want %v", current, replacements)
+	}
+	isReplaced, err := Replaced(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"github.com/turbot/dep@v1.0.0": true}; !reflect.DeepEqual(isReplaced, want) {
+		t.Errorf("Replaced() = %v, want %v", isReplaced, want)
+	}
+
+	// restoring without the replacement removes the replaced dependency so the locked version is reinstalled
+	if err := Restore(ws, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(installedPath); !os.IsNotExist(err) {
+		t.Errorf("expected the replaced dependency to be removed, got %v", err)
+	}
+	if current, err := Current(ws); err != nil || len(current) != 0 {
+		t.Errorf("Current() = %v, %v after restore, want no replacements", current, err)
+	}
+}
+
+func TestReplaceNotADependency(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	ws := t.TempDir()
+	writeFile(t, filepaths.WorkspaceLockPath(ws), testLockFile)
+	local := t.TempDir()
+	writeFile(t, filepath.Join(local, "mod.pp"), `mod "other" {}`)
+
+	if _, err := Apply(ws, map[string]string{"github.com/turbot/other": local}); err == nil {
+		t.Fatal("expected an error replacing a mod which is not a dependency")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+
+	for _, v := range []string{"github.com/turbot/dep", "=../dep", "github.com/turbot/dep=", "github.com/turbot/dep=" + t.TempDir()} {
+		if _, err := Parse([]string{v}); err == nil {
+			t.Errorf("Parse(%q) expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package modsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+// the GitHub topic which identifies public mods
+const registryTopic = "powerpipe-mod"
+
+// the maximum number of results returned from the public registry
+const registryPageSize = 100
+
+const githubSearchURL = "https://api.github.com/search/repositories"
+
+type githubSearchResult struct {
+	Items []struct {
+		FullName    string   `json:"full_name"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Topics      []string `json:"topics"`
+		Owner       struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"items"`
+}
+
+// searchPublicRegistry searches the public registry using the GitHub repository search API
+func searchPublicRegistry(ctx context.Context, query Query) ([]*Mod, error) {
+	terms := []string{fmt.Sprintf("topic:%s", registryTopic)}
+	if query.Text != "" {
+		terms = append(terms, query.Text)
+	}
+	for _, tag := range query.Tags {
+		terms = append(terms, fmt.Sprintf("topic:%s", tag))
+	}
+	if query.Maintainer != "" {
+		terms = append(terms, fmt.Sprintf("user:%s", query.Maintainer))
+	}
+	searchURL := fmt.Sprintf("%s?q=%s&per_page=%d", githubSearchURL, url.QueryEscape(strings.Join(terms, " ")), registryPageSize)
+
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	// use the Git token if set, as unauthenticated searches are heavily rate limited
+	if token := os.Getenv(app_specific.EnvGitToken); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	content, err := get(ctx, searchURL, headers)
+	if err != nil {
+		return nil, err
+	}
+	var result githubSearchResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("invalid search response: %w", err)
+	}
+
+	res := make([]*Mod, 0, len(result.Items))
+	for _, item := range result.Items {
+		m := &Mod{
+			Name:        "github.com/" + item.FullName,
+			Title:       item.Name,
+			Description: item.Description,
+			Maintainers: []string{item.Owner.Login},
+		}
+		// the registry topic is common to all mods, so is not included in the tags
+		for _, topic := range item.Topics {
+			if topic != registryTopic {
+				m.Tags = append(m.Tags, topic)
+			}
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
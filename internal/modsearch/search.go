@@ -0,0 +1,237 @@
+// Package modsearch searches mod indexes for mods by name, tag and maintainer.
+//
+// An index is either the public registry (the mods on GitHub with the powerpipe-mod topic, which are listed on
+// https://hub.powerpipe.io), or a private index - a JSON file, read from a URL or local path, of the form:
+//
+//	{
+//	  "mods": [
+//	    {
+//	      "name": "github.com/acme/powerpipe-mod-internal",
+//	      "title": "Internal Compliance",
+//	      "description": "Internal compliance benchmarks",
+//	      "version": "1.2.0",
+//	      "tags": ["aws", "compliance"],
+//	      "maintainers": ["platform-team"]
+//	    }
+//	  ]
+//	}
+package modsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+)
+
+// PublicRegistry is the name of the public mod registry index
+const PublicRegistry = "hub"
+
+// the timeout for index requests
+const requestTimeout = 30 * time.Second
+
+// Mod is a mod listed in an index
+type Mod struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	// the index the mod was found in
+	Index string `json:"index,omitempty"`
+}
+
+// InstallCommand returns the command to install the mod
+func (m *Mod) InstallCommand() string {
+	return fmt.Sprintf("powerpipe mod install %s", m.Name)
+}
+
+// Index is the content of a mod index file
+type Index struct {
+	Mods []*Mod `json:"mods"`
+}
+
+// Query is a mod search query - all the set criteria must match
+type Query struct {
+	// text which must be contained in the mod name, title or description
+	Text string
+	// tags the mod must have
+	Tags []string
+	// a maintainer of the mod
+	Maintainer string
+}
+
+// Search searches the indexes for mods matching the query, returning the matches sorted by name
+// - an error reading any index is returned along with the matches from the other indexes
+func Search(ctx context.Context, indexes []string, query Query) ([]*Mod, error) {
+	var res []*Mod
+	var errs []string
+	for _, index := range indexes {
+		var mods []*Mod
+		var err error
+		if index == PublicRegistry {
+			mods, err = searchPublicRegistry(ctx, query)
+		} else {
+			mods, err = searchIndex(ctx, index, query)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", index, err.Error()))
+			continue
+		}
+		for _, m := range mods {
+			m.Index = index
+		}
+		res = append(res, mods...)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	if len(errs) > 0 {
+		return res, fmt.Errorf("failed to search %s", strings.Join(errs, "; "))
+	}
+	return res, nil
+}
+
+// IsRemote returns whether searching the index requires network access, i.e. it is the public registry or a URL
+func IsRemote(index string) bool {
+	return index == PublicRegistry || isURL(index)
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// LoadIndex reads an index from a URL or local path
+func LoadIndex(ctx context.Context, location string) (*Index, error) {
+	var content []byte
+	var err error
+	if isURL(location) {
+		content, err = get(ctx, location, nil)
+	} else {
+		location, err = filehelpers.Tildefy(location)
+		if err == nil {
+			content, err = os.ReadFile(location)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{}
+	if err := json.Unmarshal(content, index); err != nil {
+		return nil, fmt.Errorf("invalid index: %w", err)
+	}
+	return index, nil
+}
+
+// LoadIndexFile reads an index from a local path, returning an empty index if the file does not exist
+func LoadIndexFile(path string) (*Index, error) {
+	path, err := filehelpers.Tildefy(path)
+	if err != nil {
+		return nil, err
+	}
+	if !filehelpers.FileExists(path) {
+		return &Index{}, nil
+	}
+	return LoadIndex(context.Background(), path)
+}
+
+// Upsert adds the mod to the index, replacing any existing entry with the same name
+func (i *Index) Upsert(mod *Mod) {
+	for idx, m := range i.Mods {
+		if m.Name == mod.Name {
+			i.Mods[idx] = mod
+			return
+		}
+	}
+	i.Mods = append(i.Mods, mod)
+	sort.Slice(i.Mods, func(a, b int) bool { return i.Mods[a].Name < i.Mods[b].Name })
+}
+
+// Save writes the index to a local path
+func (i *Index) Save(path string) error {
+	path, err := filehelpers.Tildefy(path)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644) //nolint:gosec // the index is not sensitive
+}
+
+func searchIndex(ctx context.Context, location string, query Query) ([]*Mod, error) {
+	index, err := LoadIndex(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Mod
+	for _, m := range index.Mods {
+		if query.Matches(m) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// Matches returns whether the mod matches the query
+func (q Query) Matches(m *Mod) bool {
+	if text := strings.ToLower(q.Text); text != "" {
+		if !strings.Contains(strings.ToLower(m.Name+"\n"+m.Title+"\n"+m.Description), text) {
+			return false
+		}
+	}
+	for _, tag := range q.Tags {
+		if !helpers.StringSliceContains(lower(m.Tags), strings.ToLower(tag)) {
+			return false
+		}
+	}
+	if q.Maintainer != "" && !helpers.StringSliceContains(lower(m.Maintainers), strings.ToLower(q.Maintainer)) {
+		return false
+	}
+	return true
+}
+
+func lower(s []string) []string {
+	res := make([]string, len(s))
+	for i, v := range s {
+		res[i] = strings.ToLower(v)
+	}
+	return res
+}
+
+func get(ctx context.Context, location string, headers map[string]string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// the errors are reported by index, so do not include the (query escaped) request URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,64 @@
+package modsearch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testIndex = `{"mods": [
+	{"name": "github.com/acme/mod-aws-compliance", "title": "AWS Compliance", "tags": ["aws", "cis"], "maintainers": ["security"]},
+	{"name": "github.com/acme/mod-aws-costs", "description": "AWS cost dashboards", "tags": ["aws"], "maintainers": ["finops"]},
+	{"name": "github.com/acme/mod-gcp-compliance", "title": "GCP Compliance", "tags": ["gcp", "CIS"], "maintainers": ["Security"]}
+]}`
+
+type searchTest struct {
+	query    Query
+	expected []string
+}
+
+func TestSearch(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(indexPath, []byte(testIndex), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]searchTest{
+		"all": {
+			query:    Query{},
+			expected: []string{"github.com/acme/mod-aws-compliance", "github.com/acme/mod-aws-costs", "github.com/acme/mod-gcp-compliance"},
+		},
+		"text matches title and description": {
+			query:    Query{Text: "aws"},
+			expected: []string{"github.com/acme/mod-aws-compliance", "github.com/acme/mod-aws-costs"},
+		},
+		"tags are case insensitive": {
+			query:    Query{Tags: []string{"cis"}},
+			expected: []string{"github.com/acme/mod-aws-compliance", "github.com/acme/mod-gcp-compliance"},
+		},
+		"all criteria must match": {
+			query:    Query{Text: "compliance", Tags: []string{"aws"}, Maintainer: "security"},
+			expected: []string{"github.com/acme/mod-aws-compliance"},
+		},
+		"no matches": {
+			query: Query{Maintainer: "nobody"},
+		},
+	}
+
+	for name, test := range tests {
+		mods, err := Search(context.Background(), []string{indexPath}, test.query)
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %v", name, err)
+			continue
+		}
+		var res []string
+		for _, m := range mods {
+			res = append(res, m.Name)
+		}
+		if !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("Test: '%s' FAILED : expected %v, got %v", name, test.expected, res)
+		}
+	}
+}
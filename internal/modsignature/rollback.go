@@ -0,0 +1,92 @@
+package modsignature
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// Rollback records the state of a workspace before mods are installed with the fail policy, so the install can be
+// undone if any installed mod fails verification
+//
+// NOTE: the mods of the previous lock file must still be installed when the install is undone, so unused mods must
+// not be pruned by the install
+type Rollback struct {
+	workspacePath string
+	// the content of the lock file and mod files before the install, keyed by path (nil if the file did not exist)
+	files map[string][]byte
+	// the dependency paths of the mods installed before the install
+	installed map[string]bool
+}
+
+// NewRollback records the lock file, mod files and installed mods of the workspace
+func NewRollback(workspacePath string) (*Rollback, error) {
+	r := &Rollback{
+		workspacePath: workspacePath,
+		files:         map[string][]byte{},
+		installed:     map[string]bool{},
+	}
+	for _, path := range append(app_specific.ModFilePaths(workspacePath), filepaths.WorkspaceLockPath(workspacePath)) {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		r.files[path] = content
+	}
+	installed, err := installedDependencyPaths(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, dependencyPath := range installed {
+		r.installed[dependencyPath] = true
+	}
+	return r, nil
+}
+
+// Undo removes the mods which were installed by the install, and restores the lock file and mod files
+func (r *Rollback) Undo() error {
+	installed, err := installedDependencyPaths(r.workspacePath)
+	if err != nil {
+		return err
+	}
+	for _, dependencyPath := range installed {
+		if r.installed[dependencyPath] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(filepaths.WorkspaceModPath(r.workspacePath), dependencyPath)); err != nil {
+			return err
+		}
+	}
+	for path, content := range r.files {
+		if content == nil {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil { //nolint:gosec // same permissions as the mod installer
+			return err
+		}
+	}
+	return nil
+}
+
+// installedDependencyPaths returns the dependency paths of the mods in the lock file of the workspace which are
+// installed from Git (local filepath dependencies are not installed)
+func installedDependencyPaths(workspacePath string) ([]string, error) {
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, dep := range lock.InstallCache.FlatMap() {
+		if dep.FilePath == "" {
+			res = append(res, dep.DependencyPath())
+		}
+	}
+	return res, nil
+}
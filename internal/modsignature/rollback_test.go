@@ -0,0 +1,112 @@
+package modsignature
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+)
+
+func testLockFile(version string) string {
+	return `{
+  "local": {
+    "github.com/turbot/dep": {
+      "name": "github.com/turbot/dep",
+      "version": "` + version + `"
+    }
+  }
+}`
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestRollback(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	// a workspace with version 1.0.0 of the dependency installed
+	ws := t.TempDir()
+	modFile := filepath.Join(ws, "mod.pp")
+	previousModFile := `mod "local" {
+  require {
+    mod "github.com/turbot/dep" {
+      version = "^1"
+    }
+  }
+}`
+	writeFile(t, modFile, previousModFile)
+	writeFile(t, filepaths.WorkspaceLockPath(ws), testLockFile("1.0.0"))
+	previousPath := filepath.Join(filepaths.WorkspaceModPath(ws), "github.com/turbot/dep@v1.0.0")
+	writeFile(t, filepath.Join(previousPath, "mod.pp"), `mod "dep" {}`)
+
+	rollback, err := NewRollback(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// install version 2.0.0, without pruning version 1.0.0
+	writeFile(t, modFile, `mod "local" {}`)
+	writeFile(t, filepaths.WorkspaceLockPath(ws), testLockFile("2.0.0"))
+	installedPath := filepath.Join(filepaths.WorkspaceModPath(ws), "github.com/turbot/dep@v2.0.0")
+	writeFile(t, filepath.Join(installedPath, "mod.pp"), `mod "dep" {}`)
+
+	if err := rollback.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(installedPath); !os.IsNotExist(err) {
+		t.Errorf("expected the installed mod to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(previousPath, "mod.pp")); err != nil {
+		t.Errorf("expected the previously installed mod to be kept: %v", err)
+	}
+	if got := readFile(t, filepaths.WorkspaceLockPath(ws)); got != testLockFile("1.0.0") {
+		t.Errorf("expected the lock file to be restored, got %s", got)
+	}
+	if got := readFile(t, modFile); got != previousModFile {
+		t.Errorf("expected the mod file to be restored, got %s", got)
+	}
+}
+
+func TestRollbackNewWorkspace(t *testing.T) {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	// the install creates the mod file and lock file of a new workspace
+	ws := t.TempDir()
+	rollback, err := NewRollback(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(ws, "mod.pp"), `mod "local" {}`)
+	writeFile(t, filepaths.WorkspaceLockPath(ws), testLockFile("1.0.0"))
+	installedPath := filepath.Join(filepaths.WorkspaceModPath(ws), "github.com/turbot/dep@v1.0.0")
+	writeFile(t, filepath.Join(installedPath, "mod.pp"), `mod "dep" {}`)
+
+	if err := rollback.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{installedPath, filepath.Join(ws, "mod.pp"), filepaths.WorkspaceLockPath(ws)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+}
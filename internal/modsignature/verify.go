@@ -0,0 +1,142 @@
+// Package modsignature verifies the GPG signatures of installed mod dependencies.
+//
+// A mod release is signed by signing its Git tag (git tag -s) or, for lightweight tags and branches,
+// the commit which is installed (git commit -S). Signatures are verified against a keyring of trusted
+// armored public keys.
+package modsignature
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// the signature verification policies
+const (
+	// PolicyOff disables signature verification
+	PolicyOff = "off"
+	// PolicyWarn shows a warning for mods which are unsigned or have an invalid signature
+	PolicyWarn = "warn"
+	// PolicyFail fails the install for mods which are unsigned or have an invalid signature
+	PolicyFail = "fail"
+)
+
+// Policies is the list of valid verification policies
+var Policies = []string{PolicyOff, PolicyWarn, PolicyFail}
+
+// ErrUnsigned is returned for mods whose tag and commit are not signed
+var ErrUnsigned = errors.New("mod is not signed")
+
+// Result is the result of verifying the signature of an installed mod
+type Result struct {
+	// the dependency path of the mod, i.e. <name>@<version>
+	Mod string
+	// the identity of the key which signed the mod (if the signature is valid)
+	Signer string
+	// the error if the mod is unsigned or the signature is invalid
+	Error error
+}
+
+// LoadKeyRing reads an armored keyring file
+func LoadKeyRing(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("signature verification requires a file of trusted public keys")
+	}
+	path, err := files.Tildefy(path)
+	if err != nil {
+		return "", err
+	}
+	keyRing, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	return string(keyRing), nil
+}
+
+// VerifyInstalled verifies the signatures of all the mods installed in the workspace, returning a result for each
+func VerifyInstalled(workspacePath string, armoredKeyRing string) ([]Result, error) {
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Result
+	for _, dep := range lock.InstallCache.FlatMap() {
+		// local filepath dependencies are not installed from Git so cannot be verified
+		if dep.FilePath != "" {
+			continue
+		}
+		dependencyPath := dep.DependencyPath()
+		result := Result{Mod: dependencyPath}
+		result.Signer, result.Error = verifyMod(filepath.Join(filepaths.WorkspaceModPath(workspacePath), dependencyPath), dep.ResolvedVersionConstraint, armoredKeyRing)
+		res = append(res, result)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Mod < res[j].Mod })
+	return res, nil
+}
+
+// verifyMod verifies the signature of the tag of the installed mod, or the installed commit if the tag is not signed
+func verifyMod(modPath string, dep *versionmap.ResolvedVersionConstraint, armoredKeyRing string) (string, error) {
+	repo, err := git.PlainOpen(modPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open mod repository: %w", err)
+	}
+
+	commitHash := plumbing.NewHash(dep.Commit)
+	refName := plumbing.ReferenceName(dep.GitRefStr)
+	if refName.IsTag() {
+		if ref, err := repo.Reference(refName, true); err == nil {
+			// annotated tags have a tag object, which may be signed
+			if tag, err := repo.TagObject(ref.Hash()); err == nil {
+				if tag.PGPSignature != "" {
+					entity, err := tag.Verify(armoredKeyRing)
+					if err != nil {
+						return "", fmt.Errorf("invalid tag signature: %w", err)
+					}
+					return signerName(entity.Identities), nil
+				}
+				commitHash = tag.Target
+			}
+		}
+	}
+	if commitHash.IsZero() {
+		head, err := repo.Head()
+		if err != nil {
+			return "", err
+		}
+		commitHash = head.Hash()
+	}
+
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read mod commit: %w", err)
+	}
+	if commit.PGPSignature == "" {
+		return "", ErrUnsigned
+	}
+	entity, err := commit.Verify(armoredKeyRing)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit signature: %w", err)
+	}
+	return signerName(entity.Identities), nil
+}
+
+func signerName[T any](identities map[string]T) string {
+	names := make([]string, 0, len(identities))
+	for name := range identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "unknown"
+	}
+	return names[0]
+}
@@ -0,0 +1,170 @@
+package modsignature
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+func newTestEntity(t *testing.T, name, email string) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+// armoredKeyRing returns the armored public keys of the entities
+func armoredKeyRing(t *testing.T, entities ...*openpgp.Entity) string {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entity := range entities {
+		if err := entity.Serialize(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// newTestModRepo creates a mod repository with a single commit tagged v1.0.0 - the commit is signed by commitKey and
+// the tag is an annotated tag signed by tagKey (a lightweight tag if annotated is false)
+func newTestModRepo(t *testing.T, commitKey, tagKey *openpgp.Entity, annotated bool) (string, plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mod.pp"), []byte(`mod "dep" {}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("mod.pp"); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "Mod Author", Email: "author@example.com", When: time.Now()}
+	commit, err := worktree.Commit("release", &git.CommitOptions{Author: signature, SignKey: commitKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagOpts *git.CreateTagOptions
+	if annotated {
+		tagOpts = &git.CreateTagOptions{Tagger: signature, Message: "v1.0.0", SignKey: tagKey}
+	}
+	if _, err := repo.CreateTag("v1.0.0", commit, tagOpts); err != nil {
+		t.Fatal(err)
+	}
+	return dir, commit
+}
+
+func TestVerifyMod(t *testing.T) {
+	trusted := newTestEntity(t, "Trusted Publisher", "publisher@example.com")
+	untrusted := newTestEntity(t, "Someone Else", "someone@example.com")
+	keyRing := armoredKeyRing(t, trusted)
+
+	tests := map[string]struct {
+		commitKey  *openpgp.Entity
+		tagKey     *openpgp.Entity
+		annotated  bool
+		wantSigner string
+		wantErr    string
+	}{
+		"signed tag": {
+			tagKey:     trusted,
+			annotated:  true,
+			wantSigner: "Trusted Publisher <publisher@example.com>",
+		},
+		"signed commit with lightweight tag": {
+			commitKey:  trusted,
+			wantSigner: "Trusted Publisher <publisher@example.com>",
+		},
+		"signed commit with unsigned annotated tag": {
+			commitKey:  trusted,
+			annotated:  true,
+			wantSigner: "Trusted Publisher <publisher@example.com>",
+		},
+		"unsigned": {
+			annotated: true,
+			wantErr:   ErrUnsigned.Error(),
+		},
+		"tag signed by untrusted key": {
+			tagKey:    untrusted,
+			annotated: true,
+			wantErr:   "invalid tag signature",
+		},
+		"commit signed by untrusted key": {
+			commitKey: untrusted,
+			wantErr:   "invalid commit signature",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, commit := newTestModRepo(t, tc.commitKey, tc.tagKey, tc.annotated)
+			dep := &versionmap.ResolvedVersionConstraint{Commit: commit.String(), GitRefStr: "refs/tags/v1.0.0"}
+
+			signer, err := verifyMod(dir, dep, keyRing)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error %q, got %v (signer %q)", tc.wantErr, err, signer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signer != tc.wantSigner {
+				t.Errorf("expected signer %q, got %q", tc.wantSigner, signer)
+			}
+		})
+	}
+}
+
+func TestVerifyModNotARepository(t *testing.T) {
+	dep := &versionmap.ResolvedVersionConstraint{GitRefStr: "refs/tags/v1.0.0"}
+	if _, err := verifyMod(t.TempDir(), dep, ""); err == nil || errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected an error opening the repository, got %v", err)
+	}
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	if _, err := LoadKeyRing(""); err == nil {
+		t.Error("expected an error when no keyring is configured")
+	}
+	if _, err := LoadKeyRing(filepath.Join(t.TempDir(), "missing.asc")); err == nil {
+		t.Error("expected an error for a missing keyring")
+	}
+	path := filepath.Join(t.TempDir(), "trusted.asc")
+	keyRing := armoredKeyRing(t, newTestEntity(t, "Trusted Publisher", "publisher@example.com"))
+	if err := os.WriteFile(path, []byte(keyRing), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadKeyRing(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != keyRing {
+		t.Error("expected the content of the keyring file")
+	}
+}
@@ -0,0 +1,144 @@
+// Package modtemplate scaffolds new mods from the built-in templates used by powerpipe mod init.
+//
+// Each template is a directory of files which are copied to the mod location. Files with a .tmpl suffix are rendered
+// as Go templates (with the suffix removed), with the Data for the new mod.
+package modtemplate
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	filehelpers "github.com/turbot/go-kit/files"
+)
+
+//go:embed all:templates
+var templateFS embed.FS
+
+const (
+	templateDir    = "templates"
+	templateSuffix = ".tmpl"
+)
+
+// Data is the data the template files are rendered with
+type Data struct {
+	// the short name of the mod, e.g. aws_compliance
+	Name string
+	// the title of the mod
+	Title string
+}
+
+// NewData returns the template data for a mod created in the given directory
+// - the mod name is derived from the directory name, and the title is the directory name
+func NewData(modPath string) Data {
+	title := filepath.Base(modPath)
+	return Data{Name: modNameFromDir(title), Title: title}
+}
+
+// Names returns the names of the available templates
+func Names() []string {
+	entries, _ := fs.ReadDir(templateFS, templateDir)
+	var res []string
+	for _, e := range entries {
+		if e.IsDir() {
+			res = append(res, e.Name())
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Create writes the files of the named template to modPath, returning the paths of the files written
+// (relative to modPath). It returns an error without writing any files if any of the files already exist.
+func Create(name, modPath string, data Data) ([]string, error) {
+	root := path.Join(templateDir, name)
+	if _, err := fs.Stat(templateFS, root); err != nil {
+		return nil, fmt.Errorf("unknown mod template '%s' - must be one of: %s", name, strings.Join(Names(), ", "))
+	}
+
+	files, err := render(root, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var relPaths []string
+	for relPath := range files {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	var existing []string
+	for _, relPath := range relPaths {
+		if filehelpers.FileExists(filepath.Join(modPath, relPath)) {
+			existing = append(existing, relPath)
+		}
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("cannot create mod from template '%s' - these files already exist: %s", name, strings.Join(existing, ", "))
+	}
+
+	for _, relPath := range relPaths {
+		target := filepath.Join(modPath, relPath)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, files[relPath], 0644); err != nil { //nolint:gosec // mod files are not sensitive
+			return nil, err
+		}
+	}
+	return relPaths, nil
+}
+
+// render returns the content of the template files, keyed by their path relative to the mod
+func render(root string, data Data) (map[string][]byte, error) {
+	res := make(map[string][]byte)
+	err := fs.WalkDir(templateFS, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := templateFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		relPath := filepath.FromSlash(strings.TrimPrefix(p, root+"/"))
+		if strings.HasSuffix(relPath, templateSuffix) {
+			relPath = strings.TrimSuffix(relPath, templateSuffix)
+			t, err := template.New(relPath).Parse(string(content))
+			if err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			if err := t.Execute(&buf, data); err != nil {
+				return err
+			}
+			content = buf.Bytes()
+		}
+		res[relPath] = content
+		return nil
+	})
+	return res, err
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// modNameFromDir converts a directory name to a valid mod name,
+// e.g. steampipe-mod-aws-compliance becomes steampipe_mod_aws_compliance
+func modNameFromDir(dir string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(dir), "_"), "_")
+	if name == "" {
+		return "local"
+	}
+	// names must start with a letter or underscore
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package modtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModNameFromDir(t *testing.T) {
+	tests := map[string]string{
+		"steampipe-mod-aws-compliance": "steampipe_mod_aws_compliance",
+		"My Mod":                       "my_mod",
+		"2024-reports":                 "_2024_reports",
+		"---":                          "local",
+	}
+	for dir, expected := range tests {
+		if res := modNameFromDir(dir); res != expected {
+			t.Errorf("Test: '%s' FAILED : expected %s, got %s", dir, expected, res)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	for _, name := range Names() {
+		modPath := filepath.Join(t.TempDir(), "my-mod")
+		files, err := Create(name, modPath, NewData(modPath))
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : unexpected error %v", name, err)
+			continue
+		}
+		modFile, err := os.ReadFile(filepath.Join(modPath, "mod.pp"))
+		if err != nil {
+			t.Errorf("Test: '%s' FAILED : mod.pp not created: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(modFile), `mod "my_mod"`) || !strings.Contains(string(modFile), `title       = "my-mod"`) {
+			t.Errorf("Test: '%s' FAILED : mod.pp not rendered:\n%s", name, modFile)
+		}
+
+		// a second create must fail without overwriting the files
+		if _, err := Create(name, modPath, NewData(modPath)); err == nil {
+			t.Errorf("Test: '%s' FAILED : expected an error creating over %d existing files", name, len(files))
+		}
+	}
+
+	if _, err := Create("unknown", t.TempDir(), Data{}); err == nil {
+		t.Errorf("Test: 'unknown' FAILED : expected an error")
+	}
+}
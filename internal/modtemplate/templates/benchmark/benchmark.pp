@@ -0,0 +1,10 @@
+benchmark "s3" {
+  title       = "S3"
+  description = "Example controls for AWS S3 buckets."
+  children = [
+    control.s3_bucket_versioning_enabled,
+    control.s3_bucket_tagged
+  ]
+
+  tags = local.common_tags
+}
@@ -0,0 +1,21 @@
+control "s3_bucket_versioning_enabled" {
+  title       = "S3 buckets should have versioning enabled"
+  description = "Versioning keeps multiple variants of an object in the same bucket, so objects can be recovered after they are overwritten or deleted."
+  query       = query.s3_bucket_versioning_enabled
+  severity    = "medium"
+
+  tags = merge(local.common_tags, {
+    category = "data_protection"
+  })
+}
+
+control "s3_bucket_tagged" {
+  title       = "S3 buckets should have an owner tag"
+  description = "An owner tag identifies who is responsible for the bucket and its contents."
+  query       = query.s3_bucket_tagged
+  severity    = "low"
+
+  tags = merge(local.common_tags, {
+    category = "tagging"
+  })
+}
@@ -0,0 +1,41 @@
+query "s3_bucket_versioning_enabled" {
+  sql = <<-EOQ
+    select
+      arn as resource,
+      case
+        when versioning_enabled then 'ok'
+        else 'alarm'
+      end as status,
+      case
+        when versioning_enabled then name || ' versioning enabled.'
+        else name || ' versioning disabled.'
+      end as reason,
+      region,
+      account_id
+    from
+      aws_s3_bucket
+    order by
+      name;
+  EOQ
+}
+
+query "s3_bucket_tagged" {
+  sql = <<-EOQ
+    select
+      arn as resource,
+      case
+        when tags ? 'owner' then 'ok'
+        else 'alarm'
+      end as status,
+      case
+        when tags ? 'owner' then name || ' has owner ' || (tags ->> 'owner') || '.'
+        else name || ' has no owner tag.'
+      end as reason,
+      region,
+      account_id
+    from
+      aws_s3_bucket
+    order by
+      name;
+  EOQ
+}
@@ -0,0 +1,13 @@
+-- Fixture data for the S3 controls. The temporary table shadows the aws_s3_bucket table for the test session.
+create temporary table aws_s3_bucket (
+  name text,
+  arn text,
+  region text,
+  account_id text,
+  versioning_enabled boolean,
+  tags jsonb
+);
+
+insert into aws_s3_bucket values
+  ('example-logs', 'arn:aws:s3:::example-logs', 'us-east-1', '123456789012', true, '{"owner": "platform"}'),
+  ('example-scratch', 'arn:aws:s3:::example-scratch', 'us-east-1', '123456789012', false, '{}');
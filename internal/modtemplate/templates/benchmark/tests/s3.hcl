@@ -0,0 +1,26 @@
+test "s3_bucket_versioning_enabled" {
+  title    = "Buckets without versioning are in alarm"
+  control  = "control.s3_bucket_versioning_enabled"
+  fixtures = ["fixtures/aws_s3_bucket.sql"]
+
+  expect {
+    resource = "arn:aws:s3:::example-logs"
+    status   = "ok"
+  }
+
+  expect {
+    resource = "arn:aws:s3:::example-scratch"
+    status   = "alarm"
+  }
+}
+
+test "s3_bucket_tagged" {
+  title    = "Buckets without an owner tag are in alarm"
+  control  = "control.s3_bucket_tagged"
+  fixtures = ["fixtures/aws_s3_bucket.sql"]
+
+  expect {
+    resource = "arn:aws:s3:::example-scratch"
+    status   = "alarm"
+  }
+}
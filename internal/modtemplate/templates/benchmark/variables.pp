@@ -0,0 +1,13 @@
+variable "common_tags" {
+  type        = map(string)
+  description = "A map of tags to add to all benchmarks and controls."
+  default = {
+    service = "AWS/S3"
+  }
+}
+
+locals {
+  common_tags = merge(var.common_tags, {
+    plugin = "aws"
+  })
+}
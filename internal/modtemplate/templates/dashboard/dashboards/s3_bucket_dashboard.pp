@@ -0,0 +1,43 @@
+dashboard "s3_bucket_dashboard" {
+  title = "S3 Bucket Dashboard"
+
+  tags = {
+    service = "AWS/S3"
+    type    = "Dashboard"
+  }
+
+  input "region" {
+    title = "Select a region:"
+    query = query.s3_bucket_region_input
+    width = 4
+  }
+
+  container {
+    card {
+      query = query.s3_bucket_count
+      width = 3
+      args = {
+        region = self.input.region.value
+      }
+    }
+
+    card {
+      query = query.s3_bucket_versioning_disabled_count
+      width = 3
+      args = {
+        region = self.input.region.value
+      }
+    }
+  }
+
+  container {
+    table {
+      title = "Buckets"
+      query = query.s3_bucket_table
+      args = {
+        region   = self.input.region.value
+        age_days = var.bucket_age_warning_days
+      }
+    }
+  }
+}
@@ -0,0 +1,68 @@
+query "s3_bucket_region_input" {
+  sql = <<-EOQ
+    select distinct
+      region as label,
+      region as value
+    from
+      aws_s3_bucket
+    order by
+      region;
+  EOQ
+}
+
+query "s3_bucket_count" {
+  sql = <<-EOQ
+    select
+      count(*) as "Buckets"
+    from
+      aws_s3_bucket
+    where
+      region = $1;
+  EOQ
+
+  param "region" {
+    default = "us-east-1"
+  }
+}
+
+query "s3_bucket_versioning_disabled_count" {
+  sql = <<-EOQ
+    select
+      count(*) as value,
+      'Versioning Disabled' as label,
+      case count(*) when 0 then 'ok' else 'alert' end as type
+    from
+      aws_s3_bucket
+    where
+      region = $1
+      and not versioning_enabled;
+  EOQ
+
+  param "region" {
+    default = "us-east-1"
+  }
+}
+
+query "s3_bucket_table" {
+  sql = <<-EOQ
+    select
+      name as "Name",
+      versioning_enabled as "Versioning Enabled",
+      creation_date as "Created",
+      creation_date < now() - ($2 || ' days')::interval as "Older Than Warning Age"
+    from
+      aws_s3_bucket
+    where
+      region = $1
+    order by
+      name;
+  EOQ
+
+  param "region" {
+    default = "us-east-1"
+  }
+
+  param "age_days" {
+    default = 365
+  }
+}
@@ -0,0 +1,12 @@
+-- Fixture data for the dashboard queries. The temporary table shadows the aws_s3_bucket table for the test session.
+create temporary table aws_s3_bucket (
+  name text,
+  region text,
+  versioning_enabled boolean,
+  creation_date timestamptz
+);
+
+insert into aws_s3_bucket values
+  ('example-logs', 'us-east-1', true, '2020-01-01'),
+  ('example-scratch', 'us-east-1', false, '2020-01-01'),
+  ('example-backups', 'eu-west-1', true, '2020-01-01');
@@ -0,0 +1,12 @@
+test "s3_bucket_count" {
+  title    = "Buckets are counted by region"
+  query    = "query.s3_bucket_count"
+  fixtures = ["fixtures/aws_s3_bucket.sql"]
+  args = {
+    region = "us-east-1"
+  }
+
+  expect {
+    Buckets = 2
+  }
+}
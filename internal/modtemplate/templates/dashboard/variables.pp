@@ -0,0 +1,5 @@
+variable "bucket_age_warning_days" {
+  type        = number
+  description = "Buckets created more than this many days ago are highlighted."
+  default     = 365
+}
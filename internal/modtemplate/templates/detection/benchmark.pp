@@ -0,0 +1,10 @@
+benchmark "cloudtrail_detections" {
+  title       = "CloudTrail Detections"
+  description = "Example detections of suspicious activity in AWS CloudTrail events."
+  children = [
+    control.root_account_used,
+    control.console_login_failed
+  ]
+
+  tags = local.detection_tags
+}
@@ -0,0 +1,21 @@
+control "root_account_used" {
+  title       = "Root account activity"
+  description = "Detect any use of the root account, which should be reserved for tasks which require it."
+  query       = query.root_account_used
+  severity    = "critical"
+
+  tags = merge(local.detection_tags, {
+    mitre_attack = "T1078.004"
+  })
+}
+
+control "console_login_failed" {
+  title       = "Failed console logins"
+  description = "Detect failed console logins, which may indicate attempts to guess credentials."
+  query       = query.console_login_failed
+  severity    = "high"
+
+  tags = merge(local.detection_tags, {
+    mitre_attack = "T1110"
+  })
+}
@@ -0,0 +1,78 @@
+query "root_account_used" {
+  sql = <<-EOQ
+    with events as (
+      select
+        event_id,
+        event_name,
+        source_ip_address,
+        timestamp
+      from
+        aws_cloudtrail_trail_event
+      where
+        log_group_name = $1
+        and timestamp > now() - $2::interval
+        and user_type = 'Root'
+    )
+    select
+      event_id as resource,
+      'alarm' as status,
+      'Root account ' || event_name || ' from ' || source_ip_address || ' at ' || timestamp || '.' as reason
+    from
+      events
+    union all
+    select
+      $1 as resource,
+      'ok' as status,
+      'No root account activity in the last ' || $2 || '.' as reason
+    where
+      not exists (select 1 from events);
+  EOQ
+
+  param "log_group_name" {
+    default = var.cloudtrail_log_group_name
+  }
+
+  param "window" {
+    default = var.detection_window
+  }
+}
+
+query "console_login_failed" {
+  sql = <<-EOQ
+    with events as (
+      select
+        event_id,
+        username,
+        source_ip_address,
+        timestamp
+      from
+        aws_cloudtrail_trail_event
+      where
+        log_group_name = $1
+        and timestamp > now() - $2::interval
+        and event_name = 'ConsoleLogin'
+        and error_code is not null
+    )
+    select
+      event_id as resource,
+      'alarm' as status,
+      'Failed console login for ' || username || ' from ' || source_ip_address || ' at ' || timestamp || '.' as reason
+    from
+      events
+    union all
+    select
+      $1 as resource,
+      'ok' as status,
+      'No failed console logins in the last ' || $2 || '.' as reason
+    where
+      not exists (select 1 from events);
+  EOQ
+
+  param "log_group_name" {
+    default = var.cloudtrail_log_group_name
+  }
+
+  param "window" {
+    default = var.detection_window
+  }
+}
@@ -0,0 +1,22 @@
+test "root_account_used" {
+  title    = "Root account logins are detected"
+  control  = "control.root_account_used"
+  fixtures = ["fixtures/aws_cloudtrail_trail_event.sql"]
+
+  expect {
+    resource = "event-1"
+    status   = "alarm"
+  }
+}
+
+test "console_login_failed" {
+  title    = "Only failed console logins are detected"
+  control  = "control.console_login_failed"
+  fixtures = ["fixtures/aws_cloudtrail_trail_event.sql"]
+  rows     = 1
+
+  expect {
+    resource = "event-2"
+    status   = "alarm"
+  }
+}
@@ -0,0 +1,17 @@
+-- Fixture data for the CloudTrail detections. The temporary table shadows the aws_cloudtrail_trail_event table for
+-- the test session.
+create temporary table aws_cloudtrail_trail_event (
+  log_group_name text,
+  event_id text,
+  event_name text,
+  user_type text,
+  username text,
+  source_ip_address text,
+  error_code text,
+  timestamp timestamptz
+);
+
+insert into aws_cloudtrail_trail_event values
+  ('aws-cloudtrail-logs', 'event-1', 'ConsoleLogin', 'Root', 'root', '203.0.113.10', null, now() - interval '1 hour'),
+  ('aws-cloudtrail-logs', 'event-2', 'ConsoleLogin', 'IAMUser', 'alice', '203.0.113.20', 'Failed authentication', now() - interval '2 hours'),
+  ('aws-cloudtrail-logs', 'event-3', 'ConsoleLogin', 'IAMUser', 'bob', '203.0.113.30', null, now() - interval '3 hours');
@@ -0,0 +1,18 @@
+variable "cloudtrail_log_group_name" {
+  type        = string
+  description = "The CloudWatch log group which CloudTrail events are delivered to."
+  default     = "aws-cloudtrail-logs"
+}
+
+variable "detection_window" {
+  type        = string
+  description = "How far back to search for events, as a Postgres interval."
+  default     = "1 day"
+}
+
+locals {
+  detection_tags = {
+    plugin = "aws"
+    type   = "detection"
+  }
+}
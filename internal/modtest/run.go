@@ -0,0 +1,275 @@
+package modtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/workspace"
+	"github.com/turbot/powerpipe/internal/db_client"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// file based database connection string prefixes - relative paths are resolved relative to the test file
+var fileDatabasePrefixes = []string{"sqlite:", "duckdb:"}
+
+// Result is the result of running a test
+type Result struct {
+	Name   string `json:"name"`
+	Title  string `json:"title,omitempty"`
+	Target string `json:"target"`
+	Passed bool   `json:"passed"`
+	// the reasons the test failed
+	Failures []string      `json:"failures,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Runner runs tests against the resources of a workspace
+type Runner struct {
+	workspace        *workspace.Workspace
+	defaultDatabase  string
+	searchPathConfig backend.SearchPathConfig
+	clients          *db_client.ClientMap
+}
+
+// NewRunner returns a runner which runs tests against the given workspace, using the default database for tests
+// which do not set a database
+func NewRunner(w *workspace.Workspace, defaultDatabase string, searchPathConfig backend.SearchPathConfig) *Runner {
+	return &Runner{
+		workspace:        w,
+		defaultDatabase:  defaultDatabase,
+		searchPathConfig: searchPathConfig,
+		clients:          db_client.NewClientMap(),
+	}
+}
+
+// Close closes the database clients opened by the runner
+func (r *Runner) Close(ctx context.Context) error {
+	return r.clients.Close(ctx)
+}
+
+// Run runs the test - failures of the test, including errors running it, are returned in the result
+func (r *Runner) Run(ctx context.Context, t *Test) *Result {
+	start := time.Now()
+	res := &Result{Name: t.Name, Title: t.Title, Target: t.Target()}
+	if err := r.run(ctx, t, res); err != nil {
+		res.Failures = append(res.Failures, err.Error())
+	}
+	res.Passed = len(res.Failures) == 0
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (r *Runner) run(ctx context.Context, t *Test, res *Result) error {
+	queryProvider, err := r.resolveTarget(t)
+	if err != nil {
+		return err
+	}
+	args, err := testArgs(t.Args)
+	if err != nil {
+		return err
+	}
+	resolvedQuery, err := r.workspace.ResolveQueryFromQueryProvider(queryProvider, args)
+	if err != nil {
+		return fmt.Errorf("failed to resolve query: %w", err)
+	}
+
+	var setup []string
+	for _, fixture := range t.Fixtures {
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(t.FilePath), fixture))
+		if err != nil {
+			return fmt.Errorf("failed to read fixture: %w", err)
+		}
+		setup = append(setup, string(content))
+	}
+
+	database, searchPathConfig := r.database(t)
+	client, err := r.clients.GetOrCreate(ctx, database, searchPathConfig)
+	if err != nil {
+		return err
+	}
+	result, err := client.ExecuteSyncInTransaction(ctx, setup, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	if err != nil {
+		return err
+	}
+
+	res.Failures = assert(t, result)
+	return nil
+}
+
+func (r *Runner) resolveTarget(t *Test) (modconfig.QueryProvider, error) {
+	parsedName, err := modconfig.ParseResourceName(t.Target())
+	if err != nil {
+		return nil, err
+	}
+	expectedType := "query"
+	if t.Control != "" {
+		expectedType = "control"
+	}
+	if parsedName.ItemType != expectedType {
+		return nil, fmt.Errorf("'%s' is not a %s", t.Target(), expectedType)
+	}
+	resource, found := r.workspace.GetResource(parsedName)
+	if !found {
+		return nil, fmt.Errorf("%s not found", t.Target())
+	}
+	queryProvider, ok := resource.(modconfig.QueryProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be run", t.Target())
+	}
+	return queryProvider, nil
+}
+
+// database returns the connection string and search path of the database to run the test against
+func (r *Runner) database(t *Test) (string, backend.SearchPathConfig) {
+	if t.Database == "" {
+		return r.defaultDatabase, r.searchPathConfig
+	}
+	for _, prefix := range fileDatabasePrefixes {
+		if path, ok := strings.CutPrefix(t.Database, prefix); ok && path != "" && !filepath.IsAbs(path) {
+			return prefix + filepath.Join(filepath.Dir(t.FilePath), path), backend.SearchPathConfig{}
+		}
+	}
+	return t.Database, backend.SearchPathConfig{}
+}
+
+// testArgs converts the test args to query args
+func testArgs(values map[string]cty.Value) (*modconfig.QueryArgs, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	args := modconfig.NewQueryArgs()
+	for name, val := range values {
+		var err error
+		if val.Type() == cty.String {
+			err = args.SetNamedArgVal(name, val.AsString())
+		} else {
+			var jsonVal string
+			if jsonVal, err = hclhelpers.CtyToJSON(val); err == nil {
+				err = args.SetNamedArgVal(name, json.RawMessage(jsonVal))
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid arg '%s': %w", name, err)
+		}
+	}
+	return args, nil
+}
+
+// assert checks the result against the expectations of the test, returning the failures
+func assert(t *Test, result *localqueryresult.SyncQueryResult) []string {
+	var failures []string
+
+	columns := make(map[string]int, len(result.Cols))
+	for i, c := range result.Cols {
+		columns[c.Name] = i
+	}
+	var rows [][]any
+	for _, r := range result.Rows {
+		if row, ok := r.(*localqueryresult.RowResult); ok {
+			rows = append(rows, row.Data)
+		}
+	}
+
+	if t.Rows != nil && len(rows) != *t.Rows {
+		failures = append(failures, fmt.Sprintf("expected %d rows, got %d", *t.Rows, len(rows)))
+	}
+
+	matched := make([]bool, len(rows))
+	for _, e := range t.Expect {
+		var missingColumns []string
+		for column := range e.Values {
+			if _, ok := columns[column]; !ok {
+				missingColumns = append(missingColumns, column)
+			}
+		}
+		if len(missingColumns) > 0 {
+			failures = append(failures, fmt.Sprintf("expected row %s: result has no column %s", e, strings.Join(missingColumns, ", ")))
+			continue
+		}
+
+		found := false
+		for i, row := range rows {
+			if !matched[i] && rowMatches(row, columns, e) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("expected row %s: no matching row", e))
+		}
+	}
+	return failures
+}
+
+func rowMatches(row []any, columns map[string]int, e *Expectation) bool {
+	for column, expected := range e.Values {
+		if !valueMatches(row[columns[column]], expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueMatches compares a result value with an expected value - numbers are compared numerically, and other values
+// by their string form
+func valueMatches(actual any, expected cty.Value) bool {
+	if expected.IsNull() || actual == nil {
+		return expected.IsNull() && actual == nil
+	}
+	actualString := fmt.Sprintf("%v", actual)
+	switch expected.Type() {
+	case cty.Number:
+		actualNumber, ok := new(big.Float).SetString(actualString)
+		return ok && actualNumber.Cmp(expected.AsBigFloat()) == 0
+	case cty.Bool:
+		return actualString == fmt.Sprintf("%v", expected.True())
+	case cty.String:
+		return actualString == expected.AsString()
+	}
+	// compare complex values (e.g. json columns) as JSON
+	expectedJSON, err := hclhelpers.CtyToJSON(expected)
+	if err != nil {
+		return false
+	}
+	actualJSON, err := json.Marshal(actual)
+	return err == nil && jsonEqual(actualJSON, []byte(expectedJSON))
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+func ctyDisplayString(v cty.Value) string {
+	if v.IsNull() {
+		return "null"
+	}
+	switch v.Type() {
+	case cty.String:
+		return fmt.Sprintf("%q", v.AsString())
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case cty.Bool:
+		return fmt.Sprintf("%v", v.True())
+	}
+	if s, err := hclhelpers.CtyToJSON(v); err == nil {
+		return s
+	}
+	return v.GoString()
+}
@@ -0,0 +1,76 @@
+package modtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+)
+
+func TestAssert(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+test "versioning" {
+  control = "control.versioning"
+  rows    = 2
+
+  expect {
+    resource = "arn:aws:s3:::a"
+    status   = "ok"
+    count    = 1
+  }
+  expect {
+    status = "alarm"
+  }
+}
+`
+	if err := os.MkdirAll(filepath.Join(dir, TestDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, TestDir, "versioning.hcl"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests, err := LoadTests(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 1 {
+		t.Fatalf("expected 1 test, got %d", len(tests))
+	}
+
+	cols := []*queryresult.ColumnDef{{Name: "resource"}, {Name: "status"}, {Name: "count"}}
+	cases := map[string]struct {
+		rows     [][]any
+		failures int
+	}{
+		"pass": {
+			rows:     [][]any{{"arn:aws:s3:::b", "alarm", int64(0)}, {"arn:aws:s3:::a", "ok", int64(1)}},
+			failures: 0,
+		},
+		"numeric comparison": {
+			rows:     [][]any{{"arn:aws:s3:::a", "ok", 1.0}, {"arn:aws:s3:::b", "alarm", nil}},
+			failures: 0,
+		},
+		"no matching row": {
+			rows:     [][]any{{"arn:aws:s3:::a", "ok", int64(1)}, {"arn:aws:s3:::b", "ok", int64(1)}},
+			failures: 1,
+		},
+		"row count": {
+			rows:     [][]any{{"arn:aws:s3:::a", "ok", int64(1)}},
+			failures: 2,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result := &localqueryresult.SyncQueryResult{Cols: cols}
+			for _, row := range tc.rows {
+				result.Rows = append(result.Rows, &localqueryresult.RowResult{Data: row})
+			}
+			if failures := assert(tests[0], result); len(failures) != tc.failures {
+				t.Errorf("expected %d failures, got %v", tc.failures, failures)
+			}
+		})
+	}
+}
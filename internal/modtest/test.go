@@ -0,0 +1,180 @@
+// Package modtest loads and runs mod tests.
+//
+// Tests are defined in test blocks, in .hcl files in the tests directory of the mod:
+//
+//	test "s3_bucket_versioning_enabled" {
+//	  title    = "Buckets without versioning are in alarm"
+//	  control  = "control.s3_bucket_versioning_enabled"
+//	  fixtures = ["fixtures/aws_s3_bucket.sql"]
+//	  rows     = 2
+//
+//	  expect {
+//	    resource = "arn:aws:s3:::example-scratch"
+//	    status   = "alarm"
+//	  }
+//	}
+//
+// A test runs a control or query, optionally with args, and asserts the result:
+//   - each expect block must match a different row of the result, by the values of the columns it sets
+//   - rows, if set, is the number of rows the result must have
+//
+// The fixtures are SQL files (relative to the test file) which are executed in the same transaction as the control
+// or query, before it, to seed the data it runs against - e.g. by creating temporary tables which shadow the tables
+// the query reads. The transaction is rolled back after the test. A test may set database to run against a
+// different database from the default, e.g. a SQLite or DuckDB database file committed with the tests.
+package modtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TestDir is the directory, relative to the mod location, which contains the test files
+const TestDir = "tests"
+
+// the extension of test files
+const testFileExtension = ".hcl"
+
+// Test is a test of a control or query
+type Test struct {
+	Name     string               `hcl:"name,label"`
+	Title    string               `hcl:"title,optional"`
+	Control  string               `hcl:"control,optional"`
+	Query    string               `hcl:"query,optional"`
+	Database string               `hcl:"database,optional"`
+	Fixtures []string             `hcl:"fixtures,optional"`
+	Args     map[string]cty.Value `hcl:"args,optional"`
+	Rows     *int                 `hcl:"rows,optional"`
+	Expect   []*Expectation       `hcl:"expect,block"`
+
+	// the path of the file the test is defined in
+	FilePath string
+	DefRange hcl.Range
+}
+
+// Target returns the name of the control or query the test runs
+func (t *Test) Target() string {
+	if t.Control != "" {
+		return t.Control
+	}
+	return t.Query
+}
+
+// Expectation is a row the result must contain - the column values are the attributes of the expect block
+type Expectation struct {
+	Body hcl.Body `hcl:",remain"`
+
+	// the expected column values
+	Values map[string]cty.Value
+}
+
+// String returns the expected values in HCL form, e.g. { resource = "arn", status = "ok" }
+func (e *Expectation) String() string {
+	columns := make([]string, 0, len(e.Values))
+	for column := range e.Values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = fmt.Sprintf("%s = %s", column, ctyDisplayString(e.Values[column]))
+	}
+	return "{ " + strings.Join(values, ", ") + " }"
+}
+
+type testFile struct {
+	Tests []*Test `hcl:"test,block"`
+}
+
+// LoadTests loads the tests from the test files in the tests directory of the mod, in name order
+func LoadTests(modPath string) ([]*Test, error) {
+	testPath := filepath.Join(modPath, TestDir)
+	paths, err := filehelpers.ListFiles(testPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesRecursive,
+		Include: filehelpers.InclusionsFromExtensions([]string{testFileExtension}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	var res []*Test
+	var diags hcl.Diagnostics
+	names := make(map[string]string)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, moreDiags := parser.ParseHCL(content, path)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		var f testFile
+		if moreDiags := gohcl.DecodeBody(file.Body, nil, &f); moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+		blocks, _, _ := file.Body.PartialContent(&hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "test", LabelNames: []string{"name"}}}})
+		for i, t := range f.Tests {
+			t.FilePath = path
+			t.DefRange = blocks.Blocks[i].DefRange
+			diags = append(diags, t.validate()...)
+			if existing, ok := names[t.Name]; ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("duplicate test '%s' - also defined in %s", t.Name, existing),
+					Subject:  &t.DefRange,
+				})
+			}
+			names[t.Name] = path
+			res = append(res, t)
+		}
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to load tests: %s", diags.Error())
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// validate checks the test has exactly one target and decodes the expected values
+func (t *Test) validate() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if (t.Control == "") == (t.Query == "") {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("test '%s' must set exactly one of control or query", t.Name),
+			Subject:  &t.DefRange,
+		})
+	}
+	if len(t.Expect) == 0 && t.Rows == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("test '%s' has no assertions - it must have an expect block or set rows", t.Name),
+			Subject:  &t.DefRange,
+		})
+	}
+	for _, e := range t.Expect {
+		attrs, moreDiags := e.Body.JustAttributes()
+		diags = append(diags, moreDiags...)
+		e.Values = make(map[string]cty.Value, len(attrs))
+		for name, attr := range attrs {
+			val, moreDiags := attr.Expr.Value(nil)
+			diags = append(diags, moreDiags...)
+			e.Values[name] = val
+		}
+	}
+	return diags
+}
@@ -0,0 +1,135 @@
+// Package modvendor copies the installed dependencies of a mod into a vendor directory, which can be committed
+// with the mod, and restores them from it - this allows dependencies to be installed without Git access.
+package modvendor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+// VendorDir is the name of the vendor directory, relative to the workspace data directory
+// (the vendor directory is inside the hidden data directory so the vendored mods are not loaded as part of the workspace mod)
+const VendorDir = "vendor"
+
+// VendorPath returns the path of the vendor directory for the workspace
+func VendorPath(workspacePath string) string {
+	return filepath.Join(workspacePath, app_specific.WorkspaceDataDir, VendorDir)
+}
+
+// Vendor copies the installed dependencies of the workspace into the vendor directory, replacing any previously
+// vendored dependencies. It returns the names of the vendored mods.
+func Vendor(workspacePath string) ([]string, error) {
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mod lock file: %w", err)
+	}
+	if lock.Empty() {
+		return nil, nil
+	}
+	if missing := lock.MissingVersions; len(missing) > 0 {
+		return nil, fmt.Errorf("%s are not installed - run 'powerpipe mod install' before vendoring", strings.Join(modNames(missing), ", "))
+	}
+
+	vendorPath := VendorPath(workspacePath)
+	if err := os.RemoveAll(vendorPath); err != nil {
+		return nil, err
+	}
+	if err := copyMods(filepaths.WorkspaceModPath(workspacePath), vendorPath); err != nil {
+		return nil, err
+	}
+	return modNames(lock.InstallCache), nil
+}
+
+// Restore installs the dependencies of the workspace from the vendor directory, replacing any installed dependencies
+// It returns an error if the vendored mods do not include all the dependencies in the lock file.
+func Restore(workspacePath string) ([]string, error) {
+	vendorPath := VendorPath(workspacePath)
+	if !filehelpers.DirectoryExists(vendorPath) {
+		return nil, fmt.Errorf("vendor directory %s does not exist - run 'powerpipe mod vendor' to create it", vendorPath)
+	}
+
+	modPath := filepaths.WorkspaceModPath(workspacePath)
+	if err := os.RemoveAll(modPath); err != nil {
+		return nil, err
+	}
+	if err := copyMods(vendorPath, modPath); err != nil {
+		return nil, err
+	}
+
+	// verify all the dependencies in the lock file are now installed
+	lock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mod lock file: %w", err)
+	}
+	if missing := lock.MissingVersions; len(missing) > 0 {
+		return nil, fmt.Errorf("the vendor directory does not contain %s - run 'powerpipe mod vendor' to update it", strings.Join(modNames(missing), ", "))
+	}
+	return modNames(lock.InstallCache), nil
+}
+
+// copyMods copies the mod installation directory, excluding any Git metadata
+func copyMods(src, dest string) error {
+	if !filehelpers.DirectoryExists(src) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return utils.CopyFile(path, target)
+	})
+}
+
+// modNames returns the sorted names and versions of the mods in the map
+func modNames(versions versionmap.InstalledDependencyVersionsMap) []string {
+	var res []string
+	for _, deps := range versions {
+		for _, dep := range deps {
+			// local filepath dependencies are not installed so are not vendored
+			if dep.FilePath != "" {
+				continue
+			}
+			res = append(res, fmt.Sprintf("%s@%s", dep.Name, versionString(dep.DependencyVersion)))
+		}
+	}
+	res = helpers.StringSliceDistinct(res)
+	sort.Strings(res)
+	return res
+}
+
+func versionString(v modconfig.DependencyVersion) string {
+	switch {
+	case v.Version != nil:
+		return v.Version.String()
+	case v.Tag != "":
+		return v.Tag
+	default:
+		return v.Branch
+	}
+}
@@ -0,0 +1,129 @@
+package modvendor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/filepaths"
+)
+
+const testLockFile = `{
+  "local": {
+    "github.com/turbot/dep": {
+      "name": "github.com/turbot/dep",
+      "version": "1.0.0"
+    }
+  }
+}`
+
+const testDependencyModFile = `mod "dep" {
+}
+`
+
+// newTestWorkspace creates a workspace with a lock file and the files of the given installed dependency
+func newTestWorkspace(t *testing.T, files map[string]string) string {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.WorkspaceDataDir = ".powerpipe"
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepaths.WorkspaceLockPath(dir), []byte(testLockFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(filepaths.WorkspaceModPath(dir), name), content)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVendor(t *testing.T) {
+	ws := newTestWorkspace(t, map[string]string{
+		"github.com/turbot/dep@v1.0.0/mod.pp":              testDependencyModFile,
+		"github.com/turbot/dep@v1.0.0/queries/buckets.pp":  `query "buckets" { sql = "select 1" }`,
+		"github.com/turbot/dep@v1.0.0/.git/HEAD":           "ref: refs/heads/main",
+		"github.com/turbot/dep@v1.0.0/.git/refs/heads/foo": "abc123",
+	})
+	// a previously vendored mod which is no longer installed should be removed
+	writeFile(t, filepath.Join(VendorPath(ws), "github.com/turbot/old@v0.1.0/mod.pp"), `mod "old" {}`)
+
+	got, err := Vendor(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"github.com/turbot/dep@1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Vendor() = %v, want %v", got, want)
+	}
+
+	var files []string
+	err = filepath.WalkDir(VendorPath(ws), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(VendorPath(ws), path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"github.com/turbot/dep@v1.0.0/mod.pp",
+		"github.com/turbot/dep@v1.0.0/queries/buckets.pp",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("vendored files = %v, want %v", files, want)
+	}
+	content, err := os.ReadFile(filepath.Join(VendorPath(ws), "github.com/turbot/dep@v1.0.0/mod.pp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testDependencyModFile {
+		t.Errorf("vendored mod.pp = %q, want %q", content, testDependencyModFile)
+	}
+}
+
+func TestVendorMissingDependency(t *testing.T) {
+	ws := newTestWorkspace(t, nil)
+
+	if _, err := Vendor(ws); err == nil {
+		t.Fatal("expected an error vendoring a workspace whose dependencies are not installed")
+	}
+	if _, err := os.Stat(VendorPath(ws)); !os.IsNotExist(err) {
+		t.Errorf("expected the vendor directory not to be created, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ws := newTestWorkspace(t, map[string]string{
+		"github.com/turbot/dep@v1.0.0/mod.pp": testDependencyModFile,
+	})
+	if _, err := Vendor(ws); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepaths.WorkspaceModPath(ws)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Restore(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"github.com/turbot/dep@1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Restore() = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(filepaths.WorkspaceModPath(ws), "github.com/turbot/dep@v1.0.0/mod.pp")); err != nil {
+		t.Errorf("expected the dependency to be restored: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+const (
+	envAzureStorageAccount  = "AZURE_STORAGE_ACCOUNT"
+	envAzureStorageKey      = "AZURE_STORAGE_KEY"
+	envAzureStorageSASToken = "AZURE_STORAGE_SAS_TOKEN"
+	// overrides the blob service endpoint, e.g. for Azurite
+	envAzureStorageBlobEndpoint = "AZURE_STORAGE_BLOB_ENDPOINT"
+)
+
+func putAzure(ctx context.Context, l *Location, key string, data []byte, contentType string) error {
+	client, err := newAzureClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.UploadBuffer(ctx, l.Bucket, key, data, &azblob.UploadBufferOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+	})
+	return err
+}
+
+// newAzureClient creates a Blob service client for the configured storage account, using either the account key or
+// a SAS token
+func newAzureClient() (*azblob.Client, error) {
+	account := os.Getenv(envAzureStorageAccount)
+	if account == "" {
+		return nil, fmt.Errorf("%s must be set to write to Azure Blob Storage", envAzureStorageAccount)
+	}
+	accountKey, sasToken := os.Getenv(envAzureStorageKey), strings.TrimPrefix(os.Getenv(envAzureStorageSASToken), "?")
+	if accountKey == "" && sasToken == "" {
+		return nil, fmt.Errorf("either %s or %s must be set to write to Azure Blob Storage", envAzureStorageKey, envAzureStorageSASToken)
+	}
+	serviceURL := os.Getenv(envAzureStorageBlobEndpoint)
+	if serviceURL == "" {
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	serviceURL = strings.TrimSuffix(serviceURL, "/") + "/"
+
+	if sasToken != "" {
+		return azblob.NewClientWithNoCredential(serviceURL+"?"+sasToken, nil)
+	}
+	credential, err := azblob.NewSharedKeyCredential(account, accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", envAzureStorageKey, err)
+	}
+	return azblob.NewClientWithSharedKeyCredential(serviceURL, credential, nil)
+}
@@ -0,0 +1,24 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+)
+
+func putGCS(ctx context.Context, l *Location, key string, data []byte, contentType string) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Cloud Storage client: %w", err)
+	}
+	defer client.Close()
+
+	w := client.Bucket(l.Bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
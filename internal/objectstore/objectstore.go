@@ -0,0 +1,101 @@
+// Package objectstore writes files to object storage, so snapshots can be archived centrally (e.g. from ephemeral
+// CI runners) without depending on Turbot Pipes.
+//
+// An object storage location is a URL of the form <scheme>://<bucket>[/<prefix>], where the scheme is one of:
+//
+//	s3      Amazon S3, using the AWS config and credentials chain, e.g. s3://compliance-evidence/powerpipe
+//	        (the region is taken from the region query parameter, e.g. s3://evidence?region=eu-west-1, or the AWS config)
+//	gs      Google Cloud Storage, using the application default credentials, e.g. gs://compliance-evidence
+//	azblob  Azure Blob Storage, where the bucket is the container, using the AZURE_STORAGE_ACCOUNT account and either
+//	        AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN, e.g. azblob://evidence/powerpipe
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	SchemeS3    = "s3"
+	SchemeGCS   = "gs"
+	SchemeAzure = "azblob"
+)
+
+// Location is a parsed object storage location
+type Location struct {
+	Scheme string
+	Bucket string
+	Prefix string
+	Query  url.Values
+}
+
+// IsURL returns whether the value is an object storage URL
+func IsURL(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return false
+	}
+	switch scheme {
+	case SchemeS3, SchemeGCS, SchemeAzure:
+		return true
+	}
+	return false
+}
+
+// Parse parses an object storage URL
+func Parse(value string) (*Location, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object storage location '%s': %w", value, err)
+	}
+	if !IsURL(value) || u.Host == "" {
+		return nil, fmt.Errorf("invalid object storage location '%s' - expected s3://, gs:// or azblob://<bucket>[/<prefix>]", value)
+	}
+	return &Location{
+		Scheme: u.Scheme,
+		Bucket: u.Host,
+		Prefix: strings.Trim(u.Path, "/"),
+		Query:  u.Query(),
+	}, nil
+}
+
+// Key returns the object key of a file in the location
+func (l *Location) Key(name string) string {
+	return path.Join(l.Prefix, name)
+}
+
+// URL returns the URL of an object in the location
+func (l *Location) URL(key string) string {
+	return fmt.Sprintf("%s://%s/%s", l.Scheme, l.Bucket, key)
+}
+
+func (l *Location) String() string {
+	if l.Prefix == "" {
+		return fmt.Sprintf("%s://%s", l.Scheme, l.Bucket)
+	}
+	return l.URL(l.Prefix)
+}
+
+// Put writes a file to the object storage location, returning the URL of the object
+func Put(ctx context.Context, location, name string, data []byte, contentType string) (string, error) {
+	l, err := Parse(location)
+	if err != nil {
+		return "", err
+	}
+	key := l.Key(name)
+	switch l.Scheme {
+	case SchemeS3:
+		err = putS3(ctx, l, key, data, contentType)
+	case SchemeGCS:
+		err = putGCS(ctx, l, key, data, contentType)
+	case SchemeAzure:
+		err = putAzure(ctx, l, key, data, contentType)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", l.URL(key), err)
+	}
+	return l.URL(key), nil
+}
@@ -0,0 +1,139 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]Location{
+		"s3://evidence": {Scheme: SchemeS3, Bucket: "evidence"},
+		"s3://evidence/powerpipe/ci/?region=eu-west-1": {Scheme: SchemeS3, Bucket: "evidence", Prefix: "powerpipe/ci"},
+		"gs://evidence/powerpipe":                      {Scheme: SchemeGCS, Bucket: "evidence", Prefix: "powerpipe"},
+		"azblob://evidence":                            {Scheme: SchemeAzure, Bucket: "evidence"},
+	}
+	for value, expected := range tests {
+		l, err := Parse(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Scheme != expected.Scheme || l.Bucket != expected.Bucket || l.Prefix != expected.Prefix {
+			t.Errorf("Parse(%s) = %+v, expected %+v", value, l, expected)
+		}
+	}
+	if l, _ := Parse("s3://evidence/ci?region=eu-west-1"); l.Query.Get("region") != "eu-west-1" || l.Key("a.pps") != "ci/a.pps" {
+		t.Errorf("unexpected location %+v", l)
+	}
+
+	for _, value := range []string{"/tmp/snapshots", "acme/prod", "s3:///prefix", "ftp://host/path"} {
+		if _, err := Parse(value); err == nil {
+			t.Errorf("expected an error for '%s'", value)
+		}
+	}
+}
+
+func TestPutS3(t *testing.T) {
+	var gotPath, gotBody, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody, gotContentType = r.URL.Path, string(body), r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv(envAWSS3Endpoint, server.URL)
+
+	url, err := Put(context.Background(), "s3://evidence/ci?region=eu-west-1", "a.pps", []byte(`{"a":1}`), "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "s3://evidence/ci/a.pps" || gotPath != "/evidence/ci/a.pps" || gotBody != `{"a":1}` || gotContentType != "application/json" {
+		t.Errorf("unexpected request %s %s %s %s", url, gotPath, gotBody, gotContentType)
+	}
+
+	_, err = Put(context.Background(), "s3://evidence?region=us-east-1", "a.pps", nil, "application/json")
+	if err == nil || !strings.Contains(err.Error(), "Access Denied") {
+		t.Errorf("expected an access denied error, got %v", err)
+	}
+
+	_, err = Put(context.Background(), "s3://evidence", "a.pps", nil, "application/json")
+	if err == nil || !strings.Contains(err.Error(), "region") {
+		t.Errorf("expected a missing region error, got %v", err)
+	}
+}
+
+func TestPutAzure(t *testing.T) {
+	tests := map[string]struct {
+		accountKey string
+		sasToken   string
+		wantAuth   string
+		wantQuery  string
+		wantErr    string
+	}{
+		"shared key": {
+			accountKey: "a2V5",
+			wantAuth:   "SharedKey devstoreaccount1:",
+		},
+		"sas token": {
+			sasToken:  "?sv=2021-08-06&sig=abc",
+			wantQuery: "sig=abc",
+		},
+		"no credentials": {
+			wantErr: envAzureStorageSASToken,
+		},
+		"invalid account key": {
+			accountKey: "not base64!",
+			wantErr:    envAzureStorageKey,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPath, gotAuth, gotQuery, gotContentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth, gotQuery, gotContentType = r.URL.Path, r.Header.Get("Authorization"), r.URL.RawQuery, r.Header.Get("X-Ms-Blob-Content-Type")
+				if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			t.Setenv(envAzureStorageAccount, "devstoreaccount1")
+			t.Setenv(envAzureStorageKey, tc.accountKey)
+			t.Setenv(envAzureStorageSASToken, tc.sasToken)
+			t.Setenv(envAzureStorageBlobEndpoint, server.URL)
+
+			url, err := Put(context.Background(), "azblob://evidence/ci", "a.pps", []byte("{}"), "application/json")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error containing %s, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if url != "azblob://evidence/ci/a.pps" || gotPath != "/evidence/ci/a.pps" || gotContentType != "application/json" {
+				t.Errorf("unexpected request %s %s %s", url, gotPath, gotContentType)
+			}
+			if !strings.HasPrefix(gotAuth, tc.wantAuth) || !strings.Contains(gotQuery, tc.wantQuery) {
+				t.Errorf("unexpected authorization %q, query %q", gotAuth, gotQuery)
+			}
+		})
+	}
+}
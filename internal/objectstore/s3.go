@@ -0,0 +1,44 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// the env var used to override the S3 endpoint (the standard AWS SDK service endpoint variable), e.g. for MinIO -
+// objects are addressed path-style when it is set
+const envAWSS3Endpoint = "AWS_ENDPOINT_URL_S3"
+
+func putS3(ctx context.Context, l *Location, key string, data []byte, contentType string) error {
+	var optFns []func(*awsconfig.LoadOptions) error
+	if region := l.Query.Get("region"); region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return fmt.Errorf("could not determine the AWS region - add ?region=<region> to the snapshot location")
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv(envAWSS3Endpoint); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(l.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
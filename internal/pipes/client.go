@@ -0,0 +1,84 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// NewClient returns a Turbot Pipes api client for the host, authenticated with the token
+func NewClient(host, token string) *steampipecloud.APIClient {
+	configuration := steampipecloud.NewConfiguration()
+	configuration.Host = host
+	if token != "" {
+		configuration.AddDefaultHeader("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	return steampipecloud.NewAPIClient(configuration)
+}
+
+// GetWorkspaceMetadata returns the metadata of the workspace, including its connection string, using the host and
+// token the workspace is resolved with (see ForWorkspace)
+func GetWorkspaceMetadata(ctx context.Context, workspace string) (*steampipeconfig.CloudMetadata, error) {
+	identityHandle, workspaceHandle, ok := strings.Cut(workspace, "/")
+	if !ok {
+		return nil, sperr.New("invalid workspace database '%s' - must be either a connection string or in format <identity>/<workspace>", workspace)
+	}
+	host, token, err := ForWorkspace(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, error_helpers.MissingCloudTokenError()
+	}
+	client := NewClient(host, token)
+
+	identity, _, err := client.Identities.Get(ctx, identityHandle).Execute()
+	if err != nil {
+		return nil, sperr.New("invalid workspace database '%s' - check the identity and workspace names and the Turbot Pipes host %s", workspace, host)
+	}
+	var cloudWorkspace steampipecloud.Workspace
+	if identity.Type == "user" {
+		cloudWorkspace, _, err = client.UserWorkspaces.Get(ctx, identityHandle, workspaceHandle).Execute()
+	} else {
+		cloudWorkspace, _, err = client.OrgWorkspaces.Get(ctx, identityHandle, workspaceHandle).Execute()
+	}
+	if error_helpers.IsInvalidWorkspaceDatabaseArg(err) {
+		return nil, sperr.New("invalid workspace database '%s' - check the workspace name and try again", workspace)
+	} else if error_helpers.IsInvalidCloudToken(err) {
+		return nil, error_helpers.InvalidCloudTokenError()
+	} else if err != nil {
+		return nil, sperr.Wrap(err)
+	}
+
+	actor, _, err := client.Actors.Get(ctx).Execute()
+	if err != nil {
+		return nil, error_helpers.InvalidCloudTokenError()
+	}
+	password, _, err := client.Users.GetDBPassword(ctx, actor.GetHandle()).Execute()
+	if err != nil {
+		return nil, sperr.Wrap(err)
+	}
+
+	connectionString := fmt.Sprintf("postgresql://%s:%s@%s-%s.%s:9193/%s", actor.Handle, password.Password, identityHandle, workspaceHandle, cloudWorkspace.GetHost(), cloudWorkspace.GetDatabaseName())
+	return &steampipeconfig.CloudMetadata{
+		Actor: &steampipeconfig.ActorMetadata{
+			Id:     actor.Id,
+			Handle: actor.Handle,
+		},
+		Identity: &steampipeconfig.IdentityMetadata{
+			Id:     cloudWorkspace.IdentityId,
+			Type:   identity.Type,
+			Handle: identityHandle,
+		},
+		Workspace: &steampipeconfig.WorkspaceMetadata{
+			Id:     cloudWorkspace.Id,
+			Handle: cloudWorkspace.Handle,
+		},
+		ConnectionString: connectionString,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/filepaths"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/encryption"
+	"github.com/turbot/powerpipe/internal/secrets"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Host is a named Turbot Pipes host, defined by a pipes credential in a config (.ppc) file in the config path, for
+// example:
+//
+//	credential "pipes" "acme" {
+//	  host       = "pipes.acme.internal"
+//	  token      = "secret://vault/pipes/acme#token"
+//	  workspaces = ["acme/*"]
+//	}
+//
+// Workspaces matching one of the workspace patterns (in which * matches any sequence of characters) are resolved
+// using the host - this applies to workspace databases and snapshot locations, so a single run can use workspaces
+// of more than one host. Other workspaces use the --pipes-host, which may also be set to the name of a credential.
+//
+// If the token is not set, the token saved by powerpipe login for the host is used.
+type Host struct {
+	Name       string
+	Host       string   `hcl:"host"`
+	Token      *string  `hcl:"token,optional"`
+	Workspaces []string `hcl:"workspaces,optional"`
+	// the file the host is defined in
+	FileName string
+}
+
+// matchesWorkspace returns whether the workspace identifier (<identity>/<workspace>) matches one of the workspace
+// patterns of the host
+func (h *Host) matchesWorkspace(workspace string) bool {
+	for _, pattern := range h.Workspaces {
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, _ := regexp.MatchString(expr, workspace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetToken returns the token of the host - the token of the host config (resolving a secret reference), otherwise
+// the token saved by powerpipe login for the host
+func (h *Host) GetToken(ctx context.Context) (string, error) {
+	if h.Token == nil {
+		return LoadToken(h.Host)
+	}
+	if secrets.IsReference(*h.Token) {
+		token, err := secrets.Resolve(ctx, *h.Token)
+		if err != nil {
+			return "", fmt.Errorf("pipes credential '%s': %w", h.Name, err)
+		}
+		return token, nil
+	}
+	return *h.Token, nil
+}
+
+// ErrOffline is returned when a Turbot Pipes workspace is used in offline mode
+var ErrOffline = errors.New("Turbot Pipes workspaces cannot be used in offline mode")
+
+// credentialType is the type of the credentials which define Pipes hosts
+const credentialType = "pipes"
+
+type credentialsConfig struct {
+	Credentials []*credentialConfig `hcl:"credential,block"`
+	// the other blocks of the config files are loaded by pipe-fittings and the connection package
+	Remain hcl.Body `hcl:",remain"`
+}
+
+type credentialConfig struct {
+	Type string   `hcl:"type,label"`
+	Name string   `hcl:"name,label"`
+	Body hcl.Body `hcl:",remain"`
+}
+
+// the hosts loaded from the config path, keyed by name
+var hosts = map[string]*Host{}
+
+// Load loads the hosts defined in the config files of the config directories, which are in decreasing order of
+// precedence - if a host is defined in more than one directory, the first definition is used
+func Load(configPaths []string) error {
+	res := map[string]*Host{}
+	for _, configPath := range configPaths {
+		dirHosts, err := loadDir(configPath)
+		if err != nil {
+			return err
+		}
+		for name, h := range dirHosts {
+			if _, ok := res[name]; !ok {
+				res[name] = h
+			}
+		}
+	}
+	hosts = res
+	return nil
+}
+
+func loadDir(configPath string) (map[string]*Host, error) {
+	res := map[string]*Host{}
+	if !filehelpers.DirectoryExists(configPath) {
+		return res, nil
+	}
+	configFiles, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions([]string{app_specific.ConfigExtension}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// as for connections, the functions available to workspace profiles (including env) can be used
+	evalCtx := &hcl.EvalContext{
+		Functions: encryption.ContextFunctions(configPath),
+		Variables: map[string]cty.Value{},
+	}
+
+	parser := hclparse.NewParser()
+	for _, configFile := range configFiles {
+		file, diags := parser.ParseHCLFile(configFile)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to parse config file", diags)
+		}
+		var config credentialsConfig
+		if diags := gohcl.DecodeBody(file.Body, evalCtx, &config); diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to decode credentials", diags)
+		}
+		for _, c := range config.Credentials {
+			if c.Type != credentialType {
+				continue
+			}
+			h := &Host{Name: c.Name, FileName: configFile}
+			if diags := gohcl.DecodeBody(c.Body, evalCtx, h); diags.HasErrors() {
+				return nil, error_helpers.HclDiagsToError(fmt.Sprintf("failed to decode pipes credential '%s'", c.Name), diags)
+			}
+			if existing, ok := res[h.Name]; ok {
+				return nil, sperr.New("duplicate pipes credential '%s' defined in %s and %s", h.Name, existing.FileName, configFile)
+			}
+			if h.Host == "" {
+				return nil, sperr.New("pipes credential '%s' in %s has an empty host", h.Name, configFile)
+			}
+			res[h.Name] = h
+		}
+	}
+	return res, nil
+}
+
+// Resolve returns the host with the given name, or nil if there is none
+func Resolve(name string) *Host {
+	return hosts[name]
+}
+
+// Names returns the sorted names of the loaded hosts
+func Names() []string {
+	res := make([]string, 0, len(hosts))
+	for name := range hosts {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ForWorkspace returns the host and token used for the workspace identifier - the first host (by name) with a
+// workspace pattern matching the workspace, otherwise the --pipes-host and --pipes-token
+func ForWorkspace(ctx context.Context, workspace string) (host, token string, err error) {
+	if viper.GetBool(localconstants.ArgOffline) {
+		return "", "", ErrOffline
+	}
+	for _, name := range Names() {
+		h := hosts[name]
+		if h.matchesWorkspace(workspace) {
+			token, err := h.GetToken(ctx)
+			return h.Host, token, err
+		}
+	}
+	return viper.GetString(constants.ArgPipesHost), viper.GetString(constants.ArgPipesToken), nil
+}
+
+// TokenForHost returns the token used for the host - the token of a configured host with that host name, the
+// --pipes-token if the host is the --pipes-host, otherwise the token saved by powerpipe login for the host
+func TokenForHost(ctx context.Context, host string) (string, error) {
+	if viper.GetBool(localconstants.ArgOffline) {
+		return "", ErrOffline
+	}
+	for _, name := range Names() {
+		if h := hosts[name]; h.Host == host {
+			return h.GetToken(ctx)
+		}
+	}
+	if host == viper.GetString(constants.ArgPipesHost) {
+		return viper.GetString(constants.ArgPipesToken), nil
+	}
+	return LoadToken(host)
+}
+
+// LoadToken returns the token saved by powerpipe login for the host, or an empty string if there is none
+func LoadToken(host string) (string, error) {
+	tokenPath := path.Join(filepaths.PipesInternalDir(), host+constants.TokenExtension)
+	if !filehelpers.FileExists(tokenPath) {
+		return "", nil
+	}
+	token, err := os.ReadFile(tokenPath)
+	if err != nil {
+		return "", sperr.WrapWithMessage(err, "failed to load token file '%s'", tokenPath)
+	}
+	return string(token), nil
+}
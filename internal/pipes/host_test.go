@@ -0,0 +1,67 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestForWorkspace(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	config := `
+workspace "default" {
+  pipes_host = "acme"
+}
+
+credential "aws" "prod" {
+  profile = "prod"
+}
+
+credential "pipes" "acme" {
+  host       = "pipes.acme.internal"
+  token      = "secret://env/ACME_PIPES_TOKEN"
+  workspaces = ["acme/*", "jane/onprem"]
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "pipes.ppc"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ACME_PIPES_TOKEN", "tpt_acme")
+	viper.Set(constants.ArgPipesHost, "pipes.turbot.com")
+	viper.Set(constants.ArgPipesToken, "tpt_default")
+	defer viper.Reset()
+
+	tests := map[string][2]string{
+		"acme/prod":   {"pipes.acme.internal", "tpt_acme"},
+		"jane/onprem": {"pipes.acme.internal", "tpt_acme"},
+		"jane/dev":    {"pipes.turbot.com", "tpt_default"},
+	}
+	for workspace, want := range tests {
+		host, token, err := ForWorkspace(context.Background(), workspace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if host != want[0] || token != want[1] {
+			t.Errorf("%s: expected %s %s, got %s %s", workspace, want[0], want[1], host, token)
+		}
+	}
+	if Resolve("acme") == nil || Resolve("other") != nil {
+		t.Error("expected only the acme host to resolve")
+	}
+
+	viper.Set(localconstants.ArgOffline, true)
+	if _, _, err := ForWorkspace(context.Background(), "acme/prod"); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline in offline mode, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/parse"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// the names of the variable environment variables set by SyncVariables, so a later sync updates them rather than
+// treating them as local overrides
+var syncedVariables = map[string]struct{}{}
+
+// SyncVariables sets the values of the workspace mod variables from the variable settings of the Turbot Pipes
+// workspace given by --pipes-variables, as <identity>/<workspace> or <identity>/<workspace>/<mod alias> (the mod
+// alias defaults to the name of the workspace mod).
+//
+// The values are set as variable environment variables (PP_VAR_<name>), which have the lowest precedence - values
+// set locally by --var, --var-file, .ppvars files and environment variables take precedence.
+func SyncVariables(ctx context.Context, workspacePath string) error {
+	target := viper.GetString(localconstants.ArgPipesVariables)
+	if target == "" {
+		return nil
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+		return sperr.New("invalid --%s '%s' - must be in format <identity>/<workspace> or <identity>/<workspace>/<mod alias>", localconstants.ArgPipesVariables, target)
+	}
+	workspace := parts[0] + "/" + parts[1]
+	var modAlias string
+	if len(parts) == 3 {
+		modAlias = parts[2]
+	} else {
+		mod, err := parse.LoadModfile(workspacePath)
+		if err != nil {
+			return err
+		}
+		if mod == nil {
+			// there is no workspace mod, so no variables to set
+			return nil
+		}
+		modAlias = mod.ShortName
+	}
+
+	values, err := GetModVariables(ctx, workspace, modAlias)
+	if err != nil {
+		return fmt.Errorf("failed to get the variables of mod '%s' in Turbot Pipes workspace '%s': %w", modAlias, workspace, err)
+	}
+	setVariableEnv(values)
+	return nil
+}
+
+// GetModVariables returns the raw values of the variables of the mod which have a setting in the workspace, keyed
+// by variable name. String values of string variables are returned as is, other values are encoded as JSON, which
+// is parsed as an HCL expression
+func GetModVariables(ctx context.Context, workspace, modAlias string) (map[string]string, error) {
+	identityHandle, workspaceHandle, _ := strings.Cut(workspace, "/")
+	host, token, err := ForWorkspace(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, error_helpers.MissingCloudTokenError()
+	}
+	client := NewClient(host, token)
+
+	identity, _, err := client.Identities.Get(ctx, identityHandle).Execute()
+	if err != nil {
+		return nil, sperr.New("invalid workspace '%s' - check the identity and workspace names and the Turbot Pipes host %s", workspace, host)
+	}
+
+	res := map[string]string{}
+	var nextToken string
+	for {
+		var page steampipecloud.ListWorkspaceModVariablesResponse
+		if identity.Type == "user" {
+			req := client.UserWorkspaceModVariables.List(ctx, identityHandle, workspaceHandle, modAlias)
+			if nextToken != "" {
+				req = req.NextToken(nextToken)
+			}
+			page, _, err = req.Execute()
+		} else {
+			req := client.OrgWorkspaceModVariables.List(ctx, identityHandle, workspaceHandle, modAlias)
+			if nextToken != "" {
+				req = req.NextToken(nextToken)
+			}
+			page, _, err = req.Execute()
+		}
+		if error_helpers.IsInvalidCloudToken(err) {
+			return nil, error_helpers.InvalidCloudTokenError()
+		} else if err != nil {
+			return nil, sperr.Wrap(err)
+		}
+		for _, v := range page.GetItems() {
+			// only variables with a setting in the workspace are used - defaults are defined by the local mod
+			if v.Name == nil || v.ValueSetting == nil {
+				continue
+			}
+			raw, err := rawVariableValue(v.GetType(), v.ValueSetting)
+			if err != nil {
+				return nil, fmt.Errorf("variable '%s': %w", *v.Name, err)
+			}
+			res[*v.Name] = raw
+		}
+		nextToken = page.GetNextToken()
+		if nextToken == "" {
+			return res, nil
+		}
+	}
+}
+
+// rawVariableValue returns the value as it would be passed to --var
+func rawVariableValue(typeName string, value any) (string, error) {
+	if s, ok := value.(string); ok && typeName == "string" {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// setVariableEnv sets the variable environment variables for the values, unless the environment variable has been
+// set locally. Variables set by a previous sync which no longer have a setting are unset
+func setVariableEnv(values map[string]string) {
+	for envVar := range syncedVariables {
+		if _, ok := values[strings.TrimPrefix(envVar, app_specific.EnvInputVarPrefix)]; !ok {
+			_ = os.Unsetenv(envVar)
+			delete(syncedVariables, envVar)
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		envVar := app_specific.EnvInputVarPrefix + name
+		if _, synced := syncedVariables[envVar]; !synced {
+			if _, ok := os.LookupEnv(envVar); ok {
+				slog.Info("ignoring Turbot Pipes variable setting - the variable is set locally", "variable", name)
+				continue
+			}
+		}
+		slog.Info("setting variable from Turbot Pipes", "variable", name)
+		_ = os.Setenv(envVar, values[name])
+		syncedVariables[envVar] = struct{}{}
+	}
+}
@@ -0,0 +1,91 @@
+package pipes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+func TestGetModVariables(t *testing.T) {
+	hosts = map[string]*Host{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v0/identity/acme":
+			_, _ = w.Write([]byte(`{"id": "o_1", "handle": "acme", "type": "org"}`))
+		case r.URL.Path == "/api/v0/org/acme/workspace/prod/mod/aws_compliance/variable" && r.URL.Query().Get("next_token") == "":
+			_, _ = w.Write([]byte(`{"items": [
+				{"name": "region", "type": "string", "value_setting": "us-east-1"},
+				{"name": "tags", "type": "map(string)", "value_setting": {"env": "prod"}},
+				{"name": "regions", "type": "list(string)", "value_default": ["us-east-1"]}
+			], "next_token": "page2"}`))
+		case r.URL.Path == "/api/v0/org/acme/workspace/prod/mod/aws_compliance/variable":
+			_, _ = w.Write([]byte(`{"items": [{"name": "max_age", "type": "number", "value_setting": 90}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = server.Client().Transport
+	defer func() { http.DefaultClient.Transport = transport }()
+	serverURL, _ := url.Parse(server.URL)
+	viper.Set(constants.ArgPipesHost, serverURL.Host)
+	viper.Set(constants.ArgPipesToken, "tpt_test")
+	defer viper.Reset()
+
+	got, err := GetModVariables(context.Background(), "acme/prod", "aws_compliance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"region":  "us-east-1",
+		"tags":    `{"env":"prod"}`,
+		"max_age": "90",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s: expected %q, got %q", name, value, got[name])
+		}
+	}
+}
+
+func TestSetVariableEnv(t *testing.T) {
+	app_specific.EnvInputVarPrefix = "PP_VAR_"
+	t.Setenv("PP_VAR_region", "eu-west-1")
+	// t.Setenv restores the environment variables after the test
+	for _, name := range []string{"PP_VAR_max_age", "PP_VAR_tags"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	defer func() { syncedVariables = map[string]struct{}{} }()
+
+	setVariableEnv(map[string]string{"region": "us-east-1", "max_age": "90", "tags": `{"env":"prod"}`})
+	// variables set locally take precedence
+	if got := os.Getenv("PP_VAR_region"); got != "eu-west-1" {
+		t.Errorf("expected the local value of region, got %q", got)
+	}
+	if got := os.Getenv("PP_VAR_max_age"); got != "90" {
+		t.Errorf("expected max_age to be set, got %q", got)
+	}
+
+	// a later sync updates the values it set, and unsets those which no longer have a setting
+	setVariableEnv(map[string]string{"region": "us-east-1", "max_age": "30"})
+	if got := os.Getenv("PP_VAR_max_age"); got != "30" {
+		t.Errorf("expected max_age to be updated, got %q", got)
+	}
+	if _, ok := os.LookupEnv("PP_VAR_tags"); ok {
+		t.Errorf("expected tags to be unset")
+	}
+}
@@ -0,0 +1,140 @@
+// Package querylog records every SQL statement executed, with its args, duration, row count and the resource which
+// executed it, for debugging mods and for database capacity planning. The query log is enabled by --query-log (or
+// POWERPIPE_QUERY_LOG), which is either a file the entries are appended to, or stdout or stderr. Each entry is written
+// as a line of JSON, e.g.
+//
+//	{"time":"2024-05-01T10:00:00Z","resource":"aws_compliance.control.s3_versioning","sql":"select ...","args":["us-east-1"],"duration_ms":41.2,"rows":12}
+package querylog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// the query log destinations which are not files
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// Entry is a single query log entry
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Resource   string    `json:"resource,omitempty"`
+	SQL        string    `json:"sql"`
+	Args       []any     `json:"args,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Rows       int       `json:"rows"`
+	Error      string    `json:"error,omitempty"`
+}
+
+var (
+	// the query log, if enabled - the lock serializes writes from concurrent queries
+	lock   sync.Mutex
+	writer io.Writer
+	file   *os.File
+)
+
+// Open opens the query log set by --query-log, if any
+func Open() error {
+	path := viper.GetString(localconstants.ArgQueryLog)
+	lock.Lock()
+	defer lock.Unlock()
+
+	switch path {
+	case "":
+		return nil
+	case Stdout:
+		writer = os.Stdout
+	case Stderr:
+		writer = os.Stderr
+	default:
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open the query log: %w", err)
+		}
+		file, writer = f, f
+	}
+	return nil
+}
+
+// Close closes the query log
+func Close() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if file != nil {
+		_ = file.Close()
+		file = nil
+	}
+	writer = nil
+}
+
+type contextKey struct{}
+
+// WithResource returns a context recording that its queries are executed by the resource
+func WithResource(ctx context.Context, resource string) context.Context {
+	return context.WithValue(ctx, contextKey{}, resource)
+}
+
+// Log records the execution of a statement by the resource of the context, if the query log is enabled
+func Log(ctx context.Context, sql string, args []any, duration time.Duration, rows int, err error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if writer == nil {
+		return
+	}
+
+	entry := &Entry{
+		Time:       time.Now().UTC(),
+		SQL:        sql,
+		Args:       args,
+		DurationMs: float64(duration.Microseconds()) / 1000,
+		Rows:       rows,
+	}
+	entry.Resource, _ = ctx.Value(contextKey{}).(string)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	b, marshalErr := marshal(entry)
+	if marshalErr != nil {
+		// args which cannot be marshalled (which the database driver has accepted) are logged as strings
+		entry.Args = stringArgs(args)
+		if b, marshalErr = marshal(entry); marshalErr != nil {
+			slog.Warn("failed to write query log entry", "error", marshalErr)
+			return
+		}
+	}
+	if _, err := writer.Write(b); err != nil {
+		slog.Warn("failed to write query log entry", "error", err)
+	}
+}
+
+// marshal returns the entry as a line of JSON, without escaping the comparison operators of the SQL
+func marshal(entry *Entry) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func stringArgs(args []any) []any {
+	res := make([]any, len(args))
+	for i, a := range args {
+		res[i] = fmt.Sprintf("%v", a)
+	}
+	return res
+}
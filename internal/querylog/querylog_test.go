@@ -0,0 +1,56 @@
+package querylog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.log")
+	viper.Set(localconstants.ArgQueryLog, path)
+	defer viper.Set(localconstants.ArgQueryLog, nil)
+
+	// nothing is logged before the query log is opened
+	Log(context.Background(), "select 0", nil, time.Millisecond, 1, nil)
+	if err := Open(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithResource(context.Background(), "aws_compliance.control.s3_versioning")
+	Log(ctx, "select * from aws_s3_bucket where region = $1 and size < 10", []any{"us-east-1"}, 1500*time.Microsecond, 12, nil)
+	Log(context.Background(), "select nope", []any{make(chan int)}, time.Millisecond, 0, errors.New("no such column: nope"))
+	Close()
+	Log(ctx, "select 2", nil, time.Millisecond, 1, nil)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries, got %d:\n%s", len(lines), b)
+	}
+	if !strings.Contains(lines[0], "size < 10") {
+		t.Errorf("expected the SQL not to be escaped, got %s", lines[0])
+	}
+	var entries [2]Entry
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e := entries[0]; e.Resource != "aws_compliance.control.s3_versioning" || e.Rows != 12 || e.DurationMs != 1.5 || len(e.Args) != 1 || e.Error != "" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := entries[1]; e.Resource != "" || e.Error != "no such column: nope" || len(e.Args) != 1 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
@@ -11,6 +11,24 @@ type RowResult struct {
 }
 type TimingMetadata struct {
 	Duration time.Duration
+	// the breakdown of the duration, reported by --timing=verbose: waiting for a database connection (or to be
+	// scheduled), executing the query until the rows are available, fetching the rows, and rendering the results
+	Queue     time.Duration
+	Execution time.Duration
+	Fetch     time.Duration
+	Render    time.Duration
+}
+
+// Add adds the durations of another timing
+func (t *TimingMetadata) Add(other *TimingMetadata) {
+	if other == nil {
+		return
+	}
+	t.Duration += other.Duration
+	t.Queue += other.Queue
+	t.Execution += other.Execution
+	t.Fetch += other.Fetch
+	t.Render += other.Render
 }
 
 type Result struct {
@@ -44,6 +62,7 @@ func (r *Result) StreamError(err error) {
 }
 
 type SyncQueryResult struct {
-	Rows []interface{}
-	Cols []*queryresult.ColumnDef
+	Rows   []interface{}
+	Cols   []*queryresult.ColumnDef
+	Timing *TimingMetadata
 }
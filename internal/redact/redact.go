@@ -0,0 +1,240 @@
+// Package redact hashes or masks the values of sensitive columns in snapshots and exports, so results can be shared
+// outside the team which ran them. Values remain visible in the terminal output and the dashboard server.
+//
+// Columns are marked as sensitive by the --redact-columns config, by the redact_columns tag of the workspace mod
+// (applying to every resource) and by the redact_columns tag of a resource (applying to the resource and, for a
+// dashboard or benchmark, its descendants). Each is a comma-separated list of column names, which may contain
+// glob patterns, e.g. "email,*_ip".
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/workspace"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// the redaction modes
+const (
+	// ModeHash replaces values with a hash keyed with POWERPIPE_REDACT_SALT, so redacted values can still be compared.
+	// The salt is required, as an unkeyed hash of a value such as an IP address or account ID can be reversed by
+	// hashing the candidate values
+	ModeHash = "hash"
+	// ModeMask replaces values with a fixed mask - this is the default mode
+	ModeMask = "mask"
+)
+
+// TagKey is the tag of a mod or resource which lists its sensitive columns
+const TagKey = "redact_columns"
+
+// mask is the value redacted values are replaced with in mask mode
+const mask = "********"
+
+// the prefix of hashed values, and the number of hex characters of the hash kept
+const (
+	hashPrefix = "redacted:"
+	hashLength = 16
+)
+
+// columns which are never redacted, as they are required to summarise control results
+var protectedColumns = map[string]struct{}{"status": {}}
+
+// Policy is the set of sensitive columns and how their values are redacted
+type Policy struct {
+	Mode string
+	// the column patterns which apply to every resource
+	columns []string
+	// the column patterns which apply to a resource and its descendants, keyed by resource full name
+	resourceColumns map[string][]string
+	// the key used to hash values, from POWERPIPE_REDACT_SALT
+	salt []byte
+}
+
+// the policy of the current run - see SetPolicy
+var current = &Policy{Mode: ModeMask}
+
+// Current returns the redaction policy of the current run
+func Current() *Policy {
+	return current
+}
+
+// SetPolicy sets the redaction policy of the current run
+func SetPolicy(p *Policy) {
+	current = p
+}
+
+// ValidateMode checks the redact-mode config is a supported mode
+func ValidateMode() error {
+	switch mode := viper.GetString(localconstants.ArgRedactMode); mode {
+	case "", ModeHash, ModeMask:
+		return nil
+	default:
+		return fmt.Errorf("invalid %s '%s' - must be '%s' or '%s'", localconstants.ArgRedactMode, mode, ModeHash, ModeMask)
+	}
+}
+
+// FromConfig returns the redaction policy for the workspace (which may be nil) - the columns of the --redact-columns
+// config, and the redact_columns tags of the workspace mod and its resources
+func FromConfig(w *workspace.Workspace) (*Policy, error) {
+	if err := ValidateMode(); err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		Mode:            viper.GetString(localconstants.ArgRedactMode),
+		resourceColumns: map[string][]string{},
+	}
+	if p.Mode == "" {
+		p.Mode = ModeMask
+	}
+	if salt := os.Getenv(localconstants.EnvRedactSalt); salt != "" {
+		p.salt = []byte(salt)
+	}
+	if p.Mode == ModeHash && len(p.salt) == 0 {
+		return nil, fmt.Errorf("%s '%s' requires %s to be set", localconstants.ArgRedactMode, ModeHash, localconstants.EnvRedactSalt)
+	}
+	for _, arg := range viper.GetStringSlice(localconstants.ArgRedactColumns) {
+		p.columns = append(p.columns, splitColumns(arg)...)
+	}
+	if w == nil || w.Mod == nil {
+		return p, nil
+	}
+	p.columns = append(p.columns, splitColumns(w.Mod.Tags[TagKey])...)
+	err := w.GetResourceMaps().WalkResources(func(item modconfig.HclResource) (bool, error) {
+		if _, ok := item.(*modconfig.Mod); ok {
+			return true, nil
+		}
+		if columns := splitColumns(item.GetTags()[TagKey]); len(columns) > 0 {
+			p.resourceColumns[item.Name()] = columns
+		}
+		return true, nil
+	})
+	return p, err
+}
+
+func splitColumns(value string) []string {
+	var res []string
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			res = append(res, column)
+		}
+	}
+	return res
+}
+
+// Empty returns whether the policy redacts no columns
+func (p *Policy) Empty() bool {
+	return p == nil || (len(p.columns) == 0 && len(p.resourceColumns) == 0)
+}
+
+// Redacts returns whether the column of a resource is redacted - the resource is given by its name and the names of
+// its ancestors
+func (p *Policy) Redacts(resources []string, column string) bool {
+	if p.Empty() {
+		return false
+	}
+	if _, ok := protectedColumns[column]; ok {
+		return false
+	}
+	if matchesAny(p.columns, column) {
+		return true
+	}
+	for _, resource := range resources {
+		if matchesAny(p.resourceColumns[resource], column) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, column string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, column); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the redacted value - null values are not redacted. Values are masked in hash mode if there is no
+// salt to key the hash with
+func (p *Policy) Value(value any) any {
+	if value == nil {
+		return nil
+	}
+	if p.Mode != ModeHash || len(p.salt) == 0 {
+		return mask
+	}
+	h := hmac.New(sha256.New, p.salt)
+	fmt.Fprint(h, value)
+	return hashPrefix + hex.EncodeToString(h.Sum(nil))[:hashLength]
+}
+
+// RedactSnapshot redacts the values of the sensitive columns in the panel data of the snapshot
+func (p *Policy) RedactSnapshot(data *steampipecloud.WorkspaceSnapshotData) {
+	if p.Empty() {
+		return
+	}
+	ancestors := map[string][]string{}
+	walkLayout(data.Layout, nil, ancestors)
+	for name, panel := range data.Panels {
+		panelMap, ok := panel.(map[string]any)
+		if !ok {
+			continue
+		}
+		panelData, ok := panelMap["data"].(map[string]any)
+		if !ok {
+			continue
+		}
+		resources := append([]string{name}, ancestors[name]...)
+		if dashboard, ok := panelMap["dashboard"].(string); ok {
+			resources = append(resources, dashboard)
+		}
+		p.redactRows(resources, panelData["rows"])
+	}
+}
+
+// walkLayout records the names of the ancestors of each panel in the layout
+func walkLayout(node steampipecloud.WorkspaceSnapshotDataLayout, parents []string, ancestors map[string][]string) {
+	ancestors[node.Name] = parents
+	if node.Children == nil {
+		return
+	}
+	childParents := append(append([]string{}, parents...), node.Name)
+	for _, child := range *node.Children {
+		walkLayout(child, childParents, ancestors)
+	}
+}
+
+func (p *Policy) redactRows(resources []string, rows any) {
+	rowList, ok := rows.([]any)
+	if !ok {
+		return
+	}
+	// cache whether each column is redacted, as every row has the same columns
+	redacted := map[string]bool{}
+	for _, row := range rowList {
+		rowMap, ok := row.(map[string]any)
+		if !ok {
+			continue
+		}
+		for column, value := range rowMap {
+			r, ok := redacted[column]
+			if !ok {
+				r = p.Redacts(resources, column)
+				redacted[column] = r
+			}
+			if r {
+				rowMap[column] = p.Value(value)
+			}
+		}
+	}
+}
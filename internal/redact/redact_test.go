@@ -0,0 +1,115 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestRedacts(t *testing.T) {
+	p := &Policy{
+		Mode:            ModeHash,
+		columns:         []string{"email", "*_ip"},
+		resourceColumns: map[string][]string{"aws_compliance.benchmark.cis": {"account_id"}},
+	}
+	tests := map[string]struct {
+		resources []string
+		column    string
+		want      bool
+	}{
+		"global column":     {[]string{"aws.query.users"}, "email", true},
+		"global pattern":    {[]string{"aws.query.users"}, "source_ip", true},
+		"resource column":   {[]string{"aws_compliance.control.cis_1_1", "aws_compliance.benchmark.cis"}, "account_id", true},
+		"other resource":    {[]string{"aws.query.users"}, "account_id", false},
+		"unmarked column":   {[]string{"aws.query.users"}, "name", false},
+		"protected column":  {[]string{"aws.query.users"}, "status", false},
+		"no resource names": {nil, "email", true},
+	}
+	for name, test := range tests {
+		if got := p.Redacts(test.resources, test.column); got != test.want {
+			t.Errorf("%s: expected %v, got %v", name, test.want, got)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	p := &Policy{Mode: ModeHash, salt: []byte("secret")}
+	hashed := p.Value("jane@acme.com")
+	if hashed != p.Value("jane@acme.com") || !strings.HasPrefix(hashed.(string), hashPrefix) {
+		t.Errorf("expected a stable hash, got %v", hashed)
+	}
+	salted := &Policy{Mode: ModeHash, salt: []byte("other")}
+	if salted.Value("jane@acme.com") == hashed {
+		t.Errorf("expected the salt to change the hash")
+	}
+	if got := (&Policy{Mode: ModeHash}).Value("jane@acme.com"); got != mask {
+		t.Errorf("expected values to be masked when there is no salt, got %v", got)
+	}
+	if got := (&Policy{Mode: ModeMask}).Value(42); got != mask {
+		t.Errorf("expected the mask, got %v", got)
+	}
+	if got := p.Value(nil); got != nil {
+		t.Errorf("expected null values not to be redacted, got %v", got)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	tests := map[string]struct {
+		mode     string
+		salt     string
+		wantMode string
+		wantErr  bool
+	}{
+		"default":           {wantMode: ModeMask},
+		"mask":              {mode: ModeMask, wantMode: ModeMask},
+		"hash":              {mode: ModeHash, salt: "secret", wantMode: ModeHash},
+		"hash without salt": {mode: ModeHash, wantErr: true},
+		"invalid mode":      {mode: "encrypt", salt: "secret", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			viper.Set(localconstants.ArgRedactMode, tc.mode)
+			defer viper.Set(localconstants.ArgRedactMode, nil)
+			t.Setenv(localconstants.EnvRedactSalt, tc.salt)
+
+			p, err := FromConfig(nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got mode %s", p.Mode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Mode != tc.wantMode {
+				t.Errorf("expected mode %s, got %s", tc.wantMode, p.Mode)
+			}
+		})
+	}
+}
+
+func TestRedactSnapshot(t *testing.T) {
+	p := &Policy{Mode: ModeMask, resourceColumns: map[string][]string{"acme.dashboard.users": {"email"}}}
+	children := []steampipecloud.WorkspaceSnapshotDataLayout{{Name: "acme.table.users", PanelType: "table"}}
+	data := &steampipecloud.WorkspaceSnapshotData{
+		Layout: steampipecloud.WorkspaceSnapshotDataLayout{Name: "acme.dashboard.users", PanelType: "dashboard", Children: &children},
+		Panels: map[string]any{
+			"acme.dashboard.users": map[string]any{"panel_type": "dashboard"},
+			"acme.table.users": map[string]any{
+				"panel_type": "table",
+				"data": map[string]any{
+					"rows": []any{map[string]any{"email": "jane@acme.com", "name": "Jane"}},
+				},
+			},
+		},
+	}
+	p.RedactSnapshot(data)
+	row := data.Panels["acme.table.users"].(map[string]any)["data"].(map[string]any)["rows"].([]any)[0].(map[string]any)
+	if row["email"] != mask || row["name"] != "Jane" {
+		t.Errorf("unexpected row: %v", row)
+	}
+}
@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// the env var used to override the Secrets Manager endpoint (the standard AWS SDK service endpoint variable)
+const envAWSSecretsManagerEndpoint = "AWS_ENDPOINT_URL_SECRETS_MANAGER"
+
+// getAWSSecret returns the secret string of an AWS Secrets Manager secret - the path is the secret name or ARN,
+// optionally followed by a region query parameter
+func getAWSSecret(ctx context.Context, path string) (string, error) {
+	secretID, rawQuery, _ := strings.Cut(path, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	region := query.Get("region")
+	// an ARN includes the region, e.g. arn:aws:secretsmanager:us-east-1:123456789012:secret:powerpipe/db
+	if arn := strings.Split(secretID, ":"); region == "" && len(arn) > 3 && arn[0] == "arn" {
+		region = arn[3]
+	}
+
+	var optFns []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return "", fmt.Errorf("could not determine the AWS region - add ?region=<region> to the secret reference")
+	}
+	endpoint := os.Getenv(envAWSSecretsManagerEndpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+	return getSecretValue(ctx, http.DefaultClient, endpoint, cfg.Region, cfg.Credentials, secretID)
+}
+
+// getSecretValue calls the Secrets Manager GetSecretValue API
+func getSecretValue(ctx context.Context, client *http.Client, endpoint, region string, credentials aws.CredentialsProvider, secretID string) (string, error) {
+	creds, err := credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	payloadHash := sha256.Sum256(body)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), "secretsmanager", region, time.Now().UTC()); err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Type != "" {
+			return "", fmt.Errorf("%s: %s", apiErr.Type, apiErr.Message)
+		}
+		return "", fmt.Errorf("secrets manager returned %s", resp.Status)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(respBody, &secret); err != nil {
+		return "", err
+	}
+	if secret.SecretString == nil {
+		return "", fmt.Errorf("the secret is a binary secret - only string secrets are supported")
+	}
+	return *secret.SecretString, nil
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the standard Vault client env vars
+const (
+	envVaultAddr      = "VAULT_ADDR"
+	envVaultToken     = "VAULT_TOKEN"
+	envVaultNamespace = "VAULT_NAMESPACE"
+)
+
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// getVaultSecret returns the secret at the Vault API path, e.g. secret/data/powerpipe for a KV version 2 secret
+// (or secret/powerpipe for KV version 1) - the secret data is returned as a JSON object
+func getVaultSecret(ctx context.Context, path string) (string, error) {
+	addr := os.Getenv(envVaultAddr)
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token := os.Getenv(envVaultToken)
+	if token == "" {
+		// the token saved by vault login
+		if home, err := os.UserHomeDir(); err == nil {
+			if b, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(b))
+			}
+		}
+	}
+	if token == "" {
+		return "", fmt.Errorf("no Vault token - set %s or run vault login", envVaultToken)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv(envVaultNamespace); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(vaultErr.Errors, ", "))
+		}
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", err
+	}
+	// a KV version 2 secret nests the data alongside its metadata
+	if data, ok := secret.Data["data"]; ok {
+		if _, hasMetadata := secret.Data["metadata"]; hasMetadata {
+			return string(data), nil
+		}
+	}
+	data, err := json.Marshal(secret.Data)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -73,6 +73,9 @@ type APIService struct {
 
 	// the loaded workspace
 	workspace *workspace.Workspace
+
+	// custom branding for the dashboard UI
+	branding *dashboardserver.Branding
 }
 
 // APIServiceOption defines a type of function to configures the APIService.
@@ -92,6 +95,13 @@ func WithWorkspace(workspace *workspace.Workspace) APIServiceOption {
 	}
 }
 
+func WithBranding(branding *dashboardserver.Branding) APIServiceOption {
+	return func(api *APIService) error {
+		api.branding = branding
+		return nil
+	}
+}
+
 func WithHttpPort(port dashboardserver.ListenPort) APIServiceOption {
 	return func(api *APIService) error {
 		api.HTTPPort = fmt.Sprintf("%d", port)
@@ -160,6 +170,16 @@ func (api *APIService) Start() error {
 	assetsDirectory := filepaths.EnsureDashboardAssetsDir()
 	// respond with the static dashboard assets for / (root)
 	router.Use(static.Serve("/", static.LocalFile(assetsDirectory, true)))
+	// serve the custom branding logo, if there is one
+	if api.branding != nil && api.branding.LogoPath != "" {
+		router.GET(dashboardserver.BrandingLogoPath, func(c *gin.Context) {
+			c.File(api.branding.LogoPath)
+		})
+	}
+	// serve the mod assets directory, if there is one
+	if modAssetsDir, ok := dashboardserver.ModAssetsDir(); ok {
+		router.Static(dashboardserver.ModAssetsPath, modAssetsDir)
+	}
 	if api.webSocket != nil {
 		router.GET("/ws", func(c *gin.Context) {
 			if err := api.webSocket.HandleRequest(c.Writer, c.Request); err != nil {
@@ -241,3 +261,15 @@ func (api *APIService) Start() error {
 
 	return nil
 }
+
+// Stop gracefully shuts down the API service.
+func (api *APIService) Stop(ctx context.Context) error {
+	if api.httpServer == nil {
+		return nil
+	}
+	if err := api.httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+	api.Status = "stopped"
+	return nil
+}
@@ -0,0 +1,206 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// the snapshot compression modes
+const (
+	CompressionNone = "none"
+	CompressionZstd = "zstd"
+)
+
+// zstdMagic is the magic number at the start of a zstd frame - compressed snapshots keep the .pps extension and are
+// identified by their content
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// the key of the table of shared panel data in a compressed snapshot, and of the references to it in panel data
+const (
+	sharedDataKey    = "shared_data"
+	sharedDataRefKey = "$shared"
+)
+
+// the panel data properties which are deduplicated when a snapshot is compressed
+var dedupedDataProperties = []string{"columns", "rows"}
+
+// ValidateCompression checks the snapshot-compression config is a supported compression mode
+func ValidateCompression() error {
+	switch compression := viper.GetString(localconstants.ArgSnapshotCompression); compression {
+	case "", CompressionNone, CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("invalid %s '%s' - must be '%s' or '%s'", localconstants.ArgSnapshotCompression, compression, CompressionNone, CompressionZstd)
+	}
+}
+
+func compressionEnabled() bool {
+	return viper.GetString(localconstants.ArgSnapshotCompression) == CompressionZstd
+}
+
+// IsCompressed returns whether the snapshot file content is compressed
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, zstdMagic)
+}
+
+// ReadFile reads a snapshot file, returning the snapshot json - compressed snapshots are decompressed
+func ReadFile(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return Decompress(data)
+}
+
+// Compress deduplicates the columns and rows shared by multiple panels of the snapshot json, then compresses it
+// with zstd. Deduplication reduces the size of snapshots in which many panels return the same data, where the
+// repeated data is too far apart to be compressed
+func Compress(data []byte) ([]byte, error) {
+	snap, err := decodeSnapshotMap(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := dedupePanelData(snap); err != nil {
+		return nil, err
+	}
+	deduped, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(deduped, nil), nil
+}
+
+// Decompress returns the json of a compressed snapshot, restoring its deduplicated panel data. Snapshots which are
+// not compressed are returned unchanged
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	decompressed, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	snap, err := decodeSnapshotMap(decompressed)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snap[sharedDataKey]; !ok {
+		return decompressed, nil
+	}
+	if err := restorePanelData(snap); err != nil {
+		return nil, err
+	}
+	return json.Marshal(snap)
+}
+
+// decodeSnapshotMap decodes the snapshot json as a map, preserving numbers as written
+func decodeSnapshotMap(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var snap map[string]any
+	if err := decoder.Decode(&snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// dedupePanelData moves the panel columns and rows which are shared by more than one panel into the shared data
+// table of the snapshot, replacing them with a reference to it
+func dedupePanelData(snap map[string]any) error {
+	type dataRef struct {
+		data     map[string]any
+		property string
+		key      string
+	}
+	var refs []dataRef
+	values := make(map[string]any)
+	counts := make(map[string]int)
+	for _, data := range panelData(snap) {
+		for _, property := range dedupedDataProperties {
+			value, ok := data[property]
+			if !ok || value == nil {
+				continue
+			}
+			valueJson, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			hash := sha256.Sum256(valueJson)
+			key := hex.EncodeToString(hash[:16])
+			values[key] = value
+			counts[key]++
+			refs = append(refs, dataRef{data: data, property: property, key: key})
+		}
+	}
+
+	sharedData := make(map[string]any)
+	for _, ref := range refs {
+		if counts[ref.key] > 1 {
+			sharedData[ref.key] = values[ref.key]
+			ref.data[ref.property] = map[string]any{sharedDataRefKey: ref.key}
+		}
+	}
+	if len(sharedData) > 0 {
+		snap[sharedDataKey] = sharedData
+	}
+	return nil
+}
+
+// restorePanelData replaces the references to the shared data table with the data
+func restorePanelData(snap map[string]any) error {
+	sharedData, ok := snap[sharedDataKey].(map[string]any)
+	if !ok {
+		return fmt.Errorf("invalid snapshot: invalid %s", sharedDataKey)
+	}
+	for name, data := range panelData(snap) {
+		for _, property := range dedupedDataProperties {
+			ref, ok := data[property].(map[string]any)
+			if !ok {
+				continue
+			}
+			key, _ := ref[sharedDataRefKey].(string)
+			value, ok := sharedData[key]
+			if !ok {
+				return fmt.Errorf("invalid snapshot: panel '%s' references missing shared data '%s'", name, key)
+			}
+			data[property] = value
+		}
+	}
+	delete(snap, sharedDataKey)
+	return nil
+}
+
+// panelData returns the data of each snapshot panel which has data, keyed by panel name
+func panelData(snap map[string]any) map[string]map[string]any {
+	res := make(map[string]map[string]any)
+	panels, _ := snap["panels"].(map[string]any)
+	for name, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if data, ok := panel["data"].(map[string]any); ok {
+			res[name] = data
+		}
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompress(t *testing.T) {
+	data := []byte(`{
+  "schema_version": "20240607",
+  "panels": {
+    "a": {"panel_type": "table", "data": {"columns": [{"name": "id", "data_type": "INT8"}], "rows": [{"id": 9007199254740993}]}},
+    "b": {"panel_type": "table", "data": {"columns": [{"name": "id", "data_type": "INT8"}], "rows": [{"id": 2}]}},
+    "c": {"panel_type": "card", "data": {"columns": [{"name": "id", "data_type": "INT8"}], "rows": [{"id": 9007199254740993}]}},
+    "d": {"panel_type": "text"}
+  }
+}`)
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsCompressed(compressed) || IsCompressed(data) {
+		t.Fatal("expected only the compressed snapshot to be detected as compressed")
+	}
+
+	// the columns shared by all panels and the rows shared by a and c are deduplicated
+	deduped, err := decodeSnapshotMap(decodeZstd(t, compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared, _ := deduped[sharedDataKey].(map[string]any); len(shared) != 2 {
+		t.Errorf("expected 2 shared values, got %v", deduped[sharedDataKey])
+	}
+
+	decompressed, err := Decompress(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, got map[string]any
+	_ = json.Unmarshal(data, &expected)
+	if err := json.Unmarshal(decompressed, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("decompressed snapshot does not match the original:\n%s", decompressed)
+	}
+	// numbers are preserved exactly
+	if !json.Valid(decompressed) || !bytes.Contains(decompressed, []byte("9007199254740993")) {
+		t.Errorf("expected large integers to be preserved, got %s", decompressed)
+	}
+
+	if unchanged, err := Decompress(data); err != nil || string(unchanged) != string(data) {
+		t.Errorf("expected an uncompressed snapshot to be returned unchanged, got %v", err)
+	}
+}
+
+func decodeZstd(t *testing.T, data []byte) []byte {
+	t.Helper()
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoder.Close()
+	res, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
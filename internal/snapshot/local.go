@@ -0,0 +1,269 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// Summary is the summary of a snapshot file in a local snapshot directory
+type Summary struct {
+	Path         string            `json:"path"`
+	Resource     string            `json:"resource"`
+	Mod          string            `json:"mod"`
+	ResourceType string            `json:"resource_type"`
+	Title        string            `json:"title,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	Size         int64             `json:"size"`
+}
+
+// Details is the content of a snapshot file, as shown by `powerpipe snapshot show`
+type Details struct {
+	*Summary
+	SchemaVersion string            `json:"schema_version"`
+	SearchPath    []string          `json:"search_path,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+	Inputs        map[string]any    `json:"inputs,omitempty"`
+	// the number of panels of each type
+	Panels map[string]int `json:"panels"`
+}
+
+// snapshotFile is the subset of the snapshot json read when listing snapshots
+// (steampipeconfig.SteampipeSnapshot cannot be unmarshalled as its panels are interfaces)
+type snapshotFile struct {
+	SchemaVersion string                            `json:"schema_version"`
+	Panels        map[string]snapshotPanel          `json:"panels"`
+	Inputs        map[string]any                    `json:"inputs"`
+	Variables     map[string]string                 `json:"variables"`
+	SearchPath    []string                          `json:"search_path"`
+	StartTime     time.Time                         `json:"start_time"`
+	EndTime       time.Time                         `json:"end_time"`
+	Layout        *steampipeconfig.SnapshotTreeNode `json:"layout"`
+	Metadata      struct {
+		Tags map[string]string `json:"tags"`
+	} `json:"metadata"`
+}
+
+type snapshotPanel struct {
+	Title     string `json:"title"`
+	PanelType string `json:"panel_type"`
+}
+
+// Filter selects the snapshots returned by List
+type Filter struct {
+	// the mod name
+	Mod string
+	// a pattern matched against the full name, unqualified name and short name of the snapshot resource
+	Resource string
+	// the tags the snapshot must have
+	Tags map[string]string
+	// the time range the snapshot start time must fall in
+	Since time.Time
+	Until time.Time
+}
+
+// Matches returns whether the snapshot satisfies the filter
+func (f Filter) Matches(s *Summary) bool {
+	if f.Mod != "" && f.Mod != s.Mod {
+		return false
+	}
+	if f.Resource != "" && !matchesResource(f.Resource, s.Resource) {
+		return false
+	}
+	for key, value := range f.Tags {
+		if tagValue, ok := s.Tags[key]; !ok || tagValue != value {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && s.StartTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && s.StartTime.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Empty returns whether the filter matches every snapshot
+func (f Filter) Empty() bool {
+	return f.Mod == "" && f.Resource == "" && len(f.Tags) == 0 && f.Since.IsZero() && f.Until.IsZero()
+}
+
+// matchesResource returns whether the pattern matches the resource full name, e.g. aws_insights.dashboard.vpc,
+// unqualified name, e.g. dashboard.vpc, or short name, e.g. vpc
+func matchesResource(pattern, resource string) bool {
+	parts := strings.Split(resource, ".")
+	names := []string{resource}
+	if len(parts) == 3 {
+		names = append(names, strings.Join(parts[1:], "."), parts[2])
+	}
+	for _, name := range names {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the summaries of the snapshot files in the directory which match the filter, most recent first
+func List(dir string, filter Filter) ([]*Summary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Summary
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != localconstants.SnapshotExtension {
+			continue
+		}
+		summary, _, err := read(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			// skip files which are not snapshots
+			continue
+		}
+		if filter.Matches(summary) {
+			res = append(res, summary)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].StartTime.After(res[j].StartTime)
+	})
+	return res, nil
+}
+
+// Load returns the details of a snapshot file
+func Load(filePath string) (*Details, error) {
+	summary, file, err := read(filePath)
+	if err != nil {
+		return nil, err
+	}
+	panels := make(map[string]int)
+	for _, panel := range file.Panels {
+		panels[panel.PanelType]++
+	}
+	return &Details{
+		Summary:       summary,
+		SchemaVersion: file.SchemaVersion,
+		SearchPath:    file.SearchPath,
+		Variables:     file.Variables,
+		Inputs:        file.Inputs,
+		Panels:        panels,
+	}, nil
+}
+
+// Resolve returns the path of a snapshot given either as a path or as the name of a file in the snapshot directory
+func Resolve(dir, name string) (string, error) {
+	candidates := []string{name}
+	if !filepath.IsAbs(name) && !strings.ContainsRune(name, filepath.Separator) {
+		candidates = append([]string{filepath.Join(dir, name)}, candidates...)
+	}
+	for _, candidate := range candidates {
+		for _, p := range []string{candidate, candidate + localconstants.SnapshotExtension} {
+			if info, err := os.Stat(p); err == nil && !info.IsDir() {
+				return p, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("snapshot '%s' not found in %s", name, dir)
+}
+
+func read(filePath string) (*Summary, *snapshotFile, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("%s is not a valid snapshot: %w", filePath, err)
+	}
+	if file.Layout == nil || file.Layout.Name == "" {
+		return nil, nil, fmt.Errorf("%s is not a valid snapshot: no layout", filePath)
+	}
+
+	summary := &Summary{
+		Path:         filePath,
+		Resource:     snapshotResource(filePath, file.Layout.Name),
+		ResourceType: file.Layout.NodeType,
+		Title:        file.Panels[file.Layout.Name].Title,
+		Tags:         file.Metadata.Tags,
+		StartTime:    file.StartTime,
+		EndTime:      file.EndTime,
+		Size:         info.Size(),
+	}
+	if parts := strings.Split(summary.Resource, "."); len(parts) == 3 {
+		summary.Mod = parts[0]
+	}
+	return summary, &file, nil
+}
+
+// snapshot files are named <resource>.<timestamp>.pps - see export.GenerateDefaultExportFileName
+var fileNameRegex = regexp.MustCompile(`^(.+)\.\d{8}T\d{6}` + regexp.QuoteMeta(localconstants.SnapshotExtension) + `$`)
+
+// snapshotResource returns the name of the resource a snapshot was taken of - taken from the file name if possible,
+// as the snapshot layout of a named query is a wrapper dashboard, e.g. custom.dashboard.sql_fvqttiup
+func snapshotResource(filePath, layoutName string) string {
+	if match := fileNameRegex.FindStringSubmatch(filepath.Base(filePath)); match != nil && len(strings.Split(match[1], ".")) == 3 {
+		return match[1]
+	}
+	return layoutName
+}
+
+var relativeTimeRegex = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// the layouts accepted for absolute times
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// ParseTime parses a time filter - either a time relative to now, e.g. 24h or 7d, meaning that long ago,
+// or a timestamp or date, e.g. 2024-01-31
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if match := relativeTimeRegex.FindStringSubmatch(value); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		unit := map[string]time.Duration{
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[match[2]]
+		return now.Add(-time.Duration(count) * unit), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' - must be a duration such as '7d' or a date such as '2024-01-31'", value)
+}
+
+// ParseUntilTime parses the end of a time filter - as ParseTime, except that a date includes the whole of that day
+func ParseUntilTime(value string, now time.Time) (time.Time, error) {
+	t, err := ParseTime(value, now)
+	if err != nil {
+		return t, err
+	}
+	if _, err := time.Parse("2006-01-02", strings.TrimSpace(value)); err == nil {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
+
+// FileName returns the name of the snapshot file
+func (s *Summary) FileName() string {
+	return filepath.Base(s.Path)
+}
@@ -0,0 +1,90 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestSnapshot(t *testing.T, dir, fileName, layoutName, panelType, startTime string) {
+	t.Helper()
+	content := `{
+  "schema_version": "20240607",
+  "panels": {"` + layoutName + `": {"title": "Test", "panel_type": "` + panelType + `"}},
+  "start_time": "` + startTime + `",
+  "end_time": "` + startTime + `",
+  "layout": {"name": "` + layoutName + `", "panel_type": "` + panelType + `"}
+}`
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSnapshot(t, dir, "aws_insights.dashboard.vpc.20240101T090000.pps", "aws_insights.dashboard.vpc", "dashboard", "2024-01-01T09:00:00Z")
+	writeTestSnapshot(t, dir, "aws_compliance.benchmark.cis_v300.20240201T090000.pps", "aws_compliance.benchmark.cis_v300", "benchmark", "2024-02-01T09:00:00Z")
+	writeTestSnapshot(t, dir, "local.query.users.20240301T090000.pps", "custom.dashboard.sql_abcd", "dashboard", "2024-03-01T09:00:00Z")
+	if err := os.WriteFile(filepath.Join(dir, "invalid.pps"), []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		filter   Filter
+		expected []string
+	}{
+		"all":           {Filter{}, []string{"local.query.users", "aws_compliance.benchmark.cis_v300", "aws_insights.dashboard.vpc"}},
+		"mod":           {Filter{Mod: "aws_insights"}, []string{"aws_insights.dashboard.vpc"}},
+		"short name":    {Filter{Resource: "cis_*"}, []string{"aws_compliance.benchmark.cis_v300"}},
+		"unqualified":   {Filter{Resource: "query.users"}, []string{"local.query.users"}},
+		"since":         {Filter{Since: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}, []string{"local.query.users", "aws_compliance.benchmark.cis_v300"}},
+		"until":         {Filter{Until: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}, []string{"aws_insights.dashboard.vpc"}},
+		"none matching": {Filter{Mod: "azure_compliance"}, nil},
+	}
+	for name, test := range tests {
+		summaries, err := List(dir, test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(summaries) != len(test.expected) {
+			t.Errorf("%s: expected %d snapshots, got %d", name, len(test.expected), len(summaries))
+			continue
+		}
+		for i, s := range summaries {
+			if s.Resource != test.expected[i] {
+				t.Errorf("%s: expected snapshot %d to be %s, got %s", name, i, test.expected[i], s.Resource)
+			}
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"2024-01-31T09:00:00Z": time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		got, err := ParseTime(value, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(expected) {
+			t.Errorf("ParseTime(%s): expected %s, got %s", value, expected, got)
+		}
+	}
+
+	until, err := ParseUntilTime("2024-01-31", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2024, 1, 31, 23, 59, 59, 0, time.Local); !until.Equal(expected) {
+		t.Errorf("ParseUntilTime: expected %s, got %s", expected, until)
+	}
+
+	if _, err := ParseTime("last week", now); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
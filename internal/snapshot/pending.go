@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/filepaths"
+)
+
+// the directory in the internal directory of the install dir in which snapshots which failed to upload are queued
+const pendingDirName = "pending_snapshots"
+
+// PendingSnapshot is a snapshot which could not be uploaded to Turbot Pipes, queued to be uploaded later
+type PendingSnapshot struct {
+	// the Turbot Pipes workspace the snapshot is uploaded to
+	Workspace string                      `json:"workspace"`
+	Request   *createPipesSnapshotRequest `json:"request"`
+	QueuedAt  time.Time                   `json:"queued_at"`
+	// the number of failed uploads, and the error of the last
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	// the path of the queued snapshot
+	Path string `json:"-"`
+}
+
+// QueuedError is returned when a snapshot could not be uploaded to Turbot Pipes and has been queued
+type QueuedError struct {
+	Err error
+	// the path of the queued snapshot
+	Path string
+}
+
+func (e *QueuedError) Error() string {
+	return fmt.Sprintf("%s - the snapshot has been queued in %s", e.Err.Error(), e.Path)
+}
+
+func (e *QueuedError) Unwrap() error {
+	return e.Err
+}
+
+// PushResult is the result of uploading a queued snapshot
+type PushResult struct {
+	Path      string `json:"path"`
+	Workspace string `json:"workspace"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PendingDir returns the directory in which snapshots which failed to upload are queued
+func PendingDir() string {
+	return filepath.Join(filepaths.EnsureInternalDir(), pendingDirName)
+}
+
+// queuePending writes the snapshot request to the queue directory, returning the path of the queued snapshot
+func queuePending(dir, workspace string, req *createPipesSnapshotRequest, uploadErr error, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	pending := &PendingSnapshot{
+		Workspace: workspace,
+		Request:   req,
+		QueuedAt:  now.UTC(),
+		Attempts:  1,
+		LastError: uploadErr.Error(),
+	}
+	// name the file so the queue is ordered by the time the snapshots were queued
+	name := fmt.Sprintf("%s.%s.json", now.UTC().Format("20060102T150405.000000000"), strings.ReplaceAll(workspace, "/", "_"))
+	pending.Path = filepath.Join(dir, name)
+	return pending.Path, pending.save()
+}
+
+func (p *PendingSnapshot) save() error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	// the snapshot may contain sensitive data, so is only readable by the user
+	return os.WriteFile(p.Path, data, 0600)
+}
+
+// ListPending returns the snapshots queued in the directory, oldest first
+func ListPending(dir string) ([]*PendingSnapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res []*PendingSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pending := &PendingSnapshot{}
+		if err := json.Unmarshal(data, pending); err != nil {
+			return nil, fmt.Errorf("%s is not a valid queued snapshot: %w", path, err)
+		}
+		if pending.Workspace == "" || pending.Request == nil {
+			return nil, fmt.Errorf("%s is not a valid queued snapshot", path)
+		}
+		pending.Path = path
+		res = append(res, pending)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].QueuedAt.Before(res[j].QueuedAt) })
+	return res, nil
+}
+
+// PushPending uploads the snapshots queued in the directory, oldest first. Uploaded snapshots are removed from the
+// queue - snapshots which fail to upload remain queued, with the error recorded
+func PushPending(ctx context.Context, dir string) ([]*PushResult, error) {
+	queued, err := ListPending(dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*PushResult, 0, len(queued))
+	for _, pending := range queued {
+		result := &PushResult{Path: pending.Path, Workspace: pending.Workspace, Title: pending.Request.Title}
+		res = append(res, result)
+
+		snapshotURL, err := createInPipes(ctx, pending.Workspace, pending.Request)
+		if err != nil {
+			result.Error = err.Error()
+			pending.Attempts++
+			pending.LastError = err.Error()
+			if err := pending.save(); err != nil {
+				return res, err
+			}
+			continue
+		}
+		result.URL = snapshotURL
+		notifyPipesUpload(ctx, pending.Workspace, pending.Request, snapshotURL)
+		if err := os.Remove(pending.Path); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// pushPendingMessage uploads the queued snapshots, returning a message listing those which were uploaded
+func pushPendingMessage(ctx context.Context) string {
+	results, err := PushPending(ctx, PendingDir())
+	if err != nil {
+		slog.Warn("failed to upload queued snapshots", "error", err)
+	}
+	var b strings.Builder
+	for _, r := range results {
+		if r.Error != "" {
+			slog.Warn("failed to upload queued snapshot", "path", r.Path, "error", r.Error)
+			continue
+		}
+		fmt.Fprintf(&b, "Queued snapshot '%s' uploaded to %s\n", r.Title, r.URL)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+func TestPushPending(t *testing.T) {
+	available := false
+	var uploads int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v0/identity/acme":
+			_, _ = w.Write([]byte(`{"id": "o_1", "handle": "acme", "type": "org"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v0/org/acme/workspace/prod/snapshot":
+			if !available {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "snap_1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = server.Client().Transport
+	defer func() { http.DefaultClient.Transport = transport }()
+	serverURL, _ := url.Parse(server.URL)
+	viper.Set(constants.ArgPipesHost, serverURL.Host)
+	viper.Set(constants.ArgPipesToken, "tpt_test")
+	defer viper.Reset()
+
+	ctx := context.Background()
+	req := &createPipesSnapshotRequest{Title: "CIS v3.0.0", Visibility: VisibilityWorkspace}
+	_, err := createInPipes(ctx, "acme/prod", req)
+	if !isRetryable(err) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := queuePending(dir, "acme/prod", req, err, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the snapshot remains queued while Pipes is unavailable
+	results, err := PushPending(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, _ := ListPending(dir)
+	if len(results) != 1 || results[0].Error == "" || len(queued) != 1 || queued[0].Attempts != 2 {
+		t.Fatalf("expected the snapshot to remain queued, got %+v", results)
+	}
+
+	available = true
+	results, err = PushPending(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !strings.HasSuffix(results[0].URL, "/org/acme/workspace/prod/snapshot/snap_1") || uploads != 1 {
+		t.Fatalf("expected the snapshot to be uploaded, got %+v", results[0])
+	}
+	if queued, _ := ListPending(dir); len(queued) != 0 {
+		t.Errorf("expected the queue to be empty, got %d snapshots", len(queued))
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"server error":  {&pipesAPIError{StatusCode: http.StatusBadGateway}, true},
+		"rate limited":  {&pipesAPIError{StatusCode: http.StatusTooManyRequests}, true},
+		"bad request":   {&pipesAPIError{StatusCode: http.StatusBadRequest}, false},
+		"network error": {&url.Error{Op: "Post", URL: "https://pipes.turbot.com", Err: errors.New("connection refused")}, true},
+		"other error":   {errors.New("invalid snapshot"), false},
+	}
+	for name, test := range tests {
+		if got := isRetryable(test.err); got != test.want {
+			t.Errorf("%s: expected %v, got %v", name, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/pipes"
+)
+
+// the visibility of a snapshot uploaded to Turbot Pipes
+const (
+	// visible to the members of the workspace
+	VisibilityWorkspace = "workspace"
+	// visible to the members of the org which owns the workspace
+	VisibilityOrg = "org"
+	// visible to anyone with the snapshot link
+	VisibilityAnyoneWithLink = "anyone_with_link"
+)
+
+// ValidateShareSettings checks the snapshot-visibility, snapshot-expiry and snapshot-webhook config are valid
+func ValidateShareSettings() error {
+	switch visibility := viper.GetString(localconstants.ArgSnapshotVisibility); visibility {
+	case "", VisibilityWorkspace, VisibilityOrg, VisibilityAnyoneWithLink:
+	default:
+		return fmt.Errorf("invalid %s '%s' - must be '%s', '%s' or '%s'", localconstants.ArgSnapshotVisibility, visibility, VisibilityWorkspace, VisibilityOrg, VisibilityAnyoneWithLink)
+	}
+	if _, err := expiryFromConfig(time.Now()); err != nil {
+		return err
+	}
+	return validateWebhook()
+}
+
+// visibilityFromConfig returns the visibility of an uploaded snapshot - the snapshot-visibility config if set,
+// otherwise anyone with the link for a shared snapshot and the workspace members for any other
+func visibilityFromConfig(share bool) string {
+	if visibility := viper.GetString(localconstants.ArgSnapshotVisibility); visibility != "" {
+		return visibility
+	}
+	if share {
+		return VisibilityAnyoneWithLink
+	}
+	return VisibilityWorkspace
+}
+
+// expiryFromConfig returns the expiry time of an uploaded snapshot set by the snapshot-expiry config, or nil if
+// the snapshot does not expire
+func expiryFromConfig(now time.Time) (*time.Time, error) {
+	expiry := viper.GetString(localconstants.ArgSnapshotExpiry)
+	if expiry == "" {
+		return nil, nil
+	}
+	age, err := parseAge(localconstants.ArgSnapshotExpiry, expiry)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(age).UTC()
+	return &expiresAt, nil
+}
+
+// createPipesSnapshotRequest is the request to create a workspace snapshot - the pipes sdk request has no expiry, so
+// the snapshot is created with this request rather than the sdk
+type createPipesSnapshotRequest struct {
+	Data       *steampipecloud.WorkspaceSnapshotData `json:"data"`
+	Tags       map[string]string                     `json:"tags,omitempty"`
+	Title      string                                `json:"title,omitempty"`
+	Visibility string                                `json:"visibility"`
+	ExpiresAt  *time.Time                            `json:"expires_at,omitempty"`
+}
+
+// uploadToPipes uploads the snapshot to the Turbot Pipes workspace of the snapshot location, with the configured
+// visibility and expiry, returning the url of the snapshot. If Turbot Pipes cannot be reached, the snapshot is queued
+// to be uploaded later and a *QueuedError is returned
+func uploadToPipes(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot, share bool) (string, error) {
+	workspace := viper.GetString(constants.ArgSnapshotLocation)
+	req, err := newPipesSnapshotRequest(snap, share)
+	if err != nil {
+		return "", err
+	}
+	snapshotURL, err := createInPipes(ctx, workspace, req)
+	if err != nil && isRetryable(err) {
+		pendingPath, queueErr := queuePending(PendingDir(), workspace, req, err, time.Now())
+		if queueErr != nil {
+			return "", fmt.Errorf("%w (failed to queue the snapshot: %s)", err, queueErr.Error())
+		}
+		return "", &QueuedError{Err: err, Path: pendingPath}
+	}
+	if err != nil {
+		return "", err
+	}
+	notifyPipesUpload(ctx, workspace, req, snapshotURL)
+	return snapshotURL, nil
+}
+
+func newPipesSnapshotRequest(snap *steampipeconfig.SteampipeSnapshot, share bool) (*createPipesSnapshotRequest, error) {
+	expiresAt, err := expiryFromConfig(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	tags, err := TagsFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	data, err := snap.AsCloudSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	// strip verbose/sensitive fields
+	if err := stripSnapshot(data); err != nil {
+		return nil, err
+	}
+	return &createPipesSnapshotRequest{
+		Data:       data,
+		Tags:       tags,
+		Title:      snapshotTitle(snap),
+		Visibility: visibilityFromConfig(share),
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// createInPipes creates the snapshot in the Turbot Pipes workspace, returning its url
+func createInPipes(ctx context.Context, workspace string, req *createPipesSnapshotRequest) (string, error) {
+	identityHandle, workspaceHandle, ok := strings.Cut(workspace, "/")
+	if !ok {
+		return "", fmt.Errorf("failed to resolve the identity and workspace handles from workspace %s", workspace)
+	}
+	host, token, err := pipes.ForWorkspace(ctx, workspace)
+	if err != nil {
+		return "", err
+	}
+	identity, resp, err := pipes.NewClient(host, token).Identities.Get(ctx, identityHandle).Execute()
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusMultipleChoices {
+			err = &pipesAPIError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		return "", fmt.Errorf("failed to get Turbot Pipes identity %s: %w", identityHandle, err)
+	}
+	if req.Visibility == VisibilityOrg && identity.Type != "org" {
+		return "", fmt.Errorf("%s '%s' is only supported for org workspaces - %s is a %s workspace", localconstants.ArgSnapshotVisibility, VisibilityOrg, workspace, identity.Type)
+	}
+
+	slog.Debug("Uploading snapshot", "title", req.Title, "visibility", req.Visibility, "expires_at", req.ExpiresAt)
+	snapshotPath := fmt.Sprintf("/%s/%s/workspace/%s/snapshot", identity.Type, url.PathEscape(identityHandle), url.PathEscape(workspaceHandle))
+	var created steampipecloud.WorkspaceSnapshot
+	if err := pipesRequest(ctx, host, token, http.MethodPost, snapshotPath, req, &created); err != nil {
+		return "", fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+	return fmt.Sprintf("https://%s/%s/%s/workspace/%s/snapshot/%s",
+		host,
+		identity.Type,
+		identityHandle,
+		workspaceHandle,
+		created.Id), nil
+}
+
+// snapshotTitle returns the title of an uploaded snapshot - the snapshot-title config if set, otherwise the title of
+// the snapshot, falling back to the name of its root resource
+func snapshotTitle(snap *steampipeconfig.SteampipeSnapshot) string {
+	if title := viper.GetString(constants.ArgSnapshotTitle); title != "" {
+		return title
+	}
+	if snap.Title != "" {
+		return snap.Title
+	}
+	return snap.FileNameRoot
+}
+
+// PipesSnapshotRef identifies a snapshot in a Turbot Pipes workspace
+type PipesSnapshotRef struct {
+	// the Turbot Pipes host of a snapshot url - if empty, the host configured for the workspace is used
+	Host string
+	// user or org - if empty, it is resolved from the identity
+	IdentityType string
+	Identity     string
+	Workspace    string
+	SnapshotId   string
+}
+
+// ParsePipesSnapshotRef parses a snapshot url, as returned when the snapshot is uploaded, or a reference of the form
+// {identity}/{workspace}/{snapshot id}
+func ParsePipesSnapshotRef(ref string) (*PipesSnapshotRef, error) {
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" {
+		// /{user|org}/{identity}/workspace/{workspace}/snapshot/{snapshot id}
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) != 6 || parts[2] != "workspace" || parts[4] != "snapshot" {
+			return nil, fmt.Errorf("'%s' is not a Turbot Pipes snapshot url", ref)
+		}
+		return &PipesSnapshotRef{Host: u.Host, IdentityType: parts[0], Identity: parts[1], Workspace: parts[3], SnapshotId: parts[5]}, nil
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid snapshot '%s' - specify the snapshot url or {identity}/{workspace}/{snapshot id}", ref)
+	}
+	return &PipesSnapshotRef{Identity: parts[0], Workspace: parts[1], SnapshotId: parts[2]}, nil
+}
+
+// Unshare revokes the shared link of a Turbot Pipes snapshot, restricting its visibility to the workspace members
+func Unshare(ctx context.Context, ref *PipesSnapshotRef) error {
+	host, token, err := pipes.ForWorkspace(ctx, ref.Identity+"/"+ref.Workspace)
+	if ref.Host != "" {
+		host = ref.Host
+		token, err = pipes.TokenForHost(ctx, host)
+	}
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return error_helpers.MissingCloudTokenError()
+	}
+	client := pipes.NewClient(host, token)
+	identityType := ref.IdentityType
+	if identityType == "" {
+		identity, _, err := client.Identities.Get(ctx, ref.Identity).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to get Turbot Pipes identity %s: %w", ref.Identity, err)
+		}
+		identityType = identity.Type
+	}
+
+	visibility := VisibilityWorkspace
+	req := steampipecloud.UpdateWorkspaceSnapshotRequest{Visibility: &visibility}
+	switch identityType {
+	case "user":
+		_, _, err = client.UserWorkspaceSnapshots.Update(ctx, ref.Identity, ref.Workspace, ref.SnapshotId).Request(req).Execute()
+	case "org":
+		_, _, err = client.OrgWorkspaceSnapshots.Update(ctx, ref.Identity, ref.Workspace, ref.SnapshotId).Request(req).Execute()
+	default:
+		return fmt.Errorf("unknown Turbot Pipes identity type '%s'", identityType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unshare snapshot %s: %w", ref.SnapshotId, err)
+	}
+	return nil
+}
+
+// pipesRequest sends a json request to the api of the Turbot Pipes host, decoding the response into res
+func pipesRequest(ctx context.Context, host, token, method, apiPath string, body, res any) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	apiURL := fmt.Sprintf("https://%s/api/v0%s", host, apiPath)
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &pipesAPIError{StatusCode: resp.StatusCode, Status: resp.Status}
+		var detail struct {
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(respBody, &detail) == nil {
+			apiErr.Detail = detail.Detail
+		}
+		return apiErr
+	}
+	return json.Unmarshal(respBody, res)
+}
+
+// pipesAPIError is an error response from the Turbot Pipes api
+type pipesAPIError struct {
+	StatusCode int
+	Status     string
+	Detail     string
+}
+
+func (e *pipesAPIError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("the Turbot Pipes api returned %s: %s", e.Status, e.Detail)
+	}
+	return fmt.Sprintf("the Turbot Pipes api returned %s", e.Status)
+}
+
+// isRetryable returns whether a failed request to Turbot Pipes may succeed if retried, i.e. Turbot Pipes could not be
+// reached, was unavailable or rate limited the request
+func isRetryable(err error) bool {
+	var apiErr *pipesAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,46 @@
+package snapshot
+
+import (
+	"testing"
+)
+
+func TestParsePipesSnapshotRef(t *testing.T) {
+	tests := map[string]struct {
+		ref     string
+		want    PipesSnapshotRef
+		wantErr bool
+	}{
+		"url": {
+			ref:  "https://pipes.turbot.com/org/acme/workspace/prod/snapshot/snap_123",
+			want: PipesSnapshotRef{Host: "pipes.turbot.com", IdentityType: "org", Identity: "acme", Workspace: "prod", SnapshotId: "snap_123"},
+		},
+		"id": {
+			ref:  "jane/dev/snap_456",
+			want: PipesSnapshotRef{Identity: "jane", Workspace: "dev", SnapshotId: "snap_456"},
+		},
+		"dashboard url": {
+			ref:     "https://pipes.turbot.com/org/acme/workspace/prod/dashboard/aws_insights.dashboard.vpc",
+			wantErr: true,
+		},
+		"workspace": {
+			ref:     "jane/dev",
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		got, err := ParsePipesSnapshotRef(test.ref)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			continue
+		}
+		if *got != test.want {
+			t.Errorf("%s: expected %+v, got %+v", name, test.want, *got)
+		}
+	}
+}
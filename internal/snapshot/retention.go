@@ -0,0 +1,118 @@
+package snapshot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// RetentionPolicy determines which snapshots are removed from a local snapshot directory when it is pruned
+type RetentionPolicy struct {
+	// remove snapshots older than this
+	MaxAge time.Duration
+	// keep at most this many snapshots of each dashboard, benchmark or query
+	MaxCount int
+	// remove the oldest snapshots once the total size of the snapshots exceeds this many bytes
+	MaxSize int64
+}
+
+// Empty returns whether the policy retains every snapshot
+func (p RetentionPolicy) Empty() bool {
+	return p == RetentionPolicy{}
+}
+
+// Expired returns the snapshots the policy removes. The summaries must be ordered most recent first, as returned by List
+func (p RetentionPolicy) Expired(summaries []*Summary, now time.Time) []*Summary {
+	var expired []*Summary
+	counts := make(map[string]int)
+	var totalSize int64
+	for _, s := range summaries {
+		counts[s.Resource]++
+		switch {
+		case p.MaxAge > 0 && s.StartTime.Before(now.Add(-p.MaxAge)):
+			expired = append(expired, s)
+		case p.MaxCount > 0 && counts[s.Resource] > p.MaxCount:
+			expired = append(expired, s)
+		case p.MaxSize > 0 && totalSize+s.Size > p.MaxSize:
+			expired = append(expired, s)
+			// once the size limit is reached, all older snapshots are removed
+			totalSize = p.MaxSize
+		default:
+			totalSize += s.Size
+		}
+	}
+	return expired
+}
+
+// Prune removes the snapshots in the directory which the policy does not retain, returning the removed snapshots.
+// If dryRun is set, the snapshots are returned but not removed
+func Prune(dir string, policy RetentionPolicy, dryRun bool, now time.Time) ([]*Summary, error) {
+	if policy.Empty() {
+		return nil, nil
+	}
+	summaries, err := List(dir, Filter{})
+	if err != nil {
+		return nil, err
+	}
+	expired := policy.Expired(summaries, now)
+	if dryRun {
+		return expired, nil
+	}
+	for i, s := range expired {
+		if err := Remove(s.Path); err != nil {
+			return expired[:i], err
+		}
+	}
+	return expired, nil
+}
+
+// RetentionPolicyFromConfig returns the retention policy set by the snapshot-max-age, snapshot-max-count and
+// snapshot-max-size config
+func RetentionPolicyFromConfig() (RetentionPolicy, error) {
+	var policy RetentionPolicy
+	var err error
+	if maxAge := viper.GetString(localconstants.ArgSnapshotMaxAge); maxAge != "" {
+		if policy.MaxAge, err = ParseRetentionAge(maxAge); err != nil {
+			return policy, err
+		}
+	}
+	if policy.MaxCount = viper.GetInt(localconstants.ArgSnapshotMaxCount); policy.MaxCount < 0 {
+		return policy, fmt.Errorf("invalid %s %d - must not be negative", localconstants.ArgSnapshotMaxCount, policy.MaxCount)
+	}
+	if maxSize := viper.GetString(localconstants.ArgSnapshotMaxSize); maxSize != "" {
+		size, err := humanize.ParseBytes(maxSize)
+		if err != nil {
+			return policy, fmt.Errorf("invalid %s '%s' - must be a size such as '500MB' or '2GiB'", localconstants.ArgSnapshotMaxSize, maxSize)
+		}
+		policy.MaxSize = int64(size)
+	}
+	return policy, nil
+}
+
+var ageRegex = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// ParseRetentionAge parses a maximum snapshot age - a number of hours, days or weeks, e.g. 12h, 30d or 4w
+func ParseRetentionAge(value string) (time.Duration, error) {
+	return parseAge(localconstants.ArgSnapshotMaxAge, value)
+}
+
+// parseAge parses a number of hours, days or weeks set by the named config
+func parseAge(name, value string) (time.Duration, error) {
+	match := ageRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid %s '%s' - must be a number of hours, days or weeks, e.g. '30d'", name, value)
+	}
+	count, _ := strconv.Atoi(match[1])
+	unit := map[string]time.Duration{
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}[match[2]]
+	return time.Duration(count) * unit, nil
+}
@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	// most recent first, as returned by List
+	summaries := []*Summary{
+		{Path: "vpc.3", Resource: "dashboard.vpc", StartTime: now.Add(-1 * day), Size: 100},
+		{Path: "cis.2", Resource: "benchmark.cis", StartTime: now.Add(-2 * day), Size: 100},
+		{Path: "vpc.2", Resource: "dashboard.vpc", StartTime: now.Add(-3 * day), Size: 100},
+		{Path: "vpc.1", Resource: "dashboard.vpc", StartTime: now.Add(-10 * day), Size: 100},
+		{Path: "cis.1", Resource: "benchmark.cis", StartTime: now.Add(-20 * day), Size: 100},
+	}
+
+	tests := map[string]struct {
+		policy   RetentionPolicy
+		expected []string
+	}{
+		"max age":      {RetentionPolicy{MaxAge: 7 * day}, []string{"vpc.1", "cis.1"}},
+		"max count":    {RetentionPolicy{MaxCount: 1}, []string{"vpc.2", "vpc.1", "cis.1"}},
+		"max size":     {RetentionPolicy{MaxSize: 250}, []string{"vpc.2", "vpc.1", "cis.1"}},
+		"combined":     {RetentionPolicy{MaxAge: 15 * day, MaxCount: 2, MaxSize: 1000}, []string{"vpc.1", "cis.1"}},
+		"count + size": {RetentionPolicy{MaxCount: 2, MaxSize: 300}, []string{"vpc.1", "cis.1"}},
+		"empty":        {RetentionPolicy{}, nil},
+	}
+	for name, test := range tests {
+		expired := test.policy.Expired(summaries, now)
+		if len(expired) != len(test.expected) {
+			t.Errorf("%s: expected %d expired snapshots, got %d", name, len(test.expected), len(expired))
+			continue
+		}
+		for i, s := range expired {
+			if s.Path != test.expected[i] {
+				t.Errorf("%s: expected expired snapshot %d to be %s, got %s", name, i, test.expected[i], s.Path)
+			}
+		}
+	}
+}
+
+func TestParseRetentionAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"12h": 12 * time.Hour,
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	}
+	for value, expected := range tests {
+		got, err := ParseRetentionAge(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("ParseRetentionAge(%s): expected %s, got %s", value, expected, got)
+		}
+	}
+	for _, value := range []string{"30", "1y", "-1d"} {
+		if _, err := ParseRetentionAge(value); err == nil {
+			t.Errorf("expected an error for '%s'", value)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Query selects the panels and control results returned by Search
+type Query struct {
+	// a pattern matched against the full name, unqualified name and short name of controls
+	Control string
+	// a pattern matched against the resource of control results, and the values of other panels' rows
+	ResourceID string
+	// the status of control results, e.g. alarm
+	Status string
+}
+
+// Empty returns whether the query has no criteria
+func (q Query) Empty() bool {
+	return q == Query{}
+}
+
+// Match is a snapshot panel matching a search - for a query on resource id or status, the rows are the matching rows
+type Match struct {
+	Snapshot  *Summary         `json:"snapshot"`
+	Panel     string           `json:"panel"`
+	PanelType string           `json:"panel_type"`
+	Title     string           `json:"title,omitempty"`
+	Status    string           `json:"status,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Rows      []map[string]any `json:"rows,omitempty"`
+}
+
+// searchFile is the subset of the snapshot json read when searching snapshots
+type searchFile struct {
+	Panels map[string]searchPanel `json:"panels"`
+}
+
+type searchPanel struct {
+	Title     string `json:"title"`
+	PanelType string `json:"panel_type"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	Data      *struct {
+		Rows []map[string]any `json:"rows"`
+	} `json:"data"`
+}
+
+// Search returns the panels of the snapshots in the directory which match the query - the snapshots are selected by
+// the filter, and are searched most recent first
+func Search(dir string, filter Filter, query Query) ([]*Match, error) {
+	summaries, err := List(dir, filter)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Match
+	for _, summary := range summaries {
+		data, err := ReadFile(summary.Path)
+		if err != nil {
+			return nil, err
+		}
+		var file searchFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s is not a valid snapshot: %w", summary.Path, err)
+		}
+		res = append(res, searchPanels(summary, file.Panels, query)...)
+	}
+	return res, nil
+}
+
+func searchPanels(summary *Summary, panels map[string]searchPanel, query Query) []*Match {
+	var res []*Match
+	for _, name := range sortedKeys(panels) {
+		panel := panels[name]
+		isControl := panel.PanelType == "control"
+		if query.Control != "" && (!isControl || !matchesResource(query.Control, name)) {
+			continue
+		}
+		// status only applies to control results
+		if query.Status != "" && !isControl {
+			continue
+		}
+
+		match := &Match{
+			Snapshot:  summary,
+			Panel:     name,
+			PanelType: panel.PanelType,
+			Title:     panel.Title,
+			Status:    panel.Status,
+			Error:     panel.Error,
+		}
+		var rows []map[string]any
+		if panel.Data != nil {
+			rows = panel.Data.Rows
+		}
+		if query.ResourceID == "" && query.Status == "" {
+			match.Rows = rows
+			res = append(res, match)
+			continue
+		}
+
+		for _, row := range rows {
+			if matchesRow(row, isControl, query) {
+				match.Rows = append(match.Rows, row)
+			}
+		}
+		// a control which failed to run has no results, but matches a search for errors
+		erroredControl := isControl && query.Status == "error" && query.ResourceID == "" && panel.Error != ""
+		if len(match.Rows) > 0 || erroredControl {
+			res = append(res, match)
+		}
+	}
+	return res
+}
+
+func matchesRow(row map[string]any, isControl bool, query Query) bool {
+	if query.Status != "" && fmt.Sprint(row["status"]) != query.Status {
+		return false
+	}
+	if query.ResourceID == "" {
+		return true
+	}
+	if isControl {
+		resource, _ := row["resource"].(string)
+		return matchesWildcard(query.ResourceID, resource)
+	}
+	for _, value := range row {
+		if s, ok := value.(string); ok && matchesWildcard(query.ResourceID, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWildcard returns whether the value matches the pattern, in which * matches any sequence of characters
+// (unlike path.Match, including /, as resource ids such as ARNs often contain it)
+func matchesWildcard(pattern, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == value
+	}
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, _ := regexp.MatchString(expr, value)
+	return matched
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	content := `{
+  "schema_version": "20240607",
+  "panels": {
+    "aws_compliance.benchmark.s3": {"panel_type": "benchmark"},
+    "aws_compliance.control.s3_bucket_versioning_enabled": {"panel_type": "control", "status": "complete", "data": {"rows": [
+      {"resource": "arn:aws:s3:::logs/archive", "status": "alarm", "reason": "versioning disabled"},
+      {"resource": "arn:aws:s3:::assets", "status": "ok", "reason": "versioning enabled"}
+    ]}},
+    "aws_compliance.control.s3_bucket_logging_enabled": {"panel_type": "control", "status": "error", "error": "access denied"},
+    "aws_compliance.table.buckets": {"panel_type": "table", "data": {"rows": [{"name": "arn:aws:s3:::assets"}]}}
+  },
+  "start_time": "2024-01-01T09:00:00Z",
+  "end_time": "2024-01-01T09:00:00Z",
+  "layout": {"name": "aws_compliance.benchmark.s3", "panel_type": "benchmark"}
+}`
+	if err := os.WriteFile(filepath.Join(dir, "aws_compliance.benchmark.s3.20240101T090000.pps"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		query    Query
+		expected map[string]int
+	}{
+		"control":          {Query{Control: "s3_bucket_versioning_*"}, map[string]int{"aws_compliance.control.s3_bucket_versioning_enabled": 2}},
+		"resource pattern": {Query{ResourceID: "arn:aws:s3:::logs*"}, map[string]int{"aws_compliance.control.s3_bucket_versioning_enabled": 1}},
+		"resource in table": {Query{ResourceID: "arn:aws:s3:::assets"}, map[string]int{
+			"aws_compliance.control.s3_bucket_versioning_enabled": 1,
+			"aws_compliance.table.buckets":                        1,
+		}},
+		"status":          {Query{Status: "alarm"}, map[string]int{"aws_compliance.control.s3_bucket_versioning_enabled": 1}},
+		"errored control": {Query{Status: "error"}, map[string]int{"aws_compliance.control.s3_bucket_logging_enabled": 0}},
+		"no match":        {Query{ResourceID: "arn:aws:s3:::assets", Status: "alarm"}, map[string]int{}},
+	}
+	for name, test := range tests {
+		matches, err := Search(dir, Filter{}, test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != len(test.expected) {
+			t.Errorf("%s: expected %d matches, got %d", name, len(test.expected), len(matches))
+			continue
+		}
+		for _, m := range matches {
+			rows, ok := test.expected[m.Panel]
+			if !ok || len(m.Rows) != rows {
+				t.Errorf("%s: unexpected match %s with %d rows", name, m.Panel, len(m.Rows))
+			}
+		}
+	}
+}
+
+func TestMatchesWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		expected       bool
+	}{
+		{"arn:aws:s3:::logs", "arn:aws:s3:::logs", true},
+		{"arn:aws:s3:::logs", "arn:aws:s3:::logs/archive", false},
+		{"arn:aws:s3:::logs*", "arn:aws:s3:::logs/archive", true},
+		{"*/archive", "arn:aws:s3:::logs/archive", true},
+		{"i-0[a]*", "i-0[a]123", true},
+		{"i-0[a]*", "i-0a123", false},
+	}
+	for _, test := range tests {
+		if got := matchesWildcard(test.pattern, test.value); got != test.expected {
+			t.Errorf("matchesWildcard(%s, %s) = %v, expected %v", test.pattern, test.value, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/files"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+// SignatureExtension is the extension of the detached signature written alongside a signed snapshot
+const SignatureExtension = ".asc"
+
+// ErrUnsigned is returned when verifying a snapshot which has no signature
+var ErrUnsigned = errors.New("snapshot is not signed")
+
+// SignaturePath returns the path of the detached signature of a snapshot file
+func SignaturePath(snapshotPath string) string {
+	return snapshotPath + SignatureExtension
+}
+
+// signingKeyFromConfig returns the key set by the snapshot-signing-key config, or nil if snapshots are not signed.
+// If the key is encrypted, it is decrypted with the passphrase in POWERPIPE_SNAPSHOT_SIGNING_KEY_PASSPHRASE
+func signingKeyFromConfig() (*openpgp.Entity, error) {
+	keyPath := viper.GetString(localconstants.ArgSnapshotSigningKey)
+	if keyPath == "" {
+		return nil, nil
+	}
+	keyPath, err := files.Tildefy(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	armoredKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot signing key: %w", err)
+	}
+	return parseSigningKey(armoredKey, os.Getenv(localconstants.EnvSnapshotSigningKeyPassphrase))
+}
+
+// ValidateSigningKey checks the snapshot signing key (if set) can be read and decrypted, so a bad key is reported
+// before a dashboard or benchmark is run rather than when the snapshot is written
+func ValidateSigningKey() error {
+	_, err := signingKeyFromConfig()
+	return err
+}
+
+func parseSigningKey(armoredKey []byte, passphrase string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot signing key: %w", err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			if passphrase == "" {
+				return nil, fmt.Errorf("the snapshot signing key is encrypted - set %s", localconstants.EnvSnapshotSigningKeyPassphrase)
+			}
+			if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt the snapshot signing key: %w", err)
+			}
+		}
+		return entity, nil
+	}
+	return nil, fmt.Errorf("the snapshot signing key file does not contain a private key")
+}
+
+// sign returns the armored detached signature of the data
+func sign(data []byte, key *openpgp.Entity) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, key, bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("failed to sign snapshot: %w", err)
+	}
+	return signature.Bytes(), nil
+}
+
+// Verify verifies the detached signature of a snapshot file against a keyring of trusted armored public keys,
+// returning the identity of the key which signed it
+func Verify(snapshotPath, armoredKeyRing string) (string, error) {
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return "", err
+	}
+	signature, err := os.ReadFile(SignaturePath(snapshotPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrUnsigned
+	}
+	if err != nil {
+		return "", err
+	}
+	return verifySignature(data, signature, armoredKeyRing)
+}
+
+func verifySignature(data, signature []byte, armoredKeyRing string) (string, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKeyRing))
+	if err != nil {
+		return "", fmt.Errorf("invalid trusted keys: %w", err)
+	}
+	entity, err := openpgp.CheckArmoredDetachedSignature(keyRing, bytes.NewReader(data), bytes.NewReader(signature), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	names := make([]string, 0, len(entity.Identities))
+	for name := range entity.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "unknown", nil
+	}
+	return names[0], nil
+}
+
+// Remove removes a snapshot file and its signature, if it has one
+func Remove(snapshotPath string) error {
+	if err := os.Remove(snapshotPath); err != nil {
+		return err
+	}
+	if err := os.Remove(SignaturePath(snapshotPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
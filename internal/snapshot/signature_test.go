@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func testKeys(t *testing.T, name string) (*openpgp.Entity, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var publicKey bytes.Buffer
+	w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return entity, publicKey.String()
+}
+
+func TestVerify(t *testing.T) {
+	signer, trustedKeys := testKeys(t, "compliance")
+	other, _ := testKeys(t, "other")
+
+	dir := t.TempDir()
+	data := []byte(`{"schema_version": "20240607"}` + "\n")
+	write := func(name string, data []byte, key *openpgp.Entity) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if key != nil {
+			signature, err := sign(data, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(SignaturePath(p), signature, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return p
+	}
+
+	valid := write("valid.pps", data, signer)
+	if got, err := Verify(valid, trustedKeys); err != nil || got != "compliance <compliance@example.com>" {
+		t.Errorf("expected a valid signature, got %s, %v", got, err)
+	}
+
+	modified := write("modified.pps", data, signer)
+	if err := os.WriteFile(modified, []byte(`{"schema_version": "20240101"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(modified, trustedKeys); err == nil {
+		t.Error("expected an error for a modified snapshot")
+	}
+
+	untrusted := write("untrusted.pps", data, other)
+	if _, err := Verify(untrusted, trustedKeys); err == nil {
+		t.Error("expected an error for a snapshot signed by an untrusted key")
+	}
+
+	unsigned := write("unsigned.pps", data, nil)
+	if _, err := Verify(unsigned, trustedKeys); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected ErrUnsigned, got %v", err)
+	}
+
+	if err := Remove(valid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(SignaturePath(valid)); !os.IsNotExist(err) {
+		t.Error("expected the signature to be removed with the snapshot")
+	}
+}
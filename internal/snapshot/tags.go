@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/export"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	"github.com/turbot/powerpipe/internal/objectstore"
+	"github.com/turbot/powerpipe/internal/redact"
+)
+
+// the key of the snapshot tags in the snapshot metadata
+const metadataKeyTags = "tags"
+
+// ParseTags parses snapshot tags of the form key=value
+func ParseTags(args []string) (map[string]string, error) {
+	tags := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid snapshot tag '%s' - tags must be specified as key=value", arg)
+		}
+		tags[key] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+// TagsFromConfig returns the snapshot tags set by the --snapshot-tag args
+func TagsFromConfig() (map[string]string, error) {
+	return ParseTags(viper.GetStringSlice(constants.ArgSnapshotTag))
+}
+
+// AsJson returns the snapshot json, stripped of verbose fields and with the values of sensitive columns redacted, with
+// the tags stored in the snapshot metadata
+func AsJson(snap *steampipeconfig.SteampipeSnapshot, tags map[string]string, indent bool) ([]byte, error) {
+	data, err := snap.AsCloudSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := stripSnapshot(data); err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		metadata := data.GetMetadata()
+		if metadata == nil {
+			metadata = make(map[string]any)
+		}
+		metadata[metadataKeyTags] = tags
+		data.SetMetadata(metadata)
+	}
+
+	if indent {
+		return json.MarshalIndent(data, "", "  ")
+	}
+	return json.Marshal(data)
+}
+
+// stripSnapshot strips verbose and sensitive fields from the snapshot, and redacts the values of sensitive columns
+func stripSnapshot(data *steampipecloud.WorkspaceSnapshotData) error {
+	if err := steampipeconfig.StripSnapshot(data); err != nil {
+		return err
+	}
+	redact.Current().RedactSnapshot(data)
+	return nil
+}
+
+// Exporter is the snapshot (pps) exporter - it stores the snapshot tags in the exported snapshot and, if snapshot
+// signing is configured, writes the detached signature of the snapshot alongside it
+type Exporter struct {
+	export.SnapshotExporter
+}
+
+func (e *Exporter) Export(_ context.Context, input export.ExportSourceData, filePath string) error {
+	snap, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("SnapshotExporter input must be a SteampipeSnapshot")
+	}
+	data, signature, err := encode(snap)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(filePath, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if signature != nil {
+		return export.Write(SignaturePath(filePath), bytes.NewReader(signature))
+	}
+	return nil
+}
+
+// encode returns the content of a snapshot file, with the configured snapshot tags and compression, and its
+// detached signature if snapshot signing is configured
+func encode(snap *steampipeconfig.SteampipeSnapshot) ([]byte, []byte, error) {
+	tags, err := TagsFromConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := AsJson(snap, tags, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if compressionEnabled() {
+		if data, err = Compress(data); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+
+	key, err := signingKeyFromConfig()
+	if err != nil || key == nil {
+		return data, nil, err
+	}
+	signature, err := sign(data, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, signature, nil
+}
+
+// Publish saves the snapshot to the snapshot location - if this is a Turbot Pipes workspace, the snapshot is uploaded
+// (with its tags, visibility and expiry), otherwise it is written to the object storage location or local snapshot
+// directory, with its tags stored in the snapshot
+func Publish(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot, share bool) (string, error) {
+	location := viper.GetString(constants.ArgSnapshotLocation)
+	if location == "" {
+		return "", fmt.Errorf("to share a snapshot, snapshot-location must be set")
+	}
+	if steampipeconfig.IsCloudWorkspaceIdentifier(location) {
+		snapshotURL, err := uploadToPipes(ctx, snap, share)
+		var queuedErr *QueuedError
+		if errors.As(err, &queuedErr) {
+			error_helpers.ShowWarning(fmt.Sprintf("%s - it will be uploaded with the next snapshot upload, or by 'powerpipe snapshot push --pending'", queuedErr.Error()))
+			return fmt.Sprintf("\nSnapshot queued for upload to %s\n", location), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		// Turbot Pipes is reachable, so upload any snapshots queued by previous runs
+		return fmt.Sprintf("\nSnapshot uploaded to %s\n", snapshotURL) + pushPendingMessage(ctx), nil
+	}
+
+	exporter := &Exporter{}
+	fileName := export.GenerateDefaultExportFileName(snap.FileNameRoot, exporter.FileExtension())
+	if objectstore.IsURL(location) {
+		data, signature, err := encode(snap)
+		if err != nil {
+			return "", err
+		}
+		contentType := "application/json"
+		if IsCompressed(data) {
+			contentType = "application/zstd"
+		}
+		objectURL, err := objectstore.Put(ctx, location, fileName, data, contentType)
+		if err != nil {
+			return "", err
+		}
+		if signature != nil {
+			if _, err := objectstore.Put(ctx, location, fileName+SignatureExtension, signature, "application/pgp-signature"); err != nil {
+				return "", err
+			}
+		}
+		notifyObjectUpload(ctx, snap, location, objectURL)
+		return fmt.Sprintf("\nSnapshot uploaded to %s\n", objectURL), nil
+	}
+
+	filePath := path.Join(location, fileName)
+	if err := exporter.Export(ctx, snap, filePath); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\nSnapshot saved to %s\n", filePath), nil
+}
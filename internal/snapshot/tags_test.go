@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags([]string{"env=prod", "release = 1.2", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"env": "prod", "release": "1.2", "empty": ""}
+	if len(tags) != len(expected) {
+		t.Fatalf("expected %d tags, got %d", len(expected), len(tags))
+	}
+	for k, v := range expected {
+		if tags[k] != v {
+			t.Errorf("expected tag %s to be '%s', got '%s'", k, v, tags[k])
+		}
+	}
+
+	for _, arg := range []string{"env", "=prod"} {
+		if _, err := ParseTags([]string{arg}); err == nil {
+			t.Errorf("expected an error for '%s'", arg)
+		}
+	}
+}
+
+func TestFilterMatchesTags(t *testing.T) {
+	s := &Summary{Resource: "aws_insights.dashboard.vpc", Tags: map[string]string{"env": "prod", "release": "1.2"}}
+	tests := map[string]struct {
+		tags     map[string]string
+		expected bool
+	}{
+		"no tags":       {nil, true},
+		"one tag":       {map[string]string{"env": "prod"}, true},
+		"all tags":      {map[string]string{"env": "prod", "release": "1.2"}, true},
+		"wrong value":   {map[string]string{"env": "dev"}, false},
+		"missing tag":   {map[string]string{"team": "platform"}, false},
+		"partial match": {map[string]string{"env": "prod", "release": "1.3"}, false},
+	}
+	for name, test := range tests {
+		if got := (Filter{Tags: test.tags}).Matches(s); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, got)
+		}
+	}
+}
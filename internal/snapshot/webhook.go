@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// the event type of the webhook posted when a snapshot is uploaded
+const webhookEventUploaded = "snapshot.uploaded"
+
+// WebhookEvent is posted as JSON to the --snapshot-webhook when a snapshot has been uploaded to Turbot Pipes or
+// object storage, so downstream systems can index the results
+type WebhookEvent struct {
+	Event string `json:"event"`
+	// the url of the uploaded snapshot, and the snapshot location it was uploaded to
+	URL          string            `json:"url"`
+	Location     string            `json:"location"`
+	Resource     string            `json:"resource"`
+	ResourceType string            `json:"resource_type"`
+	Mod          string            `json:"mod,omitempty"`
+	Title        string            `json:"title,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Visibility   string            `json:"visibility,omitempty"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	// the number of controls of each status, for benchmark snapshots
+	Summary *StatusSummary `json:"summary,omitempty"`
+	// the number of panels of each type
+	Panels map[string]int `json:"panels"`
+}
+
+// StatusSummary is the number of controls of each status in a benchmark snapshot
+type StatusSummary struct {
+	Alarm int `json:"alarm"`
+	Ok    int `json:"ok"`
+	Info  int `json:"info"`
+	Skip  int `json:"skip"`
+	Error int `json:"error"`
+}
+
+// the subset of the snapshot read to build the webhook event
+type webhookSnapshot struct {
+	Panels map[string]struct {
+		Title     string `json:"title"`
+		PanelType string `json:"panel_type"`
+		Summary   *struct {
+			Status *StatusSummary `json:"status"`
+		} `json:"summary"`
+	} `json:"panels"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Layout    struct {
+		Name     string `json:"name"`
+		NodeType string `json:"panel_type"`
+	} `json:"layout"`
+}
+
+// newWebhookEvent returns the webhook event for the snapshot uploaded to the url
+func newWebhookEvent(data *steampipecloud.WorkspaceSnapshotData, location, snapshotURL string, tags map[string]string, visibility string) (*WebhookEvent, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var snap webhookSnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+	event := &WebhookEvent{
+		Event:        webhookEventUploaded,
+		URL:          snapshotURL,
+		Location:     location,
+		Resource:     snap.Layout.Name,
+		ResourceType: snap.Layout.NodeType,
+		Tags:         tags,
+		Visibility:   visibility,
+		StartTime:    snap.StartTime,
+		EndTime:      snap.EndTime,
+		Panels:       map[string]int{},
+	}
+	if parts := strings.Split(event.Resource, "."); len(parts) == 3 {
+		event.Mod = parts[0]
+	}
+	for name, panel := range snap.Panels {
+		event.Panels[panel.PanelType]++
+		if name != snap.Layout.Name {
+			continue
+		}
+		event.Title = panel.Title
+		if panel.Summary != nil {
+			event.Summary = panel.Summary.Status
+		}
+	}
+	return event, nil
+}
+
+// validateWebhook validates the --snapshot-webhook, if set
+func validateWebhook() error {
+	webhookURL := viper.GetString(localconstants.ArgSnapshotWebhook)
+	if webhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s '%s' - must be an http or https url", localconstants.ArgSnapshotWebhook, webhookURL)
+	}
+	return nil
+}
+
+// notifyPipesUpload posts the webhook event for a snapshot uploaded to a Turbot Pipes workspace
+func notifyPipesUpload(ctx context.Context, workspace string, req *createPipesSnapshotRequest, snapshotURL string) {
+	if viper.GetString(localconstants.ArgSnapshotWebhook) == "" {
+		return
+	}
+	event, err := newWebhookEvent(req.Data, workspace, snapshotURL, req.Tags, req.Visibility)
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("failed to notify the snapshot webhook of the upload of %s: %s", snapshotURL, err.Error()))
+		return
+	}
+	notifyWebhook(ctx, event)
+}
+
+// notifyObjectUpload posts the webhook event for a snapshot uploaded to object storage
+func notifyObjectUpload(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot, location, objectURL string) {
+	if viper.GetString(localconstants.ArgSnapshotWebhook) == "" {
+		return
+	}
+	event, err := func() (*WebhookEvent, error) {
+		tags, err := TagsFromConfig()
+		if err != nil {
+			return nil, err
+		}
+		data, err := snap.AsCloudSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		return newWebhookEvent(data, location, objectURL, tags, "")
+	}()
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("failed to notify the snapshot webhook of the upload of %s: %s", objectURL, err.Error()))
+		return
+	}
+	notifyWebhook(ctx, event)
+}
+
+// notifyWebhook posts the event to the --snapshot-webhook, if set. The snapshot has already been uploaded, so a
+// failure is shown as a warning
+func notifyWebhook(ctx context.Context, event *WebhookEvent) {
+	webhookURL := viper.GetString(localconstants.ArgSnapshotWebhook)
+	if webhookURL == "" {
+		return
+	}
+	if err := postWebhook(ctx, webhookURL, event); err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("failed to notify the snapshot webhook of the upload of %s: %s", event.URL, err.Error()))
+	}
+}
+
+func postWebhook(ctx context.Context, webhookURL string, event *WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("snapshot webhook returned %s", resp.Status)
+	}
+	return nil
+}
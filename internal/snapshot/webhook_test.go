@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	steampipecloud "github.com/turbot/pipes-sdk-go"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestNotifyPipesUpload(t *testing.T) {
+	var got WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	viper.Set(localconstants.ArgSnapshotWebhook, server.URL)
+	defer viper.Reset()
+
+	req := &createPipesSnapshotRequest{
+		Data: &steampipecloud.WorkspaceSnapshotData{
+			StartTime: "2024-05-01T10:00:00Z",
+			EndTime:   "2024-05-01T10:01:00Z",
+			Layout:    steampipecloud.WorkspaceSnapshotDataLayout{Name: "aws_compliance.benchmark.cis_v300", PanelType: "benchmark"},
+			Panels: map[string]any{
+				"aws_compliance.benchmark.cis_v300": map[string]any{
+					"title":      "CIS v3.0.0",
+					"panel_type": "benchmark",
+					"summary":    map[string]any{"status": map[string]any{"alarm": 3, "ok": 40, "error": 1}},
+				},
+				"aws_compliance.control.cis_v300_1_1": map[string]any{"panel_type": "control"},
+				"aws_compliance.control.cis_v300_1_2": map[string]any{"panel_type": "control"},
+			},
+		},
+		Tags:       map[string]string{"env": "prod"},
+		Visibility: VisibilityWorkspace,
+	}
+	notifyPipesUpload(context.Background(), "acme/prod", req, "https://pipes.turbot.com/org/acme/workspace/prod/snapshot/snap_1")
+
+	if got.Event != webhookEventUploaded || got.Location != "acme/prod" || got.Mod != "aws_compliance" || got.Title != "CIS v3.0.0" {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	if got.Summary == nil || got.Summary.Alarm != 3 || got.Summary.Ok != 40 || got.Summary.Error != 1 {
+		t.Errorf("unexpected summary: %+v", got.Summary)
+	}
+	if got.Panels["control"] != 2 || got.Panels["benchmark"] != 1 {
+		t.Errorf("unexpected panel counts: %v", got.Panels)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	defer viper.Reset()
+	for webhookURL, wantErr := range map[string]bool{
+		"":                            false,
+		"https://hooks.acme.com/pp":   false,
+		"ftp://hooks.acme.com/pp":     true,
+		"hooks.acme.com/snapshot/new": true,
+	} {
+		viper.Set(localconstants.ArgSnapshotWebhook, webhookURL)
+		if err := validateWebhook(); (err != nil) != wantErr {
+			t.Errorf("%q: expected error %v, got %v", webhookURL, wantErr, err)
+		}
+	}
+}
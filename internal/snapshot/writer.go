@@ -0,0 +1,102 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+)
+
+// the number of snapshots which may be queued to be written
+const writeBufferSize = 100
+
+// Writer saves snapshots to the snapshot location in the background, so saving a snapshot does not delay the
+// caller (e.g. the dashboard server saving the snapshot of each completed execution)
+// a nil Writer is valid and saves nothing
+type Writer struct {
+	publish   func(context.Context, *steampipeconfig.SteampipeSnapshot) error
+	snapshots chan *steampipeconfig.SteampipeSnapshot
+	done      chan struct{}
+	// set (under the lock) when the writer is flushed - no snapshots are queued once it is set
+	flushed bool
+	lock    sync.Mutex
+}
+
+// NewWriter creates a writer which saves snapshots to the snapshot location, if --snapshot is set
+// if snapshots are not saved, return nil
+func NewWriter() *Writer {
+	if !viper.GetBool(constants.ArgSnapshot) {
+		return nil
+	}
+	return newWriter(func(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot) error {
+		message, err := Publish(ctx, snap, false)
+		if err == nil {
+			slog.Debug("saved snapshot", "message", message)
+		}
+		return err
+	})
+}
+
+func newWriter(publish func(context.Context, *steampipeconfig.SteampipeSnapshot) error) *Writer {
+	w := &Writer{
+		publish:   publish,
+		snapshots: make(chan *steampipeconfig.SteampipeSnapshot, writeBufferSize),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	// queued snapshots are still written after the server context is cancelled, so do not use it here
+	ctx := context.Background()
+	for snap := range w.snapshots {
+		if err := w.publish(ctx, snap); err != nil {
+			slog.Warn("failed to save snapshot", "snapshot", snap.FileNameRoot, "error", err)
+		}
+	}
+}
+
+// Write queues the snapshot to be saved. Write never blocks - if the snapshots cannot be saved as fast as they are
+// written and the queue is full, or the writer has been flushed, the snapshot is dropped and a warning logged
+func (w *Writer) Write(snap *steampipeconfig.SteampipeSnapshot) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.flushed {
+		slog.Warn("snapshot writer has been flushed - dropping snapshot", "snapshot", snap.FileNameRoot)
+		return
+	}
+	select {
+	case w.snapshots <- snap:
+	default:
+		slog.Warn("snapshot write queue is full - dropping snapshot", "snapshot", snap.FileNameRoot)
+	}
+}
+
+// Flush stops queueing snapshots and waits for the queued snapshots to be saved, returning the context error if the
+// context is done first (the remaining snapshots continue to be saved in the background)
+func (w *Writer) Flush(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	w.lock.Lock()
+	if !w.flushed {
+		w.flushed = true
+		close(w.snapshots)
+	}
+	w.lock.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
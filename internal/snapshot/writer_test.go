@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+)
+
+// recordingPublisher records the snapshots it publishes - if blocked is set, publishing waits until it is closed
+type recordingPublisher struct {
+	mut       sync.Mutex
+	published []string
+	blocked   chan struct{}
+}
+
+func (p *recordingPublisher) publish(_ context.Context, snap *steampipeconfig.SteampipeSnapshot) error {
+	if p.blocked != nil {
+		<-p.blocked
+	}
+	time.Sleep(10 * time.Millisecond)
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.published = append(p.published, snap.FileNameRoot)
+	return nil
+}
+
+func TestWriterFlush(t *testing.T) {
+	p := &recordingPublisher{}
+	w := newWriter(p.publish)
+	for _, name := range []string{"a", "b", "c"} {
+		w.Write(&steampipeconfig.SteampipeSnapshot{FileNameRoot: name})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the queued snapshots are saved before Flush returns
+	if len(p.published) != 3 {
+		t.Errorf("expected 3 snapshots to be saved, got %v", p.published)
+	}
+
+	// snapshots written after the flush are dropped
+	w.Write(&steampipeconfig.SteampipeSnapshot{FileNameRoot: "d"})
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.published) != 3 {
+		t.Errorf("expected the snapshot written after the flush to be dropped, got %v", p.published)
+	}
+}
+
+func TestWriterFlushTimeout(t *testing.T) {
+	p := &recordingPublisher{blocked: make(chan struct{})}
+	w := newWriter(p.publish)
+	w.Write(&steampipeconfig.SteampipeSnapshot{FileNameRoot: "a"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// the snapshot is still saved in the background
+	close(p.blocked)
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.published) != 1 {
+		t.Errorf("expected the snapshot to be saved, got %v", p.published)
+	}
+}
+
+func TestWriterNil(t *testing.T) {
+	var w *Writer
+	w.Write(&steampipeconfig.SteampipeSnapshot{})
+	if err := w.Flush(context.Background()); err != nil {
+		t.Errorf("expected no error flushing a nil writer, got %v", err)
+	}
+}
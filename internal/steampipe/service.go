@@ -0,0 +1,147 @@
+package steampipe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvInstallDir is the env var used to set the Steampipe install directory
+	EnvInstallDir = "STEAMPIPE_INSTALL_DIR"
+
+	// the defaults of the Steampipe service, used if the service state file does not exist
+	defaultPort     = 9193
+	defaultUser     = "steampipe"
+	defaultDatabase = "steampipe"
+
+	// how long to wait for the service to accept connections
+	dialTimeout  = time.Second
+	startTimeout = 60 * time.Second
+)
+
+// ErrNotInstalled is returned by Start if the steampipe executable is not found
+var ErrNotInstalled = errors.New("steampipe is not installed - see https://steampipe.io/downloads")
+
+// Service is a running Steampipe service
+type Service struct {
+	Pid      int    `json:"pid"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Database string `json:"database"`
+	Invoker  string `json:"invoker"`
+}
+
+// ConnectionString returns the connection string of the service database
+//
+// NOTE: the service password is not included - local connections to the service do not require it
+func (s *Service) ConnectionString() string {
+	return fmt.Sprintf("postgres://%s@127.0.0.1:%d/%s", s.User, s.Port, s.Database)
+}
+
+// Detect returns the Steampipe service running on this machine, or nil if there is none
+func Detect() (*Service, error) {
+	service, err := readServiceState(serviceStatePath())
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		// the service may have been started by a different installation - check the default port
+		service = &Service{Port: defaultPort, User: defaultUser, Database: defaultDatabase}
+	}
+	if !acceptsConnections(service.Port) {
+		return nil, nil
+	}
+	return service, nil
+}
+
+// Start starts the Steampipe service using the steampipe CLI and returns it once it accepts connections
+func Start(ctx context.Context) (*Service, error) {
+	path, err := exec.LookPath("steampipe")
+	if err != nil {
+		return nil, ErrNotInstalled
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "service", "start")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to start the Steampipe service: %s", strings.TrimSpace(output.String()))
+	}
+
+	deadline := time.Now().Add(startTimeout)
+	for {
+		service, err := Detect()
+		if err != nil || service != nil {
+			return service, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("the Steampipe service was started but is not accepting connections")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// serviceStatePath returns the path of the file the Steampipe service writes its state to when it starts
+func serviceStatePath() string {
+	installDir := os.Getenv(EnvInstallDir)
+	if installDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		installDir = filepath.Join(home, ".steampipe")
+	}
+	return filepath.Join(installDir, "internal", "steampipe.json")
+}
+
+// readServiceState reads the service state file, returning nil if it does not exist
+func readServiceState(path string) (*Service, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var service Service
+	if err := json.Unmarshal(data, &service); err != nil {
+		return nil, fmt.Errorf("failed to read the Steampipe service state file %s: %w", path, err)
+	}
+	if service.Port == 0 {
+		service.Port = defaultPort
+	}
+	if service.User == "" {
+		service.User = defaultUser
+	}
+	if service.Database == "" {
+		service.Database = defaultDatabase
+	}
+	return &service, nil
+}
+
+// acceptsConnections returns whether a server is listening on the local port
+func acceptsConnections(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", fmt.Sprintf("%d", port)), dialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,74 @@
+package steampipe
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadServiceState(t *testing.T) {
+	dir := t.TempDir()
+
+	service, err := readServiceState(filepath.Join(dir, "missing.json"))
+	if err != nil || service != nil {
+		t.Fatalf("expected no service for a missing state file, got %v, %v", service, err)
+	}
+
+	path := filepath.Join(dir, "steampipe.json")
+	if err := os.WriteFile(path, []byte(`{"pid":123,"port":9194,"user":"steampipe","password":"secret","database":"steampipe","invoker":"service"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	service, err = readServiceState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Pid != 123 || service.Invoker != "service" {
+		t.Errorf("unexpected service %+v", service)
+	}
+	if got, want := service.ConnectionString(), "postgres://steampipe@127.0.0.1:9194/steampipe"; got != want {
+		t.Errorf("expected connection string %s, got %s", want, got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"pid":123}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	service, err = readServiceState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := service.ConnectionString(), "postgres://steampipe@127.0.0.1:9193/steampipe"; got != want {
+		t.Errorf("expected connection string %s, got %s", want, got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	dir := t.TempDir()
+	t.Setenv(EnvInstallDir, dir)
+	if err := os.MkdirAll(filepath.Join(dir, "internal"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "internal", "steampipe.json"), []byte(fmt.Sprintf(`{"pid":1,"port":%d}`, port)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := Detect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service == nil || service.Port != port {
+		t.Fatalf("expected the service on port %d, got %+v", port, service)
+	}
+
+	listener.Close()
+	if service, err = Detect(); err != nil || service != nil {
+		t.Fatalf("expected no service once the port is closed, got %+v, %v", service, err)
+	}
+}
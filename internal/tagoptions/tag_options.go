@@ -0,0 +1,52 @@
+// Package tagoptions supports setting powerpipe specific options on mod resources using reserved tags.
+//
+// Options which are not part of the resource schema are set using tags with the "powerpipe:" prefix, e.g.
+//
+//	dashboard "aws_overview" {
+//	  tags = {
+//	    "powerpipe:theme" = "dark"
+//	  }
+//	}
+//
+// Reserved tags are removed from the tags displayed in the UI and used for grouping.
+package tagoptions
+
+import (
+	"strings"
+)
+
+const Prefix = "powerpipe:"
+
+// Get returns the value of the option with the given name
+func Get(tags map[string]string, name string) (string, bool) {
+	value, ok := tags[Prefix+name]
+	return value, ok
+}
+
+// GetWithPrefix returns all options whose name starts with the given prefix, keyed by the remainder of the name
+func GetWithPrefix(tags map[string]string, prefix string) map[string]string {
+	var res map[string]string
+	for k, v := range tags {
+		if name, ok := strings.CutPrefix(k, Prefix+prefix); ok && name != "" {
+			if res == nil {
+				res = make(map[string]string)
+			}
+			res[name] = v
+		}
+	}
+	return res
+}
+
+// StripOptions returns the tags with all option tags removed
+func StripOptions(tags map[string]string) map[string]string {
+	if tags == nil {
+		return nil
+	}
+	res := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if !strings.HasPrefix(k, Prefix) {
+			res[k] = v
+		}
+	}
+	return res
+}
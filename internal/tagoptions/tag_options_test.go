@@ -0,0 +1,57 @@
+package tagoptions
+
+import (
+	"reflect"
+	"testing"
+)
+
+type getWithPrefixTest struct {
+	tags     map[string]string
+	prefix   string
+	expected map[string]string
+}
+
+func testCasesGetWithPrefix() map[string]getWithPrefixTest {
+	return map[string]getWithPrefixTest{
+		"no options": {
+			tags:     map[string]string{"service": "aws"},
+			prefix:   "accent_",
+			expected: nil,
+		},
+		"matching options": {
+			tags: map[string]string{
+				"service":                "aws",
+				"powerpipe:accent_alert": "#ff0000",
+				"powerpipe:accent_ok":    "#00ff00",
+				"powerpipe:theme":        "dark",
+			},
+			prefix:   "accent_",
+			expected: map[string]string{"alert": "#ff0000", "ok": "#00ff00"},
+		},
+		"prefix only": {
+			tags:     map[string]string{"powerpipe:accent_": "#ff0000"},
+			prefix:   "accent_",
+			expected: nil,
+		},
+	}
+}
+
+func TestGetWithPrefix(t *testing.T) {
+	for name, test := range testCasesGetWithPrefix() {
+		output := GetWithPrefix(test.tags, test.prefix)
+		if !reflect.DeepEqual(output, test.expected) {
+			t.Errorf("Test: '%s'' FAILED : \nexpected:\n %v \ngot:\n %v\n", name, test.expected, output)
+		}
+	}
+}
+
+func TestStripOptions(t *testing.T) {
+	tags := map[string]string{
+		"service":         "aws",
+		"powerpipe:theme": "dark",
+	}
+	expected := map[string]string{"service": "aws"}
+	if output := StripOptions(tags); !reflect.DeepEqual(output, expected) {
+		t.Errorf("TestStripOptions FAILED : \nexpected:\n %v \ngot:\n %v\n", expected, output)
+	}
+}
@@ -0,0 +1,129 @@
+// Package timingreport reports the timing of each query of a run, for --timing=verbose, so the slowest controls and
+// dashboard panels can be found. The time of each query is broken down into:
+//
+//	queue      waiting for a database connection, and for a control, for a slot to run in (see --max-parallel)
+//	execution  executing the query until its rows are available
+//	fetch      fetching the rows from the database
+//	render     rendering the results - transforming the rows of a panel, or displaying the results of a query
+//
+// The report is displayed after the output of the run and, when the run is exported, written alongside the exports
+// as <name>.timing.json.
+package timingreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/turbot/pipe-fittings/export"
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+)
+
+// FileExtension is the extension of the JSON report written alongside exports
+const FileExtension = ".timing.json"
+
+// Query is the timing of the query of a resource
+type Query struct {
+	// the name and type of the resource, e.g. aws_compliance.control.s3_public, control
+	Name string
+	Type string
+	Rows int
+	localqueryresult.TimingMetadata
+}
+
+// Report is the timing of the queries of a run
+type Report struct {
+	Name     string
+	Duration time.Duration
+	Queries  []Query
+}
+
+// Add adds the timing of the query of a resource - resources without timing (which did not run a query) are ignored
+func (r *Report) Add(name, resourceType string, rows int, timing *localqueryresult.TimingMetadata) {
+	if timing == nil {
+		return
+	}
+	r.Queries = append(r.Queries, Query{Name: name, Type: resourceType, Rows: rows, TimingMetadata: *timing})
+}
+
+// Sort sorts the queries slowest first
+func (r *Report) Sort() {
+	sort.SliceStable(r.Queries, func(i, j int) bool {
+		return r.Queries[i].Duration > r.Queries[j].Duration
+	})
+}
+
+// Table returns the headers and rows of the report, slowest query first, for display
+func (r *Report) Table() ([]string, [][]string) {
+	r.Sort()
+	headers := []string{"Name", "Type", "Rows", "Queue", "Execution", "Fetch", "Render", "Total"}
+	rows := make([][]string, len(r.Queries))
+	for i, q := range r.Queries {
+		rows[i] = []string{q.Name, q.Type, fmt.Sprintf("%d", q.Rows), FormatDuration(q.Queue), FormatDuration(q.Execution), FormatDuration(q.Fetch), FormatDuration(q.Render), FormatDuration(q.Duration)}
+	}
+	return headers, rows
+}
+
+// WriteFile writes the report as JSON, with the durations in milliseconds
+func (r *Report) WriteFile(path string) error {
+	r.Sort()
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// Export writes the report as JSON to <name>.<timestamp>.timing.json in the working directory, as exports without a
+// file name are written, and returns the path of the file
+func (r *Report) Export() (string, error) {
+	path := export.GenerateDefaultExportFileName(r.Name, FileExtension)
+	if err := r.WriteFile(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+type jsonQuery struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Rows        int     `json:"rows"`
+	QueueMs     float64 `json:"queue_ms"`
+	ExecutionMs float64 `json:"execution_ms"`
+	FetchMs     float64 `json:"fetch_ms"`
+	RenderMs    float64 `json:"render_ms"`
+	TotalMs     float64 `json:"total_ms"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (r *Report) MarshalJSON() ([]byte, error) {
+	queries := make([]jsonQuery, len(r.Queries))
+	for i, q := range r.Queries {
+		queries[i] = jsonQuery{
+			Name:        q.Name,
+			Type:        q.Type,
+			Rows:        q.Rows,
+			QueueMs:     milliseconds(q.Queue),
+			ExecutionMs: milliseconds(q.Execution),
+			FetchMs:     milliseconds(q.Fetch),
+			RenderMs:    milliseconds(q.Render),
+			TotalMs:     milliseconds(q.Duration),
+		}
+	}
+	return json.Marshal(struct {
+		Name       string      `json:"name"`
+		DurationMs float64     `json:"duration_ms"`
+		Queries    []jsonQuery `json:"queries"`
+	}{r.Name, milliseconds(r.Duration), queries})
+}
+
+// FormatDuration formats a duration in milliseconds, to 1 decimal place
+func FormatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", milliseconds(d))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
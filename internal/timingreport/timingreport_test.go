@@ -0,0 +1,53 @@
+package timingreport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
+)
+
+func TestReport(t *testing.T) {
+	report := &Report{Name: "check.aws_compliance", Duration: 3 * time.Second}
+	report.Add("aws_compliance.control.s3_versioning", "control", 12, &localqueryresult.TimingMetadata{
+		Duration: 150 * time.Millisecond, Queue: 100 * time.Millisecond, Execution: 40 * time.Millisecond, Fetch: 10 * time.Millisecond,
+	})
+	report.Add("aws_compliance.control.iam_mfa", "control", 3, &localqueryresult.TimingMetadata{
+		Duration: 2500 * time.Microsecond, Execution: 2 * time.Millisecond, Render: 500 * time.Microsecond,
+	})
+	report.Add("aws_compliance.control.skipped", "control", 0, nil)
+
+	headers, rows := report.Table()
+	if len(headers) != 8 || len(rows) != 2 {
+		t.Fatalf("expected 2 rows of 8 columns, got %d rows of %d columns", len(rows), len(headers))
+	}
+	if rows[0][0] != "aws_compliance.control.s3_versioning" || rows[0][3] != "100.0ms" || rows[1][6] != "0.5ms" {
+		t.Errorf("expected the slowest query first, got %v", rows)
+	}
+
+	path := filepath.Join(t.TempDir(), "check"+FileExtension)
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written struct {
+		DurationMs float64 `json:"duration_ms"`
+		Queries    []struct {
+			Name    string  `json:"name"`
+			QueueMs float64 `json:"queue_ms"`
+			TotalMs float64 `json:"total_ms"`
+		} `json:"queries"`
+	}
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.DurationMs != 3000 || len(written.Queries) != 2 || written.Queries[0].QueueMs != 100 || written.Queries[1].TotalMs != 2.5 {
+		t.Errorf("unexpected report %s", b)
+	}
+}
@@ -0,0 +1,114 @@
+// Package tracing exports OpenTelemetry traces of check and dashboard execution, so the time spent in slow runs can
+// be seen in a tracing backend. Tracing is enabled by --otel-endpoint (or POWERPIPE_OTEL_ENDPOINT), the OTLP gRPC
+// endpoint of a collector, e.g. localhost:4317 or https://otel.example.com:4317. The standard OTLP exporter variables,
+// such as OTEL_EXPORTER_OTLP_HEADERS and OTEL_RESOURCE_ATTRIBUTES, also apply.
+//
+// A check run is traced as a check span, with a span for each benchmark, each control and each control query. A
+// dashboard run is traced as a dashboard span, with a span for each panel and each panel query.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/steampipe-plugin-sdk/v5/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// the span attributes of the traced resources
+const (
+	AttributeResource     = "powerpipe.resource"
+	AttributeResourceType = "powerpipe.resource_type"
+	AttributeRows         = "powerpipe.rows"
+	AttributeStatus       = "powerpipe.status"
+	AttributeAlarm        = "powerpipe.alarm"
+	AttributeOk           = "powerpipe.ok"
+	AttributeError        = "powerpipe.error"
+)
+
+// Init starts exporting traces to the endpoint set by --otel-endpoint, and returns a function which flushes and stops
+// the export. If no endpoint is set, the Steampipe telemetry variables (STEAMPIPE_OTEL_LEVEL) are used
+func Init(ctx context.Context) (func(), error) {
+	endpoint := viper.GetString(localconstants.ArgOtelEndpoint)
+	if endpoint == "" {
+		return telemetry.Init(app_specific.AppName)
+	}
+
+	var opts []otlptracegrpc.Option
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+	} else {
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+	if viper.GetBool(localconstants.ArgOtelInsecure) {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(semconv.ServiceName(app_specific.AppName), semconv.ServiceVersion(app_specific.AppVersion.String())),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.Debug("exporting traces", "endpoint", endpoint)
+
+	return func() {
+		// flush the spans of the run - do not hold up exit for long if the collector cannot be reached
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to export traces", "error", err)
+		}
+	}, nil
+}
+
+// StartSpan starts a span for the execution of a resource, named for the resource, or for the resource type if the
+// name is empty. If tracing is not enabled, the span does nothing
+func StartSpan(ctx context.Context, resourceType, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanName := resourceType
+	attributes = append(attributes, attribute.String(AttributeResourceType, resourceType))
+	if name != "" {
+		spanName = name
+		attributes = append(attributes, attribute.String(AttributeResource, name))
+	}
+	return otel.Tracer(app_specific.AppName).Start(ctx, spanName, trace.WithAttributes(attributes...))
+}
+
+// StartQuerySpan starts a span for the execution of the query of a resource
+func StartQuerySpan(ctx context.Context, name, sql string) (context.Context, trace.Span) {
+	return StartSpan(ctx, "query", name, semconv.DBStatement(sql))
+}
+
+// EndSpan ends the span, recording the error if there is one
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
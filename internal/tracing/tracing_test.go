@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, checkSpan := StartSpan(context.Background(), "check", "")
+	ctx, controlSpan := StartSpan(ctx, "control", "aws.control.s3_public")
+	_, querySpan := StartQuerySpan(ctx, "aws.query.s3_public", "select 1")
+	EndSpan(querySpan, errors.New("relation does not exist"))
+	EndSpan(controlSpan, nil)
+	checkSpan.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	query, control, check := spans[0], spans[1], spans[2]
+	if check.Name() != "check" || control.Name() != "aws.control.s3_public" || query.Name() != "aws.query.s3_public" {
+		t.Errorf("unexpected span names %s, %s, %s", check.Name(), control.Name(), query.Name())
+	}
+	if query.Parent().SpanID() != control.SpanContext().SpanID() || control.Parent().SpanID() != check.SpanContext().SpanID() {
+		t.Error("expected the query span to be a child of the control span, and the control span of the check span")
+	}
+	if query.Status().Code != codes.Error || control.Status().Code != codes.Unset {
+		t.Errorf("expected only the query span to have an error status, got %v, %v", query.Status(), control.Status())
+	}
+	attributes := map[attribute.Key]string{}
+	for _, a := range query.Attributes() {
+		attributes[a.Key] = a.Value.Emit()
+	}
+	if attributes[AttributeResourceType] != "query" || attributes[AttributeResource] != "aws.query.s3_public" || attributes["db.statement"] != "select 1" {
+		t.Errorf("unexpected query span attributes %v", attributes)
+	}
+}
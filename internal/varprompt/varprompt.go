@@ -0,0 +1,200 @@
+// Package varprompt loads a workspace, prompting for the values of required variables which have not been set.
+//
+// Each variable is prompted for with its description and type, and the value entered is validated against the type,
+// prompting again if it is invalid. Prompting is disabled by --input=false or --prompt=false, or if stdin is not a
+// terminal - in which case loading fails immediately with an error describing each missing variable, so
+// misconfiguration is reported up front in CI.
+package varprompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/viper"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/statushooks"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/pipe-fittings/workspace"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/pipes"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// MissingVariablesError is returned when required variables have no value and cannot be prompted for
+type MissingVariablesError struct {
+	// the display name of each missing variable, e.g. var.region or aws_compliance.var.tags
+	Names     []string
+	Variables []*modconfig.Variable
+	// whether any of the variables belong to transitive dependency mods, whose values can only be set by the
+	// require block of the mod which depends on them
+	Transitive bool
+}
+
+func (e MissingVariablesError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "missing %d variable %s:\n", len(e.Variables), utils.Pluralize("value", len(e.Variables)))
+	for i, v := range e.Variables {
+		fmt.Fprintf(&b, "\n  %s", Describe(e.Names[i], v))
+	}
+	b.WriteString("\n\nSet the values with --var, --var-file or a .ppvars file in the mod")
+	if e.Transitive {
+		b.WriteString(" - the variables of dependencies of dependency mods must be set by the require block of the mod which depends on them")
+	}
+	return b.String()
+}
+
+// LoadWorkspace loads the workspace, prompting for the values of any required variables of the workspace mod and
+// its direct dependencies which have not been set. If --pipes-variables is set, the variable settings of the Turbot
+// Pipes workspace are used for variables which have not been set locally
+func LoadWorkspace(ctx context.Context, workspacePath string, opts ...workspace.LoadWorkspaceOption) (*workspace.Workspace, error_helpers.ErrorAndWarnings) {
+	if err := pipes.SyncVariables(ctx, workspacePath); err != nil {
+		return nil, error_helpers.NewErrorsAndWarning(err)
+	}
+
+	// do not load resources if there is no modfile
+	opts = append(opts, workspace.WithSkipResourceLoadIfNoModfile(true))
+
+	w, errAndWarnings := workspace.Load(ctx, workspacePath, opts...)
+	missing, ok := MissingVariables(errAndWarnings.GetError(), workspacePath)
+	if !ok {
+		return w, errAndWarnings
+	}
+	if missing.Transitive || !promptEnabled() {
+		return nil, error_helpers.NewErrorsAndWarning(missing)
+	}
+
+	// hide the spinner while prompting
+	statushooks.Done(ctx)
+	if err := prompt(ctx, os.Stdin, missing); err != nil {
+		return nil, error_helpers.NewErrorsAndWarning(err)
+	}
+	// all variables are now set - reload the workspace
+	return workspace.Load(ctx, workspacePath, opts...)
+}
+
+// MissingVariables returns the missing variables error for the workspace if err is caused by required variables
+// having no value
+func MissingVariables(err error, workspacePath string) (MissingVariablesError, bool) {
+	var missingVariablesError steampipeconfig.MissingVariableError
+	if err == nil || !errors.As(err, &missingVariablesError) {
+		return MissingVariablesError{}, false
+	}
+
+	var res MissingVariablesError
+	for _, v := range missingVariablesError.MissingVariables {
+		res.Names = append(res.Names, displayName(v, workspacePath))
+		res.Variables = append(res.Variables, v)
+	}
+	for path, variables := range missingVariablesError.MissingTransitiveVariables {
+		for _, v := range variables {
+			res.Names = append(res.Names, fmt.Sprintf("%s (%s)", displayName(v, workspacePath), path))
+			res.Variables = append(res.Variables, v)
+			res.Transitive = true
+		}
+	}
+	return res, true
+}
+
+// Describe returns a description of the variable, with its type and description, e.g.
+// var.region (string): the AWS region to query
+func Describe(name string, v *modconfig.Variable) string {
+	res := fmt.Sprintf("%s (%s)", name, typeName(v))
+	if description := typehelpers.SafeString(v.Description); description != "" {
+		res += ": " + description
+	}
+	return res
+}
+
+// promptEnabled returns whether interactive prompting for variables is enabled
+func promptEnabled() bool {
+	// --input is not defined for all commands, so only disable prompting if it is explicitly set to false
+	if viper.IsSet(constants.ArgInput) && !viper.GetBool(constants.ArgInput) {
+		return false
+	}
+	return viper.GetBool(localconstants.ArgPrompt) && isatty.IsTerminal(os.Stdin.Fd())
+}
+
+func prompt(ctx context.Context, in io.Reader, missing MissingVariablesError) error {
+	reader := bufio.NewReader(in)
+	//nolint:forbidigo // UI formatting
+	fmt.Println("\nVariables defined with no value set.")
+	for i, v := range missing.Variables {
+		name := missing.Names[i]
+		//nolint:forbidigo // UI formatting
+		fmt.Printf("\n%s\n", Describe(name, v))
+		for {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			//nolint:forbidigo // UI formatting
+			fmt.Print("  Enter a value: ")
+			raw, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || raw == "") {
+				return fmt.Errorf("failed to read the value of %s: %w", name, err)
+			}
+			raw = strings.TrimRight(raw, "\r\n")
+			if err := validate(v, raw); err != nil {
+				//nolint:forbidigo // UI formatting
+				fmt.Printf("  Invalid value: %s\n", err.Error())
+				continue
+			}
+			addInteractiveVariable(viperName(v, name), raw)
+			break
+		}
+	}
+	return nil
+}
+
+// validate checks the raw value can be parsed as the type of the variable
+func validate(v *modconfig.Variable, raw string) error {
+	value, diags := v.ParsingMode.Parse(v.ShortName, raw)
+	if diags.HasErrors() {
+		return fmt.Errorf("%s", diags[0].Detail)
+	}
+	if v.Type != cty.NilType && v.Type != cty.DynamicPseudoType {
+		if _, err := convert.Convert(value, v.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayName returns the name of the variable, qualified with its mod if it is not in the workspace mod
+func displayName(v *modconfig.Variable, workspacePath string) string {
+	if v.Mod != nil && v.Mod.ModPath != workspacePath {
+		return fmt.Sprintf("%s.var.%s", v.ModName, v.ShortName)
+	}
+	return "var." + v.ShortName
+}
+
+// viperName returns the name the interactive value of the variable is stored under, e.g. region or aws_compliance.tags
+func viperName(v *modconfig.Variable, displayName string) string {
+	if strings.HasPrefix(displayName, "var.") {
+		return v.ShortName
+	}
+	return fmt.Sprintf("%s.%s", v.ModName, v.ShortName)
+}
+
+func typeName(v *modconfig.Variable) string {
+	if v.TypeString != "" {
+		return v.TypeString
+	}
+	return "any"
+}
+
+func addInteractiveVariable(name, raw string) {
+	varMap := viper.GetStringMap(constants.ConfigInteractiveVariables)
+	varMap[name] = raw
+	viper.Set(constants.ConfigInteractiveVariables, varMap)
+}
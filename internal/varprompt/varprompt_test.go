@@ -0,0 +1,266 @@
+package varprompt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/modconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+const testModFile = `mod "local" {
+  title = "Local"
+}
+
+variable "region" {
+  type        = string
+  description = "The AWS region to query"
+}
+
+variable "limit" {
+  type = number
+}
+
+variable "tags" {
+  type = list(string)
+}
+
+variable "optional" {
+  type    = string
+  default = "default"
+}
+`
+
+// setAppSpecificConstants sets the app specific constants used to load the workspace
+func setAppSpecificConstants() {
+	app_specific.ModDataExtensions = []string{".pp", ".sp"}
+	app_specific.VariablesExtensions = []string{".ppvars", ".spvars"}
+	app_specific.AutoVariablesExtensions = []string{".auto.ppvars", ".auto.spvars"}
+	app_specific.WorkspaceIgnoreFile = ".powerpipeignore"
+	app_specific.WorkspaceDataDir = ".powerpipe"
+	app_specific.DefaultVarsFileName = "powerpipe.ppvars"
+	app_specific.EnvInputVarPrefix = "PP_VAR_"
+}
+
+// loadMissingVariables loads a workspace of the test mod without prompting, returning the missing variables error
+func loadMissingVariables(t *testing.T) MissingVariablesError {
+	t.Helper()
+	setAppSpecificConstants()
+	t.Cleanup(viper.Reset)
+	viper.Set(localconstants.ArgPrompt, false)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mod.pp"), []byte(testModFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, errAndWarnings := LoadWorkspace(context.Background(), dir)
+	var missing MissingVariablesError
+	if !errors.As(errAndWarnings.GetError(), &missing) {
+		t.Fatalf("expected a missing variables error, got %v", errAndWarnings.GetError())
+	}
+	return missing
+}
+
+// variable returns the missing variable with the given name
+func (e MissingVariablesError) variable(t *testing.T, name string) *modconfig.Variable {
+	t.Helper()
+	for i, n := range e.Names {
+		if n == name {
+			return e.Variables[i]
+		}
+	}
+	t.Fatalf("variable %s is not missing", name)
+	return nil
+}
+
+func TestLoadWorkspaceMissingVariables(t *testing.T) {
+	missing := loadMissingVariables(t)
+
+	names := append([]string{}, missing.Names...)
+	sort.Strings(names)
+	expectedNames := []string{"var.limit", "var.region", "var.tags"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("got %v, expected %v", names, expectedNames)
+	}
+	if missing.Transitive {
+		t.Error("expected no transitive variables")
+	}
+
+	message := missing.Error()
+	for _, expected := range []string{
+		"missing 3 variable values:\n",
+		"\n  var.region (string): The AWS region to query",
+		"\n  var.limit (number)",
+		"\n  var.tags (list(string))",
+		"\n\nSet the values with --var, --var-file or a .ppvars file in the mod",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected the error to contain %q, got:\n%s", expected, message)
+		}
+	}
+	if strings.Contains(message, "require block") {
+		t.Errorf("expected no transitive variable hint, got:\n%s", message)
+	}
+}
+
+func TestMissingVariablesNotMissing(t *testing.T) {
+	if _, ok := MissingVariables(nil, "/workspace"); ok {
+		t.Error("expected no missing variables for a nil error")
+	}
+	if _, ok := MissingVariables(errors.New("failed to load"), "/workspace"); ok {
+		t.Error("expected no missing variables for another error")
+	}
+}
+
+func TestMissingVariablesTransitiveError(t *testing.T) {
+	missing := MissingVariablesError{
+		Names:      []string{"aws_tags.var.tags (aws_compliance -> aws_tags)"},
+		Variables:  []*modconfig.Variable{{TypeString: "map(string)"}},
+		Transitive: true,
+	}
+	expected := "missing 1 variable value:\n\n  aws_tags.var.tags (aws_compliance -> aws_tags) (map(string))\n\n" +
+		"Set the values with --var, --var-file or a .ppvars file in the mod - the variables of dependencies of dependency " +
+		"mods must be set by the require block of the mod which depends on them"
+	if missing.Error() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", missing.Error(), expected)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	workspaceMod := modconfig.NewMod("local", "/workspace", hcl.Range{})
+	depMod := modconfig.NewMod("aws_compliance", "/workspace/.powerpipe/mods/aws_compliance", hcl.Range{})
+
+	tests := map[string]struct {
+		mod             *modconfig.Mod
+		expected        string
+		expectedViperAs string
+	}{
+		"workspace mod":  {mod: workspaceMod, expected: "var.tags", expectedViperAs: "tags"},
+		"dependency mod": {mod: depMod, expected: "aws_compliance.var.tags", expectedViperAs: "aws_compliance.tags"},
+		"no mod":         {expected: "var.tags", expectedViperAs: "tags"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := &modconfig.Variable{}
+			v.ShortName, v.Mod = "tags", test.mod
+			if test.mod != nil {
+				v.ModName = test.mod.ShortName
+			}
+			res := displayName(v, "/workspace")
+			if res != test.expected {
+				t.Errorf("got %s, expected %s", res, test.expected)
+			}
+			if res := viperName(v, res); res != test.expectedViperAs {
+				t.Errorf("got viper name %s, expected %s", res, test.expectedViperAs)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	missing := loadMissingVariables(t)
+
+	tests := map[string]struct {
+		variable  string
+		raw       string
+		expectErr bool
+	}{
+		"string":              {variable: "var.region", raw: "us-east-1"},
+		"empty string":        {variable: "var.region", raw: ""},
+		"number":              {variable: "var.limit", raw: "10"},
+		"not a number":        {variable: "var.limit", raw: "ten", expectErr: true},
+		"list":                {variable: "var.tags", raw: `["a", "b"]`},
+		"list of numbers":     {variable: "var.tags", raw: `[1, 2]`},
+		"not a list":          {variable: "var.tags", raw: `{ a = "b" }`, expectErr: true},
+		"invalid expression":  {variable: "var.tags", raw: `["a"`, expectErr: true},
+		"unquoted list value": {variable: "var.tags", raw: `[a]`, expectErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validate(missing.variable(t, test.variable), test.raw)
+			if test.expectErr && err == nil {
+				t.Errorf("expected an error validating %q", test.raw)
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("expected no error validating %q, got %v", test.raw, err)
+			}
+		})
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	missing := loadMissingVariables(t)
+
+	// each variable is prompted for in order - invalid values are prompted for again
+	var input strings.Builder
+	for _, name := range missing.Names {
+		switch name {
+		case "var.region":
+			input.WriteString("us-east-1\r\n")
+		case "var.limit":
+			input.WriteString("ten\n10\n")
+		case "var.tags":
+			input.WriteString("[a]\n[\"a\"]\n")
+		}
+	}
+	if err := prompt(context.Background(), strings.NewReader(input.String()), missing); err != nil {
+		t.Fatal(err)
+	}
+
+	res := viper.GetStringMap(constants.ConfigInteractiveVariables)
+	expected := map[string]any{"region": "us-east-1", "limit": "10", "tags": `["a"]`}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %v, expected %v", res, expected)
+	}
+}
+
+func TestPromptEndOfInput(t *testing.T) {
+	missing := loadMissingVariables(t)
+
+	err := prompt(context.Background(), strings.NewReader(""), missing)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to read the value of var.") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestPromptCancelled(t *testing.T) {
+	missing := loadMissingVariables(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := prompt(ctx, strings.NewReader("value\n"), missing); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestPromptDisabled(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	tests := map[string]struct {
+		settings map[string]bool
+	}{
+		"input false":  {settings: map[string]bool{constants.ArgInput: false, localconstants.ArgPrompt: true}},
+		"prompt false": {settings: map[string]bool{localconstants.ArgPrompt: false}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			viper.Reset()
+			for k, v := range test.settings {
+				viper.Set(k, v)
+			}
+			if promptEnabled() {
+				t.Error("expected prompting to be disabled")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package workspaceprofile extends the workspace profiles loaded by pipe-fittings: it completes the inheritance of
+// profiles from their base profile, and loads the command options which set flag defaults for a command (see
+// CommandOptions).
+//
+// A profile inherits the settings it does not set from its base profile, e.g.
+//
+//	workspace "shared" {
+//	  database      = "connection.warehouse"
+//	  output        = "json"
+//	  query_timeout = 300
+//	}
+//
+//	workspace "prod" {
+//	  base     = workspace.shared
+//	  database = "connection.prod_warehouse"
+//	}
+//
+// The profile loader inherits the settings of the base from its cty value, which does not include every setting (e.g.
+// output and timing), so the settings are inherited again from the decoded base profile.
+package workspaceprofile
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+// ResolveBase sets each setting of the profiles which is not set in the profile to the setting of its base profile
+// (and so on, up the chain of base profiles) - profiles without a base are unchanged
+func ResolveBase(profiles ...*modconfig.PowerpipeWorkspaceProfile) error {
+	for _, p := range profiles {
+		if p == nil || p.Base == nil {
+			continue
+		}
+		// the base is resolved by the loader from the profiles of the same config directory
+		dirProfiles, err := LoadDir(filepath.Dir(p.DeclRange.Filename))
+		if err != nil {
+			return err
+		}
+		if err := resolveBase(p, dirProfiles, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveBase(p *modconfig.PowerpipeWorkspaceProfile, profiles map[string]*modconfig.PowerpipeWorkspaceProfile, resolving map[string]bool) error {
+	if p.Base == nil {
+		return nil
+	}
+	base, ok := profiles[p.Base.ProfileName]
+	if !ok {
+		return fmt.Errorf("base workspace profile '%s' of workspace profile '%s' is not defined", p.Base.ProfileName, p.ProfileName)
+	}
+	resolving[p.ProfileName] = true
+	if resolving[base.ProfileName] {
+		return fmt.Errorf("workspace profile '%s' inherits from itself", base.ProfileName)
+	}
+	if err := resolveBase(base, profiles, resolving); err != nil {
+		return err
+	}
+	inherit(p, base)
+	return nil
+}
+
+// inherit sets each setting of the profile which was not set in the profile to the setting of the base. A setting
+// was not set if it is nil, or has been set by the loader to the setting of the cty value of the base
+func inherit(p, base *modconfig.PowerpipeWorkspaceProfile) {
+	value, loaderBase, baseValue := reflect.ValueOf(p).Elem(), reflect.ValueOf(p.Base).Elem(), reflect.ValueOf(base).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if value.Type().Field(i).Name == "Base" || field.Kind() != reflect.Pointer {
+			continue
+		}
+		if field.IsNil() || field.Pointer() == loaderBase.Field(i).Pointer() {
+			field.Set(baseValue.Field(i))
+		}
+	}
+	p.Base = base
+}
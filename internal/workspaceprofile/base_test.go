@@ -0,0 +1,59 @@
+package workspaceprofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+)
+
+func TestResolveBase(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	config := `
+workspace "shared" {
+  database      = "connection.warehouse"
+  output        = "json"
+  timing        = true
+  port          = 9000
+  query_timeout = 300
+}
+
+workspace "staging" {
+  base   = workspace.shared
+  output = "csv"
+}
+
+workspace "prod" {
+  base     = workspace.staging
+  database = "connection.prod_warehouse"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "workspaces.ppc"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := parse.LoadWorkspaceProfiles[*modconfig.PowerpipeWorkspaceProfile](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod := profiles["prod"]
+	if err := ResolveBase(prod, profiles["shared"]); err != nil {
+		t.Fatal(err)
+	}
+
+	if prod.Database == nil || *prod.Database != "connection.prod_warehouse" {
+		t.Errorf("expected the database of the profile, got %v", prod.Database)
+	}
+	if prod.Output == nil || *prod.Output != "csv" {
+		t.Errorf("expected the output of the base, got %v", prod.Output)
+	}
+	if prod.Timing == nil || !*prod.Timing || prod.Port == nil || *prod.Port != 9000 {
+		t.Errorf("expected the timing and port of the base of the base, got %v, %v", prod.Timing, prod.Port)
+	}
+	if prod.QueryTimeout == nil || *prod.QueryTimeout != 300 {
+		t.Errorf("expected the query timeout of the base of the base, got %v", prod.QueryTimeout)
+	}
+}
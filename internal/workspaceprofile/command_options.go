@@ -0,0 +1,259 @@
+package workspaceprofile
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/powerpipe/internal/encryption"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// commandOptionsWorkspace is the attribute of a command options block which scopes it to a workspace profile
+const commandOptionsWorkspace = "workspace"
+
+// GeneralOptions is the label of the options block which sets the defaults of the flags of every command, e.g. the
+// log rotation policy
+const GeneralOptions = "general"
+
+// CommandOptions is an options block setting the defaults of the flags of a command, for example:
+//
+//	options "check" {
+//	  workspace    = "prod"
+//	  max_parallel = 5
+//	  export       = ["csv"]
+//	}
+//
+// The label is a command (e.g. "server" or "mod install"), a command whose run subcommand the options apply to (e.g.
+// "dashboard" for dashboard run), "check" for the benchmark and control run commands, or "general" for every command.
+// Each attribute sets the default of the command flag of the same name, with underscores in place of hyphens - the
+// attributes of general options set the defaults of the commands which have the flag.
+//
+// If workspace is set, the options only apply when the workspace profile is active, and take precedence over the
+// settings of the profile. As blocks cannot be nested in workspace profiles, command options are top-level blocks.
+type CommandOptions struct {
+	Command   string
+	Workspace string
+	// the flag defaults, and the ranges of the attributes which set them, keyed by flag name
+	Values map[string]any
+	ranges map[string]hcl.Range
+}
+
+// CommandOptionsSet is the command options of the config path, in increasing order of precedence
+type CommandOptionsSet []*CommandOptions
+
+// LoadCommandOptions loads the command options defined in the config files of the config directories, which are in
+// decreasing order of precedence
+func LoadCommandOptions(configPaths []string) (CommandOptionsSet, error) {
+	var res CommandOptionsSet
+	for i := len(configPaths) - 1; i >= 0; i-- {
+		dirOptions, err := loadCommandOptionsDir(configPaths[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, dirOptions...)
+	}
+	return res, nil
+}
+
+type commandOptionsConfig struct {
+	Options []struct {
+		Type string   `hcl:"type,label"`
+		Body hcl.Body `hcl:",remain"`
+	} `hcl:"options,block"`
+	Remain hcl.Body `hcl:",remain"`
+}
+
+func loadCommandOptionsDir(configPath string) (CommandOptionsSet, error) {
+	if !filehelpers.DirectoryExists(configPath) {
+		return nil, nil
+	}
+	configFiles, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions([]string{app_specific.ConfigExtension}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// sort the files, so the precedence of blocks for the same command does not depend on the file system
+	sort.Strings(configFiles)
+	structuredFiles, err := structuredConfigFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	configFiles = append(configFiles, structuredFiles...)
+
+	evalCtx := &hcl.EvalContext{
+		Functions: encryption.ContextFunctions(configPath),
+		Variables: map[string]cty.Value{},
+	}
+	var res CommandOptionsSet
+	parser := hclparse.NewParser()
+	for _, configFile := range configFiles {
+		var body hcl.Body
+		if isStructuredConfigFile(configFile) {
+			if body, err = ParseStructuredFile(configFile); err != nil {
+				return nil, err
+			}
+		} else {
+			file, diags := parser.ParseHCLFile(configFile)
+			if diags.HasErrors() {
+				return nil, error_helpers.HclDiagsToError("failed to parse config file", diags)
+			}
+			body = file.Body
+		}
+		var config commandOptionsConfig
+		if diags := gohcl.DecodeBody(body, evalCtx, &config); diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to decode command options", diags)
+		}
+		for _, block := range config.Options {
+			attributes, diags := block.Body.JustAttributes()
+			if diags.HasErrors() {
+				return nil, error_helpers.HclDiagsToError("failed to decode command options", diags)
+			}
+			o := &CommandOptions{Command: block.Type, Values: map[string]any{}, ranges: map[string]hcl.Range{}}
+			for name, attribute := range attributes {
+				value, diags := attribute.Expr.Value(evalCtx)
+				if diags.HasErrors() {
+					return nil, error_helpers.HclDiagsToError("failed to decode command options", diags)
+				}
+				if name == commandOptionsWorkspace {
+					if value.Type() != cty.String || value.IsNull() {
+						return nil, optionsError(attribute.Range, fmt.Sprintf("the workspace of options \"%s\" must be a string", o.Command))
+					}
+					o.Workspace = value.AsString()
+					continue
+				}
+				goValue, err := optionValue(value)
+				if err != nil {
+					return nil, optionsError(attribute.Range, fmt.Sprintf("options \"%s\" attribute '%s' %s", o.Command, name, err.Error()))
+				}
+				flag := strings.ReplaceAll(name, "_", "-")
+				o.Values[flag] = goValue
+				o.ranges[flag] = attribute.Range
+			}
+			res = append(res, o)
+		}
+	}
+	return res, nil
+}
+
+func optionsError(rng hcl.Range, summary string) error {
+	return error_helpers.HclDiagsToError("failed to decode command options", hcl.Diagnostics{{Severity: hcl.DiagError, Summary: summary, Subject: &rng}})
+}
+
+// optionValue converts the value of an attribute to the value of a flag - a string, bool, number or list of strings
+func optionValue(value cty.Value) (any, error) {
+	if value.IsNull() || !value.IsWhollyKnown() {
+		return nil, fmt.Errorf("must have a value")
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return value.AsString(), nil
+	case ty == cty.Bool:
+		return value.True(), nil
+	case ty == cty.Number:
+		f := value.AsBigFloat()
+		if i, accuracy := f.Int64(); accuracy == big.Exact {
+			return int(i), nil
+		}
+		f64, _ := f.Float64()
+		return f64, nil
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		var res []string
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			element, err := convert.Convert(element, cty.String)
+			if err != nil || element.IsNull() {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			res = append(res, element.AsString())
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("must be a string, bool, number or list of strings")
+	}
+}
+
+// Values returns the flag defaults set by the options of the command for the workspace profile (or by the options
+// which apply to every profile, if workspace is empty)
+func (s CommandOptionsSet) Values(cmd *cobra.Command, workspace string) (map[string]any, error) {
+	res := map[string]any{}
+	// options for more specific commands take precedence
+	for _, command := range commandNames(cmd) {
+		for _, o := range s {
+			if o.Command != command || o.Workspace != workspace {
+				continue
+			}
+			for flag, value := range o.Values {
+				if cmd.Flags().Lookup(flag) == nil {
+					if o.Command != GeneralOptions {
+						return nil, optionsError(o.ranges[flag], fmt.Sprintf("options \"%s\" sets '%s', which is not a flag of %s", o.Command, strings.ReplaceAll(flag, "-", "_"), cmd.CommandPath()))
+					}
+					if !isCommandFlag(cmd.Root(), flag) {
+						return nil, optionsError(o.ranges[flag], fmt.Sprintf("options \"%s\" sets '%s', which is not a flag of any command", o.Command, strings.ReplaceAll(flag, "-", "_")))
+					}
+					continue
+				}
+				res[flag] = value
+			}
+		}
+	}
+	return res, nil
+}
+
+// isCommandFlag returns whether the flag is a flag of the command or any of its subcommands
+func isCommandFlag(cmd *cobra.Command, flag string) bool {
+	if cmd.Flags().Lookup(flag) != nil || cmd.InheritedFlags().Lookup(flag) != nil {
+		return true
+	}
+	for _, child := range cmd.Commands() {
+		if isCommandFlag(child, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandNames returns the names an options block may use for the command, in increasing order of specificity, e.g.
+// general, check, benchmark, benchmark run for powerpipe benchmark run
+func commandNames(cmd *cobra.Command) []string {
+	path := strings.Fields(cmd.CommandPath())[1:]
+	res := []string{GeneralOptions}
+	if len(path) == 2 && path[1] == "run" {
+		if path[0] == "benchmark" || path[0] == "control" {
+			res = append(res, "check")
+		}
+		res = append(res, path[0])
+	}
+	return append(res, strings.Join(path, " "))
+}
+
+// OptionsCommands returns the commands of the command tree which each options block label applies to, keyed by label
+func OptionsCommands(root *cobra.Command) map[string][]*cobra.Command {
+	res := map[string][]*cobra.Command{}
+	for _, cmd := range root.Commands() {
+		if !cmd.IsAvailableCommand() {
+			continue
+		}
+		if cmd.Runnable() {
+			for _, command := range commandNames(cmd) {
+				res[command] = append(res[command], cmd)
+			}
+		}
+		for command, commands := range OptionsCommands(cmd) {
+			res[command] = append(res[command], commands...)
+		}
+	}
+	return res
+}
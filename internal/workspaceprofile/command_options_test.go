@@ -0,0 +1,142 @@
+package workspaceprofile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/turbot/pipe-fittings/app_specific"
+)
+
+func testCommands() (run, list *cobra.Command) {
+	root := &cobra.Command{Use: "powerpipe"}
+	benchmark := &cobra.Command{Use: "benchmark"}
+	run = &cobra.Command{Use: "run"}
+	run.Flags().Int("max-parallel", 10, "")
+	run.Flags().String("output", "text", "")
+	run.Flags().StringSlice("export", nil, "")
+	list = &cobra.Command{Use: "list"}
+	list.Flags().String("output", "pretty", "")
+	benchmark.AddCommand(run, list)
+	root.AddCommand(benchmark)
+	return run, list
+}
+
+func TestCommandOptions(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	modDir, globalDir := t.TempDir(), t.TempDir()
+	writeFile := func(dir, content string) {
+		if err := os.WriteFile(filepath.Join(dir, "options.ppc"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(globalDir, `
+options "check" {
+  max_parallel = 5
+  output       = "csv"
+}
+
+options "benchmark run" {
+  export = ["json", "csv"]
+}
+`)
+	writeFile(modDir, `
+workspace "prod" {
+  database = "acme/prod"
+}
+
+options "check" {
+  output = "brief"
+}
+
+options "benchmark" {
+  workspace    = "prod"
+  max_parallel = 2
+}
+`)
+
+	options, err := LoadCommandOptions([]string{modDir, globalDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, list := testCommands()
+
+	// the mod location has precedence over the global config directory, and more specific commands over less
+	values, err := options.Values(run, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"max-parallel": 5, "output": "brief", "export": []string{"json", "csv"}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+
+	if values, _ := options.Values(run, "prod"); !reflect.DeepEqual(values, map[string]any{"max-parallel": 2}) {
+		t.Errorf("expected the options of the prod workspace, got %v", values)
+	}
+	if values, _ := options.Values(list, ""); len(values) != 0 {
+		t.Errorf("expected no options for benchmark list, got %v", values)
+	}
+}
+
+func TestCommandOptionsUnknownFlag(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "options.ppc"), []byte(`options "check" { max_paralel = 5 }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	options, err := LoadCommandOptions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, _ := testCommands()
+	if _, err := options.Values(run, ""); err == nil {
+		t.Errorf("expected an error for an option which is not a flag")
+	}
+}
+
+func TestGeneralOptions(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "options.ppc"), []byte(`
+options "general" {
+  max_parallel = 5
+  output       = "json"
+}
+
+options "benchmark list" {
+  output = "pretty"
+}
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	options, err := LoadCommandOptions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, list := testCommands()
+
+	// general options apply to the commands which have the flag, with the lowest precedence
+	if values, _ := options.Values(run, ""); !reflect.DeepEqual(values, map[string]any{"max-parallel": 5, "output": "json"}) {
+		t.Errorf("expected the general options, got %v", values)
+	}
+	values, err := options.Values(list, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, map[string]any{"output": "pretty"}) {
+		t.Errorf("expected the benchmark list options, got %v", values)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "options.ppc"), []byte(`options "general" { max_paralel = 5 }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if options, err = LoadCommandOptions([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := options.Values(run, ""); err == nil {
+		t.Errorf("expected an error for a general option which is not a flag of any command")
+	}
+}
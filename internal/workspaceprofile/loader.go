@@ -0,0 +1,374 @@
+package workspaceprofile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/parse"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/steampipeconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/turbot/powerpipe/internal/encryption"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StructuredConfigExtensions are the extensions of config files written in YAML or JSON rather than HCL, for config
+// generated by other tools. The files have the structure of the HCL JSON syntax, and may define workspace profiles
+// and command options, e.g.
+//
+//	workspace:
+//	  prod:
+//	    database: connection.prod
+//	    base: ${workspace.shared}
+//	options:
+//	  check:
+//	    max_parallel: 5
+//
+// A profile in a YAML or JSON file may use any profile of the directory as its base, but a profile in an HCL file
+// may only use one defined in a YAML or JSON file if the HCL files use the encrypted function (otherwise the HCL files
+// are loaded by the pipe-fittings loader, which does not read YAML or JSON files).
+var StructuredConfigExtensions = []string{".ppc.json", ".ppc.yaml", ".ppc.yml"}
+
+// the blocks which may be defined in a YAML or JSON config file
+var structuredConfigSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: schema.BlockTypeWorkspaceProfile, LabelNames: []string{"name"}},
+		{Type: schema.BlockTypeOptions, LabelNames: []string{"type"}},
+	},
+}
+
+// NewLoader loads the workspace profiles of the config path. The profiles are loaded by the pipe-fittings loader,
+// unless the config path contains YAML or JSON config files, which that loader does not read, or HCL config files
+// which use the encrypted function, which that loader does not support
+func NewLoader() (*steampipeconfig.WorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile], error) {
+	// as for the pipe-fittings loader, the workspace profile and install dir may be set by env vars
+	cmdconfig.SetDefaultFromEnv(app_specific.EnvWorkspaceProfile, constants.ArgWorkspaceProfile, cmdconfig.EnvVarTypeString)
+	cmdconfig.SetDefaultFromEnv(app_specific.EnvInstallDir, constants.ArgInstallDir, cmdconfig.EnvVarTypeString)
+
+	configPaths, err := cmdconfig.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	local := false
+	for _, configPath := range configPaths {
+		files, err := structuredConfigFiles(configPath)
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := usesEncryption(configPath)
+		if err != nil {
+			return nil, err
+		}
+		local = local || len(files) > 0 || encrypted
+	}
+	if !local {
+		return cmdconfig.GetWorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile]()
+	}
+
+	// the profiles of each directory, in decreasing order of precedence
+	dirProfiles := make([]map[string]*modconfig.PowerpipeWorkspaceProfile, 0, len(configPaths))
+	for _, configPath := range configPaths {
+		profiles, err := LoadDir(configPath)
+		if err != nil {
+			return nil, err
+		}
+		dirProfiles = append(dirProfiles, profiles)
+	}
+	get := func(name string) *modconfig.PowerpipeWorkspaceProfile {
+		for _, profiles := range dirProfiles {
+			if p, ok := profiles[name]; ok {
+				return p
+			}
+		}
+		return nil
+	}
+
+	loader := &steampipeconfig.WorkspaceProfileLoader[*modconfig.PowerpipeWorkspaceProfile]{DefaultProfile: get("default")}
+	if loader.DefaultProfile == nil {
+		var diags hcl.Diagnostics
+		loader.DefaultProfile, diags = modconfig.NewDefaultWorkspaceProfile[*modconfig.PowerpipeWorkspaceProfile]()
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("failed to create default workspace", diags)
+		}
+	}
+	if viper.IsSet(constants.ArgWorkspaceProfile) {
+		name := viper.GetString(constants.ArgWorkspaceProfile)
+		loader.ConfiguredProfile = get(name)
+		if loader.ConfiguredProfile == nil && steampipeconfig.IsCloudWorkspaceIdentifier(name) {
+			// an implicit workspace, which uses the Turbot Pipes workspace as the database and snapshot location
+			loader.ConfiguredProfile = &modconfig.PowerpipeWorkspaceProfile{
+				SnapshotLocation: utils.ToStringPointer(name),
+				Database:         utils.ToStringPointer(name),
+			}
+		}
+		if loader.ConfiguredProfile == nil {
+			return nil, fmt.Errorf("workspace '%s' not found in config path %s", name, strings.Join(configPaths, ", "))
+		}
+	}
+	return loader, nil
+}
+
+// LoadDir loads the workspace profiles defined in the HCL, YAML and JSON config files of the directory
+//
+// If the HCL files of the directory use the encrypted function, which the pipe-fittings loader does not support, they
+// are decoded in the same way as YAML and JSON files (see decodeProfiles).
+func LoadDir(configPath string) (map[string]*modconfig.PowerpipeWorkspaceProfile, error) {
+	if !filehelpers.DirectoryExists(configPath) {
+		return map[string]*modconfig.PowerpipeWorkspaceProfile{}, nil
+	}
+	encrypted, err := usesEncryption(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]*modconfig.PowerpipeWorkspaceProfile
+	var configFiles []string
+	if encrypted {
+		res = map[string]*modconfig.PowerpipeWorkspaceProfile{}
+		configFiles, err = hclConfigFiles(configPath)
+	} else {
+		res, err = parse.LoadWorkspaceProfiles[*modconfig.PowerpipeWorkspaceProfile](configPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	structuredFiles, err := structuredConfigFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	configFiles = append(configFiles, structuredFiles...)
+	var blocks hcl.Blocks
+	for _, configFile := range configFiles {
+		body, err := ParseStructuredFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		blockSchema := structuredConfigSchema
+		if !isStructuredConfigFile(configFile) {
+			blockSchema = parse.ConfigBlockSchema
+		}
+		content, diags := body.Content(blockSchema)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("Failed to load workspace profiles", diags)
+		}
+		blocks = append(blocks, content.Blocks.OfType(schema.BlockTypeWorkspaceProfile)...)
+	}
+	if err := decodeProfiles(configPath, blocks, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// decodeProfiles decodes the workspace profile blocks, adding them to the profiles already loaded from the directory.
+// A profile may refer to any other profile of the directory as workspace.<name>, so each profile is decoded after the
+// profiles it refers to, whatever the order of the files and blocks.
+func decodeProfiles(configPath string, blocks hcl.Blocks, profiles map[string]*modconfig.PowerpipeWorkspaceProfile) error {
+	pending := map[string]*hcl.Block{}
+	for _, block := range blocks {
+		name := block.Labels[0]
+		if existing, ok := profiles[name]; ok {
+			return fmt.Errorf("duplicate workspace profile '%s' defined in %s and %s", name, existing.DeclRange.Filename, block.DefRange.Filename)
+		}
+		if existing, ok := pending[name]; ok {
+			return fmt.Errorf("duplicate workspace profile '%s' defined in %s and %s", name, existing.DefRange.Filename, block.DefRange.Filename)
+		}
+		pending[name] = block
+	}
+
+	var decode func(name string, decoding map[string]bool) error
+	decode = func(name string, decoding map[string]bool) error {
+		if decoding[name] {
+			return fmt.Errorf("workspace profile '%s' inherits from itself", name)
+		}
+		decoding[name] = true
+		block := pending[name]
+		for _, reference := range profileReferences(block) {
+			if _, ok := pending[reference]; ok {
+				if err := decode(reference, decoding); err != nil {
+					return err
+				}
+			}
+		}
+		p, err := decodeProfile(configPath, block, profiles)
+		if err != nil {
+			return err
+		}
+		profiles[name] = p
+		delete(pending, name)
+		return nil
+	}
+	for _, block := range blocks {
+		if _, ok := pending[block.Labels[0]]; ok {
+			if err := decode(block.Labels[0], map[string]bool{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// profileReferences returns the sorted names of the profiles which the attributes of the profile block refer to as
+// workspace.<name>
+func profileReferences(block *hcl.Block) []string {
+	_, rest, _ := block.Body.PartialContent(parse.WorkspaceProfileBlockSchema)
+	attrs, _ := rest.JustAttributes()
+	var res []string
+	for _, attr := range attrs {
+		for _, traversal := range attr.Expr.Variables() {
+			if traversal.RootName() != "workspace" || len(traversal) < 2 {
+				continue
+			}
+			if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+				res = append(res, step.Name)
+			}
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// usesEncryption returns whether the HCL config files of the directory call the encrypted function - files which
+// cannot be parsed are left to the pipe-fittings loader to report
+func usesEncryption(configPath string) (bool, error) {
+	configFiles, err := hclConfigFiles(configPath)
+	if err != nil {
+		return false, err
+	}
+	for _, configFile := range configFiles {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return false, err
+		}
+		file, diags := hclsyntax.ParseConfig(data, configFile, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		found := false
+		hclsyntax.VisitAll(file.Body.(*hclsyntax.Body), func(n hclsyntax.Node) hcl.Diagnostics {
+			if call, ok := n.(*hclsyntax.FunctionCallExpr); ok && call.Name == encryption.FunctionName {
+				found = true
+			}
+			return nil
+		})
+		if found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// decodeProfile decodes a workspace profile of a YAML or JSON file (or of an HCL file which uses the encrypted
+// function) - the profiles already loaded from the directory may be referred to as workspace.<name>
+func decodeProfile(configPath string, block *hcl.Block, profiles map[string]*modconfig.PowerpipeWorkspaceProfile) (*modconfig.PowerpipeWorkspaceProfile, error) {
+	p, diags := modconfig.NewWorkspaceProfile[*modconfig.PowerpipeWorkspaceProfile](block)
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("Failed to decode all workspace profile files", diags)
+	}
+	values := map[string]cty.Value{}
+	for name, profile := range profiles {
+		value, err := profile.CtyValue()
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	evalCtx := &hcl.EvalContext{
+		Functions: encryption.ContextFunctions(configPath),
+		Variables: map[string]cty.Value{"workspace": cty.ObjectVal(values)},
+	}
+	// as for the pipe-fittings loader, options blocks of the profile are decoded separately
+	optionsContent, rest, diags := block.Body.PartialContent(parse.WorkspaceProfileBlockSchema)
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("Failed to decode all workspace profile files", diags)
+	}
+	diags = gohcl.DecodeBody(rest, evalCtx, p)
+	foundOptions := map[string]bool{}
+	for _, optionsBlock := range optionsContent.Blocks {
+		if foundOptions[optionsBlock.Labels[0]] {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate options type '%s'", optionsBlock.Labels[0]),
+				Subject:  optionsBlock.DefRange.Ptr(),
+			})
+			continue
+		}
+		foundOptions[optionsBlock.Labels[0]] = true
+		opts, moreDiags := parse.DecodeOptions(optionsBlock, p.GetOptionsForBlock)
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			diags = append(diags, p.SetOptions(opts, optionsBlock)...)
+		}
+	}
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("Failed to decode all workspace profile files", diags)
+	}
+	if diags := p.OnDecoded(); diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("Failed to decode all workspace profile files", diags)
+	}
+	return p, nil
+}
+
+// ParseStructuredFile parses a YAML or JSON (or HCL) config file
+func ParseStructuredFile(configFile string) (hcl.Body, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	body, diags := parse.ParseHclFiles(map[string][]byte{configFile: data})
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("failed to parse config file", diags)
+	}
+	return body, nil
+}
+
+func isStructuredConfigFile(configFile string) bool {
+	for _, extension := range StructuredConfigExtensions {
+		if strings.HasSuffix(configFile, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// structuredConfigFiles returns the sorted YAML and JSON config files of the directory
+func structuredConfigFiles(configPath string) ([]string, error) {
+	if !filehelpers.DirectoryExists(configPath) {
+		return nil, nil
+	}
+	res, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions(StructuredConfigExtensions),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// hclConfigFiles returns the sorted HCL config files of the directory
+func hclConfigFiles(configPath string) ([]string, error) {
+	if !filehelpers.DirectoryExists(configPath) {
+		return nil, nil
+	}
+	res, err := filehelpers.ListFiles(configPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesFlat,
+		Include: filehelpers.InclusionsFromExtensions([]string{app_specific.ConfigExtension}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(res)
+	return res, nil
+}
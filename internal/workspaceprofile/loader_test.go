@@ -0,0 +1,189 @@
+package workspaceprofile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/encryption"
+)
+
+func TestLoadDir(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	dir := t.TempDir()
+	files := map[string]string{
+		"workspaces.ppc": `
+workspace "shared" {
+  database      = "connection.warehouse"
+  query_timeout = 300
+}
+`,
+		"generated.ppc.yaml": `
+workspace:
+  prod:
+    base: ${workspace.shared}
+    output: csv
+options:
+  check:
+    workspace: prod
+    max_parallel: 5
+`,
+		"generated.ppc.json": `{
+  "workspace": {
+    "dev": {
+      "database": "connection.dev",
+      "timing": true
+    }
+  }
+}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 3 {
+		t.Fatalf("expected 3 profiles, got %d", len(profiles))
+	}
+	dev := profiles["dev"]
+	if dev.Database == nil || *dev.Database != "connection.dev" || dev.Timing == nil || !*dev.Timing {
+		t.Errorf("expected the database and timing of the JSON profile, got %v, %v", dev.Database, dev.Timing)
+	}
+	prod := profiles["prod"]
+	if err := ResolveBase(prod); err != nil {
+		t.Fatal(err)
+	}
+	if prod.Output == nil || *prod.Output != "csv" {
+		t.Errorf("expected the output of the YAML profile, got %v", prod.Output)
+	}
+	if prod.Database == nil || *prod.Database != "connection.warehouse" || prod.QueryTimeout == nil || *prod.QueryTimeout != 300 {
+		t.Errorf("expected the database and query timeout of the HCL base, got %v, %v", prod.Database, prod.QueryTimeout)
+	}
+
+	options, err := LoadCommandOptions([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options) != 1 || options[0].Command != "check" || options[0].Workspace != "prod" || options[0].Values["max-parallel"] != 5 {
+		t.Errorf("expected the check options of the YAML file, got %+v", options)
+	}
+}
+
+func TestLoadDirEncrypted(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(constants.EnvAgeIdentity, identity.String())
+	ciphertext, err := encryption.Encrypt(context.Background(), "tpt_secret", []string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		// the base of a profile may be defined after it
+		"a.ppc": fmt.Sprintf(`
+workspace "prod" {
+  base        = workspace.shared
+  pipes_token = encrypted("%s")
+  output      = "json"
+}
+`, ciphertext),
+		"b.ppc": `
+workspace "shared" {
+  database = "connection.warehouse"
+}
+`,
+		"generated.ppc.yaml": `
+workspace:
+  ci:
+    base: ${workspace.prod}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 3 {
+		t.Fatalf("expected 3 profiles, got %d", len(profiles))
+	}
+	for _, name := range []string{"prod", "ci"} {
+		p := profiles[name]
+		if err := ResolveBase(p); err != nil {
+			t.Fatal(err)
+		}
+		if p.PipesToken == nil || *p.PipesToken != "tpt_secret" {
+			t.Errorf("%s: expected the decrypted pipes token, got %v", name, p.PipesToken)
+		}
+		if p.Database == nil || *p.Database != "connection.warehouse" {
+			t.Errorf("%s: expected the database of the base, got %v", name, p.Database)
+		}
+	}
+	if output := profiles["prod"].Output; output == nil || *output != "json" {
+		t.Errorf("expected the output of the profile, got %v", output)
+	}
+}
+
+func TestLoadDirInvalid(t *testing.T) {
+	app_specific.ConfigExtension = ".ppc"
+	tests := map[string]struct {
+		files   map[string]string
+		wantErr string
+	}{
+		"inherits from itself": {
+			files: map[string]string{
+				"a.ppc.yaml": `
+workspace:
+  prod:
+    base: ${workspace.ci}
+  ci:
+    base: ${workspace.prod}
+`,
+			},
+			wantErr: "inherits from itself",
+		},
+		"duplicate": {
+			files: map[string]string{
+				"a.ppc.yaml": `
+workspace:
+  prod:
+    output: csv
+`,
+				"b.ppc.json": `{"workspace": {"prod": {"output": "json"}}}`,
+			},
+			wantErr: "duplicate workspace profile 'prod'",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,4 +1,5 @@
 import "./utils/registerComponents";
+import BrandingFooter from "./components/BrandingFooter";
 import Dashboard from "./components/dashboards/layout/Dashboard";
 import DashboardHeader from "./components/DashboardHeader";
 import DashboardList from "./components/DashboardList";
@@ -20,6 +21,7 @@ const Dashboards = ({ analyticsContext, breakpointContext, themeContext }) => (
     <WorkspaceErrorModal />
     <DashboardList wrapperClassName="p-4 h-full overflow-y-auto" />
     <Dashboard />
+    <BrandingFooter />
   </DashboardProvider>
 );
 
@@ -0,0 +1,61 @@
+import Icon from "@powerpipe/components/Icon";
+import NeutralButton from "@powerpipe/components/forms/NeutralButton";
+import { DashboardDataModeLive } from "@powerpipe/types";
+import { classNames } from "@powerpipe/utils/styles";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
+import { useEffect, useState } from "react";
+
+// Dashboards with a "powerpipe:refresh_interval" option are re-executed by the server on that interval.
+// This toggle allows the user to pause and resume the refresh.
+const AutoRefreshToggle = () => {
+  const { dashboard, dataMode, panelsMap, selectedDashboard, setAutoRefresh } =
+    useDashboard();
+  const [enabled, setEnabled] = useState(true);
+
+  // auto-refresh is turned back on by the server whenever a dashboard is selected
+  useEffect(() => {
+    setEnabled(true);
+  }, [selectedDashboard?.full_name]);
+
+  const refreshInterval = dashboard
+    ? panelsMap[dashboard.name]?.options?.refresh_interval
+    : undefined;
+
+  if (dataMode !== DashboardDataModeLive || !refreshInterval) {
+    return null;
+  }
+
+  const toggle = () => {
+    setAutoRefresh(!enabled);
+    setEnabled(!enabled);
+  };
+
+  return (
+    <NeutralButton
+      className="inline-flex items-center space-x-2"
+      onClick={toggle}
+      title={
+        enabled
+          ? `Refreshing every ${refreshInterval} - click to pause`
+          : "Auto-refresh paused - click to resume"
+      }
+    >
+      <>
+        <Icon
+          className={classNames(
+            "inline-block w-5 -mt-0.5",
+            enabled ? "text-ok" : "text-foreground-lighter",
+          )}
+          icon={
+            enabled ? "heroicons-outline:arrow-path" : "heroicons-outline:pause"
+          }
+        />
+        <span className="hidden lg:block">
+          {enabled ? refreshInterval : "Paused"}
+        </span>
+      </>
+    </NeutralButton>
+  );
+};
+
+export default AutoRefreshToggle;
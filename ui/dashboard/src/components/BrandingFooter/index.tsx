@@ -0,0 +1,18 @@
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
+
+const BrandingFooter = () => {
+  const { metadata } = useDashboard();
+  const footer = metadata?.branding?.footer;
+
+  if (!footer) {
+    return null;
+  }
+
+  return (
+    <div className="w-full px-4 py-2 text-sm text-center text-foreground-lighter border-t border-divide">
+      {footer}
+    </div>
+  );
+};
+
+export default BrandingFooter;
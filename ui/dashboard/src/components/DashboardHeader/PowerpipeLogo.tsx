@@ -12,10 +12,12 @@ import { useDashboard } from "@powerpipe/hooks/useDashboard";
 
 const PowerpipeLogo = () => {
   const {
+    metadata,
     themeContext: { theme },
     searchPathPrefix,
   } = useDashboard();
   const ExternalLink = getComponent("external_link");
+  const branding = metadata?.branding;
 
   return (
     <div className="mr-1 md:mr-4">
@@ -23,14 +25,32 @@ const PowerpipeLogo = () => {
         ignoreDataMode
         to={`/${!!searchPathPrefix.length ? `?search_path_prefix=${searchPathPrefix}` : ""}`}
       >
-        <div className="block md:hidden w-8">
-          {theme.name === ThemeNames.STEAMPIPE_DEFAULT && <Logo />}
-          {theme.name === ThemeNames.STEAMPIPE_DARK && <LogoDarkmode />}
-        </div>
-        <div className="hidden md:block w-48">
-          {theme.name === ThemeNames.STEAMPIPE_DEFAULT && <LogoWordmark />}
-          {theme.name === ThemeNames.STEAMPIPE_DARK && <LogoWordmarkDarkmode />}
-        </div>
+        {branding?.logo_url && (
+          <img
+            className="h-8 max-w-48"
+            src={branding.logo_url}
+            alt={branding.product_name || "Logo"}
+          />
+        )}
+        {!branding?.logo_url && branding?.product_name && (
+          <span className="text-lg font-semibold text-foreground">
+            {branding.product_name}
+          </span>
+        )}
+        {!branding?.logo_url && !branding?.product_name && (
+          <>
+            <div className="block md:hidden w-8">
+              {theme.name === ThemeNames.STEAMPIPE_DEFAULT && <Logo />}
+              {theme.name === ThemeNames.STEAMPIPE_DARK && <LogoDarkmode />}
+            </div>
+            <div className="hidden md:block w-48">
+              {theme.name === ThemeNames.STEAMPIPE_DEFAULT && <LogoWordmark />}
+              {theme.name === ThemeNames.STEAMPIPE_DARK && (
+                <LogoWordmarkDarkmode />
+              )}
+            </div>
+          </>
+        )}
       </ExternalLink>
     </div>
   );
@@ -1,3 +1,4 @@
+import AutoRefreshToggle from "@powerpipe/components/AutoRefreshToggle";
 import DashboardSearch from "@powerpipe/components/DashboardSearch";
 import DashboardTagGroupSelect from "@powerpipe/components/DashboardTagGroupSelect";
 import ManageSearchPathButton from "@powerpipe/components/ManageSearchPathButton";
@@ -24,6 +25,7 @@ const DashboardHeader = () => {
           <DashboardTagGroupSelect />
           <SaveSnapshotButton />
           <OpenSnapshotButton />
+          <AutoRefreshToggle />
         </div>
         <div className="space-x-2 sm:space-x-4 md:space-x-8 flex items-center justify-end">
           <ExternalLink
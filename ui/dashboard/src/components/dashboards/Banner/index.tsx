@@ -0,0 +1,108 @@
+import DashboardIcon from "@powerpipe/components/dashboards/common/DashboardIcon";
+import { CardDataProcessor } from "@powerpipe/components/dashboards/data/CardDataProcessor";
+import {
+  ContainerDefinition,
+  PanelDefinition,
+  PanelsMap,
+} from "@powerpipe/types";
+import { classNames } from "@powerpipe/utils/styles";
+import {
+  getIconClasses,
+  getIconStyles,
+  getWrapperClasses,
+} from "@powerpipe/utils/card";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
+
+// A banner is a card with the "powerpipe:banner" option set to "true". Rather than being displayed in its
+// position in the layout, it is displayed across the full width of the top of the dashboard.
+//
+// The banner is driven by the card query - it is only displayed if the query returns a value, which is used
+// as the banner text, and the type of the card (e.g. alert, info or ok) determines the color, e.g.
+//
+//   select 'Critical controls are in alarm' as value, 'alert' as type
+//   from ... where severity = 'critical' and status = 'alarm' having count(*) > 0
+
+const isBannerPanel = (panel: PanelDefinition | undefined) =>
+  !!panel && panel.panel_type === "card" && panel.options?.banner === "true";
+
+// Returns the names of the banner panels in the dashboard, in layout order
+const getBannerPanelNames = (
+  layout: ContainerDefinition | PanelDefinition,
+  panelsMap: PanelsMap,
+): string[] => {
+  const names: string[] = [];
+  for (const child of layout.children || []) {
+    if (isBannerPanel(panelsMap[child.name])) {
+      names.push(child.name);
+    }
+    names.push(...getBannerPanelNames(child, panelsMap));
+  }
+  return names;
+};
+
+const Banner = ({ definition }: { definition: PanelDefinition }) => {
+  if (definition.hidden || definition.status !== "complete") {
+    return null;
+  }
+  const data = definition.data;
+  if (!data || !data.rows || data.rows.length === 0) {
+    return null;
+  }
+  const state = new CardDataProcessor().parseData(
+    data,
+    definition.display_type as any,
+    definition.properties || {},
+  );
+  if (state.value === null || state.value === undefined || state.value === "") {
+    return null;
+  }
+  return (
+    <div
+      className={classNames(
+        "flex items-center space-x-3 bg-dashboard-panel text-foreground shadow-sm p-3 pr-5 print:bg-white print:text-black",
+        getWrapperClasses(state.type),
+      )}
+      role="alert"
+    >
+      {state.icon && (
+        <DashboardIcon
+          className={classNames(
+            "h-6 w-6 shrink-0",
+            getIconClasses(state.type),
+          )}
+          icon={state.icon}
+          style={getIconStyles(state.type)}
+        />
+      )}
+      <div className="min-w-0">
+        {data.columns.length > 1 && state.label && (
+          <p className="font-semibold">{state.label}</p>
+        )}
+        <p>{state.value}</p>
+      </div>
+    </div>
+  );
+};
+
+const DashboardBanners = ({
+  definition,
+}: {
+  definition: ContainerDefinition | PanelDefinition;
+}) => {
+  const { panelsMap } = useDashboard();
+  const bannerPanelNames = getBannerPanelNames(definition, panelsMap);
+  if (bannerPanelNames.length === 0) {
+    return null;
+  }
+  return (
+    <div className="col-span-12 space-y-2 empty:hidden">
+      {bannerPanelNames.map((name) => (
+        <Banner key={name} definition={panelsMap[name]} />
+      ))}
+    </div>
+  );
+};
+
+export default DashboardBanners;
+
+export { isBannerPanel };
@@ -15,6 +15,7 @@ import {
   CardType,
 } from "../data/CardDataProcessor";
 import { classNames } from "@powerpipe/utils/styles";
+import { getSafeImageUrl } from "@powerpipe/utils/url";
 import { PanelDefinition, PanelProperties } from "@powerpipe/types";
 import { getComponent, registerComponent } from "../index";
 import {
@@ -124,6 +125,45 @@ const Value = ({ loading, value }) => {
   return <Label value={value} />;
 };
 
+const imageFits = ["contain", "cover", "fill", "none", "scale-down"];
+
+// An image card displays the value as an image. The value can be an http(s) URL, a relative URL
+// (e.g. a mod asset) or base64 image data. The image can be sized using the "powerpipe:image_height"
+// option (in pixels) and fitted using the "powerpipe:image_fit" option, which is one of
+// contain (default), cover, fill, none or scale-down.
+const ImageValue = ({ label, loading, options, value }) => {
+  if (loading) {
+    return null;
+  }
+  const src = getSafeImageUrl(value);
+  if (!src) {
+    return (
+      <DashboardIcon
+        className="h-8 w-8"
+        icon="materialsymbols-outline:broken_image"
+        title={value ? "Unsupported image URL" : undefined}
+      />
+    );
+  }
+  const fit =
+    options?.image_fit && imageFits.includes(options.image_fit)
+      ? options.image_fit
+      : "contain";
+  const height = Number(options?.image_height);
+  return (
+    <img
+      className="w-full"
+      src={src}
+      alt={label || ""}
+      referrerPolicy="no-referrer"
+      style={{
+        objectFit: fit,
+        height: !isNaN(height) && height > 0 ? `${height}px` : undefined,
+      }}
+    />
+  );
+};
+
 const CardDiffDisplay = ({ diff }: CardDiffDisplayProps) => {
   if (!diff || diff.direction === "none") {
     return null;
@@ -237,9 +277,20 @@ const Card = (props: CardProps) => {
               {state.loading ? "Loading..." : state.label}
             </p>
           </dt>
-          <dd className="font-semibold text-3xl mt-1 mb-1">
-            <Value loading={state.loading} value={state.value} />
-          </dd>
+          {state.type === "image" ? (
+            <dd className="mt-2">
+              <ImageValue
+                label={state.label}
+                loading={state.loading}
+                options={props.options}
+                value={state.value}
+              />
+            </dd>
+          ) : (
+            <dd className="font-semibold text-3xl mt-1 mb-1">
+              <Value loading={state.loading} value={state.value} />
+            </dd>
+          )}
         </div>
       </div>
     </div>
@@ -0,0 +1,193 @@
+import { classNames } from "@powerpipe/utils/styles";
+import { PanelOptions } from "@powerpipe/types";
+import { ReactNode } from "react";
+
+// Column display hints are set using "powerpipe:" tag options on the table, keyed by column name:
+//
+//   "powerpipe:column.<name>.format"   = "number[:<decimals>]" | "currency:<code>" | "percent[:<decimals>]" |
+//                                        "bytes" | "date" | "datetime" | "relative"
+//   "powerpipe:column.<name>.badge"    = "<value>=<color>,..." - display values as badges, "*" matches any value
+//   "powerpipe:column.<name>.truncate" = "<length>" - truncate values longer than length characters
+//
+// Badge colors are either a theme status (ok, alert, info, skip, severity) or a CSS color.
+// Links and wrapping are set using the href and wrap properties of the table column block.
+// The HTML and markdown dashboard exports apply the same hints (see internal/dashboardexport/column_format.go).
+export type TableColumnFormat = {
+  format?: string;
+  badge?: { [value: string]: string };
+  truncate?: number;
+};
+
+const columnOptionPrefix = "column.";
+
+const getColumnFormat = (
+  options: PanelOptions | undefined,
+  columnName: string,
+): TableColumnFormat | undefined => {
+  if (!options) {
+    return;
+  }
+  const prefix = `${columnOptionPrefix}${columnName}.`;
+  const format: TableColumnFormat = {};
+  for (const [key, value] of Object.entries(options)) {
+    if (!key.startsWith(prefix)) {
+      continue;
+    }
+    switch (key.substring(prefix.length)) {
+      case "format":
+        format.format = value;
+        break;
+      case "badge":
+        format.badge = Object.fromEntries(
+          value
+            .split(",")
+            .map((entry) => entry.split("=").map((part) => part.trim()))
+            .filter((parts) => parts.length === 2),
+        );
+        break;
+      case "truncate":
+        const length = parseInt(value);
+        if (!isNaN(length) && length > 0) {
+          format.truncate = length;
+        }
+        break;
+    }
+  }
+  return Object.keys(format).length > 0 ? format : undefined;
+};
+
+const relativeTimeUnits: [Intl.RelativeTimeFormatUnit, number][] = [
+  ["year", 365 * 24 * 60 * 60],
+  ["month", 30 * 24 * 60 * 60],
+  ["week", 7 * 24 * 60 * 60],
+  ["day", 24 * 60 * 60],
+  ["hour", 60 * 60],
+  ["minute", 60],
+  ["second", 1],
+];
+
+const formatRelativeTime = (date: Date) => {
+  const seconds = (date.getTime() - Date.now()) / 1000;
+  const rtf = new Intl.RelativeTimeFormat(undefined, { numeric: "auto" });
+  for (const [unit, unitSeconds] of relativeTimeUnits) {
+    if (Math.abs(seconds) >= unitSeconds || unit === "second") {
+      return rtf.format(Math.round(seconds / unitSeconds), unit);
+    }
+  }
+};
+
+const byteUnits = ["B", "KB", "MB", "GB", "TB", "PB"];
+
+const formatBytes = (bytes: number) => {
+  let value = bytes;
+  let unit = 0;
+  while (Math.abs(value) >= 1024 && unit < byteUnits.length - 1) {
+    value /= 1024;
+    unit++;
+  }
+  return `${value.toLocaleString(undefined, { maximumFractionDigits: 1 })} ${
+    byteUnits[unit]
+  }`;
+};
+
+// Format the value using the column format - if the value cannot be formatted, it is returned unchanged
+const formatValue = (value: any, format: string | undefined) => {
+  if (!format || value === null || value === undefined) {
+    return value;
+  }
+  const [type, arg] = format.split(":").map((part) => part.trim());
+  switch (type) {
+    case "number":
+    case "percent": {
+      const number = Number(value);
+      if (isNaN(number)) {
+        return value;
+      }
+      const decimals = arg !== undefined ? parseInt(arg) : undefined;
+      return number.toLocaleString(undefined, {
+        style: type === "percent" ? "percent" : "decimal",
+        minimumFractionDigits: decimals,
+        maximumFractionDigits: decimals,
+      });
+    }
+    case "currency": {
+      const number = Number(value);
+      if (isNaN(number)) {
+        return value;
+      }
+      try {
+        return number.toLocaleString(undefined, {
+          style: "currency",
+          currency: arg || "USD",
+        });
+      } catch {
+        return value;
+      }
+    }
+    case "bytes": {
+      const number = Number(value);
+      return isNaN(number) ? value : formatBytes(number);
+    }
+    case "date":
+    case "datetime":
+    case "relative": {
+      const date = new Date(value);
+      if (isNaN(date.getTime())) {
+        return value;
+      }
+      if (type === "date") {
+        return date.toLocaleDateString();
+      }
+      if (type === "datetime") {
+        return date.toLocaleString();
+      }
+      return formatRelativeTime(date);
+    }
+  }
+  return value;
+};
+
+const truncateValue = (value: any, length: number | undefined) => {
+  const str = String(value);
+  return length && str.length > length ? `${str.substring(0, length)}…` : str;
+};
+
+const themeBadgeClasses = {
+  alert: "bg-alert text-alert-inverse",
+  info: "bg-info text-info-inverse",
+  ok: "bg-ok text-ok-inverse",
+  severity: "bg-severity text-white",
+  skip: "bg-skip text-white",
+};
+
+const Badge = ({ color, children }: { color: string; children: ReactNode }) => (
+  <span
+    className={classNames(
+      "inline-block rounded-md px-2 py-0.5 text-xs font-medium",
+      themeBadgeClasses[color] || "text-white",
+    )}
+    style={themeBadgeClasses[color] ? undefined : { backgroundColor: color }}
+  >
+    {children}
+  </span>
+);
+
+// Render the value using the column format, returning null if the column has no format
+const renderFormattedValue = (
+  value: any,
+  columnFormat: TableColumnFormat | undefined,
+) => {
+  if (!columnFormat || value === null || value === undefined) {
+    return null;
+  }
+  const display = truncateValue(
+    formatValue(value, columnFormat.format),
+    columnFormat.truncate,
+  );
+  const badgeColor = columnFormat.badge
+    ? columnFormat.badge[String(value)] || columnFormat.badge["*"]
+    : null;
+  return badgeColor ? <Badge color={badgeColor}>{display}</Badge> : display;
+};
+
+export { formatValue, getColumnFormat, renderFormattedValue };
@@ -3,6 +3,7 @@ import isEmpty from "lodash/isEmpty";
 import isObject from "lodash/isObject";
 import useDeepCompareEffect from "use-deep-compare-effect";
 import useTemplateRender from "@powerpipe/hooks/useTemplateRender";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
 import {
   AlarmIcon,
   InfoIcon,
@@ -15,9 +16,15 @@ import {
   ExecutablePrimitiveProps,
   isNumericCol,
   LeafNodeDataColumn,
+  LeafNodeDataPagination,
   LeafNodeDataRow,
 } from "../common";
 import { classNames } from "@powerpipe/utils/styles";
+import {
+  getColumnFormat,
+  renderFormattedValue,
+  TableColumnFormat,
+} from "./columnFormat";
 import {
   ErrorIcon,
   SortAscendingIcon,
@@ -25,8 +32,14 @@ import {
 } from "@powerpipe/constants/icons";
 import { memo, useEffect, useMemo, useState } from "react";
 import { getComponent, registerComponent } from "../index";
-import { PanelDefinition } from "@powerpipe/types";
+import {
+  DashboardDataModeLive,
+  IDashboardContext,
+  PanelDefinition,
+  PanelOptions,
+} from "@powerpipe/types";
 import { RowRenderResult } from "../common/types";
+import { useNavigate } from "react-router-dom";
 import { useSortBy, useTable } from "react-table";
 
 export type TableColumnDisplay = "all" | "none";
@@ -41,12 +54,14 @@ type TableColumnInfo = {
   display?: "all" | "none";
   wrap: TableColumnWrap;
   href_template?: string;
+  format?: TableColumnFormat;
   sortType?: any;
 };
 
 const getColumns = (
   cols: LeafNodeDataColumn[],
   properties?: TableProperties,
+  options?: PanelOptions,
 ): { columns: TableColumnInfo[]; hiddenColumns: string[] } => {
   if (!cols || cols.length === 0) {
     return { columns: [], hiddenColumns: [] };
@@ -87,6 +102,10 @@ const getColumns = (
     if (colHref) {
       colInfo.href_template = colHref;
     }
+    const colFormat = getColumnFormat(options, col.original_name || col.name);
+    if (colFormat) {
+      colInfo.format = colFormat;
+    }
     return colInfo;
   });
   return { columns, hiddenColumns };
@@ -149,7 +168,25 @@ const CellValue = ({
 
   let cellContent;
   const dataType = column.data_type.toLowerCase();
-  if (value === null || value === undefined) {
+  const formattedValue = renderFormattedValue(value, column.format);
+  if (formattedValue !== null) {
+    cellContent = href ? (
+      <ExternalLink
+        to={href}
+        className="link-highlight tabular-nums"
+        title={showTitle ? `${column.title}=${value}` : String(value)}
+      >
+        <>{formattedValue}</>
+      </ExternalLink>
+    ) : (
+      <span
+        className="tabular-nums"
+        title={showTitle ? `${column.title}=${value}` : String(value)}
+      >
+        {formattedValue}
+      </span>
+    );
+  } else if (value === null || value === undefined) {
     cellContent = href ? (
       <ExternalLink
         to={href}
@@ -368,20 +405,78 @@ export type TableProps = PanelDefinition &
     properties?: TableProperties;
   };
 
+// the key of the row href in the rendered row templates
+// (set using the "powerpipe:href" option on the table, this makes the whole row link to the rendered href)
+const rowHrefKey = "__row_href__";
+
 const TableView = ({
   rowData,
   columns,
   hiddenColumns,
   hasTopBorder = false,
+  rowHrefTemplate,
+  name,
+  pagination,
+}: {
+  rowData: any;
+  columns: any;
+  hiddenColumns: any;
+  hasTopBorder?: boolean;
+  rowHrefTemplate?: string;
+  name?: string;
+  pagination?: LeafNodeDataPagination;
 }) => {
+  const navigate = useNavigate();
+  const { requestTablePage } = useDashboard();
   const { ready: templateRenderReady, renderTemplates } = useTemplateRender();
   const [rowTemplateData, setRowTemplateData] = useState<RowRenderResult[]>([]);
 
-  const { getTableProps, getTableBodyProps, headerGroups, prepareRow, rows } =
-    useTable(
-      { columns, data: rowData, initialState: { hiddenColumns } },
-      useSortBy,
-    );
+  // if the rows are a page of the results, sorting is performed by the server
+  const {
+    getTableProps,
+    getTableBodyProps,
+    headerGroups,
+    prepareRow,
+    rows,
+    state: { sortBy },
+  } = useTable(
+    {
+      columns,
+      data: rowData,
+      initialState: {
+        hiddenColumns,
+        sortBy: pagination?.sort_column
+          ? [
+              {
+                id: pagination.sort_column,
+                desc: pagination.sort_direction === "desc",
+              },
+            ]
+          : [],
+      },
+      manualSortBy: !!pagination,
+      disableMultiSort: !!pagination,
+      autoResetSortBy: false,
+    },
+    useSortBy,
+  );
+
+  // when the sort of a paginated table changes, request the first page with the new sort
+  useEffect(() => {
+    if (!pagination || !name) {
+      return;
+    }
+    const sortColumn = sortBy[0]?.id;
+    const sortDirection = sortBy[0]?.desc ? "desc" : "asc";
+    if (
+      sortColumn === pagination.sort_column &&
+      (!sortColumn || sortDirection === pagination.sort_direction)
+    ) {
+      return;
+    }
+    requestTablePage(name, 0, sortColumn, sortColumn ? sortDirection : undefined);
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [sortBy]);
 
   useDeepCompareEffect(() => {
     if (!templateRenderReady || columns.length === 0 || rows.length === 0) {
@@ -395,6 +490,9 @@ const TableView = ({
           .filter((col) => col.display !== "none" && !!col.href_template)
           .map((col) => [col.name, col.href_template as string]),
       );
+      if (rowHrefTemplate) {
+        templates[rowHrefKey] = rowHrefTemplate;
+      }
       if (isEmpty(templates)) {
         setRowTemplateData([]);
         return;
@@ -405,7 +503,16 @@ const TableView = ({
     };
 
     doRender();
-  }, [columns, renderTemplates, rows, templateRenderReady]);
+  }, [columns, renderTemplates, rowHrefTemplate, rows, templateRenderReady]);
+
+  const onRowClick = (e, index: number) => {
+    const rowHref = rowTemplateData[index]?.[rowHrefKey]?.result;
+    // links within cells take precedence over the row link
+    if (!rowHref || (e.target as HTMLElement).closest("a")) {
+      return;
+    }
+    navigate(rowHref);
+  };
 
   return (
     <>
@@ -461,7 +568,15 @@ const TableView = ({
           {rows.map((row, index) => {
             prepareRow(row);
             return (
-              <tr {...row.getRowProps()}>
+              <tr
+                {...row.getRowProps()}
+                className={
+                  rowTemplateData[index]?.[rowHrefKey]?.result
+                    ? "cursor-pointer hover:bg-black-scale-1"
+                    : undefined
+                }
+                onClick={(e) => onRowClick(e, index)}
+              >
                 {row.cells.map((cell) => (
                   <td
                     {...cell.getCellProps()}
@@ -486,15 +601,80 @@ const TableView = ({
           })}
         </tbody>
       </table>
+      {pagination && name && (
+        <TablePager
+          name={name}
+          pagination={pagination}
+          requestTablePage={requestTablePage}
+        />
+      )}
     </>
   );
 };
 
+const TablePager = ({
+  name,
+  pagination,
+  requestTablePage,
+}: {
+  name: string;
+  pagination: LeafNodeDataPagination;
+  requestTablePage: IDashboardContext["requestTablePage"];
+}) => {
+  const { dataMode } = useDashboard();
+  const { page, page_size, total_rows, sort_column, sort_direction } =
+    pagination;
+  const pageCount = Math.max(1, Math.ceil(total_rows / page_size));
+  const firstRow = total_rows === 0 ? 0 : page * page_size + 1;
+  const lastRow = Math.min((page + 1) * page_size, total_rows);
+  // snapshots only contain the page that was displayed when the snapshot was taken
+  const canPage = dataMode === DashboardDataModeLive;
+  const goToPage = (newPage: number) =>
+    requestTablePage(name, newPage, sort_column, sort_direction);
+
+  return (
+    <div className="flex items-center justify-between px-4 py-2 border-t border-divide text-sm text-foreground-light">
+      <span className="tabular-nums">
+        {firstRow.toLocaleString()}-{lastRow.toLocaleString()} of{" "}
+        {total_rows.toLocaleString()}
+      </span>
+      {canPage && pageCount > 1 && (
+        <div className="flex items-center space-x-4">
+          <button
+            type="button"
+            className="link-highlight disabled:text-foreground-lightest disabled:cursor-not-allowed"
+            disabled={page === 0}
+            onClick={() => goToPage(page - 1)}
+          >
+            Previous
+          </button>
+          <span className="tabular-nums">
+            Page {(page + 1).toLocaleString()} of {pageCount.toLocaleString()}
+          </span>
+          <button
+            type="button"
+            className="link-highlight disabled:text-foreground-lightest disabled:cursor-not-allowed"
+            disabled={page >= pageCount - 1}
+            onClick={() => goToPage(page + 1)}
+          >
+            Next
+          </button>
+        </div>
+      )}
+    </div>
+  );
+};
+
 // TODO retain full width on mobile, no padding
 const TableViewWrapper = (props: TableProps) => {
   const { columns, hiddenColumns } = useMemo(
-    () => getColumns(props.data ? props.data.columns : [], props.properties),
-    [props.data, props.properties],
+    () =>
+      getColumns(
+        props.data ? props.data.columns : [],
+        props.properties,
+        props.options,
+      ),
+    [props.data, props.options, props.properties],
   );
   const rowData = useMemo(
     () => getData(columns, props.data ? props.data.rows : []),
@@ -507,6 +687,9 @@ const TableViewWrapper = (props: TableProps) => {
       columns={columns}
       hiddenColumns={hiddenColumns}
       hasTopBorder={!!props.title}
+      rowHrefTemplate={props.options?.href}
+      name={props.name}
+      pagination={props.data.pagination}
     />
   ) : null;
 };
@@ -536,13 +719,14 @@ const LineView = (props: TableProps) => {
         display: columnOverrides?.display ? columnOverrides.display : "all",
         wrap: columnOverrides?.wrap ? columnOverrides.wrap : "none",
         href_template: columnOverrides?.href,
+        format: getColumnFormat(props.options, col.original_name || col.name),
       };
       newColumns.push(newColDef);
     });
 
     setColumns(newColumns);
     setRows(props.data.rows);
-  }, [props.data, props.properties]);
+  }, [props.data, props.options, props.properties]);
 
   useDeepCompareEffect(() => {
     if (!templateRenderReady || columns.length === 0 || rows.length === 0) {
@@ -1,20 +1,27 @@
 import * as echarts from "echarts/core";
 import {
   BarChart,
+  GaugeChart,
   GraphChart,
+  HeatmapChart,
   LineChart,
+  MapChart,
   PieChart,
   SankeyChart,
+  ScatterChart,
   TreeChart,
+  TreemapChart,
 } from "echarts/charts";
 import { CanvasRenderer } from "echarts/renderers";
 import {
   DatasetComponent,
+  GeoComponent,
   GridComponent,
   LegendComponent,
   TitleComponent,
   TooltipComponent,
   MarkLineComponent,
+  VisualMapComponent,
 } from "echarts/components";
 import { LabelLayout } from "echarts/features";
 
@@ -22,17 +29,24 @@ echarts.use([
   BarChart,
   CanvasRenderer,
   DatasetComponent,
+  GaugeChart,
+  GeoComponent,
   GraphChart,
   GridComponent,
+  HeatmapChart,
   LabelLayout,
   LegendComponent,
   LineChart,
+  MapChart,
   MarkLineComponent,
   PieChart,
   SankeyChart,
+  ScatterChart,
   TitleComponent,
   TooltipComponent,
   TreeChart,
+  TreemapChart,
+  VisualMapComponent,
 ]);
 
 export { echarts };
@@ -15,6 +15,10 @@ import {
   Width,
 } from "@powerpipe/components/dashboards/common";
 import { EChartsOption } from "echarts-for-react/src/types";
+import {
+  buildStructuredChartOptions,
+  isStructuredChartType,
+} from "./structuredCharts";
 import {
   ChartProperties,
   ChartProps,
@@ -701,6 +705,14 @@ const adjustGridConfig = (
 };
 
 const buildChartOptions = (props: ChartProps, themeColors: any) => {
+  // charts which do not plot series against axes are built directly from the rows
+  if (isStructuredChartType(props.display_type)) {
+    return merge(
+      getCommonBaseOptions(),
+      { legend: { show: false } },
+      buildStructuredChartOptions(props, themeColors),
+    );
+  }
   const { dataset, rowSeriesLabels, transform } = buildChartDataset(
     props.data,
     props.properties,
@@ -746,9 +758,37 @@ const buildChartOptions = (props: ChartProps, themeColors: any) => {
 type ChartComponentProps = {
   options: EChartsOption;
   type: ChartType | FlowType | GraphType | HierarchyType;
+  // the data of the chart and the template to link each datapoint to
+  // (set using the "powerpipe:href" option on the chart)
+  data?: LeafNodeData;
+  hrefTemplate?: string;
+};
+
+// Build the data used to render the href template for a clicked datapoint. This is the query row
+// the datapoint was plotted from (matched on the first column), plus the series name and value.
+const getDatumTemplateData = (params: any, data?: LeafNodeData) => {
+  const firstColumn = data?.columns[0]?.name;
+  const row =
+    firstColumn && params.name !== undefined
+      ? data?.rows.find((r) => String(r[firstColumn]) === String(params.name))
+      : null;
+  return {
+    ...(row || {}),
+    name: params.name,
+    series_name: params.seriesName,
+    value: Array.isArray(params.value)
+      ? params.value[params.value.length - 1]
+      : params.value,
+  };
 };
 
-const handleClick = async (params: any, navigate, renderTemplates) => {
+const handleClick = async (
+  params: any,
+  navigate,
+  renderTemplates,
+  hrefTemplate?: string,
+  data?: LeafNodeData,
+) => {
   const componentType = params.componentType;
   if (componentType !== "series") {
     return;
@@ -766,10 +806,23 @@ const handleClick = async (params: any, navigate, renderTemplates) => {
       );
       let rowRenderResult = renderedResults[0];
       navigate(rowRenderResult.graph_node.result);
+      break;
+    default:
+      if (!hrefTemplate) {
+        return;
+      }
+      const renderedDatumResults = await renderTemplates(
+        { datum: hrefTemplate },
+        [getDatumTemplateData(params, data)],
+      );
+      const datumHref = renderedDatumResults[0]?.datum?.result;
+      if (datumHref) {
+        navigate(datumHref);
+      }
   }
 };
 
-const Chart = ({ options, type }: ChartComponentProps) => {
+const Chart = ({ options, type, data, hrefTemplate }: ChartComponentProps) => {
   const [echarts, setEcharts] = useState<any | null>(null);
   const navigate = useNavigate();
   const chartRef = useRef<ReactEChartsCore>(null);
@@ -800,7 +853,8 @@ const Chart = ({ options, type }: ChartComponentProps) => {
   }
 
   const eventsDict = {
-    click: (params) => handleClick(params, navigate, renderTemplates),
+    click: (params) =>
+      handleClick(params, navigate, renderTemplates, hrefTemplate, data),
   };
 
   const PlaceholderComponent = Placeholder.component;
@@ -819,7 +873,11 @@ const Chart = ({ options, type }: ChartComponentProps) => {
               notMerge={true}
               lazyUpdate={true}
               style={
-                type === "pie" || type === "donut" ? { height: "250px" } : {}
+                type === "pie" || type === "donut" || type === "gauge"
+                  ? { height: "250px" }
+                  : type === "map"
+                    ? { height: "400px" }
+                    : {}
               }
             />
           </div>
@@ -852,6 +910,8 @@ const ChartWrapper = (props: ChartProps) => {
     <Chart
       options={buildChartOptions(props, themeColors)}
       type={props.display_type || "column"}
+      data={props.data}
+      hrefTemplate={props.options?.href}
     />
   );
 };
@@ -0,0 +1,261 @@
+import has from "lodash/has";
+import {
+  ChartProps,
+  ChartType,
+} from "@powerpipe/components/dashboards/charts/types";
+import {
+  getColorOverride,
+  LeafNodeData,
+} from "@powerpipe/components/dashboards/common";
+
+// Structured charts do not plot series against axes, so they are built directly from the rows
+// rather than from a dataset:
+//   heatmap - columns: x, y, value
+//   sankey  - columns: source, target, value
+//   treemap - columns: name, value or group, name, value
+//   gauge   - columns: value or label, value (first row only)
+const structuredChartTypes: ChartType[] = [
+  "gauge",
+  "heatmap",
+  "sankey",
+  "treemap",
+];
+
+const isStructuredChartType = (type: ChartType | undefined) =>
+  !!type && structuredChartTypes.includes(type);
+
+const getColumnValues = (data: LeafNodeData) => {
+  const columnNames = data.columns.map((col) => col.name);
+  return data.rows.map((row) => columnNames.map((name) => row[name]));
+};
+
+const getSeriesColor = (props: ChartProps, themeColors, index: number) => {
+  const seriesName = props.data?.columns[props.data.columns.length - 1].name;
+  const seriesOverrides =
+    seriesName && props.properties?.series
+      ? props.properties.series[seriesName]
+      : null;
+  if (seriesOverrides && seriesOverrides.color) {
+    return getColorOverride(seriesOverrides.color, themeColors);
+  }
+  return themeColors.charts[index % themeColors.charts.length];
+};
+
+const buildHeatmapOptions = (props: ChartProps, themeColors) => {
+  const rows = getColumnValues(props.data as LeafNodeData);
+  const xValues: string[] = [];
+  const yValues: string[] = [];
+  let min = Infinity;
+  let max = -Infinity;
+  for (const [x, y, value] of rows) {
+    if (!xValues.includes(x)) {
+      xValues.push(x);
+    }
+    if (!yValues.includes(y)) {
+      yValues.push(y);
+    }
+    min = Math.min(min, value);
+    max = Math.max(max, value);
+  }
+  const axisOptions = {
+    axisLabel: { color: themeColors.foreground, fontSize: 10 },
+    axisLine: { lineStyle: { color: themeColors.foregroundLightest } },
+    axisTick: { show: false },
+    splitArea: { show: true },
+    nameLocation: "center",
+    nameTextStyle: { color: themeColors.foreground },
+  };
+  return {
+    grid: { top: "5%", bottom: "20%" },
+    xAxis: {
+      ...axisOptions,
+      type: "category",
+      data: xValues,
+      nameGap: 30,
+      name: props.properties?.axes?.x?.title?.value,
+    },
+    yAxis: {
+      ...axisOptions,
+      type: "category",
+      data: yValues,
+      nameGap: 50,
+      name: props.properties?.axes?.y?.title?.value,
+    },
+    visualMap: {
+      min: has(props.properties, "axes.y.min")
+        ? props.properties?.axes?.y.min
+        : min === Infinity
+          ? 0
+          : min,
+      max: has(props.properties, "axes.y.max")
+        ? props.properties?.axes?.y.max
+        : max === -Infinity
+          ? 0
+          : max,
+      calculable: true,
+      orient: "horizontal",
+      left: "center",
+      bottom: 0,
+      itemHeight: 100,
+      textStyle: { color: themeColors.foreground, fontSize: 10 },
+      inRange: {
+        color: [
+          themeColors.dashboardPanel,
+          getSeriesColor(props, themeColors, 0),
+        ],
+      },
+    },
+    series: [
+      {
+        type: "heatmap",
+        data: rows.map(([x, y, value]) => [
+          xValues.indexOf(x),
+          yValues.indexOf(y),
+          value,
+        ]),
+        label: { show: false },
+        itemStyle: {
+          borderColor: themeColors.dashboardPanel,
+          borderWidth: 1,
+        },
+      },
+    ],
+  };
+};
+
+const buildSankeyOptions = (props: ChartProps, themeColors) => {
+  const rows = getColumnValues(props.data as LeafNodeData);
+  const nodeNames: string[] = [];
+  for (const [source, target] of rows) {
+    for (const name of [source, target]) {
+      if (!nodeNames.includes(name)) {
+        nodeNames.push(name);
+      }
+    }
+  }
+  return {
+    color: themeColors.charts,
+    series: [
+      {
+        type: "sankey",
+        top: "5%",
+        bottom: "5%",
+        emphasis: { focus: "adjacency" },
+        nodeAlign: "left",
+        data: nodeNames.map((name) => ({ name })),
+        links: rows.map(([source, target, value]) => ({
+          source,
+          target,
+          value,
+        })),
+        label: { color: themeColors.foreground, fontSize: 10 },
+        lineStyle: { color: "gradient", curveness: 0.5, opacity: 0.3 },
+      },
+    ],
+  };
+};
+
+const buildTreemapOptions = (props: ChartProps, themeColors) => {
+  const data = props.data as LeafNodeData;
+  const rows = getColumnValues(data);
+  let treeData: any[];
+  if (data.columns.length >= 3) {
+    // group, name, value - build a two level tree
+    const groups: { [name: string]: any } = {};
+    treeData = [];
+    for (const [group, name, value] of rows) {
+      if (!groups[group]) {
+        groups[group] = { name: group, children: [] };
+        treeData.push(groups[group]);
+      }
+      groups[group].children.push({ name, value });
+    }
+  } else {
+    treeData = rows.map(([name, value]) => ({ name, value }));
+  }
+  return {
+    color: themeColors.charts,
+    series: [
+      {
+        type: "treemap",
+        top: "5%",
+        bottom: "10%",
+        left: 0,
+        right: 0,
+        roam: false,
+        data: treeData,
+        breadcrumb: {
+          itemStyle: {
+            color: themeColors.dashboardPanel,
+            textStyle: { color: themeColors.foreground },
+          },
+        },
+        label: { fontSize: 10 },
+        upperLabel: { show: data.columns.length >= 3, height: 20 },
+        itemStyle: { borderColor: themeColors.dashboardPanel, gapWidth: 1 },
+        levels: [
+          { colorSaturation: [0.35, 0.5] },
+          { colorSaturation: [0.35, 0.5], itemStyle: { gapWidth: 1 } },
+        ],
+      },
+    ],
+  };
+};
+
+const buildGaugeOptions = (props: ChartProps, themeColors) => {
+  const data = props.data as LeafNodeData;
+  const row = getColumnValues(data)[0] || [];
+  const value = row[row.length - 1];
+  const label = row.length > 1 ? row[0] : data.columns[0]?.name;
+  const color = getSeriesColor(props, themeColors, 0);
+  return {
+    series: [
+      {
+        type: "gauge",
+        min: has(props.properties, "axes.y.min")
+          ? props.properties?.axes?.y.min
+          : 0,
+        max: has(props.properties, "axes.y.max")
+          ? props.properties?.axes?.y.max
+          : 100,
+        center: ["50%", "60%"],
+        progress: { show: true, width: 12, itemStyle: { color } },
+        axisLine: {
+          lineStyle: {
+            width: 12,
+            color: [[1, themeColors.foregroundLightest]],
+          },
+        },
+        axisTick: { show: false },
+        splitLine: { length: 8, lineStyle: { color: themeColors.foreground } },
+        axisLabel: { color: themeColors.foreground, fontSize: 10 },
+        pointer: { show: false },
+        title: { color: themeColors.foreground, fontSize: 12 },
+        detail: {
+          color: themeColors.foreground,
+          fontSize: 20,
+          offsetCenter: [0, "20%"],
+          valueAnimation: false,
+        },
+        data: value === undefined ? [] : [{ name: label, value }],
+      },
+    ],
+  };
+};
+
+const buildStructuredChartOptions = (props: ChartProps, themeColors) => {
+  switch (props.display_type) {
+    case "gauge":
+      return buildGaugeOptions(props, themeColors);
+    case "heatmap":
+      return buildHeatmapOptions(props, themeColors);
+    case "sankey":
+      return buildSankeyOptions(props, themeColors);
+    case "treemap":
+      return buildTreemapOptions(props, themeColors);
+    default:
+      return {};
+  }
+};
+
+export { buildStructuredChartOptions, isStructuredChartType };
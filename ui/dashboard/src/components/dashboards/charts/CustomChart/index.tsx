@@ -0,0 +1,184 @@
+import ErrorPanel from "@powerpipe/components/dashboards/Error";
+import {
+  ChartProps,
+  IChart,
+} from "@powerpipe/components/dashboards/charts/types";
+import { LeafNodeData } from "@powerpipe/components/dashboards/common";
+import { registerChartComponent } from "@powerpipe/components/dashboards/charts";
+import { useEffect, useMemo, useRef, useState } from "react";
+
+// A custom chart renders a user-provided visualization in a sandboxed iframe. It is configured using
+// "powerpipe:" tag options on a chart with type "custom":
+//
+//   "powerpipe:custom_html" = an HTML document, which receives the query data as a message event
+//                             with data {type: "powerpipe:data", data: {columns, rows}}
+//   "powerpipe:vega_lite"   = a Vega-Lite JSON specification, rendered with the query rows as the "table" dataset
+//
+// The iframe is sandboxed without "allow-same-origin", so its content runs in an opaque origin and cannot
+// read the dashboard, its cookies or its storage, and it cannot navigate the dashboard itself.
+// The content security policy stops the content loading any script other than the pinned Vega builds
+// below, and blocks fetches, images and styles from the network. This is not a guarantee that the query
+// data stays in the browser - the frame can still navigate itself (e.g. by setting window.location) to
+// another site - so only add custom charts from mods you trust.
+// Either can set the height of the panel by posting {type: "powerpipe:resize", height} to the parent.
+
+const dataMessageType = "powerpipe:data";
+const resizeMessageType = "powerpipe:resize";
+const defaultHeight = 400;
+
+// The exact builds of the Vega libraries - these are the only scripts the content security policy allows
+// to be loaded, so the policy does not open up the rest of the CDN
+const vegaScripts = [
+  "https://cdn.jsdelivr.net/npm/vega@5.25.0/build/vega.min.js",
+  "https://cdn.jsdelivr.net/npm/vega-lite@5.16.3/build/vega-lite.min.js",
+  "https://cdn.jsdelivr.net/npm/vega-embed@6.23.0/build/vega-embed.min.js",
+];
+
+const contentSecurityPolicy = [
+  "default-src 'none'",
+  `script-src 'unsafe-inline' ${vegaScripts.join(" ")}`,
+  "style-src 'unsafe-inline'",
+  "img-src data: blob:",
+  "font-src data:",
+  "base-uri 'none'",
+  "form-action 'none'",
+].join("; ");
+
+const cspMeta = `<meta http-equiv="Content-Security-Policy" content="${contentSecurityPolicy}">`;
+
+// Posts the height of the document to the parent whenever it changes
+const resizeScript = `<script>
+new ResizeObserver(function () {
+  parent.postMessage({ type: "${resizeMessageType}", height: document.documentElement.scrollHeight }, "*");
+}).observe(document.documentElement);
+</script>`;
+
+const buildVegaLiteDocument = (spec: string) => `<!DOCTYPE html>
+<html>
+<head>
+${cspMeta}
+${vegaScripts.map((src) => `<script src="${src}"></script>`).join("\n")}
+<style>body { margin: 0; }</style>
+</head>
+<body>
+<div id="vis"></div>
+<script>
+var spec = ${spec};
+window.addEventListener("message", function (event) {
+  if (!event.data || event.data.type !== "${dataMessageType}") {
+    return;
+  }
+  var s = Object.assign({}, spec, { data: { name: "table" } });
+  vegaEmbed("#vis", s, { actions: false }).then(function (result) {
+    result.view.data("table", event.data.data.rows).run();
+  });
+});
+</script>
+${resizeScript}
+</body>
+</html>`;
+
+// The CSP meta is placed first so that it applies to the whole of the user's document
+const buildHtmlDocument = (html: string) =>
+  `${cspMeta}\n${html}\n${resizeScript}`;
+
+const getDocument = (props: ChartProps) => {
+  const html = props.options?.custom_html;
+  const vegaLite = props.options?.vega_lite;
+  if (html) {
+    return { document: buildHtmlDocument(html) };
+  }
+  if (vegaLite) {
+    try {
+      // validate and normalise the spec before embedding it in the document,
+      // escaping "<" so the spec cannot close the script element
+      const spec = JSON.stringify(JSON.parse(vegaLite)).replace(
+        /</g,
+        "\\u003c",
+      );
+      return { document: buildVegaLiteDocument(spec) };
+    } catch (err) {
+      return {
+        error: `Invalid powerpipe:vega_lite specification: ${
+          (err as Error).message
+        }`,
+      };
+    }
+  }
+  return {
+    error:
+      "Custom charts require a powerpipe:custom_html or powerpipe:vega_lite option",
+  };
+};
+
+const postData = (frame: HTMLIFrameElement | null, data: LeafNodeData) => {
+  // the sandboxed frame has an opaque origin, so we cannot restrict the target origin
+  frame?.contentWindow?.postMessage({ type: dataMessageType, data }, "*");
+};
+
+const CustomChart = (props: ChartProps) => {
+  const frameRef = useRef<HTMLIFrameElement>(null);
+  const [loaded, setLoaded] = useState(false);
+  const [height, setHeight] = useState(defaultHeight);
+  const { document, error } = useMemo(
+    () => getDocument(props),
+    [props.options?.custom_html, props.options?.vega_lite],
+  );
+
+  useEffect(() => {
+    setLoaded(false);
+  }, [document]);
+
+  useEffect(() => {
+    if (!loaded || !props.data) {
+      return;
+    }
+    postData(frameRef.current, props.data);
+  }, [loaded, props.data]);
+
+  useEffect(() => {
+    const onMessage = (event: MessageEvent) => {
+      if (
+        event.source !== frameRef.current?.contentWindow ||
+        event.data?.type !== resizeMessageType
+      ) {
+        return;
+      }
+      const newHeight = Number(event.data.height);
+      if (!isNaN(newHeight) && newHeight > 0) {
+        setHeight(newHeight);
+      }
+    };
+    window.addEventListener("message", onMessage);
+    return () => window.removeEventListener("message", onMessage);
+  }, []);
+
+  if (error) {
+    return <ErrorPanel error={error} />;
+  }
+
+  if (!props.data) {
+    return null;
+  }
+
+  return (
+    <iframe
+      ref={frameRef}
+      title={props.title || props.name}
+      className="w-full border-0"
+      style={{ height }}
+      sandbox="allow-scripts"
+      srcDoc={document}
+      onLoad={() => setLoaded(true)}
+    />
+  );
+};
+
+const definition: IChart = {
+  type: "custom",
+  component: CustomChart,
+};
+
+registerChartComponent(definition.type, definition);
+
+export default definition;
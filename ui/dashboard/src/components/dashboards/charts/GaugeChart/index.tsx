@@ -0,0 +1,19 @@
+import Chart from "@powerpipe/components/dashboards/charts/Chart";
+import {
+  ChartProps,
+  IChart,
+} from "@powerpipe/components/dashboards/charts/types";
+import { registerChartComponent } from "@powerpipe/components/dashboards/charts";
+
+const GaugeChart = (props: ChartProps) => {
+  return <Chart {...props} />;
+};
+
+const definition: IChart = {
+  type: "gauge",
+  component: GaugeChart,
+};
+
+registerChartComponent(definition.type, definition);
+
+export default definition;
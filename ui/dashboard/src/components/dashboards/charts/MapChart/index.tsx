@@ -0,0 +1,103 @@
+import ErrorPanel from "@powerpipe/components/dashboards/Error";
+import useChartThemeColors from "@powerpipe/hooks/useChartThemeColors";
+import { buildMapOptions, getMapMode } from "./mapOptions";
+import { Chart } from "@powerpipe/components/dashboards/charts/Chart";
+import {
+  ChartProps,
+  IChart,
+} from "@powerpipe/components/dashboards/charts/types";
+import { registerChartComponent } from "@powerpipe/components/dashboards/charts";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
+import { useEffect, useState } from "react";
+
+// Load the GeoJSON base map and register it with echarts, using the URL as the map name
+const useBaseMap = (url: string | undefined) => {
+  const [mapName, setMapName] = useState<string | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!url) {
+      setMapName(null);
+      return;
+    }
+    let cancelled = false;
+    Promise.all([
+      fetch(url).then((response) => {
+        if (!response.ok) {
+          throw new Error(`${response.status} ${response.statusText}`);
+        }
+        return response.json();
+      }),
+      import("../Chart/echarts"),
+    ])
+      .then(([geoJSON, m]) => {
+        if (cancelled) {
+          return;
+        }
+        m.echarts.registerMap(url, geoJSON);
+        setError(null);
+        setMapName(url);
+      })
+      .catch((err) => {
+        if (!cancelled) {
+          setError(`Unable to load map ${url}: ${err.message}`);
+        }
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [url]);
+
+  return { mapName, error };
+};
+
+const MapChart = (props: ChartProps) => {
+  const {
+    themeContext: { wrapperRef },
+  } = useDashboard();
+  const themeColors = useChartThemeColors();
+  const geoJSONUrl = props.options?.map_geojson;
+  const { mapName, error } = useBaseMap(geoJSONUrl);
+
+  if (!wrapperRef || !props.data) {
+    return null;
+  }
+
+  if (error) {
+    return <ErrorPanel error={error} />;
+  }
+
+  if (getMapMode(props) === "choropleth") {
+    if (!geoJSONUrl) {
+      return (
+        <ErrorPanel error="Choropleth maps require a powerpipe:map_geojson option" />
+      );
+    }
+    // wait for the base map to load
+    if (!mapName) {
+      return null;
+    }
+  }
+
+  return (
+    <Chart
+      options={{
+        animation: false,
+        tooltip: { trigger: "item" },
+        ...buildMapOptions(props, themeColors, mapName),
+      }}
+      type="map"
+      data={props.data}
+      hrefTemplate={props.options?.href}
+    />
+  );
+};
+
+const definition: IChart = {
+  type: "map",
+  component: MapChart,
+};
+
+registerChartComponent(definition.type, definition);
+
+export default definition;
@@ -0,0 +1,195 @@
+import { ChartProps } from "@powerpipe/components/dashboards/charts/types";
+import {
+  getColorOverride,
+  LeafNodeData,
+} from "@powerpipe/components/dashboards/common";
+
+// Maps are configured using "powerpipe:" tag options on the chart:
+//   map_mode            - "points" or "choropleth" (defaults to points if the data has lat/long columns)
+//   map_geojson         - URL of the GeoJSON used to draw the base map (required for choropleth maps)
+//   map_region_property - the GeoJSON feature property matched against the region column (defaults to "name")
+//
+// Points mode columns:     lat, long[, name][, value]
+// Choropleth mode columns: region, value
+type MapMode = "choropleth" | "points";
+
+const latitudeColumnNames = ["lat", "latitude"];
+const longitudeColumnNames = ["lon", "lng", "long", "longitude"];
+
+const findColumn = (data: LeafNodeData, names: string[]) =>
+  data.columns.find((col) => names.includes(col.name.toLowerCase()))?.name;
+
+const getMapMode = (props: ChartProps): MapMode => {
+  const mode = props.options?.map_mode;
+  if (mode === "points" || mode === "choropleth") {
+    return mode;
+  }
+  const data = props.data as LeafNodeData;
+  return findColumn(data, latitudeColumnNames) &&
+    findColumn(data, longitudeColumnNames)
+    ? "points"
+    : "choropleth";
+};
+
+const getMapColor = (props: ChartProps, themeColors) => {
+  const seriesName = props.data?.columns[props.data.columns.length - 1].name;
+  const seriesOverrides =
+    seriesName && props.properties?.series
+      ? props.properties.series[seriesName]
+      : null;
+  if (seriesOverrides && seriesOverrides.color) {
+    return getColorOverride(seriesOverrides.color, themeColors);
+  }
+  return themeColors.charts[0];
+};
+
+const getValueRange = (values: number[]) => {
+  const numeric = values.filter((v) => typeof v === "number");
+  if (numeric.length === 0) {
+    return { min: 0, max: 0 };
+  }
+  return { min: Math.min(...numeric), max: Math.max(...numeric) };
+};
+
+const buildVisualMap = (
+  props: ChartProps,
+  themeColors,
+  min: number,
+  max: number,
+) => ({
+  min: props.properties?.axes?.y?.min ?? min,
+  max: props.properties?.axes?.y?.max ?? max,
+  calculable: true,
+  orient: "horizontal",
+  left: "center",
+  bottom: 0,
+  itemHeight: 100,
+  textStyle: { color: themeColors.foreground, fontSize: 10 },
+  inRange: {
+    color: [themeColors.dashboardPanel, getMapColor(props, themeColors)],
+  },
+});
+
+const getGeoStyle = (themeColors) => ({
+  itemStyle: {
+    areaColor: themeColors.foregroundLightest,
+    borderColor: themeColors.dashboardPanel,
+  },
+  emphasis: {
+    label: { show: false },
+    itemStyle: { areaColor: themeColors.foregroundLighter },
+  },
+});
+
+const buildPointsOptions = (
+  props: ChartProps,
+  themeColors,
+  mapName: string | null,
+) => {
+  const data = props.data as LeafNodeData;
+  const latColumn = findColumn(data, latitudeColumnNames);
+  const lonColumn = findColumn(data, longitudeColumnNames);
+  if (!latColumn || !lonColumn) {
+    return {};
+  }
+  const otherColumns = data.columns
+    .map((col) => col.name)
+    .filter((name) => name !== latColumn && name !== lonColumn);
+  const nameColumn = findColumn(data, ["name", "label"]) || otherColumns[0];
+  const valueColumn =
+    findColumn(data, ["value"]) ||
+    otherColumns.find(
+      (name) =>
+        name !== nameColumn && typeof data.rows[0]?.[name] === "number",
+    );
+
+  const points = data.rows.map((row) => ({
+    name: nameColumn ? row[nameColumn] : undefined,
+    value: [
+      row[lonColumn],
+      row[latColumn],
+      valueColumn ? row[valueColumn] : null,
+    ],
+  }));
+  const { min, max } = getValueRange(
+    points.map((point) => point.value[2] as number),
+  );
+  const color = getMapColor(props, themeColors);
+
+  const series = {
+    type: "scatter",
+    coordinateSystem: mapName ? "geo" : "cartesian2d",
+    data: points,
+    symbolSize: (value: any[]) =>
+      valueColumn && max > min ? 6 + ((value[2] - min) / (max - min)) * 14 : 8,
+    itemStyle: { color, opacity: 0.8 },
+    encode: { tooltip: 2 },
+  };
+
+  // without a base map, plot the points on a lat/long grid
+  if (!mapName) {
+    const axisOptions = {
+      type: "value",
+      axisLabel: { color: themeColors.foreground, fontSize: 10 },
+      axisLine: { lineStyle: { color: themeColors.foregroundLightest } },
+      splitLine: { lineStyle: { color: themeColors.foregroundLightest } },
+    };
+    return {
+      grid: { top: "5%", bottom: "10%" },
+      xAxis: { ...axisOptions, min: -180, max: 180 },
+      yAxis: { ...axisOptions, min: -90, max: 90 },
+      series: [series],
+    };
+  }
+
+  return {
+    geo: {
+      map: mapName,
+      roam: true,
+      ...getGeoStyle(themeColors),
+    },
+    series: [series],
+  };
+};
+
+const buildChoroplethOptions = (
+  props: ChartProps,
+  themeColors,
+  mapName: string,
+) => {
+  const data = props.data as LeafNodeData;
+  const regionColumn = data.columns[0]?.name;
+  const valueColumn =
+    findColumn(data, ["value"]) || data.columns[data.columns.length - 1]?.name;
+  const regions = data.rows.map((row) => ({
+    name: row[regionColumn],
+    value: row[valueColumn],
+  }));
+  const { min, max } = getValueRange(regions.map((region) => region.value));
+  return {
+    visualMap: buildVisualMap(props, themeColors, min, max),
+    series: [
+      {
+        type: "map",
+        map: mapName,
+        roam: true,
+        nameProperty: props.options?.map_region_property || "name",
+        data: regions,
+        ...getGeoStyle(themeColors),
+      },
+    ],
+  };
+};
+
+const buildMapOptions = (
+  props: ChartProps,
+  themeColors,
+  mapName: string | null,
+) => {
+  if (getMapMode(props) === "points") {
+    return buildPointsOptions(props, themeColors, mapName);
+  }
+  return mapName ? buildChoroplethOptions(props, themeColors, mapName) : {};
+};
+
+export { buildMapOptions, getMapMode };
@@ -0,0 +1,19 @@
+import Chart from "@powerpipe/components/dashboards/charts/Chart";
+import {
+  ChartProps,
+  IChart,
+} from "@powerpipe/components/dashboards/charts/types";
+import { registerChartComponent } from "@powerpipe/components/dashboards/charts";
+
+const SankeyChart = (props: ChartProps) => {
+  return <Chart {...props} />;
+};
+
+const definition: IChart = {
+  type: "sankey",
+  component: SankeyChart,
+};
+
+registerChartComponent(definition.type, definition);
+
+export default definition;
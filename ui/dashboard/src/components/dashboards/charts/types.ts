@@ -87,10 +87,16 @@ export type ChartType =
   | "area"
   | "bar"
   | "column"
+  | "custom"
   | "donut"
+  | "gauge"
+  | "heatmap"
   | "line"
+  | "map"
   | "pie"
-  | "table";
+  | "sankey"
+  | "table"
+  | "treemap";
 
 export type IChart = {
   type: ChartType;
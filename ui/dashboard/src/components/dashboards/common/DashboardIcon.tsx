@@ -17,36 +17,54 @@ type DashboardImageIconProps = DashboardIconProps & {
   icon: string;
 };
 
-const useDashboardIconType = (icon) =>
-  useMemo(() => {
-    if (!icon) {
-      return null;
-    }
+// Icons prefixed with "asset:" are files in the assets directory of the mod, e.g. "asset:icons/vpc.svg",
+// which the dashboard server serves from /mod-assets
+const modAssetIconPrefix = "asset:";
 
-    // This gets parsed as a URL if we don't check first
-    if (
-      icon.startsWith("heroicons-outline:") ||
-      icon.startsWith("heroicons-solid:") ||
-      icon.startsWith("materialsymbols-outline:") ||
-      icon.startsWith("materialsymbols-solid:")
-    ) {
-      return "icon";
-    }
+const getDashboardImageIconUrl = (icon: string) =>
+  icon.startsWith(modAssetIconPrefix)
+    ? `/mod-assets/${icon
+        .substring(modAssetIconPrefix.length)
+        .replace(/^\/+/, "")}`
+    : icon;
 
-    // Same for text - this gets parsed as a URL if we don't check first
-    if (icon.startsWith("text:")) {
-      return "text";
-    }
-
-    // If it looks like a URL, treat it like a URL
-    try {
-      new URL(icon);
-      return "url";
-    } catch {}
+const getDashboardIconType = (icon: string | null | undefined) => {
+  if (!icon) {
+    return null;
+  }
 
-    // Else fall back to hero icons
+  // This gets parsed as a URL if we don't check first
+  if (
+    icon.startsWith("heroicons-outline:") ||
+    icon.startsWith("heroicons-solid:") ||
+    icon.startsWith("materialsymbols-outline:") ||
+    icon.startsWith("materialsymbols-solid:")
+  ) {
     return "icon";
-  }, [icon]);
+  }
+
+  // Same for text - this gets parsed as a URL if we don't check first
+  if (icon.startsWith("text:")) {
+    return "text";
+  }
+
+  // Icons in the mod assets directory
+  if (icon.startsWith(modAssetIconPrefix)) {
+    return "url";
+  }
+
+  // If it looks like a URL, treat it like a URL
+  try {
+    new URL(icon);
+    return "url";
+  } catch {}
+
+  // Else fall back to hero icons
+  return "icon";
+};
+
+const useDashboardIconType = (icon) =>
+  useMemo(() => getDashboardIconType(icon), [icon]);
 
 const DashboardImageIcon = ({
   className,
@@ -54,7 +72,13 @@ const DashboardImageIcon = ({
   style,
   title,
 }: DashboardImageIconProps) => (
-  <img className={className} src={icon} alt="" style={style} title={title} />
+  <img
+    className={className}
+    src={getDashboardImageIconUrl(icon)}
+    alt=""
+    style={style}
+    title={title}
+  />
 );
 
 const DashboardTextIcon = ({
@@ -122,4 +146,8 @@ const DashboardIcon = ({
 
 export default DashboardIcon;
 
-export { useDashboardIconType };
+export {
+  getDashboardIconType,
+  getDashboardImageIconUrl,
+  useDashboardIconType,
+};
@@ -12,10 +12,14 @@ import {
   NodesAndEdges,
 } from "./types";
 import { ChartProperties, ChartTransform, ChartType } from "../charts/types";
-import { DashboardRunState } from "@powerpipe/types";
+import { DashboardRunState, PanelOptions } from "@powerpipe/types";
 import { ExpandedNodes } from "../graphs/common/useGraph";
 import { FlowProperties, FlowType } from "../flows/types";
 import { getColumn } from "@powerpipe/utils/data";
+import {
+  getDashboardIconType,
+  getDashboardImageIconUrl,
+} from "./DashboardIcon";
 import { Graph, json } from "graphlib";
 import { GraphProperties, GraphType, NodeAndEdgeData } from "../graphs/types";
 import { HierarchyProperties, HierarchyType } from "../hierarchies/types";
@@ -43,9 +47,19 @@ export type LeafNodeDataRow = {
   [key: string]: any;
 };
 
+// Set by the server when the rows are a single page of the query results
+export type LeafNodeDataPagination = {
+  page: number;
+  page_size: number;
+  total_rows: number;
+  sort_column?: string;
+  sort_direction?: "asc" | "desc";
+};
+
 export type LeafNodeData = {
   columns: LeafNodeDataColumn[];
   rows: LeafNodeDataRow[];
+  pagination?: LeafNodeDataPagination;
 };
 
 export type ExecutablePrimitiveProps = {
@@ -259,6 +273,7 @@ const recordEdge = (
   title: string | null = null,
   category: string | null = null,
   row_data: LeafNodeDataRow | null = null,
+  weight?: number,
 ) => {
   let duplicate_edge = false;
   // Find any existing edge
@@ -272,6 +287,7 @@ const recordEdge = (
     title,
     category,
     row_data,
+    weight,
     isFolded: false,
   };
 
@@ -659,6 +675,8 @@ const buildNodesAndEdges = (
     const category: string | null = row.category || null;
     const depth: number | null =
       typeof row.depth === "number" ? row.depth : null;
+    const weight: number | undefined =
+      typeof row.weight === "number" ? row.weight : undefined;
 
     if (category && !categories[category]) {
       const overrides = categoryProperties[category];
@@ -866,6 +884,7 @@ const buildNodesAndEdges = (
         title,
         category,
         nodeAndEdgeMask === 6 ? row : null,
+        weight,
       );
       if (duplicate_edge) {
         contains_duplicate_edges = true;
@@ -892,9 +911,44 @@ const buildNodesAndEdges = (
   };
 };
 
+// Edges of a category can be drawn with a "solid", "dashed" or "dotted" line using
+// the "powerpipe:category.<name>.line_style" option
+const edgeLineStyles = ["solid", "dashed", "dotted"];
+
+const getCategoryLineStyle = (
+  options: PanelOptions | undefined,
+  category: string | null,
+): string | null => {
+  if (!options || !category) {
+    return null;
+  }
+  const lineStyle = options[`category.${category}.line_style`];
+  return lineStyle && edgeLineStyles.includes(lineStyle) ? lineStyle : null;
+};
+
+// Weighted edges are drawn with a stroke width scaled between these widths
+const minEdgeStrokeWidth = 1;
+const maxEdgeStrokeWidth = 5;
+
+const getEdgeStrokeWidth = (
+  weight: number | undefined,
+  minWeight: number,
+  maxWeight: number,
+) => {
+  if (weight === undefined || maxWeight === minWeight) {
+    return minEdgeStrokeWidth;
+  }
+  return (
+    minEdgeStrokeWidth +
+    ((weight - minWeight) / (maxWeight - minWeight)) *
+      (maxEdgeStrokeWidth - minEdgeStrokeWidth)
+  );
+};
+
 const buildSankeyDataInputs = (
   nodesAndEdges: NodesAndEdges,
   namedThemeColors,
+  options?: PanelOptions,
 ) => {
   const data: any[] = [];
   const links: any[] = [];
@@ -917,12 +971,14 @@ const buildSankeyDataInputs = (
     links.push({
       source: edge.from_id,
       target: edge.to_id,
-      value: 0.01,
+      // edges without a weight are given a nominal value so they are still drawn
+      value: edge.weight && edge.weight > 0 ? edge.weight : 0.01,
       lineStyle: {
         color:
           categoryOverrides && categoryOverrides.color
             ? categoryOverrides.color
             : "target",
+        type: getCategoryLineStyle(options, edge.category) || "solid",
       },
     });
   });
@@ -974,6 +1030,7 @@ type TreeItem = {
 const nodesAndEdgesToTree = (
   nodesAndEdges: NodesAndEdges,
   namedThemeColors,
+  options?: PanelOptions,
 ): TreeItem[] => {
   // const rootParentIds = { "": true };
 
@@ -998,6 +1055,7 @@ const nodesAndEdgesToTree = (
     }
 
     let color;
+    let symbol;
     if (node.category && nodesAndEdges.categories[node.category]) {
       const categoryOverrides = nodesAndEdges.categories[node.category];
       if (categoryOverrides.color) {
@@ -1006,6 +1064,13 @@ const nodesAndEdgesToTree = (
       } else {
         color = namedThemeColors.charts[colorIndex++];
       }
+      // Image icons (URLs and mod assets) are drawn as the node symbol
+      if (
+        categoryOverrides.icon &&
+        getDashboardIconType(categoryOverrides.icon) === "url"
+      ) {
+        symbol = `image://${getDashboardImageIconUrl(categoryOverrides.icon)}`;
+      }
     }
 
     lookup[node.id] = {
@@ -1014,9 +1079,17 @@ const nodesAndEdgesToTree = (
       itemStyle: {
         color,
       },
+      ...(symbol ? { symbol, symbolSize: 16 } : {}),
       children: lookup[node.id].children,
     };
   }
+
+  const weights = nodesAndEdges.edges
+    .map((edge) => edge.weight)
+    .filter((weight): weight is number => weight !== undefined);
+  const minWeight = weights.length > 0 ? Math.min(...weights) : 0;
+  const maxWeight = weights.length > 0 ? Math.max(...weights) : 0;
+
   // Fill in the children with the edge relationships
   for (const edge of nodesAndEdges.edges) {
     const childId = edge.to_id;
@@ -1030,6 +1103,15 @@ const nodesAndEdgesToTree = (
 
     const childItem = lookup[childId];
 
+    // The line to a child is drawn for the edge to it
+    if (childItem) {
+      childItem.weight = edge.weight;
+      childItem.lineStyle = {
+        width: getEdgeStrokeWidth(edge.weight, minWeight, maxWeight),
+        type: getCategoryLineStyle(options, edge.category) || "solid",
+      };
+    }
+
     // add the current item to the parent
     lookup[parentId].children.push(childItem);
   }
@@ -1041,8 +1123,9 @@ const nodesAndEdgesToTree = (
 const buildTreeDataInputs = (
   nodesAndEdges: NodesAndEdges,
   namedThemeColors,
+  options?: PanelOptions,
 ) => {
-  const tree = nodesAndEdgesToTree(nodesAndEdges, namedThemeColors);
+  const tree = nodesAndEdgesToTree(nodesAndEdges, namedThemeColors, options);
   return {
     data: tree,
   };
@@ -1138,6 +1221,8 @@ export {
   buildSankeyDataInputs,
   buildTreeDataInputs,
   foldNodesAndEdges,
+  getCategoryLineStyle,
+  getEdgeStrokeWidth,
   getChartColors,
   getColorOverride,
   isNumericCol,
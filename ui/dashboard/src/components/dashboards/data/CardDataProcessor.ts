@@ -37,7 +37,14 @@ export interface CardState {
 
 export type CardDataFormat = "simple" | "formal";
 
-export type CardType = "alert" | "info" | "ok" | "severity" | "table" | null;
+export type CardType =
+  | "alert"
+  | "image"
+  | "info"
+  | "ok"
+  | "severity"
+  | "table"
+  | null;
 
 export class CardDataProcessor {
   constructor() {}
@@ -17,6 +17,7 @@ import {
 import { getFlowComponent } from "..";
 import { NodesAndEdges } from "@powerpipe/components/dashboards/common/types";
 import { registerComponent } from "@powerpipe/components/dashboards";
+import { PanelOptions } from "@powerpipe/types";
 import { useDashboard } from "@powerpipe/hooks/useDashboard";
 
 const getCommonBaseOptions = () => ({
@@ -42,6 +43,7 @@ const getSeriesForFlowType = (
   properties: FlowProperties | undefined,
   nodesAndEdges: NodesAndEdges,
   themeColors,
+  options?: PanelOptions,
 ) => {
   if (!data) {
     return {};
@@ -54,6 +56,7 @@ const getSeriesForFlowType = (
         const { data: sankeyData, links } = buildSankeyDataInputs(
           nodesAndEdges,
           themeColors,
+          options,
         );
         series.push({
           type: toEChartsType(type),
@@ -110,6 +113,7 @@ const buildFlowOptions = (props: FlowProps, themeColors) => {
       props.properties,
       nodesAndEdges,
       themeColors,
+      props.options,
     ),
     getOptionOverridesForFlowType(props.display_type, props.properties),
   );
@@ -10,6 +10,17 @@ import { colorToRgb } from "@powerpipe/utils/color";
 import { EdgeLabelRenderer, useStore } from "reactflow";
 import { useCallback } from "react";
 
+const getStrokeDasharray = (lineStyle: string | null) => {
+  switch (lineStyle) {
+    case "dashed":
+      return "6 4";
+    case "dotted":
+      return "1 3";
+    default:
+      return undefined;
+  }
+};
+
 const FloatingEdge = ({
   id,
   source,
@@ -22,6 +33,9 @@ const FloatingEdge = ({
     properties,
     labelOpacity,
     lineOpacity,
+    lineStyle,
+    strokeWidth,
+    weight,
     row_data,
     label,
     themeColors,
@@ -53,12 +67,18 @@ const FloatingEdge = ({
     colorRgb = colorToRgb(themeColors.foreground, themeColors);
   }
 
+  // the weight of the edge is shown in the tooltip along with any row properties
+  const tooltipProperties =
+    weight !== undefined
+      ? { ...(row_data?.properties || {}), weight }
+      : row_data?.properties;
+
   const edgeLabel = (
     <span
       title={label}
       className={classNames(
         "block italic max-w-[70px] text-sm text-center text-wrap leading-tight line-clamp-2",
-        row_data && row_data.properties ? "border-b border-dashed" : null,
+        tooltipProperties ? "border-b border-dashed" : null,
       )}
       style={{
         borderColor: `rgba(${colorRgb[0]},${colorRgb[1]},${colorRgb[2]},${labelOpacity})`,
@@ -73,12 +93,12 @@ const FloatingEdge = ({
 
   const edgeLabelWrapper = (
     <>
-      {row_data && row_data.properties && (
+      {tooltipProperties && (
         <Tooltip
           overlay={
             <RowProperties
               propertySettings={properties || null}
-              properties={row_data.properties}
+              properties={tooltipProperties}
             />
           }
           title={<RowPropertiesTitle category={category} title={label} />}
@@ -86,7 +106,7 @@ const FloatingEdge = ({
           {edgeLabel}
         </Tooltip>
       )}
-      {(!row_data || !row_data.properties) && edgeLabel}
+      {!tooltipProperties && edgeLabel}
     </>
   );
 
@@ -100,7 +120,8 @@ const FloatingEdge = ({
           ...(style || {}),
           opacity: lineOpacity,
           stroke: color,
-          strokeWidth: 1,
+          strokeWidth: strokeWidth || 1,
+          strokeDasharray: getStrokeDasharray(lineStyle),
         }}
       />
       <EdgeLabelRenderer>
@@ -21,6 +21,8 @@ import useNodeAndEdgeData from "@powerpipe/components/dashboards/common/useNodeA
 import {
   buildNodesAndEdges,
   foldNodesAndEdges,
+  getCategoryLineStyle,
+  getEdgeStrokeWidth,
   LeafNodeData,
 } from "@powerpipe/components/dashboards/common";
 import {
@@ -41,7 +43,7 @@ import {
   NodeStatus,
   WithStatus,
 } from "@powerpipe/components/dashboards/graphs/types";
-import { DashboardRunState } from "@powerpipe/types";
+import { DashboardRunState, PanelOptions } from "@powerpipe/types";
 import {
   ExpandedNodes,
   GraphProvider,
@@ -112,6 +114,7 @@ const buildGraphNodesAndEdges = (
   themeColors: any,
   expandedNodes: ExpandedNodes,
   status: DashboardRunState,
+  options?: PanelOptions,
 ) => {
   if (!data) {
     return {
@@ -188,6 +191,13 @@ const buildGraphNodesAndEdges = (
       },
     });
   }
+  // Weighted edges are drawn with a stroke width scaled to their weight,
+  // relative to the other edges in the graph
+  const weights = nodesAndEdges.edges
+    .map((edge) => edge.weight)
+    .filter((weight): weight is number => weight !== undefined);
+  const minWeight = weights.length > 0 ? Math.min(...weights) : 0;
+  const maxWeight = weights.length > 0 ? Math.max(...weights) : 0;
   for (const edge of nodesAndEdges.edges) {
     // The color rules are:
     // 1) If the target node of the edge specifies a category and that
@@ -222,6 +232,7 @@ const buildGraphNodesAndEdges = (
         : themeColors.blackScale4;
     const labelOpacity = categoryColor ? 1 : targetNodeColor ? 0.7 : 1;
     const lineOpacity = categoryColor ? 1 : targetNodeColor ? 0.7 : 1;
+    const strokeWidth = getEdgeStrokeWidth(edge.weight, minWeight, maxWeight);
     edges.push({
       type: "floating",
       id: edge.id,
@@ -245,6 +256,9 @@ const buildGraphNodesAndEdges = (
         properties: matchingCategory ? matchingCategory.properties : null,
         labelOpacity,
         lineOpacity,
+        lineStyle: getCategoryLineStyle(options, edge.category),
+        strokeWidth,
+        weight: edge.weight,
         row_data: edge.row_data,
         label: getNodeOrEdgeLabel(edge, matchingCategory),
         themeColors,
@@ -283,6 +297,7 @@ const useGraphOptions = (props: GraphProps) => {
     props.data,
     props.properties,
     props.status,
+    props.options,
   );
   const { setGraphEdges, setGraphNodes } = useGraph();
   const [nodes, setNodes, onNodesChange] = useNodesState(nodesAndEdges.nodes);
@@ -317,6 +332,7 @@ const useGraphNodesAndEdges = (
   data: LeafNodeData | undefined,
   properties: GraphProperties | undefined,
   status: DashboardRunState,
+  options?: PanelOptions,
 ) => {
   const { expandedNodes } = useGraph();
   const themeColors = useChartThemeColors();
@@ -329,8 +345,9 @@ const useGraphNodesAndEdges = (
         themeColors,
         expandedNodes,
         status,
+        options,
       ),
-    [categories, data, expandedNodes, properties, status, themeColors],
+    [categories, data, expandedNodes, options, properties, status, themeColors],
   );
 
   return {
@@ -15,6 +15,8 @@ import {
   HierarchyType,
 } from "@powerpipe/components/dashboards/hierarchies/types";
 import { NodesAndEdges } from "@powerpipe/components/dashboards/common/types";
+import { PanelOptions } from "@powerpipe/types";
+import { echarts } from "@powerpipe/components/dashboards/charts/Chart/echarts";
 import { registerComponent } from "@powerpipe/components/dashboards";
 import { useDashboard } from "@powerpipe/hooks/useDashboard";
 
@@ -33,12 +35,25 @@ const getCommonBaseOptionsForHierarchyType = (type: HierarchyType = "tree") => {
   }
 };
 
+// The tooltip of a tree node shows its title, category and, if the edge to it is weighted, that weight
+const formatTreeTooltip = (params) => {
+  const lines = [echarts.format.encodeHTML(params.name || "")];
+  if (params.data?.category) {
+    lines.push(`category: ${echarts.format.encodeHTML(params.data.category)}`);
+  }
+  if (params.data?.weight !== undefined) {
+    lines.push(`weight: ${params.data.weight}`);
+  }
+  return lines.join("<br />");
+};
+
 const getSeriesForHierarchyType = (
   type: HierarchyType = "tree",
   data: LeafNodeData | undefined,
   properties: HierarchyProperties | undefined,
   nodesAndEdges: NodesAndEdges,
   themeColors,
+  options?: PanelOptions,
 ) => {
   if (!data) {
     return {};
@@ -51,6 +66,7 @@ const getSeriesForHierarchyType = (
         const { data: treeData } = buildTreeDataInputs(
           nodesAndEdges,
           themeColors,
+          options,
         );
         series.push({
           type: "tree",
@@ -76,6 +92,9 @@ const getSeriesForHierarchyType = (
           emphasis: {
             focus: "descendant",
           },
+          tooltip: {
+            formatter: formatTreeTooltip,
+          },
           expandAndCollapse: false,
           animationDuration: 550,
           animationDurationUpdate: 750,
@@ -115,6 +134,7 @@ const buildHierarchyOptions = (props: HierarchyProps, themeColors) => {
       props.properties,
       nodesAndEdges,
       themeColors,
+      props.options,
     ),
     getOptionOverridesForHierarchyType(props.display_type, props.properties),
   );
@@ -0,0 +1,141 @@
+import { DashboardActions, DashboardDataModeLive } from "@powerpipe/types";
+import {
+  IInput,
+  InputProps,
+} from "@powerpipe/components/dashboards/inputs/types";
+import { registerInputComponent } from "@powerpipe/components/dashboards/inputs";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
+import { useEffect, useState } from "react";
+
+// The value of a date_range input is either a preset relative to the current time, e.g. "last_7d",
+// or a custom range of two dates separated by "/", e.g. "2024-01-01/2024-01-31".
+// The server resolves the value into start and end timestamps.
+const presets = [
+  { value: "last_24h", label: "Last 24 hours" },
+  { value: "last_7d", label: "Last 7 days" },
+  { value: "last_30d", label: "Last 30 days" },
+];
+
+const customValue = "custom";
+
+const inputClassName =
+  "block w-full bg-dashboard-panel rounded-md border border-black-scale-3 text-sm md:text-base disabled:bg-black-scale-1 focus:ring-0";
+
+const parseValue = (value: any) => {
+  if (!value) {
+    return { preset: "", start: "", end: "" };
+  }
+  if (presets.find((p) => p.value === value)) {
+    return { preset: value, start: "", end: "" };
+  }
+  const [start = "", end = ""] = String(value).split("/");
+  return { preset: customValue, start, end };
+};
+
+const DateRangeInput = (props: InputProps) => {
+  const { dataMode, dispatch, selectedDashboardInputs } = useDashboard();
+  const stateValue = selectedDashboardInputs[props.name];
+  const [range, setRange] = useState(() => parseValue(stateValue));
+
+  useEffect(() => {
+    setRange(parseValue(stateValue));
+  }, [stateValue]);
+
+  const readOnly = dataMode !== DashboardDataModeLive;
+
+  const setValue = (value: string | null) => {
+    if (value) {
+      dispatch({
+        type: DashboardActions.SET_DASHBOARD_INPUT,
+        name: props.name,
+        value,
+        recordInputsHistory: !!stateValue,
+      });
+    } else {
+      dispatch({
+        type: DashboardActions.DELETE_DASHBOARD_INPUT,
+        name: props.name,
+        recordInputsHistory: !!stateValue,
+      });
+    }
+  };
+
+  const onPresetChange = (e) => {
+    const preset = e.target.value;
+    setRange({ ...range, preset });
+    if (preset !== customValue) {
+      setValue(preset);
+    } else if (range.start && range.end) {
+      setValue(`${range.start}/${range.end}`);
+    }
+  };
+
+  const onCustomChange = (key: "start" | "end", value: string) => {
+    const updated = { ...range, [key]: value };
+    setRange(updated);
+    // only submit once both ends of the range are set and in order
+    if (updated.start && updated.end && updated.start <= updated.end) {
+      setValue(`${updated.start}/${updated.end}`);
+    }
+  };
+
+  return (
+    <div>
+      {props.properties.label && (
+        <label htmlFor={props.name} className="block mb-1">
+          {props.properties.label}
+        </label>
+      )}
+      <select
+        id={props.name}
+        name={props.name}
+        className={inputClassName}
+        disabled={readOnly}
+        onChange={onPresetChange}
+        value={range.preset}
+      >
+        <option value="" disabled>
+          {props.properties.placeholder || "Select a date range..."}
+        </option>
+        {presets.map((preset) => (
+          <option key={preset.value} value={preset.value}>
+            {preset.label}
+          </option>
+        ))}
+        <option value={customValue}>Custom</option>
+      </select>
+      {range.preset === customValue && (
+        <div className="flex items-center space-x-2 mt-2">
+          <input
+            type="date"
+            aria-label="Start date"
+            className={inputClassName}
+            readOnly={readOnly}
+            max={range.end || undefined}
+            onChange={(e) => onCustomChange("start", e.target.value)}
+            value={range.start}
+          />
+          <span className="text-foreground-light">to</span>
+          <input
+            type="date"
+            aria-label="End date"
+            className={inputClassName}
+            readOnly={readOnly}
+            min={range.start || undefined}
+            onChange={(e) => onCustomChange("end", e.target.value)}
+            value={range.end}
+          />
+        </div>
+      )}
+    </div>
+  );
+};
+
+const definition: IInput = {
+  type: "date_range",
+  component: DateRangeInput,
+};
+
+registerInputComponent(definition.type, definition);
+
+export default definition;
@@ -0,0 +1,19 @@
+import { FreeTextInput } from "@powerpipe/components/dashboards/inputs/TextInput";
+import {
+  IInput,
+  InputProps,
+} from "@powerpipe/components/dashboards/inputs/types";
+import { registerInputComponent } from "@powerpipe/components/dashboards/inputs";
+
+const NumberInput = (props: InputProps) => (
+  <FreeTextInput {...props} inputType="number" />
+);
+
+const definition: IInput = {
+  type: "number",
+  component: NumberInput,
+};
+
+registerInputComponent(definition.type, definition);
+
+export default definition;
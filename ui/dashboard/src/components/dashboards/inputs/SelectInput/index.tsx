@@ -137,6 +137,10 @@ const SelectInput = ({
     }
   };
 
+  // The server re-resolves the options when an input this depends on changes - while it does,
+  // the input is blocked (waiting for a value for the input it depends on) or running
+  const isResolving = status === "blocked" || status === "running";
+
   const styles = useSelectInputStyles();
 
   if (!styles) {
@@ -170,9 +174,11 @@ const SelectInput = ({
         menuPortalTarget={document.getElementById("portals")}
         inputId={`${name}.input`}
         isDisabled={
-          (!properties.options && !data) || dataMode !== DashboardDataModeLive
+          (!properties.options && !data) ||
+          dataMode !== DashboardDataModeLive ||
+          isResolving
         }
-        isLoading={!properties.options && !data}
+        isLoading={(!properties.options && !data) || status === "running"}
         isClearable={!!properties.placeholder}
         isRtl={false}
         isSearchable
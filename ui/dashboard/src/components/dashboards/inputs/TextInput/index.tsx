@@ -1,5 +1,9 @@
 import { ClearIcon, SubmitIcon } from "@powerpipe/constants/icons";
-import { DashboardActions, DashboardDataModeLive } from "@powerpipe/types";
+import {
+  DashboardActions,
+  DashboardDataModeLive,
+  PanelOptions,
+} from "@powerpipe/types";
 import { registerInputComponent } from "@powerpipe/components/dashboards/inputs";
 import {
   IInput,
@@ -8,13 +12,55 @@ import {
 import { useDashboard } from "@powerpipe/hooks/useDashboard";
 import { useEffect, useState } from "react";
 
-const TextInput = (props: InputProps) => {
+type FreeTextInputProps = InputProps & {
+  inputType: "number" | "text";
+};
+
+// Validate the value against the validation options of the input:
+//   pattern  - a regular expression which text values must match
+//   min, max - the range of number values
+// The server performs the same validation, this just gives earlier feedback.
+const validateValue = (
+  value: string,
+  inputType: "number" | "text",
+  options: PanelOptions | undefined,
+): string | null => {
+  if (!value) {
+    return null;
+  }
+  if (inputType === "number") {
+    const number = Number(value);
+    if (value.trim() === "" || isNaN(number)) {
+      return "Value must be a number";
+    }
+    if (options?.min !== undefined && number < Number(options.min)) {
+      return `Value must be at least ${options.min}`;
+    }
+    if (options?.max !== undefined && number > Number(options.max)) {
+      return `Value must be at most ${options.max}`;
+    }
+    return null;
+  }
+  if (options?.pattern) {
+    try {
+      if (!new RegExp(options.pattern).test(value)) {
+        return `Value must match the pattern ${options.pattern}`;
+      }
+    } catch {
+      // invalid patterns are reported by the server
+    }
+  }
+  return null;
+};
+
+const FreeTextInput = ({ inputType, ...props }: FreeTextInputProps) => {
   const { dataMode, dispatch, selectedDashboardInputs } = useDashboard();
   const stateValue = selectedDashboardInputs[props.name];
   const [value, setValue] = useState<string>(() => {
     return stateValue || "";
   });
   const [isDirty, setIsDirty] = useState<boolean>(false);
+  const validationError = validateValue(value, inputType, props.options);
 
   const updateValue = (e) => {
     setValue(e.target.value);
@@ -22,6 +68,9 @@ const TextInput = (props: InputProps) => {
   };
 
   const submit = () => {
+    if (validationError) {
+      return;
+    }
     setIsDirty(false);
     if (value) {
       dispatch({
@@ -65,9 +114,12 @@ const TextInput = (props: InputProps) => {
       )}
       <div className="relative">
         <input
-          type="text"
+          type={inputType}
           name={props.name}
           id={props.name}
+          min={inputType === "number" ? props.options?.min : undefined}
+          max={inputType === "number" ? props.options?.max : undefined}
+          aria-invalid={!!validationError}
           className="flex-1 block w-full bg-dashboard-panel rounded-md border border-black-scale-3 pr-8 overflow-x-auto text-sm md:text-base disabled:bg-black-scale-1 focus:ring-0"
           onChange={updateValue}
           onKeyPress={(e) => {
@@ -80,7 +132,7 @@ const TextInput = (props: InputProps) => {
           readOnly={readOnly}
           value={value}
         />
-        {value && isDirty && !readOnly && (
+        {value && isDirty && !readOnly && !validationError && (
           <div
             className="absolute inset-y-0 right-0 pr-3 flex items-center cursor-pointer text-foreground-light"
             onClick={submit}
@@ -99,10 +151,17 @@ const TextInput = (props: InputProps) => {
           </div>
         )}
       </div>
+      {validationError && isDirty && (
+        <p className="mt-1 text-sm text-alert">{validationError}</p>
+      )}
     </div>
   );
 };
 
+const TextInput = (props: InputProps) => (
+  <FreeTextInput {...props} inputType="text" />
+);
+
 const definition: IInput = {
   type: "text",
   component: TextInput,
@@ -111,3 +170,5 @@ const definition: IInput = {
 registerInputComponent(definition.type, definition);
 
 export default definition;
+
+export { FreeTextInput };
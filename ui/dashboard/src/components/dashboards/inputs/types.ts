@@ -34,9 +34,11 @@ export type InputProps = BaseInputProps & {
 
 export type InputType =
   | "combo"
+  | "date_range"
   | "hidden"
   | "multicombo"
   | "multiselect"
+  | "number"
   | "select"
   | "table"
   | "text";
@@ -1,4 +1,5 @@
 import Child from "../Child";
+import { isBannerPanel } from "@powerpipe/components/dashboards/Banner";
 import {
   ContainerDefinition,
   DashboardPanelType,
@@ -22,7 +23,12 @@ const Children = ({
     <>
       {children.map((child) => {
         const definition = panelsMap[child.name];
-        if (!definition) {
+        // hidden panels have a display condition which is not met
+        if (!definition || definition.hidden) {
+          return null;
+        }
+        // banners are displayed at the top of the dashboard
+        if (isBannerPanel(definition)) {
           return null;
         }
         return (
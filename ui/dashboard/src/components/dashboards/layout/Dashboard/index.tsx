@@ -1,18 +1,22 @@
 import Children from "../Children";
+import DashboardBanners from "@powerpipe/components/dashboards/Banner";
 import DashboardControls from "./DashboardControls";
 import DashboardProgress from "./DashboardProgress";
 import DashboardTitle from "@powerpipe/components/dashboards/titles/DashboardTitle";
 import Grid from "../Grid";
 import PanelDetail from "../PanelDetail";
 import SnapshotRenderComplete from "@powerpipe/components/snapshot/SnapshotRenderComplete";
+import { ThemeNames } from "@powerpipe/hooks/useTheme";
 import { DashboardControlsProvider } from "./DashboardControlsProvider";
 import {
   DashboardDataModeCLISnapshot,
   DashboardDataModeLive,
   DashboardDefinition,
 } from "@powerpipe/types";
+import useThemeColorOverrides from "@powerpipe/hooks/useThemeColorOverrides";
 import { registerComponent } from "@powerpipe/components/dashboards";
 import { useDashboard } from "@powerpipe/hooks/useDashboard";
+import { useEffect } from "react";
 
 type DashboardProps = {
   definition: DashboardDefinition;
@@ -40,6 +44,7 @@ const Dashboard = ({
       {isRoot && !definition.artificial && (
         <DashboardTitle title={definition.title} />
       )}
+      {isRoot && <DashboardBanners definition={definition} />}
       <Children
         children={definition.children}
         parentType="dashboard"
@@ -69,6 +74,33 @@ const Dashboard = ({
   );
 };
 
+const DashboardTheme = ({ name }: { name: string }) => {
+  const { panelsMap, themeContext } = useDashboard();
+  const theme = panelsMap[name]?.theme;
+  const { setDashboardTheme } = themeContext;
+
+  useEffect(() => {
+    if (!setDashboardTheme) {
+      return;
+    }
+    switch (theme?.mode) {
+      case "dark":
+        setDashboardTheme(ThemeNames.STEAMPIPE_DARK);
+        break;
+      case "light":
+        setDashboardTheme(ThemeNames.STEAMPIPE_DEFAULT);
+        break;
+      default:
+        setDashboardTheme(null);
+    }
+    return () => setDashboardTheme(null);
+  }, [setDashboardTheme, theme?.mode]);
+
+  useThemeColorOverrides(theme?.accents, themeContext);
+
+  return null;
+};
+
 const DashboardWrapper = ({
   showPanelControls = true,
 }: DashboardWrapperProps) => {
@@ -89,6 +121,7 @@ const DashboardWrapper = ({
 
   return (
     <>
+      <DashboardTheme name={dashboard.name} />
       <Dashboard
         definition={dashboard}
         showPanelControls={showPanelControls}
@@ -6,13 +6,22 @@ const PanelDetailDataDownloadButton = ({ panelDefinition, size }) => {
   const { download, processing } = useDownloadPanelData(panelDefinition);
 
   return (
-    <NeutralButton
-      disabled={processing}
-      onClick={processing ? noop : () => download()}
-      size={size}
-    >
-      <>Download</>
-    </NeutralButton>
+    <>
+      <NeutralButton
+        disabled={processing}
+        onClick={processing ? noop : () => download("csv")}
+        size={size}
+      >
+        <>Download CSV</>
+      </NeutralButton>
+      <NeutralButton
+        disabled={processing}
+        onClick={processing ? noop : () => download("json")}
+        size={size}
+      >
+        <>Download JSON</>
+      </NeutralButton>
+    </>
   );
 };
 
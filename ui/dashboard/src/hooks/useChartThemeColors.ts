@@ -5,8 +5,14 @@ import { useCallback, useEffect, useState } from "react";
 
 const useChartThemeColors = () => {
   const {
+    dashboard,
+    panelsMap,
     themeContext: { theme, wrapperRef },
   } = useDashboard();
+  // use the chart palette declared by the dashboard, if there is one
+  const palette: string[] | undefined = dashboard
+    ? panelsMap[dashboard.name]?.theme?.palette
+    : undefined;
 
   const getThemeColors = useCallback(() => {
     // We need to get the theme CSS variable values - these are accessible on the theme root element and below in the tree
@@ -38,7 +44,7 @@ const useChartThemeColors = () => {
       const alert = `rgb(${style.getPropertyValue("--color-alert").trim()})`;
       const info = style.getPropertyValue("--color-info").trim();
       const ok = `rgb(${style.getPropertyValue("--color-ok").trim()})`;
-      const charts = getChartColors(theme);
+      const charts = palette?.length ? palette : getChartColors(theme);
       return {
         dashboard,
         dashboardPanel,
@@ -56,7 +62,7 @@ const useChartThemeColors = () => {
     } else {
       return {};
     }
-  }, [theme, wrapperRef]);
+  }, [palette, theme, wrapperRef]);
 
   const [themeColors, setThemeColors] =
     useState<KeyValuePairs>(getThemeColors());
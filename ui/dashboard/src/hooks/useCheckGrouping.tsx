@@ -785,6 +785,17 @@ const includeResult = (
   return matches.every((m) => m);
 };
 
+// The key identifying a control result, regardless of the benchmark it was run in
+const checkResultKey = (checkResult: CheckResult) =>
+  JSON.stringify([
+    checkResult.control.name,
+    checkResult.type,
+    checkResult.resource,
+    checkResult.status,
+    checkResult.reason,
+    checkResult.dimensions,
+  ]);
+
 const useGrouping = (
   definition: PanelDefinition | null,
   panelsMap: PanelsMap | undefined,
@@ -838,6 +849,13 @@ const useGrouping = (
     const temp = { _: result };
     const benchmarkChildrenLookup = {};
 
+    // A control which is a child of more than one benchmark is run (and its results reported) once per benchmark.
+    // If we're not grouping by benchmark, only count each result once so the roll-up totals are correct.
+    const groupedByBenchmark = groupingsConfig.some(
+      (g) => g.type === "benchmark",
+    );
+    const seenResults = new Set<string>();
+
     // We'll loop over each control result and build up the grouped nodes from there
     b.all_control_results.forEach((checkResult) => {
       // Record values pre-filter so we can expand out from filtered states with all values later on
@@ -848,6 +866,14 @@ const useGrouping = (
         return;
       }
 
+      if (!groupedByBenchmark) {
+        const key = checkResultKey(checkResult);
+        if (seenResults.has(key)) {
+          return;
+        }
+        seenResults.add(key);
+      }
+
       // Build a grouping node - this will be the leaf node down from the root group
       // e.g. benchmark -> control (where control is the leaf)
       const grouping = groupCheckItems(
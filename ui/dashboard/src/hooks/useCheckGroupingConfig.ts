@@ -2,6 +2,8 @@ import {
   CheckDisplayGroup,
   CheckDisplayGroupType,
 } from "@powerpipe/components/dashboards/check/common";
+import { DashboardDefinition, PanelsMap } from "@powerpipe/types";
+import { useDashboard } from "@powerpipe/hooks/useDashboard";
 import { useMemo } from "react";
 import { useSearchParams } from "react-router-dom";
 
@@ -17,52 +19,94 @@ const groupingKeys = [
   "status",
 ];
 
+const defaultGrouping: CheckDisplayGroup[] = [
+  // { type: "status" },
+  // { type: "reason" },
+  // { type: "resource" },
+  // { type: "severity" },
+  // { type: "dimension", value: "account_id" },
+  // { type: "dimension", value: "region" },
+  // { type: "control_tag", value: "service" },
+  // { type: "control_tag", value: "cis_type" },
+  // { type: "control_tag", value: "cis_level" },
+  { type: "benchmark" },
+  { type: "control" },
+  { type: "result" },
+];
+
+// Parse a grouping of the form "control_tag|service,severity,control,result"
+const parseGrouping = (rawGrouping: string): CheckDisplayGroup[] => {
+  const groupings: CheckDisplayGroup[] = [];
+  const groupingParts = rawGrouping.split(",").filter((g) => !!g);
+  for (const groupingPart of groupingParts) {
+    const typeValueParts = groupingPart.split("|").map((p) => p.trim());
+    const groupingKey = typeValueParts[0];
+
+    // Is this a valid grouping key?
+    const isValid = groupingKeys.includes(groupingKey);
+    if (!isValid) {
+      throw new Error(`Unsupported grouping key ${groupingKey}`);
+    }
+
+    if (typeValueParts.length > 1) {
+      groupings.push({
+        type: typeValueParts[0] as CheckDisplayGroupType,
+        value: typeValueParts[1],
+      });
+    } else {
+      groupings.push({
+        type: typeValueParts[0] as CheckDisplayGroupType,
+      });
+    }
+  }
+  return groupings;
+};
+
+// Find the grouping declared using the "powerpipe:grouping" option on the dashboard,
+// or on the first benchmark in the dashboard which declares one.
+// As options are part of the panel definitions, the declared grouping is also used when viewing a snapshot.
+const getDeclaredGrouping = (
+  dashboard: DashboardDefinition | null,
+  panelsMap: PanelsMap,
+): string | undefined => {
+  if (!dashboard) {
+    return;
+  }
+  const dashboardGrouping = panelsMap[dashboard.name]?.options?.grouping;
+  if (dashboardGrouping) {
+    return dashboardGrouping;
+  }
+  const findBenchmarkGrouping = (children: any[] = []) => {
+    for (const child of children) {
+      const grouping =
+        child.panel_type === "benchmark"
+          ? panelsMap[child.name]?.options?.grouping
+          : undefined;
+      if (grouping) {
+        return grouping;
+      }
+      const nestedGrouping = findBenchmarkGrouping(child.children);
+      if (nestedGrouping) {
+        return nestedGrouping;
+      }
+    }
+  };
+  return findBenchmarkGrouping(dashboard.children);
+};
+
 const useCheckGroupingConfig = () => {
   const [searchParams] = useSearchParams();
+  const { dashboard, panelsMap } = useDashboard();
+  const declaredGrouping = getDeclaredGrouping(dashboard, panelsMap);
   return useMemo(() => {
-    const rawGrouping = searchParams.get("grouping");
+    // a grouping selected by the user takes precedence over the declared grouping
+    const rawGrouping = searchParams.get("grouping") || declaredGrouping;
     if (rawGrouping) {
-      const groupings: CheckDisplayGroup[] = [];
-      const groupingParts = rawGrouping.split(",").filter((g) => !!g);
-      for (const groupingPart of groupingParts) {
-        const typeValueParts = groupingPart.split("|");
-        const groupingKey = typeValueParts[0];
-
-        // Is this a valid grouping key?
-        const isValid = groupingKeys.includes(groupingKey);
-        if (!isValid) {
-          throw new Error(`Unsupported grouping key ${groupingKey}`);
-        }
-
-        if (typeValueParts.length > 1) {
-          groupings.push({
-            type: typeValueParts[0] as CheckDisplayGroupType,
-            value: typeValueParts[1],
-          });
-        } else {
-          groupings.push({
-            type: typeValueParts[0] as CheckDisplayGroupType,
-          });
-        }
-      }
-      return groupings;
+      return parseGrouping(rawGrouping);
     } else {
-      return [
-        // { type: "status" },
-        // { type: "reason" },
-        // { type: "resource" },
-        // { type: "severity" },
-        // { type: "dimension", value: "account_id" },
-        // { type: "dimension", value: "region" },
-        // { type: "control_tag", value: "service" },
-        // { type: "control_tag", value: "cis_type" },
-        // { type: "control_tag", value: "cis_level" },
-        { type: "benchmark" },
-        { type: "control" },
-        { type: "result" },
-      ] as CheckDisplayGroup[];
+      return defaultGrouping;
     }
-  }, [searchParams]);
+  }, [declaredGrouping, searchParams]);
 };
 
 export default useCheckGroupingConfig;
@@ -36,6 +36,7 @@ import {
   useSearchParams,
 } from "react-router-dom";
 import useDashboardSearchPathPrefix from "@powerpipe/hooks/useDashboardSearchPathPrefix";
+import useThemeColorOverrides from "@powerpipe/hooks/useThemeColorOverrides";
 
 const DashboardContext = createContext<IDashboardContext | null>(null);
 
@@ -553,10 +554,18 @@ const DashboardProvider = ({
       previousSelectedDashboardStates.selectedDashboard.full_name ===
         state.selectedDashboard.full_name;
 
-    // Sync params into the URL
-    const newParams = {
-      ...state.selectedDashboardInputs,
-    };
+    // Sync params into the URL, preserving any params which are not inputs (e.g. grouping or search path)
+    // so that the URL always reflects the full view of the dashboard and can be bookmarked or shared
+    const newParams = new URLSearchParams(
+      Array.from(searchParams.entries()).filter(
+        ([key]) => !key.startsWith("input"),
+      ),
+    );
+    for (const [key, value] of Object.entries(
+      state.selectedDashboardInputs,
+    )) {
+      newParams.set(key, value);
+    }
     setSearchParams(newParams, {
       replace: !shouldRecordHistory,
     });
@@ -564,6 +573,7 @@ const DashboardProvider = ({
     featureFlags,
     navigationType,
     previousSelectedDashboardStates,
+    searchParams,
     setSearchParams,
     state.dataMode,
     state.recordInputsHistory,
@@ -601,15 +611,20 @@ const DashboardProvider = ({
     }
   }, [location, navigate, state.dataMode]);
 
+  const productName = state.metadata?.branding?.product_name || "Steampipe";
+
   useEffect(() => {
     if (!state.selectedDashboard) {
-      document.title = "Dashboards | Steampipe";
+      document.title = `Dashboards | ${productName}`;
     } else {
       document.title = `${
         state.selectedDashboard.title || state.selectedDashboard.full_name
-      } | Dashboards | Steampipe`;
+      } | Dashboards | ${productName}`;
     }
-  }, [state.selectedDashboard]);
+  }, [productName, state.selectedDashboard]);
+
+  useThemeColorOverrides(state.metadata?.branding?.colors, themeContext);
+
 
   const [hotKeysHandlers, setHotKeysHandlers] = useState({
     CLOSE_PANEL_DETAIL: noop,
@@ -632,19 +647,65 @@ const DashboardProvider = ({
     });
   }, [closePanelDetail]);
 
+  // Request a page of a paginated table from the server - the updated panel is sent back as a leaf_node_updated event
+  const requestTablePage = useCallback(
+    (
+      panel: string,
+      page: number,
+      sortColumn?: string,
+      sortDirection?: "asc" | "desc",
+    ) => {
+      if (!socketReady || state.dataMode !== DashboardDataModeLive) {
+        return;
+      }
+      sendSocketMessage({
+        action: SocketActions.TABLE_PAGE,
+        payload: {
+          dashboard: {
+            full_name: state.selectedDashboard?.full_name,
+          },
+          panel,
+          page,
+          sort_column: sortColumn,
+          sort_direction: sortDirection,
+        },
+      });
+    },
+    [sendSocketMessage, socketReady, state.dataMode, state.selectedDashboard],
+  );
+
+  // Turn auto-refresh on or off for the selected dashboard - this only applies to dashboards with a refresh interval
+  const setAutoRefresh = useCallback(
+    (enabled: boolean) => {
+      if (!socketReady || state.dataMode !== DashboardDataModeLive) {
+        return;
+      }
+      sendSocketMessage({
+        action: SocketActions.SET_AUTO_REFRESH,
+        payload: {
+          dashboard: {
+            full_name: state.selectedDashboard?.full_name,
+          },
+          auto_refresh: enabled,
+        },
+      });
+    },
+    [sendSocketMessage, socketReady, state.dataMode, state.selectedDashboard],
+  );
+
   const [renderSnapshotCompleteDiv, setRenderSnapshotCompleteDiv] =
     useState(false);
 
   useEffect(() => {
     if (
-      (dataOptions?.dataMode !== DashboardDataModeCLISnapshot &&
-        dataOptions?.dataMode !== DashboardDataModeCloudSnapshot) ||
+      (state.dataMode !== DashboardDataModeCLISnapshot &&
+        state.dataMode !== DashboardDataModeCloudSnapshot) ||
       state.state !== "complete"
     ) {
       return;
     }
     setRenderSnapshotCompleteDiv(true);
-  }, [dataOptions?.dataMode, state.state]);
+  }, [state.dataMode, state.state]);
 
   return (
     <DashboardContext.Provider
@@ -655,6 +716,8 @@ const DashboardProvider = ({
         components,
         dispatch,
         closePanelDetail,
+        requestTablePage,
+        setAutoRefresh,
         themeContext,
         render: {
           headless: renderOptions?.headless,
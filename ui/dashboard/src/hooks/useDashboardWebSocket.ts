@@ -4,6 +4,7 @@ import {
   DashboardCliMode,
   DashboardDataMode,
   DashboardDataModeCLISnapshot,
+  DashboardActions,
   DashboardDataModeLive,
   IActions,
   ReceivedSocketMessagePayload,
@@ -18,8 +19,15 @@ export const SocketActions: IActions = {
   SELECT_DASHBOARD: "select_dashboard",
   SELECT_SNAPSHOT: "select_snapshot",
   INPUT_CHANGED: "input_changed",
+  TABLE_PAGE: "table_page",
+  SET_AUTO_REFRESH: "set_auto_refresh",
+  RESUME_SESSION: "resume_session",
+  ACK: "ack",
 };
 
+// How often to acknowledge the events we have received, so the server can discard them
+const ackInterval = 5000;
+
 const useDashboardWebSocket = (
   cliMode: DashboardCliMode,
   dataMode: DashboardDataMode,
@@ -28,6 +36,16 @@ const useDashboardWebSocket = (
   socketUrlFactory?: () => Promise<string>,
 ) => {
   const didUnmount = useRef(false);
+  // Track the last event we received and the execution it belongs to, so that
+  // if the socket drops we can resume the session rather than re-run the dashboard
+  const hasConnected = useRef(false);
+  const executionId = useRef<string | null>(null);
+  const lastSequence = useRef(0);
+  const lastAckedSequence = useRef(0);
+  // The token the server requires to resume our session, and the token of the
+  // session started by the current connection, which we adopt if we are not resuming
+  const resumeToken = useRef<string | null>(null);
+  const connectionResumeToken = useRef<string | null>(null);
   // const [socketUrl, setSocketUrl] = useState<string | null>(
   //   !socketUrlFactory ? getSocketServerUrl() : null
   // );
@@ -73,6 +91,29 @@ const useDashboardWebSocket = (
     if (!typedEvent || !typedEvent.action) {
       return;
     }
+    if (typedEvent.sequence) {
+      lastSequence.current = typedEvent.sequence;
+    }
+    if (typedEvent.execution_id) {
+      executionId.current = typedEvent.execution_id;
+    }
+    if (typedEvent.action === "session_started") {
+      connectionResumeToken.current = typedEvent.resume_token;
+      // If we have an execution, we will try to resume its session instead
+      if (!executionId.current) {
+        resumeToken.current = typedEvent.resume_token;
+      }
+      return;
+    }
+    if (typedEvent.action === "session_resumed") {
+      // If the server could not resume our session, select the dashboard again
+      // in the session started by this connection
+      if (!typedEvent.resumed) {
+        resumeToken.current = connectionResumeToken.current;
+        dispatch({ type: DashboardActions.SET_REFETCH_DASHBOARD });
+      }
+      return;
+    }
     eventHandler(typedEvent);
   }, [eventHandler, lastJsonMessage]);
 
@@ -80,6 +121,18 @@ const useDashboardWebSocket = (
     if (readyState !== ReadyState.OPEN || !sendJsonMessage) {
       return;
     }
+    // If this is a reconnect, ask the server to replay anything we missed
+    if (hasConnected.current && executionId.current) {
+      sendJsonMessage({
+        action: SocketActions.RESUME_SESSION,
+        payload: {
+          execution_id: executionId.current,
+          resume_token: resumeToken.current,
+          last_sequence: lastSequence.current,
+        },
+      });
+    }
+    hasConnected.current = true;
     // TODO remove once all workspaces are running Powerpipe as this is actually GET_SERVER_METADATA in Powerpipe
     if (cliMode === "steampipe") {
       sendJsonMessage({ action: SocketActions.GET_DASHBOARD_METADATA });
@@ -89,6 +142,23 @@ const useDashboardWebSocket = (
     sendJsonMessage({ action: SocketActions.GET_AVAILABLE_DASHBOARDS });
   }, [cliMode, readyState, sendJsonMessage]);
 
+  useEffect(() => {
+    if (readyState !== ReadyState.OPEN || !sendJsonMessage) {
+      return;
+    }
+    const interval = setInterval(() => {
+      if (lastSequence.current === lastAckedSequence.current) {
+        return;
+      }
+      lastAckedSequence.current = lastSequence.current;
+      sendJsonMessage({
+        action: SocketActions.ACK,
+        payload: { last_sequence: lastSequence.current },
+      });
+    }, ackInterval);
+    return () => clearInterval(interval);
+  }, [readyState, sendJsonMessage]);
+
   useEffect(() => {
     return () => {
       didUnmount.current = true;
@@ -5,12 +5,47 @@ import { useCallback, useState } from "react";
 import { useDashboard } from "./useDashboard";
 import { usePapaParse } from "react-papaparse";
 
+export type PanelDataDownloadFormat = "csv" | "json";
+
 const useDownloadPanelData = (definition: PanelDefinition) => {
   const { selectedDashboard } = useDashboard();
   const { jsonToCSV } = usePapaParse();
   const [processing, setProcessing] = useState(false);
 
-  const downloadQueryData = useCallback(async () => {
+  const getFilename = useCallback(
+    (extension: string) =>
+      `${(
+        selectedDashboard?.full_name ||
+        definition.dashboard ||
+        ""
+      ).replaceAll(".", "_")}_${definition.panel_type}_${timestampForFilename(
+        Date.now(),
+      )}.${extension}`,
+    [definition, selectedDashboard],
+  );
+
+  const downloadQueryDataAsJSON = useCallback(async () => {
+    if (!definition.data) {
+      return;
+    }
+    setProcessing(true);
+    const data = definition.data;
+    // Build the rows with the keys in column order
+    const jsonRows = data.rows.map((row) => {
+      const jsonRow = {};
+      for (const col of data.columns) {
+        jsonRow[col.name] = row[col.name];
+      }
+      return jsonRow;
+    });
+    const blob = new Blob([JSON.stringify(jsonRows, null, 2)], {
+      type: "application/json;charset=utf-8",
+    });
+    saveAs(blob, getFilename("json"));
+    setProcessing(false);
+  }, [definition, getFilename]);
+
+  const downloadQueryDataAsCSV = useCallback(async () => {
     if (!definition.data) {
       return;
     }
@@ -38,18 +73,15 @@ const useDownloadPanelData = (definition: PanelDefinition) => {
     const csv = jsonToCSV([colNames, ...csvRows]);
     const blob = new Blob([csv], { type: "text/csv;charset=utf-8" });
 
-    saveAs(
-      blob,
-      `${(
-        selectedDashboard?.full_name ||
-        definition.dashboard ||
-        ""
-      ).replaceAll(".", "_")}_${definition.panel_type}_${timestampForFilename(
-        Date.now(),
-      )}.csv`,
-    );
+    saveAs(blob, getFilename("csv"));
     setProcessing(false);
-  }, [definition, jsonToCSV, selectedDashboard]);
+  }, [definition, getFilename, jsonToCSV]);
+
+  const downloadQueryData = useCallback(
+    async (format: PanelDataDownloadFormat = "csv") =>
+      format === "json" ? downloadQueryDataAsJSON() : downloadQueryDataAsCSV(),
+    [downloadQueryDataAsCSV, downloadQueryDataAsJSON],
+  );
 
   return { download: downloadQueryData, processing };
 };
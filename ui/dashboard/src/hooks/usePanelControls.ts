@@ -8,10 +8,18 @@ const usePanelControls = (definition: PanelDefinition, show = false) => {
   const { download } = useDownloadPanelData(definition);
   const { select } = useSelectPanel(definition);
 
-  const downloadPanelData = useCallback(
+  const downloadPanelDataAsCSV = useCallback(
     async (e) => {
       e.stopPropagation();
-      await download();
+      await download("csv");
+    },
+    [download],
+  );
+
+  const downloadPanelDataAsJSON = useCallback(
+    async (e) => {
+      e.stopPropagation();
+      await download("json");
     },
     [download],
   );
@@ -23,9 +31,14 @@ const usePanelControls = (definition: PanelDefinition, show = false) => {
     }
     if (definition.data) {
       controls.push({
-        action: downloadPanelData,
+        action: downloadPanelDataAsCSV,
         icon: "arrow-down-tray",
-        title: "Download data",
+        title: "Download data as CSV",
+      });
+      controls.push({
+        action: downloadPanelDataAsJSON,
+        icon: "code-bracket",
+        title: "Download data as JSON",
       });
     }
     controls.push({
@@ -34,7 +47,13 @@ const usePanelControls = (definition: PanelDefinition, show = false) => {
       title: "View detail",
     });
     return controls;
-  }, [definition, downloadPanelData, select, show]);
+  }, [
+    definition,
+    downloadPanelDataAsCSV,
+    downloadPanelDataAsJSON,
+    select,
+    show,
+  ]);
 
   const [panelControls, setPanelControls] = useState(getBasePanelControls());
   const [customControls, setCustomControls] = useState<IPanelControl[]>([]);
@@ -31,6 +31,7 @@ const Themes: IThemes = {
 type IThemeContext = {
   localStorageTheme: string | null;
   theme: Theme;
+  setDashboardTheme?(theme: string | null): void;
   withFooterPadding: boolean;
   wrapperRef: React.Ref<null>;
   setTheme(theme: string): void;
@@ -46,6 +47,8 @@ const ThemeProvider = ({ children }) => {
     useLocalStorage("steampipe.ui.theme");
   const prefersDarkTheme = useMediaQuery("(prefers-color-scheme: dark)");
   const [wrapperRef, setWrapperRef] = useState(null);
+  // The default theme declared by the current dashboard - an explicit user choice takes precedence
+  const [dashboardTheme, setDashboardTheme] = useState<string | null>(null);
   const doSetWrapperRef = (element) => setWrapperRef(() => element);
 
   let theme;
@@ -56,6 +59,8 @@ const ThemeProvider = ({ children }) => {
       localStorageTheme === ThemeNames.STEAMPIPE_DARK)
   ) {
     theme = Themes[localStorageTheme];
+  } else if (dashboardTheme && Themes[dashboardTheme]) {
+    theme = Themes[dashboardTheme];
   } else if (prefersDarkTheme) {
     theme = Themes[ThemeNames.STEAMPIPE_DARK];
   } else {
@@ -67,6 +72,7 @@ const ThemeProvider = ({ children }) => {
       value={{
         localStorageTheme,
         theme,
+        setDashboardTheme,
         setTheme: setLocalStorageTheme,
         setWrapperRef: doSetWrapperRef,
         withFooterPadding,
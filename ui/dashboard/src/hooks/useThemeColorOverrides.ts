@@ -0,0 +1,49 @@
+import { useEffect } from "react";
+
+const rgbTripleRegex = /^\d+\s+\d+\s+\d+$/;
+const hexColorRegex = /^#([0-9a-f]{2})([0-9a-f]{2})([0-9a-f]{2})$/i;
+
+// Some theme colors are stored as "r g b" triples so that Tailwind can apply opacity to them,
+// so if the existing value is a triple, convert any hex override to the same format
+const toThemeColorValue = (existing: string, value: string) => {
+  if (!rgbTripleRegex.test(existing)) {
+    return value;
+  }
+  const match = hexColorRegex.exec(value);
+  if (!match) {
+    return value;
+  }
+  return match
+    .slice(1)
+    .map((hex) => parseInt(hex, 16))
+    .join(" ");
+};
+
+// Override theme color CSS variables on the theme wrapper element, e.g. { alert: "#ff0000" } sets --color-alert
+const useThemeColorOverrides = (
+  colors: { [name: string]: string } | null | undefined,
+  themeContext: { theme: { name: string }; wrapperRef: any },
+) => {
+  const { theme, wrapperRef } = themeContext;
+
+  useEffect(() => {
+    if (!colors || !wrapperRef) {
+      return;
+    }
+    // @ts-ignore
+    const element: HTMLElement = wrapperRef;
+    const style = window.getComputedStyle(element);
+    Object.entries(colors).forEach(([name, value]) => {
+      const variable = `--color-${name}`;
+      const existing = style.getPropertyValue(variable).trim();
+      element.style.setProperty(variable, toThemeColorValue(existing, value));
+    });
+    return () => {
+      Object.keys(colors).forEach((name) =>
+        element.style.removeProperty(`--color-${name}`),
+      );
+    };
+  }, [colors, theme.name, wrapperRef]);
+};
+
+export default useThemeColorOverrides;
@@ -15,6 +15,13 @@ export type IDashboardContext = {
 
   closePanelDetail(): void;
   dispatch(action: DashboardAction): void;
+  requestTablePage(
+    panel: string,
+    page: number,
+    sortColumn?: string,
+    sortDirection?: "asc" | "desc",
+  ): void;
+  setAutoRefresh(enabled: boolean): void;
 
   dataMode: DashboardDataMode;
   snapshotId: string | null;
@@ -302,6 +309,13 @@ type CloudServerMetadata = {
   workspace: CloudServerWorkspaceMetadata;
 };
 
+export type BrandingServerMetadata = {
+  product_name?: string;
+  logo_url?: string;
+  colors?: { [name: string]: string };
+  footer?: string;
+};
+
 export type ServerMetadata = {
   mod: ModServerMetadata;
   installed_mods?: InstalledModsServerMetadata;
@@ -309,6 +323,7 @@ export type ServerMetadata = {
   cloud?: CloudServerMetadata;
   telemetry: "info" | "none";
   search_path: SearchPathMetadata;
+  branding?: BrandingServerMetadata;
 };
 
 export type DashboardLayoutNode = {
@@ -411,6 +426,11 @@ export type PanelProperties = {
   [key: string]: any;
 };
 
+// Options set on the resource using "powerpipe:" tags, keyed by option name
+export type PanelOptions = {
+  [key: string]: string;
+};
+
 export type DependencyPanelProperties = {
   name: string;
 };
@@ -420,6 +440,8 @@ export type PanelDefinition = {
   args?: any[];
   display?: string;
   display_type?: string;
+  hidden?: boolean;
+  options?: PanelOptions;
   panel_type: DashboardPanelType;
   title?: string;
   description?: string;
@@ -434,6 +456,13 @@ export type PanelDefinition = {
   dashboard: string;
   children?: DashboardLayoutNode[];
   dependencies?: string[];
+  theme?: DashboardTheme;
+};
+
+export type DashboardTheme = {
+  mode?: "light" | "dark";
+  palette?: string[];
+  accents?: { [name: string]: string };
 };
 
 export type PanelDependenciesByStatus = {
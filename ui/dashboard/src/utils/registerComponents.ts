@@ -13,9 +13,15 @@ import "@powerpipe/components/SnapshotHeader";
 import "@powerpipe/components/dashboards/charts/AreaChart";
 import "@powerpipe/components/dashboards/charts/BarChart";
 import "@powerpipe/components/dashboards/charts/ColumnChart";
+import "@powerpipe/components/dashboards/charts/CustomChart";
 import "@powerpipe/components/dashboards/charts/DonutChart";
+import "@powerpipe/components/dashboards/charts/GaugeChart";
+import "@powerpipe/components/dashboards/charts/HeatmapChart";
 import "@powerpipe/components/dashboards/charts/LineChart";
+import "@powerpipe/components/dashboards/charts/MapChart";
 import "@powerpipe/components/dashboards/charts/PieChart";
+import "@powerpipe/components/dashboards/charts/SankeyChart";
+import "@powerpipe/components/dashboards/charts/TreemapChart";
 import "@powerpipe/components/dashboards/charts/Chart";
 
 // Flows
@@ -31,8 +37,10 @@ import "@powerpipe/components/dashboards/hierarchies/Tree";
 import "@powerpipe/components/dashboards/hierarchies/Hierarchy";
 
 // Inputs
+import "@powerpipe/components/dashboards/inputs/DateRangeInput";
 import "@powerpipe/components/dashboards/inputs/MultiComboInput";
 import "@powerpipe/components/dashboards/inputs/MultiSelectInput";
+import "@powerpipe/components/dashboards/inputs/NumberInput";
 import "@powerpipe/components/dashboards/inputs/SingleComboInput";
 import "@powerpipe/components/dashboards/inputs/SingleSelectInput";
 import "@powerpipe/components/dashboards/inputs/TextInput";
@@ -65,6 +65,8 @@ export const PanelStoryDecorator = ({
         },
         availableDashboardsLoaded: true,
         closePanelDetail: noop,
+        requestTablePage: noop,
+        setAutoRefresh: noop,
         dataMode: DashboardDataModeLive,
         snapshotId: null,
         dispatch: noop,
@@ -1,4 +1,4 @@
-import { isRelativeUrl } from "./url";
+import { getSafeImageUrl, isRelativeUrl } from "./url";
 
 describe("isRelativeUrl", () => {
   test("null", () => {
@@ -25,3 +25,45 @@ describe("isRelativeUrl", () => {
     expect(isRelativeUrl("https://foo.bar")).toEqual(false);
   });
 });
+
+describe("getSafeImageUrl", () => {
+  test("null", () => {
+    expect(getSafeImageUrl(null)).toEqual(null);
+  });
+
+  test("https", () => {
+    expect(getSafeImageUrl("https://foo.bar/image.png")).toEqual(
+      "https://foo.bar/image.png",
+    );
+  });
+
+  test("relative", () => {
+    expect(getSafeImageUrl("/mod-assets/diagram.svg")).toEqual(
+      new URL("/mod-assets/diagram.svg", document.baseURI).href,
+    );
+  });
+
+  test("javascript", () => {
+    expect(getSafeImageUrl("javascript:alert(1)")).toEqual(null);
+  });
+
+  test("base64 data url", () => {
+    expect(getSafeImageUrl("data:image/png;base64,iVBORw0KGgo=")).toEqual(
+      "data:image/png;base64,iVBORw0KGgo=",
+    );
+  });
+
+  test("non-image data url", () => {
+    expect(
+      getSafeImageUrl(
+        "data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==",
+      ),
+    ).toEqual(null);
+  });
+
+  test("raw base64", () => {
+    const data =
+      "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==";
+    expect(getSafeImageUrl(data)).toEqual(`data:image/png;base64,${data}`);
+  });
+});
@@ -4,4 +4,34 @@ const isRelativeUrl = (url) => {
   );
 };
 
-export { isRelativeUrl };
+const safeImageDataUrlRegex =
+  /^data:image\/(png|jpeg|jpg|gif|webp|bmp|svg\+xml);base64,[a-z0-9+/]+=*$/i;
+const base64Regex = /^[a-z0-9+/\s]+=*$/i;
+
+// Returns a URL which is safe to use as the src of an image, or null if the value is not safe.
+// Allowed values are http(s) URLs, relative URLs, base64 image data URLs and raw base64 data,
+// which is assumed to be a PNG.
+const getSafeImageUrl = (value: string | null | undefined): string | null => {
+  if (!value || typeof value !== "string") {
+    return null;
+  }
+  const trimmed = value.trim();
+  if (trimmed.startsWith("data:")) {
+    return safeImageDataUrlRegex.test(trimmed) ? trimmed : null;
+  }
+  // raw base64 data is long and has no URL structure
+  if (trimmed.length > 64 && base64Regex.test(trimmed)) {
+    return `data:image/png;base64,${trimmed.replace(/\s/g, "")}`;
+  }
+  try {
+    const url = new URL(trimmed, document.baseURI);
+    if (url.protocol !== "http:" && url.protocol !== "https:") {
+      return null;
+    }
+    return url.href;
+  } catch {
+    return null;
+  }
+};
+
+export { getSafeImageUrl, isRelativeUrl };